@@ -92,6 +92,12 @@ pub struct ParsedArgs {
     /// opportunistically on supported platforms.
     pub tcp_fastopen: TcpFastOpenMode,
 
+    /// `--tls` - wrap daemon connections in TLS.
+    ///
+    /// oc-rsync extension with no upstream equivalent: only an oc-rsync
+    /// daemon configured with `tls cert file` / `tls key file` accepts it.
+    pub tls: bool,
+
     /// `--blocking-io` / `--no-blocking-io` - blocking I/O for remote shell.
     pub blocking_io: Option<bool>,
 
@@ -107,6 +113,9 @@ pub struct ParsedArgs {
     /// `--contimeout` - connection establishment timeout in seconds.
     pub contimeout: Option<OsString>,
 
+    /// `--blocking-timeout` - deadline in seconds for each socket read or write.
+    pub blocking_timeout: Option<OsString>,
+
     /// `--stop-after` - stop transfer after the specified duration.
     pub stop_after: Option<OsString>,
 
@@ -310,6 +319,13 @@ pub struct ParsedArgs {
     /// `--crtimes`, `-N` / `--no-crtimes` - preserve creation times (macOS/Windows).
     pub crtimes: Option<bool>,
 
+    /// `--fileflags` / `--no-fileflags` - preserve file flags (`chattr`/`chflags`).
+    pub fileflags: Option<bool>,
+
+    /// `--force-change`, `--force-uchange`, `--force-schange` - immutable flags
+    /// that may be cleared to update a protected destination file.
+    pub force_change: ::metadata::ForceChange,
+
     /// `--acls`, `-A` / `--no-acls` - preserve Access Control Lists.
     pub acls: Option<bool>,
 
@@ -528,6 +544,26 @@ pub struct ParsedArgs {
     /// `--temp-dir`, `-T` - directory for temporary files during transfer.
     pub temp_dir: Option<PathBuf>,
 
+    /// `--checksum-cache=DIR` - persist `--checksum` sums across runs.
+    ///
+    /// oc-rsync extension with no upstream equivalent. Only local copies
+    /// consult the cache; the option is never forwarded to a remote server.
+    pub checksum_cache: Option<PathBuf>,
+
+    /// `--checksum-xattr=NAME` - read precomputed `--checksum` sums from an
+    /// extended attribute.
+    ///
+    /// oc-rsync extension with no upstream equivalent. Both local copies and
+    /// this side of a remote transfer consult the attribute; the option is
+    /// never forwarded to a remote server.
+    pub checksum_xattr: Option<OsString>,
+
+    /// `--resume-manifest=FILE` - record and resume interrupted daemon pulls.
+    ///
+    /// oc-rsync extension with no upstream equivalent. Only an oc-rsync
+    /// daemon acts on the manifest; other daemons run a normal pull.
+    pub resume_manifest: Option<PathBuf>,
+
     /// `--max-alloc=SIZE` - soft byte budget on buffer-pool retention.
     ///
     /// Stored as the raw user-supplied string. The downstream parser in
@@ -570,6 +606,10 @@ pub struct ParsedArgs {
     /// `--stats` - print transfer statistics at the end.
     pub stats: bool,
 
+    /// `--json` - replace the text summary and listing with one JSON object
+    /// (oc-rsync extension).
+    pub json: bool,
+
     /// `--8-bit-output`, `-8` - display high-bit characters as-is.
     pub eight_bit_output: bool,
 
@@ -668,6 +708,13 @@ pub struct ParsedArgs {
     /// server and never changes wire bytes.
     pub checksum_threads: Option<super::ChecksumThreadsSetting>,
 
+    /// `--copy-buffer-size` - buffer length for streamed file data.
+    ///
+    /// Validated to `1..=MAX_BUFFER_POOL_BLOCK_SIZE`. `None` keeps the default
+    /// buffers. Applies to local copies and to the local half of a remote
+    /// transfer; never forwarded to the remote server.
+    pub copy_buffer_size: Option<usize>,
+
     /// `--spill-dir` - override the reorder-buffer spill directory.
     ///
     /// Overrides the `OC_RSYNC_SPILL_DIR` environment variable and the
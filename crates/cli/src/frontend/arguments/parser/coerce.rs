@@ -1,7 +1,8 @@
 //! Value coercion and validation helpers for numeric/sized CLI options.
 //!
 //! These parse and range-check the integer and byte-sized arguments
-//! (`--rayon-threads`, `--tokio-threads`, `--spill-threshold-bytes`) before
+//! (`--rayon-threads`, `--tokio-threads`, `--spill-threshold-bytes`,
+//! `--copy-buffer-size`) before
 //! they reach the strongly-typed [`ParsedArgs`](super::ParsedArgs) struct.
 
 use std::ffi::OsString;
@@ -133,6 +134,31 @@ pub(super) fn parse_spill_threshold_bytes(
     Ok(Some(bytes))
 }
 
+/// Parses the `--copy-buffer-size` value into a buffer length in bytes.
+///
+/// Uses the `--spill-threshold-bytes` size grammar. `0` and values above
+/// the buffer pool's largest block are rejected.
+/// Returns `Ok(None)` when the flag was not supplied.
+pub(super) fn parse_copy_buffer_size(
+    matches: &mut clap::ArgMatches,
+) -> Result<Option<usize>, clap::Error> {
+    let Some(value) = matches.remove_one::<OsString>("copy-buffer-size") else {
+        return Ok(None);
+    };
+    let raw = value.to_string_lossy();
+    let max = engine::local_copy::MAX_BUFFER_POOL_BLOCK_SIZE;
+    match parse_spill_size(raw.trim()).and_then(|bytes| usize::try_from(bytes).ok()) {
+        Some(bytes) if bytes > 0 && bytes <= max => Ok(Some(bytes)),
+        _ => Err(clap::Error::raw(
+            clap::error::ErrorKind::ValueValidation,
+            format!(
+                "invalid --copy-buffer-size value '{raw}': must be between 1 and \
+                 {max} bytes, with an optional K/M/G suffix (base 1024)\n"
+            ),
+        )),
+    }
+}
+
 /// Parses a positive integer with an optional K/M/G/T/P/E suffix (base 1024).
 ///
 /// Returns `None` when the input does not match the expected grammar or when
@@ -21,10 +21,12 @@ use core::client::{
     AddressMode, DeleteMode, HumanReadableMode, StrongChecksumChoice, TcpFastOpenMode,
 };
 
-use super::coerce::{parse_checksum_threads, parse_spill_threshold_bytes, parse_thread_count};
+use super::coerce::{
+    parse_checksum_threads, parse_copy_buffer_size, parse_spill_threshold_bytes, parse_thread_count,
+};
 use super::cow::{last_occurrence, parse_reflink_mode, resolve_cow_policy};
 use super::flags::{
-    archive_aware_flag, leveled_flag_pair, tri_state_flag_negative_first,
+    archive_aware_flag, leveled_flag_pair, tri_state_flag_indexed, tri_state_flag_negative_first,
     tri_state_flag_positive_first,
 };
 use super::values::join_os_values;
@@ -149,6 +151,14 @@ where
         tri_state_flag_negative_first(&matches, "omit-link-times", "no-omit-link-times");
     let atimes = leveled_flag_pair(&matches, "atimes", "no-atimes");
     let crtimes = tri_state_flag_negative_first(&matches, "crtimes", "no-crtimes");
+    let fileflags = tri_state_flag_negative_first(&matches, "fileflags", "no-fileflags");
+    // upstream: rsync-patches fileflags.diff - `--force-change` covers both the
+    // user and the system immutable flags; the u/s variants pick one kind.
+    let force_all = matches.get_flag("force-change");
+    let force_change = ::metadata::ForceChange::new(
+        force_all || matches.get_flag("force-uchange"),
+        force_all || matches.get_flag("force-schange"),
+    );
     // upstream: options.c:2366-2367 - only `dry_run` sets `do_xfers = 0` (and
     // thus the compact `n` letter); `list_only` does NOT (options.c:2634 "Note:
     // NOT dry_run!"). The receiver skips destination writes under `list_only`
@@ -198,6 +208,7 @@ where
             })?,
         None => TcpFastOpenMode::default(),
     };
+    let tls = matches.get_flag("tls");
     let blocking_io = tri_state_flag_positive_first(&matches, "blocking-io", "no-blocking-io");
     let archive = matches.get_flag("archive");
     // Last command-line index of `-a`, used to resolve every archive-implied
@@ -274,6 +285,7 @@ where
     let rayon_threads = parse_thread_count(&mut matches, "rayon-threads")?;
     let tokio_threads = parse_thread_count(&mut matches, "tokio-threads")?;
     let checksum_threads = parse_checksum_threads(&mut matches)?;
+    let copy_buffer_size = parse_copy_buffer_size(&mut matches)?;
 
     let spill_dir = matches
         .remove_one::<OsString>("spill-dir")
@@ -533,7 +545,9 @@ where
         }
     };
     let implied_dirs = tri_state_flag_positive_first(&matches, "implied-dirs", "no-implied-dirs");
-    let msgs_to_stderr = tri_state_flag_positive_first(&matches, "msgs2stderr", "no-msgs2stderr");
+    let msgs_to_stderr_flag =
+        tri_state_flag_indexed(&matches, "msgs2stderr", "no-msgs2stderr", true);
+    let stderr_index = last_occurrence(&matches, "stderr");
     let stderr_mode = matches.remove_one::<OsString>("stderr");
     // upstream: options.c:1912 OPT_STDERR rejects any value that is not a
     // non-empty prefix of "errors", "all", or "client" with this exact message.
@@ -551,6 +565,21 @@ where
             ));
         }
     }
+    // upstream: options.c:1912 OPT_STDERR - `--stderr` sets the same
+    // `msgs2stderr` variable as `--[no-]msgs2stderr`, so whichever comes last
+    // on the command line decides the routing.
+    let stderr_setting = stderr_mode
+        .as_ref()
+        .and_then(|value| value.to_str())
+        .and_then(StderrMode::from_str);
+    let msgs_to_stderr = match (msgs_to_stderr_flag, stderr_setting) {
+        (Some((_, flag_index)), Some(mode)) if stderr_index.is_some_and(|i| i > flag_index) => {
+            mode.msgs2stderr()
+        }
+        (Some((value, _)), _) => Some(value),
+        (None, Some(mode)) => mode.msgs2stderr(),
+        (None, None) => None,
+    };
     let outbuf = matches.remove_one::<OsString>("outbuf");
     // upstream: options.c:1954-1957 - `if (!max_alloc_arg) { max_alloc_arg =
     // getenv("RSYNC_MAX_ALLOC"); ... }`. RSYNC_MAX_ALLOC supplies the default
@@ -559,6 +588,7 @@ where
         .remove_one::<OsString>("max-alloc")
         .or_else(env_max_alloc_default);
     let stats = matches.get_flag("stats");
+    let json = matches.get_flag("json");
     let eight_bit_output = matches.get_flag("8-bit-output");
     let partial_flag = matches.get_flag("partial") || matches.get_count("partial-progress") > 0;
     let no_partial = matches.get_flag("no-partial");
@@ -675,6 +705,13 @@ where
     let temp_dir = matches
         .remove_one::<OsString>("temp-dir")
         .map(PathBuf::from);
+    let checksum_cache = matches
+        .remove_one::<OsString>("checksum-cache")
+        .map(PathBuf::from);
+    let checksum_xattr = matches.remove_one::<OsString>("checksum-xattr");
+    let resume_manifest = matches
+        .remove_one::<OsString>("resume-manifest")
+        .map(PathBuf::from);
     let log_file = matches.remove_one::<OsString>("log-file");
     let log_file_format = matches.remove_one::<OsString>("log-file-format");
     let write_batch = matches.remove_one::<OsString>("write-batch");
@@ -902,6 +939,7 @@ where
     let protocol = matches.remove_one::<OsString>("protocol");
     let timeout = matches.remove_one::<OsString>("timeout");
     let contimeout = matches.remove_one::<OsString>("contimeout");
+    let blocking_timeout = matches.remove_one::<OsString>("blocking-timeout");
     let stop_after = matches.remove_one::<OsString>("stop-after");
     let stop_at_option = matches.remove_one::<OsString>("stop-at");
     let out_format = matches.remove_one::<OsString>("out-format");
@@ -1027,6 +1065,7 @@ where
         bind_address: bind_address_raw,
         sockopts,
         tcp_fastopen,
+        tls,
         blocking_io,
         archive,
         recursive,
@@ -1083,6 +1122,8 @@ where
         omit_link_times,
         atimes,
         crtimes,
+        fileflags,
+        force_change,
         acls,
         numeric_ids,
         hard_links,
@@ -1119,6 +1160,7 @@ where
         name_level,
         name_overridden,
         stats,
+        json,
         eight_bit_output,
         partial,
         preallocate,
@@ -1132,6 +1174,9 @@ where
         delay_updates,
         partial_dir,
         temp_dir,
+        checksum_cache,
+        checksum_xattr,
+        resume_manifest,
         log_file,
         log_file_format,
         write_batch,
@@ -1175,6 +1220,7 @@ where
         protocol,
         timeout,
         contimeout,
+        blocking_timeout,
         stop_after,
         stop_at: stop_at_option,
         out_format,
@@ -1195,6 +1241,7 @@ where
         rayon_threads,
         tokio_threads,
         checksum_threads,
+        copy_buffer_size,
         spill_dir,
         spill_threshold_bytes,
         no_spill,
@@ -50,7 +50,7 @@ fn tri_state_flag_with_order(
 /// Behaves exactly like [`tri_state_flag_with_order`] but exposes the deciding
 /// index so callers can compose the result with another flag in argv order
 /// (see [`archive_aware_flag`]).
-fn tri_state_flag_indexed(
+pub(super) fn tri_state_flag_indexed(
     matches: &clap::ArgMatches,
     positive: &str,
     negative: &str,
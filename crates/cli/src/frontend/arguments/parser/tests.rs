@@ -688,6 +688,30 @@ fn simd_rejects_unknown_levels() {
     assert!(err.to_string().contains("avx1024"));
 }
 
+#[test]
+fn copy_buffer_size_flag_default_is_none() {
+    let parsed = parse_test_args(["src/", "dst/"]).expect("parse");
+    assert!(parsed.copy_buffer_size.is_none());
+}
+
+#[test]
+fn copy_buffer_size_flag_parses_suffix() {
+    let parsed = parse_test_args(["--copy-buffer-size=256K", "src/", "dst/"]).expect("parse");
+    assert_eq!(parsed.copy_buffer_size, Some(256 * 1024));
+}
+
+#[test]
+fn copy_buffer_size_rejects_zero_and_oversized_values() {
+    for value in ["0", "2G", "abc"] {
+        let err = parse_test_args(["--copy-buffer-size", value, "src/", "dst/"])
+            .expect_err("value should be rejected");
+        assert!(
+            err.to_string().contains("--copy-buffer-size"),
+            "{value}: {err}"
+        );
+    }
+}
+
 #[test]
 fn spill_dir_flag_default_is_none() {
     let parsed = parse_test_args(["src/", "dst/"]).expect("parse");
@@ -963,6 +987,45 @@ fn checksum_threads_rejects_invalid_and_out_of_range() {
     }
 }
 
+#[test]
+fn checksum_cache_records_directory() {
+    let parsed = parse_test_args(["-c", "--checksum-cache=/var/cache/sums", "src/", "dst/"])
+        .expect("parse --checksum-cache");
+    assert_eq!(
+        parsed.checksum_cache.as_deref(),
+        Some(std::path::Path::new("/var/cache/sums"))
+    );
+
+    let parsed = parse_test_args(["-c", "src/", "dst/"]).expect("parse without cache");
+    assert!(parsed.checksum_cache.is_none());
+}
+
+#[test]
+fn checksum_xattr_records_attribute_name() {
+    let parsed = parse_test_args(["-c", "--checksum-xattr=user.rsync.checksum", "src/", "dst/"])
+        .expect("parse --checksum-xattr");
+    assert_eq!(
+        parsed.checksum_xattr.as_deref(),
+        Some(std::ffi::OsStr::new("user.rsync.checksum"))
+    );
+
+    let parsed = parse_test_args(["-c", "src/", "dst/"]).expect("parse without xattr");
+    assert!(parsed.checksum_xattr.is_none());
+}
+
+#[test]
+fn resume_manifest_records_path() {
+    let parsed = parse_test_args(["--resume-manifest=pull.manifest", "rsync://h/m/", "dst/"])
+        .expect("parse --resume-manifest");
+    assert_eq!(
+        parsed.resume_manifest.as_deref(),
+        Some(std::path::Path::new("pull.manifest"))
+    );
+
+    let parsed = parse_test_args(["rsync://h/m/", "dst/"]).expect("parse without manifest");
+    assert!(parsed.resume_manifest.is_none());
+}
+
 /// Builds `count` repetitions of a single alt-dest option plus operands.
 fn alt_dest_args(flag: &str, count: usize) -> Vec<String> {
     let mut args: Vec<String> = (0..count)
@@ -1565,6 +1565,14 @@ mod option_values {
         assert_eq!(parsed.sockopts, Some(OsString::from("SO_SNDBUF=65536")));
     }
 
+    #[test]
+    fn tls_flag_defaults_off_and_sets() {
+        let parsed = parse_test_args(["src/", "dst/"]).expect("parse");
+        assert!(!parsed.tls);
+        let parsed = parse_test_args(["--tls", "src/", "dst/"]).expect("parse");
+        assert!(parsed.tls);
+    }
+
     #[test]
     fn tcp_fastopen_defaults_to_auto() {
         let parsed = parse_test_args(["src/", "dst/"]).expect("parse");
@@ -2757,6 +2765,26 @@ mod msgs_stderr_tests {
         assert_eq!(parsed.stderr_mode, Some(OsString::from("errors")));
     }
 
+    #[test]
+    fn stderr_mode_sets_msgs2stderr_in_argv_order() {
+        // upstream: options.c:1912 - `--stderr` and `--[no-]msgs2stderr` write
+        // one variable, so the later option wins.
+        let cases: [(&[&str], Option<bool>); 6] = [
+            (&["--stderr=all"], Some(true)),
+            (&["--stderr=client"], Some(false)),
+            (&["--stderr=errors"], None),
+            (&["--msgs2stderr", "--stderr=errors"], None),
+            (&["--stderr=all", "--no-msgs2stderr"], Some(false)),
+            (&["--no-msgs2stderr", "--stderr=all"], Some(true)),
+        ];
+        for (flags, expected) in cases {
+            let mut args = flags.to_vec();
+            args.extend(["src/", "dst/"]);
+            let parsed = parse_test_args(args).expect("parse");
+            assert_eq!(parsed.msgs_to_stderr, expected, "{flags:?}");
+        }
+    }
+
     #[test]
     fn stderr_mode_accepts_upstream_prefixes() {
         // upstream: options.c:1912 OPT_STDERR accepts any non-empty prefix of
@@ -2836,6 +2864,29 @@ mod time_option_tests {
         let parsed = parse_test_args(["--no-crtimes", "src/", "dst/"]).expect("parse");
         assert_eq!(parsed.crtimes, Some(false));
     }
+
+    #[test]
+    fn fileflags_flag_pair() {
+        let parsed = parse_test_args(["--fileflags", "src/", "dst/"]).expect("parse");
+        assert_eq!(parsed.fileflags, Some(true));
+        let parsed =
+            parse_test_args(["--fileflags", "--no-fileflags", "src/", "dst/"]).expect("parse");
+        assert_eq!(parsed.fileflags, Some(false));
+        let parsed = parse_test_args(["src/", "dst/"]).expect("parse");
+        assert_eq!(parsed.fileflags, None);
+    }
+
+    #[test]
+    fn force_change_variants() {
+        let parsed = parse_test_args(["src/", "dst/"]).expect("parse");
+        assert!(parsed.force_change.is_none());
+        let parsed = parse_test_args(["--force-change", "src/", "dst/"]).expect("parse");
+        assert_eq!(parsed.force_change, ::metadata::ForceChange::ALL);
+        let parsed = parse_test_args(["--force-uchange", "src/", "dst/"]).expect("parse");
+        assert!(parsed.force_change.user() && !parsed.force_change.system());
+        let parsed = parse_test_args(["--force-schange", "src/", "dst/"]).expect("parse");
+        assert!(!parsed.force_change.user() && parsed.force_change.system());
+    }
 }
 
 mod delay_updates_tests {
@@ -1,6 +1,6 @@
 //! Output formatting and verbosity arguments: verbose, quiet, human-readable,
 //! 8-bit-output, msgs2stderr, outbuf, itemize-changes, out-format, progress,
-//! and stats.
+//! stats, and json.
 
 use super::{Arg, ArgAction, ClapCommand, OsStringValueParser};
 
@@ -141,4 +141,12 @@ pub(super) fn add_output_args(command: ClapCommand) -> ClapCommand {
                 .help("Output transfer statistics after completion.")
                 .action(ArgAction::SetTrue),
         )
+        .arg(
+            Arg::new("json")
+                .long("json")
+                .help(
+                    "Print the transfer statistics and file list as one JSON object instead of text (oc-rsync extension).",
+                )
+                .action(ArgAction::SetTrue),
+        )
 }
@@ -22,6 +22,16 @@ pub(crate) fn add_connection_and_logging_options(command: ClapCommand) -> ClapCo
                 .action(ArgAction::SetTrue)
                 .overrides_with("contimeout"),
         )
+        .arg(
+            Arg::new("blocking-timeout")
+                .long("blocking-timeout")
+                .value_name("SECS")
+                .help("Fail any single socket read or write blocked for SECS seconds (0 disables).")
+                .num_args(1)
+                .action(ArgAction::Set)
+                .value_parser(OsStringValueParser::new())
+                .overrides_with("blocking-timeout"),
+        )
         .arg(
             Arg::new("protocol")
                 .long("protocol")
@@ -53,6 +63,15 @@ pub(crate) fn add_connection_and_logging_options(command: ClapCommand) -> ClapCo
                 .action(ArgAction::Set)
                 .value_parser(OsStringValueParser::new()),
         )
+        .arg(
+            Arg::new("tls")
+                .long("tls")
+                .help(
+                    "Wrap daemon connections in TLS (oc-rsync extension; the daemon must \
+                     be oc-rsync with tls cert file/tls key file set).",
+                )
+                .action(ArgAction::SetTrue),
+        )
         .arg(
             Arg::new("blocking-io")
                 .long("blocking-io")
@@ -465,6 +465,43 @@ pub(crate) fn add_transfer_behavior_options(command: ClapCommand) -> ClapCommand
                     .num_args(1)
                     .value_parser(OsStringValueParser::new()),
             )
+            .arg(
+                Arg::new("checksum-cache")
+                    .long("checksum-cache")
+                    .value_name("DIR")
+                    .help(
+                        "Keep --checksum sums in .rsync-checksums side files under DIR and \
+                         reuse them while a file's size and mtime are unchanged. Local-only; \
+                         never sent to the remote.",
+                    )
+                    .num_args(1)
+                    .value_parser(OsStringValueParser::new()),
+            )
+            .arg(
+                Arg::new("checksum-xattr")
+                    .long("checksum-xattr")
+                    .value_name("NAME")
+                    .help(
+                        "Take a file's --checksum sum from its NAME extended attribute (e.g. \
+                         user.rsync.checksum) while the recorded size and mtime still match. \
+                         Applies to this side only; never sent to the remote.",
+                    )
+                    .num_args(1)
+                    .value_parser(OsStringValueParser::new()),
+            )
+            .arg(
+                Arg::new("resume-manifest")
+                    .long("resume-manifest")
+                    .value_name("FILE")
+                    .help(
+                        "Record files a daemon pull completes in FILE; a rerun sends the \
+                         list so an oc-rsync daemon skips files already received. FILE is \
+                         removed once the pull succeeds. Ignored with --delete, --dry-run, \
+                         and --list-only.",
+                    )
+                    .num_args(1)
+                    .value_parser(OsStringValueParser::new()),
+            )
             .arg(
                 Arg::new("tokio-threads")
                     .long("tokio-threads")
@@ -475,6 +512,21 @@ pub(crate) fn add_transfer_behavior_options(command: ClapCommand) -> ClapCommand
                     .num_args(1)
                     .value_parser(OsStringValueParser::new()),
             )
+            .arg(
+                Arg::new("copy-buffer-size")
+                    .long("copy-buffer-size")
+                    .value_name("SIZE")
+                    .help(
+                        "Stream file data through a SIZE-byte buffer: local \
+                         copies that skip the kernel copy fast path, and this \
+                         side's file reads (push) or writes (pull) in a remote \
+                         transfer. Accepts an optional K/M/G suffix (base \
+                         1024); not forwarded to the remote side.",
+                    )
+                    .num_args(1)
+                    .action(ArgAction::Set)
+                    .value_parser(OsStringValueParser::new()),
+            )
             .arg(
                 Arg::new("spill-dir")
                     .long("spill-dir")
@@ -875,6 +927,38 @@ pub(crate) fn add_transfer_behavior_options(command: ClapCommand) -> ClapCommand
                     .action(ArgAction::SetTrue)
                     .overrides_with("crtimes"),
             )
+            .arg(
+                Arg::new("fileflags")
+                    .long("fileflags")
+                    .help("Preserve file flags (chattr/chflags) on local copies.")
+                    .action(ArgAction::SetTrue)
+                    .overrides_with("no-fileflags"),
+            )
+            .arg(
+                Arg::new("no-fileflags")
+                    .long("no-fileflags")
+                    .help("Disable file flag preservation.")
+                    .action(ArgAction::SetTrue)
+                    .overrides_with("fileflags"),
+            )
+            .arg(
+                Arg::new("force-change")
+                    .long("force-change")
+                    .help("Clear user and system immutable flags to update a file.")
+                    .action(ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("force-uchange")
+                    .long("force-uchange")
+                    .help("Clear user immutable flags (uchg/uappnd) to update a file.")
+                    .action(ArgAction::SetTrue),
+            )
+            .arg(
+                Arg::new("force-schange")
+                    .long("force-schange")
+                    .help("Clear system immutable flags (schg, chattr +i) to update a file.")
+                    .action(ArgAction::SetTrue),
+            )
             .arg(
                 Arg::new("acls")
                     .long("acls")
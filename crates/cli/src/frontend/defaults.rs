@@ -8,18 +8,18 @@ pub(super) const SUPPORTED_OPTIONS_LIST: &str = concat!(
     "--remote-option/-M, --protect-args/-s, --no-protect-args, --secluded-args, --no-secluded-args, ",
     "--ipv4, --ipv6, --daemon, --config, --dry-run/-n, --list-only, --archive/-a, --recursive/-r, --no-recursive, ",
     "--dirs/-d, --no-dirs, --delete/--del, --delete-before, --delete-during, --delete-delay, --delete-after, ",
-    "--delete-excluded, --max-delete, --min-size, --max-size, --max-alloc, --block-size, --backup/-b, --backup-dir, ",
+    "--delete-excluded, --max-delete, --min-size, --max-size, --max-alloc, --copy-buffer-size, --block-size, --backup/-b, --backup-dir, ",
     "--suffix, --checksum/-c, --checksum-choice, --checksum-seed, --size-only, --ignore-times, --ignore-existing, --existing, ",
     "--ignore-missing-args, --delete-missing-args, --update/-u, --modify-window, --exclude, --exclude-from, ",
     "--include, --include-from, --compare-dest, --copy-dest, --link-dest, --hard-links/-H, --no-hard-links, ",
     "--cvs-exclude/-C, --apple-double-skip, --filter/-F (including exclude-if-present=FILE), --files-from, --password-file, --password-command, --no-motd, ",
-    "--from0, --no-from0, --bwlimit, --no-bwlimit, --timeout, --contimeout, --stop-after/--time-limit, --stop-at, --sockopts, ",
-    "--tcp-fastopen, --blocking-io, --no-blocking-io, --protocol, --compress/-z, --no-compress, --compress-level, --compress-choice, --compress-threads, ",
+    "--from0, --no-from0, --bwlimit, --no-bwlimit, --timeout, --contimeout, --blocking-timeout, --stop-after/--time-limit, --stop-at, --sockopts, ",
+    "--tcp-fastopen, --tls, --blocking-io, --no-blocking-io, --protocol, --compress/-z, --no-compress, --compress-level, --compress-choice, --compress-threads, ",
     "--skip-compress, --open-noatime, --no-open-noatime, --iconv, --no-iconv, --info, --debug, --verbose/-v, --no-verbose, ",
     "--relative/-R, --no-relative, --one-file-system/-x, --no-one-file-system, --implied-dirs, --no-implied-dirs, ",
     "--mkpath, --no-mkpath, --old-dirs/--old-d, --prune-empty-dirs/-m, --no-prune-empty-dirs, --progress, --no-progress, --quiet, --no-quiet, ",
     "--force, --no-force, --fuzzy/-y, --no-fuzzy, --msgs2stderr, --no-msgs2stderr, --8-bit-output, --outbuf, ",
-    "--itemize-changes/-i, --no-itemize-changes, --out-format, --stats, --partial, --no-partial, --partial-dir, --temp-dir, --log-file, ",
+    "--itemize-changes/-i, --no-itemize-changes, --out-format, --stats, --json, --partial, --no-partial, --partial-dir, --temp-dir, --log-file, ",
     "--log-file-format, --delay-updates, --no-delay-updates, --whole-file/-W, --no-whole-file, --xxh64-dedup, --remove-source-files, ",
     "--remove-sent-files, --append, --no-append, --append-verify, --preallocate, --fsync, --io-uring, --no-io-uring, --no-io-uring-sqpoll, --io-uring-depth, --io-uring-status, --lsm-status, --simd, --cow, --no-cow, --reflink, --zero-copy, --no-zero-copy, --parallel-delta-scan, --inplace, --no-inplace, ",
     "--human-readable/-h, --no-human-readable, -P, --sparse/-S, --no-sparse/--no-S, --sparse-detect, --links/-l, --no-links/--no-l, ",
@@ -27,9 +27,9 @@ pub(super) const SUPPORTED_OPTIONS_LIST: &str = concat!(
     "--copy-unsafe-links, --safe-links, --copy-dirlinks/-k, --keep-dirlinks/-K, ",
     "-D, --devices, --copy-devices, --no-devices, --specials, --no-specials, --super, --no-super, --owner, --no-owner, --group, --no-group, ",
     "--chown, --usermap, --groupmap, --chmod, --executability/-E, --perms/-p, --no-perms, --times/-t, --no-times, ",
-    "--atimes/-U, --no-atimes, --crtimes/-N, --no-crtimes, --omit-dir-times, --no-omit-dir-times, --omit-link-times, --no-omit-link-times, ",
+    "--atimes/-U, --no-atimes, --crtimes/-N, --no-crtimes, --fileflags, --no-fileflags, --force-change, --force-uchange, --force-schange, --omit-dir-times, --no-omit-dir-times, --omit-link-times, --no-omit-link-times, ",
     "--acls/-A, --no-acls, --xattrs/-X, --no-xattrs, ",
-    "--numeric-ids, --no-numeric-ids, --rayon-threads, --checksum-threads, --tokio-threads"
+    "--numeric-ids, --no-numeric-ids, --rayon-threads, --checksum-threads, --checksum-cache, --checksum-xattr, --resume-manifest, --tokio-threads"
 );
 
 /// Format string used for `--itemize-changes` output.
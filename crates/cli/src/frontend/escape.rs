@@ -151,6 +151,53 @@ pub(crate) fn escape_path(path: &Path, allow_8bit: bool) -> Vec<u8> {
     }
 }
 
+/// Renders a path as a Unicode string without losing any of its bytes.
+///
+/// Used by `--json`, whose strings must be valid Unicode. Well-formed UTF-8
+/// passes through unchanged, control characters included (the JSON encoder
+/// escapes those). Each byte that is not valid UTF-8 becomes `\#ooo`, and the
+/// backslash of a literal `\#ddd` becomes `\#134`, so a consumer can recover
+/// the exact name with the same rule that decodes the text listing. On
+/// non-Unix platforms the path goes through `to_string_lossy()` first and
+/// separators are normalised to `/`.
+pub(crate) fn escape_path_lossless(path: &Path) -> String {
+    #[cfg(unix)]
+    {
+        use std::os::unix::ffi::OsStrExt;
+        escape_invalid_utf8(path.as_os_str().as_bytes())
+    }
+    #[cfg(not(unix))]
+    {
+        let lossy = path.to_string_lossy().replace('\\', "/");
+        escape_invalid_utf8(lossy.as_bytes())
+    }
+}
+
+fn escape_invalid_utf8(input: &[u8]) -> String {
+    let mut output = String::with_capacity(input.len());
+    for chunk in input.utf8_chunks() {
+        let valid = chunk.valid();
+        let bytes = valid.as_bytes();
+        for (i, ch) in valid.char_indices() {
+            // A `\#ddd` sequence is pure ASCII, so it never straddles chunks.
+            if ch == '\\'
+                && bytes.get(i + 1) == Some(&b'#')
+                && bytes
+                    .get(i + 2..i + 5)
+                    .is_some_and(|digits| digits.iter().all(u8::is_ascii_digit))
+            {
+                output.push_str("\\#134");
+            } else {
+                output.push(ch);
+            }
+        }
+        for byte in chunk.invalid() {
+            output.push_str(&format!("\\#{byte:03o}"));
+        }
+    }
+    output
+}
+
 /// Escapes a string for display output, returning raw bytes.
 ///
 /// Convenience wrapper for already-converted strings (e.g. from
@@ -342,6 +389,30 @@ mod tests {
         assert_eq!(escape_path(path, false), b"foo/bar/baz.txt".to_vec());
     }
 
+    // -- escape_path_lossless --
+
+    #[test]
+    fn lossless_path_keeps_valid_utf8_and_control_characters() {
+        let path = Path::new("caf\u{e9}/a\nb");
+        assert_eq!(escape_path_lossless(path), "caf\u{e9}/a\nb");
+    }
+
+    #[test]
+    fn lossless_path_guards_literal_escape_sequences() {
+        let path = Path::new("a\\#001b");
+        assert_eq!(escape_path_lossless(path), "a\\#134#001b");
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn lossless_path_escapes_only_invalid_utf8_bytes() {
+        use std::ffi::OsStr;
+        use std::os::unix::ffi::OsStrExt;
+
+        let path = Path::new(OsStr::from_bytes(b"bad\xff\xc3\xa9.txt"));
+        assert_eq!(escape_path_lossless(path), "bad\\#377\u{e9}.txt");
+    }
+
     // -- escape_str --
 
     #[test]
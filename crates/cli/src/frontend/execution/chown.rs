@@ -31,10 +31,36 @@ impl ParsedChown {
     }
 
     /// Returns the original spec string.
-    #[allow(dead_code)] // REASON: accessor used in unit tests
     pub(crate) const fn spec(&self) -> &OsString {
         &self.spec
     }
+
+    /// Returns the `--usermap` rule this spec stands for (`*:USER`), if it
+    /// named a user.
+    ///
+    /// The user is kept as written so a name is resolved by whichever side
+    /// receives, exactly as an explicit `--usermap` would be.
+    pub(crate) fn usermap_spec(&self) -> Option<String> {
+        self.owner?;
+        let (user, _) = self.split_spec();
+        Some(format!("*:{user}"))
+    }
+
+    /// Returns the `--groupmap` rule this spec stands for (`*:GROUP`), if it
+    /// named a group.
+    pub(crate) fn groupmap_spec(&self) -> Option<String> {
+        self.group?;
+        let (_, group) = self.split_spec();
+        Some(format!("*:{group}"))
+    }
+
+    fn split_spec(&self) -> (String, String) {
+        let text = self.spec.to_string_lossy();
+        match text.split_once(':') {
+            Some((user, group)) => (user.to_owned(), group.to_owned()),
+            None => (text.into_owned(), String::new()),
+        }
+    }
 }
 
 /// Parses a `--chown=USER:GROUP` spec, resolving names to numeric IDs.
@@ -193,6 +219,21 @@ mod tests {
         assert_eq!(parsed, cloned);
     }
 
+    #[test]
+    fn mapping_specs_cover_named_fields() {
+        let both = parse_chown_argument(OsStr::new("1000:2000")).expect("parse");
+        assert_eq!(both.usermap_spec().as_deref(), Some("*:1000"));
+        assert_eq!(both.groupmap_spec().as_deref(), Some("*:2000"));
+
+        let user_only = parse_chown_argument(OsStr::new("1000")).expect("parse");
+        assert_eq!(user_only.usermap_spec().as_deref(), Some("*:1000"));
+        assert!(user_only.groupmap_spec().is_none());
+
+        let group_only = parse_chown_argument(OsStr::new(":2000")).expect("parse");
+        assert!(group_only.usermap_spec().is_none());
+        assert_eq!(group_only.groupmap_spec().as_deref(), Some("*:2000"));
+    }
+
     #[test]
     fn parse_chown_argument_numeric_user_only() {
         let result = parse_chown_argument(OsStr::new("1000")).expect("parse");
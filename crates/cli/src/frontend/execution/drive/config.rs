@@ -23,11 +23,14 @@ pub(crate) struct ConfigInputs {
     /// Optional `--protocol=N` ceiling that caps the negotiated protocol version
     /// for remote (SSH and daemon) transfers. `None` uses the default ceiling.
     pub(crate) desired_protocol: Option<protocol::ProtocolVersion>,
+    /// `--port=N` used for daemon operands that do not embed a port.
+    pub(crate) daemon_port: Option<u16>,
     pub(crate) address_mode: AddressMode,
     pub(crate) connect_program: Option<OsString>,
     pub(crate) bind_address: Option<core::client::BindAddress>,
     pub(crate) sockopts: Option<OsString>,
     pub(crate) tcp_fastopen: TcpFastOpenMode,
+    pub(crate) tls: bool,
     pub(crate) blocking_io: Option<bool>,
     pub(crate) dry_run: bool,
     pub(crate) list_only: bool,
@@ -79,6 +82,8 @@ pub(crate) struct ConfigInputs {
     /// Access-time preservation level (0 = off, 1 = `-U`, 2 = `-UU`).
     pub(crate) atimes: u8,
     pub(crate) crtimes: bool,
+    pub(crate) fileflags: bool,
+    pub(crate) force_change: ::metadata::ForceChange,
     pub(crate) modify_window_setting: Option<i64>,
     pub(crate) omit_dir_times: bool,
     pub(crate) omit_link_times: bool,
@@ -139,6 +144,12 @@ pub(crate) struct ConfigInputs {
     pub(crate) cow_policy: fast_io::CowPolicy,
     pub(crate) partial_dir: Option<PathBuf>,
     pub(crate) temp_dir: Option<PathBuf>,
+    /// `--checksum-cache=DIR` - persistent `--checksum` sums for local copies.
+    pub(crate) checksum_cache: Option<PathBuf>,
+    /// `--checksum-xattr=NAME` - attribute holding precomputed `--checksum` sums.
+    pub(crate) checksum_xattr: Option<OsString>,
+    /// `--resume-manifest=FILE` - completed-file record for daemon pulls.
+    pub(crate) resume_manifest: Option<PathBuf>,
     pub(crate) delay_updates: bool,
     pub(crate) link_dests: Vec<PathBuf>,
     pub(crate) remove_source_files: bool,
@@ -166,6 +177,7 @@ pub(crate) struct ConfigInputs {
     pub(crate) xxh64_dedup: bool,
     pub(crate) timeout: TransferTimeout,
     pub(crate) connect_timeout: TransferTimeout,
+    pub(crate) blocking_timeout: TransferTimeout,
     pub(crate) stop_deadline: Option<SystemTime>,
     pub(crate) checksum_choice: Option<StrongChecksumChoice>,
     pub(crate) compare_destinations: Vec<OsString>,
@@ -183,6 +195,9 @@ pub(crate) struct ConfigInputs {
     /// `-C` / `--cvs-exclude` request, forwarded to the peer as the `C` letter.
     pub(crate) cvs_exclude: bool,
     pub(crate) itemize_changes: bool,
+    /// `--json`: the JSON summary lists transferred entries, so events are
+    /// collected even without `-v` or `-i`.
+    pub(crate) json: bool,
     pub(crate) out_format_template: Option<crate::frontend::out_format::OutFormat>,
     pub(crate) log_file_template: Option<crate::frontend::out_format::OutFormat>,
     pub(crate) name_level: NameOutputLevel,
@@ -202,6 +217,8 @@ pub(crate) struct ConfigInputs {
     pub(crate) daemon_params: Vec<String>,
     pub(crate) files_from: FilesFromSource,
     pub(crate) from0: bool,
+    /// `--copy-buffer-size` buffer length for streamed file data.
+    pub(crate) copy_buffer_size: Option<usize>,
     /// CLI override for the reorder-buffer spill directory.
     ///
     /// `Some` replaces both the env-var value and the `SpillPolicy::dir`
@@ -228,11 +245,13 @@ pub(crate) fn build_base_config(mut inputs: ConfigInputs) -> ClientConfigBuilder
     let mut builder = ClientConfig::builder()
         .transfer_args(std::mem::take(&mut inputs.transfer_operands))
         .protocol_version(inputs.desired_protocol)
+        .daemon_port(inputs.daemon_port)
         .address_mode(inputs.address_mode)
         .connect_program(inputs.connect_program.clone())
         .bind_address(inputs.bind_address.clone())
         .sockopts(inputs.sockopts.clone())
         .tcp_fastopen(inputs.tcp_fastopen)
+        .tls(inputs.tls)
         .blocking_io(inputs.blocking_io)
         .dry_run(inputs.dry_run)
         .list_only(inputs.list_only)
@@ -287,6 +306,8 @@ pub(crate) fn build_base_config(mut inputs: ConfigInputs) -> ClientConfigBuilder
         .times(inputs.times)
         .atimes(inputs.atimes)
         .crtimes(inputs.crtimes)
+        .fileflags(inputs.fileflags)
+        .force_change(inputs.force_change)
         .modify_window(inputs.modify_window_setting)
         .omit_dir_times(inputs.omit_dir_times)
         .omit_link_times(inputs.omit_link_times)
@@ -345,6 +366,9 @@ pub(crate) fn build_base_config(mut inputs: ConfigInputs) -> ClientConfigBuilder
         .cow_policy(inputs.cow_policy)
         .partial_directory(inputs.partial_dir.clone())
         .temp_directory(inputs.temp_dir.clone())
+        .checksum_cache_directory(inputs.checksum_cache.clone())
+        .checksum_xattr_name(inputs.checksum_xattr.clone())
+        .resume_manifest(inputs.resume_manifest.clone())
         .delay_updates(inputs.delay_updates)
         .extend_link_dests(inputs.link_dests.clone())
         .remove_source_files(inputs.remove_source_files)
@@ -360,6 +384,7 @@ pub(crate) fn build_base_config(mut inputs: ConfigInputs) -> ClientConfigBuilder
         .xxh64_dedup(inputs.xxh64_dedup)
         .timeout(inputs.timeout)
         .connect_timeout(inputs.connect_timeout)
+        .blocking_timeout(inputs.blocking_timeout)
         .stop_at(inputs.stop_deadline)
         .iconv(inputs.iconv.clone());
 
@@ -448,6 +473,7 @@ pub(crate) fn build_base_config(mut inputs: ConfigInputs) -> ClientConfigBuilder
     ));
 
     let force_event_collection = inputs.itemize_changes
+        || inputs.json
         || inputs.out_format_template.is_some()
         || inputs.log_file_template.is_some()
         || !matches!(inputs.name_level, NameOutputLevel::Disabled);
@@ -455,6 +481,7 @@ pub(crate) fn build_base_config(mut inputs: ConfigInputs) -> ClientConfigBuilder
     builder = builder.files_from(inputs.files_from).from0(inputs.from0);
 
     builder = builder
+        .copy_buffer_size(inputs.copy_buffer_size)
         .spill_dir(inputs.spill_dir)
         .spill_threshold_bytes(inputs.spill_threshold_bytes)
         .no_spill(inputs.no_spill);
@@ -23,8 +23,8 @@ use crate::frontend::filter_rules::{
 /// order, mirroring upstream options.c.
 pub(crate) struct FilterInputs {
     pub(crate) order: Vec<FilterOrderToken>,
-    /// `--from0`/`-0`: makes `--exclude-from`/`--include-from` files
-    /// NUL-delimited (upstream exclude.c:1501 parse_filter_file eol_nulls).
+    /// `--from0`/`-0`: makes `--exclude-from`/`--include-from` files and
+    /// `--filter` merge files NUL-delimited (upstream exclude.c:1501 parse_filter_file eol_nulls).
     pub(crate) from0: bool,
 }
 
@@ -73,6 +73,7 @@ where
                 &rule,
                 merge_base.as_path(),
                 &mut merge_stack,
+                from0,
             ),
             FilterOrderToken::CvsExclude => append_cvs_exclude_rules(&mut filter_rules),
             FilterOrderToken::AppleDoubleSkip => {
@@ -97,6 +98,7 @@ fn push_filter_directive(
     rule: &OsString,
     merge_base: &std::path::Path,
     merge_stack: &mut HashSet<PathBuf>,
+    eol_nulls: bool,
 ) -> Result<(), Message> {
     match parse_filter_directive(rule.as_os_str())? {
         FilterDirective::Rule(spec) => filter_rules.push(spec),
@@ -104,7 +106,7 @@ fn push_filter_directive(
             let effective_options =
                 merge_directive_options(&DirMergeOptions::default(), &directive);
             let directive = directive.with_options(effective_options);
-            apply_merge_directive(directive, merge_base, filter_rules, merge_stack)?;
+            apply_merge_directive(directive, merge_base, filter_rules, merge_stack, eol_nulls)?;
         }
         FilterDirective::Clear => filter_rules.clear(),
         FilterDirective::CvsDefaults => filter_rules.extend(cvs_default_exclude_rules()?),
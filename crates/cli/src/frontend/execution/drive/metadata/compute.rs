@@ -5,7 +5,9 @@
 
 use ::metadata::ChmodModifiers;
 
-use super::mapping::{parse_group_mapping, parse_user_mapping};
+use super::mapping::{
+    chown_group_mapping, chown_user_mapping, parse_group_mapping, parse_user_mapping,
+};
 use super::types::{MetadataInputs, MetadataSettings};
 
 /// Computes metadata preservation flags according to upstream semantics.
@@ -46,14 +48,22 @@ pub(crate) fn compute_metadata_settings(
         chmod,
     } = inputs;
 
+    // `--chown` is shorthand for `--usermap=*:USER` / `--groupmap=*:GROUP`;
+    // naming the same field through both is rejected by the parsers.
     let user_mapping = match usermap {
         Some(value) => Some(parse_user_mapping(value, parsed_chown)?),
-        None => None,
+        None => match parsed_chown {
+            Some(parsed) => chown_user_mapping(parsed)?,
+            None => None,
+        },
     };
 
     let group_mapping = match groupmap {
         Some(value) => Some(parse_group_mapping(value, parsed_chown)?),
-        None => None,
+        None => match parsed_chown {
+            Some(parsed) => chown_group_mapping(parsed)?,
+            None => None,
+        },
     };
 
     let preserve_owner =
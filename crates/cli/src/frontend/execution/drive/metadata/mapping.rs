@@ -133,6 +133,53 @@ pub(super) fn parse_group_mapping(
     parser.parse(value, parsed_chown)
 }
 
+/// Builds the [`UserMapping`] that `--chown=USER` stands for, if the spec
+/// named a user.
+///
+/// upstream: options.c:parse_arguments() `OPT_CHOWN` rewrites `--chown=USER`
+/// into `usermap = "*:USER"`, so the owner reaches a remote receiver through
+/// the same `--usermap` argument an explicit mapping uses.
+#[cfg(unix)]
+pub(super) fn chown_user_mapping(
+    parsed_chown: &ParsedChown,
+) -> Result<Option<UserMapping>, core::message::Message> {
+    parsed_chown
+        .usermap_spec()
+        .map(|spec| parse_mapping_impl(&OsString::from(spec), MappingKind::User))
+        .transpose()
+}
+
+/// Windows builds have no name mappings; `--chown` applies locally through
+/// the numeric ownership override alone.
+#[cfg(windows)]
+pub(super) fn chown_user_mapping(
+    parsed_chown: &ParsedChown,
+) -> Result<Option<UserMapping>, core::message::Message> {
+    let _ = parsed_chown;
+    Ok(None)
+}
+
+/// Builds the [`GroupMapping`] that `--chown=:GROUP` stands for, if the spec
+/// named a group (`OPT_CHOWN` sets `groupmap = "*:GROUP"`).
+#[cfg(unix)]
+pub(super) fn chown_group_mapping(
+    parsed_chown: &ParsedChown,
+) -> Result<Option<GroupMapping>, core::message::Message> {
+    parsed_chown
+        .groupmap_spec()
+        .map(|spec| parse_mapping_impl(&OsString::from(spec), MappingKind::Group))
+        .transpose()
+}
+
+/// Windows counterpart of [`chown_group_mapping`]; see [`chown_user_mapping`].
+#[cfg(windows)]
+pub(super) fn chown_group_mapping(
+    parsed_chown: &ParsedChown,
+) -> Result<Option<GroupMapping>, core::message::Message> {
+    let _ = parsed_chown;
+    Ok(None)
+}
+
 #[cfg(unix)]
 fn parse_mapping_impl<M>(value: &OsString, kind: MappingKind) -> Result<M, core::message::Message>
 where
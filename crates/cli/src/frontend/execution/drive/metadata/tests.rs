@@ -431,3 +431,54 @@ fn metadata_inputs_default_helper() {
     assert!(inputs.owner.is_none());
     assert!(inputs.chmod.is_empty());
 }
+
+#[cfg(unix)]
+#[test]
+fn chown_implies_wildcard_user_and_group_mappings() {
+    use crate::frontend::execution::chown::parse_chown_argument;
+
+    let parsed = parse_chown_argument(std::ffi::OsStr::new("1000:2000")).unwrap();
+    let inputs = MetadataInputs {
+        parsed_chown: Some(&parsed),
+        ..default_inputs()
+    };
+    let result = compute_metadata_settings(inputs).unwrap();
+
+    assert!(result.preserve_owner);
+    assert!(result.preserve_group);
+    assert_eq!(result.user_mapping.expect("usermap").spec(), "*:1000");
+    assert_eq!(result.group_mapping.expect("groupmap").spec(), "*:2000");
+}
+
+#[cfg(unix)]
+#[test]
+fn chown_group_only_composes_with_explicit_usermap() {
+    use crate::frontend::execution::chown::parse_chown_argument;
+
+    let parsed = parse_chown_argument(std::ffi::OsStr::new(":2000")).unwrap();
+    let usermap = OsString::from("0:1000");
+    let inputs = MetadataInputs {
+        parsed_chown: Some(&parsed),
+        usermap: Some(&usermap),
+        ..default_inputs()
+    };
+    let result = compute_metadata_settings(inputs).unwrap();
+
+    assert_eq!(result.user_mapping.expect("usermap").spec(), "0:1000");
+    assert_eq!(result.group_mapping.expect("groupmap").spec(), "*:2000");
+}
+
+#[cfg(unix)]
+#[test]
+fn chown_user_conflicts_with_explicit_usermap() {
+    use crate::frontend::execution::chown::parse_chown_argument;
+
+    let parsed = parse_chown_argument(std::ffi::OsStr::new("1000")).unwrap();
+    let usermap = OsString::from("*:0");
+    let inputs = MetadataInputs {
+        parsed_chown: Some(&parsed),
+        usermap: Some(&usermap),
+        ..default_inputs()
+    };
+    assert!(compute_metadata_settings(inputs).is_err());
+}
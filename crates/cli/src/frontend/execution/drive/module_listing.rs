@@ -28,6 +28,7 @@ pub(super) struct ModuleListingInputs<'a> {
     pub connect_timeout_setting: TransferTimeout,
     pub sockopts: Option<&'a OsString>,
     pub tcp_fastopen: TcpFastOpenMode,
+    pub tls: bool,
     pub blocking_io: Option<bool>,
 }
 
@@ -59,6 +60,7 @@ where
         connect_timeout_setting,
         sockopts,
         tcp_fastopen,
+        tls,
         blocking_io,
     } = inputs;
 
@@ -99,6 +101,7 @@ where
         .with_rsync_path(rsync_path.cloned())
         .with_sockopts(sockopts.cloned())
         .with_tcp_fastopen(tcp_fastopen)
+        .with_tls(tls)
         .with_blocking_io(blocking_io);
 
     match run_module_list_with_password_and_options(
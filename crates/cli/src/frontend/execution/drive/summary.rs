@@ -17,7 +17,7 @@ use crate::frontend::{
     out_format::{OutFormat, OutFormatContext},
     progress::{
         LiveProgress, NameOutputLevel, ProgressMode, ProgressOutputConfig, StderrMode,
-        emit_transfer_summary,
+        emit_json_summary, emit_list_only, emit_list_only_trailer, emit_transfer_summary,
     },
 };
 
@@ -64,6 +64,9 @@ pub(crate) struct TransferExecutionInputs<'a> {
     /// octal escaping. When false (the default), non-printable bytes in
     /// filenames are escaped as `\#ooo` matching upstream log.c:filtered_fwrite.
     pub(crate) eight_bit_output: bool,
+    /// `--json`: replace the stdout summary and listing with one JSON object.
+    /// The log file keeps the text format.
+    pub(crate) json: bool,
     pub(crate) log_file: Option<LogFileConfig>,
 }
 
@@ -98,6 +101,7 @@ where
         name_level,
         name_overridden,
         eight_bit_output,
+        json,
         log_file,
     } = inputs;
 
@@ -189,30 +193,70 @@ where
                 .with_eight_bit_output(eight_bit_output)
                 .with_preserve_links(preserve_links)
                 .with_full_checksum(full_checksum_algorithm, always_checksum);
-            if let Err(error) = with_output_writer(stdout, stderr, msgs_to_stderr, |writer| {
-                emit_transfer_summary(
-                    &summary,
-                    verbosity,
-                    requested_progress_mode,
-                    stats_level,
-                    progress_rendered_live,
-                    list_only,
-                    dry_run,
-                    only_write_batch,
-                    out_format_template,
-                    &out_format_context,
-                    name_level,
-                    name_overridden,
+            if json {
+                if let Err(error) = emit_json_summary(&summary, list_only, stdout) {
+                    let _ = with_output_writer(stdout, stderr, msgs_to_stderr, |writer| {
+                        writeln!(writer, "warning: failed to render JSON summary: {error}")
+                    });
+                }
+            } else if list_only && msgs_to_stderr {
+                // The listing is the data the user asked for, not a message,
+                // so it stays on stdout; only the `-v` totals and `--stats`
+                // block follow the other messages to stderr.
+                let listed = emit_list_only(
+                    summary.events(),
+                    stdout,
                     human_readable_mode,
-                    suppress_updated_only_totals,
-                    emit_flist_banner,
-                    show_copy_method,
                     show_atimes,
                     show_crtimes,
                     eight_bit_output,
-                    writer,
+                    preserve_links,
                 )
-            }) {
+                .and_then(|()| stdout.flush());
+                if let Err(error) = listed.and_then(|()| {
+                    emit_list_only_trailer(
+                        &summary,
+                        verbosity,
+                        stats_level,
+                        false,
+                        dry_run,
+                        only_write_batch,
+                        human_readable_mode,
+                        show_copy_method,
+                        stderr.writer_mut(),
+                    )
+                }) {
+                    let _ = writeln!(
+                        stderr.writer_mut(),
+                        "warning: failed to render transfer summary: {error}"
+                    );
+                }
+            } else if let Err(error) =
+                with_output_writer(stdout, stderr, msgs_to_stderr, |writer| {
+                    emit_transfer_summary(
+                        &summary,
+                        verbosity,
+                        requested_progress_mode,
+                        stats_level,
+                        progress_rendered_live,
+                        list_only,
+                        dry_run,
+                        only_write_batch,
+                        out_format_template,
+                        &out_format_context,
+                        name_level,
+                        name_overridden,
+                        human_readable_mode,
+                        suppress_updated_only_totals,
+                        emit_flist_banner,
+                        show_copy_method,
+                        show_atimes,
+                        show_crtimes,
+                        eight_bit_output,
+                        writer,
+                    )
+                })
+            {
                 let _ = with_output_writer(stdout, stderr, msgs_to_stderr, |writer| {
                     writeln!(
                         writer,
@@ -19,6 +19,12 @@
 //! | `--acls`, `-A` | `acl` (CLI) -> `core/acl` | `preflight.rs:234` ([`validate_feature_support`]) | `POSIX ACLs are not supported on this client` |
 //! | `--xattrs`, `-X` | `xattr` (CLI) -> `core/xattr` | `preflight.rs:245` ([`validate_feature_support`]) | `extended attributes are not supported on this client` |
 //!
+//! `--crtimes`, `-N` is not rejected: on hosts other than macOS and Windows
+//! [`warn_unsupported_crtimes`] prints `--crtimes is not supported on this
+//! platform; creation times will not be preserved` and the run continues.
+//! `--fileflags` is handled the same way by [`warn_unsupported_fileflags`]
+//! when the host has no file flags or the transfer involves a remote peer.
+//!
 //! Cfg expression for both gates: `not(all(any(unix, windows), feature = "<feat>"))`.
 //! The gate fires only when the user explicitly opts in to the flag
 //! (`preserve_acls=true` or `xattrs=Some(true)`); flag-off paths are
@@ -41,6 +47,8 @@
 //! pair the cfg gates currently support.
 
 use crate::frontend::{arguments::ProgramName, render_help, render_lsm_status_text};
+#[cfg(not(any(target_os = "macos", windows)))]
+use core::rsync_warning;
 use core::{
     client::{BindAddress, TransferTimeout},
     message::Role,
@@ -57,6 +65,8 @@ use super::super::super::{
     ProtocolArg, legacy_remote_rejection, parse_bind_address_argument, parse_protocol_version_arg,
     parse_timeout_argument,
 };
+#[cfg(not(any(target_os = "macos", windows)))]
+use super::super::messages::emit_message_with_fallback;
 #[cfg(any(
     not(all(any(unix, windows), feature = "acl")),
     not(all(any(unix, windows), feature = "xattr"))
@@ -258,6 +268,77 @@ where
     Ok(())
 }
 
+/// Warns once when `--crtimes` is requested on a platform that cannot set
+/// creation times.
+///
+/// Upstream refuses `-N` outright when built without `SUPPORT_CRTIMES`; we
+/// accept it so the same command line works against a macOS or Windows peer,
+/// and the local side leaves creation times untouched.
+pub(crate) fn warn_unsupported_crtimes<Err>(preserve_crtimes: bool, stderr: &mut MessageSink<Err>)
+where
+    Err: Write,
+{
+    #[cfg(not(any(target_os = "macos", windows)))]
+    if preserve_crtimes {
+        let message = rsync_warning!(CRTIMES_UNSUPPORTED_WARNING).with_role(Role::Client);
+        let fallback = format!("rsync warning: {CRTIMES_UNSUPPORTED_WARNING}");
+        emit_message_with_fallback(&message, &fallback, stderr);
+    }
+
+    #[cfg(any(target_os = "macos", windows))]
+    let _ = (preserve_crtimes, stderr);
+}
+
+/// Warning text emitted by [`warn_unsupported_crtimes`].
+#[cfg(not(any(target_os = "macos", windows)))]
+const CRTIMES_UNSUPPORTED_WARNING: &str =
+    "--crtimes is not supported on this platform; creation times will not be preserved";
+
+/// Warns once per option when `--fileflags` or `--force-change` is requested
+/// but cannot take effect.
+///
+/// File flags are handled by the local copy engine only; the wire protocol
+/// does not carry them, so a transfer with a remote operand leaves the
+/// destination's flags alone. Hosts without `chattr`/`chflags` support never
+/// touch them.
+pub(crate) fn warn_unsupported_fileflags<Err>(
+    preserve_fileflags: bool,
+    force_change: bool,
+    has_remote_operand: bool,
+    stderr: &mut MessageSink<Err>,
+) where
+    Err: Write,
+{
+    let requested = [
+        (
+            preserve_fileflags,
+            "--fileflags",
+            "file flags will not be preserved",
+        ),
+        (
+            force_change,
+            "--force-change",
+            "protected files will not be unlocked",
+        ),
+    ];
+    for (enabled, option, consequence) in requested {
+        if !enabled {
+            continue;
+        }
+        let reason = if !::metadata::file_flags_supported() {
+            "is not supported on this platform"
+        } else if has_remote_operand {
+            "is only supported for local copies"
+        } else {
+            continue;
+        };
+        let text = format!("{option} {reason}; {consequence}");
+        let fallback = format!("rsync warning: {text}");
+        let message = rsync_warning!(text).with_role(Role::Client);
+        emit_message_with_fallback(&message, &fallback, stderr);
+    }
+}
+
 #[cfg(test)]
 mod tests {
     //! Parameterized regression suite for platform-feature preflight gating.
@@ -272,10 +353,11 @@ mod tests {
     //! `*-not-supported-on-this-client` rejection.
     //!
     //! Scope is limited to the two flags `validate_feature_support` actually
-    //! gates: `preserve_acls` and `xattrs`. Other feature-flag pairs (e.g.
-    //! crtimes, atimes, hard-links) are gated elsewhere and out of scope.
+    //! gates: `preserve_acls` and `xattrs`, plus the non-fatal `--crtimes`
+    //! platform warning. Other feature-flag pairs (e.g. atimes, hard-links)
+    //! are gated elsewhere and out of scope.
 
-    use super::validate_feature_support;
+    use super::{validate_feature_support, warn_unsupported_crtimes, warn_unsupported_fileflags};
     use logging_sink::MessageSink;
 
     const ACL_REJECTION: &str = "POSIX ACLs are not supported on this client";
@@ -403,4 +485,75 @@ mod tests {
             "xattrs=Some(1) without xattr feature",
         );
     }
+
+    // --- `--crtimes`: warn but continue where creation times are unsupported ---
+
+    fn crtimes_stderr(preserve_crtimes: bool) -> String {
+        let mut sink = MessageSink::new(Vec::<u8>::new());
+        warn_unsupported_crtimes(preserve_crtimes, &mut sink);
+        String::from_utf8_lossy(sink.writer()).into_owned()
+    }
+
+    /// Linux and other hosts without a birth-time setter: `--crtimes` emits a
+    /// warning instead of failing the run.
+    #[cfg(not(any(target_os = "macos", windows)))]
+    #[test]
+    fn crtimes_warns_on_unsupported_platform() {
+        let stderr = crtimes_stderr(true);
+        assert!(
+            stderr.contains("warning") && stderr.contains("--crtimes is not supported"),
+            "expected --crtimes warning, got: {stderr}"
+        );
+        assert!(crtimes_stderr(false).is_empty());
+    }
+
+    /// macOS and Windows set creation times natively, so `--crtimes` is silent.
+    #[cfg(any(target_os = "macos", windows))]
+    #[test]
+    fn crtimes_silent_on_supported_platform() {
+        assert!(crtimes_stderr(true).is_empty());
+    }
+
+    // --- `--fileflags` / `--force-change`: warn when flags cannot be touched ---
+
+    fn fileflags_stderr(
+        preserve_fileflags: bool,
+        force_change: bool,
+        has_remote_operand: bool,
+    ) -> String {
+        let mut sink = MessageSink::new(Vec::<u8>::new());
+        warn_unsupported_fileflags(
+            preserve_fileflags,
+            force_change,
+            has_remote_operand,
+            &mut sink,
+        );
+        String::from_utf8_lossy(sink.writer()).into_owned()
+    }
+
+    #[test]
+    fn fileflags_warns_for_remote_transfer() {
+        let stderr = fileflags_stderr(true, false, true);
+        assert!(
+            stderr.contains("warning") && stderr.contains("--fileflags is"),
+            "expected --fileflags warning, got: {stderr}"
+        );
+        assert!(!stderr.contains("--force-change"));
+        assert!(fileflags_stderr(false, false, true).is_empty());
+    }
+
+    #[test]
+    fn force_change_warns_for_remote_transfer() {
+        let stderr = fileflags_stderr(false, true, true);
+        assert!(
+            stderr.contains("--force-change is") && !stderr.contains("--fileflags"),
+            "expected --force-change warning, got: {stderr}"
+        );
+    }
+
+    #[test]
+    fn fileflags_local_copy_warns_only_without_platform_support() {
+        let stderr = fileflags_stderr(true, true, false);
+        assert_eq!(stderr.is_empty(), ::metadata::file_flags_supported());
+    }
 }
@@ -3,7 +3,8 @@
 use super::operands::ensure_transfer_operands_present;
 use super::preflight::{
     maybe_print_help_or_version, resolve_bind_address, resolve_desired_protocol, resolve_timeout,
-    validate_feature_support, validate_stdin_sources_conflict,
+    validate_feature_support, validate_stdin_sources_conflict, warn_unsupported_crtimes,
+    warn_unsupported_fileflags,
 };
 use crate::frontend::execution::drive::messages::fail_with_message;
 use crate::frontend::execution::drive::metadata::MetadataSettings;
@@ -66,6 +67,7 @@ where
         bind_address: bind_address_raw,
         sockopts,
         tcp_fastopen,
+        tls,
         blocking_io,
         archive,
         recursive,
@@ -123,6 +125,8 @@ where
         omit_link_times,
         atimes,
         crtimes,
+        fileflags,
+        force_change,
         acls,
         excludes: _,
         includes: _,
@@ -175,6 +179,7 @@ where
         name_level: initial_name_level,
         name_overridden: initial_name_overridden,
         stats,
+        json,
         eight_bit_output,
         partial,
         preallocate,
@@ -188,6 +193,9 @@ where
         delay_updates,
         partial_dir,
         temp_dir,
+        checksum_cache,
+        checksum_xattr,
+        resume_manifest,
         log_file,
         log_file_format,
         write_batch,
@@ -215,6 +223,7 @@ where
         protocol,
         timeout,
         contimeout,
+        blocking_timeout,
         stop_after,
         stop_at,
         out_format,
@@ -233,6 +242,7 @@ where
         rayon_threads,
         tokio_threads,
         checksum_threads,
+        copy_buffer_size,
         spill_dir,
         spill_threshold_bytes,
         no_spill,
@@ -264,10 +274,10 @@ where
         .and_then(StderrMode::from_str)
         .unwrap_or_default();
 
-    let msgs_to_stderr_enabled = match stderr_mode_setting {
-        StderrMode::All => true,
-        StderrMode::Errors | StderrMode::Client => msgs_to_stderr_option.unwrap_or(false),
-    };
+    // The parser already folded `--stderr` into the `--[no-]msgs2stderr`
+    // tri-state in command-line order, so only `all` (or a later
+    // `--msgs2stderr`) sends messages to stderr here.
+    let msgs_to_stderr_enabled = msgs_to_stderr_option.unwrap_or(false);
     // Record the resolved routing so the post-execute final flush in
     // `frontend::mod` directs any leftover diagnostic events (e.g. the
     // backup notice emitted by the local-copy executor) to the same stream
@@ -338,6 +348,11 @@ where
         Err(code) => return code,
     };
 
+    let blocking_timeout_setting = match resolve_timeout(blocking_timeout.as_ref(), stderr) {
+        Ok(setting) => setting,
+        Err(code) => return code,
+    };
+
     let stop_request = if let Some(value) = stop_after.as_ref() {
         match parse_stop_after_argument(value.as_os_str()) {
             Ok(deadline) => Some(StopRequest::new_stop_after(value.clone(), deadline)),
@@ -546,6 +561,7 @@ where
             connect_timeout_setting,
             sockopts: sockopts.as_ref(),
             tcp_fastopen,
+            tls,
             blocking_io,
         },
     ) {
@@ -832,6 +848,15 @@ where
         group_mapping,
     } = metadata;
 
+    warn_unsupported_crtimes(preserve_crtimes, stderr);
+    let preserve_fileflags = fileflags.unwrap_or(false);
+    warn_unsupported_fileflags(
+        preserve_fileflags,
+        !force_change.is_none(),
+        has_remote_operand,
+        stderr,
+    );
+
     let prune_empty_dirs_flag = prune_empty_dirs.unwrap_or(false);
     let fsync_flag = fsync_option.unwrap_or(false);
     // upstream: options.c:2413-2419 - `--write-devices` forces the global
@@ -870,11 +895,13 @@ where
     let config_inputs = config::ConfigInputs {
         transfer_operands,
         desired_protocol,
+        daemon_port,
         address_mode,
         connect_program: connect_program.clone(),
         bind_address,
         sockopts: sockopts.clone(),
         tcp_fastopen,
+        tls,
         blocking_io,
         dry_run,
         list_only,
@@ -925,6 +952,8 @@ where
         // application.
         atimes: atimes.unwrap_or(0),
         crtimes: preserve_crtimes,
+        fileflags: preserve_fileflags,
+        force_change,
         modify_window_setting,
         omit_dir_times: omit_dir_times_setting,
         omit_link_times: omit_link_times_setting,
@@ -977,6 +1006,9 @@ where
         cow_policy,
         partial_dir,
         temp_dir,
+        checksum_cache,
+        checksum_xattr,
+        resume_manifest,
         delay_updates,
         link_dests,
         remove_source_files,
@@ -992,6 +1024,7 @@ where
         xxh64_dedup,
         timeout: timeout_setting,
         connect_timeout: connect_timeout_setting,
+        blocking_timeout: blocking_timeout_setting,
         stop_deadline: stop_request.as_ref().map(StopRequest::deadline),
         checksum_choice,
         compare_destinations,
@@ -1005,6 +1038,7 @@ where
         skip_compress_spec,
         cvs_exclude,
         itemize_changes,
+        json,
         out_format_template: out_format_template.clone(),
         log_file_template,
         name_level,
@@ -1026,6 +1060,7 @@ where
             .collect(),
         files_from: files_from_resolved.clone(),
         from0,
+        copy_buffer_size,
         spill_dir,
         spill_threshold_bytes,
         no_spill,
@@ -1097,6 +1132,7 @@ where
             name_level,
             name_overridden,
             eight_bit_output,
+            json,
             log_file: log_file_for_local,
         },
     )
@@ -15,12 +15,14 @@ use super::sources::{read_merge_file, read_merge_from_standard_input};
 
 /// Loads a merge file referenced by `directive`, parsing each of its lines and
 /// appending the resulting rules to `destination`. `visited` guards against
-/// recursive merge cycles; stdin (`-`) is read once.
+/// recursive merge cycles; stdin (`-`) is read once. `eol_nulls` (`--from0`)
+/// makes the file's records NUL-terminated instead of newline-terminated.
 pub(crate) fn apply_merge_directive(
     directive: MergeDirective,
     base_dir: &Path,
     destination: &mut Vec<FilterRuleSpec>,
     visited: &mut HashSet<PathBuf>,
+    eol_nulls: bool,
 ) -> Result<(), Message> {
     let options = directive.options().clone();
     let original_source_text = os_string_to_pattern(directive.source().to_os_string());
@@ -78,7 +80,7 @@ pub(crate) fn apply_merge_directive(
         };
 
         parse_merge_contents(
-            &contents, &options, next_base, &display, &mut local, visited,
+            &contents, &options, next_base, &display, &mut local, visited, eol_nulls,
         )
     })();
     visited.remove(&guard_key);
@@ -100,9 +102,13 @@ fn parse_merge_contents(
     display: &str,
     destination: &mut Vec<FilterRuleSpec>,
     visited: &mut HashSet<PathBuf>,
+    eol_nulls: bool,
 ) -> Result<(), Message> {
     if options.uses_whitespace() {
-        let mut tokens = contents.split_whitespace();
+        // upstream: exclude.c:1501 parse_filter_file - a word-split file breaks
+        // on whitespace and on the record terminator, which is NUL under
+        // --from0.
+        let mut tokens = contents.split(|ch: char| ch.is_whitespace() || (eol_nulls && ch == '\0'));
         while let Some(token) = tokens.next() {
             if token.is_empty() {
                 continue;
@@ -149,12 +155,27 @@ fn parse_merge_contents(
                 token.to_owned()
             };
 
-            process_merge_directive(&directive, options, base_dir, display, destination, visited)?;
+            process_merge_directive(
+                &directive,
+                options,
+                base_dir,
+                display,
+                destination,
+                visited,
+                eol_nulls,
+            )?;
         }
         return Ok(());
     }
 
-    for line in contents.lines() {
+    // upstream: exclude.c:1501 parse_filter_file - `--from0` terminates each
+    // record with NUL, so CR and LF become literal pattern bytes.
+    let records: Vec<&str> = if eol_nulls {
+        contents.split('\0').collect()
+    } else {
+        contents.lines().collect()
+    };
+    for line in records {
         // upstream: exclude.c:1514 parse_filter_file - a line is skipped only
         // when it is empty or (line parsing) begins with `;`/`#`. Whitespace is
         // never stripped, so leading whitespace and whitespace-only lines fall
@@ -192,7 +213,15 @@ fn parse_merge_contents(
             continue;
         }
 
-        process_merge_directive(line, options, base_dir, display, destination, visited)?;
+        process_merge_directive(
+            line,
+            options,
+            base_dir,
+            display,
+            destination,
+            visited,
+            eol_nulls,
+        )?;
     }
 
     Ok(())
@@ -208,6 +237,7 @@ pub(crate) fn process_merge_directive(
     display: &str,
     destination: &mut Vec<FilterRuleSpec>,
     visited: &mut HashSet<PathBuf>,
+    eol_nulls: bool,
 ) -> Result<(), Message> {
     match parse_filter_directive(OsStr::new(directive)) {
         Ok(FilterDirective::Rule(mut rule)) => {
@@ -217,14 +247,16 @@ pub(crate) fn process_merge_directive(
         Ok(FilterDirective::Merge(nested)) => {
             let effective_options = merge_directive_options(options, &nested);
             let nested = nested.with_options(effective_options);
-            apply_merge_directive(nested, base_dir, destination, visited).map_err(|error| {
-                let detail = error.to_string();
-                rsync_error!(
-                    1,
-                    format!("failed to process merge file '{display}': {detail}")
-                )
-                .with_role(Role::Client)
-            })?;
+            apply_merge_directive(nested, base_dir, destination, visited, eol_nulls).map_err(
+                |error| {
+                    let detail = error.to_string();
+                    rsync_error!(
+                        1,
+                        format!("failed to process merge file '{display}': {detail}")
+                    )
+                    .with_role(Role::Client)
+                },
+            )?;
         }
         Ok(FilterDirective::Clear) => destination.clear(),
         // A blank line inside a merge file contributes no rule.
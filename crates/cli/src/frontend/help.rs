@@ -47,8 +47,12 @@ pub(super) fn help_text(program_name: ProgramName) -> String {
             "      --max-size=SIZE  Skip files larger than SIZE.\n",
             "      --max-alloc=SIZE  Cap memory allocation at SIZE bytes (K=1024, M=1024^2, G=1024^3, T=1024^4, P=1024^5, E=1024^6; KB/MB/GB use powers of 1000; KiB/MiB/GiB are explicit binary; default 1G; 0 is rejected).\n",
             "      --block-size=SIZE  Force the delta-transfer block size to SIZE bytes.\n",
+            "      --copy-buffer-size=SIZE  Stream file data through a SIZE-byte buffer: local copies off the kernel fast path, and this side's reads or writes in a remote transfer; not forwarded.\n",
             "      --rayon-threads=N  Cap the rayon worker pool to N threads (1-1024).\n",
             "      --checksum-threads=N  Parallelise basis-signature hashing (auto/0=parallel, 1=sequential, N=cap); local-only, no wire change.\n",
+            "      --checksum-cache=DIR  Reuse --checksum sums kept in DIR while size and mtime are unchanged; local-only.\n",
+            "      --checksum-xattr=NAME  Take --checksum sums recorded in the NAME xattr while size and mtime are unchanged; not forwarded.\n",
+            "      --resume-manifest=FILE  Record completed files of a daemon pull in FILE and skip them when resuming.\n",
             "      --tokio-threads=N  Cap the async (tokio) runtime to N threads (1-1024); requires async features.\n",
             "  -b, --backup    Create backups before overwriting or deleting existing entries.\n",
             "      --backup-dir=DIR  Store backups inside DIR instead of alongside the destination.\n",
@@ -90,9 +94,13 @@ pub(super) fn help_text(program_name: ProgramName) -> String {
             "      --no-bwlimit    Remove any configured bandwidth limit.\n",
             "      --timeout=SECS  Abort when no progress is observed for SECS seconds (0 disables the timeout).\n",
             "      --contimeout=SECS  Abort connection attempts after SECS seconds (0 disables the limit).\n",
+            "      --blocking-timeout=SECS  Fail any single daemon socket read or write that stays\n",
+            "                              blocked for SECS seconds (0 disables the limit).\n",
             "      --sockopts=LIST  Set additional socket options (comma-separated LIST).\n",
             "      --tcp-fastopen=MODE  Enable TCP Fast Open on daemon and client sockets\n",
             "                              (auto, on, off; default auto: enabled where supported).\n",
+            "      --tls  Wrap daemon connections in TLS (oc-rsync daemons only; trusts\n",
+            "                              RSYNC_SSL_CA_CERT in addition to the system store).\n",
             "      --blocking-io  Force the remote shell to use blocking I/O.\n",
             "      --no-blocking-io  Disable forced blocking I/O on the remote shell.\n",
             "      --protocol=NUM  Force a specific protocol version (28 through 32).\n",
@@ -129,6 +137,8 @@ pub(super) fn help_text(program_name: ProgramName) -> String {
             "      --no-itemize-changes  Disable change summaries for updated entries.\n",
             "      --out-format=FORMAT  Customise transfer output using FORMAT.\n",
             "      --stats      Output transfer statistics after completion.\n",
+            "      --json       Print the transfer statistics and file list as one JSON object\n",
+            "                              instead of text (oc-rsync extension).\n",
             "      --partial    Keep partially transferred files on errors.\n",
             "      --no-partial Discard partially transferred files on errors.\n",
             "      --partial-dir=DIR  Store partially transferred files in DIR.\n",
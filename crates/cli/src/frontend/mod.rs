@@ -194,6 +194,7 @@ pub(crate) fn process_merge_directive(
         display,
         destination,
         visited,
+        false,
     )
 }
 
@@ -114,7 +114,33 @@ impl<W: Write> Write for NoBufferWriter<'_, W> {
 mod tests {
     use super::{OutbufAdapter, OutbufMode, parse_outbuf_mode};
     use std::ffi::OsStr;
-    use std::io::Write;
+    use std::io::{self, Write};
+
+    /// Sink that records every chunk the adapter hands down, so a test can
+    /// see when buffered bytes actually leave the adapter.
+    #[derive(Default)]
+    struct ChunkSink {
+        chunks: Vec<Vec<u8>>,
+        flushes: usize,
+    }
+
+    impl ChunkSink {
+        fn received(&self) -> Vec<u8> {
+            self.chunks.concat()
+        }
+    }
+
+    impl Write for ChunkSink {
+        fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
+            self.chunks.push(buf.to_vec());
+            Ok(buf.len())
+        }
+
+        fn flush(&mut self) -> io::Result<()> {
+            self.flushes += 1;
+            Ok(())
+        }
+    }
 
     #[test]
     fn parse_accepts_uppercase_variants() {
@@ -178,4 +204,44 @@ mod tests {
         }
         assert_eq!(buffer, b"payload");
     }
+
+    #[test]
+    fn line_mode_releases_each_completed_line() {
+        let mut sink = ChunkSink::default();
+        let mut adapter = OutbufAdapter::new(&mut sink, OutbufMode::Line);
+        adapter.write_all(b"file1\n").unwrap();
+        adapter.write_all(b"file2\npart").unwrap();
+        drop(adapter);
+
+        assert_eq!(sink.chunks[0], b"file1\n");
+        assert_eq!(sink.chunks[1], b"file2\n");
+        assert_eq!(sink.received(), b"file1\nfile2\npart");
+    }
+
+    #[test]
+    fn block_mode_batches_lines_until_flush() {
+        let mut sink = ChunkSink::default();
+        {
+            let mut adapter = OutbufAdapter::new(&mut sink, OutbufMode::Block);
+            adapter.write_all(b"file1\n").unwrap();
+            adapter.write_all(b"file2\n").unwrap();
+            adapter.flush().unwrap();
+        }
+
+        assert_eq!(sink.chunks, [b"file1\nfile2\n".to_vec()]);
+        assert_eq!(sink.flushes, 1);
+    }
+
+    #[test]
+    fn none_mode_flushes_every_write() {
+        let mut sink = ChunkSink::default();
+        {
+            let mut adapter = OutbufAdapter::new(&mut sink, OutbufMode::None);
+            adapter.write_all(b"12%").unwrap();
+            adapter.write_all(b"\r47%").unwrap();
+        }
+
+        assert_eq!(sink.chunks, [b"12%".to_vec(), b"\r47%".to_vec()]);
+        assert_eq!(sink.flushes, 2);
+    }
 }
@@ -0,0 +1,210 @@
+//! `--json` transfer summary: the `--stats` counters and the file list as one
+//! JSON object.
+//!
+//! This is an oc-rsync extension for tooling; upstream rsync only prints text.
+//! Counters carry the same values as the `--stats` block, always as raw
+//! numbers regardless of `--human-readable`. The `files` array lists every
+//! entry `--list-only` would print, or the entries a transfer copied or
+//! created.
+
+use std::io::{self, Write};
+use std::time::{SystemTime, UNIX_EPOCH};
+
+use core::client::{ClientEntryKind, ClientEvent, ClientSummary};
+use serde::Serialize;
+
+use super::format::list_only_event;
+use crate::frontend::escape::escape_path_lossless;
+
+/// Top-level `--json` object.
+#[derive(Serialize)]
+struct JsonSummary {
+    list_only: bool,
+    stats: JsonStats,
+    files: Vec<JsonFile>,
+}
+
+/// The `--stats` counters, using upstream's totals: the file count includes
+/// directories, links and specials, and the created count includes new
+/// directories.
+// upstream: main.c:output_summary()
+#[derive(Serialize)]
+struct JsonStats {
+    files_total: u64,
+    regular_files_total: u64,
+    directories_total: u64,
+    symlinks_total: u64,
+    devices_total: u64,
+    specials_total: u64,
+    files_created: u64,
+    files_deleted: u64,
+    files_transferred: u64,
+    total_file_size: u64,
+    bytes_transferred: u64,
+    literal_data: u64,
+    matched_data: u64,
+    file_list_size: u64,
+    bytes_sent: u64,
+    bytes_received: u64,
+    elapsed_seconds: f64,
+}
+
+/// One file-list entry. Metadata fields are omitted when the event carries
+/// none. Paths use forward slashes and keep non-UTF-8 bytes as `\#ooo`; see
+/// [`escape_path_lossless`].
+#[derive(Serialize)]
+struct JsonFile {
+    path: String,
+    #[serde(rename = "type", skip_serializing_if = "Option::is_none")]
+    kind: Option<&'static str>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    size: Option<u64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    mode: Option<u32>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    mtime: Option<i64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    target: Option<String>,
+}
+
+/// Writes `summary` as a single JSON object followed by a newline.
+pub(crate) fn emit_json_summary<W: Write + ?Sized>(
+    summary: &ClientSummary,
+    list_only: bool,
+    stdout: &mut W,
+) -> io::Result<()> {
+    let record = JsonSummary {
+        list_only,
+        stats: JsonStats::from_summary(summary),
+        files: summary
+            .events()
+            .iter()
+            .filter(|event| list_only_event(event.kind()))
+            .map(JsonFile::from_event)
+            .collect(),
+    };
+    let mut line = serde_json::to_vec(&record)?;
+    line.push(b'\n');
+    stdout.write_all(&line)
+}
+
+impl JsonStats {
+    fn from_summary(summary: &ClientSummary) -> Self {
+        let specials_total = summary.fifos_total();
+        Self {
+            files_total: summary
+                .regular_files_total()
+                .saturating_add(summary.directories_total())
+                .saturating_add(summary.symlinks_total())
+                .saturating_add(summary.devices_total())
+                .saturating_add(specials_total),
+            regular_files_total: summary.regular_files_total(),
+            directories_total: summary.directories_total(),
+            symlinks_total: summary.symlinks_total(),
+            devices_total: summary.devices_total(),
+            specials_total,
+            files_created: summary
+                .created_regular_files()
+                .saturating_add(summary.directories_created())
+                .saturating_add(summary.created_symlinks())
+                .saturating_add(summary.created_devices())
+                .saturating_add(summary.created_specials()),
+            files_deleted: summary.items_deleted(),
+            files_transferred: summary.files_copied(),
+            total_file_size: summary.total_source_bytes(),
+            bytes_transferred: summary.transferred_file_size(),
+            literal_data: summary.bytes_copied(),
+            matched_data: summary.matched_bytes(),
+            file_list_size: summary.file_list_size(),
+            bytes_sent: summary.bytes_sent(),
+            bytes_received: summary.bytes_received(),
+            elapsed_seconds: millisecond_precision(summary.wall_clock_elapsed().as_secs_f64()),
+        }
+    }
+}
+
+impl JsonFile {
+    fn from_event(event: &ClientEvent) -> Self {
+        let mut file = Self {
+            path: escape_path_lossless(event.relative_path()),
+            kind: None,
+            size: None,
+            mode: None,
+            mtime: None,
+            target: None,
+        };
+        if let Some(metadata) = event.metadata() {
+            file.kind = Some(kind_name(metadata.kind()));
+            file.size = Some(metadata.length());
+            file.mode = metadata.mode().map(|mode| mode & 0o7777);
+            file.mtime = metadata.modified().and_then(unix_seconds);
+            if metadata.kind().is_symlink() {
+                file.target = metadata.symlink_target().map(escape_path_lossless);
+            }
+        }
+        file
+    }
+}
+
+/// Rounds to the millisecond, the precision `--stats` reports.
+fn millisecond_precision(seconds: f64) -> f64 {
+    (seconds * 1000.0).round() / 1000.0
+}
+
+const fn kind_name(kind: ClientEntryKind) -> &'static str {
+    match kind {
+        ClientEntryKind::File => "file",
+        ClientEntryKind::Directory => "dir",
+        ClientEntryKind::Symlink => "symlink",
+        ClientEntryKind::Fifo => "fifo",
+        ClientEntryKind::CharDevice => "char-device",
+        ClientEntryKind::BlockDevice => "block-device",
+        ClientEntryKind::Socket => "socket",
+        ClientEntryKind::Other => "other",
+    }
+}
+
+/// Seconds since the epoch; negative for pre-1970 times.
+fn unix_seconds(time: SystemTime) -> Option<i64> {
+    match time.duration_since(UNIX_EPOCH) {
+        Ok(after) => i64::try_from(after.as_secs()).ok(),
+        Err(before) => i64::try_from(before.duration().as_secs())
+            .ok()
+            .map(|secs| -secs),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn file_record_omits_missing_metadata() {
+        let file = JsonFile {
+            path: "a\"b\\c\nd\u{1}".to_owned(),
+            kind: None,
+            size: None,
+            mode: None,
+            mtime: None,
+            target: None,
+        };
+        assert_eq!(
+            serde_json::to_string(&file).unwrap(),
+            r#"{"path":"a\"b\\c\nd\u0001"}"#
+        );
+    }
+
+    #[test]
+    fn elapsed_seconds_keep_millisecond_precision() {
+        assert_eq!(millisecond_precision(1.234_567), 1.235);
+        assert_eq!(millisecond_precision(0.0), 0.0);
+    }
+
+    #[test]
+    fn unix_seconds_handles_both_sides_of_the_epoch() {
+        let after = UNIX_EPOCH + std::time::Duration::from_secs(1_700_000_000);
+        let before = UNIX_EPOCH - std::time::Duration::from_secs(60);
+        assert_eq!(unix_seconds(after), Some(1_700_000_000));
+        assert_eq!(unix_seconds(before), Some(-60));
+    }
+}
@@ -2,6 +2,7 @@
 
 pub mod diagnostic;
 mod format;
+mod json;
 mod live;
 mod mode;
 mod render;
@@ -16,9 +17,9 @@ pub(crate) use self::format::{
     format_progress_rate_decimal, format_progress_rate_from_value, format_size,
     format_stat_categories, format_summary_rate, is_progress_event, list_only_event,
 };
+pub(crate) use self::json::emit_json_summary;
 pub(crate) use self::live::{LiveProgress, ProgressOutputConfig};
 pub(crate) use self::mode::ProgressMode;
 pub use self::mode::{NameOutputLevel, ProgressSetting, StderrMode}; // Changed to pub for test_utils
-#[cfg(test)]
 pub(crate) use self::render::emit_list_only;
-pub(crate) use self::render::emit_transfer_summary;
+pub(crate) use self::render::{emit_list_only_trailer, emit_transfer_summary};
@@ -86,12 +86,32 @@ impl StderrMode {
             None
         }
     }
+
+    /// Returns the `--[no-]msgs2stderr` tri-state this mode stands for.
+    ///
+    /// upstream: options.c:1912 - `errors` restores the default
+    /// (`msgs2stderr = 2`, `None`), `all` is `--msgs2stderr` (1) and `client`
+    /// is `--no-msgs2stderr` (0).
+    pub const fn msgs2stderr(self) -> Option<bool> {
+        match self {
+            Self::Errors => None,
+            Self::All => Some(true),
+            Self::Client => Some(false),
+        }
+    }
 }
 
 #[cfg(test)]
 mod tests {
     use super::*;
 
+    #[test]
+    fn stderr_mode_maps_onto_msgs2stderr_tri_state() {
+        assert_eq!(StderrMode::Errors.msgs2stderr(), None);
+        assert_eq!(StderrMode::All.msgs2stderr(), Some(true));
+        assert_eq!(StderrMode::Client.msgs2stderr(), Some(false));
+    }
+
     #[test]
     fn progress_mode_eq() {
         assert_eq!(ProgressMode::PerFile, ProgressMode::PerFile);
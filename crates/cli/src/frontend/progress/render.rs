@@ -96,34 +96,17 @@ pub(crate) fn emit_transfer_summary(
             wrote_listing = true;
         }
 
-        if stats_on {
-            if wrote_listing {
-                writeln!(writer)?;
-            }
-            emit_stats(
-                summary,
-                writer,
-                human_readable_mode,
-                dry_run,
-                only_write_batch,
-                stats_level,
-                show_copy_method,
-            )?;
-        } else if verbosity > 0 {
-            if wrote_listing {
-                writeln!(writer)?;
-            }
-            emit_totals(
-                summary,
-                writer,
-                human_readable_mode,
-                dry_run,
-                only_write_batch,
-                show_copy_method,
-            )?;
-        }
-
-        return Ok(());
+        return emit_list_only_trailer(
+            summary,
+            verbosity,
+            stats_level,
+            wrote_listing,
+            dry_run,
+            only_write_batch,
+            human_readable_mode,
+            show_copy_method,
+            writer,
+        );
     }
 
     // upstream: flist.c:2251 - rprintf(FCLIENT, "sending incremental file list\n")
@@ -281,6 +264,52 @@ fn format_list_time_column(time: Option<std::time::SystemTime>, blank: bool) ->
     format!("{value:>width$}")
 }
 
+/// Writes the `--stats` block or `-v` totals that close a `--list-only` run.
+///
+/// `after_listing` separates them from listing lines already written to the
+/// same stream with a blank line.
+#[allow(clippy::too_many_arguments)]
+pub(crate) fn emit_list_only_trailer(
+    summary: &ClientSummary,
+    verbosity: u8,
+    stats_level: u8,
+    after_listing: bool,
+    dry_run: bool,
+    only_write_batch: bool,
+    human_readable_mode: HumanReadableMode,
+    show_copy_method: bool,
+    writer: &mut dyn Write,
+) -> io::Result<()> {
+    if stats_level > 0 {
+        if after_listing {
+            writeln!(writer)?;
+        }
+        emit_stats(
+            summary,
+            writer,
+            human_readable_mode,
+            dry_run,
+            only_write_batch,
+            stats_level,
+            show_copy_method,
+        )
+    } else if verbosity > 0 {
+        if after_listing {
+            writeln!(writer)?;
+        }
+        emit_totals(
+            summary,
+            writer,
+            human_readable_mode,
+            dry_run,
+            only_write_batch,
+            show_copy_method,
+        )
+    } else {
+        Ok(())
+    }
+}
+
 #[allow(clippy::too_many_arguments)]
 pub(crate) fn emit_list_only<W: Write + ?Sized>(
     events: &[ClientEvent],
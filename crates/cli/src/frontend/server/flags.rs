@@ -47,6 +47,8 @@ pub(super) struct ServerLongFlags {
     pub(super) is_sender: bool,
     pub(super) is_receiver: bool,
     pub(super) ignore_errors: bool,
+    /// Remove non-empty directories that block incoming files (upstream: `--force`).
+    pub(super) force_delete: bool,
     pub(super) fsync: bool,
     pub(super) io_uring_policy: fast_io::IoUringPolicy,
     /// Optional `--io-uring-depth=N` value forwarded by the client.
@@ -364,6 +366,13 @@ pub(super) struct ServerLongFlags {
     /// emits a standalone `-B<digits>` token after the compact flag string.
     /// Recognised here so it is not mistaken for a positional destination path.
     pub(super) block_size: Option<String>,
+
+    /// Receiver write identity (upstream: `--copy-as=USER[:GROUP]`).
+    ///
+    /// upstream: main.c:become_copy_as_user() - never emitted by
+    /// `server_options()`; it arrives only through `--remote-option`, and
+    /// the server receiver then writes the destination as that identity.
+    pub(super) copy_as: Option<String>,
 }
 
 /// Parses all long-form flags from the server argument list.
@@ -376,6 +385,7 @@ pub(super) fn parse_server_long_flags(args: &[OsString]) -> ServerLongFlags {
         is_sender: false,
         is_receiver: false,
         ignore_errors: false,
+        force_delete: false,
         fsync: false,
         io_uring_policy: fast_io::IoUringPolicy::Auto,
         io_uring_depth: None,
@@ -435,6 +445,7 @@ pub(super) fn parse_server_long_flags(args: &[OsString]) -> ServerLongFlags {
         groupmap: None,
         skip_compress: None,
         block_size: None,
+        copy_as: None,
     };
 
     let mut idx = 0;
@@ -481,13 +492,9 @@ pub(super) fn parse_server_long_flags(args: &[OsString]) -> ServerLongFlags {
             // "--force"`, emitted in the am_sender block so it reaches a server
             // acting as the receiver. force_delete only changes behavior when a
             // non-empty directory must be replaced by a non-directory while
-            // deletions are inactive (delete.c). Under an active --delete pass -
-            // the trigger server_options() ships it alongside - oc already
-            // removes extraneous non-empty directories recursively
-            // (receiver/directory/deletion.rs), matching upstream 2.6.7+
-            // (`--delete` no longer needs `--force`). Recognized here so the arg
-            // does not leak into the positional path list.
-            "--force" => {}
+            // deletions are inactive (generator.c:recv_generator() adds
+            // DEL_RECURSE for `delete_mode || force_delete`).
+            "--force" => flags.force_delete = true,
             // upstream: options.c:2852-2853 - `if (am_root > 1) args[ac++] =
             // "--super"`, forcing super-user metadata semantics (chown/mknod)
             // even when the receiver is not literally uid 0. oc gates those
@@ -744,6 +751,8 @@ fn parse_value_bearing_flag(s: &str, flags: &mut ServerLongFlags) {
     } else if let Some(value) = s.strip_prefix("--usermap=") {
         // upstream: options.c:2912-2913 - safe_arg("--usermap", usermap).
         flags.usermap = Some(value.to_owned());
+    } else if let Some(value) = s.strip_prefix("--copy-as=") {
+        flags.copy_as = Some(value.to_owned());
     } else if let Some(value) = s.strip_prefix("--groupmap=") {
         // upstream: options.c:2915-2916 - safe_arg("--groupmap", groupmap).
         flags.groupmap = Some(value.to_owned());
@@ -887,6 +896,8 @@ pub(super) fn is_known_server_long_flag(arg: &str) -> bool {
             | "--no-mkpath"
             | "--old-dirs"
             | "--old-d"
+            // Emitted by the oc-rsync client but not acted on by the server.
+            | "--munge-links"
     ) || arg == "-s"
         || arg == "--new-compress"
         || arg == "--old-compress"
@@ -918,6 +929,9 @@ pub(super) fn is_known_server_long_flag(arg: &str) -> bool {
         || arg.starts_with("--usermap=")
         || arg.starts_with("--groupmap=")
         || arg.starts_with("--skip-compress=")
+        // `--copy-as=USER[:GROUP]` is never emitted by server_options(); it
+        // arrives via `--remote-option` and must not become a positional path.
+        || arg.starts_with("--copy-as=")
         // upstream: options.c:2787-2790 - block_size arrives as a standalone
         // `-B%u` token. Guard on trailing digits so only that spelling matches.
         || (arg.starts_with("-B")
@@ -938,6 +952,62 @@ pub(super) fn is_known_server_long_flag(arg: &str) -> bool {
         // server receiver on a push. Recognise it so the placeholder token is
         // not mistaken for a positional destination path.
         || arg.starts_with("--only-write-batch=")
+        // Joined forms of options the oc-rsync client forwards (builder.rs)
+        // which the server accepts without acting on.
+        || arg.starts_with("--bwlimit=")
+        || arg.starts_with("--block-size=")
+        || arg.starts_with("--temp-dir=")
+        || arg.starts_with("--backup-dir=")
+}
+
+/// Returns the first option in `args` that a client may not pass to
+/// `--server`.
+///
+/// Only the option region before the `.` separator is checked: a client
+/// prefixes every path beginning with `-` with `./` (see `safe_arg()`), so a
+/// dash there can only start an option. Each option must be a known server
+/// flag or the single compact flag string, so a hostile client cannot
+/// smuggle in an option such as `--rsync-path` or `--daemon` that the server
+/// would otherwise misread as flag letters or a destination path.
+///
+/// upstream: options.c:parse_arguments() runs the server's argv through the
+/// popt table and refuses any option it does not know with
+/// `on remote machine: <option>: unknown option`.
+pub(super) fn first_disallowed_server_option(args: &[OsString]) -> Option<String> {
+    let mut found_flags = false;
+    let mut idx = 0;
+    while idx < args.len() {
+        let arg = args[idx].to_string_lossy();
+        if arg == "." {
+            break;
+        }
+        if arg == "--partial-dir" || is_two_arg_server_long_flag(&arg) {
+            idx += 2;
+            continue;
+        }
+        idx += 1;
+        if is_known_server_long_flag(&arg) || !arg.starts_with('-') {
+            continue;
+        }
+        if !found_flags && is_compact_flag_string(&arg) {
+            found_flags = true;
+            continue;
+        }
+        return Some(arg.into_owned());
+    }
+    None
+}
+
+/// Reports whether `arg` has the shape of the compact flag string built by
+/// upstream `server_options()`: single-letter flags, optionally followed by
+/// `e.` and the capability letters.
+fn is_compact_flag_string(arg: &str) -> bool {
+    arg.strip_prefix('-').is_some_and(|letters| {
+        !letters.is_empty()
+            && letters
+                .bytes()
+                .all(|b| b.is_ascii_alphanumeric() || b == b'.')
+    })
 }
 
 /// Returns `true` when the argument is a bare server-mode long flag whose
@@ -9,7 +9,9 @@ use core::message::Role;
 use core::rsync_error;
 use logging_sink::MessageSink;
 
-use super::flags::{detect_secluded_args_flag, parse_server_long_flags};
+use super::flags::{
+    detect_secluded_args_flag, first_disallowed_server_option, parse_server_long_flags,
+};
 use super::parse::{
     parse_server_checksum_seed, parse_server_flag_string_and_args, parse_server_size_limit,
     parse_server_stop_after, parse_server_stop_at,
@@ -96,6 +98,18 @@ where
         &args[1..]
     };
 
+    // upstream: options.c:1460-1465 - an option outside the server's table
+    // fails parse_arguments() and the server exits RERR_SYNTAX before
+    // touching any file.
+    if let Some(option) = first_disallowed_server_option(effective_slice) {
+        write_server_error(
+            stderr,
+            program_brand,
+            format!("on remote machine: {option}: unknown option"),
+        );
+        return 1;
+    }
+
     let long_flags = parse_server_long_flags(effective_slice);
 
     let (flag_string, positional_args) = parse_server_flag_string_and_args(effective_slice);
@@ -190,6 +204,7 @@ where
 
     // Boolean and move-only flags applied after value parsing releases its borrow.
     config.deletion.ignore_errors = long_flags.ignore_errors;
+    config.deletion.force_delete = long_flags.force_delete;
     config.write.fsync = long_flags.fsync;
     config.write.io_uring_policy = long_flags.io_uring_policy;
     config.write.zero_copy_policy = long_flags.zero_copy_policy;
@@ -751,6 +766,20 @@ fn apply_value_flags<Err: Write>(
         }
     }
 
+    // upstream: main.c:do_recv() - become_copy_as_user(). server_options()
+    // never forwards --copy-as; it reaches a server receiver only when the
+    // user passes it through `--remote-option` (-M). An unknown name is fatal,
+    // as upstream's "Invalid --copy-as user" is.
+    if let Some(spec) = &long_flags.copy_as {
+        match ::metadata::parse_copy_as_spec(std::ffi::OsStr::new(spec)) {
+            Ok(ids) => config.copy_as = Some(ids),
+            Err(e) => {
+                write_server_error(stderr, brand, format!("invalid --copy-as: {e}"));
+                return Err(1);
+            }
+        }
+    }
+
     Ok(())
 }
 
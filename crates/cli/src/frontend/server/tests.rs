@@ -5,7 +5,10 @@ use super::daemon::{
     daemon_mode_arguments, server_daemon_arguments, server_daemon_mode_requested,
     server_mode_requested,
 };
-use super::flags::{detect_secluded_args_flag, is_known_server_long_flag, parse_server_long_flags};
+use super::flags::{
+    detect_secluded_args_flag, first_disallowed_server_option, is_known_server_long_flag,
+    parse_server_long_flags,
+};
 use super::parse::{
     parse_server_checksum_seed, parse_server_flag_string_and_args, parse_server_size_limit,
     parse_server_stop_after, parse_server_stop_at,
@@ -971,6 +974,25 @@ fn long_flags_capture_groupmap() {
     assert_eq!(flags.groupmap.as_deref(), Some("*:5678"));
 }
 
+// upstream: main.c:become_copy_as_user() - `--copy-as` reaches the server
+// only through `--remote-option`; it is captured for the receiver and kept
+// out of the positional destination list.
+#[test]
+fn copy_as_is_captured_and_not_positional() {
+    assert!(is_known_server_long_flag("--copy-as=backup:backup"));
+    let args = vec![
+        OsString::from("--server"),
+        OsString::from("-logDtpr"),
+        OsString::from("--copy-as=backup:backup"),
+        OsString::from("."),
+        OsString::from("dst/"),
+    ];
+    let flags = parse_server_long_flags(&args);
+    assert_eq!(flags.copy_as.as_deref(), Some("backup:backup"));
+    let (_, pos_args) = parse_server_flag_string_and_args(&args);
+    assert_eq!(pos_args, vec![OsString::from("dst/")]);
+}
+
 // upstream: options.c:2859-2860 - `server_options()` emits
 // `safe_arg("--skip-compress", skip_compress)` (joined `--skip-compress=VALUE`)
 // in the client-receiver branch so a server sender skips compression for the
@@ -1346,6 +1368,89 @@ fn known_flag_rejects_unknown() {
     assert!(!is_known_server_long_flag("dest/"));
 }
 
+fn server_args(args: &[&str]) -> Vec<OsString> {
+    args.iter().map(OsString::from).collect()
+}
+
+#[test]
+fn disallowed_option_accepts_client_argument_vector() {
+    let args = server_args(&[
+        "--server",
+        "--sender",
+        "-vlogDtpre.iLsfxCIvu",
+        "--delete-after",
+        "--bwlimit=100",
+        "--partial-dir",
+        ".rsync-partial",
+        "--compare-dest",
+        "/srv/basis",
+        "-B131072",
+        "-@-1",
+        ".",
+        "src/",
+        "./-leading-dash",
+    ]);
+    assert_eq!(first_disallowed_server_option(&args), None);
+}
+
+#[test]
+fn disallowed_option_rejects_unknown_options() {
+    for smuggled in [
+        "--rsync-path=/tmp/evil",
+        "--rsh=/tmp/evil",
+        "--daemon",
+        "--config=/tmp/rsyncd.conf",
+        "-e=ssh",
+    ] {
+        let args = server_args(&["--server", "-logDtpr", smuggled, ".", "dest/"]);
+        assert_eq!(
+            first_disallowed_server_option(&args).as_deref(),
+            Some(smuggled),
+            "{smuggled} must be refused"
+        );
+    }
+}
+
+#[test]
+fn disallowed_option_rejects_option_in_place_of_flag_string() {
+    // Taken as the compact flag string, `--rsync-path=...` would enable flag
+    // letters such as `r`, `n` and `c`.
+    let args = server_args(&["--server", "--rsync-path=/tmp/evil", ".", "dest/"]);
+    assert_eq!(
+        first_disallowed_server_option(&args).as_deref(),
+        Some("--rsync-path=/tmp/evil")
+    );
+
+    let args = server_args(&["--server", "-logDtpr", "-vvv", ".", "dest/"]);
+    assert_eq!(
+        first_disallowed_server_option(&args).as_deref(),
+        Some("-vvv")
+    );
+}
+
+#[test]
+fn server_mode_refuses_smuggled_option() {
+    let args = server_args(&[
+        "oc-rsync",
+        "--server",
+        "-logDtpre.iLsfxCIvu",
+        "--rsync-path=/tmp/evil",
+        ".",
+        "dest/",
+    ]);
+    let mut stdout = Vec::new();
+    let mut stderr = Vec::new();
+    let status = super::run_server_mode(&args, &mut stdout, &mut stderr);
+
+    assert_eq!(status, 1);
+    let rendered = String::from_utf8_lossy(&stderr);
+    assert!(
+        rendered.contains("on remote machine: --rsync-path=/tmp/evil: unknown option"),
+        "{rendered}"
+    );
+    assert!(stdout.is_empty(), "nothing may reach the protocol stream");
+}
+
 #[test]
 fn checksum_seed_parses_valid() {
     assert_eq!(parse_server_checksum_seed("0").unwrap(), 0);
@@ -2094,9 +2199,7 @@ fn long_flags_missing_args_variants() {
 
 /// upstream: options.c:2848-2853 / 2990-2991 - `--force` (force_delete),
 /// `--super` (am_root > 1), and `--preallocate` (preallocate_files) are emitted
-/// in the am_sender block and reach a server acting as the receiver. oc has no
-/// content-affecting server sink for these (recursive delete already happens,
-/// root is already privileged, preallocation is content-invisible), but they
+/// in the am_sender block and reach a server acting as the receiver. They
 /// MUST be recognised so they never surface as a positional destination path.
 #[test]
 fn force_super_preallocate_recognised_as_known_long_flags() {
@@ -2121,6 +2224,18 @@ fn parse_server_long_flags_sets_preallocate() {
     assert!(with.preallocate, "--preallocate must set the flag");
 }
 
+/// upstream: generator.c:recv_generator() - `--force` adds DEL_RECURSE so a
+/// server receiver can remove a non-empty directory standing where a file
+/// arrives; parsing must carry it onto `force_delete`.
+#[test]
+fn parse_server_long_flags_sets_force_delete() {
+    let without = parse_server_long_flags(&[OsString::from("--server")]);
+    assert!(!without.force_delete, "default must be false");
+
+    let with = parse_server_long_flags(&[OsString::from("--server"), OsString::from("--force")]);
+    assert!(with.force_delete, "--force must set the flag");
+}
+
 /// Regression for the positional leak: with the compact flag string present,
 /// none of the seven newly recognised long flags may fall through into
 /// `positional_args`; only the real destination path (`dst/`) survives. Without
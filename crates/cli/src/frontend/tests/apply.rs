@@ -20,7 +20,7 @@ fn apply_merge_directive_resolves_relative_paths() {
     let mut visited = HashSet::new();
     let directive = MergeDirective::new(OsString::from("outer.rules"), None)
         .with_options(DirMergeOptions::default().allow_list_clearing(true));
-    super::apply_merge_directive(directive, temp.path(), &mut rules, &mut visited)
+    super::apply_merge_directive(directive, temp.path(), &mut rules, &mut visited, false)
         .expect("merge succeeds");
 
     assert!(visited.is_empty());
@@ -44,7 +44,7 @@ fn apply_merge_directive_respects_forced_include() {
                 .with_enforced_kind(Some(DirMergeEnforcedKind::Include))
                 .allow_list_clearing(true),
         );
-    super::apply_merge_directive(directive, temp.path(), &mut rules, &mut visited)
+    super::apply_merge_directive(directive, temp.path(), &mut rules, &mut visited, false)
         .expect("merge succeeds");
 
     assert!(visited.is_empty());
@@ -53,3 +53,25 @@ fn apply_merge_directive_respects_forced_include() {
     let patterns: Vec<_> = rules.iter().map(|rule| rule.pattern().to_owned()).collect();
     assert_eq!(patterns, vec!["existing", "beta"]);
 }
+
+#[test]
+fn apply_merge_directive_splits_records_on_nul_with_eol_nulls() {
+    use tempfile::tempdir;
+
+    let temp = tempdir().expect("tempdir");
+    let path = temp.path().join("filters0.rules");
+    std::fs::write(&path, b"- a\nb\0+ keep\r\0").expect("write filters");
+
+    let mut rules = Vec::new();
+    let mut visited = HashSet::new();
+    let directive = MergeDirective::new(path.into_os_string(), None);
+    super::apply_merge_directive(directive, temp.path(), &mut rules, &mut visited, true)
+        .expect("merge succeeds");
+
+    // upstream: exclude.c:1501 parse_filter_file - under --from0 only NUL ends
+    // a record, so the newline and carriage return stay in the patterns.
+    let patterns: Vec<_> = rules.iter().map(|rule| rule.pattern().to_owned()).collect();
+    assert_eq!(patterns, vec!["a\nb", "keep\r"]);
+    assert_eq!(rules[0].kind(), FilterRuleKind::Exclude);
+    assert_eq!(rules[1].kind(), FilterRuleKind::Include);
+}
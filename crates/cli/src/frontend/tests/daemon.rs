@@ -141,3 +141,91 @@ fn daemon_config_flag_separate_value_is_passed_through() {
         "expected config path to be forwarded, got: {daemon_args:?}"
     );
 }
+
+/// Simulates a reconnect after an interrupted pull: the destination already
+/// holds some files and the manifest records them, so the rerun transfers
+/// only the rest and removes the manifest.
+#[cfg(unix)]
+#[test]
+fn resume_manifest_pull_transfers_only_remaining_files() {
+    use filetime::{FileTime, set_file_times};
+    use std::fs;
+    use tempfile::tempdir;
+
+    let tmp = tempdir().expect("tempdir");
+    let module_dir = tmp.path().join("module");
+    let dest_dir = tmp.path().join("dest");
+    fs::create_dir_all(&module_dir).expect("create module");
+    fs::create_dir_all(&dest_dir).expect("create dest");
+    for (name, contents) in [("a.txt", "alpha"), ("b.txt", "beta"), ("c.txt", "gamma")] {
+        fs::write(module_dir.join(name), contents).expect("write source");
+    }
+
+    // The interrupted run already received a.txt and b.txt. Their local mtimes
+    // differ from the source, so a plain -t pull would touch them again.
+    let stale = FileTime::from_unix_time(1_000_000_000, 0);
+    for name in ["a.txt", "b.txt"] {
+        fs::copy(module_dir.join(name), dest_dir.join(name)).expect("seed dest");
+        set_file_times(dest_dir.join(name), stale, stale).expect("stamp dest");
+    }
+    let manifest = tmp.path().join("pull.manifest");
+    fs::write(&manifest, "a.txt\nb.txt\n").expect("write manifest");
+
+    let config_file = tmp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[mod]\npath = {}\nread only = true\nuse chroot = false\n",
+            module_dir.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind daemon listener");
+    let port = listener.local_addr().expect("listener addr").port();
+    let daemon_config = daemon_cli::DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.into_os_string(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("1"),
+        ])
+        .pre_bound_listener(listener)
+        .build();
+    let daemon = thread::spawn(move || daemon_cli::run_daemon(daemon_config));
+
+    let mut manifest_arg = OsString::from("--resume-manifest=");
+    manifest_arg.push(manifest.as_os_str());
+    let mut dest_arg = dest_dir.clone().into_os_string();
+    dest_arg.push("/");
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("-rt"),
+        OsString::from("--json"),
+        manifest_arg,
+        OsString::from(format!("rsync://127.0.0.1:{port}/mod/")),
+        dest_arg,
+    ]);
+    daemon
+        .join()
+        .expect("daemon thread")
+        .expect("daemon session");
+
+    assert_eq!(code, 0, "stderr: {}", String::from_utf8_lossy(&stderr));
+    let report = String::from_utf8(stdout).expect("utf8 stdout");
+    assert!(
+        report.contains("\"files_transferred\":1,"),
+        "only c.txt should transfer: {report}"
+    );
+    assert_eq!(fs::read(dest_dir.join("c.txt")).expect("read c"), b"gamma");
+    for name in ["a.txt", "b.txt"] {
+        let mtime = FileTime::from_last_modification_time(
+            &fs::metadata(dest_dir.join(name)).expect("dest metadata"),
+        );
+        assert_eq!(mtime, stale, "{name} must be left out of the file list");
+    }
+    assert!(!manifest.exists(), "manifest is removed after a clean pull");
+}
@@ -1570,3 +1570,107 @@ fn list_only_renders_atime_and_crtime_columns() {
         "directory crtime column must be shown: {dir_line:?}"
     );
 }
+
+/// Verifies that `--list-only -r` against a daemon module walks the whole
+/// remote tree and renders one upstream-format line per entry, including
+/// nested paths, human-readable sizes and the symlink arrow.
+#[cfg(unix)]
+#[test]
+fn list_only_recursive_daemon_module_renders_full_tree() {
+    use filetime::{FileTime, set_file_times, set_symlink_file_times};
+    use std::fs;
+    use std::os::unix::fs::{PermissionsExt, symlink};
+    use tempfile::tempdir;
+
+    let tmp = tempdir().expect("tempdir");
+    let module_dir = tmp.path().join("tree");
+    let sub_dir = module_dir.join("sub");
+    fs::create_dir_all(&sub_dir).expect("create module tree");
+    fs::write(module_dir.join("top.txt"), b"top").expect("write top");
+    fs::write(sub_dir.join("nested.txt"), vec![b'n'; 1_234]).expect("write nested");
+    symlink("nested.txt", sub_dir.join("link")).expect("create symlink");
+    fs::set_permissions(
+        module_dir.join("top.txt"),
+        fs::Permissions::from_mode(0o644),
+    )
+    .expect("chmod top");
+    fs::set_permissions(
+        sub_dir.join("nested.txt"),
+        fs::Permissions::from_mode(0o600),
+    )
+    .expect("chmod nested");
+    fs::set_permissions(&sub_dir, fs::Permissions::from_mode(0o755)).expect("chmod sub");
+    fs::set_permissions(&module_dir, fs::Permissions::from_mode(0o755)).expect("chmod root");
+
+    // Stamp directories last so creating their children does not move them.
+    let timestamp = FileTime::from_unix_time(1_700_000_000, 0);
+    set_symlink_file_times(sub_dir.join("link"), timestamp, timestamp).expect("stamp link");
+    for path in [
+        module_dir.join("top.txt"),
+        sub_dir.join("nested.txt"),
+        sub_dir.clone(),
+        module_dir.clone(),
+    ] {
+        set_file_times(&path, timestamp, timestamp).expect("stamp entry");
+    }
+
+    let config_file = tmp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[tree]\npath = {}\nread only = true\nuse chroot = false\nmunge symlinks = false\n",
+            module_dir.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind daemon listener");
+    let port = listener.local_addr().expect("listener addr").port();
+    let daemon_config = daemon_cli::DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.into_os_string(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("1"),
+        ])
+        .pre_bound_listener(listener)
+        .build();
+    let daemon = thread::spawn(move || daemon_cli::run_daemon(daemon_config));
+
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("--list-only"),
+        OsString::from("--recursive"),
+        OsString::from("--links"),
+        OsString::from("--human-readable"),
+        OsString::from(format!("rsync://127.0.0.1:{port}/tree/")),
+    ]);
+    daemon
+        .join()
+        .expect("daemon thread")
+        .expect("daemon session");
+
+    assert_eq!(code, 0, "stderr: {}", String::from_utf8_lossy(&stderr));
+
+    let system_time = SystemTime::UNIX_EPOCH
+        + Duration::from_secs(u64::try_from(timestamp.unix_seconds()).expect("positive timestamp"));
+    let time = format_list_timestamp(Some(system_time));
+    let size = |len: u64| format_list_size(len, HumanReadableMode::DecimalUnits);
+    let dir_size = |path: &Path| size(fs::metadata(path).expect("dir metadata").len());
+    let mut expected = vec![
+        format!("drwxr-xr-x {} {time} .", dir_size(&module_dir)),
+        format!("-rw-r--r-- {} {time} top.txt", size(3)),
+        format!("drwxr-xr-x {} {time} sub", dir_size(&sub_dir)),
+        format!("-rw------- {} {time} sub/nested.txt", size(1_234)),
+        format!("lrwxrwxrwx {} {time} sub/link -> nested.txt", size(10)),
+    ];
+    expected.sort();
+
+    let rendered = String::from_utf8(stdout).expect("utf8 stdout");
+    let mut lines: Vec<String> = rendered.lines().map(str::to_owned).collect();
+    lines.sort();
+    assert_eq!(lines, expected, "listing:\n{rendered}");
+}
@@ -41,7 +41,7 @@ fn apply_merge_directive_parses_whitespace_risk_and_exclude_if_present() {
 
     let mut rules = Vec::new();
     let mut visited = HashSet::new();
-    apply_merge_directive(directive, temp.path(), &mut rules, &mut visited).expect("apply merge");
+    apply_merge_directive(directive, temp.path(), &mut rules, &mut visited, false).expect("apply merge");
 
     assert!(visited.is_empty());
     assert!(
@@ -73,7 +73,7 @@ fn apply_merge_directive_rejects_per_dir_alias() {
     let mut visited = HashSet::new();
     // "per-dir" is not an upstream directive, so a merge file that uses it must
     // fail to load rather than silently accept the oc-only alias.
-    assert!(apply_merge_directive(directive, temp.path(), &mut rules, &mut visited).is_err());
+    assert!(apply_merge_directive(directive, temp.path(), &mut rules, &mut visited, false).is_err());
 }
 
 #[test]
@@ -101,6 +101,8 @@ mod long_tests;
 mod merge_tests;
 #[path = "module.rs"]
 mod module_tests;
+#[path = "msgs2stderr.rs"]
+mod msgs2stderr_tests;
 #[path = "non.rs"]
 mod non_tests;
 #[path = "operands.rs"]
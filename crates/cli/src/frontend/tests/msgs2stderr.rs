@@ -0,0 +1,62 @@
+use super::common::*;
+use super::*;
+
+#[test]
+fn msgs2stderr_moves_verbose_output_to_stderr() {
+    use tempfile::tempdir;
+
+    let tmp = tempdir().expect("tempdir");
+    let source_dir = tmp.path().join("src");
+    let dest_dir = tmp.path().join("dst");
+    std::fs::create_dir(&source_dir).expect("create src dir");
+    std::fs::write(source_dir.join("verbose.txt"), b"verbose").expect("write source");
+
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("-rv"),
+        OsString::from("--msgs2stderr"),
+        source_dir.into_os_string(),
+        dest_dir.clone().into_os_string(),
+    ]);
+
+    assert_eq!(code, 0);
+    assert!(
+        stdout.is_empty(),
+        "stdout must stay empty: {}",
+        String::from_utf8_lossy(&stdout)
+    );
+    let rendered = String::from_utf8(stderr).expect("stderr utf8");
+    assert!(rendered.contains("verbose.txt"), "{rendered}");
+    assert!(rendered.contains("total size is"), "{rendered}");
+    assert!(dest_dir.join("src/verbose.txt").exists());
+}
+
+#[test]
+fn msgs2stderr_keeps_list_only_output_on_stdout() {
+    use tempfile::tempdir;
+
+    let tmp = tempdir().expect("tempdir");
+    let source_dir = tmp.path().join("src");
+    let dest_dir = tmp.path().join("dst");
+    std::fs::create_dir(&source_dir).expect("create src dir");
+    std::fs::create_dir(&dest_dir).expect("create dest dir");
+    std::fs::write(source_dir.join("listed.txt"), b"listed").expect("write source");
+
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("--list-only"),
+        OsString::from("-rv"),
+        OsString::from("--msgs2stderr"),
+        source_dir.into_os_string(),
+        dest_dir.clone().into_os_string(),
+    ]);
+
+    assert_eq!(code, 0);
+    let listing = String::from_utf8(stdout).expect("stdout utf8");
+    assert!(listing.contains("listed.txt"), "{listing}");
+    assert!(!listing.contains("total size is"), "{listing}");
+    let messages = String::from_utf8(stderr).expect("stderr utf8");
+    assert!(messages.contains("total size is"), "{messages}");
+    assert!(!messages.contains("listed.txt"), "{messages}");
+    assert!(!dest_dir.join("listed.txt").exists());
+}
@@ -119,3 +119,101 @@ fn stats_sparse_transfer_reports_literal_data() {
         "expected literal data line to report {sparse_len} bytes, got {literal_line:?}"
     );
 }
+
+#[test]
+fn json_reports_stats_and_transferred_files() {
+    use tempfile::tempdir;
+
+    let _env_lock = ENV_LOCK.lock().expect("env lock");
+    let _rsh_guard = clear_rsync_rsh();
+
+    let tmp = tempdir().expect("tempdir");
+    let source = tmp.path().join("src");
+    std::fs::create_dir_all(source.join("sub")).expect("create source tree");
+    std::fs::write(source.join("a.txt"), b"alpha").expect("write a");
+    std::fs::write(source.join("sub").join("b.txt"), b"bravo!!").expect("write b");
+    let dest = tmp.path().join("dest");
+
+    let mut source_arg = source.into_os_string();
+    source_arg.push("/");
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("-r"),
+        OsString::from("--json"),
+        OsString::from("--human-readable"),
+        source_arg,
+        dest.clone().into_os_string(),
+    ]);
+
+    assert_eq!(code, 0);
+    assert!(stderr.is_empty());
+    let rendered = String::from_utf8(stdout).expect("json output utf8");
+    assert!(rendered.starts_with("{\"list_only\":false,\"stats\":{"));
+    assert!(rendered.ends_with("]}\n"));
+    assert_eq!(rendered.lines().count(), 1, "one JSON object: {rendered}");
+    // Raw numbers even under --human-readable.
+    for field in [
+        "\"regular_files_total\":2",
+        "\"directories_total\":2",
+        "\"files_transferred\":2",
+        "\"total_file_size\":12",
+        "\"bytes_transferred\":12",
+        "\"literal_data\":12",
+        "\"matched_data\":0",
+        "\"files_deleted\":0",
+        "\"bytes_sent\":",
+        "\"bytes_received\":",
+        "\"elapsed_seconds\":",
+    ] {
+        assert!(rendered.contains(field), "missing {field} in {rendered}");
+    }
+    assert!(rendered.contains("{\"path\":\"a.txt\",\"type\":\"file\",\"size\":5"));
+    assert!(rendered.contains("{\"path\":\"sub/b.txt\",\"type\":\"file\",\"size\":7"));
+    assert!(!rendered.contains("Number of files"));
+    assert_eq!(
+        std::fs::read(dest.join("sub").join("b.txt")).expect("read copy"),
+        b"bravo!!"
+    );
+}
+
+#[cfg(unix)]
+#[test]
+fn json_list_only_reports_listing_with_symlink_target() {
+    use tempfile::tempdir;
+
+    let _env_lock = ENV_LOCK.lock().expect("env lock");
+    let _rsh_guard = clear_rsync_rsh();
+
+    let tmp = tempdir().expect("tempdir");
+    let source = tmp.path().join("src");
+    std::fs::create_dir(&source).expect("create source");
+    std::fs::write(source.join("file.txt"), b"contents").expect("write file");
+    std::fs::set_permissions(
+        source.join("file.txt"),
+        std::fs::Permissions::from_mode(0o640),
+    )
+    .expect("chmod file");
+    std::os::unix::fs::symlink("file.txt", source.join("link")).expect("create symlink");
+
+    let mut source_arg = source.into_os_string();
+    source_arg.push("/");
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("--list-only"),
+        OsString::from("-rl"),
+        OsString::from("--json"),
+        source_arg,
+    ]);
+
+    assert_eq!(code, 0);
+    assert!(stderr.is_empty());
+    let rendered = String::from_utf8(stdout).expect("json output utf8");
+    assert!(rendered.starts_with("{\"list_only\":true,"));
+    assert!(rendered.contains("{\"path\":\"file.txt\",\"type\":\"file\",\"size\":8,\"mode\":416,"));
+    assert!(rendered.contains("\"type\":\"symlink\""));
+    assert!(rendered.contains(",\"target\":\"file.txt\"}"));
+    assert!(
+        !rendered.contains("-rw-r-----"),
+        "no text listing: {rendered}"
+    );
+}
@@ -280,6 +280,26 @@ fn cli_timeout_default_is_none() {
     assert_eq!(parsed.timeout, None);
 }
 
+#[test]
+fn cli_blocking_timeout_is_independent_of_timeout() {
+    let parsed = parse_args([
+        "rsync",
+        "--timeout=60",
+        "--blocking-timeout=5",
+        "src/",
+        "dst/",
+    ])
+    .expect("parse");
+    assert_eq!(parsed.timeout, Some(OsString::from("60")));
+    assert_eq!(parsed.blocking_timeout, Some(OsString::from("5")));
+}
+
+#[test]
+fn cli_blocking_timeout_default_is_none() {
+    let parsed = parse_args(["rsync", "src/", "dst/"]).expect("parse");
+    assert_eq!(parsed.blocking_timeout, None);
+}
+
 #[test]
 fn cli_contimeout_with_equals_syntax() {
     let parsed = parse_args(["rsync", "--contimeout=10", "src/", "dst/"]).expect("parse");
@@ -73,3 +73,73 @@ fn transfer_request_with_from0_strips_comment_prefix_entries() {
     assert_eq!(code, 0);
     assert!(!dest_dir.join("#commented.txt").exists());
 }
+
+#[cfg(unix)]
+#[test]
+fn transfer_request_with_from0_reads_null_separated_exclude_from() {
+    use tempfile::tempdir;
+
+    let tmp = tempdir().expect("tempdir");
+    let source_dir = tmp.path().join("src");
+    std::fs::create_dir(&source_dir).expect("create source dir");
+    std::fs::write(source_dir.join("line\nbreak.txt"), b"skip").expect("write newline name");
+    std::fs::write(source_dir.join("line"), b"keep").expect("write line");
+    std::fs::write(source_dir.join("break.txt"), b"keep").expect("write break");
+
+    // One NUL-terminated record whose pattern contains a literal newline.
+    let exclude_path = tmp.path().join("exclude0.list");
+    std::fs::write(&exclude_path, b"line\nbreak.txt\0").expect("write exclude list");
+
+    let dest_dir = tmp.path().join("exclude0-dest");
+    std::fs::create_dir(&dest_dir).expect("create dest");
+
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("-r"),
+        OsString::from("--from0"),
+        OsString::from(format!("--exclude-from={}", exclude_path.display())),
+        OsString::from(format!("{}/", source_dir.display())),
+        dest_dir.clone().into_os_string(),
+    ]);
+
+    assert_eq!(code, 0);
+    assert!(stdout.is_empty());
+    assert!(stderr.is_empty());
+    assert!(!dest_dir.join("line\nbreak.txt").exists());
+    assert!(dest_dir.join("line").exists());
+    assert!(dest_dir.join("break.txt").exists());
+}
+
+#[test]
+fn transfer_request_with_from0_reads_null_separated_merge_file() {
+    use tempfile::tempdir;
+
+    let tmp = tempdir().expect("tempdir");
+    let source_dir = tmp.path().join("src");
+    std::fs::create_dir(&source_dir).expect("create source dir");
+    std::fs::write(source_dir.join("keep.txt"), b"keep").expect("write keep");
+    std::fs::write(source_dir.join("skip.tmp"), b"skip").expect("write skip");
+    std::fs::write(source_dir.join("skip.bak"), b"skip").expect("write skip");
+
+    let rules_path = tmp.path().join("rules0.txt");
+    std::fs::write(&rules_path, b"- *.tmp\0- *.bak\0").expect("write merge file");
+
+    let dest_dir = tmp.path().join("merge0-dest");
+    std::fs::create_dir(&dest_dir).expect("create dest");
+
+    let (code, stdout, stderr) = run_with_args([
+        OsString::from(RSYNC),
+        OsString::from("-r"),
+        OsString::from("--from0"),
+        OsString::from(format!("--filter=merge {}", rules_path.display())),
+        OsString::from(format!("{}/", source_dir.display())),
+        dest_dir.clone().into_os_string(),
+    ]);
+
+    assert_eq!(code, 0);
+    assert!(stdout.is_empty());
+    assert!(stderr.is_empty());
+    assert!(dest_dir.join("keep.txt").exists());
+    assert!(!dest_dir.join("skip.tmp").exists());
+    assert!(!dest_dir.join("skip.bak").exists());
+}
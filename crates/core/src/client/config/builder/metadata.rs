@@ -159,6 +159,28 @@ impl ClientConfigBuilder {
         self
     }
 
+    /// Requests that file flags (immutable, append-only, nodump, ...) be preserved.
+    ///
+    /// Applies to local copies on Linux (`chattr` inode flags) and the BSDs and
+    /// macOS (`chflags`). Corresponds to the `--fileflags` option.
+    #[must_use]
+    #[doc(alias = "--fileflags")]
+    pub const fn fileflags(mut self, preserve: bool) -> Self {
+        self.preserve_fileflags = preserve;
+        self
+    }
+
+    /// Selects which immutable/append-only flags may be cleared so a protected
+    /// destination file can be updated.
+    ///
+    /// Corresponds to `--force-change`, `--force-uchange` and `--force-schange`.
+    #[must_use]
+    #[doc(alias = "--force-change")]
+    pub const fn force_change(mut self, force: ForceChange) -> Self {
+        self.force_change = force;
+        self
+    }
+
     builder_setter! {
         /// Requests that directory timestamps be skipped when preserving times.
         #[doc(alias = "--omit-dir-times")]
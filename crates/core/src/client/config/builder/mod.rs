@@ -91,10 +91,11 @@ use super::{
     FilesFromSource, FilterRuleSpec, IconvSetting, ReferenceDirectory, ReferenceDirectoryKind,
     StrongChecksumChoice, TcpFastOpenMode, TransferTimeout,
 };
-use ::metadata::{ChmodModifiers, GroupMapping, UserMapping};
+use ::metadata::{ChmodModifiers, ForceChange, GroupMapping, UserMapping};
 use compress::algorithm::CompressionAlgorithm;
 use compress::zlib::CompressionLevel;
 use engine::SkipCompressList;
+use engine::local_copy::LocalCopyFileHooks;
 
 /// Builder used to assemble a [`ClientConfig`].
 ///
@@ -154,6 +155,8 @@ pub struct ClientConfigBuilder {
     rayon_threads: Option<NonZeroUsize>,
     tokio_threads: Option<NonZeroUsize>,
     max_alloc: Option<u64>,
+    file_list_limits: Option<protocol::flist::FileListLimits>,
+    copy_buffer_size: Option<usize>,
     modify_window: Option<i64>,
     remove_source_files: bool,
     remove_sent_files: bool,
@@ -171,6 +174,8 @@ pub struct ClientConfigBuilder {
     preserve_times: bool,
     preserve_atimes: u8,
     preserve_crtimes: bool,
+    preserve_fileflags: bool,
+    force_change: ForceChange,
     owner_override: Option<u32>,
     group_override: Option<u32>,
     copy_as: Option<OsString>,
@@ -240,6 +245,9 @@ pub struct ClientConfigBuilder {
     partial: bool,
     partial_dir: Option<PathBuf>,
     temp_directory: Option<PathBuf>,
+    checksum_cache_directory: Option<PathBuf>,
+    checksum_xattr_name: Option<OsString>,
+    resume_manifest: Option<PathBuf>,
     backup: bool,
     backup_dir: Option<PathBuf>,
     backup_suffix: Option<OsString>,
@@ -260,6 +268,7 @@ pub struct ClientConfigBuilder {
     address_mode: AddressMode,
     timeout: TransferTimeout,
     connect_timeout: TransferTimeout,
+    blocking_timeout: TransferTimeout,
     stop_deadline: Option<SystemTime>,
     link_dest_paths: Vec<PathBuf>,
     reference_directories: Vec<ReferenceDirectory>,
@@ -267,6 +276,7 @@ pub struct ClientConfigBuilder {
     bind_address: Option<BindAddress>,
     sockopts: Option<OsString>,
     tcp_fastopen: TcpFastOpenMode,
+    tls: bool,
     blocking_io: Option<bool>,
     iconv: IconvSetting,
     remote_shell: Option<Vec<OsString>>,
@@ -287,6 +297,8 @@ pub struct ClientConfigBuilder {
     remote_options: Vec<OsString>,
     daemon_params: Vec<String>,
     protocol_version: Option<protocol::ProtocolVersion>,
+    daemon_port: Option<u16>,
+    file_hooks: LocalCopyFileHooks,
     #[cfg(feature = "embedded-ssh")]
     embedded_ssh_config: Option<super::client::EmbeddedSshOptions>,
     #[cfg(all(any(unix, windows), feature = "acl"))]
@@ -402,6 +414,13 @@ impl ClientConfigBuilder {
             rayon_threads: self.rayon_threads,
             tokio_threads: self.tokio_threads,
             max_alloc: self.max_alloc,
+            file_list_limits: self.file_list_limits.unwrap_or_else(|| {
+                self.max_alloc.map_or_else(
+                    protocol::flist::FileListLimits::default,
+                    protocol::flist::FileListLimits::for_max_alloc,
+                )
+            }),
+            copy_buffer_size: self.copy_buffer_size,
             modify_window: self.modify_window,
             remove_source_files: self.remove_source_files,
             remove_sent_files: self.remove_sent_files,
@@ -419,6 +438,8 @@ impl ClientConfigBuilder {
             preserve_times: self.preserve_times,
             preserve_atimes: self.preserve_atimes,
             preserve_crtimes: self.preserve_crtimes,
+            preserve_fileflags: self.preserve_fileflags,
+            force_change: self.force_change,
             owner_override: self.owner_override,
             group_override: self.group_override,
             copy_as: self.copy_as,
@@ -491,6 +512,9 @@ impl ClientConfigBuilder {
             partial: self.partial,
             partial_dir: self.partial_dir,
             temp_directory: self.temp_directory,
+            checksum_cache_directory: self.checksum_cache_directory,
+            checksum_xattr_name: self.checksum_xattr_name,
+            resume_manifest: self.resume_manifest,
             backup: self.backup,
             backup_dir: self.backup_dir,
             backup_suffix: self.backup_suffix,
@@ -511,6 +535,7 @@ impl ClientConfigBuilder {
             address_mode: self.address_mode,
             timeout: self.timeout,
             connect_timeout: self.connect_timeout,
+            blocking_timeout: self.blocking_timeout,
             stop_at: self.stop_deadline,
             link_dest_paths: self.link_dest_paths,
             reference_directories: self.reference_directories,
@@ -518,6 +543,7 @@ impl ClientConfigBuilder {
             bind_address: self.bind_address,
             sockopts: self.sockopts,
             tcp_fastopen: self.tcp_fastopen,
+            tls: self.tls,
             blocking_io: self.blocking_io,
             iconv: self.iconv,
             remote_shell: self.remote_shell,
@@ -538,6 +564,8 @@ impl ClientConfigBuilder {
             remote_options: self.remote_options,
             daemon_params: self.daemon_params,
             protocol_version: self.protocol_version,
+            daemon_port: self.daemon_port,
+            file_hooks: self.file_hooks,
             #[cfg(feature = "embedded-ssh")]
             embedded_ssh_config: self.embedded_ssh_config,
             #[cfg(all(any(unix, windows), feature = "acl"))]
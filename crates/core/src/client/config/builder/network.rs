@@ -29,6 +29,17 @@ impl ClientConfigBuilder {
         self
     }
 
+    /// Wraps daemon connections in TLS.
+    ///
+    /// oc-rsync extension: the daemon must be an oc-rsync daemon configured
+    /// with `tls cert file` / `tls key file`.
+    #[must_use]
+    #[doc(alias = "--tls")]
+    pub const fn tls(mut self, enabled: bool) -> Self {
+        self.tls = enabled;
+        self
+    }
+
     builder_setter! {
         /// Controls whether blocking I/O should be forced for remote shells.
         #[doc(alias = "--blocking-io")]
@@ -43,6 +54,10 @@ impl ClientConfigBuilder {
         #[doc(alias = "--contimeout")]
         connect_timeout: TransferTimeout,
 
+        /// Sets the deadline applied to each read or write on the transfer socket.
+        #[doc(alias = "--blocking-timeout")]
+        blocking_timeout: TransferTimeout,
+
         /// Selects the preferred address family for network operations.
         #[doc(alias = "--ipv4")]
         #[doc(alias = "--ipv6")]
@@ -226,4 +241,16 @@ impl ClientConfigBuilder {
         self.protocol_version = version;
         self
     }
+
+    /// Sets the daemon port used when an `rsync://` or `host::module` operand
+    /// does not name one.
+    ///
+    /// A port embedded in the URL still takes precedence, matching upstream
+    /// `main.c` where `rsync_port` only replaces the default of 873.
+    #[must_use]
+    #[doc(alias = "--port")]
+    pub const fn daemon_port(mut self, port: Option<u16>) -> Self {
+        self.daemon_port = port;
+        self
+    }
 }
@@ -143,4 +143,17 @@ impl ClientConfigBuilder {
         self.daemon_params = params;
         self
     }
+
+    /// Installs per-file start, done and veto callbacks.
+    ///
+    /// The hooks are local-only: they run in this process's generator, so
+    /// they see each regular file of a local copy or of a pull over a remote
+    /// shell or daemon. A push runs the generator on the remote host, and
+    /// [`run_client`](crate::client::run_client) rejects hooks there with a
+    /// syntax error instead of ignoring a veto.
+    #[must_use]
+    pub fn file_hooks(mut self, hooks: LocalCopyFileHooks) -> Self {
+        self.file_hooks = hooks;
+        self
+    }
 }
@@ -39,6 +39,33 @@ impl ClientConfigBuilder {
         self
     }
 
+    /// Configures the directory that persists `--checksum` sums across local
+    /// copies.
+    #[must_use]
+    #[doc(alias = "--checksum-cache")]
+    pub fn checksum_cache_directory<P: Into<PathBuf>>(mut self, directory: Option<P>) -> Self {
+        self.checksum_cache_directory = directory.map(Into::into);
+        self
+    }
+
+    /// Names the extended attribute that may hold each file's precomputed
+    /// `--checksum` sum.
+    #[must_use]
+    #[doc(alias = "--checksum-xattr")]
+    pub fn checksum_xattr_name<N: Into<OsString>>(mut self, name: Option<N>) -> Self {
+        self.checksum_xattr_name = name.map(Into::into);
+        self
+    }
+
+    /// Configures the manifest that records files a daemon pull completed,
+    /// so a rerun resumes after the last one.
+    #[must_use]
+    #[doc(alias = "--resume-manifest")]
+    pub fn resume_manifest<P: Into<PathBuf>>(mut self, path: Option<P>) -> Self {
+        self.resume_manifest = path.map(Into::into);
+        self
+    }
+
     /// Enables or disables in-place updates for destination files.
     #[must_use]
     #[doc(alias = "--inplace")]
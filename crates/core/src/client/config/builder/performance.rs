@@ -188,6 +188,32 @@ impl ClientConfigBuilder {
         max_alloc: Option<u64>,
     }
 
+    /// Caps the file list accepted when pulling from a daemon.
+    ///
+    /// Without this call the caps are
+    /// [`FileListLimits::default`](protocol::flist::FileListLimits), which
+    /// keeps a malicious server from exhausting memory with an endless list,
+    /// or [`FileListLimits::for_max_alloc`](protocol::flist::FileListLimits::for_max_alloc)
+    /// when [`max_alloc`](Self::max_alloc) is set. Pass
+    /// [`FileListLimits::UNLIMITED`](protocol::flist::FileListLimits::UNLIMITED)
+    /// to leave only `--max-alloc` in force.
+    #[must_use]
+    pub const fn file_list_limits(mut self, limits: protocol::flist::FileListLimits) -> Self {
+        self.file_list_limits = Some(limits);
+        self
+    }
+
+    builder_setter! {
+        /// Sets the size of the buffer file contents are streamed through.
+        ///
+        /// Local copies use it when the kernel copy fast path is not taken;
+        /// remote transfers use it for the local sender's reads or the local
+        /// receiver's writes. It is never forwarded to the remote side.
+        /// `None` keeps the default buffers.
+        #[doc(alias = "--copy-buffer-size")]
+        copy_buffer_size: Option<usize>,
+    }
+
     builder_setter! {
         /// Enables or disables sparse file handling for the transfer.
         #[doc(alias = "--sparse")]
@@ -437,6 +437,20 @@ fn crtimes_false_clears_flag() {
     assert!(!config.preserve_crtimes());
 }
 
+#[test]
+fn fileflags_sets_flag() {
+    let config = builder().fileflags(true).build();
+    assert!(config.preserve_fileflags());
+}
+
+#[test]
+fn force_change_sets_selection() {
+    let force = ForceChange::new(true, false);
+    let config = builder().force_change(force).build();
+    assert_eq!(config.force_change(), force);
+    assert!(builder().build().force_change().is_none());
+}
+
 #[test]
 fn omit_dir_times_sets_flag() {
     let config = builder().omit_dir_times(true).build();
@@ -584,6 +598,12 @@ fn sockopts_none_clears_value() {
     assert!(config.sockopts().is_none());
 }
 
+#[test]
+fn tls_sets_flag() {
+    let config = builder().tls(true).build();
+    assert!(config.tls());
+}
+
 #[test]
 fn blocking_io_sets_some_true() {
     let config = builder().blocking_io(Some(true)).build();
@@ -621,6 +641,15 @@ fn timeout_sets_seconds() {
     assert_eq!(config.timeout().as_seconds(), Some(seconds));
 }
 
+#[test]
+fn blocking_timeout_sets_value() {
+    let seconds = NonZeroU64::new(10).unwrap();
+    let config = builder()
+        .blocking_timeout(TransferTimeout::Seconds(seconds))
+        .build();
+    assert_eq!(config.blocking_timeout().as_seconds(), Some(seconds));
+}
+
 #[test]
 fn connect_timeout_sets_value() {
     let seconds = NonZeroU64::new(30).unwrap();
@@ -728,6 +757,15 @@ fn early_input_sets_path() {
     assert!(config.early_input().is_some());
 }
 
+#[test]
+fn daemon_port_defaults_to_none() {
+    assert_eq!(builder().build().daemon_port(), None);
+    assert_eq!(
+        builder().daemon_port(Some(2873)).build().daemon_port(),
+        Some(2873)
+    );
+}
+
 #[test]
 fn early_input_none_clears_path() {
     let config = builder()
@@ -869,6 +907,14 @@ fn default_human_readable_is_false() {
     assert!(!config.human_readable());
 }
 
+#[test]
+fn file_hooks_sets_callbacks() {
+    let hooks = engine::local_copy::LocalCopyFileHooks::new().should_transfer(|_| false);
+    let config = builder().file_hooks(hooks.clone()).build();
+    assert_eq!(config.file_hooks(), &hooks);
+    assert!(builder().build().file_hooks().is_empty());
+}
+
 #[test]
 fn daemon_params_sets_values() {
     let params = vec!["read only=true".to_owned(), "timeout=60".to_owned()];
@@ -1633,6 +1679,50 @@ fn max_alloc_none_clears_limit() {
     assert!(config.max_alloc().is_none());
 }
 
+#[test]
+fn file_list_limits_default_to_untrusted_caps() {
+    let config = builder().build();
+    assert_eq!(
+        config.file_list_limits(),
+        protocol::flist::FileListLimits::default()
+    );
+}
+
+#[test]
+fn file_list_limits_scale_with_max_alloc() {
+    let config = builder().max_alloc(Some(8 * 1024 * 1024 * 1024)).build();
+    assert_eq!(
+        config.file_list_limits(),
+        protocol::flist::FileListLimits::for_max_alloc(8 * 1024 * 1024 * 1024)
+    );
+
+    let explicit = builder()
+        .file_list_limits(protocol::flist::FileListLimits::UNLIMITED)
+        .max_alloc(Some(1024))
+        .build();
+    assert_eq!(
+        explicit.file_list_limits(),
+        protocol::flist::FileListLimits::UNLIMITED
+    );
+}
+
+#[test]
+fn file_list_limits_can_be_lifted() {
+    let config = builder()
+        .file_list_limits(protocol::flist::FileListLimits::UNLIMITED)
+        .build();
+    assert_eq!(
+        config.file_list_limits(),
+        protocol::flist::FileListLimits::UNLIMITED
+    );
+}
+
+#[test]
+fn copy_buffer_size_sets_value() {
+    let config = builder().copy_buffer_size(Some(512 * 1024)).build();
+    assert_eq!(config.copy_buffer_size(), Some(512 * 1024));
+}
+
 #[test]
 fn sparse_sets_flag() {
     let config = builder().sparse(true).build();
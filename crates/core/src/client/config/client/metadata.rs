@@ -131,6 +131,22 @@ impl ClientConfig {
         self.preserve_crtimes
     }
 
+    /// Reports whether file flags should be preserved.
+    ///
+    /// Corresponds to the `--fileflags` option.
+    #[must_use]
+    #[doc(alias = "--fileflags")]
+    pub const fn preserve_fileflags(&self) -> bool {
+        self.preserve_fileflags
+    }
+
+    /// Returns which immutable/append-only flags may be cleared to update a file.
+    #[must_use]
+    #[doc(alias = "--force-change")]
+    pub const fn force_change(&self) -> ForceChange {
+        self.force_change
+    }
+
     /// Reports whether directory timestamps should be skipped when preserving times.
     #[must_use]
     #[doc(alias = "--omit-dir-times")]
@@ -325,6 +341,12 @@ mod tests {
         assert!(!config.preserve_crtimes());
     }
 
+    #[test]
+    fn preserve_fileflags_default_is_false() {
+        let config = default_config();
+        assert!(!config.preserve_fileflags());
+    }
+
     #[test]
     fn omit_dir_times_default_is_false() {
         let config = default_config();
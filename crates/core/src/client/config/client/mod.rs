@@ -3,10 +3,11 @@ use std::num::{NonZeroU32, NonZeroUsize};
 use std::path::{Path, PathBuf};
 use std::time::SystemTime;
 
-use ::metadata::{ChmodModifiers, GroupMapping, ModifyWindow, UserMapping};
+use ::metadata::{ChmodModifiers, ForceChange, GroupMapping, ModifyWindow, UserMapping};
 use compress::algorithm::CompressionAlgorithm;
 use compress::zlib::CompressionLevel;
 use engine::SkipCompressList;
+use engine::local_copy::LocalCopyFileHooks;
 
 use super::builder::ClientConfigBuilder;
 use super::{
@@ -72,6 +73,8 @@ pub struct ClientConfig {
     pub(super) rayon_threads: Option<NonZeroUsize>,
     pub(super) tokio_threads: Option<NonZeroUsize>,
     pub(super) max_alloc: Option<u64>,
+    pub(super) file_list_limits: protocol::flist::FileListLimits,
+    pub(super) copy_buffer_size: Option<usize>,
     pub(super) modify_window: Option<i64>,
     pub(super) remove_source_files: bool,
     /// Whether the user spelled the deprecated `--remove-sent-files` alias (and
@@ -118,6 +121,8 @@ pub struct ClientConfig {
     /// Access-time preservation level: 0 = off, 1 = `-U`, 2 = `-UU`.
     pub(super) preserve_atimes: u8,
     pub(super) preserve_crtimes: bool,
+    pub(super) preserve_fileflags: bool,
+    pub(super) force_change: ForceChange,
     pub(super) owner_override: Option<u32>,
     pub(super) group_override: Option<u32>,
     pub(super) copy_as: Option<OsString>,
@@ -212,6 +217,9 @@ pub struct ClientConfig {
     pub(super) partial: bool,
     pub(super) partial_dir: Option<PathBuf>,
     pub(super) temp_directory: Option<PathBuf>,
+    pub(super) checksum_cache_directory: Option<PathBuf>,
+    pub(super) checksum_xattr_name: Option<OsString>,
+    pub(super) resume_manifest: Option<PathBuf>,
     pub(super) backup: bool,
     pub(super) backup_dir: Option<PathBuf>,
     pub(super) backup_suffix: Option<OsString>,
@@ -250,6 +258,7 @@ pub struct ClientConfig {
     pub(super) address_mode: AddressMode,
     pub(super) timeout: TransferTimeout,
     pub(super) connect_timeout: TransferTimeout,
+    pub(super) blocking_timeout: TransferTimeout,
     pub(super) stop_at: Option<SystemTime>,
     pub(super) link_dest_paths: Vec<PathBuf>,
     pub(super) reference_directories: Vec<ReferenceDirectory>,
@@ -257,6 +266,7 @@ pub struct ClientConfig {
     pub(super) bind_address: Option<BindAddress>,
     pub(super) sockopts: Option<OsString>,
     pub(super) tcp_fastopen: TcpFastOpenMode,
+    pub(super) tls: bool,
     pub(super) blocking_io: Option<bool>,
     pub(super) iconv: IconvSetting,
     pub(super) remote_shell: Option<Vec<OsString>>,
@@ -307,6 +317,10 @@ pub struct ClientConfig {
     pub(super) remote_options: Vec<OsString>,
     pub(super) daemon_params: Vec<String>,
     pub(super) protocol_version: Option<protocol::ProtocolVersion>,
+    /// `--port` value applied to daemon operands that omit a port.
+    pub(super) daemon_port: Option<u16>,
+    /// Per-file callbacks supplied by an embedding program.
+    pub(super) file_hooks: LocalCopyFileHooks,
     #[cfg(feature = "embedded-ssh")]
     pub(super) embedded_ssh_config: Option<EmbeddedSshOptions>,
     #[cfg(all(any(unix, windows), feature = "acl"))]
@@ -360,6 +374,8 @@ impl Default for ClientConfig {
             rayon_threads: None,
             tokio_threads: None,
             max_alloc: None,
+            file_list_limits: protocol::flist::FileListLimits::default(),
+            copy_buffer_size: None,
             modify_window: None,
             remove_source_files: false,
             remove_sent_files: false,
@@ -377,6 +393,8 @@ impl Default for ClientConfig {
             preserve_times: false,
             preserve_atimes: 0,
             preserve_crtimes: false,
+            preserve_fileflags: false,
+            force_change: ForceChange::NONE,
             owner_override: None,
             group_override: None,
             copy_as: None,
@@ -438,6 +456,9 @@ impl Default for ClientConfig {
             partial: false,
             partial_dir: None,
             temp_directory: None,
+            checksum_cache_directory: None,
+            checksum_xattr_name: None,
+            resume_manifest: None,
             backup: false,
             backup_dir: None,
             backup_suffix: None,
@@ -466,6 +487,7 @@ impl Default for ClientConfig {
             address_mode: AddressMode::Default,
             timeout: TransferTimeout::Default,
             connect_timeout: TransferTimeout::Default,
+            blocking_timeout: TransferTimeout::Default,
             stop_at: None,
             link_dest_paths: Vec::new(),
             reference_directories: Vec::new(),
@@ -473,6 +495,7 @@ impl Default for ClientConfig {
             bind_address: None,
             sockopts: None,
             tcp_fastopen: TcpFastOpenMode::Auto,
+            tls: false,
             blocking_io: None,
             iconv: IconvSetting::Unspecified,
             remote_shell: None,
@@ -493,6 +516,8 @@ impl Default for ClientConfig {
             remote_options: Vec::new(),
             daemon_params: Vec::new(),
             protocol_version: None,
+            daemon_port: None,
+            file_hooks: LocalCopyFileHooks::new(),
             #[cfg(feature = "embedded-ssh")]
             embedded_ssh_config: None,
             #[cfg(all(any(unix, windows), feature = "acl"))]
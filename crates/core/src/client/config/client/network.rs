@@ -38,6 +38,13 @@ impl ClientConfig {
         self.tcp_fastopen
     }
 
+    /// Returns whether daemon connections are wrapped in TLS.
+    #[doc(alias = "--tls")]
+    #[must_use]
+    pub const fn tls(&self) -> bool {
+        self.tls
+    }
+
     /// Returns the requested blocking I/O preference for remote shells.
     #[doc(alias = "--blocking-io")]
     #[doc(alias = "--no-blocking-io")]
@@ -80,6 +87,32 @@ impl ClientConfig {
         self.connect_timeout
     }
 
+    /// Returns the configured per-operation socket deadline.
+    #[must_use]
+    #[doc(alias = "--blocking-timeout")]
+    pub const fn blocking_timeout(&self) -> TransferTimeout {
+        self.blocking_timeout
+    }
+
+    /// Resolves the read/write timeout applied to a daemon or TCP transfer
+    /// socket.
+    ///
+    /// `--timeout` alone keeps its upstream meaning. `--blocking-timeout` bounds
+    /// every individual `read(2)`/`write(2)`, so a peer that stops draining the
+    /// socket fails the blocked call instead of hanging it; when both are set
+    /// the shorter one applies. Returns `None` when neither is set.
+    #[must_use]
+    pub fn transfer_socket_timeout(&self) -> Option<std::time::Duration> {
+        let seconds = match (
+            self.timeout.as_seconds(),
+            self.blocking_timeout.as_seconds(),
+        ) {
+            (Some(io), Some(blocking)) => Some(io.min(blocking)),
+            (io, blocking) => io.or(blocking),
+        };
+        seconds.map(|secs| std::time::Duration::from_secs(secs.get()))
+    }
+
     /// Returns the configured stop-at deadline, if any.
     #[doc(alias = "--stop-after")]
     #[doc(alias = "--stop-at")]
@@ -195,6 +228,13 @@ impl ClientConfig {
     pub const fn protocol_version(&self) -> Option<protocol::ProtocolVersion> {
         self.protocol_version
     }
+
+    /// Returns the `--port` value used for daemon operands without a port.
+    #[must_use]
+    #[doc(alias = "--port")]
+    pub const fn daemon_port(&self) -> Option<u16> {
+        self.daemon_port
+    }
 }
 
 #[cfg(test)]
@@ -235,6 +275,12 @@ mod tests {
         assert_eq!(config.tcp_fastopen(), TcpFastOpenMode::Auto);
     }
 
+    #[test]
+    fn tls_default_is_off() {
+        let config = default_config();
+        assert!(!config.tls());
+    }
+
     #[test]
     fn blocking_io_default_is_none() {
         let config = default_config();
@@ -259,6 +305,34 @@ mod tests {
         assert_eq!(config.connect_timeout(), TransferTimeout::Default);
     }
 
+    #[test]
+    fn blocking_timeout_default_is_default() {
+        let config = default_config();
+        assert_eq!(config.blocking_timeout(), TransferTimeout::Default);
+        assert_eq!(config.transfer_socket_timeout(), None);
+    }
+
+    #[test]
+    fn transfer_socket_timeout_takes_the_shorter_deadline() {
+        let secs = |n| TransferTimeout::Seconds(std::num::NonZeroU64::new(n).unwrap());
+        let mut config = default_config();
+        config.timeout = secs(60);
+        assert_eq!(
+            config.transfer_socket_timeout(),
+            Some(std::time::Duration::from_secs(60))
+        );
+        config.blocking_timeout = secs(5);
+        assert_eq!(
+            config.transfer_socket_timeout(),
+            Some(std::time::Duration::from_secs(5))
+        );
+        config.timeout = TransferTimeout::Disabled;
+        assert_eq!(
+            config.transfer_socket_timeout(),
+            Some(std::time::Duration::from_secs(5))
+        );
+    }
+
     #[test]
     fn ssh_io_timeout_maps_positive_timeout_to_seconds() {
         // WHY: --timeout N must reach the SSH stall watchdog so a hung remote
@@ -142,6 +142,12 @@ impl ClientConfig {
     pub fn daemon_params(&self) -> &[String] {
         &self.daemon_params
     }
+
+    /// Returns the per-file callbacks installed by the caller.
+    #[must_use]
+    pub const fn file_hooks(&self) -> &LocalCopyFileHooks {
+        &self.file_hooks
+    }
 }
 
 #[cfg(test)]
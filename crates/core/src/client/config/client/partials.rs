@@ -29,6 +29,25 @@ impl ClientConfig {
         self.temp_directory.as_deref()
     }
 
+    /// Returns the directory holding persistent `--checksum` sums, if any.
+    #[doc(alias = "--checksum-cache")]
+    pub fn checksum_cache_directory(&self) -> Option<&Path> {
+        self.checksum_cache_directory.as_deref()
+    }
+
+    /// Returns the extended attribute consulted for precomputed `--checksum`
+    /// sums, if any.
+    #[doc(alias = "--checksum-xattr")]
+    pub fn checksum_xattr_name(&self) -> Option<&OsStr> {
+        self.checksum_xattr_name.as_deref()
+    }
+
+    /// Returns the manifest recording files completed by a daemon pull, if any.
+    #[doc(alias = "--resume-manifest")]
+    pub fn resume_manifest(&self) -> Option<&Path> {
+        self.resume_manifest.as_deref()
+    }
+
     /// Reports whether destination updates should be performed in place.
     #[must_use]
     #[doc(alias = "--inplace")]
@@ -142,6 +161,24 @@ mod tests {
         assert!(config.temp_directory().is_none());
     }
 
+    #[test]
+    fn checksum_cache_directory_default_is_none() {
+        let config = default_config();
+        assert!(config.checksum_cache_directory().is_none());
+    }
+
+    #[test]
+    fn checksum_xattr_name_default_is_none() {
+        let config = default_config();
+        assert!(config.checksum_xattr_name().is_none());
+    }
+
+    #[test]
+    fn resume_manifest_default_is_none() {
+        let config = default_config();
+        assert!(config.resume_manifest().is_none());
+    }
+
     #[test]
     fn inplace_default_is_false() {
         let config = default_config();
@@ -199,6 +199,17 @@ impl ClientConfig {
         self.max_alloc
     }
 
+    /// Returns the caps applied to a file list received from a daemon.
+    pub const fn file_list_limits(&self) -> protocol::flist::FileListLimits {
+        self.file_list_limits
+    }
+
+    /// Returns the configured file I/O buffer size in bytes, if any.
+    #[doc(alias = "--copy-buffer-size")]
+    pub const fn copy_buffer_size(&self) -> Option<usize> {
+        self.copy_buffer_size
+    }
+
     /// Reports whether qsort should be used instead of merge sort for file lists.
     ///
     /// When enabled, uses qsort for file list sorting which may be faster
@@ -332,6 +343,12 @@ mod tests {
         assert!(config.max_alloc().is_none());
     }
 
+    #[test]
+    fn copy_buffer_size_default_is_none() {
+        let config = default_config();
+        assert!(config.copy_buffer_size().is_none());
+    }
+
     #[test]
     fn qsort_default_is_false() {
         let config = default_config();
@@ -1,10 +1,11 @@
+use std::error::Error;
+use std::ffi::OsStr;
 use std::fmt;
 use std::io;
 use std::path::Path;
 
-use thiserror::Error;
-
 use crate::exit_code::{ErrorCodification, ExitCode, HasExitCode};
+use crate::failure::{AuthError, Failure, TimeoutError, TransferError};
 use crate::message::{Message, Role};
 use crate::rsync_error;
 use engine::local_copy::{LocalCopyError, LocalCopyErrorKind, upstream_io_error};
@@ -35,17 +36,36 @@ pub const REMOTE_COMMAND_NOT_FOUND_EXIT_CODE: i32 = ExitCode::CommandNotFound.as
 /// Uses the centralized `ExitCode` enum to ensure exit codes match
 /// upstream rsync behavior. Exit codes are defined in upstream `errcode.h`
 /// and mapped to string names in `log.c`.
-#[derive(Clone, Debug, Error)]
-#[error("{message}")]
+///
+/// When the failure is a protocol, authentication, per-file transfer or
+/// timeout error, [`Error::source`] returns the matching type from
+/// [`crate::failure`] so callers can branch without parsing the message.
+#[derive(Clone, Debug)]
 pub struct ClientError {
     exit_code: ExitCode,
     message: Message,
+    failure: Option<Failure>,
 }
 
 impl ClientError {
     /// Creates a new [`ClientError`] from the supplied exit code and message.
-    pub(crate) const fn with_code(exit_code: ExitCode, message: Message) -> Self {
-        Self { exit_code, message }
+    ///
+    /// Exit codes that imply a failure kind (protocol, timeout) classify the
+    /// error automatically; other kinds are attached with [`Self::with_failure`].
+    pub(crate) fn with_code(exit_code: ExitCode, message: Message) -> Self {
+        let failure = Failure::from_exit_code(exit_code, message.text());
+        Self {
+            exit_code,
+            message,
+            failure,
+        }
+    }
+
+    /// Attaches a typed failure cause, replacing any derived from the exit code.
+    #[must_use]
+    pub(crate) fn with_failure(mut self, failure: impl Into<Failure>) -> Self {
+        self.failure = Some(failure.into());
+        self
     }
 
     /// Creates a new [`ClientError`] from an i32 exit code and message.
@@ -76,6 +96,30 @@ impl ClientError {
     pub const fn message(&self) -> &Message {
         &self.message
     }
+
+    /// Returns the typed failure cause, when the error has one.
+    #[must_use]
+    pub const fn failure(&self) -> Option<&Failure> {
+        self.failure.as_ref()
+    }
+
+    /// Returns the failure cause as `T`, mirroring `source()?.downcast_ref()`.
+    #[must_use]
+    pub fn failure_as<T: Error + 'static>(&self) -> Option<&T> {
+        self.source()?.downcast_ref::<T>()
+    }
+}
+
+impl fmt::Display for ClientError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        fmt::Display::fmt(&self.message, f)
+    }
+}
+
+impl Error for ClientError {
+    fn source(&self) -> Option<&(dyn Error + 'static)> {
+        self.failure.as_ref().map(|failure| failure.as_error() as _)
+    }
 }
 
 impl HasExitCode for ClientError {
@@ -238,13 +282,10 @@ pub(crate) fn map_local_copy_error(error: LocalCopyError) -> ClientError {
             // whose NotFound branch maps to RERR_VANISHED (24) for files that
             // disappear mid-transfer.
             let code = ExitCode::PartialTransfer;
-            let text = format!(
-                "link_stat \"{}\" failed: {}",
-                path.display(),
-                upstream_io_error(&source)
-            );
+            let detail = upstream_io_error(&source);
+            let text = format!("link_stat \"{}\" failed: {detail}", path.display());
             let message = rsync_error!(code.as_i32(), text).with_role(Role::Sender);
-            ClientError::with_code(code, message)
+            ClientError::with_code(code, message).with_failure(TransferError::new(path, detail))
         }
         LocalCopyErrorKind::Timeout { duration } => {
             let code = ExitCode::Timeout;
@@ -252,8 +293,9 @@ pub(crate) fn map_local_copy_error(error: LocalCopyError) -> ClientError {
                 "transfer timed out after {:.3} seconds without progress",
                 duration.as_secs_f64()
             );
+            let failure = TimeoutError::new(Some(duration), false, text.clone());
             let message = rsync_error!(code.as_i32(), text).with_role(Role::Client);
-            ClientError::with_code(code, message)
+            ClientError::with_code(code, message).with_failure(failure)
         }
         LocalCopyErrorKind::DeleteLimitExceeded { skipped } => {
             // upstream: generator.c:2431 - the generator emits
@@ -342,8 +384,9 @@ pub(crate) fn io_error(action: &str, path: &Path, error: io::Error) -> ClientErr
             upstream_io_error(&error)
         )
     };
+    let detail = upstream_io_error(&error);
     let message = rsync_error!(code.as_i32(), text).with_role(Role::Sender);
-    ClientError::with_code(code, message)
+    ClientError::with_code(code, message).with_failure(TransferError::new(path, detail))
 }
 
 #[cold]
@@ -358,6 +401,7 @@ pub(crate) fn destination_access_error(path: &Path, error: io::Error) -> ClientE
     );
     let message = rsync_error!(code.as_i32(), text).with_role(Role::Client);
     ClientError::with_code(code, message)
+        .with_failure(TransferError::new(path, upstream_io_error(&error)))
 }
 
 /// Validates a `--temp-dir` argument before transferring, mirroring upstream's
@@ -399,12 +443,58 @@ pub(crate) fn validate_temp_dir(temp_dir: &Path) -> Result<(), ClientError> {
     }
 }
 
+/// Resolves a `--copy-as=USER[:GROUP]` specification for the local receiver.
+///
+/// An unknown user or group is fatal rather than dropped: falling back to the
+/// invoking identity would write every file as that user, which may be root.
+/// The wording matches the server receiver's refusal of the same input.
+///
+/// Every remote transfer's receiver config runs its `--copy-as` through here.
+/// `become_copy_as_user()` runs wherever the receiver runs, and `--copy-as`
+/// is never forwarded by `server_options()`: on a pull the local client is
+/// the receiver and switches identity itself, while a push reaches the remote
+/// receiver only through `--remote-option`.
+///
+/// upstream: main.c:do_recv() calls become_copy_as_user(), which exits with
+/// `RERR_SYNTAX` when the copy-as user or group cannot be resolved.
+pub(crate) fn resolve_copy_as(
+    spec: Option<&OsStr>,
+) -> Result<Option<::metadata::CopyAsIds>, ClientError> {
+    let Some(spec) = spec else {
+        return Ok(None);
+    };
+    ::metadata::parse_copy_as_spec(spec)
+        .map(Some)
+        .map_err(|error| {
+            let message = rsync_error!(
+                ExitCode::Syntax.as_i32(),
+                format!("invalid --copy-as: {error}")
+            )
+            .with_role(Role::Receiver);
+            ClientError::with_code(ExitCode::Syntax, message)
+        })
+}
+
 #[cold]
 fn temp_dir_error(text: String, code: ExitCode) -> ClientError {
     let message = rsync_error!(code.as_i32(), text).with_role(Role::Receiver);
     ClientError::with_code(code, message)
 }
 
+/// Failure to read, record, or remove a `--resume-manifest` file.
+///
+/// The manifest only ever exists on the receiving side of a daemon pull.
+#[cold]
+pub(crate) fn resume_manifest_error(action: &str, path: &Path, error: io::Error) -> ClientError {
+    let text = format!(
+        "failed to {action} resume manifest {}: {}",
+        path.display(),
+        upstream_io_error(&error)
+    );
+    let message = rsync_error!(FILE_IO_EXIT_CODE, text).with_role(Role::Receiver);
+    ClientError::with_code(ExitCode::FileIo, message)
+}
+
 #[cold]
 pub(crate) fn socket_error(
     action: &str,
@@ -432,8 +522,9 @@ pub(crate) fn connect_timeout_error(target: impl fmt::Display, error: io::Error)
         "failed to connect to {target}: {}",
         upstream_io_error(&error)
     );
+    let failure = TimeoutError::new(None, true, text.clone());
     let message = rsync_error!(code.as_i32(), text).with_role(Role::Client);
-    ClientError::with_code(code, message)
+    ClientError::with_code(code, message).with_failure(failure)
 }
 
 /// Builds the canonical "connection unexpectedly closed" diagnostic that
@@ -487,7 +578,7 @@ pub(crate) fn daemon_authentication_required_error(reason: &str) -> ClientError
         format!("daemon requires authentication for module listing: {reason}")
     };
 
-    daemon_error(detail, FEATURE_UNAVAILABLE_EXIT_CODE)
+    daemon_error(&detail, FEATURE_UNAVAILABLE_EXIT_CODE).with_failure(AuthError::new(None, detail))
 }
 
 #[cold]
@@ -499,7 +590,7 @@ pub(crate) fn daemon_authentication_failed_error(reason: Option<&str>) -> Client
         _ => "daemon rejected provided credentials".to_owned(),
     };
 
-    daemon_error(detail, FEATURE_UNAVAILABLE_EXIT_CODE)
+    daemon_error(&detail, FEATURE_UNAVAILABLE_EXIT_CODE).with_failure(AuthError::new(None, detail))
 }
 
 #[cold]
@@ -1031,6 +1122,31 @@ mod tests {
             assert!(!msg.contains("credentials: "));
         }
 
+        #[test]
+        fn daemon_authentication_failed_error_exposes_auth_failure() {
+            let error = daemon_authentication_failed_error(Some("wrong password"));
+
+            let auth = error
+                .failure_as::<crate::failure::AuthError>()
+                .expect("auth failure cause");
+            assert_eq!(auth.module(), None);
+            assert!(auth.detail().contains("wrong password"));
+        }
+
+        #[test]
+        fn io_error_exposes_transfer_failure_path() {
+            let error = io_error(
+                "read",
+                Path::new("src/file.txt"),
+                io::Error::from(io::ErrorKind::PermissionDenied),
+            );
+
+            let transfer = error
+                .failure_as::<crate::failure::TransferError>()
+                .expect("transfer failure cause");
+            assert_eq!(transfer.path(), Path::new("src/file.txt"));
+        }
+
         #[test]
         fn daemon_authentication_failed_error_with_empty_string() {
             let error = daemon_authentication_failed_error(Some(""));
@@ -33,6 +33,8 @@
 //!   [`run_module_list`](crate::client::run_module_list) connects to the remote
 //!   daemon using the legacy `@RSYNCD:` negotiation to retrieve the advertised
 //!   module table.
+//! - [`walk_remote`](crate::client::walk_remote) streams a daemon module's
+//!   file list entry by entry as a list-only pull receives it.
 //! - [`ClientError`](crate::client::ClientError) carries the exit code and fully
 //!   formatted [`crate::message::Message`] so binaries can surface diagnostics
 //!   via the central rendering helpers.
@@ -96,6 +98,7 @@ mod outcome;
 mod progress;
 /// Remote transfer orchestration for SSH and daemon transports.
 pub mod remote;
+mod remote_walk;
 mod run;
 mod summary;
 
@@ -122,6 +125,7 @@ pub use self::module_list::{
 };
 pub use self::outcome::ClientOutcome;
 pub use self::progress::{ClientProgressObserver, ClientProgressUpdate};
+pub use self::remote_walk::{RemoteFileEntry, RemoteWalk, walk_remote};
 pub use self::run::{run_client, run_client_with_observer};
 pub use self::summary::{
     ClientEntryKind, ClientEntryMetadata, ClientEvent, ClientEventKind, ClientSummary,
@@ -129,7 +133,9 @@ pub use self::summary::{
 };
 pub use engine::SkipCompressList;
 pub use engine::batch::{BatchConfig, BatchMode};
-pub use engine::local_copy::{DirMergeEnforcedKind, DirMergeOptions};
+pub use engine::local_copy::{
+    DirMergeEnforcedKind, DirMergeOptions, LocalCopyFileHooks, LocalCopyFileInfo,
+};
 
 use std::time::Duration;
 
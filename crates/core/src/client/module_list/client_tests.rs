@@ -24,10 +24,10 @@ use super::super::{
 };
 use super::auth::set_test_daemon_password;
 use super::{
-    DaemonAddress, DaemonAuthDigest, ModuleListOptions, ModuleListRequest, ProxyConfig,
-    compute_daemon_auth_response, establish_proxy_tunnel, map_daemon_handshake_error,
-    resolve_daemon_addresses, run_module_list, run_module_list_with_options,
-    run_module_list_with_password,
+    DaemonAddress, DaemonAuthDigest, ModuleList, ModuleListEntry, ModuleListOptions,
+    ModuleListRequest, ProxyConfig, compute_daemon_auth_response, establish_proxy_tunnel,
+    map_daemon_handshake_error, resolve_daemon_addresses, run_module_list,
+    run_module_list_with_options, run_module_list_with_password,
 };
 
 const LEGACY_DAEMON_GREETING: &str = "@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4\n";
@@ -282,6 +282,15 @@ fn run_module_list_reports_authentication_failure() {
             .to_string()
             .contains("rejected provided credentials")
     );
+    let auth = std::error::Error::source(&error)
+        .and_then(|source| source.downcast_ref::<crate::failure::AuthError>())
+        .expect("auth failure is exposed as the error source");
+    assert!(auth.detail().contains("rejected provided credentials"));
+    assert!(
+        error
+            .failure_as::<crate::failure::ProtocolError>()
+            .is_none()
+    );
 
     handle.join().expect("server thread");
 }
@@ -369,6 +378,100 @@ fn run_module_list_suppresses_plaintext_motd_when_requested() {
     handle.join().expect("daemon thread completes");
 }
 
+/// Responses shaped like a real daemon listing: the MOTD block and its
+/// trailing empty line, then `%-15s\t%s` rows, then `@RSYNCD: EXIT` with no
+/// `@RSYNCD: OK` in between.
+const MOTD_THEN_MODULES: [&str; 8] = [
+    "Welcome to the mirror\n",
+    "\n",
+    "Contact:\tops@example.com\n",
+    "\n",
+    "alpha          \tPrimary module\n",
+    "beta           \t\n",
+    "gamma          \tNightly builds\n",
+    "@RSYNCD: EXIT\n",
+];
+
+fn assert_module_rows(list: &ModuleList) {
+    let names: Vec<&str> = list.entries().iter().map(ModuleListEntry::name).collect();
+    assert_eq!(
+        names,
+        ["alpha          ", "beta           ", "gamma          "]
+    );
+    assert_eq!(list.entries()[0].comment(), Some("Primary module"));
+    assert_eq!(list.entries()[1].comment(), None);
+    assert_eq!(list.entries()[2].comment(), Some("Nightly builds"));
+}
+
+#[test]
+fn run_module_list_separates_multiline_motd_from_unacknowledged_rows() {
+    let _guard = env_lock().lock().expect("env mutex poisoned");
+
+    let (addr, handle) = spawn_stub_daemon(MOTD_THEN_MODULES.to_vec());
+    let request = ModuleListRequest::from_components(
+        DaemonAddress::new(addr.ip().to_string(), addr.port()).expect("address"),
+        None,
+        ProtocolVersion::NEWEST,
+    );
+
+    let list = run_module_list(request).expect("module list succeeds");
+
+    assert_eq!(
+        list.motd_lines(),
+        ["Welcome to the mirror", "", "Contact:\tops@example.com", ""]
+    );
+    assert_module_rows(&list);
+
+    handle.join().expect("daemon thread completes");
+}
+
+#[test]
+fn run_module_list_keeps_rows_when_no_motd_discards_the_banner() {
+    let _guard = env_lock().lock().expect("env mutex poisoned");
+
+    let (addr, handle) = spawn_stub_daemon(MOTD_THEN_MODULES.to_vec());
+    let request = ModuleListRequest::from_components(
+        DaemonAddress::new(addr.ip().to_string(), addr.port()).expect("address"),
+        None,
+        ProtocolVersion::NEWEST,
+    );
+
+    let options = ModuleListOptions::default().suppress_motd(true);
+    let list = run_module_list_with_options(request, options).expect("module list succeeds");
+
+    assert!(list.motd_lines().is_empty());
+    assert_module_rows(&list);
+
+    handle.join().expect("daemon thread completes");
+}
+
+#[test]
+fn run_module_list_without_motd_separator_starts_rows_at_first_tab() {
+    let _guard = env_lock().lock().expect("env mutex poisoned");
+
+    let responses = vec![
+        "Banner headline\n",
+        "alpha\tPrimary module\n",
+        "beta\tSecondary module\n",
+        "@RSYNCD: EXIT\n",
+    ];
+    let (addr, handle) = spawn_stub_daemon(responses);
+    let request = ModuleListRequest::from_components(
+        DaemonAddress::new(addr.ip().to_string(), addr.port()).expect("address"),
+        None,
+        ProtocolVersion::NEWEST,
+    );
+
+    let list = run_module_list(request).expect("module list succeeds");
+
+    assert_eq!(list.motd_lines(), ["Banner headline"]);
+    assert_eq!(list.entries().len(), 2);
+    assert_eq!(list.entries()[0].name(), "alpha");
+    assert_eq!(list.entries()[1].name(), "beta");
+
+    handle.join().expect("daemon thread completes");
+}
+
 #[test]
 fn establish_proxy_tunnel_formats_ipv6_authority_without_brackets() {
     let listener = TcpListener::bind("127.0.0.1:0").expect("bind proxy listener");
@@ -858,6 +961,38 @@ fn module_list_request_parses_ipv6_zone_identifier() {
     assert_eq!(request.address().port(), 873);
 }
 
+#[test]
+fn module_list_request_keeps_numeric_ipv6_zone() {
+    for operand in [
+        "rsync://[fe80::1%10]/",
+        "rsync://[fe80::1%2510]/",
+        "[fe80::1%10]::",
+    ] {
+        let operands = vec![OsString::from(operand)];
+        let request = ModuleListRequest::from_operands(&operands)
+            .expect("parse succeeds")
+            .expect("request present");
+        assert_eq!(request.address().host(), "fe80::1%10", "{operand}");
+    }
+}
+
+#[test]
+fn resolve_daemon_addresses_keeps_ipv6_scope_id() {
+    let address = DaemonAddress::new(String::from("fe80::1%7"), 873).expect("address");
+    let addresses = resolve_daemon_addresses(&address, AddressMode::Ipv6).expect("zone resolves");
+
+    match addresses.as_slice() {
+        [std::net::SocketAddr::V6(scoped)] => {
+            assert_eq!(
+                scoped.ip(),
+                &"fe80::1".parse::<std::net::Ipv6Addr>().unwrap()
+            );
+            assert_eq!(scoped.scope_id(), 7);
+        }
+        other => panic!("unexpected resolution {other:?}"),
+    }
+}
+
 #[test]
 fn module_list_request_rejects_truncated_percent_encoding() {
     let operands = vec![OsString::from("rsync://example%2/")];
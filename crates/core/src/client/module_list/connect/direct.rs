@@ -478,4 +478,37 @@ mod tests {
         drop(stream);
         handle.join().expect("accept thread completes");
     }
+
+    // `--sockopts=SO_SNDBUF=N,SO_RCVBUF=N` is how a high-bandwidth link is
+    // tuned; both sizes must reach the connected socket, not just the first.
+    #[test]
+    fn connect_with_optional_bind_applies_send_and_receive_buffer_sizes() {
+        use std::net::TcpListener;
+
+        let listener = TcpListener::bind("127.0.0.1:0").expect("bind loopback listener");
+        let target = listener.local_addr().expect("listener addr");
+        let handle = std::thread::spawn(move || {
+            let _ = listener.accept();
+        });
+
+        let stream = connect_with_optional_bind(
+            target,
+            None,
+            None,
+            TcpFastOpenMode::Off,
+            Some(std::ffi::OsStr::new("SO_SNDBUF=131072,SO_RCVBUF=98304")),
+        )
+        .expect("connect with sockopts");
+
+        let socket = socket2::SockRef::from(&stream);
+        let send = socket.send_buffer_size().expect("query send buffer size");
+        let recv = socket
+            .recv_buffer_size()
+            .expect("query receive buffer size");
+        assert!(send >= 131_072, "SO_SNDBUF not applied, got {send}");
+        assert!(recv >= 98_304, "SO_RCVBUF not applied, got {recv}");
+
+        drop(stream);
+        handle.join().expect("accept thread completes");
+    }
 }
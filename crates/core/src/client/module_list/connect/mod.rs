@@ -2,13 +2,22 @@ mod direct;
 mod program;
 mod proxy;
 mod rsh;
+mod tls;
+#[cfg(unix)]
+mod unix;
 
 use std::ffi::OsStr;
 use std::io::{self, IoSlice, Read, Write};
 use std::net::{SocketAddr, TcpStream};
+#[cfg(unix)]
+use std::os::unix::net::UnixStream;
+use std::path::Path;
 use std::time::Duration;
 
-use super::super::{AddressMode, ClientError, TcpFastOpenMode, TransferTimeout};
+use super::super::{
+    AddressMode, ClientError, FEATURE_UNAVAILABLE_EXIT_CODE, TcpFastOpenMode, TransferTimeout,
+    daemon_error,
+};
 use super::DaemonAddress;
 pub(crate) use direct::{connect_direct, resolve_daemon_addresses};
 pub(crate) use program::ConnectProgramConfig;
@@ -23,6 +32,9 @@ pub(crate) use rsh::{RshDaemonSpawn, spawn_rsh_daemon_stream};
 pub(crate) enum DaemonStreamReader {
     /// Cloned TCP socket used for reading.
     Tcp(TcpStream),
+    /// Cloned Unix domain socket used for reading.
+    #[cfg(unix)]
+    Unix(UnixStream),
     /// Connect program read half: Unix socketpair clone or child stdout
     /// pipe (Unix), or child stdout pipe (non-Unix).
     #[cfg(unix)]
@@ -35,6 +47,8 @@ impl Read for DaemonStreamReader {
     fn read(&mut self, buf: &mut [u8]) -> io::Result<usize> {
         match self {
             Self::Tcp(stream) => stream.read(buf),
+            #[cfg(unix)]
+            Self::Unix(stream) => stream.read(buf),
             Self::Program(reader) => reader.read(buf),
         }
     }
@@ -43,11 +57,12 @@ impl Read for DaemonStreamReader {
 impl DaemonStreamReader {
     /// Clones the underlying TCP read half so an adopted daemon
     /// `MSG_IO_TIMEOUT` can be re-applied to the live socket. Returns `None`
-    /// for connect-program (pipe) transports, which carry no socket timeout.
+    /// for connect-program (pipe) transports, which carry no socket timeout,
+    /// and for Unix sockets, which keep the handshake timeout.
     pub(crate) fn try_clone_tcp(&self) -> Option<TcpStream> {
         match self {
             Self::Tcp(stream) => stream.try_clone().ok(),
-            Self::Program(_) => None,
+            _ => None,
         }
     }
 }
@@ -144,6 +159,9 @@ impl Drop for CorkedTcpWriter {
 pub(crate) enum DaemonStreamWriter {
     /// Original TCP socket used for writing, with burst corking applied.
     Tcp(CorkedTcpWriter),
+    /// Original Unix domain socket used for writing.
+    #[cfg(unix)]
+    Unix(UnixStream),
     /// Connect program write half: Unix socketpair clone or child stdin
     /// pipe (Unix), or child stdin pipe (non-Unix).
     #[cfg(unix)]
@@ -156,6 +174,8 @@ impl Write for DaemonStreamWriter {
     fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
         match self {
             Self::Tcp(writer) => writer.write(buf),
+            #[cfg(unix)]
+            Self::Unix(stream) => stream.write(buf),
             Self::Program(writer) => writer.write(buf),
         }
     }
@@ -163,6 +183,8 @@ impl Write for DaemonStreamWriter {
     fn write_vectored(&mut self, bufs: &[IoSlice<'_>]) -> io::Result<usize> {
         match self {
             Self::Tcp(writer) => writer.write_vectored(bufs),
+            #[cfg(unix)]
+            Self::Unix(stream) => stream.write_vectored(bufs),
             Self::Program(writer) => writer.write_vectored(bufs),
         }
     }
@@ -170,6 +192,8 @@ impl Write for DaemonStreamWriter {
     fn flush(&mut self) -> io::Result<()> {
         match self {
             Self::Tcp(writer) => writer.flush(),
+            #[cfg(unix)]
+            Self::Unix(stream) => stream.flush(),
             Self::Program(writer) => writer.flush(),
         }
     }
@@ -178,11 +202,12 @@ impl Write for DaemonStreamWriter {
 impl DaemonStreamWriter {
     /// Clones the underlying TCP write half so an adopted daemon
     /// `MSG_IO_TIMEOUT` can be re-applied to the live socket. Returns `None`
-    /// for connect-program (pipe) transports, which carry no socket timeout.
+    /// for connect-program (pipe) transports, which carry no socket timeout,
+    /// and for Unix sockets, which keep the handshake timeout.
     pub(crate) fn try_clone_tcp(&self) -> Option<TcpStream> {
         match self {
             Self::Tcp(writer) => writer.stream.try_clone().ok(),
-            Self::Program(_) => None,
+            _ => None,
         }
     }
 }
@@ -193,13 +218,16 @@ impl DaemonStreamWriter {
 /// adopts a daemon-advertised `MSG_IO_TIMEOUT`, the hook re-applies the value as
 /// the socket's read and write timeouts (both fds reference one kernel socket,
 /// so either updates the pair). Returns `None` for connect-program transports,
-/// which have no socket timeout to adjust.
+/// which have no socket timeout to adjust. `blocking_cap` is the
+/// `--blocking-timeout` deadline; an adopted value never lifts the socket
+/// timeouts above it, and an adopted `0` leaves the cap in place.
 ///
 /// upstream: io.c:1148-1157 `set_io_timeout()` - the client-side effect of
 /// adopting a daemon `MSG_IO_TIMEOUT` (io.c:1551-1561).
 pub(crate) fn build_io_timeout_reapply(
     reader: &DaemonStreamReader,
     writer: &DaemonStreamWriter,
+    blocking_cap: Option<Duration>,
 ) -> Option<crate::server::IoTimeoutReapply> {
     let read_half = reader.try_clone_tcp();
     let write_half = writer.try_clone_tcp();
@@ -208,7 +236,11 @@ pub(crate) fn build_io_timeout_reapply(
     }
     Some(crate::server::IoTimeoutReapply(std::sync::Arc::new(
         move |secs: u32| -> io::Result<()> {
-            let timeout = (secs != 0).then(|| Duration::from_secs(u64::from(secs)));
+            let adopted = (secs != 0).then(|| Duration::from_secs(u64::from(secs)));
+            let timeout = match (adopted, blocking_cap) {
+                (Some(adopted), Some(cap)) => Some(adopted.min(cap)),
+                (adopted, cap) => adopted.or(cap),
+            };
             if let Some(stream) = &read_half {
                 stream.set_read_timeout(timeout)?;
                 stream.set_write_timeout(timeout)?;
@@ -229,6 +261,16 @@ pub(crate) fn build_io_timeout_reapply(
 /// connecting socket before `connect(2)` for both the direct and proxied
 /// paths; it has no effect on a connect program, matching upstream (a
 /// connect program bypasses `open_socket_out()` entirely).
+///
+/// With `tls` (`--tls`) the TCP connection, direct or proxied, is wrapped in
+/// TLS before the `@RSYNCD:` exchange. A connect program owns its transport,
+/// so combining it with `--tls` is rejected rather than silently sending
+/// plaintext.
+///
+/// A `unix:PATH` address dials the daemon's Unix socket directly. Proxies,
+/// connect programs and the TCP-level options only reach TCP endpoints and
+/// do not apply to it; `--tls` is rejected for the same reason as above.
+#[allow(clippy::too_many_arguments)]
 pub(crate) fn open_daemon_stream(
     addr: &DaemonAddress,
     connect_timeout: Option<Duration>,
@@ -238,8 +280,25 @@ pub(crate) fn open_daemon_stream(
     bind_address: Option<SocketAddr>,
     tfo: TcpFastOpenMode,
     sockopts: Option<&OsStr>,
+    tls: bool,
 ) -> Result<DaemonStream, ClientError> {
+    if let Some(path) = addr.unix_socket_path() {
+        if tls {
+            return Err(daemon_error(
+                "--tls cannot be combined with a unix: daemon address",
+                FEATURE_UNAVAILABLE_EXIT_CODE,
+            ));
+        }
+        return open_unix_daemon_stream(addr, path, io_timeout);
+    }
+
     if let Some(program) = program::load_daemon_connect_program(connect_program)? {
+        if tls {
+            return Err(daemon_error(
+                "--tls cannot be combined with RSYNC_CONNECT_PROG",
+                FEATURE_UNAVAILABLE_EXIT_CODE,
+            ));
+        }
         return program::connect_via_program(addr, &program);
     }
 
@@ -264,9 +323,37 @@ pub(crate) fn open_daemon_stream(
         )?,
     };
 
+    if tls {
+        let stream = tls::wrap_tls(addr, stream, connect_timeout.or(io_timeout))?;
+        return Ok(DaemonStream::tcp(stream));
+    }
+
     Ok(DaemonStream::tcp(stream))
 }
 
+/// Connects to the Unix domain socket of a `unix:PATH` daemon address.
+#[cfg(unix)]
+fn open_unix_daemon_stream(
+    addr: &DaemonAddress,
+    path: &Path,
+    io_timeout: Option<Duration>,
+) -> Result<DaemonStream, ClientError> {
+    unix::connect_unix(addr, path, io_timeout).map(DaemonStream::Unix)
+}
+
+/// Unix domain sockets are unavailable on this platform.
+#[cfg(not(unix))]
+fn open_unix_daemon_stream(
+    _addr: &DaemonAddress,
+    _path: &Path,
+    _io_timeout: Option<Duration>,
+) -> Result<DaemonStream, ClientError> {
+    Err(daemon_error(
+        "unix: daemon addresses are not supported on this platform",
+        FEATURE_UNAVAILABLE_EXIT_CODE,
+    ))
+}
+
 /// Resolves the connect-phase timeout for a daemon TCP connection.
 ///
 /// Upstream arms a `SIGALRM` around `connect(2)` only when `--contimeout` is set
@@ -290,11 +377,14 @@ pub(crate) const fn resolve_connect_timeout(connect_timeout: TransferTimeout) ->
 
 /// Bidirectional stream to an rsync daemon.
 ///
-/// Abstracts over the underlying transport: plain TCP or a connect program
-/// (`RSYNC_CONNECT_PROG`).
+/// Abstracts over the underlying transport: plain TCP, a Unix domain socket
+/// (`unix:PATH` address) or a connect program (`RSYNC_CONNECT_PROG`).
 pub(crate) enum DaemonStream {
     /// Plain TCP connection.
     Tcp(TcpStream),
+    /// Unix domain socket connection.
+    #[cfg(unix)]
+    Unix(UnixStream),
     /// Connection via an external connect program.
     Program(ConnectProgramStream),
 }
@@ -327,14 +417,14 @@ impl DaemonStream {
     pub(crate) fn as_tcp_stream(&self) -> Option<&TcpStream> {
         match self {
             Self::Tcp(stream) => Some(stream),
-            Self::Program(_) => None,
+            _ => None,
         }
     }
 
     /// Splits the daemon stream into independent read and write halves.
     ///
-    /// For TCP, the socket is cloned (separate fd) so reader and writer
-    /// can be used concurrently. For connect programs on Unix, the
+    /// For TCP and Unix sockets, the socket is cloned (separate fd) so
+    /// reader and writer can be used concurrently. For connect programs on Unix, the
     /// socketpair fd is cloned; on non-Unix the child's stdout and stdin
     /// pipes are returned directly.
     ///
@@ -353,6 +443,15 @@ impl DaemonStream {
                     DaemonStreamGuard::None,
                 ))
             }
+            #[cfg(unix)]
+            Self::Unix(stream) => {
+                let reader = stream.try_clone()?;
+                Ok((
+                    DaemonStreamReader::Unix(reader),
+                    DaemonStreamWriter::Unix(stream),
+                    DaemonStreamGuard::None,
+                ))
+            }
             Self::Program(prog) => {
                 let parts = prog.into_parts()?;
                 Ok((
@@ -366,19 +465,27 @@ impl DaemonStream {
 
     /// Configures TCP-specific socket options for the transfer phase.
     ///
-    /// Sets TCP_NODELAY and applies read/write timeouts. No-op for
-    /// non-TCP transports (connect programs).
+    /// Sets TCP_NODELAY and applies read/write timeouts. A Unix socket only
+    /// takes the timeouts; connect programs are left untouched.
     pub(crate) fn configure_transfer_options(
         &self,
         nodelay: bool,
         timeout: Option<Duration>,
     ) -> io::Result<()> {
-        if let Self::Tcp(stream) = self {
-            if nodelay {
-                stream.set_nodelay(true)?;
+        match self {
+            Self::Tcp(stream) => {
+                if nodelay {
+                    stream.set_nodelay(true)?;
+                }
+                stream.set_read_timeout(timeout)?;
+                stream.set_write_timeout(timeout)?;
             }
-            stream.set_read_timeout(timeout)?;
-            stream.set_write_timeout(timeout)?;
+            #[cfg(unix)]
+            Self::Unix(stream) => {
+                stream.set_read_timeout(timeout)?;
+                stream.set_write_timeout(timeout)?;
+            }
+            Self::Program(_) => {}
         }
         Ok(())
     }
@@ -408,6 +515,8 @@ impl Read for DaemonStream {
     fn read(&mut self, buf: &mut [u8]) -> io::Result<usize> {
         match self {
             Self::Tcp(stream) => stream.read(buf),
+            #[cfg(unix)]
+            Self::Unix(stream) => stream.read(buf),
             Self::Program(stream) => stream.read(buf),
         }
     }
@@ -417,6 +526,8 @@ impl Write for DaemonStream {
     fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
         match self {
             Self::Tcp(stream) => stream.write(buf),
+            #[cfg(unix)]
+            Self::Unix(stream) => stream.write(buf),
             Self::Program(stream) => stream.write(buf),
         }
     }
@@ -424,6 +535,8 @@ impl Write for DaemonStream {
     fn flush(&mut self) -> io::Result<()> {
         match self {
             Self::Tcp(stream) => stream.flush(),
+            #[cfg(unix)]
+            Self::Unix(stream) => stream.flush(),
             Self::Program(stream) => stream.flush(),
         }
     }
@@ -527,6 +640,60 @@ mod cork_tests {
     }
 }
 
+#[cfg(test)]
+mod blocking_timeout_tests {
+    use super::*;
+    use std::net::{Ipv4Addr, TcpListener};
+    use std::sync::mpsc;
+    use std::thread;
+    use std::time::Instant;
+
+    /// A peer that accepts but never reads lets the send buffer fill, after
+    /// which `write(2)` blocks. With `--blocking-timeout` applied through
+    /// `configure_transfer_options` that write must fail once the deadline
+    /// passes instead of blocking the transfer forever.
+    #[test]
+    fn write_to_stalled_peer_aborts_after_deadline() {
+        let listener = TcpListener::bind((Ipv4Addr::LOCALHOST, 0)).expect("bind loopback");
+        let addr = listener.local_addr().expect("addr");
+        let client = TcpStream::connect(addr).expect("connect");
+        let (_stalled_peer, _) = listener.accept().expect("accept");
+
+        let deadline = Duration::from_secs(1);
+        let mut stream = DaemonStream::tcp(client);
+        stream
+            .configure_transfer_options(true, Some(deadline))
+            .expect("configure transfer options");
+
+        let (done_tx, done_rx) = mpsc::channel();
+        thread::spawn(move || {
+            let chunk = vec![0u8; 64 * 1024];
+            let started = Instant::now();
+            let error = loop {
+                if let Err(error) = stream.write(&chunk) {
+                    break error;
+                }
+            };
+            let _ = done_tx.send((error, started.elapsed()));
+        });
+
+        let (error, elapsed) = done_rx
+            .recv_timeout(Duration::from_secs(30))
+            .expect("write to a stalled peer must not block past the deadline");
+        assert!(
+            matches!(
+                error.kind(),
+                io::ErrorKind::WouldBlock | io::ErrorKind::TimedOut
+            ),
+            "unexpected error kind: {error:?}"
+        );
+        assert!(
+            elapsed >= deadline.mul_f32(0.9),
+            "write gave up after {elapsed:?}, before the {deadline:?} deadline"
+        );
+    }
+}
+
 #[cfg(test)]
 mod connect_timeout_tests {
     use super::*;
@@ -133,7 +133,7 @@ pub(crate) fn connect_via_program(
 /// `TcpStream::connect` completes the handshake synchronously, unlike the
 /// non-blocking dance upstream performs in C).
 #[cfg(unix)]
-fn socketpair_tcp() -> io::Result<(std::net::TcpStream, std::net::TcpStream)> {
+pub(super) fn socketpair_tcp() -> io::Result<(std::net::TcpStream, std::net::TcpStream)> {
     use std::net::{Ipv4Addr, TcpListener, TcpStream};
 
     let listener = TcpListener::bind((Ipv4Addr::LOCALHOST, 0))?;
@@ -0,0 +1,76 @@
+//! `--tls` transport: TLS over the daemon TCP connection.
+//!
+//! oc-rsync extension with no upstream equivalent; the daemon end must be an
+//! oc-rsync daemon configured with `tls cert file` / `tls key file` (see
+//! `rsync_io::tls`). The session is terminated on a relay thread bridged to a
+//! loopback socket pair, so the rest of the client keeps driving an ordinary
+//! `TcpStream` - the in-process counterpart of upstream's `rsync-ssl`, which
+//! runs `openssl s_client` as an `RSYNC_CONNECT_PROG`.
+
+use std::net::TcpStream;
+use std::time::Duration;
+
+use super::super::DaemonAddress;
+use crate::client::{ClientError, FEATURE_UNAVAILABLE_EXIT_CODE, daemon_error};
+
+/// Negotiates TLS on `stream` and returns the plaintext end of the relay.
+///
+/// The daemon certificate is verified against the system trust store plus
+/// the bundle named by `RSYNC_SSL_CA_CERT`, and must match the host from the
+/// daemon operand. `timeout` bounds the handshake.
+#[cfg(all(unix, feature = "tls"))]
+pub(super) fn wrap_tls(
+    addr: &DaemonAddress,
+    stream: TcpStream,
+    timeout: Option<Duration>,
+) -> Result<TcpStream, ClientError> {
+    use crate::client::socket_error;
+    use rsync_io::tls::TlsClient;
+
+    let client = TlsClient::from_env().map_err(|error| {
+        daemon_error(
+            format!("failed to set up TLS: {error}"),
+            FEATURE_UNAVAILABLE_EXIT_CODE,
+        )
+    })?;
+    let session = client
+        .connect(addr.host(), stream, timeout)
+        .map_err(|error| {
+            socket_error(
+                "establish TLS session with",
+                addr.socket_addr_display(),
+                error,
+            )
+        })?;
+
+    // Loopback TCP rather than a Unix socket pair so the caller's
+    // `DaemonStream::Tcp` handling (timeouts, corking, split) applies as-is.
+    let (local, relay_end) = super::program::socketpair_tcp().map_err(|error| {
+        socket_error(
+            "create TLS relay socket for",
+            addr.socket_addr_display(),
+            error,
+        )
+    })?;
+    std::thread::Builder::new()
+        .name("tls-relay".to_owned())
+        .spawn(move || {
+            // Relay errors surface to the protocol layer as EOF on `local`.
+            let _ = session.relay(relay_end);
+        })
+        .map_err(|error| socket_error("start TLS relay for", addr.socket_addr_display(), error))?;
+    Ok(local)
+}
+
+/// Fallback for builds without the `tls` feature (or off Unix).
+#[cfg(not(all(unix, feature = "tls")))]
+pub(super) fn wrap_tls(
+    _addr: &DaemonAddress,
+    _stream: TcpStream,
+    _timeout: Option<Duration>,
+) -> Result<TcpStream, ClientError> {
+    Err(daemon_error(
+        "--tls is not supported by this build (requires the `tls` feature on a Unix platform)",
+        FEATURE_UNAVAILABLE_EXIT_CODE,
+    ))
+}
@@ -0,0 +1,80 @@
+//! Daemon connections over a Unix domain socket.
+//!
+//! oc-rsync extension with no upstream equivalent: a `unix:PATH` daemon host
+//! (`unix:/run/rsyncd.sock::module`, or percent-encoded as
+//! `rsync://unix:%2Frun%2Frsyncd.sock/module/`) dials the socket file a daemon
+//! started with `--address unix:PATH` listens on. The `@RSYNCD:` exchange and
+//! the transfer that follows are byte-identical to TCP.
+
+use std::os::unix::net::UnixStream;
+use std::path::Path;
+use std::time::Duration;
+
+use super::super::DaemonAddress;
+use crate::client::{ClientError, socket_error};
+
+/// Connects to the daemon socket at `path` and applies `io_timeout` to it.
+///
+/// A local `connect(2)` either succeeds or fails at once, so no connect
+/// timeout is armed.
+pub(super) fn connect_unix(
+    addr: &DaemonAddress,
+    path: &Path,
+    io_timeout: Option<Duration>,
+) -> Result<UnixStream, ClientError> {
+    let stream = UnixStream::connect(path)
+        .map_err(|error| socket_error("connect to", addr.socket_addr_display(), error))?;
+    if let Some(duration) = io_timeout {
+        stream.set_read_timeout(Some(duration)).map_err(|error| {
+            socket_error("set read timeout on", addr.socket_addr_display(), error)
+        })?;
+        stream.set_write_timeout(Some(duration)).map_err(|error| {
+            socket_error("set write timeout on", addr.socket_addr_display(), error)
+        })?;
+    }
+    Ok(stream)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::io::{Read, Write};
+    use std::os::unix::net::UnixListener;
+    use std::thread;
+
+    fn unix_address(path: &Path) -> DaemonAddress {
+        DaemonAddress::new(format!("unix:{}", path.display()), 873).expect("daemon addr")
+    }
+
+    #[test]
+    fn connect_unix_reaches_listener_and_applies_io_timeout() {
+        let dir = tempfile::tempdir().expect("tempdir");
+        let path = dir.path().join("rsyncd.sock");
+        let listener = UnixListener::bind(&path).expect("bind socket");
+        let server = thread::spawn(move || {
+            let (mut stream, _) = listener.accept().expect("accept");
+            stream.write_all(b"@RSYNCD: 32.0\n").expect("greet");
+        });
+
+        let timeout = Some(Duration::from_secs(3));
+        let mut stream = connect_unix(&unix_address(&path), &path, timeout).expect("connect");
+        assert_eq!(stream.read_timeout().expect("read timeout"), timeout);
+        assert_eq!(stream.write_timeout().expect("write timeout"), timeout);
+
+        let mut greeting = String::new();
+        stream.read_to_string(&mut greeting).expect("read greeting");
+        assert_eq!(greeting, "@RSYNCD: 32.0\n");
+        server.join().expect("server thread");
+    }
+
+    #[test]
+    fn connect_unix_reports_missing_socket() {
+        let dir = tempfile::tempdir().expect("tempdir");
+        let path = dir.path().join("absent.sock");
+        let error = connect_unix(&unix_address(&path), &path, None).expect_err("no listener");
+        assert!(
+            error.to_string().contains(&path.display().to_string()),
+            "error must name the socket: {error}"
+        );
+    }
+}
@@ -223,6 +223,7 @@ pub fn run_module_list_with_password_and_options(
             options.bind_address(),
             options.tcp_fastopen(),
             options.sockopts(),
+            options.tls(),
         )?
     };
 
@@ -286,15 +287,19 @@ pub fn run_module_list_with_password_and_options(
                 }
                 Ok(LegacyDaemonMessage::Exit) => {
                     // upstream: clientserver.c - the daemon sends @RSYNCD: EXIT
-                    // without a preceding @RSYNCD: OK for module listings.
-                    // Lines collected in pre_ack_messages are the module entries.
+                    // without a preceding @RSYNCD: OK for module listings, so
+                    // the MOTD and the module rows arrive as one run of plain
+                    // lines. Only the trailing block is the module table.
                     if !acknowledged {
-                        for msg in pre_ack_messages.drain(..) {
-                            entries.push(ModuleListEntry::from_line(&msg));
+                        let start = module_block_start(&pre_ack_messages);
+                        let listed = pre_ack_messages.split_off(start);
+                        for line in listed.iter().rev() {
+                            if motd.last() == Some(line) {
+                                motd.pop();
+                            }
                         }
+                        entries.extend(listed.iter().map(|line| ModuleListEntry::from_line(line)));
                         acknowledged = true;
-                        // Clear motd of module-entry lines (those containing tabs).
-                        motd.retain(|line| !line.contains('\t'));
                     }
                     break;
                 }
@@ -410,6 +415,26 @@ pub fn run_module_list_with_password_and_options(
     Ok(ModuleList::new(motd, warnings, capabilities, entries))
 }
 
+/// Returns the index of the first module row in the plain lines a daemon sent
+/// before `@RSYNCD: EXIT`.
+///
+/// upstream: clientserver.c:exchange_protocols() writes the MOTD file followed
+/// by one empty line, and send_listing() then writes one `%-15s\t%s` row per
+/// module. A row is never empty, so everything after the last empty line is
+/// the module table even when the MOTD itself contains blank lines or tabs.
+/// Without a separator (no MOTD, or a daemon that omits it) the table starts
+/// at the first tab-separated row; lines before it are banner text.
+fn module_block_start(lines: &[String]) -> usize {
+    if let Some(separator) = lines.iter().rposition(String::is_empty) {
+        return separator + 1;
+    }
+
+    lines
+        .iter()
+        .position(|line| line.contains('\t'))
+        .unwrap_or(0)
+}
+
 /// Enforces upstream's greeting-completeness gate on the daemon's banner.
 ///
 /// upstream: clientserver.c:188-210 `exchange_protocols()` (am_client == 1) -
@@ -1,5 +1,6 @@
 use super::super::{ClientError, FEATURE_UNAVAILABLE_EXIT_CODE, daemon_error};
 use super::DaemonAddress;
+use super::types::UNIX_SOCKET_PREFIX;
 
 pub(crate) fn strip_prefix_ignore_ascii_case<'a>(text: &'a str, prefix: &str) -> Option<&'a str> {
     if text.len() < prefix.len() {
@@ -46,6 +47,20 @@ pub(crate) fn parse_bracketed_host(
 }
 
 pub(crate) fn decode_host_component(input: &str) -> Result<String, ClientError> {
+    // The `%` of an IPv6 literal introduces a zone, which getaddrinfo() turns
+    // into the scope id, so it is kept verbatim: a numeric zone such as
+    // `fe80::1%10` must not be read as the escape `%10`. RFC 6874 spells the
+    // separator `%25` inside a URL.
+    if let Some((address, zone)) = input.split_once('%')
+        && address.contains(':')
+    {
+        let zone = zone
+            .strip_prefix("25")
+            .filter(|rest| !rest.is_empty())
+            .unwrap_or(zone);
+        return Ok(format!("{address}%{zone}"));
+    }
+
     decode_percent_component(
         input,
         invalid_percent_encoding_error,
@@ -243,6 +258,24 @@ pub(crate) fn parse_host_port(
         return Ok(ParsedDaemonTarget { address, username });
     }
 
+    // oc-rsync extension: `unix:PATH` names a daemon's Unix socket. The path
+    // may be percent-encoded so that it fits the host part of an rsync:// URL.
+    if let Some(path) = input.strip_prefix(UNIX_SOCKET_PREFIX) {
+        let path = decode_percent_component(
+            path,
+            invalid_percent_encoding_error,
+            invalid_host_utf8_error,
+        )?;
+        if path.is_empty() {
+            return Err(daemon_error(
+                "unix: daemon address requires a socket path",
+                FEATURE_UNAVAILABLE_EXIT_CODE,
+            ));
+        }
+        let address = DaemonAddress::new(format!("{UNIX_SOCKET_PREFIX}{path}"), default_port)?;
+        return Ok(ParsedDaemonTarget { address, username });
+    }
+
     if let Some(host) = input.strip_prefix('[') {
         let (address, port) = parse_bracketed_host(host, default_port)?;
         let address = DaemonAddress::new(address, port)?;
@@ -484,4 +517,19 @@ mod tests {
         let result = parse_host_port("", 873).expect("parse");
         assert_eq!(result.address.port(), 873);
     }
+
+    #[test]
+    fn parse_host_port_parses_unix_socket_path() {
+        let result = parse_host_port("user@unix:/run/rsyncd.sock", 873).expect("parse");
+        assert_eq!(result.address.host(), "unix:/run/rsyncd.sock");
+        assert_eq!(result.username, Some("user".to_owned()));
+
+        let encoded = parse_host_port("unix:%2Frun%2Frsyncd.sock", 873).expect("parse");
+        assert_eq!(encoded.address, result.address);
+    }
+
+    #[test]
+    fn parse_host_port_rejects_unix_prefix_without_path() {
+        assert!(parse_host_port("unix:", 873).is_err());
+    }
 }
@@ -134,6 +134,7 @@ pub struct ModuleListOptions {
     bind_address: Option<SocketAddr>,
     sockopts: Option<OsString>,
     tcp_fastopen: TcpFastOpenMode,
+    tls: bool,
     blocking_io: Option<bool>,
     remote_shell: Option<Vec<OsString>>,
     rsync_path: Option<OsString>,
@@ -150,6 +151,7 @@ impl ModuleListOptions {
             bind_address: None,
             sockopts: None,
             tcp_fastopen: TcpFastOpenMode::Auto,
+            tls: false,
             blocking_io: None,
             remote_shell: None,
             rsync_path: None,
@@ -254,6 +256,20 @@ impl ModuleListOptions {
         self.tcp_fastopen
     }
 
+    /// Wraps the daemon connection in TLS (oc-rsync extension).
+    #[must_use]
+    #[doc(alias = "--tls")]
+    pub const fn with_tls(mut self, enabled: bool) -> Self {
+        self.tls = enabled;
+        self
+    }
+
+    /// Returns whether the daemon connection is wrapped in TLS.
+    #[must_use]
+    pub const fn tls(&self) -> bool {
+        self.tls
+    }
+
     /// Configures the desired blocking I/O mode for daemon TCP sockets.
     #[must_use]
     #[doc(alias = "--blocking-io")]
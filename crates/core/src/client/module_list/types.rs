@@ -4,9 +4,14 @@
 //! of an rsync daemon connection.
 
 use std::fmt;
+use std::path::Path;
 
 use super::super::{ClientError, FEATURE_UNAVAILABLE_EXIT_CODE, daemon_error};
 
+/// Host prefix that names a daemon's Unix domain socket instead of a TCP
+/// endpoint, the client side of the daemon's `--address unix:PATH`.
+pub(crate) const UNIX_SOCKET_PREFIX: &str = "unix:";
+
 /// Target daemon address used for module listing requests.
 #[derive(Clone, Debug, Eq, PartialEq)]
 pub struct DaemonAddress {
@@ -42,6 +47,13 @@ impl DaemonAddress {
         self.port
     }
 
+    /// Returns the socket file of a `unix:PATH` address, or `None` for a
+    /// TCP host.
+    #[must_use]
+    pub fn unix_socket_path(&self) -> Option<&Path> {
+        self.host.strip_prefix(UNIX_SOCKET_PREFIX).map(Path::new)
+    }
+
     pub(crate) fn socket_addr_display(&self) -> SocketAddrDisplay<'_> {
         SocketAddrDisplay {
             host: &self.host,
@@ -57,7 +69,9 @@ pub(crate) struct SocketAddrDisplay<'a> {
 
 impl fmt::Display for SocketAddrDisplay<'_> {
     fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
-        if self.host.contains(':') && !self.host.starts_with('[') {
+        if self.host.starts_with(UNIX_SOCKET_PREFIX) {
+            f.write_str(self.host)
+        } else if self.host.contains(':') && !self.host.starts_with('[') {
             write!(f, "[{}]:{}", self.host, self.port)
         } else {
             write!(f, "{}:{}", self.host, self.port)
@@ -147,6 +161,19 @@ mod tests {
         assert_eq!(format!("{display}"), "example.com:8873");
     }
 
+    #[test]
+    fn unix_socket_address_exposes_path_and_displays_without_port() {
+        let addr = DaemonAddress::new("unix:/run/rsyncd.sock".to_owned(), 873).expect("address");
+        assert_eq!(addr.unix_socket_path(), Some(Path::new("/run/rsyncd.sock")));
+        assert_eq!(
+            format!("{}", addr.socket_addr_display()),
+            "unix:/run/rsyncd.sock"
+        );
+
+        let tcp = DaemonAddress::new("localhost".to_owned(), 873).expect("address");
+        assert_eq!(tcp.unix_socket_path(), None);
+    }
+
     #[test]
     fn daemon_address_socket_addr_display_formats_correctly() {
         let addr = DaemonAddress::new("192.168.1.1".to_owned(), 873).expect("address");
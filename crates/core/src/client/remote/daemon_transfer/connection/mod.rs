@@ -8,6 +8,7 @@ use std::io::{BufRead, BufReader, Write};
 use std::path::Path;
 
 use protocol::ProtocolVersion;
+use protocol::daemon_resume::{RESUME_ACCEPTED_LINE, RESUME_GREETING_TOKEN};
 use protocol::missing_greeting_token;
 use protocol::nstr::{trace_daemon_auth_negotiated, trace_daemon_greeting_auth_list};
 
@@ -19,6 +20,7 @@ use super::super::super::CLIENT_SERVER_PROTOCOL_EXIT_CODE;
 use super::super::super::error::{ClientError, daemon_error, socket_error};
 use super::super::super::module_list::{DaemonAddress, load_daemon_password};
 use crate::client::error::invalid_argument_error;
+use crate::failure::AuthError;
 
 /// Parsed daemon transfer request containing connection and path details.
 #[derive(Clone, Debug)]
@@ -33,7 +35,11 @@ impl DaemonTransferRequest {
     /// Parses an rsync:// URL into a transfer request.
     ///
     /// Format: `rsync://[user@]host[:port]/module/path`
-    pub(crate) fn parse_rsync_url(url: &str) -> Result<Self, ClientError> {
+    ///
+    /// `default_port` is used when the URL does not embed a port. The module
+    /// path is kept verbatim, trailing slash included, so the daemon sees the
+    /// same copy-contents semantics as a local source.
+    pub(crate) fn parse_rsync_url(url: &str, default_port: u16) -> Result<Self, ClientError> {
         use super::super::super::module_list::parse_host_port;
 
         let rest = url
@@ -45,7 +51,7 @@ impl DaemonTransferRequest {
         let host_port = parts.next().unwrap_or("");
         let path_part = parts.next().unwrap_or("");
 
-        let target = parse_host_port(host_port, 873)?;
+        let target = parse_host_port(host_port, default_port)?;
 
         let mut path_parts = path_part.splitn(2, '/');
         let module = path_parts.next().unwrap_or("").to_owned();
@@ -71,14 +77,14 @@ impl DaemonTransferRequest {
     /// Format: `[user@]host::module[/path]`
     ///
     /// upstream: `main.c` - `host::module` is equivalent to `rsync://host/module`.
-    pub(crate) fn parse_double_colon(operand: &str) -> Result<Self, ClientError> {
+    pub(crate) fn parse_double_colon(operand: &str, default_port: u16) -> Result<Self, ClientError> {
         use super::super::super::module_list::parse_host_port;
 
         let (host_part, module_path) = operand.split_once("::").ok_or_else(|| {
             invalid_argument_error(&format!("not a daemon operand: {operand}"), 1)
         })?;
 
-        let target = parse_host_port(host_part, 873)?;
+        let target = parse_host_port(host_part, default_port)?;
 
         let mut path_parts = module_path.splitn(2, '/');
         let module = path_parts.next().unwrap_or("").to_owned();
@@ -178,10 +184,23 @@ fn extract_digest_list_from_greeting(greeting: &str) -> Option<&str> {
 #[cold]
 fn handle_daemon_at_error(line: &str) -> ClientError {
     eprintln!("{line}");
-    daemon_error(
-        line.strip_prefix("@ERROR: ").unwrap_or(line),
-        CLIENT_SERVER_PROTOCOL_EXIT_CODE,
-    )
+    let payload = line.strip_prefix("@ERROR: ").unwrap_or(line);
+    let error = daemon_error(payload, CLIENT_SERVER_PROTOCOL_EXIT_CODE);
+    // upstream: clientserver.c:rsync_module() - a rejected login is reported
+    // as "@ERROR: auth failed on module <name>".
+    match payload.strip_prefix("auth failed on module ") {
+        Some(module) => error.with_failure(AuthError::new(Some(module.to_owned()), payload)),
+        None => error,
+    }
+}
+
+/// Outcome of a successful daemon handshake.
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub(crate) struct DaemonHandshake {
+    /// Negotiated protocol version.
+    pub(crate) protocol: ProtocolVersion,
+    /// Whether the daemon accepted the resume offer (oc-rsync extension).
+    pub(crate) resume_accepted: bool,
 }
 
 /// Performs the rsync daemon handshake protocol.
@@ -192,10 +211,13 @@ fn handle_daemon_at_error(line: &str) -> ClientError {
 /// 3. Send module name
 /// 4. Read response lines (MOTD, `@RSYNCD: OK` / `@RSYNCD: AUTHREQD` / `@ERROR`)
 ///
-/// Returns the negotiated protocol version.
+/// Returns the negotiated protocol version and whether a resume offer was
+/// accepted.
 ///
 /// When `output_motd` is true, MOTD lines are printed to stdout, mirroring
-/// upstream rsync's `output_motd` global variable.
+/// upstream rsync's `output_motd` global variable. When `offer_resume` is
+/// true the greeting carries the oc-rsync resume token; an upstream daemon
+/// ignores it.
 pub(crate) fn perform_daemon_handshake<R: std::io::Read, W: Write>(
     reader: &mut BufReader<R>,
     writer: &mut W,
@@ -205,7 +227,8 @@ pub(crate) fn perform_daemon_handshake<R: std::io::Read, W: Write>(
     early_input: Option<&Path>,
     protocol_override: Option<ProtocolVersion>,
     password_override: Option<&[u8]>,
-) -> Result<ProtocolVersion, ClientError> {
+    offer_resume: bool,
+) -> Result<DaemonHandshake, ClientError> {
     let mut greeting = String::new();
     reader.read_line(&mut greeting).map_err(|e| {
         socket_error(
@@ -255,8 +278,13 @@ pub(crate) fn perform_daemon_handshake<R: std::io::Read, W: Write>(
     // upstream: compat.c:832-845 - for protocol 30+, client must include
     // supported auth digests.
     let our_version = protocol_override.unwrap_or(ProtocolVersion::NEWEST);
+    let resume_token = if offer_resume {
+        format!(" {RESUME_GREETING_TOKEN}")
+    } else {
+        String::new()
+    };
     let client_version = format!(
-        "@RSYNCD: {}.0 sha512 sha256 sha1 md5 md4\n",
+        "@RSYNCD: {}.0 sha512 sha256 sha1 md5 md4{resume_token}\n",
         our_version.as_u8()
     );
     writer.write_all(client_version.as_bytes()).map_err(|e| {
@@ -304,6 +332,7 @@ pub(crate) fn perform_daemon_handshake<R: std::io::Read, W: Write>(
 
     // upstream: clientserver.c:357-390 - loop until @RSYNCD: OK, @ERROR, or
     // @RSYNCD: EXIT. Other lines are MOTD output.
+    let mut resume_accepted = false;
     loop {
         let mut line = String::new();
         let bytes = reader.read_line(&mut line).map_err(|e| {
@@ -375,6 +404,12 @@ pub(crate) fn perform_daemon_handshake<R: std::io::Read, W: Write>(
             break;
         }
 
+        // oc-rsync extension: the daemon accepted the resume offer.
+        if offer_resume && trimmed == RESUME_ACCEPTED_LINE {
+            resume_accepted = true;
+            continue;
+        }
+
         if trimmed == "@RSYNCD: EXIT" {
             return Err(daemon_error(
                 "daemon closed connection",
@@ -399,7 +434,10 @@ pub(crate) fn perform_daemon_handshake<R: std::io::Read, W: Write>(
         remote_protocol
     };
 
-    Ok(negotiated)
+    Ok(DaemonHandshake {
+        protocol: negotiated,
+        resume_accepted,
+    })
 }
 
 /// Maximum early-input file size in bytes.
@@ -498,6 +498,24 @@ mod handle_at_error_tests {
         assert_eq!(err.exit_code(), CLIENT_SERVER_PROTOCOL_EXIT_CODE);
     }
 
+    #[test]
+    fn auth_failure_downcasts_to_auth_error() {
+        let err = handle_daemon_at_error("@ERROR: auth failed on module foo");
+        let auth = std::error::Error::source(&err)
+            .and_then(|source| source.downcast_ref::<crate::failure::AuthError>())
+            .expect("auth failure cause");
+        assert_eq!(auth.module(), Some("foo"));
+    }
+
+    #[test]
+    fn other_at_errors_are_protocol_failures() {
+        let err = handle_daemon_at_error("@ERROR: Unknown module 'bar'");
+        assert!(matches!(
+            err.failure(),
+            Some(crate::failure::Failure::Protocol(_))
+        ));
+    }
+
     /// Regression: an unexpected daemon disconnect during the module-response
     /// phase must fail promptly, not busy-loop printing blank lines.
     ///
@@ -538,6 +556,7 @@ mod handle_at_error_tests {
             None,
             None,
             None,
+            false,
         );
 
         let err = result.expect_err("EOF mid-handshake must be an error, not a hang");
@@ -568,7 +587,9 @@ mod handle_at_error_tests {
             None,
             None,
             None,
+            false,
         )
+        .map(|handshake| handshake.protocol)
     }
 
     // upstream: clientserver.c:189-194 (am_client == 1) - a server greeting at
@@ -622,6 +643,43 @@ mod handle_at_error_tests {
         );
     }
 
+    #[test]
+    fn resume_offer_is_sent_and_acceptance_detected() {
+        use std::io::{BufReader, Cursor};
+
+        let request = DaemonTransferRequest {
+            address: DaemonAddress::new("127.0.0.1".to_owned(), 873).unwrap(),
+            module: "mod".to_owned(),
+            path: String::new(),
+            username: None,
+        };
+        let greeting = "@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4\n";
+        let mut reader = BufReader::new(Cursor::new(
+            format!("{greeting}@RSYNCD: OC-RESUME\n@RSYNCD: OK\n").into_bytes(),
+        ));
+        let mut writer: Vec<u8> = Vec::new();
+
+        let handshake = perform_daemon_handshake(
+            &mut reader,
+            &mut writer,
+            &request,
+            true,
+            &[],
+            None,
+            None,
+            None,
+            true,
+        )
+        .expect("handshake");
+
+        assert!(handshake.resume_accepted);
+        let sent = String::from_utf8(writer).unwrap();
+        assert!(
+            sent.starts_with("@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4 oc-resume\n"),
+            "greeting must carry the resume token: {sent:?}"
+        );
+    }
+
     #[test]
     fn ghsa_rjfm_3w2m_jf4f_payload_renders_intact() {
         // The exact wire string the daemon emits for the GHSA-rjfm-3w2m-jf4f
@@ -638,3 +696,77 @@ mod handle_at_error_tests {
         assert_eq!(err.exit_code(), CLIENT_SERVER_PROTOCOL_EXIT_CODE);
     }
 }
+
+#[cfg(test)]
+mod request_parse_tests {
+    use super::*;
+
+    #[test]
+    fn rsync_url_with_embedded_port_and_deep_path() {
+        let request = DaemonTransferRequest::parse_rsync_url("rsync://host:8873/mod/a/b/c", 873)
+            .expect("parse");
+        assert_eq!(request.address.host(), "host");
+        assert_eq!(request.address.port(), 8873);
+        assert_eq!(request.module, "mod");
+        assert_eq!(request.path, "a/b/c");
+        assert!(request.username.is_none());
+    }
+
+    #[test]
+    fn rsync_url_preserves_trailing_slash() {
+        let request =
+            DaemonTransferRequest::parse_rsync_url("rsync://host/mod/a/b/", 873).expect("parse");
+        assert_eq!(request.path, "a/b/");
+
+        let request =
+            DaemonTransferRequest::parse_rsync_url("rsync://host/mod/", 873).expect("parse");
+        assert_eq!(request.module, "mod");
+        assert_eq!(request.path, "");
+    }
+
+    #[test]
+    fn rsync_url_uses_default_port_only_when_absent() {
+        let request =
+            DaemonTransferRequest::parse_rsync_url("rsync://host/mod", 2873).expect("parse");
+        assert_eq!(request.address.port(), 2873);
+
+        let request =
+            DaemonTransferRequest::parse_rsync_url("rsync://host:8873/mod", 2873).expect("parse");
+        assert_eq!(request.address.port(), 8873);
+    }
+
+    #[test]
+    fn rsync_url_with_user_and_bracketed_ipv6() {
+        let request =
+            DaemonTransferRequest::parse_rsync_url("rsync://alice@[::1]:8873/mod/file", 873)
+                .expect("parse");
+        assert_eq!(request.username.as_deref(), Some("alice"));
+        assert_eq!(request.address.host(), "::1");
+        assert_eq!(request.address.port(), 8873);
+        assert_eq!(request.path, "file");
+    }
+
+    #[test]
+    fn rsync_url_decodes_host_but_keeps_path_literal() {
+        let request = DaemonTransferRequest::parse_rsync_url("rsync://my%2Dhost/mod/a%20b", 873)
+            .expect("parse");
+        assert_eq!(request.address.host(), "my-host");
+        assert_eq!(request.path, "a%20b");
+    }
+
+    #[test]
+    fn rsync_url_without_module_is_rejected() {
+        assert!(DaemonTransferRequest::parse_rsync_url("rsync://host/", 873).is_err());
+        assert!(DaemonTransferRequest::parse_rsync_url("rsync://host", 873).is_err());
+    }
+
+    #[test]
+    fn double_colon_operand_honours_default_port() {
+        let request = DaemonTransferRequest::parse_double_colon("bob@host::mod/sub/dir/", 2873)
+            .expect("parse");
+        assert_eq!(request.username.as_deref(), Some("bob"));
+        assert_eq!(request.address.port(), 2873);
+        assert_eq!(request.module, "mod");
+        assert_eq!(request.path, "sub/dir/");
+    }
+}
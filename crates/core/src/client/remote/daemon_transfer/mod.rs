@@ -9,6 +9,7 @@
 //! Split into submodules by responsibility:
 //! - `connection` - connection establishment, authentication, early-input
 //! - `orchestration` - argument building, transfer execution, server config
+//! - `resume` - `--resume-manifest` recording and exchange
 //!
 //! # Upstream Reference
 //!
@@ -19,11 +20,13 @@
 
 mod connection;
 mod orchestration;
+mod resume;
 
 #[cfg(feature = "tracing")]
 use tracing::instrument;
 
 use std::io::BufReader;
+use std::path::Path;
 use std::sync::{Arc, Mutex};
 use std::time::Duration;
 
@@ -33,15 +36,18 @@ use super::super::DAEMON_SOCKET_TIMEOUT;
 use super::super::config::ClientConfig;
 use super::super::error::{ClientError, invalid_argument_error, socket_error};
 use super::super::module_list::{
-    RshDaemonSpawn, open_daemon_stream, resolve_connect_timeout, spawn_rsh_daemon_stream,
+    ModuleListRequest, RshDaemonSpawn, open_daemon_stream, resolve_connect_timeout,
+    spawn_rsh_daemon_stream,
 };
 use super::super::progress::ClientProgressObserver;
 use super::super::summary::ClientSummary;
 use super::batch_support::build_batch_context;
 use super::invocation::{RemoteRole, TransferSpec, determine_transfer_role};
+use crate::server::FileListCallback;
 
 use connection::{DaemonTransferRequest, perform_daemon_handshake};
 use orchestration::{run_pull_transfer, run_push_transfer, send_daemon_arguments};
+use resume::{manifest_for_transfer, send_resume_manifest};
 
 /// Executes a transfer over daemon protocol (rsync://).
 ///
@@ -56,14 +62,28 @@ use orchestration::{run_pull_transfer, run_push_transfer, send_daemon_arguments}
 ///
 /// Supports `RSYNC_CONNECT_PROG` for piped connections (used by upstream
 /// testsuite) and double-colon syntax (`host::module/path`).
+pub fn run_daemon_transfer(
+    config: &ClientConfig,
+    observer: Option<&mut dyn ClientProgressObserver>,
+    batch_writer: Option<Arc<Mutex<BatchWriter>>>,
+) -> Result<ClientSummary, ClientError> {
+    run_daemon_transfer_with_file_list(config, observer, batch_writer, None)
+}
+
+/// [`run_daemon_transfer`] with a sink for the received file list.
+///
+/// On a pull, `file_list` is handed each file-list entry as the receiver
+/// decodes it (see [`FileListCallback`]); a push has no list to receive and
+/// ignores it.
 #[cfg_attr(
     feature = "tracing",
-    instrument(skip(config, observer), name = "daemon_transfer")
+    instrument(skip(config, observer, file_list), name = "daemon_transfer")
 )]
-pub fn run_daemon_transfer(
+pub(crate) fn run_daemon_transfer_with_file_list(
     config: &ClientConfig,
     observer: Option<&mut dyn ClientProgressObserver>,
     batch_writer: Option<Arc<Mutex<BatchWriter>>>,
+    file_list: Option<Box<dyn FileListCallback>>,
 ) -> Result<ClientSummary, ClientError> {
     let args = config.transfer_args();
     // upstream: options.c:2194 - a single source with list_only set lists the
@@ -110,12 +130,17 @@ pub fn run_daemon_transfer(
         .ok_or_else(|| invalid_argument_error("no daemon URL or host::module operand found", 1))?;
 
     let daemon_operand_str = daemon_operand.to_string_lossy();
+    // upstream: main.c - `--port` replaces the default of 873 but an explicit
+    // `host:port` in the URL wins.
+    let default_port = config
+        .daemon_port()
+        .unwrap_or(ModuleListRequest::DEFAULT_PORT);
     let request = if daemon_operand_str.starts_with("rsync://")
         || daemon_operand_str.starts_with("RSYNC://")
     {
-        DaemonTransferRequest::parse_rsync_url(&daemon_operand_str)?
+        DaemonTransferRequest::parse_rsync_url(&daemon_operand_str, default_port)?
     } else {
-        DaemonTransferRequest::parse_double_colon(&daemon_operand_str)?
+        DaemonTransferRequest::parse_double_colon(&daemon_operand_str, default_port)?
     };
 
     // upstream: socket.c:274-277 - open_socket_out() bounds connect(2) only when
@@ -131,6 +156,7 @@ pub fn run_daemon_transfer(
         config.bind_address().map(|b| b.socket()),
         config.tcp_fastopen(),
         config.sockopts(),
+        config.tls(),
     )?;
 
     // upstream: socket.c:279-280 - set_socket_options(s, sockopts) is applied
@@ -157,12 +183,10 @@ pub fn run_daemon_transfer(
 
     // upstream: io.c - select_timeout() uses io_timeout for all transfer I/O.
     // Configure TCP_NODELAY and transfer-phase timeouts before splitting.
-    // For TCP, settings apply to both halves (shared underlying socket).
+    // For TCP, settings apply to both halves (shared underlying socket), and
+    // `--blocking-timeout` caps each blocked read or write on it.
     // For connect programs, this is a no-op.
-    let transfer_timeout = config
-        .timeout()
-        .as_seconds()
-        .map(|s| std::time::Duration::from_secs(s.get()));
+    let transfer_timeout = config.transfer_socket_timeout();
     stream
         .configure_transfer_options(true, transfer_timeout)
         .map_err(|e| socket_error("configure transfer options on", "daemon socket", e))?;
@@ -176,7 +200,8 @@ pub fn run_daemon_transfer(
     let mut buf_reader = BufReader::new(reader_half);
 
     let output_motd = !config.no_motd();
-    let protocol = perform_daemon_handshake(
+    let resume_manifest = manifest_for_transfer(config, role);
+    let handshake = perform_daemon_handshake(
         &mut buf_reader,
         &mut writer_half,
         &request,
@@ -185,7 +210,9 @@ pub fn run_daemon_transfer(
         config.early_input(),
         config.protocol_version(),
         config.password_override(),
+        resume_manifest.is_some(),
     )?;
+    let protocol = handshake.protocol;
 
     // For pull (we receive), the daemon is the sender, so is_sender=true.
     // For push (we send), the daemon is the receiver, so is_sender=false.
@@ -197,6 +224,9 @@ pub fn run_daemon_transfer(
         protocol,
         daemon_is_sender,
     )?;
+    if let Some(manifest) = resume_manifest.filter(|_| handshake.resume_accepted) {
+        send_resume_manifest(&mut writer_half, manifest, Path::new(&local_paths[0]))?;
+    }
 
     let batch_ctx = batch_writer.map(|bw| build_batch_context(config, bw));
 
@@ -222,9 +252,11 @@ pub fn run_daemon_transfer(
             &local_paths,
             &implied_source_args,
             protocol,
+            resume_manifest,
             batch_ctx,
             buffered,
             observer,
+            file_list,
         ),
         RemoteRole::Sender => run_push_transfer(
             config,
@@ -303,7 +335,11 @@ pub fn run_daemon_over_remote_shell(
         .find(|arg| arg.to_string_lossy().contains("::"))
         .ok_or_else(|| invalid_argument_error("no host::module operand found", 1))?;
     let daemon_operand_str = daemon_operand.to_string_lossy();
-    let request = DaemonTransferRequest::parse_double_colon(&daemon_operand_str)?;
+    // The port is irrelevant when the daemon is spawned over a remote shell.
+    let request = DaemonTransferRequest::parse_double_colon(
+        &daemon_operand_str,
+        ModuleListRequest::DEFAULT_PORT,
+    )?;
 
     // upstream: main.c:603-613 - when daemon_connection > 0, the remote
     // command is `rsync_path --server --daemon .` with no server_options().
@@ -325,10 +361,7 @@ pub fn run_daemon_over_remote_shell(
         address_mode: config.address_mode(),
     })?;
 
-    let transfer_timeout = config
-        .timeout()
-        .as_seconds()
-        .map(|s| Duration::from_secs(s.get()));
+    let transfer_timeout = config.transfer_socket_timeout();
     stream
         .configure_transfer_options(true, transfer_timeout)
         .map_err(|e| socket_error("configure transfer options on", "remote shell stream", e))?;
@@ -339,7 +372,8 @@ pub fn run_daemon_over_remote_shell(
     let mut buf_reader = BufReader::new(reader_half);
 
     let output_motd = !config.no_motd();
-    let protocol = perform_daemon_handshake(
+    let resume_manifest = manifest_for_transfer(config, role);
+    let handshake = perform_daemon_handshake(
         &mut buf_reader,
         &mut writer_half,
         &request,
@@ -348,7 +382,9 @@ pub fn run_daemon_over_remote_shell(
         config.early_input(),
         config.protocol_version(),
         config.password_override(),
+        resume_manifest.is_some(),
     )?;
+    let protocol = handshake.protocol;
 
     let daemon_is_sender = matches!(role, RemoteRole::Receiver);
     send_daemon_arguments(
@@ -358,6 +394,9 @@ pub fn run_daemon_over_remote_shell(
         protocol,
         daemon_is_sender,
     )?;
+    if let Some(manifest) = resume_manifest.filter(|_| handshake.resume_accepted) {
+        send_resume_manifest(&mut writer_half, manifest, Path::new(&local_paths[0]))?;
+    }
 
     let batch_ctx = batch_writer.map(|bw| build_batch_context(config, bw));
 
@@ -377,9 +416,11 @@ pub fn run_daemon_over_remote_shell(
             &local_paths,
             &implied_source_args,
             protocol,
+            resume_manifest,
             batch_ctx,
             buffered,
             observer,
+            None,
         ),
         RemoteRole::Sender => run_push_transfer(
             config,
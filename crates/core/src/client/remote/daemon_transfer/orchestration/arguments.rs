@@ -1197,7 +1197,8 @@ mod server_option_fidelity_tests {
     use protocol::ProtocolVersion;
 
     fn request() -> DaemonTransferRequest {
-        DaemonTransferRequest::parse_rsync_url("rsync://host/mod/path").expect("valid rsync url")
+        DaemonTransferRequest::parse_rsync_url("rsync://host/mod/path", 873)
+            .expect("valid rsync url")
     }
 
     fn args(config: &ClientConfig, is_sender: bool) -> Vec<String> {
@@ -1696,7 +1697,8 @@ mod zero_copy_forwarding_tests {
     use protocol::ProtocolVersion;
 
     fn request() -> DaemonTransferRequest {
-        DaemonTransferRequest::parse_rsync_url("rsync://host/mod/path").expect("valid rsync url")
+        DaemonTransferRequest::parse_rsync_url("rsync://host/mod/path", 873)
+            .expect("valid rsync url")
     }
 
     fn args_for(policy: fast_io::ZeroCopyPolicy, is_sender: bool) -> Vec<String> {
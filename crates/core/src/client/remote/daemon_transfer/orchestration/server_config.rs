@@ -8,7 +8,7 @@ use std::ffi::OsString;
 use protocol::filters::FilterRuleWireFormat;
 
 use crate::client::config::ClientConfig;
-use crate::client::error::{ClientError, invalid_argument_error};
+use crate::client::error::{ClientError, invalid_argument_error, resolve_copy_as};
 use crate::client::remote::flags;
 
 use crate::server::{ServerConfig, ServerRole};
@@ -152,7 +152,13 @@ pub(crate) fn build_server_config_for_receiver(
     // side is the sender. Without this the daemon pull left files at their
     // existing mode while the local copy executor honoured -E.
     server_config.flags.preserve_executability = config.preserve_executability();
+    // The daemon is not ours to trust: cap the file list it may send on top
+    // of --max-alloc, so a malicious server cannot make this receiver grow
+    // the list until memory runs out.
+    server_config.file_list_limits = config.file_list_limits();
 
+    // The local receiver's --copy-as; see `resolve_copy_as`.
+    server_config.copy_as = resolve_copy_as(config.copy_as())?;
     flags::apply_common_server_flags(config, &mut server_config);
     Ok(server_config)
 }
@@ -208,6 +214,9 @@ fn apply_common_daemon_config(
     server_config.connection.filter_rules = filter_rules;
 
     server_config.flags.verbose = config.verbosity() > 0;
+    // upstream: log.c:rwrite() - `--stderr=all` sends the daemon's info
+    // messages to the client's stderr, same as the remote-shell path.
+    server_config.flags.msgs_to_stderr = config.msgs2stderr() == Some(true);
 
     // upstream: numeric_ids and delete are --numeric-ids / --delete-* long-form args only.
     server_config.flags.numeric_ids = crate::server::NumericIds::from_client(config.numeric_ids());
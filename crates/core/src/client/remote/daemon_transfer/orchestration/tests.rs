@@ -1014,6 +1014,17 @@ mod server_config_reference_dirs {
     use super::*;
     use crate::client::config::ReferenceDirectoryKind;
 
+    #[test]
+    fn receiver_config_rejects_unresolvable_copy_as() {
+        let config = ClientConfig::builder()
+            .copy_as(Some(":1000".into()))
+            .build();
+        let error = build_server_config_for_receiver(&config, &["dest".to_owned()], Vec::new())
+            .expect_err("an unresolvable --copy-as must fail the pull");
+        assert_eq!(error.exit_code(), 1);
+        assert!(error.to_string().contains("invalid --copy-as"), "{error}");
+    }
+
     #[test]
     fn receiver_config_propagates_reference_directories() {
         let config = ClientConfig::builder()
@@ -1498,6 +1509,43 @@ mod server_config_reference_dirs {
             "itemize should be false by default"
         );
     }
+
+    /// A daemon pull caps the file list the server may send, while the
+    /// generator side, reading nothing from the daemon, stays uncapped.
+    #[test]
+    fn receiver_config_caps_the_daemon_file_list() {
+        use protocol::flist::FileListLimits;
+
+        let config = ClientConfig::default();
+        let receiver =
+            build_server_config_for_receiver(&config, &["dest".to_owned()], Vec::new()).unwrap();
+        assert_eq!(receiver.file_list_limits, FileListLimits::default());
+        let generator =
+            build_server_config_for_generator(&config, &["src".to_owned()], Vec::new()).unwrap();
+        assert_eq!(generator.file_list_limits, FileListLimits::UNLIMITED);
+
+        let lifted = ClientConfig::builder()
+            .file_list_limits(FileListLimits::UNLIMITED)
+            .build();
+        let receiver =
+            build_server_config_for_receiver(&lifted, &["dest".to_owned()], Vec::new()).unwrap();
+        assert_eq!(receiver.file_list_limits, FileListLimits::UNLIMITED);
+
+        // --max-alloc=16G lets a legitimately large tree through.
+        let raised = ClientConfig::builder().max_alloc(Some(16 << 30)).build();
+        let receiver =
+            build_server_config_for_receiver(&raised, &["dest".to_owned()], Vec::new()).unwrap();
+        assert_eq!(
+            receiver.file_list_limits,
+            FileListLimits::for_max_alloc(16 << 30)
+        );
+        assert!(
+            receiver
+                .file_list_limits
+                .check(protocol::flist::DEFAULT_MAX_FILE_LIST_ENTRIES + 1, 0)
+                .is_ok()
+        );
+    }
 }
 
 mod dry_run_remote_close_tests {
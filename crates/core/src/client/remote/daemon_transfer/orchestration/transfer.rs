@@ -6,10 +6,11 @@
 use std::io::Write;
 use std::path::Path;
 use std::sync::Arc;
-use std::time::Instant;
+use std::time::{Duration, Instant};
 
 use protocol::ProtocolVersion;
 
+use super::super::resume::{ResumeRecorder, finish_resume_manifest};
 use super::server_config::{build_server_config_for_generator, build_server_config_for_receiver};
 use super::stats::convert_server_stats_to_summary;
 use crate::client::config::ClientConfig;
@@ -25,7 +26,7 @@ use crate::client::summary::ClientSummary;
 use crate::exit_code::ExitCode;
 use crate::message::Role;
 use crate::server::handshake::HandshakeResult;
-use crate::server::{TransferProgressCallback, TransferProgressEvent};
+use crate::server::{FileListCallback, TransferProgressCallback, TransferProgressEvent};
 
 /// Executes a pull transfer (remote to local).
 ///
@@ -38,6 +39,10 @@ use crate::server::{TransferProgressCallback, TransferProgressEvent};
 /// 3. `io_start_multiplex_out()` activates output multiplex
 /// 4. `send_filter_list()` sends filters after multiplex activation
 /// 5. File list exchange and transfer
+///
+/// With `resume_manifest`, each completed file is appended to the manifest,
+/// which is removed once the pull succeeds. With `file_list`, each received
+/// file-list entry is handed to it as the receiver decodes it.
 #[allow(clippy::too_many_arguments)]
 pub(crate) fn run_pull_transfer(
     config: &ClientConfig,
@@ -47,9 +52,11 @@ pub(crate) fn run_pull_transfer(
     local_paths: &[String],
     implied_source_args: &[String],
     protocol: ProtocolVersion,
+    resume_manifest: Option<&Path>,
     batch_ctx: Option<BatchContext>,
     buffered: Vec<u8>,
     observer: Option<&mut dyn ClientProgressObserver>,
+    file_list: Option<Box<dyn FileListCallback>>,
 ) -> Result<ClientSummary, ClientError> {
     let filter_rules =
         flags::build_wire_format_rules(config.filter_rules(), config.delete_excluded())?;
@@ -102,15 +109,28 @@ pub(crate) fn run_pull_transfer(
 
     let start = Instant::now();
     let mut adapter = observer.map(|obs| DaemonProgressAdapter::new(obs, start));
-    let progress: Option<&mut dyn TransferProgressCallback> = adapter
+    let mut progress: Option<&mut dyn TransferProgressCallback> = adapter
         .as_mut()
         .map(|a| a as &mut dyn TransferProgressCallback);
+    let mut recorder = resume_manifest
+        .map(|manifest| ResumeRecorder::open(manifest, progress.take()))
+        .transpose()?;
+    if let Some(recorder) = recorder.as_mut() {
+        progress = Some(recorder);
+    }
 
     // upstream: io.c:1551-1561 - the daemon sends MSG_IO_TIMEOUT once, right
     // after io_start_multiplex_out (main.c:1267-1268). As the client receiver we
     // adopt it and re-apply to the live socket. Build the re-apply hook from the
     // split socket halves; connect-program (pipe) transports yield None.
-    let io_timeout_reapply = build_io_timeout_reapply(reader, writer);
+    let io_timeout_reapply = build_io_timeout_reapply(
+        reader,
+        writer,
+        config
+            .blocking_timeout()
+            .as_seconds()
+            .map(|secs| Duration::from_secs(secs.get())),
+    );
     let server_stats = crate::server::run_server_with_handshake_adopting(
         server_config,
         handshake,
@@ -121,10 +141,15 @@ pub(crate) fn run_pull_transfer(
             batch: batch_recording,
             itemize: None,
             io_timeout_reapply,
+            sendfile_socket: None,
+            file_list,
         },
     )
     .map_err(|e| map_server_transfer_error(e, Role::Receiver))?;
     let elapsed = start.elapsed();
+    if let Some(manifest) = resume_manifest {
+        finish_resume_manifest(manifest)?;
+    }
 
     let mut summary = convert_server_stats_to_summary(server_stats, elapsed);
     summary.set_protocol_version(protocol.as_u8());
@@ -195,17 +220,30 @@ pub(crate) fn run_push_transfer(
         let _ = out.write_all(line.as_bytes());
     };
 
-    let result = crate::server::run_server_with_handshake(
+    // Uncompressed whole-file literals may move from the page cache straight
+    // onto the daemon socket with sendfile(2); the generator re-checks the
+    // per-file conditions. Connect-program transports expose no socket.
+    let sendfile_socket = (config.zero_copy_policy() != fast_io::ZeroCopyPolicy::Disabled)
+        .then(|| writer.try_clone_tcp())
+        .flatten()
+        .map(crate::server::SendfileSocket::new);
+
+    let result = crate::server::run_server_with_handshake_adopting(
         server_config,
         handshake,
         reader,
         writer,
-        progress,
-        batch_recording,
-        if wants_client_output {
-            Some(&mut itemize_cb as &mut dyn crate::server::ItemizeCallback)
-        } else {
-            None
+        crate::server::ServerTransferHooks {
+            progress,
+            batch: batch_recording,
+            itemize: if wants_client_output {
+                Some(&mut itemize_cb as &mut dyn crate::server::ItemizeCallback)
+            } else {
+                None
+            },
+            io_timeout_reapply: None,
+            sendfile_socket,
+            file_list: None,
         },
     );
 
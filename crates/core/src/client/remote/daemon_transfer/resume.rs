@@ -0,0 +1,179 @@
+//! Client side of resumable daemon pulls (`--resume-manifest`).
+//!
+//! While a pull runs, every file the receiver completes is appended to the
+//! manifest file, one relative path per line, so the record survives an
+//! interrupted connection. On the next run the client hashes each recorded
+//! file under the destination and sends the list to the daemon (see
+//! [`protocol::daemon_resume`]), which leaves unchanged files out of the file
+//! list. The manifest is removed once a pull finishes cleanly.
+//!
+//! Resume is only offered for pulls that cannot observe the trimmed file
+//! list: `--delete` would remove the skipped files, and `--list-only` or
+//! `--dry-run` would misreport them. Those runs ignore the manifest.
+//!
+//! Upstream rsync has no equivalent.
+
+use std::collections::BTreeSet;
+use std::fs::{self, File, OpenOptions};
+use std::io::{self, Write};
+use std::path::Path;
+
+use protocol::daemon_resume::{ResumeEntry, write_resume_manifest};
+
+use crate::client::config::ClientConfig;
+use crate::client::error::{ClientError, resume_manifest_error, socket_error};
+use crate::client::remote::invocation::RemoteRole;
+use crate::server::{TransferProgressCallback, TransferProgressEvent, resume_file_digest};
+
+/// Returns the manifest to offer and record for this transfer, if any.
+pub(super) fn manifest_for_transfer(config: &ClientConfig, role: RemoteRole) -> Option<&Path> {
+    let eligible = matches!(role, RemoteRole::Receiver)
+        && !config.delete()
+        && !config.list_only()
+        && !config.dry_run();
+    config.resume_manifest().filter(|_| eligible)
+}
+
+/// Sends the manifest block for files recorded under `destination`.
+///
+/// Recorded files that are missing or no longer regular files are left out;
+/// a missing manifest sends an empty block.
+pub(super) fn send_resume_manifest<W: Write>(
+    writer: &mut W,
+    manifest: &Path,
+    destination: &Path,
+) -> Result<(), ClientError> {
+    let recorded = match fs::read_to_string(manifest) {
+        Ok(text) => text,
+        Err(error) if error.kind() == io::ErrorKind::NotFound => String::new(),
+        Err(error) => return Err(resume_manifest_error("read", manifest, error)),
+    };
+    let paths: BTreeSet<&str> = recorded.lines().filter(|line| !line.is_empty()).collect();
+
+    let mut entries = Vec::with_capacity(paths.len());
+    for path in paths {
+        let local = destination.join(path);
+        if !fs::symlink_metadata(&local).is_ok_and(|metadata| metadata.is_file()) {
+            continue;
+        }
+        if let Ok(digest) = resume_file_digest(&local) {
+            entries.push(ResumeEntry {
+                digest,
+                path: path.to_owned(),
+            });
+        }
+    }
+
+    write_resume_manifest(writer, &entries)
+        .map_err(|error| socket_error("send resume manifest to", "daemon", error))
+}
+
+/// Removes the manifest after a pull completed without error.
+pub(super) fn finish_resume_manifest(manifest: &Path) -> Result<(), ClientError> {
+    match fs::remove_file(manifest) {
+        Ok(()) => Ok(()),
+        Err(error) if error.kind() == io::ErrorKind::NotFound => Ok(()),
+        Err(error) => Err(resume_manifest_error("remove", manifest, error)),
+    }
+}
+
+/// Progress callback that appends each completed file to the manifest.
+///
+/// Forwards every event to the wrapped callback. Write failures stop the
+/// recording but never the transfer; the next run just resumes earlier.
+pub(super) struct ResumeRecorder<'a> {
+    file: Option<File>,
+    inner: Option<&'a mut dyn TransferProgressCallback>,
+}
+
+impl<'a> ResumeRecorder<'a> {
+    /// Opens `manifest` for appending, creating it if needed.
+    pub(super) fn open(
+        manifest: &Path,
+        inner: Option<&'a mut dyn TransferProgressCallback>,
+    ) -> Result<Self, ClientError> {
+        let file = OpenOptions::new()
+            .create(true)
+            .append(true)
+            .open(manifest)
+            .map_err(|error| resume_manifest_error("open", manifest, error))?;
+        Ok(Self {
+            file: Some(file),
+            inner,
+        })
+    }
+}
+
+impl TransferProgressCallback for ResumeRecorder<'_> {
+    fn on_file_transferred(&mut self, event: &TransferProgressEvent<'_>) {
+        if let Some(file) = self.file.as_mut()
+            && let Some(path) = event.path.to_str()
+            && !path.is_empty()
+            && !path.contains('\n')
+            && file.write_all(format!("{path}\n").as_bytes()).is_err()
+        {
+            self.file = None;
+        }
+        if let Some(inner) = self.inner.as_mut() {
+            inner.on_file_transferred(event);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use protocol::daemon_resume::{RESUME_MAX_ENTRIES, read_resume_manifest};
+    use std::io::Cursor;
+
+    fn event(path: &Path) -> TransferProgressEvent<'_> {
+        TransferProgressEvent {
+            path,
+            file_bytes: 1,
+            total_file_bytes: Some(1),
+            files_done: 1,
+            total_files: 1,
+            flist_eof: true,
+        }
+    }
+
+    #[test]
+    fn recorded_files_are_sent_with_their_digests() {
+        let dir = tempfile::tempdir().expect("tempdir");
+        let dest = dir.path().join("dest");
+        fs::create_dir_all(dest.join("sub")).expect("mkdir");
+        fs::write(dest.join("a.txt"), b"alpha").expect("write a");
+        fs::write(dest.join("sub/b.txt"), b"beta").expect("write b");
+        let manifest = dir.path().join("pull.manifest");
+
+        let mut recorder = ResumeRecorder::open(&manifest, None).expect("open");
+        recorder.on_file_transferred(&event(Path::new("a.txt")));
+        recorder.on_file_transferred(&event(Path::new("sub/b.txt")));
+        recorder.on_file_transferred(&event(Path::new("gone.txt")));
+        recorder.on_file_transferred(&event(Path::new("a.txt")));
+        drop(recorder);
+
+        let mut wire = Vec::new();
+        send_resume_manifest(&mut wire, &manifest, &dest).expect("send");
+        let entries =
+            read_resume_manifest(&mut Cursor::new(wire), RESUME_MAX_ENTRIES).expect("read");
+        let paths: Vec<&str> = entries.iter().map(|entry| entry.path.as_str()).collect();
+        assert_eq!(paths, ["a.txt", "sub/b.txt"]);
+        assert_eq!(
+            entries[0].digest,
+            resume_file_digest(&dest.join("a.txt")).expect("digest")
+        );
+
+        finish_resume_manifest(&manifest).expect("finish");
+        assert!(!manifest.exists());
+        finish_resume_manifest(&manifest).expect("finish is idempotent");
+    }
+
+    #[test]
+    fn missing_manifest_sends_empty_block() {
+        let dir = tempfile::tempdir().expect("tempdir");
+        let mut wire = Vec::new();
+        send_resume_manifest(&mut wire, &dir.path().join("none"), dir.path()).expect("send");
+        assert_eq!(wire, b"\n");
+    }
+}
@@ -33,7 +33,7 @@ use rsync_io::ssh::embedded::SshConfig;
 use super::super::config::ClientConfig;
 #[allow(unused_imports)] // REASON: used in tests
 use super::super::config::EmbeddedSshOptions;
-use super::super::error::{ClientError, invalid_argument_error};
+use super::super::error::{ClientError, invalid_argument_error, resolve_copy_as};
 use super::super::progress::ClientProgressObserver;
 use super::super::summary::ClientSummary;
 use super::batch_support::{build_batch_context, build_batch_recording};
@@ -649,6 +649,8 @@ fn build_server_config_for_receiver(
     // existing mode while the local copy executor honoured -E.
     server_config.flags.preserve_executability = config.preserve_executability();
 
+    // The local receiver's --copy-as; see `resolve_copy_as`.
+    server_config.copy_as = resolve_copy_as(config.copy_as())?;
     flags::apply_common_server_flags(config, &mut server_config);
     Ok(server_config)
 }
@@ -10,6 +10,8 @@
 //! - `options.c:parse_arguments()` - Server-side flag parsing
 //! - `exclude.c:send_rules()` - Filter rule wire format
 
+use std::ffi::OsStr;
+
 use protocol::filters::{FilterRuleWireFormat, RuleType};
 
 use super::super::config::{ClientConfig, DeleteMode, FilterRuleKind, FilterRuleSpec};
@@ -427,6 +429,18 @@ pub(crate) fn apply_common_server_flags(config: &ClientConfig, server_config: &m
     server_config.flags.remove_source_files = config.remove_source_files();
     server_config.has_partial_dir = config.partial_directory().is_some();
     server_config.partial_dir = config.partial_directory().map(std::path::Path::to_path_buf);
+    // `--copy-buffer-size` is an oc-rsync extension and is never forwarded.
+    // It tunes the file I/O of the local half: the sender's reads on a push,
+    // the receiver's writes on a pull.
+    server_config.write.copy_buffer_size = config.copy_buffer_size();
+    // `--checksum-xattr` is likewise local-only: the local sender takes the
+    // file-list sums from the attribute and the local receiver its
+    // quick-check sums, while the peer hashes its side as usual.
+    server_config.file_selection.checksum_xattr =
+        config.checksum_xattr_name().map(OsStr::to_os_string);
+    // Embedder file hooks run in this process's generator, which only exists
+    // on a pull; run_client refuses them for a push.
+    server_config.file_hooks = config.file_hooks().clone();
     server_config.file_selection.min_file_size = config.min_file_size();
     server_config.file_selection.max_file_size = config.max_file_size();
     // upstream: generator.c:quick_check_ok() -> same_time() applies the
@@ -461,6 +475,9 @@ pub(crate) fn apply_common_server_flags(config: &ClientConfig, server_config: &m
     // but a pull sender never deletes, so the cap must be carried onto this
     // local receiver config or it is silently ignored (unbounded deletion).
     server_config.deletion.max_delete = config.max_delete();
+    // upstream: generator.c:recv_generator() - `--force` lets the pull
+    // receiver remove a non-empty directory standing where a file arrives.
+    server_config.deletion.force_delete = config.force_replacements();
     logging::debug_log!(
         Del,
         2,
@@ -880,6 +897,43 @@ mod tests {
         );
     }
 
+    #[test]
+    fn apply_common_server_flags_carries_copy_buffer_size() {
+        let config = ClientConfig::builder()
+            .copy_buffer_size(Some(64 * 1024))
+            .build();
+        let mut server_config = ServerConfig::default();
+        apply_common_server_flags(&config, &mut server_config);
+        assert_eq!(server_config.write.copy_buffer_size, Some(64 * 1024));
+
+        let mut server_config = ServerConfig::default();
+        apply_common_server_flags(&ClientConfig::default(), &mut server_config);
+        assert_eq!(server_config.write.copy_buffer_size, None);
+    }
+
+    #[test]
+    fn apply_common_server_flags_carries_checksum_xattr() {
+        let config = ClientConfig::builder()
+            .checksum(true)
+            .checksum_xattr_name(Some("user.rsync.checksum"))
+            .build();
+        let mut server_config = ServerConfig::default();
+        apply_common_server_flags(&config, &mut server_config);
+        assert_eq!(
+            server_config.file_selection.checksum_xattr.as_deref(),
+            Some(OsStr::new("user.rsync.checksum"))
+        );
+    }
+
+    #[test]
+    fn apply_common_server_flags_carries_file_hooks() {
+        let hooks = crate::client::LocalCopyFileHooks::new().should_transfer(|_| false);
+        let config = ClientConfig::builder().file_hooks(hooks.clone()).build();
+        let mut server_config = ServerConfig::default();
+        apply_common_server_flags(&config, &mut server_config);
+        assert_eq!(server_config.file_hooks, hooks);
+    }
+
     #[test]
     fn apply_common_server_flags_default_compress_leaves_choice_none() {
         // Plain `-z` (no explicit choice) must leave compress_choice None so the
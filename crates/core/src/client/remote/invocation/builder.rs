@@ -150,7 +150,9 @@ impl<'a> RemoteInvocationBuilder<'a> {
         self.append_long_form_args(&mut args);
         args.push(OsString::from("."));
         for path in remote_paths {
-            args.push(OsString::from(*path));
+            // upstream: options.c safe_arg() - escape_leading_dash applies even
+            // under protect_args, so a `-name` path cannot become an option.
+            args.push(OsString::from(dash_safe_filename_arg(path)));
         }
         args.into_iter()
             .map(|a| a.to_string_lossy().into_owned())
@@ -282,7 +284,9 @@ impl<'a> RemoteInvocationBuilder<'a> {
                 };
                 args.push(OsString::from(escaped));
             } else {
-                args.push(OsString::from(*path));
+                // upstream: options.c safe_arg() - --old-args skips the shell
+                // escaping but still prefixes a leading `-` with `./`.
+                args.push(OsString::from(dash_safe_filename_arg(path)));
             }
         }
 
@@ -1202,6 +1206,21 @@ pub(super) fn shell_safe_filename_arg_with_tilde(arg: &str, escape_leading_tilde
     out
 }
 
+/// Prefixes a filename argument that begins with `-` with `./`.
+///
+/// Mirrors the part of upstream `options.c:safe_arg(NULL, arg)` that is not
+/// conditional on the argument-passing mode: `escape_leading_dash` is set for
+/// every filename argument, so even under `--secluded-args` (no shell) and
+/// `--old-args` (no escaping) the remote server never parses a path such as
+/// `--delete` or `-e` as an option.
+pub(super) fn dash_safe_filename_arg(arg: &str) -> String {
+    if arg.starts_with('-') {
+        format!("./{arg}")
+    } else {
+        arg.to_owned()
+    }
+}
+
 /// Converts a `CompressionLevel` into its numeric representation for the wire.
 ///
 /// upstream: options.c:2755-2756 - `--compress-level=%d` forwards the signed
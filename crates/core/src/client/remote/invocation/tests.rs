@@ -3529,7 +3529,9 @@ fn iconv_explicit_single_forwards_whole_spec() {
 // filename args when protect_args is off. These tests verify that oc-rsync
 // produces the same escaping, matching the lsh.sh `eval "$@"` contract.
 
-use super::builder::{shell_safe_filename_arg, shell_safe_filename_arg_with_tilde};
+use super::builder::{
+    dash_safe_filename_arg, shell_safe_filename_arg, shell_safe_filename_arg_with_tilde,
+};
 
 #[test]
 fn shell_safe_simple_path_unchanged() {
@@ -3653,6 +3655,54 @@ fn shell_safe_escaping_in_normal_mode() {
     );
 }
 
+#[test]
+fn dash_safe_prefixes_only_a_leading_dash() {
+    assert_eq!(dash_safe_filename_arg("-e"), "./-e");
+    assert_eq!(dash_safe_filename_arg("--delete"), "./--delete");
+    assert_eq!(dash_safe_filename_arg("a-b"), "a-b");
+    assert_eq!(dash_safe_filename_arg("a weird)name"), "a weird)name");
+}
+
+// upstream: options.c safe_arg() - escape_leading_dash is set for every
+// filename argument regardless of protect_args / old_style_args, so a remote
+// path that looks like an option reaches the server as `./-...` in all three
+// argument-passing modes.
+#[test]
+fn leading_dash_path_is_never_an_option_on_the_remote() {
+    let path = "--delete-excluded";
+    let expected = "./--delete-excluded";
+
+    let config = ClientConfig::builder().build();
+    let args = RemoteInvocationBuilder::new(&config, RemoteRole::Receiver).build(path);
+    assert!(
+        args.iter().any(|a| a == expected) && !args.iter().any(|a| a == path),
+        "default mode must prefix the path: {args:?}"
+    );
+
+    let config = ClientConfig::builder().protect_args(Some(true)).build();
+    let secluded =
+        RemoteInvocationBuilder::new(&config, RemoteRole::Receiver).build_secluded(&[path]);
+    assert_eq!(
+        secluded.stdin_args.last().map(String::as_str),
+        Some(expected)
+    );
+    assert!(
+        !secluded
+            .command_line_args
+            .iter()
+            .any(|a| a.to_string_lossy().contains("delete-excluded")),
+        "secluded mode keeps the path off the command line: {:?}",
+        secluded.command_line_args
+    );
+
+    let config = ClientConfig::builder().old_args(Some(true)).build();
+    let args = RemoteInvocationBuilder::new(&config, RemoteRole::Receiver).build(path);
+    assert!(
+        args.iter().any(|a| a == expected) && !args.iter().any(|a| a == path),
+        "--old-args must still prefix the path: {args:?}"
+    );
+}
+
 // --usermap / --groupmap forwarding tests
 
 #[cfg(unix)]
@@ -83,6 +83,17 @@ mod tests {
         assert!(server_config.flags.prune_empty_dirs);
     }
 
+    #[test]
+    fn receiver_server_config_rejects_unresolvable_copy_as() {
+        let config = ClientConfig::builder()
+            .copy_as(Some(":1000".into()))
+            .build();
+        let error = build_server_config_for_receiver(&config, &["dest/".to_owned()])
+            .expect_err("an unresolvable --copy-as must fail the pull");
+        assert_eq!(error.exit_code(), 1);
+        assert!(error.to_string().contains("invalid --copy-as"), "{error}");
+    }
+
     #[test]
     fn receiver_server_config_prune_empty_dirs_default_false() {
         let config = ClientConfig::builder().recursive(true).times(true).build();
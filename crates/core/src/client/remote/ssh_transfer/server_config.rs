@@ -8,7 +8,7 @@
 use std::ffi::OsString;
 
 use super::super::super::config::ClientConfig;
-use super::super::super::error::{ClientError, invalid_argument_error};
+use super::super::super::error::{ClientError, invalid_argument_error, resolve_copy_as};
 use super::super::flags;
 use crate::server::{ServerConfig, ServerRole};
 
@@ -183,6 +183,8 @@ pub(in crate::client::remote) fn build_server_config_for_receiver(
     // the receiver's default output, so keep the two wired together.
     server_config.flags.info_flags.out_format_active = config.render_out_format_locally();
 
+    // The local receiver's --copy-as; see `resolve_copy_as`.
+    server_config.copy_as = resolve_copy_as(config.copy_as())?;
     flags::apply_common_server_flags(config, &mut server_config);
     Ok(server_config)
 }
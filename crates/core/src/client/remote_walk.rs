@@ -0,0 +1,173 @@
+//! Streaming walk of a daemon module's file list.
+//!
+//! [`walk_remote`] runs a list-only pull against an rsync daemon on a worker
+//! thread and yields every file-list entry as the receiver decodes it, instead
+//! of collecting the whole listing into the final [`ClientSummary`]. With
+//! incremental recursion the daemon sends one sub-list per directory, so the
+//! first entries reach the caller while the daemon is still scanning the rest
+//! of the tree.
+//!
+//! # Examples
+//!
+//! ```ignore
+//! use core::client::{ClientConfig, walk_remote};
+//!
+//! let config = ClientConfig::builder()
+//!     .transfer_args(["rsync://example.com/pub/"])
+//!     .list_only(true)
+//!     .recursive(true)
+//!     .build();
+//!
+//! let mut walk = walk_remote(config)?;
+//! for entry in walk.by_ref() {
+//!     println!("{} {}", entry.size(), entry.path().display());
+//! }
+//! walk.finish()?;
+//! ```
+
+use std::path::{Path, PathBuf};
+use std::sync::mpsc::{self, Receiver};
+use std::thread::{self, JoinHandle};
+
+use super::config::ClientConfig;
+use super::error::{ClientError, invalid_argument_error};
+use super::remote::daemon_transfer::run_daemon_transfer_with_file_list;
+use super::summary::ClientSummary;
+use crate::server::ListOnlyEntry;
+
+/// One entry of a remote file list, as yielded by [`RemoteWalk`].
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub struct RemoteFileEntry {
+    path: PathBuf,
+    size: u64,
+    mode: u32,
+    mtime: i64,
+    link_target: Option<PathBuf>,
+}
+
+impl RemoteFileEntry {
+    /// Returns the path relative to the root of the walk.
+    #[must_use]
+    pub fn path(&self) -> &Path {
+        &self.path
+    }
+
+    /// Returns the size in bytes.
+    #[must_use]
+    pub const fn size(&self) -> u64 {
+        self.size
+    }
+
+    /// Returns the full Unix mode bits (file type and permissions).
+    #[must_use]
+    pub const fn mode(&self) -> u32 {
+        self.mode
+    }
+
+    /// Returns the modification time in seconds since the Unix epoch.
+    #[must_use]
+    pub const fn mtime(&self) -> i64 {
+        self.mtime
+    }
+
+    /// Returns the target of a symbolic link, or `None` for other entries.
+    #[must_use]
+    pub fn link_target(&self) -> Option<&Path> {
+        self.link_target.as_deref()
+    }
+}
+
+impl From<&ListOnlyEntry> for RemoteFileEntry {
+    fn from(entry: &ListOnlyEntry) -> Self {
+        Self {
+            path: entry.path.clone(),
+            size: entry.size,
+            mode: entry.mode,
+            mtime: entry.mtime,
+            link_target: entry.symlink_target.clone(),
+        }
+    }
+}
+
+/// A running remote walk started by [`walk_remote`].
+///
+/// Iterating yields entries in the order the daemon sends them and ends when
+/// the file list is complete or the transfer fails; [`RemoteWalk::finish`]
+/// then reports which. Dropping the walk early stops delivery, but the worker
+/// still reads the rest of the list so the session ends cleanly.
+#[derive(Debug)]
+pub struct RemoteWalk {
+    entries: Receiver<RemoteFileEntry>,
+    worker: JoinHandle<Result<ClientSummary, ClientError>>,
+}
+
+impl RemoteWalk {
+    /// Waits for the walk to end and returns the transfer outcome.
+    ///
+    /// Entries not yet consumed are discarded.
+    ///
+    /// # Errors
+    ///
+    /// Returns the error the list-only transfer failed with, such as a refused
+    /// module or a dropped connection.
+    pub fn finish(self) -> Result<ClientSummary, ClientError> {
+        drop(self.entries);
+        self.worker
+            .join()
+            .unwrap_or_else(|_| Err(invalid_argument_error("remote walk thread panicked", 23)))
+    }
+}
+
+impl Iterator for RemoteWalk {
+    type Item = RemoteFileEntry;
+
+    fn next(&mut self) -> Option<Self::Item> {
+        self.entries.recv().ok()
+    }
+}
+
+/// Starts streaming the file list of a daemon module.
+///
+/// `config` must describe a list-only pull of one `rsync://` or `host::module`
+/// operand; its other options (port, password, filters, `--recursive`, ...)
+/// apply as they would to `rsync --list-only`. The connection and transfer run
+/// on a worker thread, so this returns once the thread is started.
+///
+/// # Errors
+///
+/// Returns an error when `config` is not list-only, names no daemon operand,
+/// or tunnels the daemon over `--rsh`.
+pub fn walk_remote(config: ClientConfig) -> Result<RemoteWalk, ClientError> {
+    if !config.list_only() {
+        return Err(invalid_argument_error(
+            "a remote walk requires a list-only transfer",
+            1,
+        ));
+    }
+    let has_daemon_operand = config.transfer_args().iter().any(|arg| {
+        let arg = arg.to_string_lossy();
+        arg.starts_with("rsync://") || arg.starts_with("RSYNC://") || arg.contains("::")
+    });
+    if !has_daemon_operand {
+        return Err(invalid_argument_error(
+            "a remote walk requires an rsync:// or host::module operand",
+            1,
+        ));
+    }
+    if config.remote_shell().is_some() {
+        return Err(invalid_argument_error(
+            "a remote walk cannot tunnel the daemon over a remote shell",
+            1,
+        ));
+    }
+
+    let (sender, entries) = mpsc::channel();
+    let sink = move |entry: &ListOnlyEntry| {
+        // The walk was dropped; keep reading so the session completes.
+        let _ = sender.send(RemoteFileEntry::from(entry));
+    };
+    let worker = thread::spawn(move || {
+        run_daemon_transfer_with_file_list(&config, None, None, Some(Box::new(sink)))
+    });
+    Ok(RemoteWalk { entries, worker })
+}
@@ -67,10 +67,14 @@ use engine::local_copy::{
 };
 
 use super::config::{BandwidthLimit, ClientConfig, DeleteMode};
-use super::error::{ClientError, map_local_copy_error, missing_operands_error, validate_temp_dir};
+use super::error::{
+    ClientError, invalid_argument_error_typed, map_local_copy_error, missing_operands_error,
+    resolve_copy_as, validate_temp_dir,
+};
 use super::progress::{ClientProgressForwarder, ClientProgressObserver};
 use super::remote;
 use super::summary::ClientSummary;
+use crate::exit_code::ExitCode;
 
 /// Runs the client orchestration using the provided configuration.
 ///
@@ -187,19 +191,30 @@ fn run_client_internal(
 
     apply_max_alloc(&config);
 
+    let dest_is_local = config
+        .transfer_args()
+        .last()
+        .is_none_or(|dest| !remote::operand_is_remote(dest));
+
     // upstream: main.c:1031-1046 do_recv() - the receiver validates --temp-dir
     // exists and is a directory before transferring. tmpdir is a receiver-only
     // option (options.c:2925 forwards it only when am_sender), so the check
     // fires only when the local process receives: a local copy or a pull (local
     // destination), never a push (remote destination).
-    if let Some(temp_dir) = config.temp_directory() {
-        let dest_is_local = config
-            .transfer_args()
-            .last()
-            .is_none_or(|dest| !remote::operand_is_remote(dest));
-        if dest_is_local {
-            validate_temp_dir(temp_dir)?;
-        }
+    if let Some(temp_dir) = config.temp_directory()
+        && dest_is_local
+    {
+        validate_temp_dir(temp_dir)?;
+    }
+
+    // The file hooks run in the generator. A push runs it on the remote host,
+    // where a veto could not reach it, so refuse the combination before
+    // anything is written rather than transfer a vetoed file.
+    if !config.file_hooks().is_empty() && !dest_is_local {
+        return Err(invalid_argument_error_typed(
+            "file hooks are only supported for local copies and pulls, not pushes to a remote destination",
+            ExitCode::Syntax,
+        ));
     }
 
     let batch_writer = if let Some(batch_cfg) = config.batch_config() {
@@ -313,6 +328,10 @@ fn run_client_internal(
         Err(error) => return Err(map_local_copy_error(error)),
     };
 
+    // upstream: main.c:do_recv() - a local copy runs its receiver in this
+    // process, so an unresolvable --copy-as is fatal here as on a pull.
+    resolve_copy_as(config.copy_as())?;
+
     // upstream: main.c:760 validates destination directory access early,
     // returning FILE_SELECTION (3) for PermissionDenied instead of
     // PARTIAL_TRANSFER (23). Other errors (e.g. NotFound) proceed normally.
@@ -622,6 +641,7 @@ impl<'a> LocalCopyOptionsBuilder<'a> {
         options = self.apply_reference_directories(options, config);
         options = self.apply_iconv(options, config);
         options = Self::apply_cow_policy(options, config);
+        options = Self::apply_file_hooks(options, config);
         options = self.apply_filter_program(options);
         options = Self::apply_zero_copy_policy(options, config);
 
@@ -724,6 +744,7 @@ impl<'a> LocalCopyOptionsBuilder<'a> {
             .min_file_size(config.min_file_size())
             .max_file_size(config.max_file_size())
             .with_block_size_override(config.block_size_override())
+            .copy_buffer_size(config.copy_buffer_size())
             .remove_source_files(config.remove_source_files())
             .bandwidth_limit(
                 config
@@ -767,9 +788,8 @@ impl<'a> LocalCopyOptionsBuilder<'a> {
         mut options: LocalCopyOptions,
         config: &ClientConfig,
     ) -> LocalCopyOptions {
-        let copy_as_ids = config
-            .copy_as()
-            .and_then(|spec| ::metadata::parse_copy_as_spec(spec).ok());
+        // `run_client` refuses an unresolvable spec before building options.
+        let copy_as_ids = resolve_copy_as(config.copy_as()).ok().flatten();
 
         options = options
             .with_stop_at(config.stop_at())
@@ -791,6 +811,8 @@ impl<'a> LocalCopyOptionsBuilder<'a> {
             .times(config.preserve_times())
             .atimes(config.preserve_atimes())
             .crtimes(config.preserve_crtimes())
+            .fileflags(config.preserve_fileflags())
+            .with_force_change(config.force_change())
             .omit_dir_times(config.omit_dir_times())
             .omit_link_times(config.omit_link_times())
             .with_user_mapping(config.user_mapping().cloned())
@@ -821,6 +843,8 @@ impl<'a> LocalCopyOptionsBuilder<'a> {
             .itemize_active(config.itemize_changes())
             .checksum(config.checksum())
             .with_checksum_algorithm(config.checksum_signature_algorithm())
+            .with_checksum_cache_dir(config.checksum_cache_directory().map(Path::to_path_buf))
+            .with_checksum_xattr(config.checksum_xattr_name())
             .enable_xxh64_dedup(config.xxh64_dedup())
             .size_only(config.size_only())
             .ignore_times(config.ignore_times())
@@ -904,6 +928,10 @@ impl<'a> LocalCopyOptionsBuilder<'a> {
     fn apply_filter_program(self, options: LocalCopyOptions) -> LocalCopyOptions {
         options.with_filter_program(self.filter_program)
     }
+
+    fn apply_file_hooks(options: LocalCopyOptions, config: &ClientConfig) -> LocalCopyOptions {
+        options.with_file_hooks(config.file_hooks().clone())
+    }
 }
 
 /// Builds [`LocalCopyOptions`] reflecting the provided client configuration and optional filter
@@ -1119,6 +1147,77 @@ mod run_client_tests {
         assert!(summary.files_copied() >= 1);
     }
 
+    #[test]
+    fn run_client_file_hook_vetoes_file() {
+        use crate::client::LocalCopyFileHooks;
+
+        let tmp = tempdir().expect("tempdir");
+        let source_root = tmp.path().join("source");
+        let dest_root = tmp.path().join("dest");
+        fs::create_dir_all(&source_root).expect("create source root");
+        fs::write(source_root.join("keep.txt"), b"keep").expect("write keep");
+        fs::write(source_root.join("private.txt"), b"private").expect("write private");
+
+        let hooks = LocalCopyFileHooks::new()
+            .should_transfer(|file| !file.relative_path().ends_with("private.txt"));
+        let config = ClientConfig::builder()
+            .transfer_args([source_root.clone(), dest_root.clone()])
+            .recursive(true)
+            .file_hooks(hooks)
+            .build();
+
+        let summary = run_client(config).expect("copy succeeds");
+
+        assert!(dest_root.join("source").join("keep.txt").exists());
+        assert!(!dest_root.join("source").join("private.txt").exists());
+        assert_eq!(summary.files_copied(), 1);
+    }
+
+    #[test]
+    fn run_client_rejects_file_hooks_on_pushes() {
+        use std::ffi::OsString;
+
+        use crate::client::LocalCopyFileHooks;
+
+        let tmp = tempdir().expect("tempdir");
+        let source = tmp.path().join("source");
+        fs::create_dir_all(&source).expect("create source");
+
+        for dest in ["example.invalid:files/", "rsync://example.invalid/files/"] {
+            let hooks = LocalCopyFileHooks::new().should_transfer(|_| false);
+            let config = ClientConfig::builder()
+                .transfer_args([source.clone().into_os_string(), OsString::from(dest)])
+                .recursive(true)
+                .file_hooks(hooks)
+                .build();
+
+            let error = run_client(config).expect_err("a remote generator cannot run hooks");
+            assert_eq!(error.exit_code(), 1, "{dest}");
+            assert!(error.to_string().contains("file hooks"), "{error}");
+        }
+    }
+
+    #[test]
+    fn run_client_rejects_unresolvable_copy_as() {
+        let tmp = tempdir().expect("tempdir");
+        let source = tmp.path().join("source.txt");
+        let dest = tmp.path().join("dest.txt");
+        fs::write(&source, b"data").expect("write source");
+
+        let config = ClientConfig::builder()
+            .transfer_args([source, dest.clone()])
+            .copy_as(Some(":1000".into()))
+            .build();
+
+        let error = run_client(config).expect_err("an empty copy-as user is fatal");
+        assert_eq!(error.exit_code(), 1);
+        assert!(error.to_string().contains("invalid --copy-as"), "{error}");
+        assert!(
+            !dest.exists(),
+            "nothing may be written as the invoking user"
+        );
+    }
+
     #[test]
     fn run_client_filter_clear_resets_previous_rules() {
         let tmp = tempdir().expect("tempdir");
@@ -1463,7 +1562,7 @@ mod run_client_tests {
 mod local_copy_option_wiring_tests {
     use super::build_local_copy_options;
     use crate::client::config::{ClientConfig, TransferTimeout};
-    use std::ffi::OsString;
+    use std::ffi::{OsStr, OsString};
     use std::num::NonZeroU64;
     use std::path::{Path, PathBuf};
     use std::time::Duration;
@@ -1532,6 +1631,33 @@ mod local_copy_option_wiring_tests {
         assert!(!disabled_options.partial_enabled());
     }
 
+    #[test]
+    fn local_copy_options_honour_checksum_cache_directory() {
+        let config = ClientConfig::builder()
+            .transfer_args([OsString::from("src"), OsString::from("dst")])
+            .checksum(true)
+            .checksum_cache_directory(Some(PathBuf::from(".sums")))
+            .build();
+
+        let options = build_local_copy_options(&config, None);
+        assert_eq!(options.checksum_cache_dir(), Some(Path::new(".sums")));
+    }
+
+    #[test]
+    fn local_copy_options_honour_checksum_xattr_name() {
+        let config = ClientConfig::builder()
+            .transfer_args([OsString::from("src"), OsString::from("dst")])
+            .checksum(true)
+            .checksum_xattr_name(Some("user.rsync.checksum"))
+            .build();
+
+        let options = build_local_copy_options(&config, None);
+        assert_eq!(
+            options.checksum_xattr(),
+            Some(OsStr::new("user.rsync.checksum"))
+        );
+    }
+
     #[test]
     fn local_copy_options_honour_temp_directory_setting() {
         let config = ClientConfig::builder()
@@ -325,8 +325,11 @@ impl ExitCode {
     ///   `BrokenPipe`, `AddrInUse`, `AddrNotAvailable`, `NotConnected` - `SocketIo`
     /// - `TimedOut` - `Timeout`
     /// - `InvalidData` tagged [`protocol::ProtocolViolation`] - `Protocol`
-    /// - `UnexpectedEof`, other `InvalidData` - `StreamIo`
+    /// - `UnexpectedEof`, other `InvalidData` (including
+    ///   [`protocol::ProtocolDesync`]) - `StreamIo`
     /// - `Unsupported` - `Unsupported`
+    /// - `OutOfMemory` tagged [`protocol::MaxAllocExceeded`] or
+    ///   [`protocol::flist::FileListLimitExceeded`] - `Malloc`
     /// - `Interrupted` by signal - `Signal`
     /// - All other I/O errors - `FileIo`
     ///
@@ -350,6 +353,16 @@ impl ExitCode {
             return Self::Protocol;
         }
 
+        // upstream: errcode.h RERR_MALLOC=22 - util2.c:my_alloc() exits with
+        // it once a request reaches --max-alloc. A file list refused by the
+        // untrusted-daemon caps is the same protective abort.
+        if error.get_ref().is_some_and(|inner| {
+            inner.is::<protocol::MaxAllocExceeded>()
+                || inner.is::<protocol::flist::FileListLimitExceeded>()
+        }) {
+            return Self::Malloc;
+        }
+
         match error.kind() {
             ErrorKind::NotFound | ErrorKind::PermissionDenied | ErrorKind::AlreadyExists => {
                 Self::FileSelect
@@ -274,6 +274,35 @@ fn from_io_error_maps_tagged_protocol_violation_to_protocol() {
     assert_eq!(err.to_string(), "got transfer request in phase 2 [sender]");
 }
 
+#[test]
+fn from_io_error_maps_protocol_desync_to_stream_io() {
+    // upstream: io.c:read_a_msg() exits RERR_STREAMIO(12) for an unexpected
+    // tag or an invalid multi-message. The desync marker must not be mistaken
+    // for a ProtocolViolation.
+    let err = protocol::protocol_desync("invalid multi-message 9:3");
+    assert_eq!(ExitCode::from_io_error(&err), ExitCode::StreamIo);
+    assert_eq!(ExitCode::from_io_error(&err).as_i32(), 12);
+}
+
+#[test]
+fn from_io_error_maps_max_alloc_refusal_to_malloc() {
+    // upstream: util2.c:75-79 - my_alloc() reports `exceeded --max-alloc`
+    // and exits RERR_MALLOC(22). An untagged OutOfMemory stays FileIo.
+    let err = protocol::check_max_alloc(8192, 4096, "file list").expect_err("over the limit");
+    assert_eq!(ExitCode::from_io_error(&err), ExitCode::Malloc);
+    assert_eq!(ExitCode::from_io_error(&err).as_i32(), 22);
+}
+
+#[test]
+fn from_io_error_maps_file_list_limit_refusal_to_malloc() {
+    let limits = protocol::flist::FileListLimits {
+        max_entries: 1,
+        max_name_bytes: usize::MAX,
+    };
+    let err = limits.check(2, 0).expect_err("over the limit");
+    assert_eq!(ExitCode::from_io_error(&err), ExitCode::Malloc);
+}
+
 #[test]
 fn from_io_error_maps_signal_interruption() {
     use std::io::{Error, ErrorKind};
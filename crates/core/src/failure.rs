@@ -0,0 +1,257 @@
+//! Typed failure causes for client and daemon errors.
+//!
+//! [`ClientError`](crate::client::ClientError) and the daemon's error type
+//! carry an exit code and a rendered diagnostic. That is what the CLI needs,
+//! but an embedding program that wants to retry on a timeout or prompt for a
+//! new password after a rejected login would otherwise have to match on the
+//! message text. The structs here are attached as the error's
+//! [`source()`](std::error::Error::source) when the failure falls into one of
+//! a few well-known kinds, so callers can branch with `downcast_ref`:
+//!
+//! ```
+//! use core::failure::AuthError;
+//!
+//! fn needs_new_password(error: &(dyn std::error::Error + 'static)) -> bool {
+//!     error
+//!         .source()
+//!         .is_some_and(|cause| cause.downcast_ref::<AuthError>().is_some())
+//! }
+//! # let _ = needs_new_password;
+//! ```
+//!
+//! The classification happens where internal failures are turned into the
+//! public error types; the diagnostic text and exit code are unchanged.
+
+use std::path::{Path, PathBuf};
+use std::time::Duration;
+
+use thiserror::Error;
+
+use crate::exit_code::ExitCode;
+
+/// The peer sent something the protocol does not allow, or the handshake
+/// could not be completed.
+///
+/// Covers `RERR_PROTOCOL` (2) and `RERR_STARTCLIENT` (5) failures.
+#[derive(Clone, Debug, Eq, PartialEq, Error)]
+#[error("{detail}")]
+pub struct ProtocolError {
+    detail: String,
+}
+
+impl ProtocolError {
+    /// Creates a protocol failure with the given description.
+    #[must_use]
+    pub fn new(detail: impl Into<String>) -> Self {
+        Self {
+            detail: detail.into(),
+        }
+    }
+
+    /// Returns the description of what went wrong.
+    #[must_use]
+    pub fn detail(&self) -> &str {
+        &self.detail
+    }
+}
+
+/// The daemon rejected the supplied credentials or required ones that were
+/// not provided.
+#[derive(Clone, Debug, Eq, PartialEq, Error)]
+#[error("{detail}")]
+pub struct AuthError {
+    module: Option<String>,
+    detail: String,
+}
+
+impl AuthError {
+    /// Creates an authentication failure, optionally naming the module.
+    #[must_use]
+    pub fn new(module: Option<String>, detail: impl Into<String>) -> Self {
+        Self {
+            module,
+            detail: detail.into(),
+        }
+    }
+
+    /// Returns the module the client tried to access, when the daemon named it.
+    #[must_use]
+    pub fn module(&self) -> Option<&str> {
+        self.module.as_deref()
+    }
+
+    /// Returns the description of what went wrong.
+    #[must_use]
+    pub fn detail(&self) -> &str {
+        &self.detail
+    }
+}
+
+/// Transferring or accessing a specific file failed.
+#[derive(Clone, Debug, Eq, PartialEq, Error)]
+#[error("{}: {detail}", .path.display())]
+pub struct TransferError {
+    path: PathBuf,
+    detail: String,
+}
+
+impl TransferError {
+    /// Creates a transfer failure for `path`.
+    #[must_use]
+    pub fn new(path: impl Into<PathBuf>, detail: impl Into<String>) -> Self {
+        Self {
+            path: path.into(),
+            detail: detail.into(),
+        }
+    }
+
+    /// Returns the file the failure relates to.
+    #[must_use]
+    pub fn path(&self) -> &Path {
+        &self.path
+    }
+
+    /// Returns the description of what went wrong.
+    #[must_use]
+    pub fn detail(&self) -> &str {
+        &self.detail
+    }
+}
+
+/// An I/O or connect timeout expired.
+///
+/// Covers `RERR_TIMEOUT` (30, `--timeout`) and `RERR_CONTIMEOUT` (35,
+/// `--contimeout`).
+#[derive(Clone, Debug, Eq, PartialEq, Error)]
+#[error("{detail}")]
+pub struct TimeoutError {
+    timeout: Option<Duration>,
+    connect: bool,
+    detail: String,
+}
+
+impl TimeoutError {
+    /// Creates a timeout failure.
+    ///
+    /// `connect` distinguishes a `--contimeout` expiry from an idle-I/O one.
+    #[must_use]
+    pub fn new(timeout: Option<Duration>, connect: bool, detail: impl Into<String>) -> Self {
+        Self {
+            timeout,
+            connect,
+            detail: detail.into(),
+        }
+    }
+
+    /// Returns the limit that expired, when known.
+    #[must_use]
+    pub const fn timeout(&self) -> Option<Duration> {
+        self.timeout
+    }
+
+    /// Reports whether the connection could not be established in time.
+    #[must_use]
+    pub const fn is_connect_timeout(&self) -> bool {
+        self.connect
+    }
+
+    /// Returns the description of what went wrong.
+    #[must_use]
+    pub fn detail(&self) -> &str {
+        &self.detail
+    }
+}
+
+/// One of the typed failure causes.
+///
+/// Returned by `ClientError::failure()` and `DaemonError::failure()` for
+/// exhaustive-style matching; [`Self::as_error`] yields the inner struct for
+/// `downcast_ref`.
+#[derive(Clone, Debug, Eq, PartialEq, Error)]
+#[non_exhaustive]
+pub enum Failure {
+    /// See [`ProtocolError`].
+    #[error(transparent)]
+    Protocol(#[from] ProtocolError),
+    /// See [`AuthError`].
+    #[error(transparent)]
+    Auth(#[from] AuthError),
+    /// See [`TransferError`].
+    #[error(transparent)]
+    Transfer(#[from] TransferError),
+    /// See [`TimeoutError`].
+    #[error(transparent)]
+    Timeout(#[from] TimeoutError),
+}
+
+impl Failure {
+    /// Returns the inner typed error as a trait object.
+    #[must_use]
+    pub fn as_error(&self) -> &(dyn std::error::Error + Send + Sync + 'static) {
+        match self {
+            Self::Protocol(error) => error,
+            Self::Auth(error) => error,
+            Self::Transfer(error) => error,
+            Self::Timeout(error) => error,
+        }
+    }
+
+    /// Derives the failure kind implied by an exit code alone.
+    ///
+    /// Only codes that unambiguously name a kind are classified; everything
+    /// else needs an explicit cause from the construction site.
+    #[must_use]
+    pub fn from_exit_code(code: ExitCode, detail: &str) -> Option<Self> {
+        match code {
+            ExitCode::Protocol | ExitCode::StartClient => Some(ProtocolError::new(detail).into()),
+            ExitCode::Timeout => Some(TimeoutError::new(None, false, detail).into()),
+            ExitCode::ConnectionTimeout => Some(TimeoutError::new(None, true, detail).into()),
+            _ => None,
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn exit_codes_classify_protocol_and_timeout() {
+        assert!(matches!(
+            Failure::from_exit_code(ExitCode::Protocol, "bad"),
+            Some(Failure::Protocol(_))
+        ));
+        assert!(matches!(
+            Failure::from_exit_code(ExitCode::StartClient, "bad"),
+            Some(Failure::Protocol(_))
+        ));
+        let Some(Failure::Timeout(timeout)) =
+            Failure::from_exit_code(ExitCode::ConnectionTimeout, "slow")
+        else {
+            panic!("expected timeout");
+        };
+        assert!(timeout.is_connect_timeout());
+        assert_eq!(
+            Failure::from_exit_code(ExitCode::PartialTransfer, "x"),
+            None
+        );
+    }
+
+    #[test]
+    fn as_error_downcasts_to_inner_type() {
+        let failure = Failure::from(AuthError::new(Some("secure".into()), "denied"));
+        let auth = failure
+            .as_error()
+            .downcast_ref::<AuthError>()
+            .expect("auth error");
+        assert_eq!(auth.module(), Some("secure"));
+        assert!(failure.as_error().downcast_ref::<ProtocolError>().is_none());
+    }
+
+    #[test]
+    fn transfer_error_displays_path() {
+        let error = TransferError::new("dir/file.txt", "Permission denied (13)");
+        assert_eq!(error.to_string(), "dir/file.txt: Permission denied (13)");
+        assert_eq!(error.path(), Path::new("dir/file.txt"));
+    }
+}
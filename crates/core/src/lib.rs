@@ -11,6 +11,9 @@ pub mod auth;
 pub mod bandwidth;
 /// Centralized exit code definitions matching upstream rsync's `errcode.h`.
 pub mod exit_code;
+/// Typed failure causes (protocol, auth, transfer, timeout) attached to
+/// client and daemon errors.
+pub mod failure;
 /// Signal handling for graceful shutdown and cleanup.
 pub mod signal;
 /// Timeout configuration and tracking for rsync connections and I/O operations.
@@ -583,6 +583,9 @@ pub(crate) fn compiled_build_features() -> Vec<Cow<'static, str>> {
     if cfg!(feature = "embedded-ssh") {
         features.push(Cow::Borrowed("embedded-ssh"));
     }
+    if cfg!(feature = "tls") {
+        features.push(Cow::Borrowed("tls"));
+    }
 
     features
 }
@@ -40,11 +40,18 @@
 use std::io;
 use std::net::{SocketAddr, TcpStream};
 use std::sync::Arc;
-use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::atomic::{AtomicBool, AtomicUsize, Ordering};
+use std::time::Duration;
 
 use tokio::net::TcpListener as TokioTcpListener;
 use tokio::runtime::Builder;
 
+use crate::shutdown::ShutdownHandle;
+
+/// Interval at which the accept loop re-checks its stop conditions and a
+/// draining loop re-checks for in-flight workers.
+const STOP_POLL_INTERVAL: Duration = Duration::from_millis(250);
+
 /// Sync per-connection worker invoked on a dedicated OS thread.
 ///
 /// The worker owns the converted blocking `TcpStream` for the lifetime of
@@ -93,6 +100,11 @@ pub const DEFAULT_MAX_INFLIGHT_WORKERS: usize = 512;
 /// drains the loop and returns `Ok(())`. The listener does not install
 /// signal handlers; integration sites wire `SIGTERM`/`Ctrl-C` to this flag.
 ///
+/// `drain` is the embedder's [`ShutdownHandle`], checked alongside `shutdown`.
+/// Once [`ShutdownHandle::shutdown`] is called the listening socket is closed,
+/// so new clients are refused, and the call returns only after every
+/// in-flight worker has finished.
+///
 /// # Errors
 ///
 /// Returns the underlying `io::Error` if the runtime cannot be built or the
@@ -103,6 +115,7 @@ pub fn run_hybrid_listener(
     worker_threads: usize,
     max_inflight: usize,
     shutdown: Arc<AtomicBool>,
+    drain: Option<ShutdownHandle>,
     worker: SyncWorker,
 ) -> io::Result<()> {
     let worker_threads = worker_threads.max(1);
@@ -113,28 +126,50 @@ pub fn run_hybrid_listener(
         .thread_name("oc-rsyncd-async")
         .build()?;
 
-    runtime.block_on(async move { accept_loop(bind_addr, max_inflight, shutdown, worker).await })
+    runtime.block_on(
+        async move { accept_loop(bind_addr, max_inflight, shutdown, drain, worker).await },
+    )
+}
+
+/// Decrements the in-flight connection count when a dispatch task ends,
+/// however it ends.
+struct InflightGuard(Arc<AtomicUsize>);
+
+impl Drop for InflightGuard {
+    fn drop(&mut self) {
+        self.0.fetch_sub(1, Ordering::AcqRel);
+    }
 }
 
 async fn accept_loop(
     bind_addr: SocketAddr,
     max_inflight: usize,
     shutdown: Arc<AtomicBool>,
+    drain: Option<ShutdownHandle>,
     worker: SyncWorker,
 ) -> io::Result<()> {
     let listener = TokioTcpListener::bind(bind_addr).await?;
     let permits = Arc::new(tokio::sync::Semaphore::new(max_inflight.max(1)));
+    let inflight = Arc::new(AtomicUsize::new(0));
 
     loop {
         if shutdown.load(Ordering::Acquire) {
             return Ok(());
         }
+        if drain.as_ref().is_some_and(ShutdownHandle::is_requested) {
+            // upstream: main.c - the SIGUSR1 graceful exit this mirrors stops
+            // listening first, then waits for running sessions.
+            drop(listener);
+            while inflight.load(Ordering::Acquire) > 0 {
+                tokio::time::sleep(STOP_POLL_INTERVAL).await;
+            }
+            return Ok(());
+        }
 
         // `accept().await` parks on the kernel event source; poll the
-        // shutdown flag at a coarse interval so a stalled accept does not
+        // stop conditions at a coarse interval so a stalled accept does not
         // delay graceful shutdown indefinitely.
-        let accept =
-            tokio::time::timeout(std::time::Duration::from_millis(250), listener.accept()).await;
+        let accept = tokio::time::timeout(STOP_POLL_INTERVAL, listener.accept()).await;
 
         let (stream, peer_addr) = match accept {
             Ok(Ok(pair)) => pair,
@@ -145,7 +180,10 @@ async fn accept_loop(
 
         let worker = Arc::clone(&worker);
         let permits = Arc::clone(&permits);
+        inflight.fetch_add(1, Ordering::AcqRel);
+        let inflight_guard = InflightGuard(Arc::clone(&inflight));
         tokio::spawn(async move {
+            let _inflight = inflight_guard;
             // Convert the tokio stream back to a blocking std stream so the
             // existing sync worker can use `read`/`write` directly. The
             // `into_std()` + `set_nonblocking(false)` pair is the canonical
@@ -273,6 +311,7 @@ mod tests {
                     local_addr,
                     DEFAULT_MAX_INFLIGHT_WORKERS,
                     shutdown_for_loop,
+                    None,
                     worker_for_loop,
                 )
                 .await
@@ -328,7 +367,7 @@ mod tests {
         let worker_for_loop = Arc::clone(&worker);
         let server = thread::spawn(move || {
             runtime.block_on(async move {
-                accept_loop(local_addr, 2, shutdown_for_loop, worker_for_loop).await
+                accept_loop(local_addr, 2, shutdown_for_loop, None, worker_for_loop).await
             })
         });
 
@@ -377,6 +416,7 @@ mod tests {
                 1,
                 DEFAULT_MAX_INFLIGHT_WORKERS,
                 shutdown_for_thread,
+                None,
                 worker_for_thread,
             )
         });
@@ -387,4 +427,50 @@ mod tests {
         let result = handle.join().expect("listener thread");
         assert!(result.is_ok(), "listener error: {result:?}");
     }
+
+    #[test]
+    fn shutdown_handle_closes_listener_and_drains_workers() {
+        let bind_addr = reserve_port();
+        let finished = Arc::new(AtomicUsize::new(0));
+        let drain = ShutdownHandle::new();
+
+        let finished_w = Arc::clone(&finished);
+        let worker: SyncWorker = Arc::new(move |_, _| {
+            // Still running when the shutdown is requested.
+            thread::sleep(Duration::from_millis(600));
+            finished_w.fetch_add(1, Ordering::SeqCst);
+            Ok(())
+        });
+
+        let drain_for_thread = drain.clone();
+        let handle = thread::spawn(move || {
+            run_hybrid_listener(
+                bind_addr,
+                1,
+                DEFAULT_MAX_INFLIGHT_WORKERS,
+                Arc::new(AtomicBool::new(false)),
+                Some(drain_for_thread),
+                worker,
+            )
+        });
+
+        thread::sleep(Duration::from_millis(150));
+        let _client = StdTcpStream::connect(bind_addr).expect("connect");
+        thread::sleep(Duration::from_millis(100));
+
+        // Only requests the drain: marking the daemon stopped is the
+        // embedding entry point's job, so this wait always times out.
+        let _ = drain.shutdown(Duration::ZERO);
+        let result = handle.join().expect("listener thread");
+        assert!(result.is_ok(), "listener error: {result:?}");
+        assert_eq!(
+            finished.load(Ordering::SeqCst),
+            1,
+            "the in-flight worker must finish before the listener returns"
+        );
+        assert!(
+            StdTcpStream::connect(bind_addr).is_err(),
+            "the listening socket must be closed after the drain"
+        );
+    }
 }
@@ -10,10 +10,15 @@
 
 use std::ffi::OsString;
 use std::net::TcpListener;
+use std::sync::Arc;
 
 use core::branding::Brand;
 use platform::signal::SignalFlags;
 
+use crate::logger::DaemonLogger;
+use crate::metrics::DaemonMetrics;
+use crate::shutdown::ShutdownHandle;
+
 /// Configuration describing the requested daemon operation.
 #[derive(Debug)]
 pub struct DaemonConfig {
@@ -32,6 +37,14 @@ pub struct DaemonConfig {
     /// binding a new socket, eliminating the TOCTOU race between port allocation
     /// and daemon bind in tests.
     pre_bound_listener: Option<TcpListener>,
+    /// Structured event sink installed by the embedder.
+    ///
+    /// When `None`, the daemon only writes its upstream-style text log.
+    logger: Option<Arc<dyn DaemonLogger>>,
+    /// Metrics sink installed by the embedder.
+    metrics: Option<Arc<dyn DaemonMetrics>>,
+    /// Embedder-side handle for a drain-and-exit shutdown.
+    shutdown_handle: Option<ShutdownHandle>,
 }
 
 impl Clone for DaemonConfig {
@@ -42,6 +55,9 @@ impl Clone for DaemonConfig {
             load_default_paths: self.load_default_paths,
             signal_flags: self.signal_flags.clone(),
             pre_bound_listener: None,
+            logger: self.logger.clone(),
+            metrics: self.metrics.clone(),
+            shutdown_handle: self.shutdown_handle.clone(),
         }
     }
 }
@@ -100,6 +116,26 @@ impl DaemonConfig {
         self.pre_bound_listener.take()
     }
 
+    /// Returns the installed structured event sink, if any.
+    #[must_use]
+    pub fn logger(&self) -> Option<Arc<dyn DaemonLogger>> {
+        self.logger.clone()
+    }
+
+    /// Returns the installed metrics sink, if any.
+    #[must_use]
+    pub fn metrics(&self) -> Option<Arc<dyn DaemonMetrics>> {
+        self.metrics.clone()
+    }
+
+    /// Returns the installed shutdown handle, if present.
+    ///
+    /// The accept loop polls it alongside the signal flags and marks it
+    /// stopped once every session has drained.
+    pub fn take_shutdown_handle(&mut self) -> Option<ShutdownHandle> {
+        self.shutdown_handle.take()
+    }
+
     /// Reports whether any daemon-specific arguments were provided.
     #[must_use]
     pub const fn has_runtime_request(&self) -> bool {
@@ -115,6 +151,9 @@ pub struct DaemonConfigBuilder {
     load_default_paths: bool,
     signal_flags: Option<SignalFlags>,
     pre_bound_listener: Option<TcpListener>,
+    logger: Option<Arc<dyn DaemonLogger>>,
+    metrics: Option<Arc<dyn DaemonMetrics>>,
+    shutdown_handle: Option<ShutdownHandle>,
 }
 
 impl Clone for DaemonConfigBuilder {
@@ -125,6 +164,9 @@ impl Clone for DaemonConfigBuilder {
             load_default_paths: self.load_default_paths,
             signal_flags: self.signal_flags.clone(),
             pre_bound_listener: None,
+            logger: self.logger.clone(),
+            metrics: self.metrics.clone(),
+            shutdown_handle: self.shutdown_handle.clone(),
         }
     }
 }
@@ -147,6 +189,9 @@ impl Default for DaemonConfigBuilder {
             load_default_paths: true,
             signal_flags: None,
             pre_bound_listener: None,
+            logger: None,
+            metrics: None,
+            shutdown_handle: None,
         }
     }
 }
@@ -159,6 +204,9 @@ impl From<DaemonConfig> for DaemonConfigBuilder {
             load_default_paths: config.load_default_paths,
             signal_flags: config.signal_flags,
             pre_bound_listener: config.pre_bound_listener,
+            logger: config.logger,
+            metrics: config.metrics,
+            shutdown_handle: config.shutdown_handle,
         }
     }
 }
@@ -211,6 +259,40 @@ impl DaemonConfigBuilder {
         self
     }
 
+    /// Installs a structured event sink for connection lifecycle events.
+    ///
+    /// The daemon keeps writing its text log (`--log-file`, `log file`, or
+    /// syslog) and additionally hands a [`crate::LogRecord`] to `logger` for
+    /// every connect, transfer, and disconnect. Use [`crate::JsonLogger`] for
+    /// JSON-lines output or supply a custom [`DaemonLogger`].
+    #[must_use]
+    pub fn logger(mut self, logger: Arc<dyn DaemonLogger>) -> Self {
+        self.logger = Some(logger);
+        self
+    }
+
+    /// Installs a metrics sink for connection and transfer counters.
+    ///
+    /// The daemon calls the [`DaemonMetrics`] hooks at fixed lifecycle points
+    /// without depending on any metrics library; the embedder maps them onto
+    /// Prometheus or a similar backend.
+    #[must_use]
+    pub fn metrics(mut self, metrics: Arc<dyn DaemonMetrics>) -> Self {
+        self.metrics = Some(metrics);
+        self
+    }
+
+    /// Installs a handle the embedder can use to shut the daemon down.
+    ///
+    /// [`ShutdownHandle::shutdown`] stops the accept loop, lets in-flight
+    /// sessions finish, and waits for them up to a timeout, mirroring the
+    /// SIGUSR1 graceful exit without sending a signal.
+    #[must_use]
+    pub fn shutdown_handle(mut self, handle: ShutdownHandle) -> Self {
+        self.shutdown_handle = Some(handle);
+        self
+    }
+
     /// Finalises the builder and constructs the [`DaemonConfig`].
     #[must_use]
     pub fn build(self) -> DaemonConfig {
@@ -220,6 +302,9 @@ impl DaemonConfigBuilder {
             load_default_paths: self.load_default_paths,
             signal_flags: self.signal_flags,
             pre_bound_listener: self.pre_bound_listener,
+            logger: self.logger,
+            metrics: self.metrics,
+            shutdown_handle: self.shutdown_handle,
         }
     }
 }
@@ -302,6 +387,50 @@ mod tests {
             assert!(debug.contains("brand"));
         }
 
+        #[test]
+        fn builder_default_has_no_logger() {
+            let config = DaemonConfig::builder().build();
+            assert!(config.logger().is_none());
+        }
+
+        #[test]
+        fn builder_with_logger_survives_clone() {
+            let logger: Arc<dyn DaemonLogger> = Arc::new(crate::JsonLogger::new(Vec::new()));
+            let config = DaemonConfig::builder().logger(Arc::clone(&logger)).build();
+            let cloned = config.clone();
+
+            assert!(config.logger().is_some());
+            assert!(cloned.logger().is_some());
+            assert_eq!(Arc::strong_count(&logger), 3);
+        }
+
+        #[test]
+        fn builder_with_metrics_installs_sink() {
+            #[derive(Debug)]
+            struct NoopMetrics;
+            impl DaemonMetrics for NoopMetrics {}
+
+            assert!(DaemonConfig::builder().build().metrics().is_none());
+            let config = DaemonConfig::builder()
+                .metrics(Arc::new(NoopMetrics))
+                .build();
+            assert!(config.metrics().is_some());
+            assert!(config.clone().metrics().is_some());
+        }
+
+        #[test]
+        fn builder_with_shutdown_handle_shares_state() {
+            let handle = ShutdownHandle::new();
+            let mut config = DaemonConfig::builder()
+                .shutdown_handle(handle.clone())
+                .build();
+            let taken = config.take_shutdown_handle().expect("handle installed");
+            assert!(config.take_shutdown_handle().is_none());
+            let _stop = taken.stop_on_drop();
+            drop(_stop);
+            assert!(handle.is_stopped());
+        }
+
         #[test]
         fn builder_default_has_no_signal_flags() {
             let mut config = DaemonConfig::builder().build();
@@ -16,7 +16,9 @@ use std::fmt;
 use std::fs;
 use std::fs::OpenOptions;
 use std::io::{self, BufRead, BufReader, Read, Write};
-use std::net::{IpAddr, Ipv4Addr, Ipv6Addr, SocketAddr, TcpListener, TcpStream};
+use std::net::{
+    IpAddr, Ipv4Addr, Ipv6Addr, SocketAddr, SocketAddrV6, TcpListener, TcpStream, ToSocketAddrs,
+};
 use std::num::{NonZeroU32, NonZeroU64, NonZeroUsize};
 use std::path::{Path, PathBuf};
 use std::sync::{
@@ -24,7 +26,7 @@ use std::sync::{
     atomic::{AtomicBool, AtomicUsize, Ordering},
 };
 use std::thread;
-use std::time::{Duration, SystemTime, UNIX_EPOCH};
+use std::time::{Duration, Instant, SystemTime, UNIX_EPOCH};
 #[cfg(feature = "tracing")]
 use tracing::instrument;
 
@@ -34,7 +36,9 @@ use base64::Engine as _;
 use base64::engine::general_purpose::STANDARD_NO_PAD;
 
 #[cfg(unix)]
-use std::os::unix::fs::PermissionsExt;
+use std::os::unix::fs::{FileTypeExt, PermissionsExt};
+#[cfg(unix)]
+use std::os::unix::net::{UnixListener, UnixStream};
 
 use checksums::strong::{Md4, Md5};
 use clap::{Arg, ArgAction, Command, builder::OsStringValueParser};
@@ -50,14 +54,20 @@ use core::{
     message::{Message, Role},
     rsync_error, rsync_info, rsync_warning,
     server::{
-        HandshakeResult, ReferenceDirectory, ReferenceDirectoryKind, ServerConfig, ServerResult,
-        ServerRole, run_server_with_handshake,
+        HandshakeResult, ReferenceDirectory, ReferenceDirectoryKind, RemoteExitError,
+        ResumeSkipList, ServerConfig, ServerResult, ServerRole, ServerStats,
+        TransferProgressCallback, TransferProgressEvent, io_error_flags, run_server_with_handshake,
     },
 };
 use logging_sink::MessageSink;
 use protocol::{
     LEGACY_DAEMON_PREFIX_LEN, LegacyDaemonMessage, MessageCode, MessageFrame, ProtocolVersion,
-    filters::FilterRuleWireFormat, format_legacy_daemon_message, iconv::FilenameConverter,
+    daemon_resume::{
+        RESUME_ACCEPTED_LINE, RESUME_MAX_ENTRIES, greeting_offers_resume, read_resume_manifest,
+    },
+    filters::FilterRuleWireFormat,
+    format_legacy_daemon_message,
+    iconv::FilenameConverter,
     missing_greeting_token, parse_legacy_daemon_message,
 };
 
@@ -66,6 +76,9 @@ use crate::{
     connection::{ConnectionState, InvalidTransition},
     daemon_stream::DaemonStream,
     error::DaemonError,
+    logger::{LogLevel, LogRecord, SharedDaemonLogger},
+    metrics::{DaemonMetrics, SessionError, SharedDaemonMetrics, TransferMetrics},
+    shutdown::ShutdownHandle,
     systemd,
 };
 
@@ -273,6 +286,10 @@ include!("daemon/sections/group_expansion.rs");
 pub fn run_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
     let external_signal_flags = config.take_signal_flags();
     let pre_bound_listener = config.take_pre_bound_listener();
+    let shutdown_handle = config.take_shutdown_handle();
+    // Release `ShutdownHandle::shutdown` waiters however the daemon exits.
+    let _stop_on_drop = shutdown_handle.as_ref().map(ShutdownHandle::stop_on_drop);
+    let observers = SessionObservers::from_config(&config);
     let options = RuntimeOptions::parse_with_brand(
         config.arguments(),
         config.brand(),
@@ -285,10 +302,16 @@ pub fn run_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
     // When stdin is a socket, serve a single session over stdio (inetd mode)
     // instead of binding a TCP listener.
     if is_stdin_socket() {
-        return serve_inetd_session(options);
+        return serve_inetd_session(options, observers);
     }
 
-    serve_connections(options, external_signal_flags, pre_bound_listener)
+    serve_connections(
+        options,
+        external_signal_flags,
+        pre_bound_listener,
+        observers,
+        shutdown_handle,
+    )
 }
 
 /// Seeds the thread-local [`logging::VerbosityConfig`] from the daemon's
@@ -328,6 +351,7 @@ pub(crate) fn apply_verbosity(level: u8) {
 /// encounters an I/O error.
 pub fn run_daemon_stdio(config: DaemonConfig) -> Result<(), DaemonError> {
     let brand = config.brand();
+    let observers = SessionObservers::from_config(&config);
     // upstream: clientserver.c:1275-1283 `load_config()` - when invoked as
     // `--server --daemon` (rsh-spawned, am_daemon < 0) without an explicit
     // `--config`, upstream picks `RSYNCD_USERCONF` (`./rsyncd.conf` - relative
@@ -424,6 +448,7 @@ pub fn run_daemon_stdio(config: DaemonConfig) -> Result<(), DaemonError> {
     if let Some(log) = log_sink.as_ref() {
         log_connection(log, peer_host.as_deref(), peer_addr);
     }
+    log_connect_event(&observers, peer_host.as_deref(), peer_addr);
 
     handle_legacy_session(
         stream,
@@ -434,6 +459,7 @@ pub fn run_daemon_stdio(config: DaemonConfig) -> Result<(), DaemonError> {
             daemon_limit: bandwidth_limit,
             daemon_burst: bandwidth_burst,
             log_sink,
+            observers,
             peer_host,
             reverse_lookup,
         },
@@ -490,6 +516,10 @@ pub fn run_daemon_stdio(config: DaemonConfig) -> Result<(), DaemonError> {
 pub fn run_async_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
     let external_signal_flags = config.take_signal_flags();
     let _ = config.take_pre_bound_listener();
+    let shutdown_handle = config.take_shutdown_handle();
+    // Release `ShutdownHandle::shutdown` waiters however the daemon exits.
+    let _stop_on_drop = shutdown_handle.as_ref().map(ShutdownHandle::stop_on_drop);
+    let observers = SessionObservers::from_config(&config);
     let brand = config.brand();
     let options =
         RuntimeOptions::parse_with_brand(config.arguments(), brand, config.load_default_paths())?;
@@ -497,9 +527,11 @@ pub fn run_async_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
     apply_verbosity(options.verbosity());
 
     let max_connections = options.max_connections.map(NonZeroUsize::get);
+    let max_connection_rate = options.max_connection_rate();
     let socket_options_str = options.socket_options().map(str::to_string);
     let RuntimeOptions {
         bind_address,
+        bind_scope_id,
         port,
         modules,
         motd_lines,
@@ -512,6 +544,9 @@ pub fn run_async_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
         daemon_uid,
         daemon_gid,
         daemon_chroot,
+        tls_cert_file,
+        tls_key_file,
+        unix_socket,
         ..
     } = options;
 
@@ -522,6 +557,31 @@ pub fn run_async_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
         return Err(async_privileged_module_error());
     }
 
+    // Likewise the async accept path never terminates TLS; serving plaintext
+    // to a deployment that configured a certificate would be a downgrade.
+    if tls_cert_file.is_some() || tls_key_file.is_some() {
+        return Err(config_error(
+            "async-daemon does not support 'tls cert file'; use the sync daemon".to_owned(),
+        ));
+    }
+
+    // The async listener only binds TCP; falling back to every interface when
+    // the operator asked for a local socket would widen exposure.
+    if let Some(path) = unix_socket {
+        return Err(config_error(format!(
+            "async-daemon does not support --address unix:{}; use the sync daemon",
+            path.display()
+        )));
+    }
+
+    // Nor does it run the per-address rate limiter; accepting a flood the
+    // operator asked to throttle would defeat the directive.
+    if max_connection_rate.is_some() {
+        return Err(config_error(
+            "async-daemon does not support 'max connection rate'; use the sync daemon".to_owned(),
+        ));
+    }
+
     let log_sink = if let Some(path) = log_file {
         Some(open_log_sink(&path, brand)?)
     } else {
@@ -583,6 +643,7 @@ pub fn run_async_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
         modules,
         Arc::new(motd_lines),
         log_sink,
+        observers,
         client_socket_options,
         bandwidth_limit,
         bandwidth_burst,
@@ -590,7 +651,7 @@ pub fn run_async_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
         proxy_protocol,
     );
 
-    let bind_addr = std::net::SocketAddr::new(bind_address, port);
+    let bind_addr = listen_socket_addr(bind_address, port, bind_scope_id);
     let worker_threads = std::thread::available_parallelism()
         .map(NonZeroUsize::get)
         .unwrap_or(1)
@@ -645,6 +706,7 @@ pub fn run_async_daemon(mut config: DaemonConfig) -> Result<(), DaemonError> {
         worker_threads,
         max_inflight,
         shutdown,
+        shutdown_handle,
         worker,
     )
     .map_err(|error| {
@@ -744,6 +806,8 @@ include!("daemon/sections/daemonize.rs");
 
 include!("daemon/sections/server_runtime.rs");
 
+include!("daemon/sections/observers.rs");
+
 include!("daemon/sections/session_runtime.rs");
 
 include!("daemon/sections/greeting.rs");
@@ -17,6 +17,7 @@ pub(crate) fn help_text(brand: Brand) -> String {
             "  --help        Show this help message and exit.\n",
             "  --version     Output version information and exit.\n",
             "  --bind ADDR         Bind to the supplied IPv4/IPv6 address (default 0.0.0.0).\n",
+            "  --address unix:PATH Listen on a Unix domain socket at PATH instead of TCP.\n",
             "  --ipv4             Restrict the listener to IPv4 sockets.\n",
             "  --ipv6             Restrict the listener to IPv6 sockets (defaults to :: when no bind address is provided).\n",
             "  --port PORT         Listen on the supplied TCP port (default 873).\n",
@@ -25,6 +26,7 @@ pub(crate) fn help_text(brand: Brand) -> String {
             "  --detach            Fork and run in the background (default on Unix).\n",
             "  --no-detach         Stay in the foreground; do not fork.\n",
             "  --config FILE      Load module definitions from FILE (packages install {default_config}).\n",
+            "  --dparam KEY=VALUE Override a global config parameter (repeatable; alias -M).\n",
             "  --module SPEC      Register an in-memory module (NAME=PATH[,COMMENT]).\n",
             "  --motd-file FILE   Append MOTD lines from FILE before module listings.\n",
             "  --motd-line TEXT   Append TEXT as an additional MOTD line.\n",
@@ -33,6 +35,7 @@ pub(crate) fn help_text(brand: Brand) -> String {
             "  --bwlimit=RATE[:BURST]  Limit per-connection bandwidth in KiB/s.\n",
             "                          Optional :BURST caps the token bucket; 0 = unlimited.\n",
             "  --no-bwlimit       Remove any per-connection bandwidth limit configured so far.\n",
+            "  --sockopts=OPTIONS Apply socket OPTIONS to the listener and accepted connections.\n",
             "\n",
             "The listener accepts legacy @RSYNCD: connections sequentially, reports the\n",
             "negotiated protocol as 32, lists configured modules for #list requests, and\n",
@@ -1,3 +1,4 @@
+use std::ffi::OsString;
 use std::num::{NonZeroU32, NonZeroU64};
 use std::path::{Path, PathBuf};
 
@@ -145,6 +146,17 @@ pub(crate) struct ModuleDefinition {
     ///
     /// Upstream: `loadparm.c` - `charset` parameter.
     pub(crate) charset: Option<String>,
+    /// `--usermap`-style rules that rewrite the owner of served files.
+    ///
+    /// Applied by the daemon's sender as it builds the file list, so a
+    /// client downloading from the module sees the remapped owner id and
+    /// name. Numeric rules still apply under `numeric ids`; name rules do
+    /// not, since no names are sent.
+    pub(crate) uid_map: Option<String>,
+    /// `--groupmap`-style rules that rewrite the group of served files.
+    ///
+    /// Group counterpart of [`Self::uid_map`].
+    pub(crate) gid_map: Option<String>,
     /// When true, DNS forward lookup verification is performed on connecting hosts.
     ///
     /// Upstream: `loadparm.c` - `forward lookup` parameter, default true.
@@ -211,6 +223,15 @@ pub(crate) struct ModuleDefinition {
     /// loadparm.c syslog_facility (P_ENUM, P_LOCAL, default LOG_DAEMON);
     /// consumed per-module at log.c:143 `openlog(..., lp_syslog_facility(module_id))`.
     pub(crate) syslog_facility: Option<String>,
+    /// File name served by a module whose configured `path` names a regular
+    /// file rather than a directory.
+    ///
+    /// Never read from the config: the daemon sets it per connection once the
+    /// path has been resolved, re-rooting `path` at the file's parent so the
+    /// chroot and sandbox still see a directory. Such a module serves exactly
+    /// this entry and refuses pushes. Upstream rsync requires a directory
+    /// module (clientserver.c:rsync_module() `change_dir(module_chdir)`).
+    pub(crate) single_file: Option<OsString>,
 }
 
 impl ModuleDefinition {
@@ -613,6 +634,16 @@ impl ModuleDefinition {
         self.charset.as_deref()
     }
 
+    /// Returns the `uid map` rules applied to served files.
+    pub(crate) fn uid_map(&self) -> Option<&str> {
+        self.uid_map.as_deref()
+    }
+
+    /// Returns the `gid map` rules applied to served files.
+    pub(crate) fn gid_map(&self) -> Option<&str> {
+        self.gid_map.as_deref()
+    }
+
     /// Returns whether forward DNS lookup is enabled.
     pub(crate) fn forward_lookup(&self) -> bool {
         self.forward_lookup
@@ -21,6 +21,12 @@ impl RuntimeOptions {
         self.acceptor_threads.map_or(1, NonZeroU32::get)
     }
 
+    /// Returns the per-source-IP cap on new connections per minute, or
+    /// `None` when `max connection rate` is unset or 0.
+    pub(crate) fn max_connection_rate(&self) -> Option<NonZeroU32> {
+        self.max_connection_rate
+    }
+
     /// Returns the configured socket options string.
     ///
     /// Upstream: `daemon-parm.txt` - `socket options` STRING. Comma-separated
@@ -107,6 +113,14 @@ impl RuntimeOptions {
         self.bind_address
     }
 
+    pub(super) fn bind_scope_id(&self) -> u32 {
+        self.bind_scope_id
+    }
+
+    pub(super) fn unix_socket(&self) -> Option<&Path> {
+        self.unix_socket.as_deref()
+    }
+
     pub(super) fn address_family(&self) -> Option<AddressFamily> {
         self.address_family
     }
@@ -5,7 +5,7 @@ impl RuntimeOptions {
         seen_modules: &mut HashSet<String>,
     ) -> Result<(), DaemonError> {
         let path = PathBuf::from(value.clone());
-        let parsed = parse_config_modules(&path)?;
+        let parsed = parse_config_modules_with_dparams(&path, &self.dparams)?;
 
         // Retain the config path for SIGHUP reload. Only the first config
         // file loaded is reloadable; subsequent --config flags add modules
@@ -61,9 +61,15 @@ impl RuntimeOptions {
 
         // Apply the `address` directive only when no CLI --address/--bind was given.
         // upstream: clientserver.c - CLI --address overrides the config file `address`.
+        if let Some((path, _origin)) = parsed.unix_socket {
+            if !self.bind_address_overridden {
+                self.set_unix_socket(path);
+            }
+        }
         if let Some((addr, _origin)) = parsed.bind_address {
             if !self.bind_address_overridden {
                 self.bind_address = addr;
+                self.bind_scope_id = parsed.bind_scope_id;
                 self.bind_address_overridden = true;
                 self.address_family = Some(AddressFamily::from_ip(addr));
             }
@@ -87,6 +93,11 @@ impl RuntimeOptions {
             self.acceptor_threads = Some(threads);
         }
 
+        // Config-only as well; `max connection rate = 0` keeps the limit off.
+        if let Some((rate, _origin)) = parsed.max_connection_rate {
+            self.max_connection_rate = NonZeroU32::new(rate);
+        }
+
         // upstream: clientserver.c - config `port` overrides the default
         // listening port unless CLI `--port` was already given.
         if let Some((port, _origin)) = parsed.rsync_port {
@@ -108,6 +119,14 @@ impl RuntimeOptions {
             self.daemon_chroot = Some(chroot_path);
         }
 
+        if let Some((cert_path, _origin)) = parsed.tls_cert_file {
+            self.tls_cert_file = Some(cert_path);
+        }
+
+        if let Some((key_path, _origin)) = parsed.tls_key_file {
+            self.tls_key_file = Some(key_path);
+        }
+
         if !parsed.motd_lines.is_empty() {
             self.motd_lines.extend(parsed.motd_lines);
         }
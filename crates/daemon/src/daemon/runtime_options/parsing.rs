@@ -14,6 +14,7 @@ impl RuntimeOptions {
             brand,
             ..Default::default()
         };
+        options.dparams = dparam_arguments(arguments)?;
         let mut seen_modules = HashSet::new();
         if load_defaults && !config_argument_present(arguments) {
             if let Some(path) = environment_config_override() {
@@ -47,13 +48,19 @@ impl RuntimeOptions {
                 // CLI port suppresses the config value.
                 options.port_overridden = options.port != 0;
             } else if let Some(value) = take_option_value(argument, &mut iter, "--bind")? {
-                let addr = parse_bind_address(&value)?;
-                options.set_bind_address(addr)?;
+                let (addr, scope_id) = parse_bind_address(&value)?;
+                options.set_bind_address(addr, scope_id)?;
             } else if let Some(value) = take_option_value(argument, &mut iter, "--address")? {
-                let addr = parse_bind_address(&value)?;
-                options.set_bind_address(addr)?;
+                if let Some(path) = parse_unix_socket_address(&value)? {
+                    options.set_unix_socket(path);
+                } else {
+                    let (addr, scope_id) = parse_bind_address(&value)?;
+                    options.set_bind_address(addr, scope_id)?;
+                }
             } else if let Some(value) = take_option_value(argument, &mut iter, "--config")? {
                 options.load_config_modules(&value, &mut seen_modules)?;
+            } else if take_dparam_value(argument, &mut iter)?.is_some() {
+                // Collected by dparam_arguments() before any config loaded.
             } else if let Some(value) = take_option_value(argument, &mut iter, "--motd-file")? {
                 options.load_motd_file(&value)?;
             } else if let Some(value) = take_option_value(argument, &mut iter, "--motd")? {
@@ -100,6 +107,8 @@ impl RuntimeOptions {
                 options.set_cli_secrets_file(validated)?;
             } else if let Some(value) = take_option_value(argument, &mut iter, "--pid-file")? {
                 options.set_pid_file(PathBuf::from(value))?;
+            } else if let Some(value) = take_option_value(argument, &mut iter, "--sockopts")? {
+                options.set_socket_options(value.to_string_lossy().into_owned())?;
             } else if argument == "--verbose" {
                 options.verbosity = options.verbosity.saturating_add(1);
             } else if argument == "--no-verbose" || argument == "--no-v" {
@@ -140,6 +149,10 @@ impl RuntimeOptions {
             options.port = DEFAULT_PORT;
         }
 
+        if !options.dparams.is_empty() && options.config_path.is_none() {
+            return Err(config_error("--dparam requires a config file to override".to_owned()));
+        }
+
         Ok(options)
     }
 }
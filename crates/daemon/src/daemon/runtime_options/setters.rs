@@ -63,6 +63,18 @@ impl RuntimeOptions {
         Ok(())
     }
 
+    /// Records `--sockopts`, which replaces any `socket options` directive
+    /// read from the config file.
+    fn set_socket_options(&mut self, value: String) -> Result<(), DaemonError> {
+        if self.socket_options.is_some() && !self.socket_options_from_config {
+            return Err(duplicate_argument("--sockopts"));
+        }
+
+        self.socket_options = Some(value);
+        self.socket_options_from_config = false;
+        Ok(())
+    }
+
     fn set_pid_file(&mut self, path: PathBuf) -> Result<(), DaemonError> {
         if let Some(existing) = &self.pid_file {
             if !self.pid_file_from_config {
@@ -80,7 +92,7 @@ impl RuntimeOptions {
         Ok(())
     }
 
-    fn set_bind_address(&mut self, addr: IpAddr) -> Result<(), DaemonError> {
+    fn set_bind_address(&mut self, addr: IpAddr, scope_id: u32) -> Result<(), DaemonError> {
         if let Some(family) = self.address_family {
             if !family.matches(addr) {
                 return Err(match family {
@@ -97,10 +109,20 @@ impl RuntimeOptions {
         }
 
         self.bind_address = addr;
+        self.bind_scope_id = scope_id;
         self.bind_address_overridden = true;
         Ok(())
     }
 
+    /// Listens on the Unix domain socket at `path` instead of TCP.
+    ///
+    /// Counts as an explicit bind address, so a config-file `address`
+    /// directive no longer applies.
+    fn set_unix_socket(&mut self, path: PathBuf) {
+        self.unix_socket = Some(path);
+        self.bind_address_overridden = true;
+    }
+
     fn force_address_family(&mut self, family: AddressFamily) -> Result<(), DaemonError> {
         if let Some(existing) = self.address_family {
             if existing != family {
@@ -173,6 +173,29 @@ mod runtime_options_tests {
         );
     }
 
+    #[test]
+    fn parse_bind_address_keeps_ipv6_scope_id() {
+        for value in ["fe80::1%7", "[fe80::1%7]"] {
+            let args = vec![OsString::from("--address"), OsString::from(value)];
+            let options = RuntimeOptions::parse(&args).expect("parse");
+            assert_eq!(options.bind_address(), "fe80::1".parse::<IpAddr>().unwrap());
+            assert_eq!(options.bind_scope_id(), 7, "{value}");
+        }
+    }
+
+    #[test]
+    fn parse_bind_address_rejects_unknown_zone() {
+        let args = vec![
+            OsString::from("--address"),
+            OsString::from("fe80::1%no-such-if0"),
+        ];
+        let error = RuntimeOptions::parse(&args).expect_err("unknown interface");
+        assert!(
+            error.to_string().contains("invalid bind address"),
+            "{error}"
+        );
+    }
+
     #[test]
     fn parse_bind_option_alias() {
         let args = vec![OsString::from("--bind"), OsString::from("192.168.1.1")];
@@ -183,6 +206,26 @@ mod runtime_options_tests {
         );
     }
 
+    #[test]
+    fn parse_unix_socket_address_option() {
+        let args = vec![
+            OsString::from("--address"),
+            OsString::from("unix:/run/oc-rsyncd.sock"),
+        ];
+        let options = RuntimeOptions::parse(&args).expect("parse");
+        assert_eq!(
+            options.unix_socket(),
+            Some(Path::new("/run/oc-rsyncd.sock"))
+        );
+        assert_eq!(options.bind_address(), DEFAULT_BIND_ADDRESS);
+    }
+
+    #[test]
+    fn parse_unix_socket_address_requires_path() {
+        let args = vec![OsString::from("--address"), OsString::from("unix:")];
+        assert!(RuntimeOptions::parse(&args).is_err());
+    }
+
     #[test]
     fn ipv6_bind_with_ipv4_flag_is_rejected() {
         let args = vec![
@@ -412,6 +455,56 @@ mod runtime_options_tests {
         assert!(options.detach());
     }
 
+    #[test]
+    fn parse_sockopts_option() {
+        let args = vec![OsString::from("--sockopts=TCP_NODELAY,SO_KEEPALIVE")];
+        let options = RuntimeOptions::parse(&args).expect("parse");
+        assert_eq!(options.socket_options(), Some("TCP_NODELAY,SO_KEEPALIVE"));
+
+        let args = vec![
+            OsString::from("--sockopts"),
+            OsString::from("SO_SNDBUF=65536"),
+        ];
+        let options = RuntimeOptions::parse(&args).expect("parse");
+        assert_eq!(options.socket_options(), Some("SO_SNDBUF=65536"));
+    }
+
+    #[test]
+    fn duplicate_sockopts_is_rejected() {
+        let args = vec![
+            OsString::from("--sockopts=TCP_NODELAY"),
+            OsString::from("--sockopts=SO_KEEPALIVE"),
+        ];
+        assert!(RuntimeOptions::parse(&args).is_err());
+    }
+
+    #[test]
+    fn sockopts_overrides_config_socket_options() {
+        let mut file = NamedTempFile::new().expect("config file");
+        writeln!(
+            file,
+            "socket options = SO_KEEPALIVE\n[share]\npath = /srv/share\n"
+        )
+        .expect("write config");
+        let config = file.path().as_os_str().to_os_string();
+
+        let args = vec![
+            OsString::from("--config"),
+            config.clone(),
+            OsString::from("--sockopts=TCP_NODELAY"),
+        ];
+        let options = RuntimeOptions::parse(&args).expect("parse");
+        assert_eq!(options.socket_options(), Some("TCP_NODELAY"));
+
+        let args = vec![
+            OsString::from("--sockopts=TCP_NODELAY"),
+            OsString::from("--config"),
+            config,
+        ];
+        let options = RuntimeOptions::parse(&args).expect("parse");
+        assert_eq!(options.socket_options(), Some("TCP_NODELAY"));
+    }
+
     #[test]
     fn parse_config_loads_modules_from_file() {
         let mut file = NamedTempFile::new().expect("config file");
@@ -483,6 +576,60 @@ mod runtime_options_tests {
         assert_eq!(options.modules()[0].name, "files");
     }
 
+    #[test]
+    fn dparam_overrides_config_global_for_modules() {
+        let mut file = NamedTempFile::new().expect("config file");
+        writeln!(file, "max connections = 2\n[share]\npath = /srv/share\n")
+            .expect("write config");
+
+        let args = vec![
+            OsString::from("--dparam=maxconnections=5"),
+            OsString::from("--config"),
+            file.path().as_os_str().to_os_string(),
+        ];
+        let options = RuntimeOptions::parse(&args).expect("parse");
+        assert_eq!(options.modules().len(), 1);
+        assert_eq!(options.modules()[0].max_connections, NonZeroU32::new(5));
+    }
+
+    #[test]
+    fn dparam_short_form_replaces_config_global() {
+        let mut file = NamedTempFile::new().expect("config file");
+        writeln!(file, "pid file = /run/a.pid\n[share]\npath = /srv/share\n")
+            .expect("write config");
+
+        let args = vec![
+            OsString::from("--config"),
+            file.path().as_os_str().to_os_string(),
+            OsString::from("-M"),
+            OsString::from("pid file = /run/b.pid"),
+        ];
+        let options = RuntimeOptions::parse(&args).expect("parse");
+        assert_eq!(options.pid_file(), Some(Path::new("/run/b.pid")));
+    }
+
+    #[test]
+    fn dparam_without_equals_is_rejected() {
+        let file = NamedTempFile::new().expect("config file");
+        let args = vec![
+            OsString::from("--config"),
+            file.path().as_os_str().to_os_string(),
+            OsString::from("--dparam=maxconnections"),
+        ];
+        let error = RuntimeOptions::parse(&args).expect_err("missing '='");
+        assert!(error.to_string().contains("missing an '='"));
+    }
+
+    #[test]
+    fn dparam_without_config_file_is_rejected() {
+        let args = vec![
+            OsString::from("--dparam=maxconnections=5"),
+            OsString::from("--module"),
+            OsString::from("data=/srv/data"),
+        ];
+        assert!(RuntimeOptions::parse(&args).is_err());
+    }
+
     #[test]
     fn unsupported_option_is_rejected() {
         let args = vec![OsString::from("--unknown-option")];
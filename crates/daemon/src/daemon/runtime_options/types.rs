@@ -2,6 +2,9 @@
 pub(crate) struct RuntimeOptions {
     brand: Brand,
     bind_address: IpAddr,
+    /// IPv6 scope id of a link-local `bind_address` given as `fe80::1%eth0`;
+    /// zero otherwise.
+    bind_scope_id: u32,
     port: u16,
     max_sessions: Option<NonZeroUsize>,
     /// Maximum number of concurrently active client connections.
@@ -32,6 +35,12 @@ pub(crate) struct RuntimeOptions {
     /// (`open_socket_in`) for the family-iteration loop oc-rsync reproduces.
     pub(crate) dual_stack: bool,
     bind_address_overridden: bool,
+    /// Unix domain socket the daemon listens on instead of TCP, selected by
+    /// `--address unix:PATH` or `address = unix:PATH`.
+    ///
+    /// oc-rsync extension with no upstream equivalent. The wire protocol is
+    /// unchanged; only the listener transport differs.
+    unix_socket: Option<PathBuf>,
     port_overridden: bool,
     log_file: Option<PathBuf>,
     log_file_configured: bool,
@@ -74,6 +83,11 @@ pub(crate) struct RuntimeOptions {
     /// (upstream forks one child per accepted connection from a single
     /// listener); it changes only kernel socket behaviour, never the wire.
     acceptor_threads: Option<NonZeroU32>,
+    /// New connections admitted per source IP per minute from the
+    /// `max connection rate` directive. `None` disables the limit.
+    ///
+    /// oc-rsync extension with no upstream equivalent.
+    max_connection_rate: Option<NonZeroU32>,
     /// TCP port from the `port` / `rsync port` global config parameter.
     ///
     /// upstream: daemon-parm.txt - `port` INTEGER, P_GLOBAL, default 0.
@@ -100,6 +114,11 @@ pub(crate) struct RuntimeOptions {
     ///
     /// upstream: daemon-parm.h - `daemon chroot` STRING, P_GLOBAL.
     daemon_chroot: Option<PathBuf>,
+    /// PEM certificate chain and private key from `tls cert file` /
+    /// `tls key file`. oc-rsync extension; when set, every accepted TCP
+    /// connection must complete a TLS handshake first.
+    tls_cert_file: Option<PathBuf>,
+    tls_key_file: Option<PathBuf>,
     detach: bool,
     /// Path to the config file loaded at startup, retained for SIGHUP reload.
     ///
@@ -107,6 +126,12 @@ pub(crate) struct RuntimeOptions {
     /// new connections pick up module definition changes without a restart.
     /// `None` when no config file was loaded (all modules from CLI flags).
     config_path: Option<PathBuf>,
+    /// `KEY=VALUE` overrides from `--dparam` / `-M`, applied to the global
+    /// section of every config file loaded, including on SIGHUP reload.
+    ///
+    /// upstream: options.c - daemon `-M` collects `dparam_list`, which
+    /// loadparm.c:set_dparams() replays into every config load.
+    dparams: Vec<String>,
     /// CLI verbosity counter incremented per `-v` / `--verbose` flag.
     ///
     /// upstream: options.c:877 - `{"verbose", 'v', POPT_ARG_NONE, 0, 'v', 0, 0}`
@@ -120,6 +145,7 @@ impl Default for RuntimeOptions {
         Self {
             brand: Brand::Oc,
             bind_address: DEFAULT_BIND_ADDRESS,
+            bind_scope_id: 0,
             port: DEFAULT_PORT,
             max_sessions: None,
             max_connections: None,
@@ -131,6 +157,7 @@ impl Default for RuntimeOptions {
             address_family: None,
             dual_stack: false,
             bind_address_overridden: false,
+            unix_socket: None,
             port_overridden: false,
             log_file: None,
             log_file_configured: false,
@@ -155,14 +182,18 @@ impl Default for RuntimeOptions {
             listen_backlog: None,
             listen_backlog_from_config: false,
             acceptor_threads: None,
+            max_connection_rate: None,
             rsync_port: None,
             socket_options: None,
             socket_options_from_config: false,
             tcp_fastopen: TcpFastOpenMode::Auto,
             proxy_protocol: false,
             daemon_chroot: None,
+            tls_cert_file: None,
+            tls_key_file: None,
             detach: cfg!(unix),
             config_path: None,
+            dparams: Vec::new(),
             verbosity: 0,
         }
     }
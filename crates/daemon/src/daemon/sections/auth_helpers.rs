@@ -56,6 +56,19 @@ fn log_connection(log: &SharedLogSink, host: Option<&str>, peer_addr: SocketAddr
     log_message(log, &message);
 }
 
+/// Emits the structured `connect` event for a newly accepted session.
+fn log_connect_event(
+    observers: &SessionObservers,
+    host: Option<&str>,
+    peer_addr: SocketAddr,
+) {
+    observers.log(|| {
+        LogRecord::new(LogLevel::Info, "connect")
+            .with("peer", peer_addr.to_string())
+            .with_opt("host", host)
+    });
+}
+
 fn log_list_request(log: &SharedLogSink, host: Option<&str>, peer_addr: SocketAddr) {
     let display = format_host(host, peer_addr.ip());
     let ip = peer_addr.ip();
@@ -422,7 +422,11 @@ fn apply_global_directive(
                 ));
             }
 
-            let parsed_addr = parse_bind_address(&OsString::from(value))
+            // oc-rsync extension: `address = unix:PATH` listens on a Unix
+            // domain socket instead of a TCP port. Only one listener address
+            // may be configured, so a socket path conflicts with a TCP
+            // address exactly as two different TCP addresses do.
+            let unix_socket = parse_unix_socket_address(&OsString::from(value))
                 .map_err(|_| {
                     config_parse_error(
                         path,
@@ -430,25 +434,50 @@ fn apply_global_directive(
                         format!("invalid bind address '{value}'"),
                     )
                 })?;
+            let parsed_addr = if unix_socket.is_some() {
+                None
+            } else {
+                let addr = parse_bind_address(&OsString::from(value)).map_err(|_| {
+                    config_parse_error(
+                        path,
+                        line_number,
+                        format!("invalid bind address '{value}'"),
+                    )
+                })?;
+                Some(addr)
+            };
+
+            let conflicting_line = match (&state.unix_socket, &state.bind_address) {
+                (Some((existing, existing_origin)), _) => {
+                    (unix_socket.as_ref() != Some(existing)).then_some(existing_origin.line)
+                }
+                (None, Some((existing, existing_origin))) => {
+                    (parsed_addr != Some((*existing, state.bind_scope_id)))
+                        .then_some(existing_origin.line)
+                }
+                (None, None) => None,
+            };
+            if let Some(existing_line) = conflicting_line {
+                return Err(config_parse_error(
+                    path,
+                    line_number,
+                    format!(
+                        "duplicate 'address' directive in global section (previously defined on line {existing_line})"
+                    ),
+                ));
+            }
 
             let origin = ConfigDirectiveOrigin {
                 path: canonical.to_path_buf(),
                 line: line_number,
             };
-
-            if let Some((existing, existing_origin)) = &state.bind_address {
-                if *existing != parsed_addr {
-                    let existing_line = existing_origin.line;
-                    return Err(config_parse_error(
-                        path,
-                        line_number,
-                        format!(
-                            "duplicate 'address' directive in global section (previously defined on line {existing_line})"
-                        ),
-                    ));
+            if let Some(socket_path) = unix_socket {
+                state.unix_socket.get_or_insert((socket_path, origin));
+            } else if let Some((addr, scope_id)) = parsed_addr {
+                if state.bind_address.is_none() {
+                    state.bind_address = Some((addr, origin));
+                    state.bind_scope_id = scope_id;
                 }
-            } else {
-                state.bind_address = Some((parsed_addr, origin));
             }
         }
         // upstream: daemon-parm.txt `Locals:` `uid` is P_LOCAL. A value in the
@@ -617,6 +646,38 @@ fn apply_global_directive(
                 state.acceptor_threads = Some((threads, origin));
             }
         }
+        // oc-rsync extension - per-source-IP cap on new connections per
+        // minute, enforced in the accept loop before a worker is spawned.
+        // 0 (the default) disables the limit.
+        "maxconnectionrate" => {
+            let parsed: u32 = value.parse().map_err(|_| {
+                config_parse_error(
+                    path,
+                    line_number,
+                    format!("invalid integer value '{value}' for 'max connection rate'"),
+                )
+            })?;
+
+            let origin = ConfigDirectiveOrigin {
+                path: canonical.to_path_buf(),
+                line: line_number,
+            };
+
+            if let Some((existing, existing_origin)) = &state.max_connection_rate {
+                if *existing != parsed {
+                    let existing_line = existing_origin.line;
+                    return Err(config_parse_error(
+                        path,
+                        line_number,
+                        format!(
+                            "duplicate 'max connection rate' directive in global section (previously defined on line {existing_line})"
+                        ),
+                    ));
+                }
+            } else {
+                state.max_connection_rate = Some((parsed, origin));
+            }
+        }
         // upstream: daemon-parm.txt - port INTEGER, P_GLOBAL, default 0.
         // Controls the TCP port the daemon listens on.
         "port" | "rsyncport" => {
@@ -731,6 +792,70 @@ fn apply_global_directive(
                 state.daemon_chroot = Some((PathBuf::from(trimmed), origin));
             }
         }
+        // oc-rsync extension: TLS transport for daemon connections. Relative
+        // paths resolve against the config file, like `lock file`.
+        "tlscertfile" => {
+            let trimmed = value.trim();
+            if trimmed.is_empty() {
+                return Err(config_parse_error(
+                    path,
+                    line_number,
+                    "'tls cert file' directive must not be empty",
+                ));
+            }
+
+            let resolved = resolve_config_relative_path(path, trimmed);
+            let origin = ConfigDirectiveOrigin {
+                path: canonical.to_path_buf(),
+                line: line_number,
+            };
+
+            if let Some((existing, existing_origin)) = &state.tls_cert_file {
+                if existing != &resolved {
+                    let existing_line = existing_origin.line;
+                    return Err(config_parse_error(
+                        path,
+                        line_number,
+                        format!(
+                            "duplicate 'tls cert file' directive in global section (previously defined on line {existing_line})"
+                        ),
+                    ));
+                }
+            } else {
+                state.tls_cert_file = Some((resolved, origin));
+            }
+        }
+        "tlskeyfile" => {
+            let trimmed = value.trim();
+            if trimmed.is_empty() {
+                return Err(config_parse_error(
+                    path,
+                    line_number,
+                    "'tls key file' directive must not be empty",
+                ));
+            }
+
+            let resolved = resolve_config_relative_path(path, trimmed);
+            let origin = ConfigDirectiveOrigin {
+                path: canonical.to_path_buf(),
+                line: line_number,
+            };
+
+            if let Some((existing, existing_origin)) = &state.tls_key_file {
+                if existing != &resolved {
+                    let existing_line = existing_origin.line;
+                    return Err(config_parse_error(
+                        path,
+                        line_number,
+                        format!(
+                            "duplicate 'tls key file' directive in global section (previously defined on line {existing_line})"
+                        ),
+                    ));
+                }
+            } else {
+                state.tls_key_file = Some((resolved, origin));
+            }
+        }
         // upstream: loadparm.c - P_LOCAL directives in the global section set
         // default values inherited by all modules that don't override them.
         // When bInGlobalSection is true, parm_ptr = def_ptr, so the value
@@ -940,13 +1065,15 @@ fn apply_global_directive(
         // `path` only makes sense per-module - silently accepted, not inherited.
         "path" => {}
         _ => {
-            eprintln!(
+            let warning = format!(
                 "warning: unknown global directive '{}' in '{}' line {} [daemon={}]",
                 key,
                 path.display(),
                 line_number,
                 env!("CARGO_PKG_VERSION"),
             );
+            eprintln!("{warning}");
+            state.warnings.push(warning);
         }
     }
     Ok(())
@@ -23,14 +23,19 @@ struct GlobalParseState {
     syslog_facility: Option<(String, ConfigDirectiveOrigin)>,
     syslog_tag: Option<(String, ConfigDirectiveOrigin)>,
     bind_address: Option<(IpAddr, ConfigDirectiveOrigin)>,
+    bind_scope_id: u32,
+    unix_socket: Option<(PathBuf, ConfigDirectiveOrigin)>,
     daemon_uid: Option<(String, ConfigDirectiveOrigin)>,
     daemon_gid: Option<(String, ConfigDirectiveOrigin)>,
     listen_backlog: Option<(u32, ConfigDirectiveOrigin)>,
     acceptor_threads: Option<(NonZeroU32, ConfigDirectiveOrigin)>,
+    max_connection_rate: Option<(u32, ConfigDirectiveOrigin)>,
     socket_options: Option<(String, ConfigDirectiveOrigin)>,
     proxy_protocol: Option<(bool, ConfigDirectiveOrigin)>,
     rsync_port: Option<(u16, ConfigDirectiveOrigin)>,
     daemon_chroot: Option<(PathBuf, ConfigDirectiveOrigin)>,
+    tls_cert_file: Option<(PathBuf, ConfigDirectiveOrigin)>,
+    tls_key_file: Option<(PathBuf, ConfigDirectiveOrigin)>,
     modules: Vec<ModuleDefinition>,
     /// P_LOCAL parameter defaults from the global section.
     ///
@@ -47,6 +52,8 @@ struct GlobalParseState {
     inherited_secrets_file: Option<PathBuf>,
     inherited_incoming_chmod: Option<String>,
     inherited_outgoing_chmod: Option<String>,
+    /// Warnings already printed for ignored directives, kept for the result.
+    warnings: Vec<String>,
 }
 
 impl GlobalParseState {
@@ -66,20 +73,26 @@ impl GlobalParseState {
             syslog_facility: None,
             syslog_tag: None,
             bind_address: None,
+            bind_scope_id: 0,
+            unix_socket: None,
             daemon_uid: None,
             daemon_gid: None,
             listen_backlog: None,
             acceptor_threads: None,
+            max_connection_rate: None,
             socket_options: None,
             proxy_protocol: None,
             rsync_port: None,
             daemon_chroot: None,
+            tls_cert_file: None,
+            tls_key_file: None,
             modules: Vec::new(),
             module_defaults: GlobalModuleDefaults::default(),
             inherited_use_chroot: None,
             inherited_secrets_file: None,
             inherited_incoming_chmod: None,
             inherited_outgoing_chmod: None,
+            warnings: Vec::new(),
         }
     }
 
@@ -124,6 +137,48 @@ impl GlobalParseState {
         state
     }
 
+    /// Clears the recorded value of the global directive `key` so the next
+    /// occurrence replaces it instead of being rejected as a duplicate.
+    ///
+    /// `key` is the normalized directive name. Directives that keep no
+    /// duplicate-detection state (module defaults, `port`) are left alone;
+    /// a later value already overwrites them.
+    fn forget_global_directive(&mut self, key: &str) {
+        match key {
+            "refuseoptions" => {
+                self.global_refuse_directives.clear();
+                self.global_refuse_line = None;
+            }
+            "motdfile" | "motd" => self.motd_lines.clear(),
+            "pidfile" => self.pid_file = None,
+            "reverselookup" => self.reverse_lookup = None,
+            "bwlimit" => self.global_bwlimit = None,
+            "secretsfile" => self.global_secrets_file = None,
+            "incomingchmod" | "incoming-chmod" => self.global_incoming_chmod = None,
+            "outgoingchmod" | "outgoing-chmod" => self.global_outgoing_chmod = None,
+            "lockfile" => self.lock_file = None,
+            "usechroot" => self.global_use_chroot = None,
+            "syslogfacility" => self.syslog_facility = None,
+            "syslogtag" => self.syslog_tag = None,
+            "address" => {
+                self.bind_address = None;
+                self.bind_scope_id = 0;
+                self.unix_socket = None;
+            }
+            "daemonuid" => self.daemon_uid = None,
+            "daemongid" => self.daemon_gid = None,
+            "listenbacklog" => self.listen_backlog = None,
+            "acceptorthreads" => self.acceptor_threads = None,
+            "maxconnectionrate" => self.max_connection_rate = None,
+            "socketoptions" => self.socket_options = None,
+            "proxyprotocol" => self.proxy_protocol = None,
+            "daemonchroot" => self.daemon_chroot = None,
+            "tlscertfile" => self.tls_cert_file = None,
+            "tlskeyfile" => self.tls_key_file = None,
+            _ => {}
+        }
+    }
+
     /// Converts the accumulated global state into the final parsed result.
     fn into_result(self) -> ParsedConfigModules {
         ParsedConfigModules {
@@ -140,14 +195,20 @@ impl GlobalParseState {
             syslog_facility: self.syslog_facility,
             syslog_tag: self.syslog_tag,
             bind_address: self.bind_address,
+            bind_scope_id: self.bind_scope_id,
+            unix_socket: self.unix_socket,
             daemon_uid: self.daemon_uid,
             daemon_gid: self.daemon_gid,
             listen_backlog: self.listen_backlog,
             acceptor_threads: self.acceptor_threads,
+            max_connection_rate: self.max_connection_rate,
             socket_options: self.socket_options,
             proxy_protocol: self.proxy_protocol,
             rsync_port: self.rsync_port,
             daemon_chroot: self.daemon_chroot,
+            tls_cert_file: self.tls_cert_file,
+            tls_key_file: self.tls_key_file,
+            warnings: self.warnings,
         }
     }
 }
@@ -97,24 +97,27 @@ fn include_config_file(
     // the parent's globals so modules declared in the included file inherit the
     // parent's P_LOCAL defaults (use chroot, hosts allow, secrets file, ...),
     // matching the shared `Vars` that `]push` copies (not resets).
-    let included = parse_config_modules_inner(include_path, stack, Some(state)).map_err(|error| {
-        // Wrap inner failures so the user sees both the directive site that
-        // triggered the include and the underlying parse error from the
-        // included file. Missing-file and recursive-include errors already
-        // name the offending path; this wrap adds the parent line context.
-        let display = include_path.display();
-        config_parse_error(
-            path,
-            line_number,
-            format!("failed to process '{directive} {display}': {error}"),
-        )
-    })?;
+    let included =
+        parse_config_modules_inner(include_path, stack, Some(state), &[]).map_err(|error| {
+            // Wrap inner failures so the user sees both the directive site that
+            // triggered the include and the underlying parse error from the
+            // included file. Missing-file and recursive-include errors already
+            // name the offending path; this wrap adds the parent line context.
+            let display = include_path.display();
+            config_parse_error(
+                path,
+                line_number,
+                format!("failed to process '{directive} {display}': {error}"),
+            )
+        })?;
 
     if manage_globals {
         // `&include`: `]pop` restores the parent's globals afterwards, so the
         // included file's global directives do not leak back into the caller.
-        // Only the modules (upstream's section list) survive.
+        // Only the modules (upstream's section list) survive, along with the
+        // record of what the included file ignored.
         state.modules.extend(included.modules);
+        state.warnings.extend(included.warnings);
         return Ok(());
     }
 
@@ -134,6 +137,7 @@ fn merge_included_globals(
     if !included.modules.is_empty() {
         state.modules.extend(included.modules);
     }
+    state.warnings.extend(included.warnings);
 
     if !included.motd_lines.is_empty() {
         state.motd_lines.extend(included.motd_lines);
@@ -167,12 +171,21 @@ fn merge_included_globals(
         "outgoing chmod",
     )?;
 
+    if state.bind_address.is_none() {
+        state.bind_scope_id = included.bind_scope_id;
+    }
     merge_optional_directive(
         &mut state.bind_address,
         included.bind_address,
         "address",
     )?;
 
+    merge_optional_directive(
+        &mut state.unix_socket,
+        included.unix_socket,
+        "address",
+    )?;
+
     merge_optional_directive(
         &mut state.daemon_uid,
         included.daemon_uid,
@@ -191,6 +204,12 @@ fn merge_included_globals(
         "listen backlog",
     )?;
 
+    merge_optional_directive(
+        &mut state.max_connection_rate,
+        included.max_connection_rate,
+        "max connection rate",
+    )?;
+
     merge_optional_directive(
         &mut state.socket_options,
         included.socket_options,
@@ -226,6 +245,18 @@ fn merge_included_globals(
         "daemon chroot",
     )?;
 
+    merge_optional_directive(
+        &mut state.tls_cert_file,
+        included.tls_cert_file,
+        "tls cert file",
+    )?;
+
+    merge_optional_directive(
+        &mut state.tls_key_file,
+        included.tls_key_file,
+        "tls key file",
+    )?;
+
     Ok(())
 }
 
@@ -44,6 +44,7 @@ fn apply_module_directive(
     path: &Path,
     line_number: usize,
     canonical: &Path,
+    warnings: &mut Vec<String>,
 ) -> Result<(), DaemonError> {
     match key {
         "path" => {
@@ -326,6 +327,22 @@ fn apply_module_directive(
             };
             builder.set_charset(cs, path, line_number)?;
         }
+        "uidmap" => {
+            let spec = if value.is_empty() {
+                None
+            } else {
+                Some(value.to_owned())
+            };
+            builder.set_uid_map(spec, path, line_number)?;
+        }
+        "gidmap" => {
+            let spec = if value.is_empty() {
+                None
+            } else {
+                Some(value.to_owned())
+            };
+            builder.set_gid_map(spec, path, line_number)?;
+        }
         "forwardlookup" => {
             if let Some(parsed) =
                 apply_boolean_directive(value, false, "forward lookup", path, line_number)
@@ -449,16 +466,20 @@ fn apply_module_directive(
             // that appears inside a module section is reported and ignored,
             // never applied to the module (loadparm.c: "Global parameter %s
             // found in module section!").
-            eprintln!("Global parameter {key} found in module section!");
+            let warning = format!("Global parameter {key} found in module section!");
+            eprintln!("{warning}");
+            warnings.push(warning);
         }
         _ => {
-            eprintln!(
+            let warning = format!(
                 "warning: unknown per-module directive '{}' in '{}' line {} [daemon={}]",
                 key,
                 path.display(),
                 line_number,
                 env!("CARGO_PKG_VERSION"),
             );
+            eprintln!("{warning}");
+            warnings.push(warning);
         }
     }
     Ok(())
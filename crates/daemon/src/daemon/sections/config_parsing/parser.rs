@@ -33,14 +33,24 @@ fn normalize_param_name(name: &str) -> String {
 
 /// Parses the `rsyncd.conf` at `path` into module definitions and global settings.
 pub(crate) fn parse_config_modules(path: &Path) -> Result<ParsedConfigModules, DaemonError> {
+    parse_config_modules_with_dparams(path, &[])
+}
+
+/// Parses the `rsyncd.conf` at `path`, applying `--dparam` `KEY=VALUE`
+/// overrides as if they were appended to its global section.
+pub(crate) fn parse_config_modules_with_dparams(
+    path: &Path,
+    dparams: &[String],
+) -> Result<ParsedConfigModules, DaemonError> {
     let mut stack = Vec::new();
-    parse_config_modules_inner(path, &mut stack, None)
+    parse_config_modules_inner(path, &mut stack, None, dparams)
 }
 
 fn parse_config_modules_inner(
     path: &Path,
     stack: &mut Vec<PathBuf>,
     inherited: Option<&GlobalParseState>,
+    dparams: &[String],
 ) -> Result<ParsedConfigModules, DaemonError> {
     let canonical = path
         .canonicalize()
@@ -68,6 +78,7 @@ fn parse_config_modules_inner(
         None => GlobalParseState::new(),
     };
     let mut current: Option<ModuleDefinitionBuilder> = None;
+    let mut dparams_pending = !dparams.is_empty();
 
     let result = (|| -> Result<ParsedConfigModules, DaemonError> {
         for (line_number, logical_line) in logical_config_lines(&contents) {
@@ -104,6 +115,11 @@ fn parse_config_modules_inner(
                     ));
                 }
 
+                if dparams_pending {
+                    dparams_pending = false;
+                    apply_dparams(&mut state, dparams, path, &canonical, stack)?;
+                }
+
                 if let Some(builder) = current.take() {
                     state.modules.push(finish_module_builder(builder, path, &state)?);
                 }
@@ -151,7 +167,15 @@ fn parse_config_modules_inner(
             if !is_amp_directive
                 && let Some(builder) = current.as_mut()
             {
-                apply_module_directive(builder, &key, value, path, line_number, &canonical)?;
+                apply_module_directive(
+                    builder,
+                    &key,
+                    value,
+                    path,
+                    line_number,
+                    &canonical,
+                    &mut state.warnings,
+                )?;
                 continue;
             }
 
@@ -164,9 +188,20 @@ fn parse_config_modules_inner(
                 state.modules.push(finish_module_builder(builder, path, &state)?);
             }
 
+            // Modules pulled in by `&include`/`&merge` inherit the globals
+            // in effect at the directive, so the overrides must land first.
+            if is_amp_directive && dparams_pending {
+                dparams_pending = false;
+                apply_dparams(&mut state, dparams, path, &canonical, stack)?;
+            }
+
             apply_global_directive(&mut state, &key, value, path, line_number, &canonical, stack)?;
         }
 
+        if dparams_pending {
+            apply_dparams(&mut state, dparams, path, &canonical, stack)?;
+        }
+
         if let Some(builder) = current {
             state.modules.push(finish_module_builder(builder, path, &state)?);
         }
@@ -178,6 +213,37 @@ fn parse_config_modules_inner(
     result
 }
 
+/// Applies `--dparam` overrides to the global section of the file being
+/// parsed.
+///
+/// upstream: loadparm.c:set_dparams() - each `KEY=VALUE` goes through
+/// do_parameter() once the global section ends, before the first module is
+/// defined. A value therefore replaces the one the file set and becomes the
+/// default every module inherits. Errors name line 0 because the setting
+/// has no line in the file.
+fn apply_dparams(
+    state: &mut GlobalParseState,
+    dparams: &[String],
+    path: &Path,
+    canonical: &Path,
+    stack: &mut Vec<PathBuf>,
+) -> Result<(), DaemonError> {
+    for param in dparams {
+        let (raw_key, raw_value) = param
+            .split_once('=')
+            .ok_or_else(|| config_error(format!("--dparam value is missing an '=': {param}")))?;
+        let key = normalize_param_name(raw_key);
+        if key.is_empty() || key.starts_with('&') {
+            return Err(config_error(format!(
+                "unknown daemon parameter in --dparam: {param}"
+            )));
+        }
+        state.forget_global_directive(&key);
+        apply_global_directive(state, &key, raw_value.trim(), path, 0, canonical, stack)?;
+    }
+    Ok(())
+}
+
 /// Finalizes a module builder using the current global defaults.
 ///
 /// Explicit globals declared in the same file win over inherited values
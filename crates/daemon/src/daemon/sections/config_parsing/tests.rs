@@ -164,6 +164,13 @@ mod config_parsing_tests {
         let file = write_config("unknown = value\n");
         let result = parse_config_modules(file.path()).expect("parse succeeds with warning");
         assert!(result.modules.is_empty());
+        assert_eq!(result.warnings.len(), 1);
+        assert!(
+            result.warnings[0].starts_with("warning: unknown global directive 'unknown' in '"),
+            "{:?}",
+            result.warnings
+        );
+        assert!(result.warnings[0].contains("' line 1 [daemon="));
     }
 
 
@@ -983,6 +990,37 @@ mod config_parsing_tests {
         assert_eq!(result.modules[0].charset.as_deref(), Some("utf-8"));
     }
 
+    #[test]
+    fn parse_module_uid_and_gid_maps() {
+        let dir = TempDir::new().expect("create temp dir");
+        let path = dir.path().join("data");
+        fs::create_dir(&path).expect("create dir");
+
+        let config = format!(
+            "[mod]\npath = {}\nuid map = 1000:0,*:65534\ngid map = *:0\n",
+            path.display()
+        );
+        let file = write_config(&config);
+        let result = parse_config_modules(file.path()).expect("parse succeeds");
+        assert_eq!(result.modules[0].uid_map.as_deref(), Some("1000:0,*:65534"));
+        assert_eq!(result.modules[0].gid_map.as_deref(), Some("*:0"));
+    }
+
+    #[test]
+    fn parse_module_duplicate_uid_map_errors() {
+        let dir = TempDir::new().expect("create temp dir");
+        let path = dir.path().join("data");
+        fs::create_dir(&path).expect("create dir");
+
+        let config = format!(
+            "[mod]\npath = {}\nuid map = *:0\nuid map = *:1\n",
+            path.display()
+        );
+        let file = write_config(&config);
+        let err = parse_config_modules(file.path()).expect_err("duplicate must fail");
+        assert!(err.to_string().contains("duplicate 'uid map'"));
+    }
+
     #[test]
     fn parse_module_forward_lookup() {
         let dir = TempDir::new().expect("create temp dir");
@@ -2225,6 +2263,24 @@ mod config_parsing_tests {
         let result = parse_config_modules(file.path()).expect("parse succeeds");
         let (addr, _) = result.bind_address.expect("should have bind_address");
         assert_eq!(addr, IpAddr::V6(Ipv6Addr::LOCALHOST));
+        assert_eq!(result.bind_scope_id, 0);
+    }
+
+    #[test]
+    fn parse_address_link_local_keeps_scope_id() {
+        let file = write_config("address = fe80::1%3\n");
+        let result = parse_config_modules(file.path()).expect("parse succeeds");
+        let (addr, _) = result.bind_address.expect("should have bind_address");
+        assert_eq!(addr, "fe80::1".parse::<IpAddr>().unwrap());
+        assert_eq!(result.bind_scope_id, 3);
+    }
+
+    #[test]
+    fn parse_address_duplicate_different_scope() {
+        let file = write_config("address = fe80::1%3\naddress = fe80::1%4\n");
+        let err = parse_config_modules(file.path()).unwrap_err();
+        let msg = err.to_string();
+        assert!(msg.contains("duplicate 'address' directive"), "{msg}");
     }
 
     #[test]
@@ -2266,6 +2322,23 @@ mod config_parsing_tests {
         assert!(msg.contains("duplicate 'address' directive"), "{msg}");
     }
 
+    #[test]
+    fn parse_address_unix_socket() {
+        let file = write_config("address = unix:/run/oc-rsyncd.sock\n");
+        let result = parse_config_modules(file.path()).expect("parse succeeds");
+        let (socket, _) = result.unix_socket.expect("should have unix_socket");
+        assert_eq!(socket, PathBuf::from("/run/oc-rsyncd.sock"));
+        assert!(result.bind_address.is_none());
+    }
+
+    #[test]
+    fn parse_address_unix_socket_conflicts_with_tcp_address() {
+        let file = write_config("address = 10.0.0.1\naddress = unix:/run/oc-rsyncd.sock\n");
+        let err = parse_config_modules(file.path()).unwrap_err();
+        let msg = err.to_string();
+        assert!(msg.contains("duplicate 'address' directive"), "{msg}");
+    }
+
 
     #[test]
     fn parse_socket_options_single_option() {
@@ -2415,6 +2488,66 @@ mod config_parsing_tests {
         assert!(result.is_err());
     }
 
+    #[test]
+    fn parse_global_max_connection_rate() {
+        let dir = TempDir::new().expect("create temp dir");
+        let path = dir.path().join("data");
+        fs::create_dir(&path).expect("create dir");
+
+        let config = format!("max connection rate = 30\n[mod]\npath = {}\n", path.display());
+        let file = write_config(&config);
+        let result = parse_config_modules(file.path()).expect("parse succeeds");
+        let (rate, _) = result
+            .max_connection_rate
+            .expect("should have max_connection_rate");
+        assert_eq!(rate, 30);
+    }
+
+    #[test]
+    fn parse_global_max_connection_rate_rejects_non_integer() {
+        let dir = TempDir::new().expect("create temp dir");
+        let path = dir.path().join("data");
+        fs::create_dir(&path).expect("create dir");
+
+        let config = format!("max connection rate = fast\n[mod]\npath = {}\n", path.display());
+        let file = write_config(&config);
+        let error = parse_config_modules(file.path()).expect_err("non-integer must fail");
+        assert!(error.to_string().contains("max connection rate"));
+    }
+
+    #[test]
+    fn parse_global_tls_cert_and_key_files() {
+        let dir = TempDir::new().expect("create temp dir");
+        let path = dir.path().join("data");
+        fs::create_dir(&path).expect("create dir");
+
+        let config = format!(
+            "tls cert file = /etc/rsyncd/cert.pem\ntls key file = /etc/rsyncd/key.pem\n[mod]\npath = {}\n",
+            path.display()
+        );
+        let file = write_config(&config);
+        let result = parse_config_modules(file.path()).expect("parse succeeds");
+        let (cert, _) = result.tls_cert_file.expect("should have tls_cert_file");
+        let (key, _) = result.tls_key_file.expect("should have tls_key_file");
+        assert_eq!(cert, PathBuf::from("/etc/rsyncd/cert.pem"));
+        assert_eq!(key, PathBuf::from("/etc/rsyncd/key.pem"));
+    }
+
+    #[test]
+    fn parse_global_tls_cert_file_duplicate_rejected() {
+        let dir = TempDir::new().expect("create temp dir");
+        let path = dir.path().join("data");
+        fs::create_dir(&path).expect("create dir");
+
+        let config = format!(
+            "tls cert file = /a.pem\ntls cert file = /b.pem\n[mod]\npath = {}\n",
+            path.display()
+        );
+        let file = write_config(&config);
+        let error = parse_config_modules(file.path()).expect_err("duplicate must fail");
+        assert!(error.to_string().contains("duplicate 'tls cert file'"));
+    }
+
 
     #[test]
     fn parse_module_filter_single_rule() {
@@ -3241,4 +3374,88 @@ mod config_parsing_tests {
             "a module-level 'auth users' overrides the global default",
         );
     }
+
+    #[test]
+    fn parse_representative_config_yields_structured_globals_and_modules() {
+        // One file exercising every layer of the format at once: comments of
+        // both styles, a continued global, an unknown key that only warns,
+        // P_LOCAL defaults set globally, an `&include`d module, and a module
+        // that overrides an inherited default.
+        let dir = TempDir::new().expect("create temp dir");
+        let alpha = dir.path().join("alpha");
+        let beta = dir.path().join("beta");
+        fs::create_dir(&alpha).expect("create alpha");
+        fs::create_dir(&beta).expect("create beta");
+
+        let included = format!(
+            "; beta ships from a separate file\n\
+             [beta]\n\
+             path = {}\n\
+             read only = no\n\
+             timeout = 60\n",
+            beta.display()
+        );
+        let include_file = write_config(&included);
+
+        let main_config = format!(
+            "# global section\n\
+             pid file = {pid}\n\
+             motd = Welcome to \\\n\
+             the mirror\n\
+             reverse lookup = no\n\
+             use chroot = no\n\
+             timeout = 900\n\
+             frobnicate = yes\n\
+             &include {include}\n\
+             \n\
+             [alpha]\n\
+             \tpath = {alpha}\n\
+             \tcomment = Primary mirror\n\
+             \texclude = lost+found/\n\
+             \tmax connections = 4\n",
+            pid = abs("/var/run/oc-rsyncd.pid"),
+            include = include_file.path().display(),
+            alpha = alpha.display(),
+        );
+        let main_file = write_config(&main_config);
+
+        let result = parse_config_modules(main_file.path()).expect("parse succeeds");
+
+        // The continued motd spans lines 3-4, so the unknown key sits on line 8.
+        assert_eq!(
+            result.warnings,
+            [format!(
+                "warning: unknown global directive 'frobnicate' in '{}' line 8 [daemon={}]",
+                main_file.path().display(),
+                env!("CARGO_PKG_VERSION"),
+            )],
+            "only the unknown key warns, naming its own line"
+        );
+
+        let (pid_file, _) = result.pid_file.as_ref().expect("pid file parsed");
+        assert_eq!(pid_file, &PathBuf::from(abs("/var/run/oc-rsyncd.pid")));
+        assert_eq!(result.motd_lines, vec!["Welcome to the mirror"]);
+        assert_eq!(result.reverse_lookup.as_ref().map(|(v, _)| *v), Some(false));
+
+        let names: Vec<&str> = result.modules.iter().map(|m| m.name.as_str()).collect();
+        assert_eq!(names, ["beta", "alpha"], "modules keep file order");
+
+        let beta_mod = &result.modules[0];
+        assert_eq!(beta_mod.path, beta);
+        assert!(!beta_mod.read_only);
+        assert!(
+            !beta_mod.use_chroot,
+            "global use chroot reaches included module"
+        );
+        assert_eq!(beta_mod.timeout.map(NonZeroU64::get), Some(60));
+
+        let alpha_mod = &result.modules[1];
+        assert_eq!(alpha_mod.path, alpha);
+        assert_eq!(alpha_mod.comment.as_deref(), Some("Primary mirror"));
+        assert!(alpha_mod.read_only, "read only defaults to yes");
+        assert!(!alpha_mod.use_chroot);
+        assert_eq!(alpha_mod.timeout.map(NonZeroU64::get), Some(900));
+        assert_eq!(alpha_mod.exclude, ["lost+found/"]);
+        assert_eq!(alpha_mod.max_connections.map(NonZeroU32::get), Some(4));
+    }
 }
@@ -33,6 +33,12 @@ pub(crate) struct ParsedConfigModules {
     /// upstream: loadparm.c - `bind address` / `address` parameter sets the
     /// interface the daemon listens on.
     bind_address: Option<(IpAddr, ConfigDirectiveOrigin)>,
+    /// IPv6 scope id of a link-local `bind_address` such as `fe80::1%eth0`.
+    bind_scope_id: u32,
+    /// Unix domain socket path from an `address = unix:PATH` directive.
+    ///
+    /// oc-rsync extension: the daemon listens on the socket instead of TCP.
+    unix_socket: Option<(PathBuf, ConfigDirectiveOrigin)>,
     /// Process-wide daemon uid from the `daemon uid` global directive.
     ///
     /// upstream: daemon-parm.txt `Globals:` `daemon_uid`; clientserver.c:1376
@@ -50,6 +56,9 @@ pub(crate) struct ParsedConfigModules {
     /// Number of SO_REUSEPORT listener replicas per family from the
     /// `acceptor threads` directive (oc-rsync extension, default 1).
     acceptor_threads: Option<(NonZeroU32, ConfigDirectiveOrigin)>,
+    /// New connections admitted per source IP per minute from the
+    /// `max connection rate` directive (oc-rsync extension, 0 = unlimited).
+    max_connection_rate: Option<(u32, ConfigDirectiveOrigin)>,
     /// Global socket options from the `socket options` directive.
     ///
     /// upstream: daemon-parm.txt - `socket options` STRING. Comma-separated list
@@ -67,4 +76,13 @@ pub(crate) struct ParsedConfigModules {
     ///
     /// upstream: daemon-parm.h - `daemon chroot` STRING, P_GLOBAL.
     daemon_chroot: Option<(PathBuf, ConfigDirectiveOrigin)>,
+    /// PEM certificate chain from the `tls cert file` directive.
+    ///
+    /// oc-rsync extension: together with `tls key file`, makes the daemon
+    /// accept only TLS-wrapped connections.
+    tls_cert_file: Option<(PathBuf, ConfigDirectiveOrigin)>,
+    /// PEM private key from the `tls key file` directive.
+    tls_key_file: Option<(PathBuf, ConfigDirectiveOrigin)>,
+    /// Warnings printed for directives that were ignored, in file order.
+    warnings: Vec<String>,
 }
@@ -23,6 +23,53 @@ where
     Ok(None)
 }
 
+/// Extracts the value of a `--dparam` / `-M` argument, in any of the
+/// `--dparam=KV`, `--dparam KV`, `-MKV` and `-M KV` spellings.
+fn take_dparam_value<'a, I>(
+    argument: &'a OsString,
+    iter: &mut I,
+) -> Result<Option<OsString>, DaemonError>
+where
+    I: Iterator<Item = &'a OsString>,
+{
+    if let Some(value) = take_option_value(argument, iter, "--dparam")? {
+        return Ok(Some(value));
+    }
+    if argument == "-M" {
+        let value = iter
+            .next()
+            .cloned()
+            .ok_or_else(|| missing_argument_value("-M"))?;
+        return Ok(Some(value));
+    }
+    Ok(argument
+        .to_str()
+        .and_then(|text| text.strip_prefix("-M"))
+        .map(OsString::from))
+}
+
+/// Collects every `--dparam` override ahead of config loading, so the
+/// overrides apply whichever order `--config` and `--dparam` appear in.
+///
+/// upstream: options.c - a daemon `-M` value without `=` is rejected with
+/// "--dparam value is missing an '='".
+fn dparam_arguments(arguments: &[OsString]) -> Result<Vec<String>, DaemonError> {
+    let mut dparams = Vec::new();
+    let mut iter = arguments.iter();
+    while let Some(argument) = iter.next() {
+        if let Some(value) = take_dparam_value(argument, &mut iter)? {
+            let text = value.to_string_lossy().into_owned();
+            if !text.contains('=') {
+                return Err(config_error(format!(
+                    "--dparam value is missing an '=': {text}"
+                )));
+            }
+            dparams.push(text);
+        }
+    }
+    Ok(dparams)
+}
+
 fn config_argument_present(arguments: &[OsString]) -> bool {
     for argument in arguments {
         if argument == "--config" {
@@ -46,7 +46,10 @@ fn is_stdin_socket() -> bool {
 /// upstream: clientserver.c:1548-1559 - when `is_a_socket(STDIN_FILENO)` is
 /// true, `daemon_main()` redirects stdout/stderr to `/dev/null` and calls
 /// `start_daemon(STDIN_FILENO, STDIN_FILENO)`.
-fn serve_inetd_session(options: RuntimeOptions) -> Result<(), DaemonError> {
+fn serve_inetd_session(
+    options: RuntimeOptions,
+    observers: SessionObservers,
+) -> Result<(), DaemonError> {
     let brand = options.brand;
 
     let RuntimeOptions {
@@ -121,6 +124,7 @@ fn serve_inetd_session(options: RuntimeOptions) -> Result<(), DaemonError> {
     if let Some(log) = log_sink.as_ref() {
         log_connection(log, peer_host.as_deref(), peer_addr);
     }
+    log_connect_event(&observers, peer_host.as_deref(), peer_addr);
 
     handle_legacy_session(
         stream,
@@ -131,6 +135,7 @@ fn serve_inetd_session(options: RuntimeOptions) -> Result<(), DaemonError> {
             daemon_limit: bandwidth_limit,
             daemon_burst: bandwidth_burst,
             log_sink,
+            observers,
             peer_host,
             reverse_lookup,
         },
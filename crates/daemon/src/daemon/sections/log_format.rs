@@ -141,6 +141,56 @@ fn log_transfer(format: &str, ctx: &LogFormatContext<'_>, log_sink: &SharedLogSi
     log_message(log_sink, &message);
 }
 
+/// Writes one `log format` line per transferred file to the daemon log.
+///
+/// Installed as the engine's [`TransferProgressCallback`] when the module has
+/// `transfer logging` enabled, so each completed file expands the module's
+/// format with its own name and sizes. The connection-level fields are fixed
+/// when the transfer starts.
+///
+/// Upstream: `log.c:log_item()` -- called per file from `sender.c` and
+/// `receiver.c`; with `transfer logging` set it emits
+/// `log_formatted(FLOG, lp_log_format(module_id), ...)`.
+struct TransferLogWriter<'a> {
+    format: &'a str,
+    log_sink: &'a SharedLogSink,
+    operation: TransferOperation,
+    hostname: &'a str,
+    remote_addr: String,
+    module_name: &'a str,
+    username: &'a str,
+    module_path: String,
+    pid: u32,
+}
+
+impl TransferProgressCallback for TransferLogWriter<'_> {
+    fn on_file_transferred(&mut self, event: &TransferProgressEvent<'_>) {
+        let filename = event.path.to_string_lossy();
+        let secs = SystemTime::now()
+            .duration_since(UNIX_EPOCH)
+            .unwrap_or_default()
+            .as_secs();
+        let timestamp = format_daemon_timestamp(secs);
+
+        let ctx = LogFormatContext {
+            operation: self.operation,
+            hostname: self.hostname,
+            remote_addr: &self.remote_addr,
+            module_name: self.module_name,
+            username: self.username,
+            filename: &filename,
+            file_length: event.total_file_bytes.unwrap_or(event.file_bytes),
+            pid: self.pid,
+            module_path: &self.module_path,
+            timestamp: &timestamp,
+            bytes_transferred: event.file_bytes,
+            bytes_checksumed: 0,
+            itemize_string: "",
+        };
+        log_transfer(self.format, &ctx, self.log_sink);
+    }
+}
+
 /// Maximum epoch seconds accepted for timestamp formatting.
 ///
 /// Corresponds to 9999-12-31 23:59:59 UTC - the last representable date in
@@ -68,6 +68,11 @@ fn apply_long_form_args(client_args: &[String], config: &mut ServerConfig) -> Op
             "--size-only" => {
                 config.file_selection.size_only = true;
             }
+            // upstream: options.c:2848-2849 - force_delete lets a non-empty
+            // directory make way for an incoming non-directory.
+            "--force" => {
+                config.deletion.force_delete = true;
+            }
             // upstream: options.c:2896-2897
             "--ignore-errors" => {
                 config.deletion.ignore_errors = true;
@@ -1,5 +1,6 @@
 // Resolution of daemon module directives that depend on the selected module:
-// the `charset =` iconv converter and the `incoming`/`outgoing chmod` specs.
+// the `charset =` iconv converter, the `incoming`/`outgoing chmod` specs and
+// the `uid map`/`gid map` ownership rules.
 /// Resolves the daemon module's `charset =` directive into a
 /// [`FilenameConverter`] that mirrors upstream rsync's iconv setup.
 ///
@@ -102,3 +103,36 @@ fn parse_one_chmod_spec(
         None => Ok(None),
     }
 }
+
+/// Parses the module's `uid map` / `gid map` directives into the
+/// [`metadata::UserMapping`] / [`metadata::GroupMapping`] rules the sender
+/// applies to served file ownership. The specs share `--usermap` /
+/// `--groupmap` syntax; a malformed spec yields a message the caller wraps
+/// in an `@ERROR` reply, as for the chmod directives.
+fn parse_daemon_id_maps(
+    module: &ModuleRuntime,
+) -> Result<
+    (
+        Option<metadata::UserMapping>,
+        Option<metadata::GroupMapping>,
+    ),
+    String,
+> {
+    let user = module
+        .uid_map
+        .as_deref()
+        .map(|text| {
+            metadata::UserMapping::parse(text)
+                .map_err(|err| format!("invalid 'uid map' directive '{text}': {err}"))
+        })
+        .transpose()?;
+    let group = module
+        .gid_map
+        .as_deref()
+        .map(|text| {
+            metadata::GroupMapping::parse(text)
+                .map_err(|err| format!("invalid 'gid map' directive '{text}': {err}"))
+        })
+        .transpose()?;
+    Ok((user, group))
+}
@@ -218,6 +218,33 @@ fn resolve_sender_sources(
     Some(expand_sender_source_globs(module_path, sources))
 }
 
+/// Resolves the sender's source for a pull from a single-file module.
+///
+/// `module_path` is the file's parent directory and `file_name` the served
+/// entry (see `single_file_module`). A bare module request, or one naming
+/// `.` or the file itself, yields `<parent>/<file_name>` with no trailing
+/// slash, so the sender's `dir/fn` split emits the file under its own name.
+/// Any other sub-path is refused with `None`: the parent's other entries are
+/// not part of the module.
+fn resolve_single_file_source(
+    module_path: &std::path::Path,
+    client_args: &[String],
+    module_name: &str,
+    file_name: &std::ffi::OsStr,
+) -> Option<Vec<std::path::PathBuf>> {
+    let positionals = extract_module_relative_paths(client_args, module_name);
+    let requests_file = |raw: &String| {
+        let tail = raw.trim().trim_matches('/');
+        tail.is_empty() || tail == "." || std::ffi::OsStr::new(tail) == file_name
+    };
+    if !positionals.iter().all(requests_file) {
+        return None;
+    }
+    let mut buf = module_root_dotdir(module_path).into_os_string();
+    buf.push(file_name);
+    Some(vec![std::path::PathBuf::from(buf)])
+}
+
 /// Returns the module root path with a trailing `/` appended (idempotent).
 ///
 /// The trailing slash signals "transfer contents" through
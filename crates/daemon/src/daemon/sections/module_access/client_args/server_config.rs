@@ -78,6 +78,11 @@ fn apply_module_transfer_directives(module: &ModuleDefinition, cfg: &mut ServerC
         cfg.deletion.ignore_errors = true;
     }
 
+    // upstream: flist.c:make_file() - `lp_ignore_nonreadable(module_id)`
+    if module.ignore_nonreadable {
+        cfg.file_selection.ignore_nonreadable = true;
+    }
+
     // upstream: clientserver.c:1201-1204
     //   if (!numeric_ids
     //    && (use_chroot ? lp_numeric_ids(module_id) != False
@@ -111,6 +116,76 @@ fn apply_module_transfer_directives(module: &ModuleDefinition, cfg: &mut ServerC
     }
 }
 
+/// Extracts the client's `--timeout` from its server argv.
+///
+/// upstream: options.c:server_options() forwards a non-zero `io_timeout` as
+/// `--timeout=N`. Zero, a malformed value, or no option at all mean the client
+/// asked for no timeout.
+fn client_io_timeout(client_args: &[String]) -> Option<NonZeroU64> {
+    client_args
+        .iter()
+        .take_while(|arg| arg.as_str() != ".")
+        .filter_map(|arg| arg.strip_prefix("--timeout="))
+        .last()
+        .and_then(|value| value.parse::<u64>().ok())
+        .and_then(NonZeroU64::new)
+}
+
+/// Resolves the data-phase I/O timeout from the module `timeout` (which
+/// already carries the global default) and the client's `--timeout`.
+///
+/// The smaller non-zero value wins; an unset side never loosens the other.
+///
+/// upstream: clientserver.c:rsync_module() - `if (lp_timeout(module_id) &&
+/// (!io_timeout || lp_timeout(module_id) < io_timeout))
+/// set_io_timeout(lp_timeout(module_id));`
+fn effective_io_timeout(
+    module_timeout: Option<NonZeroU64>,
+    client_args: &[String],
+) -> Option<NonZeroU64> {
+    match (module_timeout, client_io_timeout(client_args)) {
+        (Some(module), Some(client)) => Some(module.min(client)),
+        (module, client) => module.or(client),
+    }
+}
+
+/// Extracts the client's `--bwlimit` rate in bytes per second from its server
+/// argv.
+///
+/// upstream: options.c:server_options() forwards a non-zero `bwlimit` as
+/// `--bwlimit=%d` in whole KiB. Zero, a malformed value, or no option at all
+/// mean the client asked for no throttling.
+fn client_bwlimit(client_args: &[String]) -> Option<NonZeroU64> {
+    client_args
+        .iter()
+        .take_while(|arg| arg.as_str() != ".")
+        .filter_map(|arg| arg.strip_prefix("--bwlimit="))
+        .last()
+        .and_then(|value| parse_bandwidth_limit(value).ok())
+        .and_then(|components| components.rate())
+}
+
+/// Builds the limiter that paces the transfer streams from the connection
+/// limiter (daemon `--bwlimit` merged with the module `bwlimit`) and the
+/// client's `--bwlimit`.
+///
+/// The smaller rate wins, so a client can tighten the daemon cap but never
+/// lift it - an unlimited client request leaves the module cap in force.
+///
+/// upstream: options.c:parse_arguments() - `if (daemon_bwlimit && (!bwlimit
+/// || bwlimit > daemon_bwlimit)) bwlimit = daemon_bwlimit;`
+fn transfer_bandwidth_limiter(
+    connection_limiter: Option<&BandwidthLimiter>,
+    client_args: &[String],
+) -> Option<BandwidthLimiter> {
+    let mut limiter = connection_limiter.cloned();
+    if let Some(rate) = client_bwlimit(client_args) {
+        let _ = BandwidthLimitComponents::new_with_flags(Some(rate), None, true, false)
+            .apply_to_limiter(&mut limiter);
+    }
+    limiter
+}
+
 /// Builds the server configuration from client arguments.
 ///
 /// Returns the configuration on success, or sends an error and returns `None`.
@@ -159,7 +234,14 @@ fn build_server_config(
             }
         }
     } else {
-        match resolve_sender_sources(std::path::Path::new(&module.path), client_args, &module.name) {
+        let module_path = std::path::Path::new(&module.path);
+        let sources = match &module.single_file {
+            Some(file_name) => {
+                resolve_single_file_source(module_path, client_args, &module.name, file_name)
+            }
+            None => resolve_sender_sources(module_path, client_args, &module.name),
+        };
+        match sources {
             Some(sources) => sources
                 .into_iter()
                 .map(|p| OsString::from(p.as_os_str()))
@@ -322,6 +404,26 @@ fn build_server_config(
                 }
             }
 
+            // The `uid map` / `gid map` directives rewrite the ownership of
+            // served files on the daemon's sender, so a downloading client sees
+            // the remapped ids and names. A receiving daemon leaves them unused.
+            // Parsed at module-use time like the chmod specs above.
+            match parse_daemon_id_maps(module) {
+                Ok((user, group)) => {
+                    cfg.daemon_user_mapping = user;
+                    cfg.daemon_group_mapping = group;
+                }
+                Err(err) => {
+                    let payload = format!("@ERROR: {err}");
+                    send_error(
+                        ctx.reader.get_mut(),
+                        ctx.limiter,
+                        &payload,
+                    )?;
+                    return Ok(None);
+                }
+            }
+
             // upstream: clientserver.c:997-998 - `munge_symlinks = lp_munge_symlinks(i)`
             // with `!use_chroot || module_dirlen` as the auto default. The bit is
             // purely daemon-config-driven (no client-side override) and travels
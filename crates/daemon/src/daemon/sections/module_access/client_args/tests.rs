@@ -34,6 +34,49 @@ mod daemon_chmod_spec_tests {
     }
 }
 
+#[cfg(test)]
+mod daemon_id_map_tests {
+    use super::{ModuleDefinition, ModuleRuntime, parse_daemon_id_maps};
+
+    fn module(uid_map: Option<&str>, gid_map: Option<&str>) -> ModuleRuntime {
+        ModuleRuntime::from(ModuleDefinition {
+            name: "data".to_owned(),
+            uid_map: uid_map.map(str::to_owned),
+            gid_map: gid_map.map(str::to_owned),
+            ..Default::default()
+        })
+    }
+
+    #[test]
+    fn parse_daemon_id_maps_returns_none_for_unset_directives() {
+        let (user, group) = parse_daemon_id_maps(&module(None, None)).expect("ok");
+        assert!(user.is_none());
+        assert!(group.is_none());
+    }
+
+    #[test]
+    fn parse_daemon_id_maps_accepts_usermap_syntax() {
+        let (user, group) =
+            parse_daemon_id_maps(&module(Some("1000:0,*:65534"), Some("*:0"))).expect("ok");
+        assert_eq!(user.expect("uid map").spec(), "1000:0,*:65534");
+        assert_eq!(group.expect("gid map").spec(), "*:0");
+    }
+
+    #[test]
+    fn parse_daemon_id_maps_surfaces_directive_name_on_error() {
+        let err = parse_daemon_id_maps(&module(None, Some("nocolon")))
+            .expect_err("malformed spec must error");
+        assert!(
+            err.contains("gid map"),
+            "error '{err}' must name the offending directive",
+        );
+        assert!(
+            err.contains("nocolon"),
+            "error '{err}' must include the offending spec text",
+        );
+    }
+}
+
 #[cfg(test)]
 mod iconv_charset_converter_tests {
     use super::resolve_module_charset_converter;
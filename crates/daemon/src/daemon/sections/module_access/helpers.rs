@@ -116,6 +116,19 @@ fn pid_file_error(path: &Path, error: io::Error) -> DaemonError {
     )
 }
 
+/// Creates a [`DaemonError`] for a PID file still locked by a running daemon.
+fn pid_file_in_use_error(path: &Path, pid: Option<u32>) -> DaemonError {
+    let owner = pid.map_or_else(|| "another daemon".to_owned(), |pid| format!("pid {pid}"));
+    DaemonError::new(
+        FEATURE_UNAVAILABLE_EXIT_CODE,
+        rsync_error!(
+            FEATURE_UNAVAILABLE_EXIT_CODE,
+            format!("pid file '{}' is in use by {}", path.display(), owner)
+        )
+        .with_role(Role::Daemon),
+    )
+}
+
 /// Creates a [`DaemonError`] for lock file open failures.
 #[cfg(test)]
 fn lock_file_error(path: &Path, error: io::Error) -> DaemonError {
@@ -138,6 +151,12 @@ fn log_message(log: &SharedLogSink, message: &Message) {
     }
 }
 
+/// Converts an elapsed duration to whole milliseconds for `duration_ms`
+/// fields, saturating instead of truncating on overflow.
+fn duration_millis(elapsed: Duration) -> u64 {
+    u64::try_from(elapsed.as_millis()).unwrap_or(u64::MAX)
+}
+
 /// Formats a host for logging, using the IP address as fallback.
 fn format_host(host: Option<&str>, fallback: IpAddr) -> String {
     host.map_or_else(|| fallback.to_string(), str::to_string)
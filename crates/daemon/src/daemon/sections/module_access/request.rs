@@ -26,7 +26,13 @@ struct ModuleRequestContext<'a> {
     module_peer_host: Option<&'a str>,
     request: &'a str,
     log_sink: Option<&'a SharedLogSink>,
+    /// Embedder observers; receive the per-connection `transfer` record and
+    /// metrics.
+    observers: &'a SessionObservers,
     messages: &'a LegacyMessageCache,
+    /// Whether the client's greeting offered a resume manifest
+    /// (oc-rsync extension, see `protocol::daemon_resume`).
+    resume_offered: bool,
     /// Early-input data sent by the client before the module name.
     ///
     /// upstream: clientserver.c:583-584 - the daemon writes `early_input` to
@@ -450,6 +456,7 @@ fn handle_authentication(
             if let Some(log) = ctx.log_sink {
                 log_module_auth_failure(log, ctx.effective_host(), ctx.peer_ip, ctx.request);
             }
+            ctx.observers.metrics(|metrics| metrics.session_error(SessionError::AuthFailed));
             // FSM: -> Closing on auth failure (session ends).
             ctx.conn_state = ctx
                 .conn_state
@@ -503,6 +510,7 @@ fn handle_module_denied(
     if let Some(log) = ctx.log_sink {
         log_module_denied(log, host, ctx.peer_ip, ctx.request);
     }
+    ctx.observers.metrics(|metrics| metrics.session_error(SessionError::AccessDenied));
     deny_module(
         ctx.reader.get_mut(),
         module,
@@ -533,13 +541,16 @@ fn respond_with_module_request(
     session_peer_host: Option<&str>,
     options: &[String],
     log_sink: Option<&SharedLogSink>,
+    observers: &SessionObservers,
     reverse_lookup: bool,
     messages: &LegacyMessageCache,
     negotiated_protocol: Option<ProtocolVersion>,
+    resume_offered: bool,
     early_input_data: Option<Vec<u8>>,
     conn_state: ConnectionState,
 ) -> io::Result<()> {
     let Some(module) = modules.iter().find(|module| module.name == request) else {
+        observers.metrics(|metrics| metrics.session_error(SessionError::UnknownModule));
         return handle_unknown_module(
             reader.get_mut(),
             limiter,
@@ -597,7 +608,9 @@ fn respond_with_module_request(
         module_peer_host,
         request,
         log_sink,
+        observers,
         messages,
+        resume_offered,
         early_input_data,
         conn_state,
     };
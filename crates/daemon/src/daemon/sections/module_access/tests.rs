@@ -575,6 +575,19 @@ mod module_access_tests {
         assert!(message.contains("/var/run/rsyncd.pid"));
     }
 
+    #[test]
+    fn pid_file_in_use_error_names_owner() {
+        let path = std::path::Path::new("/var/run/rsyncd.pid");
+        let err = pid_file_in_use_error(path, Some(4242));
+        assert_eq!(err.exit_code(), FEATURE_UNAVAILABLE_EXIT_CODE);
+        let message = format!("{:?}", err.message());
+        assert!(message.contains("/var/run/rsyncd.pid"));
+        assert!(message.contains("pid 4242"));
+
+        let unknown = format!("{:?}", pid_file_in_use_error(path, None).message());
+        assert!(unknown.contains("another daemon"));
+    }
+
     #[test]
     fn lock_file_error_creates_daemon_error_with_correct_code() {
         let path = std::path::Path::new("/var/lock/rsyncd.lock");
@@ -2887,6 +2900,77 @@ mod module_access_tests {
         assert_eq!(lossy, vec![r"C:\srv\upload/d1/d2/".to_owned()]);
     }
 
+    #[test]
+    fn single_file_module_reroots_at_parent_directory() {
+        let dir = tempfile::tempdir().expect("temp dir");
+        let file = dir.path().join("release.tar");
+        fs::write(&file, b"payload").expect("write file");
+        let definition = ModuleDefinition {
+            name: "release".to_owned(),
+            path: file,
+            ..ModuleDefinition::default()
+        };
+
+        let rooted = single_file_module(&definition).expect("file path re-roots");
+        assert_eq!(rooted.path, dir.path());
+        assert_eq!(
+            rooted.single_file.as_deref(),
+            Some(OsStr::new("release.tar"))
+        );
+        assert_eq!(rooted.name, "release");
+    }
+
+    #[test]
+    fn single_file_module_leaves_directory_and_missing_paths_alone() {
+        let dir = tempfile::tempdir().expect("temp dir");
+        let directory = ModuleDefinition {
+            path: dir.path().to_path_buf(),
+            ..ModuleDefinition::default()
+        };
+        assert!(single_file_module(&directory).is_none());
+
+        let missing = ModuleDefinition {
+            path: dir.path().join("absent"),
+            ..ModuleDefinition::default()
+        };
+        assert!(single_file_module(&missing).is_none());
+    }
+
+    #[test]
+    fn resolve_single_file_source_serves_file_for_bare_request() {
+        // No trailing slash: the sender splits at the last `/` and emits the
+        // file under its own name, not as a `.` transfer root.
+        let parent = std::path::Path::new("/srv/dist");
+        let file_name = OsStr::new("release.tar");
+        let expected = vec![std::path::PathBuf::from("/srv/dist/release.tar")];
+        for args in [
+            vec![],
+            vec![".".to_owned(), "release".to_owned()],
+            vec![".".to_owned(), "release/".to_owned()],
+            vec![".".to_owned(), "release/.".to_owned()],
+            vec![".".to_owned(), "release/release.tar".to_owned()],
+        ] {
+            assert_eq!(
+                resolve_single_file_source(parent, &args, "release", file_name),
+                Some(expected.clone()),
+                "args {args:?}"
+            );
+        }
+    }
+
+    #[test]
+    fn resolve_single_file_source_rejects_sibling_paths() {
+        let parent = std::path::Path::new("/srv/dist");
+        let file_name = OsStr::new("release.tar");
+        for tail in ["release/other.tar", "release/../etc/passwd", "release/sub/"] {
+            let args = vec![".".to_owned(), tail.to_owned()];
+            assert!(
+                resolve_single_file_source(parent, &args, "release", file_name).is_none(),
+                "sibling {tail} must not be served"
+            );
+        }
+    }
+
     #[cfg(target_os = "windows")]
     #[test]
     fn resolve_sender_sources_accepts_backslash_terminated_module_root_on_windows() {
@@ -3131,6 +3215,88 @@ mod module_access_tests {
         assert!(!cfg.deletion.ignore_errors);
     }
 
+    fn args(list: &[&str]) -> Vec<String> {
+        list.iter().map(|arg| (*arg).to_owned()).collect()
+    }
+
+    // upstream: clientserver.c:rsync_module() - the module `timeout` wins when
+    // it is tighter than the client's --timeout or the client sent none.
+    #[test]
+    fn effective_io_timeout_picks_smaller_non_zero_value() {
+        let secs = |n| NonZeroU64::new(n);
+        let client_60 = args(&["--server", "-vlogDtpr", "--timeout=60", ".", "mod/"]);
+        let client_none = args(&["--server", "-vlogDtpr", ".", "mod/"]);
+        let client_zero = args(&["--server", "--timeout=0", ".", "mod/"]);
+
+        assert_eq!(effective_io_timeout(secs(30), &client_60), secs(30));
+        assert_eq!(effective_io_timeout(secs(900), &client_60), secs(60));
+        assert_eq!(effective_io_timeout(None, &client_60), secs(60));
+        assert_eq!(effective_io_timeout(secs(30), &client_none), secs(30));
+        assert_eq!(effective_io_timeout(secs(30), &client_zero), secs(30));
+        assert_eq!(effective_io_timeout(None, &client_none), None);
+    }
+
+    #[test]
+    fn client_io_timeout_ignores_positional_args() {
+        let argv = args(&["--server", "--sender", ".", "mod/--timeout=5"]);
+        assert_eq!(client_io_timeout(&argv), None);
+    }
+
+    // upstream: options.c:parse_arguments() - the daemon cap bounds the
+    // client's --bwlimit, and a client sending none stays throttled.
+    #[test]
+    fn transfer_bandwidth_limiter_takes_smaller_rate() {
+        let rate = |n| NonZeroU64::new(n).unwrap();
+        let module_cap = BandwidthLimiter::new(rate(4096));
+        let client_1k = args(&["--server", "--bwlimit=1", ".", "mod/"]);
+        let client_64k = args(&["--server", "--bwlimit=64", ".", "mod/"]);
+        let client_none = args(&["--server", "-vlogDtpr", ".", "mod/"]);
+        let client_zero = args(&["--server", "--bwlimit=0", ".", "mod/"]);
+
+        let limit = |limiter: Option<BandwidthLimiter>| limiter.map(|l| l.limit_bytes());
+        assert_eq!(
+            limit(transfer_bandwidth_limiter(Some(&module_cap), &client_1k)),
+            Some(rate(1024))
+        );
+        assert_eq!(
+            limit(transfer_bandwidth_limiter(Some(&module_cap), &client_64k)),
+            Some(rate(4096))
+        );
+        assert_eq!(
+            limit(transfer_bandwidth_limiter(Some(&module_cap), &client_none)),
+            Some(rate(4096))
+        );
+        assert_eq!(
+            limit(transfer_bandwidth_limiter(Some(&module_cap), &client_zero)),
+            Some(rate(4096))
+        );
+        assert_eq!(
+            limit(transfer_bandwidth_limiter(None, &client_1k)),
+            Some(rate(1024))
+        );
+        assert!(transfer_bandwidth_limiter(None, &client_none).is_none());
+    }
+
+    #[test]
+    fn client_bwlimit_ignores_positional_args() {
+        let argv = args(&["--server", "--sender", ".", "mod/--bwlimit=5"]);
+        assert_eq!(client_bwlimit(&argv), None);
+    }
+
+    // upstream: flist.c:make_file() - `ignore nonreadable` makes the sender
+    // drop unreadable entries while building the file list.
+    #[test]
+    fn module_ignore_nonreadable_forces_file_selection_flag() {
+        let module = ModuleDefinition {
+            ignore_nonreadable: true,
+            ..Default::default()
+        };
+        let mut cfg = ServerConfig::default();
+        assert!(!cfg.file_selection.ignore_nonreadable);
+        apply_module_transfer_directives(&module, &mut cfg);
+        assert!(cfg.file_selection.ignore_nonreadable);
+    }
+
     // upstream: loadparm `open noatime` - the module directive makes the daemon
     // (as sender) open source files with O_NOATIME. Without wiring it into the
     // server config the directive was parsed but never enforced.
@@ -90,7 +90,7 @@ fn process_approved_module(
     // After overrides, expand %-variables (e.g. %MODULE%, %ADDR%) in path-type
     // fields using the connection's client address and hostname.
     // upstream: loadparm.c:lp_string() - variable substitution at access time.
-    let effective_module = {
+    let (effective_module, path_template) = {
         let mut definition = module.definition.clone();
         if !options.is_empty() {
             match apply_daemon_param_overrides(options, &mut definition) {
@@ -104,8 +104,9 @@ fn process_approved_module(
         }
         let client_addr = ctx.peer_ip.to_string();
         let client_host = ctx.effective_host().unwrap_or(&client_addr);
+        let path_template = definition.path.display().to_string();
         expand_module_vars(&mut definition, &client_addr, client_host);
-        ModuleRuntime::from(definition)
+        (ModuleRuntime::from(definition), path_template)
     };
     let module = &effective_module;
 
@@ -130,6 +131,41 @@ fn process_approved_module(
             None => return Ok(()),
         };
 
+    // A `path = /srv/%u` template resolves only now that the user is known.
+    // upstream: clientserver.c:rsync_module() - lp_path() is read after
+    // auth_server() has set RSYNC_USER_NAME.
+    let user_module;
+    let module = {
+        let client_addr = ctx.peer_ip.to_string();
+        let client_host = ctx.effective_host().unwrap_or(&client_addr);
+        match expand_module_path_for_user(
+            &path_template,
+            &module.name,
+            &client_addr,
+            client_host,
+            auth_user.as_deref(),
+        ) {
+            Some(path) => {
+                let mut definition = module.definition.clone();
+                definition.path = path;
+                user_module = ModuleRuntime::from(definition);
+                &user_module
+            }
+            None => module,
+        }
+    };
+
+    // A module whose path names a regular file serves just that file from
+    // its parent directory; see `single_file_module`.
+    let file_module;
+    let module = match single_file_module(module) {
+        Some(definition) => {
+            file_module = ModuleRuntime::from(definition);
+            &file_module
+        }
+        None => module,
+    };
+
     // Run early exec after authentication so the authenticated username
     // is available in the RSYNC_USER_NAME environment variable.
     // upstream: clientserver.c - early_exec() runs after auth completes.
@@ -154,7 +190,11 @@ fn process_approved_module(
                 // Early exec runs before client args are received.
                 client_args: &[],
             };
-            match run_early_exec(&expanded_command, &early_ctx) {
+            match run_early_exec(
+                &expanded_command,
+                &early_ctx,
+                ctx.early_input_data.as_deref(),
+            ) {
                 Ok(Ok(())) => {
                     if let Some(log) = ctx.log_sink {
                         let text = format!("early exec succeeded for module '{}'", ctx.request);
@@ -240,6 +280,17 @@ fn process_approved_module(
     // upstream: clientserver.c:1071 - emit `@RSYNCD: OK` now that chroot and the
     // privilege drop have succeeded; the client then switches to multiplexed
     // input and sends its argv, which we read next.
+    //
+    // oc-rsync extension: accept a resume offer just before the OK so the
+    // client knows to follow its argv with the manifest block.
+    if ctx.resume_offered {
+        write_limited(
+            ctx.reader.get_mut(),
+            ctx.limiter,
+            RESUME_ACCEPTED_LINE.as_bytes(),
+        )?;
+        write_limited(ctx.reader.get_mut(), ctx.limiter, b"\n")?;
+    }
     send_daemon_ok(ctx.reader.get_mut(), ctx.limiter, ctx.messages)?;
 
     let client_args = match read_and_log_client_args(ctx, negotiated_protocol)? {
@@ -247,6 +298,12 @@ fn process_approved_module(
         None => return Ok(()),
     };
 
+    let resume_entries = if ctx.resume_offered {
+        read_resume_manifest(ctx.reader, RESUME_MAX_ENTRIES)?
+    } else {
+        Vec::new()
+    };
+
     // upstream: clientserver.c:rsync_module() -> parse_arguments() applies the
     // module's `refuse options` list against the actual client argv after the
     // post-OK `read_args()` round-trip. The earlier check at the OPTION-line
@@ -307,7 +364,10 @@ fn process_approved_module(
     // user is refused writes to a `read only = no` module. The `write only`
     // check is unaffected: upstream's auth override only touches `read_only`.
     let role = determine_server_role(&client_args);
-    let effective_read_only = access_effective_read_only(module.read_only, auth_access_level);
+    // A single-file module has no directory to receive into, so it is
+    // read-only whatever the user's access level.
+    let effective_read_only = access_effective_read_only(module.read_only, auth_access_level)
+        || module.single_file.is_some();
     // upstream: clientserver.c:908-933 - the post-xfer parent waits for the
     // module child and runs `post-xfer exec` regardless of outcome. A refused
     // read-only push / write-only pull exits `RERR_SYNTAX` (1) in the child, so
@@ -448,6 +508,20 @@ fn process_approved_module(
         }
     };
 
+    // upstream: clientserver.c:rsync_module() - `if (module_dirlen ||
+    // (!use_chroot && !*lp_daemon_chroot(i))) sanitize_paths = 1;`. Without
+    // a chroot wall at the module root (or with one above an inner `/./`
+    // directory) nothing stops a symlinked directory from leading the
+    // sender outside the module, so confine it to the served directory.
+    let inner_split = privilege_outcome
+        .inner_module_path
+        .as_deref()
+        .is_some_and(|inner| inner != std::path::Path::new("/"));
+    if !privilege_outcome.chroot_applied || inner_split {
+        let served = &config_module.path;
+        config.sanitize_root = Some(served.canonicalize().unwrap_or_else(|_| served.clone()));
+    }
+
     // upstream: clientserver.c:rsync_module() - build daemon_filter_list from
     // module filter/exclude/include/exclude_from/include_from parameters.
     // These rules are enforced server-side regardless of client-sent filters.
@@ -469,6 +543,17 @@ fn process_approved_module(
         }
     }
 
+    // oc-rsync extension: on a pull, leave out the files the client already
+    // holds. A push has no sender-side file list to trim, so the manifest is
+    // read and dropped.
+    if matches!(role, ServerRole::Generator) && !resume_entries.is_empty() {
+        let mut skip = ResumeSkipList::new();
+        for entry in resume_entries {
+            skip.insert(entry.path, entry.digest);
+        }
+        config.resume_skip = skip;
+    }
+
     // LSM-CAP.3: drop every Linux capability not required by this module
     // before Landlock engages. The worker process inherits the resulting
     // capability set across the transfer pipeline; combined with Landlock
@@ -545,6 +630,14 @@ fn process_approved_module(
         }
     };
 
+    // The connection limiter already carries the daemon `--bwlimit` merged with
+    // the module `bwlimit`; the client's own `--bwlimit` can only tighten it.
+    // upstream: options.c:parse_arguments() - daemon_bwlimit caps bwlimit.
+    let mut streams = throttle_transfer_streams(
+        streams,
+        transfer_bandwidth_limiter(ctx.limiter.as_ref(), &client_args),
+    );
+
     // Extract host name before building structs that borrow ctx, so the
     // borrow is released before the FSM transition mutates ctx.conn_state.
     let host_name_owned = ctx.effective_host().map(str::to_owned);
@@ -658,8 +751,18 @@ fn process_approved_module(
         .transition(ConnectionState::Transferring)
         .map_err(transition_error)?;
 
-    let handshake =
-        build_handshake_result(ctx.reader, negotiated_protocol, client_args.clone(), module);
+    // The module deadline set at selection time governed the argv read; from
+    // here on the tighter of the module `timeout` and the client's --timeout
+    // applies to the socket and is what the engine advertises and keeps alive.
+    let io_timeout = effective_io_timeout(module.timeout, &client_args);
+    apply_io_timeout(ctx.reader.get_mut(), io_timeout)?;
+
+    let handshake = build_handshake_result(
+        ctx.reader,
+        negotiated_protocol,
+        client_args.clone(),
+        io_timeout,
+    );
     let final_protocol = handshake.protocol;
 
     let supports_tcp_shutdown = streams.supports_tcp_shutdown;
@@ -672,6 +775,7 @@ fn process_approved_module(
         role,
         final_protocol,
         module,
+        auth_user.as_deref(),
     );
 
     // #503: stop and join the background delta-drain thread before the TCP
@@ -813,6 +917,12 @@ fn process_approved_module(
                 }
             }
         }
+        // Unix socket sessions skip the TCP barrier above; a plain half-close
+        // still tells the peer our side of the goodbye is complete.
+        #[cfg(unix)]
+        if stream.unix_stream().is_some() {
+            let _ = stream.shutdown(std::net::Shutdown::Write);
+        }
 
         // Post-shutdown drain: now that our FIN is on the wire, wait for the
         // peer to observe it and close, consuming any last bytes so the final
@@ -203,6 +203,28 @@ fn validate_module_path(
     Ok(false)
 }
 
+/// Re-roots a module whose `path` names a regular file at the file's parent.
+///
+/// Returns the rewritten definition, with the file name recorded in
+/// `single_file`, or `None` for a directory (or missing) path so the caller
+/// keeps the module unchanged and [`validate_module_path`] reports a missing
+/// one. Serving the parent lets chroot, the Landlock allowlist and the
+/// sender's last-`/` split all treat the file like any named sub-path.
+fn single_file_module(definition: &ModuleDefinition) -> Option<ModuleDefinition> {
+    if !fs::metadata(&definition.path).is_ok_and(|meta| meta.is_file()) {
+        return None;
+    }
+    let file_name = definition.path.file_name()?.to_os_string();
+    let parent = match definition.path.parent() {
+        Some(parent) if !parent.as_os_str().is_empty() => parent.to_path_buf(),
+        _ => PathBuf::from("."),
+    };
+    let mut rooted = definition.clone();
+    rooted.path = parent;
+    rooted.single_file = Some(file_name);
+    Some(rooted)
+}
+
 /// Outcome of [`validate_client_paths_in_module`].
 ///
 /// `Rejected` is the daemon-error path: an `@ERROR` reply was already sent.
@@ -142,6 +142,52 @@ impl Write for ZeroCopyTcpWriter {
     }
 }
 
+/// Paces the transfer write stream through a [`BandwidthLimiter`].
+///
+/// Wraps whichever writer `setup_transfer_streams` produced so every byte the
+/// transfer engine sends - file list, deltas, and multiplexed messages -
+/// counts against the cap. Writes are split at
+/// [`BandwidthLimiter::recommended_read_size`] and registered after each chunk
+/// lands, the same pacing `write_limited` applies to the daemon's text lines.
+///
+/// upstream: io.c:writefd_unbuffered() - `if (bwlimit_writemax && len >
+/// bwlimit_writemax) len = bwlimit_writemax;` followed by
+/// `sleep_for_bwlimit(cnt)`.
+struct ThrottledWriter {
+    inner: Box<dyn Write + Send>,
+    limiter: BandwidthLimiter,
+}
+
+impl Write for ThrottledWriter {
+    fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
+        let chunk_len = self.limiter.recommended_read_size(buf.len());
+        let written = self.inner.write(&buf[..chunk_len])?;
+        let _ = self.limiter.register(written);
+        Ok(written)
+    }
+
+    fn flush(&mut self) -> io::Result<()> {
+        self.inner.flush()
+    }
+}
+
+/// Applies the effective transfer bandwidth cap to the write side of `streams`.
+///
+/// A `None` limiter leaves the writer untouched, so an unthrottled session
+/// keeps the exact same byte sink.
+fn throttle_transfer_streams(
+    mut streams: TransferStreams,
+    limiter: Option<BandwidthLimiter>,
+) -> TransferStreams {
+    if let Some(limiter) = limiter {
+        streams.write = Box::new(ThrottledWriter {
+            inner: streams.write,
+            limiter,
+        });
+    }
+    streams
+}
+
 /// `zero_copy_policy` opts the daemon-sender's socket write side into the
 /// io_uring `IORING_OP_SEND_ZC` transport when it is
 /// [`ZeroCopyPolicy::Enabled`](fast_io::ZeroCopyPolicy::Enabled) (the client
@@ -185,6 +231,33 @@ fn setup_transfer_streams(
         }));
     }
 
+    #[cfg(unix)]
+    if let Some(unix) = stream.unix_stream() {
+        // Unix socket sessions clone the socket like TCP and keep the #503
+        // drain thread: a single full-duplex socket can wedge the same way.
+        // Zero-copy `SEND_ZC` is TCP-only, so the write clone is used as-is.
+        let (read_stream, write_stream) = match (unix.try_clone(), unix.try_clone()) {
+            (Ok(read), Ok(write)) => (read, write),
+            (Err(err), _) | (_, Err(err)) => {
+                let payload = format!("@ERROR: failed to clone stream: {err}");
+                send_error(ctx.reader.get_mut(), ctx.limiter, &payload)?;
+                return Ok(None);
+            }
+        };
+        let (read, drain_handle): (Box<dyn Read + Send>, _) = if arm_drain {
+            let (draining_reader, drain_handle) = DrainingReader::new(read_stream);
+            (Box::new(draining_reader), Some(drain_handle))
+        } else {
+            (Box::new(read_stream), None)
+        };
+        return Ok(Some(TransferStreams {
+            read,
+            write: Box::new(write_stream),
+            supports_tcp_shutdown: true,
+            drain_handle,
+        }));
+    }
+
     let tcp = stream
         .tcp_stream()
         .expect("non-stdio stream has tcp_stream");
@@ -258,7 +331,7 @@ fn build_handshake_result(
     reader: &BufReader<DaemonStream>,
     negotiated_protocol: Option<ProtocolVersion>,
     client_args: Vec<String>,
-    module: &ModuleRuntime,
+    io_timeout: Option<NonZeroU64>,
 ) -> HandshakeResult {
     let final_protocol = negotiated_protocol.unwrap_or(ProtocolVersion::V30);
     let buffered_data = reader.buffer().to_vec();
@@ -268,7 +341,7 @@ fn build_handshake_result(
         buffered: buffered_data,
         compat_exchanged: false,
         client_args: Some(client_args),
-        io_timeout: module.timeout.map(|t| t.get()),
+        io_timeout: io_timeout.map(NonZeroU64::get),
         negotiated_algorithms: None,
         compat_flags: None,
         checksum_seed: 0,
@@ -281,13 +354,14 @@ fn run_daemon_transfer(
     handshake: HandshakeResult,
     read_stream: &mut dyn Read,
     write_stream: &mut dyn Write,
+    progress: Option<&mut dyn TransferProgressCallback>,
 ) -> ServerResult {
     run_server_with_handshake(
         config,
         handshake,
         read_stream,
         write_stream,
-        None,
+        progress,
         None,
         None,
     )
@@ -296,10 +370,10 @@ fn run_daemon_transfer(
 /// Executes the server transfer and logs the result.
 ///
 /// When the module has `transfer_logging` enabled and a log sink is available,
-/// a per-transfer log line is emitted using the module's configured format
-/// string (or `DEFAULT_LOG_FORMAT` as fallback).
+/// a [`TransferLogWriter`] logs one line per transferred file using the
+/// module's configured format string (or `DEFAULT_LOG_FORMAT` as fallback).
 ///
-/// Returns the transfer exit status: `0` on success, non-zero on failure.
+/// Returns the session's exit status as derived by [`transfer_exit_status`].
 fn execute_transfer(
     ctx: &ModuleRequestContext<'_>,
     config: ServerConfig,
@@ -309,6 +383,7 @@ fn execute_transfer(
     role: ServerRole,
     final_protocol: ProtocolVersion,
     module: &ModuleRuntime,
+    auth_user: Option<&str>,
 ) -> i32 {
     if let Some(log) = ctx.log_sink {
         let text = format!(
@@ -328,45 +403,35 @@ fn execute_transfer(
     // exchanges (NDX_DONE, stats, goodbye) when TCP backpressure occurs,
     // causing 10-second hangs. Standard I/O handles partial writes correctly,
     // matching upstream rsync's socket I/O model.
-    let result = run_daemon_transfer(config, handshake, read_stream, write_stream);
+    let mut transfer_log = match ctx.log_sink {
+        Some(log) if module.transfer_logging => Some(TransferLogWriter {
+            format: effective_log_format(module),
+            log_sink: log,
+            operation: match role {
+                ServerRole::Generator => TransferOperation::Send,
+                ServerRole::Receiver => TransferOperation::Recv,
+            },
+            hostname: ctx.effective_host().unwrap_or("unknown"),
+            remote_addr: ctx.peer_ip.to_string(),
+            module_name: ctx.request,
+            username: auth_user.unwrap_or(""),
+            module_path: module.path.display().to_string(),
+            pid: std::process::id(),
+        }),
+        _ => None,
+    };
+    let progress = transfer_log
+        .as_mut()
+        .map(|writer| writer as &mut dyn TransferProgressCallback);
+
+    let started = Instant::now();
+    let result = run_daemon_transfer(config, handshake, read_stream, write_stream, progress);
+    report_transfer(ctx, role, final_protocol, &result, started.elapsed());
+    let exit_status = transfer_exit_status(&result);
 
     match result {
         Ok(_server_stats) => {
             if let Some(log) = ctx.log_sink {
-                if module.transfer_logging {
-                    let operation = match role {
-                        ServerRole::Generator => TransferOperation::Send,
-                        ServerRole::Receiver => TransferOperation::Recv,
-                    };
-                    let addr_str = ctx.peer_ip.to_string();
-                    let path_str = module.path.display().to_string();
-                    let pid = std::process::id();
-                    let now = SystemTime::now()
-                        .duration_since(UNIX_EPOCH)
-                        .unwrap_or_default();
-                    let secs = now.as_secs();
-                    let timestamp = format_daemon_timestamp(secs);
-
-                    let log_ctx = LogFormatContext {
-                        operation,
-                        hostname: ctx.effective_host().unwrap_or("unknown"),
-                        remote_addr: &addr_str,
-                        module_name: ctx.request,
-                        username: "",
-                        filename: "",
-                        file_length: 0,
-                        pid,
-                        module_path: &path_str,
-                        timestamp: &timestamp,
-                        bytes_transferred: 0,
-                        bytes_checksumed: 0,
-                        itemize_string: "",
-                    };
-
-                    let fmt = effective_log_format(module);
-                    log_transfer(fmt, &log_ctx, log);
-                }
-
                 let text = format!(
                     "transfer to {} ({}): module={} status=success",
                     ctx.effective_host().unwrap_or("unknown"),
@@ -376,7 +441,6 @@ fn execute_transfer(
                 let message = rsync_info!(text).with_role(Role::Daemon);
                 log_message(log, &message);
             }
-            0
         }
         Err(err) => {
             if let Some(log) = ctx.log_sink {
@@ -390,9 +454,124 @@ fn execute_transfer(
                 let message = rsync_error!(1, text).with_role(Role::Daemon);
                 log_message(log, &message);
             }
-            1
         }
     }
+    exit_status
+}
+
+/// Derives the exit status a module session ends with, which `post-xfer exec`
+/// sees as `RSYNC_EXIT_STATUS`.
+///
+/// A completed transfer still reports the file-level failures it accumulated:
+/// the `io_error` bits map to 23, 24 or 25, and `MSG_ERROR` frames from the
+/// client's sender count as a partial transfer. An aborted transfer takes the
+/// code the peer sent with `MSG_ERROR_EXIT`, or else the code its I/O error
+/// classifies as.
+///
+/// upstream: clientserver.c:908-933 - the post-xfer parent runs the hook with
+/// the module child's exit status; log.c:log_exit() derives it from
+/// `io_error`, and io.c:1663-1701 exits with a peer's `MSG_ERROR_EXIT` code.
+fn transfer_exit_status(result: &ServerResult) -> i32 {
+    match result {
+        Ok(ServerStats::Receiver(stats)) => {
+            let code = io_error_flags::to_exit_code(stats.io_error);
+            if code == 0 && stats.error_count > 0 {
+                ExitCode::PartialTransfer.as_i32()
+            } else {
+                code
+            }
+        }
+        Ok(ServerStats::Generator(stats)) => io_error_flags::to_exit_code(stats.io_error),
+        Err(error) => {
+            remote_exit_code(error).unwrap_or_else(|| ExitCode::from_io_error(error).as_i32())
+        }
+    }
+}
+
+/// Returns the exit code carried by a [`RemoteExitError`] in `error`'s source
+/// chain, if the transfer ended because the peer sent `MSG_ERROR_EXIT`.
+fn remote_exit_code(error: &io::Error) -> Option<i32> {
+    let mut source: Option<&(dyn std::error::Error + 'static)> = Some(error.get_ref()?);
+    while let Some(err) = source {
+        if let Some(remote) = err.downcast_ref::<RemoteExitError>() {
+            return Some(remote.code);
+        }
+        source = err.source();
+    }
+    None
+}
+
+/// Reports a finished module transfer to the embedder observers: the
+/// structured `transfer` event and [`DaemonMetrics::transfer_finished`].
+///
+/// Byte counts are the wire totals from the server stats, seen from the
+/// daemon: `bytes_read` is what the client sent, `bytes_written` what the
+/// daemon sent back (upstream `stats.total_read` / `stats.total_written`).
+/// A failed transfer reports zero bytes because the engine returns no stats.
+fn report_transfer(
+    ctx: &ModuleRequestContext<'_>,
+    role: ServerRole,
+    protocol: ProtocolVersion,
+    result: &ServerResult,
+    elapsed: Duration,
+) {
+    let operation = match role {
+        ServerRole::Generator => TransferOperation::Send,
+        ServerRole::Receiver => TransferOperation::Recv,
+    };
+    let (bytes_read, bytes_written, files) = match result {
+        Ok(ServerStats::Receiver(stats)) => (
+            stats.bytes_received,
+            stats.bytes_sent,
+            stats.files_transferred as u64,
+        ),
+        Ok(ServerStats::Generator(stats)) => (
+            stats.bytes_read,
+            stats.bytes_sent,
+            stats.files_transferred as u64,
+        ),
+        Err(_) => (0, 0, 0),
+    };
+
+    ctx.observers.log(|| {
+        let record = LogRecord::new(
+            if result.is_ok() {
+                LogLevel::Info
+            } else {
+                LogLevel::Error
+            },
+            "transfer",
+        )
+        .with("module", ctx.request)
+        .with("peer", ctx.peer_ip.to_string())
+        .with_opt("host", ctx.effective_host())
+        .with("operation", operation.as_str())
+        .with("protocol", protocol.as_u8())
+        .with("bytes_read", bytes_read)
+        .with("bytes_written", bytes_written)
+        .with("duration_ms", duration_millis(elapsed));
+        match result {
+            Ok(_) => record.with("status", "success"),
+            Err(err) => record
+                .with("status", "error")
+                .with("error", err.to_string()),
+        }
+    });
+
+    ctx.observers.metrics(|metrics| {
+        metrics.transfer_finished(&TransferMetrics {
+            module: ctx.request,
+            operation: operation.as_str(),
+            bytes_received: bytes_read,
+            bytes_sent: bytes_written,
+            files,
+            duration: elapsed,
+            success: result.is_ok(),
+        });
+        if result.is_err() {
+            metrics.session_error(SessionError::TransferFailed);
+        }
+    });
 }
 
 #[cfg(test)]
@@ -433,3 +612,53 @@ mod delta_drain_gate_tests {
         );
     }
 }
+
+#[cfg(test)]
+mod transfer_exit_status_tests {
+    use super::{RemoteExitError, ServerStats, io, io_error_flags, transfer_exit_status};
+    use core::server::{GeneratorStats, TransferStats};
+
+    #[test]
+    fn clean_transfer_exits_zero() {
+        let result = Ok(ServerStats::Generator(GeneratorStats::default()));
+        assert_eq!(transfer_exit_status(&result), 0);
+    }
+
+    #[test]
+    fn io_error_bits_map_to_partial_codes() {
+        let stats = GeneratorStats {
+            io_error: io_error_flags::IOERR_VANISHED,
+            ..GeneratorStats::default()
+        };
+        assert_eq!(transfer_exit_status(&Ok(ServerStats::Generator(stats))), 24);
+
+        let stats = TransferStats {
+            io_error: io_error_flags::IOERR_GENERAL,
+            ..TransferStats::default()
+        };
+        assert_eq!(transfer_exit_status(&Ok(ServerStats::Receiver(stats))), 23);
+    }
+
+    #[test]
+    fn sender_error_messages_count_as_partial() {
+        let stats = TransferStats {
+            error_count: 2,
+            ..TransferStats::default()
+        };
+        assert_eq!(transfer_exit_status(&Ok(ServerStats::Receiver(stats))), 23);
+    }
+
+    #[test]
+    fn aborted_transfer_uses_peer_exit_code() {
+        let error = io::Error::other(RemoteExitError { code: 20 });
+        assert_eq!(transfer_exit_status(&Err(error)), 20);
+    }
+
+    #[test]
+    fn aborted_transfer_classifies_io_error() {
+        let error = io::Error::new(io::ErrorKind::TimedOut, "idle");
+        assert_eq!(transfer_exit_status(&Err(error)), 30);
+        let error = io::Error::new(io::ErrorKind::UnexpectedEof, "closed");
+        assert_eq!(transfer_exit_status(&Err(error)), 12);
+    }
+}
@@ -46,6 +46,8 @@ struct ModuleDefinitionBuilder {
     name_converter: Option<Option<String>>,
     temp_dir: Option<Option<String>>,
     charset: Option<Option<String>>,
+    uid_map: Option<Option<String>>,
+    gid_map: Option<Option<String>>,
     forward_lookup: Option<bool>,
     strict_modes: Option<bool>,
     exclude_from: Option<PathBuf>,
@@ -112,6 +114,8 @@ impl ModuleDefinitionBuilder {
             name_converter: None,
             temp_dir: None,
             charset: None,
+            uid_map: None,
+            gid_map: None,
             forward_lookup: None,
             strict_modes: None,
             exclude_from: None,
@@ -206,6 +206,8 @@ impl ModuleDefinitionBuilder {
             name_converter: self.name_converter.unwrap_or_else(|| defaults.name_converter.clone()),
             temp_dir: self.temp_dir.unwrap_or_else(|| defaults.temp_dir.clone()),
             charset: self.charset.unwrap_or_else(|| defaults.charset.clone()),
+            uid_map: self.uid_map.flatten(),
+            gid_map: self.gid_map.flatten(),
             forward_lookup: self.forward_lookup.or(defaults.forward_lookup).unwrap_or(true),
             strict_modes: self.strict_modes.or(defaults.strict_modes).unwrap_or(true),
             exclude_from: self.exclude_from.or_else(|| defaults.exclude_from.clone()),
@@ -225,6 +227,7 @@ impl ModuleDefinitionBuilder {
             syslog_facility: self
                 .syslog_facility
                 .or_else(|| defaults.syslog_facility.clone()),
+            single_file: None,
             filter,
             exclude,
             include,
@@ -683,6 +683,48 @@ impl ModuleDefinitionBuilder {
         Ok(())
     }
 
+    fn set_uid_map(
+        &mut self,
+        uid_map: Option<String>,
+        config_path: &Path,
+        line: usize,
+    ) -> Result<(), DaemonError> {
+        if self.uid_map.is_some() {
+            return Err(config_parse_error(
+                config_path,
+                line,
+                format!(
+                    "duplicate 'uid map' directive in module '{}'",
+                    self.name
+                ),
+            ));
+        }
+
+        self.uid_map = Some(uid_map);
+        Ok(())
+    }
+
+    fn set_gid_map(
+        &mut self,
+        gid_map: Option<String>,
+        config_path: &Path,
+        line: usize,
+    ) -> Result<(), DaemonError> {
+        if self.gid_map.is_some() {
+            return Err(config_parse_error(
+                config_path,
+                line,
+                format!(
+                    "duplicate 'gid map' directive in module '{}'",
+                    self.name
+                ),
+            ));
+        }
+
+        self.gid_map = Some(gid_map);
+        Ok(())
+    }
+
     fn set_forward_lookup(
         &mut self,
         forward_lookup: bool,
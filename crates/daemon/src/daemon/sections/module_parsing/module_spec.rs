@@ -7,12 +7,17 @@
 // `clientserver.c` per-module config handling.
 
 fn apply_module_timeout(stream: &DaemonStream, module: &ModuleDefinition) -> io::Result<()> {
-    if let Some(timeout) = module.timeout {
+    apply_io_timeout(stream, module.timeout)
+}
+
+/// Sets both socket deadlines to `timeout`, or clears them when `None`.
+fn apply_io_timeout(stream: &DaemonStream, timeout: Option<NonZeroU64>) -> io::Result<()> {
+    if let Some(timeout) = timeout {
         let duration = Duration::from_secs(timeout.get());
         stream.set_read_timeout(Some(duration))?;
         stream.set_write_timeout(Some(duration))?;
     } else {
-        // When the module sets no `timeout`, the data phase is untimed, matching
+        // When no `timeout` applies, the data phase is untimed, matching
         // upstream's `io_timeout = 0` default (options.c:102; io.c:179
         // short-circuits the timeout check when unset). Clearing both directions
         // is defensive: it guarantees the accepted socket carries no inherited
@@ -35,22 +40,61 @@ fn parse_port(value: &OsString) -> Result<u16, DaemonError> {
         .map_err(|_| config_error(format!("invalid value for --port: '{text}'")))
 }
 
-fn parse_bind_address(value: &OsString) -> Result<IpAddr, DaemonError> {
+/// Parses a listen address, returning it with its IPv6 scope id.
+///
+/// A link-local literal such as `fe80::1%eth0` names the interface it lives
+/// on; the zone is resolved to the scope id the socket is bound with, which is
+/// zero for every other address.
+// upstream: socket.c:open_socket_in() hands the address to getaddrinfo(),
+// whose sockaddr_in6 carries the zone's scope id into bind().
+fn parse_bind_address(value: &OsString) -> Result<(IpAddr, u32), DaemonError> {
     let text = value.to_string_lossy();
     let trimmed = text.trim();
     let candidate = trimmed
         .strip_prefix('[')
         .and_then(|inner| inner.strip_suffix(']'))
         .unwrap_or(trimmed);
+    let invalid = || config_error(format!("invalid bind address '{text}'"));
 
     if let Ok(address) = candidate.parse::<IpAddr>() {
-        return Ok(address);
+        return Ok((address, 0));
+    }
+
+    if let Some((address, _zone)) = candidate.split_once('%')
+        && address.parse::<Ipv6Addr>().is_ok()
+    {
+        return match (candidate, 0)
+            .to_socket_addrs()
+            .map_err(|_| invalid())?
+            .next()
+        {
+            Some(SocketAddr::V6(scoped)) => Ok((IpAddr::V6(*scoped.ip()), scoped.scope_id())),
+            _ => Err(invalid()),
+        };
     }
 
     lookup_host(candidate)
-        .map_err(|_| config_error(format!("invalid bind address '{text}'")))?
+        .map_err(|_| invalid())?
         .next()
-        .ok_or_else(|| config_error(format!("invalid bind address '{text}'")))
+        .map(|address| (address, 0))
+        .ok_or_else(invalid)
+}
+
+
+/// Extracts the socket path from a `unix:PATH` listen address.
+///
+/// Returns `Ok(None)` when `value` is not a `unix:` address so the caller
+/// falls back to [`parse_bind_address`]. This is an oc-rsync extension;
+/// upstream only listens on TCP.
+fn parse_unix_socket_address(value: &OsString) -> Result<Option<PathBuf>, DaemonError> {
+    let text = value.to_string_lossy();
+    let Some(path) = text.trim().strip_prefix("unix:") else {
+        return Ok(None);
+    };
+    if path.is_empty() {
+        return Err(config_error(format!("invalid bind address '{text}'")));
+    }
+    Ok(Some(PathBuf::from(path)))
 }
 
 fn parse_max_sessions(value: &OsString) -> Result<NonZeroUsize, DaemonError> {
@@ -146,6 +190,8 @@ fn parse_module_definition(
         name_converter: None,
         temp_dir: None,
         charset: None,
+        uid_map: None,
+        gid_map: None,
         forward_lookup: true,
         strict_modes: true,
         exclude_from: None,
@@ -155,6 +201,7 @@ fn parse_module_definition(
         lock_file: None,
         syslog_tag: None,
         syslog_facility: None,
+        single_file: None,
         filter: Vec::new(),
         exclude: Vec::new(),
         include: Vec::new(),
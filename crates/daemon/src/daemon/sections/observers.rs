@@ -0,0 +1,39 @@
+/// Embedder-installed observers carried from [`DaemonConfig`] into every
+/// connection.
+///
+/// Bundles the structured [`crate::DaemonLogger`] and the
+/// [`crate::DaemonMetrics`] sink so the accept loops and session handlers
+/// thread one value instead of one per hook. Both are optional; the default
+/// carries neither and every call below is a no-op.
+#[derive(Clone, Debug, Default)]
+struct SessionObservers {
+    logger: Option<SharedDaemonLogger>,
+    metrics: Option<SharedDaemonMetrics>,
+}
+
+impl SessionObservers {
+    /// Captures the observers installed on `config`.
+    fn from_config(config: &DaemonConfig) -> Self {
+        Self {
+            logger: config.logger(),
+            metrics: config.metrics(),
+        }
+    }
+
+    /// Hands a structured event to the installed logger, if any.
+    ///
+    /// `build` only runs when a logger is installed, so connections served
+    /// without one pay nothing for record construction.
+    fn log(&self, build: impl FnOnce() -> LogRecord) {
+        if let Some(logger) = self.logger.as_ref() {
+            logger.log(&build());
+        }
+    }
+
+    /// Runs `record` against the installed metrics sink, if any.
+    fn metrics(&self, record: impl FnOnce(&dyn DaemonMetrics)) {
+        if let Some(metrics) = self.metrics.as_deref() {
+            record(metrics);
+        }
+    }
+}
@@ -16,10 +16,16 @@ include!("server_runtime/reload.rs");
 
 include!("server_runtime/connection.rs");
 
+include!("server_runtime/connection_rate.rs");
+
 include!("server_runtime/connection_context.rs");
 
 include!("server_runtime/accept_engine.rs");
 
+include!("server_runtime/unix_listener.rs");
+
+include!("server_runtime/tls.rs");
+
 include!("server_runtime/accept_loop.rs");
 
 #[cfg(test)]
@@ -33,6 +33,9 @@ trait AcceptEngine {
 enum AcceptOutcome {
     /// A client connection was accepted (stream already set to blocking).
     Connection(TcpStream, SocketAddr),
+    /// A client connected to the Unix domain socket listener.
+    #[cfg(unix)]
+    UnixConnection(UnixStream),
     /// No connection was ready within the poll interval. The engine has
     /// already waited the appropriate amount, so the caller must re-check
     /// signal flags and poll again without adding its own sleep.
@@ -582,6 +585,12 @@ fn run_accept_loop(
                     break;
                 }
             }
+            #[cfg(unix)]
+            AcceptOutcome::UnixConnection(stream) => {
+                if handle_accepted_unix_connection(stream, state) {
+                    break;
+                }
+            }
             AcceptOutcome::Idle => continue,
             AcceptOutcome::Closed => break,
         }
@@ -1,4 +1,5 @@
-/// Accepts TCP connections and spawns a thread per session.
+/// Accepts TCP connections, or Unix socket connections for
+/// `--address unix:PATH`, and spawns a thread per session.
 ///
 /// Unlike upstream rsync which forks a child process per connection
 /// (giving each session its own address space), this function uses
@@ -12,6 +13,8 @@ fn serve_connections(
     options: RuntimeOptions,
     external_signal_flags: Option<platform::signal::SignalFlags>,
     pre_bound_listener: Option<TcpListener>,
+    observers: SessionObservers,
+    shutdown_handle: Option<ShutdownHandle>,
 ) -> Result<(), DaemonError> {
     // Use externally injected signal flags (from the Windows Service dispatcher)
     // when available, otherwise register platform signal handlers so SIGPIPE is
@@ -36,10 +39,12 @@ fn serve_connections(
     let detach = options.detach();
     let listen_backlog = options.listen_backlog();
     let acceptor_threads = options.acceptor_threads();
+    let max_connection_rate = options.max_connection_rate();
     let socket_options_str = options.socket_options().map(str::to_string);
     let tcp_fastopen_mode = options.tcp_fastopen();
     let RuntimeOptions {
         bind_address,
+        bind_scope_id,
         port,
         max_sessions,
         max_connections,
@@ -54,13 +59,17 @@ fn serve_connections(
         address_family,
         dual_stack,
         bind_address_overridden,
+        unix_socket,
         config_path,
+        dparams,
         syslog_facility,
         syslog_tag,
         daemon_uid,
         daemon_gid,
         daemon_chroot,
         proxy_protocol,
+        tls_cert_file,
+        tls_key_file,
         ..
     } = options;
 
@@ -192,6 +201,37 @@ fn serve_connections(
         Vec::new()
     };
 
+    // oc-rsync extension: the TLS identity is loaded before binding (and
+    // before any daemon chroot hides the files) so a bad certificate or key
+    // fails startup instead of every handshake.
+    #[cfg(all(unix, feature = "tls"))]
+    let tls_server = load_tls_server(
+        tls_cert_file.as_deref(),
+        tls_key_file.as_deref(),
+        proxy_protocol,
+        unix_socket.is_some(),
+    )?;
+    #[cfg(not(all(unix, feature = "tls")))]
+    load_tls_server(
+        tls_cert_file.as_deref(),
+        tls_key_file.as_deref(),
+        proxy_protocol,
+        unix_socket.is_some(),
+    )?;
+
+    // oc-rsync extension: `--address unix:PATH` replaces the TCP listeners
+    // with one Unix domain socket. It is bound here, at the same point as
+    // TCP, so startup errors reach stderr before detaching.
+    #[cfg(not(unix))]
+    if let Some(path) = unix_socket.as_deref() {
+        return Err(unix_socket_unsupported_error(path));
+    }
+    #[cfg(unix)]
+    let unix_listener = match unix_socket.clone() {
+        Some(path) => Some(UnixSocketListener::bind(path)?),
+        None => None,
+    };
+
     // When a pre-bound listener is injected (test infrastructure), use it
     // directly - skipping the bind step eliminates the TOCTOU race between
     // port allocation and daemon bind. `listeners` is later moved into the
@@ -199,7 +239,10 @@ fn serve_connections(
     let listeners: Vec<TcpListener>;
     let bound_addresses: Vec<SocketAddr>;
 
-    if let Some(listener) = pre_bound_listener {
+    if unix_socket.is_some() {
+        listeners = Vec::new();
+        bound_addresses = Vec::new();
+    } else if let Some(listener) = pre_bound_listener {
         let local_addr = listener
             .local_addr()
             .unwrap_or_else(|_| SocketAddr::new(IpAddr::V4(Ipv4Addr::LOCALHOST), port));
@@ -225,6 +268,7 @@ fn serve_connections(
         match bind_listeners_per_family(
             &bind_addresses,
             port,
+            bind_scope_id,
             backlog,
             tcp_fastopen_mode,
             acceptor_threads,
@@ -236,7 +280,7 @@ fn serve_connections(
                 bound_addresses = bound_local_addrs;
             }
             Err(error) => {
-                let requested_addr = SocketAddr::new(bind_addresses[0], port);
+                let requested_addr = listen_socket_addr(bind_addresses[0], port, bind_scope_id);
                 return Err(bind_error(requested_addr, error));
             }
         }
@@ -326,7 +370,9 @@ fn serve_connections(
     }
 
     let notifier = systemd::ServiceNotifier::new();
-    let ready_status = if bound_addresses.len() == 1 {
+    let ready_status = if let Some(path) = unix_socket.as_deref() {
+        format!("Listening on unix:{}", path.display())
+    } else if bound_addresses.len() == 1 {
         format!("Listening on {}", bound_addresses[0])
     } else {
         let addrs: Vec<String> = bound_addresses.iter().map(ToString::to_string).collect();
@@ -337,9 +383,13 @@ fn serve_connections(
     }
 
     if let Some(log) = log_sink.as_ref() {
-        let text = format!(
-            "rsyncd version {version} starting, listening on port {port}"
-        );
+        let text = match unix_socket.as_deref() {
+            Some(path) => format!(
+                "rsyncd version {version} starting, listening on unix:{}",
+                path.display()
+            ),
+            None => format!("rsyncd version {version} starting, listening on port {port}"),
+        };
         let message = rsync_info!(text).with_role(Role::Daemon);
         log_message(log, &message);
     }
@@ -354,23 +404,38 @@ fn serve_connections(
         max_sessions: max_sessions.map(NonZeroUsize::get),
         max_connections: max_connections.map(NonZeroUsize::get),
         config_path: &config_path,
+        dparams: &dparams,
         connection_limiter: &connection_limiter,
         modules,
         motd_lines,
         log_sink: &log_sink,
+        observers,
+        shutdown_handle,
         notifier: &notifier,
         client_socket_options,
         bandwidth_limit,
         bandwidth_burst,
         reverse_lookup,
         proxy_protocol,
+        connection_rate: max_connection_rate.map(ConnectionRateLimiter::new),
+        #[cfg(all(unix, feature = "tls"))]
+        tls_server,
     };
 
     // Select the accept engine once from the bound listener topology, then run
     // the shared accept loop. The engine hides the readiness mechanism
     // (non-blocking accept vs acceptor-thread fan-in) behind a uniform poll.
+    #[cfg(unix)]
+    let mut engine: Box<dyn AcceptEngine> = match unix_listener {
+        Some(listener) => Box::new(UnixListenerEngine::new(listener, log_sink.clone())?),
+        None => build_accept_engine(listeners, &bound_addresses, &state)?,
+    };
+    #[cfg(not(unix))]
     let mut engine = build_accept_engine(listeners, &bound_addresses, &state)?;
     run_accept_loop(engine.as_mut(), &mut state)?;
+    // Close the listening sockets before draining so clients connecting
+    // during a graceful exit are refused instead of queueing in the backlog.
+    drop(engine);
 
     let result = drain_workers(&mut state.workers);
 
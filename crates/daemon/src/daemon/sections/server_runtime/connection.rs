@@ -18,16 +18,30 @@ struct AcceptLoopState<'a> {
     /// the same behaviour at the daemon level.
     max_connections: Option<usize>,
     config_path: &'a Option<PathBuf>,
+    /// `--dparam` overrides re-applied when SIGHUP reloads `config_path`.
+    dparams: &'a [String],
     connection_limiter: &'a Option<Arc<ConnectionLimiter>>,
     modules: Arc<Vec<ModuleRuntime>>,
     motd_lines: Arc<Vec<String>>,
     log_sink: &'a Option<SharedLogSink>,
+    /// Embedder observers (logger, metrics) shared with every connection
+    /// worker.
+    observers: SessionObservers,
+    /// Embedder shutdown request, treated like SIGUSR1.
+    shutdown_handle: Option<ShutdownHandle>,
     notifier: &'a systemd::ServiceNotifier,
     client_socket_options: Arc<Vec<SocketOption>>,
     bandwidth_limit: Option<NonZeroU64>,
     bandwidth_burst: Option<NonZeroU64>,
     reverse_lookup: bool,
     proxy_protocol: bool,
+    /// Per-source-IP token buckets for `max connection rate`; `None` when
+    /// the limit is off.
+    connection_rate: Option<ConnectionRateLimiter>,
+    /// TLS identity from `tls cert file` / `tls key file`; when set, every
+    /// accepted TCP connection is terminated through [`spawn_tls_relay`].
+    #[cfg(all(unix, feature = "tls"))]
+    tls_server: Option<Arc<core::io::tls::TlsServer>>,
 }
 
 /// Checks signal flags and performs maintenance tasks between accept iterations.
@@ -50,11 +64,21 @@ fn check_signals_and_maintain(
     }
 
     // upstream: main.c - SIGUSR1 stops accepting new connections
-    // and exits after active transfers drain.
-    if state.signal_flags.graceful_exit.load(Ordering::Relaxed) {
+    // and exits after active transfers drain. An embedder's
+    // `ShutdownHandle::shutdown` takes the same path.
+    let shutdown_requested = state
+        .shutdown_handle
+        .as_ref()
+        .is_some_and(ShutdownHandle::is_requested);
+    if shutdown_requested || state.signal_flags.graceful_exit.load(Ordering::Relaxed) {
         if let Some(log) = state.log_sink.as_ref() {
+            let trigger = if shutdown_requested {
+                "shutdown requested"
+            } else {
+                "received SIGUSR1"
+            };
             let text = format!(
-                "received SIGUSR1, draining {} active connection(s) before exit",
+                "{trigger}, draining {} active connection(s) before exit",
                 state.workers.len()
             );
             let message = rsync_info!(text).with_role(Role::Daemon);
@@ -73,6 +97,7 @@ fn check_signals_and_maintain(
     if state.signal_flags.reload_config.swap(false, Ordering::Relaxed) {
         reload_daemon_config(
             state.config_path.as_deref(),
+            state.dparams,
             state.connection_limiter,
             &mut state.modules,
             &mut state.motd_lines,
@@ -195,6 +220,7 @@ fn spawn_connection_worker(
         Arc::clone(&state.modules),
         Arc::clone(&state.motd_lines),
         state.log_sink.as_ref().map(Arc::clone),
+        state.observers.clone(),
         Arc::clone(&state.client_socket_options),
         state.bandwidth_limit,
         state.bandwidth_burst,
@@ -234,11 +260,39 @@ fn apply_client_options(
     }
 }
 
-/// Wraps an accepted `TcpStream` into a [`DaemonStream::Plain`].
+/// Wraps an accepted `TcpStream` into a [`DaemonStream::Plain`], or into the
+/// plaintext end of a TLS relay when the daemon is configured for TLS.
+///
+/// Returns `None` when the relay cannot be started; the connection is dropped.
 fn wrap_accepted_stream(
     tcp_stream: TcpStream,
-    _state: &AcceptLoopState<'_>,
+    state: &AcceptLoopState<'_>,
 ) -> Option<DaemonStream> {
+    #[cfg(all(unix, feature = "tls"))]
+    if let Some(server) = state.tls_server.as_ref() {
+        // Socket options target the TCP socket, which the relay thread owns
+        // from here on.
+        if !state.client_socket_options.is_empty() {
+            apply_socket_options_to_stream(
+                &tcp_stream,
+                &state.client_socket_options,
+                state.log_sink.as_ref(),
+            );
+        }
+        return match spawn_tls_relay(server, tcp_stream, state.log_sink.as_ref()) {
+            Ok(local) => Some(DaemonStream::unix(local)),
+            Err(error) => {
+                if let Some(log) = state.log_sink.as_ref() {
+                    let text = format!("failed to start TLS relay: {error}");
+                    let message = rsync_warning!(text).with_role(Role::Daemon);
+                    log_message(log, &message);
+                }
+                None
+            }
+        };
+    }
+    #[cfg(not(all(unix, feature = "tls")))]
+    let _ = state;
     Some(DaemonStream::plain(tcp_stream))
 }
 
@@ -266,22 +320,39 @@ fn update_connection_status_after_accept(state: &mut AcceptLoopState<'_>) {
 /// connection was sourced. Returns `true` when the `--max-sessions` limit has
 /// been reached and the accept loop should stop.
 fn handle_accepted_connection(
-    tcp_stream: TcpStream,
+    mut tcp_stream: TcpStream,
     raw_peer_addr: SocketAddr,
     state: &mut AcceptLoopState<'_>,
 ) -> bool {
+    if refuse_if_over_connection_rate(&mut tcp_stream, raw_peer_addr, state) {
+        return false;
+    }
+
     apply_accepted_stream_tcp_notsent_lowat(&tcp_stream);
     // upstream: clientserver.c:1396 - the daemon unconditionally enables
     // SO_KEEPALIVE on the accepted client socket, independent of the per-module
     // `socket options` config applied below.
     enable_accepted_stream_keepalive(&tcp_stream, state.log_sink.as_ref());
 
-    let Some(mut stream) = wrap_accepted_stream(tcp_stream, state) else {
+    let Some(stream) = wrap_accepted_stream(tcp_stream, state) else {
         return false;
     };
 
     apply_client_options(&stream, &state.client_socket_options, state.log_sink.as_ref());
 
+    admit_connection(stream, raw_peer_addr, state)
+}
+
+/// Enforces the concurrent-connection cap and spawns a session worker for an
+/// already-wrapped stream.
+///
+/// Shared by the TCP and Unix socket admission paths. Returns `true` when the
+/// `--max-sessions` limit has been reached and the accept loop should stop.
+fn admit_connection(
+    mut stream: DaemonStream,
+    raw_peer_addr: SocketAddr,
+    state: &mut AcceptLoopState<'_>,
+) -> bool {
     if refuse_if_at_capacity(&mut stream, raw_peer_addr, state) {
         drop(stream);
         return false;
@@ -16,6 +16,7 @@ struct ConnectionContext {
     modules: Arc<Vec<ModuleRuntime>>,
     motd_lines: Arc<Vec<String>>,
     log_sink: Option<SharedLogSink>,
+    observers: SessionObservers,
     // Read only by the async accept path's `serve_one_connection`; the sync
     // accept loop applies client socket options in `handle_accepted_connection`
     // before wrapping the stream, so this field is unused in default builds.
@@ -35,6 +36,7 @@ impl ConnectionContext {
         modules: Arc<Vec<ModuleRuntime>>,
         motd_lines: Arc<Vec<String>>,
         log_sink: Option<SharedLogSink>,
+        observers: SessionObservers,
         client_socket_options: Arc<Vec<SocketOption>>,
         bandwidth_limit: Option<NonZeroU64>,
         bandwidth_burst: Option<NonZeroU64>,
@@ -45,6 +47,7 @@ impl ConnectionContext {
             modules,
             motd_lines,
             log_sink,
+            observers,
             client_socket_options,
             bandwidth_limit,
             bandwidth_burst,
@@ -75,6 +78,8 @@ impl ConnectionContext {
     ) -> io::Result<()> {
         let peer_addr = normalize_peer_address(raw_peer_addr);
         let log_for_worker = self.log_sink.clone();
+        let started = Instant::now();
+        self.observers.metrics(|metrics| metrics.connection_opened());
 
         let result = std::panic::catch_unwind(std::panic::AssertUnwindSafe(|| {
             handle_session(
@@ -86,11 +91,24 @@ impl ConnectionContext {
                     daemon_limit: self.bandwidth_limit,
                     daemon_burst: self.bandwidth_burst,
                     log_sink: log_for_worker.clone(),
+                    observers: self.observers.clone(),
                     reverse_lookup: self.reverse_lookup,
                     proxy_protocol: self.proxy_protocol,
                 },
             )
         }));
+        let elapsed = started.elapsed();
+        self.observers.log(|| {
+            LogRecord::new(LogLevel::Info, "disconnect")
+                .with("peer", peer_addr.to_string())
+                .with("duration_ms", duration_millis(elapsed))
+        });
+        self.observers.metrics(|metrics| {
+            if result.is_err() {
+                metrics.session_error(SessionError::Panicked);
+            }
+            metrics.connection_closed(elapsed);
+        });
 
         match result {
             Ok(inner) => inner,
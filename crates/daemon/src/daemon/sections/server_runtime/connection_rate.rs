@@ -0,0 +1,119 @@
+// Per-source-IP connection rate limiting (`max connection rate`).
+//
+// oc-rsync extension with no upstream equivalent: upstream bounds only
+// concurrent sessions (`max connections`), so one client opening and closing
+// connections in a tight loop can still keep the daemon busy forking. Each
+// source IP gets a token bucket holding `rate` tokens that refills at `rate`
+// per minute; a connection that finds the bucket empty is refused in the
+// accept loop before any session worker is spawned.
+
+/// Window over which `max connection rate` tokens refill.
+const CONNECTION_RATE_WINDOW: Duration = Duration::from_secs(60);
+
+/// Number of tracked addresses above which idle buckets are pruned.
+const CONNECTION_RATE_PRUNE_THRESHOLD: usize = 4096;
+
+/// Token bucket for one source address.
+#[derive(Clone, Copy, Debug)]
+struct RateBucket {
+    tokens: f64,
+    updated: Instant,
+}
+
+/// Per-source-IP token buckets consulted for every accepted TCP connection.
+#[derive(Debug)]
+struct ConnectionRateLimiter {
+    rate: NonZeroU32,
+    buckets: std::collections::HashMap<IpAddr, RateBucket>,
+    /// When the table was last pruned; pruning runs at most once a window.
+    last_prune: Option<Instant>,
+}
+
+impl ConnectionRateLimiter {
+    fn new(rate: NonZeroU32) -> Self {
+        Self {
+            rate,
+            buckets: std::collections::HashMap::new(),
+            last_prune: None,
+        }
+    }
+
+    /// Takes one token for `ip` at time `now`; returns `false` when the
+    /// address has exhausted its allowance.
+    fn try_acquire(&mut self, ip: IpAddr, now: Instant) -> bool {
+        if self.buckets.len() >= CONNECTION_RATE_PRUNE_THRESHOLD && self.prune_due(now) {
+            self.prune(now);
+        }
+
+        let capacity = f64::from(self.rate.get());
+        let bucket = self.buckets.entry(ip).or_insert(RateBucket {
+            tokens: capacity,
+            updated: now,
+        });
+        let elapsed = now.saturating_duration_since(bucket.updated);
+        let refill = capacity * elapsed.as_secs_f64() / CONNECTION_RATE_WINDOW.as_secs_f64();
+        bucket.tokens = (bucket.tokens + refill).min(capacity);
+        bucket.updated = now;
+
+        if bucket.tokens >= 1.0 {
+            bucket.tokens -= 1.0;
+            true
+        } else {
+            false
+        }
+    }
+
+    /// Whether a full window has passed since the last prune. Buckets only
+    /// become prunable after a window of idleness, so scanning the table more
+    /// often than that would cost every accept a full pass for nothing.
+    fn prune_due(&self, now: Instant) -> bool {
+        self.last_prune
+            .is_none_or(|last| now.saturating_duration_since(last) >= CONNECTION_RATE_WINDOW)
+    }
+
+    /// Drops buckets idle for a full window; they would be back at capacity,
+    /// which is indistinguishable from an untracked address.
+    fn prune(&mut self, now: Instant) {
+        self.buckets.retain(|_, bucket| {
+            now.saturating_duration_since(bucket.updated) < CONNECTION_RATE_WINDOW
+        });
+        self.last_prune = Some(now);
+    }
+
+    fn rate(&self) -> u32 {
+        self.rate.get()
+    }
+}
+
+/// Refuses `tcp_stream` when its source address is over `max connection
+/// rate`, writing an `@ERROR:` line before the socket is dropped.
+///
+/// The check keys on the TCP peer, so behind a PROXY protocol balancer it
+/// limits the balancer rather than the forwarded client. Returns `true` when
+/// the connection was refused.
+fn refuse_if_over_connection_rate(
+    tcp_stream: &mut TcpStream,
+    raw_peer_addr: SocketAddr,
+    state: &mut AcceptLoopState<'_>,
+) -> bool {
+    let Some(limiter) = state.connection_rate.as_mut() else {
+        return false;
+    };
+    let peer = normalize_peer_address(raw_peer_addr);
+    if limiter.try_acquire(peer.ip(), Instant::now()) {
+        return false;
+    }
+
+    let limit = limiter.rate();
+    let payload =
+        format!("@ERROR: connection rate limit ({limit} per minute) reached -- try again later\n");
+    let _ = tcp_stream.write_all(payload.as_bytes());
+    let _ = tcp_stream.flush();
+
+    if let Some(log) = state.log_sink.as_ref() {
+        let text = format!("connection rate limit reached: peer={peer} limit={limit}/min");
+        let message = rsync_warning!(text).with_role(Role::Daemon);
+        log_message(log, &message);
+    }
+    true
+}
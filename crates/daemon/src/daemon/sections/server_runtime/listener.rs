@@ -383,7 +383,7 @@ fn warn_per_family_accept_failure(
 /// visibility into the churn without implying the daemon is degraded.
 fn warn_transient_accept_failure(
     log: Option<&SharedLogSink>,
-    local_addr: SocketAddr,
+    local_addr: impl fmt::Display,
     error: &io::Error,
 ) {
     let payload = format!(
@@ -399,6 +399,15 @@ fn warn_transient_accept_failure(
     }
 }
 
+/// Socket address a listener for `address` binds, carrying `scope_id` when
+/// the address is IPv6.
+fn listen_socket_addr(address: IpAddr, port: u16, scope_id: u32) -> SocketAddr {
+    match address {
+        IpAddr::V6(v6) => SocketAddr::V6(SocketAddrV6::new(v6, port, 0, scope_id)),
+        IpAddr::V4(_) => SocketAddr::new(address, port),
+    }
+}
+
 /// Binds one TCP listener per entry in `bind_addresses`, tolerating per-family
 /// failures while at least one family still binds successfully.
 ///
@@ -410,6 +419,9 @@ fn warn_transient_accept_failure(
 /// with `EADDRNOTAVAIL` or `EAFNOSUPPORT`, oc-rsync logs the per-family error,
 /// and the listener degrades to IPv4 instead of producing an opaque exit 10.
 ///
+/// `scope_id` is applied to every IPv6 entry. It is only non-zero for an
+/// explicit link-local bind address, which is never combined with another.
+///
 /// Returns the listeners in `bind_addresses` order (skipping families that
 /// failed) along with the matching `local_addr()` for status reporting. Returns
 /// `Err(io::Error)` only when every family in `bind_addresses` failed to bind;
@@ -418,6 +430,7 @@ fn warn_transient_accept_failure(
 fn bind_listeners_per_family(
     bind_addresses: &[IpAddr],
     port: u16,
+    scope_id: u32,
     backlog: i32,
     tcp_fastopen: TcpFastOpenMode,
     acceptor_threads: u32,
@@ -436,7 +449,7 @@ fn bind_listeners_per_family(
     let mut last_error: Option<io::Error> = None;
 
     for addr in bind_addresses {
-        let requested_addr = SocketAddr::new(*addr, port);
+        let requested_addr = listen_socket_addr(*addr, port, scope_id);
 
         // Bind up to `replicas` SO_REUSEPORT sockets for this family. The kernel
         // load-balances accepted connections across them, each driven by its own
@@ -1,8 +1,17 @@
 /// RAII guard that writes a PID file on creation and removes it on drop.
 ///
+/// The guard keeps the file open with an exclusive advisory lock for the
+/// daemon's lifetime. A second daemon pointed at the same path finds the lock
+/// held and refuses to start instead of clobbering a live PID. A file left
+/// behind by a daemon that died without cleaning up is unlocked, because the
+/// kernel drops the lock with the process, so it is treated as stale and
+/// overwritten.
+///
 /// upstream: main.c - `write_pid_file()` writes the daemon PID after binding.
 struct PidFileGuard {
     path: PathBuf,
+    /// Holds the advisory lock; released when the guard drops.
+    _file: fs::File,
 }
 
 impl PidFileGuard {
@@ -13,29 +22,47 @@ impl PidFileGuard {
             fs::create_dir_all(parent).map_err(|error| pid_file_error(&path, error))?;
         }
 
+        // Open without truncating: a live daemon's PID must survive until the
+        // lock below proves the file is not in use.
         let mut file = OpenOptions::new()
             .create(true)
-            .truncate(true)
+            .truncate(false)
+            .read(true)
             .write(true)
             .open(&path)
             .map_err(|error| pid_file_error(&path, error))?;
 
+        if let Err(error) = fs2::FileExt::try_lock_exclusive(&file) {
+            if error.kind() == fs2::lock_contended_error().kind() {
+                let mut contents = String::new();
+                let _ = file.read_to_string(&mut contents);
+                return Err(pid_file_in_use_error(&path, contents.trim().parse().ok()));
+            }
+            return Err(pid_file_error(&path, error));
+        }
+
         // upstream: main.c write_pid_file() - mode 0644
         #[cfg(unix)]
         fs::set_permissions(&path, fs::Permissions::from_mode(0o644))
             .map_err(|error| pid_file_error(&path, error))?;
 
+        // Any previous contents belong to a daemon that no longer holds the
+        // lock, so they are discarded.
+        file.set_len(0)
+            .map_err(|error| pid_file_error(&path, error))?;
         let pid = std::process::id();
         writeln!(file, "{pid}").map_err(|error| pid_file_error(&path, error))?;
         file.sync_all()
             .map_err(|error| pid_file_error(&path, error))?;
 
-        Ok(Self { path })
+        Ok(Self { path, _file: file })
     }
 }
 
 impl Drop for PidFileGuard {
     fn drop(&mut self) {
+        // Unlink while the lock is still held so a daemon starting
+        // concurrently never sees an unlocked file with our PID in it.
         let _ = fs::remove_file(&self.path);
     }
 }
@@ -4,6 +4,9 @@
 /// so that subsequent connections use the new configuration. Existing
 /// connections retain the old config via their `Arc` clones.
 ///
+/// `dparams` are the `--dparam` overrides from startup, re-applied on top of
+/// the re-read file.
+///
 /// On failure (missing file, parse error), the error is logged and the daemon
 /// continues with the previous configuration - matching upstream rsync
 /// behaviour where a bad config reload is non-fatal.
@@ -11,6 +14,7 @@
 /// upstream: clientserver.c - `re_read_config()` called from SIGHUP handler.
 fn reload_daemon_config(
     config_path: Option<&Path>,
+    dparams: &[String],
     connection_limiter: &Option<Arc<ConnectionLimiter>>,
     modules: &mut Arc<Vec<ModuleRuntime>>,
     motd_lines: &mut Arc<Vec<String>>,
@@ -40,7 +44,7 @@ fn reload_daemon_config(
         }
     };
 
-    let parsed = match parse_config_modules(path) {
+    let parsed = match parse_config_modules_with_dparams(path, dparams) {
         Ok(parsed) => parsed,
         Err(error) => {
             if let Some(log) = log_sink {
@@ -310,6 +310,7 @@ fn reload_config_with_no_config_path_is_noop() {
 
     reload_daemon_config(
         None,
+        &[],
         &limiter,
         &mut modules,
         &mut motd,
@@ -339,6 +340,7 @@ fn reload_config_with_missing_file_keeps_old_config() {
     let missing = PathBuf::from("/nonexistent/rsyncd.conf");
     reload_daemon_config(
         Some(&missing),
+        &[],
         &limiter,
         &mut modules,
         &mut motd,
@@ -378,6 +380,7 @@ fn reload_config_replaces_modules_and_motd() {
 
     reload_daemon_config(
         Some(&conf_path),
+        &[],
         &limiter,
         &mut modules,
         &mut motd,
@@ -410,6 +413,7 @@ fn reload_config_existing_connections_keep_old_config() {
 
     reload_daemon_config(
         Some(&conf_path),
+        &[],
         &limiter,
         &mut modules,
         &mut motd,
@@ -428,6 +432,7 @@ fn reload_config_existing_connections_keep_old_config() {
     }
     reload_daemon_config(
         Some(&conf_path),
+        &[],
         &limiter,
         &mut modules,
         &mut motd,
@@ -463,6 +468,7 @@ fn reload_config_with_invalid_syntax_keeps_old_config() {
 
     reload_daemon_config(
         Some(&conf_path),
+        &[],
         &limiter,
         &mut modules,
         &mut motd,
@@ -479,6 +485,7 @@ fn reload_config_with_invalid_syntax_keeps_old_config() {
 
     reload_daemon_config(
         Some(&conf_path),
+        &[],
         &limiter,
         &mut modules,
         &mut motd,
@@ -948,16 +955,22 @@ fn test_accept_loop_state<'a>(
         max_sessions: None,
         max_connections,
         config_path,
+        dparams: &[],
         connection_limiter: limiter,
         modules: Arc::new(Vec::new()),
         motd_lines: Arc::new(Vec::new()),
         log_sink,
+        observers: SessionObservers::default(),
+        shutdown_handle: None,
         notifier,
         client_socket_options: Arc::new(Vec::new()),
         bandwidth_limit: None,
         bandwidth_burst: None,
         reverse_lookup: false,
         proxy_protocol: false,
+        connection_rate: None,
+        #[cfg(all(unix, feature = "tls"))]
+        tls_server: None,
     }
 }
 
@@ -1242,6 +1255,7 @@ fn bind_listeners_per_family_falls_back_when_first_family_unreachable() {
     let (listeners, bound_addresses) = bind_listeners_per_family(
         &bind_addresses,
         0,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         1,
@@ -1279,6 +1293,7 @@ fn bind_listeners_per_family_applies_socket_options_before_listen() {
     let (listeners, _bound_addresses) = bind_listeners_per_family(
         &[loopback],
         0,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         1,
@@ -1312,6 +1327,7 @@ fn bind_listeners_per_family_replicates_acceptor_threads() {
     let (listeners, bound_addresses) = bind_listeners_per_family(
         &bind_addresses,
         0,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         3,
@@ -1357,6 +1373,7 @@ fn default_single_listener_refuses_a_second_bind_on_the_same_port() {
     let (first, first_addrs) = bind_listeners_per_family(
         &[reachable],
         0,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         1,
@@ -1373,6 +1390,7 @@ fn default_single_listener_refuses_a_second_bind_on_the_same_port() {
     let second = bind_listeners_per_family(
         &[reachable],
         port,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         1,
@@ -1410,6 +1428,7 @@ fn multi_acceptor_replicas_co_bind_the_same_fixed_port() {
         match bind_listeners_per_family(
             &[reachable],
             port,
+            0,
             DEFAULT_LISTEN_BACKLOG,
             TcpFastOpenMode::Off,
             2,
@@ -1452,6 +1471,7 @@ fn bind_listeners_per_family_fails_only_when_all_families_unreachable() {
     let err = bind_listeners_per_family(
         &bind_addresses,
         0,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         1,
@@ -1496,6 +1516,7 @@ fn bind_listeners_per_family_falls_back_from_ipv6_to_ipv4() {
     let (listeners, bound_addresses) = bind_listeners_per_family(
         &bind_addresses,
         0,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         1,
@@ -1534,6 +1555,7 @@ fn bind_listeners_per_family_single_family_propagates_error() {
     let err = bind_listeners_per_family(
         &bind_addresses,
         0,
+        0,
         DEFAULT_LISTEN_BACKLOG,
         TcpFastOpenMode::Off,
         1,
@@ -1552,6 +1574,74 @@ fn bind_listeners_per_family_single_family_propagates_error() {
     );
 }
 
+/// First usable link-local IPv6 address with its interface name and index,
+/// read from `/proc/net/if_inet6`.
+#[cfg(target_os = "linux")]
+fn usable_link_local_address() -> Option<(Ipv6Addr, String, u32)> {
+    // Columns: address, ifindex, prefix length, scope, flags, name (hex
+    // except the name). Scope 0x20 is link-local; tentative (0x40) and
+    // DAD-failed (0x08) addresses cannot be bound yet.
+    let table = std::fs::read_to_string("/proc/net/if_inet6").ok()?;
+    table.lines().find_map(|line| {
+        let fields: Vec<&str> = line.split_whitespace().collect();
+        let [address, index, _, scope, flags, name] = fields.as_slice() else {
+            return None;
+        };
+        let flags = u32::from_str_radix(flags, 16).ok()?;
+        if *scope != "20" || flags & 0x48 != 0 {
+            return None;
+        }
+        let address = Ipv6Addr::from(u128::from_str_radix(address, 16).ok()?);
+        let index = u32::from_str_radix(index, 16).ok()?;
+        Some((address, (*name).to_owned(), index))
+    })
+}
+
+#[cfg(target_os = "linux")]
+#[test]
+fn link_local_address_binds_and_dials_with_its_scope_id() {
+    let Some((address, interface, index)) = usable_link_local_address() else {
+        eprintln!("no usable link-local IPv6 address, skipping");
+        return;
+    };
+
+    let zoned = format!("{address}%{interface}");
+    let (bind_ip, scope_id) = parse_bind_address(&OsString::from(&zoned)).expect("zone resolves");
+    assert_eq!(bind_ip, IpAddr::V6(address));
+    assert_eq!(scope_id, index);
+
+    let (listeners, bound_addresses) = match bind_listeners_per_family(
+        &[bind_ip],
+        0,
+        scope_id,
+        DEFAULT_LISTEN_BACKLOG,
+        TcpFastOpenMode::Off,
+        1,
+        &[],
+        None,
+    ) {
+        Ok(bound) => bound,
+        Err(error) => {
+            eprintln!("cannot bind {zoned} ({error}), skipping");
+            return;
+        }
+    };
+    let SocketAddr::V6(bound) = bound_addresses[0] else {
+        panic!("expected an IPv6 listener, got {}", bound_addresses[0]);
+    };
+    assert_eq!(bound.scope_id(), index);
+
+    // Dial the way the client does: getaddrinfo() of the zoned host string.
+    let target = (zoned.as_str(), bound.port())
+        .to_socket_addrs()
+        .expect("client resolves the zone")
+        .next()
+        .expect("one address");
+    let client = TcpStream::connect(target).expect("dial link-local listener");
+    let (_accepted, peer) = listeners[0].accept().expect("accept");
+    assert_eq!(peer, client.local_addr().expect("client addr"));
+}
+
 #[test]
 fn parse_address_family_env_accepts_ipv4_variants() {
     // The runtime override accepts a few common spellings so operators
@@ -1664,6 +1754,10 @@ fn single_listener_engine_poll_returns_connection_with_blocking_reset() {
             }
             AcceptOutcome::Idle => continue,
             AcceptOutcome::Closed => panic!("single-listener engine never reports Closed"),
+            #[cfg(unix)]
+            AcceptOutcome::UnixConnection(_) => {
+                panic!("single-listener engine never accepts Unix sockets")
+            }
         }
     }
 
@@ -1837,6 +1931,7 @@ fn kqueue_engine_poll_returns_connection_with_blocking_reset() {
             }
             AcceptOutcome::Idle => continue,
             AcceptOutcome::Closed => panic!("kqueue engine never reports Closed"),
+            AcceptOutcome::UnixConnection(_) => panic!("kqueue engine never accepts Unix sockets"),
         }
     }
 
@@ -1889,6 +1984,7 @@ fn kqueue_engine_level_triggered_backlog_is_not_stranded() {
             }
             AcceptOutcome::Idle => continue,
             AcceptOutcome::Closed => panic!("kqueue engine never reports Closed"),
+            AcceptOutcome::UnixConnection(_) => panic!("kqueue engine never accepts Unix sockets"),
         }
     }
     assert_eq!(accepted, 3, "all queued connections must be delivered, not stranded");
@@ -1940,6 +2036,7 @@ fn kqueue_engine_needs_one_poll_per_queued_connection() {
             }
             AcceptOutcome::Idle => continue,
             AcceptOutcome::Closed => panic!("kqueue engine never reports Closed"),
+            AcceptOutcome::UnixConnection(_) => panic!("kqueue engine never accepts Unix sockets"),
         }
     }
 
@@ -2008,3 +2105,126 @@ fn kqueue_engine_delivers_queued_connection_without_sleep_floor() {
     engine.shutdown();
     drop(client);
 }
+
+#[test]
+fn pid_file_guard_writes_pid_and_removes_on_drop() {
+    let dir = tempfile::tempdir().expect("pid dir");
+    let path = dir.path().join("run").join("rsyncd.pid");
+
+    let guard = PidFileGuard::create(path.clone()).expect("create pid file");
+    let contents = fs::read_to_string(&path).expect("read pid file");
+    assert_eq!(contents, format!("{}\n", std::process::id()));
+
+    drop(guard);
+    assert!(!path.exists(), "pid file must be removed on drop");
+}
+
+#[test]
+fn pid_file_guard_overwrites_stale_pid() {
+    let dir = tempfile::tempdir().expect("pid dir");
+    let path = dir.path().join("rsyncd.pid");
+    // Longer than our own PID so a missing truncate would leave a tail.
+    fs::write(&path, "4294967295\nleftover\n").expect("write stale pid");
+
+    let _guard = PidFileGuard::create(path.clone()).expect("stale pid file is reclaimed");
+    let contents = fs::read_to_string(&path).expect("read pid file");
+    assert_eq!(contents, format!("{}\n", std::process::id()));
+}
+
+#[test]
+fn pid_file_guard_refuses_pid_file_held_by_running_daemon() {
+    let dir = tempfile::tempdir().expect("pid dir");
+    let path = dir.path().join("rsyncd.pid");
+
+    let _owner = PidFileGuard::create(path.clone()).expect("first daemon");
+    let Err(error) = PidFileGuard::create(path.clone()) else {
+        panic!("second daemon must not take over a locked pid file");
+    };
+    let message = format!("{:?}", error.message());
+    assert!(
+        message.contains(&format!("pid {}", std::process::id())),
+        "error should name the owning pid: {message}"
+    );
+    assert_eq!(
+        fs::read_to_string(&path).expect("read pid file"),
+        format!("{}\n", std::process::id()),
+        "the live daemon's pid must survive the refused start"
+    );
+}
+
+#[test]
+fn tls_identity_paths_disabled_when_unset() {
+    assert!(matches!(tls_identity_paths(None, None, false, false), Ok(None)));
+}
+
+#[test]
+fn tls_identity_paths_requires_both_directives() {
+    let cert = Path::new("/etc/rsyncd/cert.pem");
+    let key = Path::new("/etc/rsyncd/key.pem");
+    assert!(tls_identity_paths(Some(cert), None, false, false).is_err());
+    assert!(tls_identity_paths(None, Some(key), false, false).is_err());
+    assert!(matches!(
+        tls_identity_paths(Some(cert), Some(key), false, false),
+        Ok(Some((c, k))) if c == cert && k == key
+    ));
+}
+
+#[test]
+fn tls_identity_paths_rejects_proxy_protocol_and_unix_socket() {
+    let cert = Some(Path::new("/etc/rsyncd/cert.pem"));
+    let key = Some(Path::new("/etc/rsyncd/key.pem"));
+    assert!(tls_identity_paths(cert, key, true, false).is_err());
+    assert!(tls_identity_paths(cert, key, false, true).is_err());
+}
+
+#[test]
+fn connection_rate_limiter_spends_allowance_then_refuses() {
+    let mut limiter = ConnectionRateLimiter::new(NonZeroU32::new(2).unwrap());
+    let ip: IpAddr = "192.0.2.1".parse().unwrap();
+    let now = Instant::now();
+    assert!(limiter.try_acquire(ip, now));
+    assert!(limiter.try_acquire(ip, now));
+    assert!(!limiter.try_acquire(ip, now));
+}
+
+#[test]
+fn connection_rate_limiter_tracks_addresses_independently() {
+    let mut limiter = ConnectionRateLimiter::new(NonZeroU32::new(1).unwrap());
+    let now = Instant::now();
+    assert!(limiter.try_acquire("192.0.2.1".parse().unwrap(), now));
+    assert!(limiter.try_acquire("192.0.2.2".parse().unwrap(), now));
+    assert!(!limiter.try_acquire("192.0.2.1".parse().unwrap(), now));
+}
+
+#[test]
+fn connection_rate_limiter_refills_over_the_window() {
+    let mut limiter = ConnectionRateLimiter::new(NonZeroU32::new(2).unwrap());
+    let ip: IpAddr = "192.0.2.1".parse().unwrap();
+    let start = Instant::now();
+    assert!(limiter.try_acquire(ip, start));
+    assert!(limiter.try_acquire(ip, start));
+    // Two tokens per minute: one token back after 30 seconds.
+    assert!(!limiter.try_acquire(ip, start + Duration::from_secs(29)));
+    assert!(limiter.try_acquire(ip, start + Duration::from_secs(31)));
+    assert!(!limiter.try_acquire(ip, start + Duration::from_secs(31)));
+}
+
+#[test]
+fn connection_rate_limiter_prune_drops_idle_buckets() {
+    let mut limiter = ConnectionRateLimiter::new(NonZeroU32::new(1).unwrap());
+    let start = Instant::now();
+    limiter.try_acquire("192.0.2.1".parse().unwrap(), start);
+    limiter.try_acquire("192.0.2.2".parse().unwrap(), start + Duration::from_secs(45));
+    limiter.prune(start + Duration::from_secs(61));
+    assert_eq!(limiter.buckets.len(), 1);
+}
+
+#[test]
+fn connection_rate_limiter_prunes_at_most_once_per_window() {
+    let mut limiter = ConnectionRateLimiter::new(NonZeroU32::new(1).unwrap());
+    let start = Instant::now();
+    assert!(limiter.prune_due(start));
+    limiter.prune(start);
+    assert!(!limiter.prune_due(start + Duration::from_secs(59)));
+    assert!(limiter.prune_due(start + Duration::from_secs(60)));
+}
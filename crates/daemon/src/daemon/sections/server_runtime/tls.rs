@@ -0,0 +1,137 @@
+// TLS termination for daemon connections (`tls cert file` / `tls key file`).
+//
+// oc-rsync extension with no upstream equivalent: upstream leaves encryption
+// of `rsync://` traffic to an external terminator (stunnel in front of
+// rsyncd, `rsync-ssl` on the client). When both directives are set, every
+// accepted TCP connection must complete a TLS handshake before the `@RSYNCD:`
+// greeting; plaintext clients fail the handshake and are dropped.
+//
+// The handshake and the record layer run on a per-connection relay thread
+// bridged to a Unix socket pair, so the session worker keeps reading and
+// writing an ordinary `DaemonStream` and the accept loop never blocks on a
+// slow handshake.
+
+/// Upper bound on the TLS handshake of one accepted connection.
+#[cfg(all(unix, feature = "tls"))]
+const TLS_HANDSHAKE_TIMEOUT: Duration = Duration::from_secs(30);
+
+/// Loads the daemon's TLS identity when `tls cert file` / `tls key file` are
+/// configured.
+///
+/// Both directives must be set together. TLS is refused in combination with
+/// `proxy protocol` (the PROXY header precedes the handshake and would be
+/// read as a TLS record) and with `--address unix:PATH` (local sockets are
+/// gated by file permissions instead).
+#[cfg(all(unix, feature = "tls"))]
+fn load_tls_server(
+    cert_file: Option<&Path>,
+    key_file: Option<&Path>,
+    proxy_protocol: bool,
+    unix_socket: bool,
+) -> Result<Option<Arc<core::io::tls::TlsServer>>, DaemonError> {
+    let Some((cert_file, key_file)) =
+        tls_identity_paths(cert_file, key_file, proxy_protocol, unix_socket)?
+    else {
+        return Ok(None);
+    };
+    let server =
+        core::io::tls::TlsServer::from_pem_files(cert_file, key_file).map_err(|error| {
+            config_error(format!(
+                "failed to load TLS certificate '{}' and key '{}': {error}",
+                cert_file.display(),
+                key_file.display()
+            ))
+        })?;
+    Ok(Some(Arc::new(server)))
+}
+
+/// Rejects TLS configuration on builds without the `tls` feature.
+#[cfg(not(all(unix, feature = "tls")))]
+fn load_tls_server(
+    cert_file: Option<&Path>,
+    key_file: Option<&Path>,
+    proxy_protocol: bool,
+    unix_socket: bool,
+) -> Result<(), DaemonError> {
+    if tls_identity_paths(cert_file, key_file, proxy_protocol, unix_socket)?.is_some() {
+        return Err(config_error(
+            "'tls cert file' is not supported by this build \
+             (requires the `tls` feature on a Unix platform)"
+                .to_owned(),
+        ));
+    }
+    Ok(())
+}
+
+/// Validates the TLS directive combination and returns the certificate and
+/// key paths when TLS is enabled.
+fn tls_identity_paths<'p>(
+    cert_file: Option<&'p Path>,
+    key_file: Option<&'p Path>,
+    proxy_protocol: bool,
+    unix_socket: bool,
+) -> Result<Option<(&'p Path, &'p Path)>, DaemonError> {
+    let (cert_file, key_file) = match (cert_file, key_file) {
+        (None, None) => return Ok(None),
+        (Some(cert), Some(key)) => (cert, key),
+        (Some(_), None) => {
+            return Err(config_error(
+                "'tls cert file' requires 'tls key file'".to_owned(),
+            ));
+        }
+        (None, Some(_)) => {
+            return Err(config_error(
+                "'tls key file' requires 'tls cert file'".to_owned(),
+            ));
+        }
+    };
+    if proxy_protocol {
+        return Err(config_error(
+            "'tls cert file' cannot be combined with 'proxy protocol'".to_owned(),
+        ));
+    }
+    if unix_socket {
+        return Err(config_error(
+            "'tls cert file' cannot be combined with --address unix:PATH".to_owned(),
+        ));
+    }
+    Ok(Some((cert_file, key_file)))
+}
+
+/// Starts TLS termination for an accepted connection and returns the
+/// plaintext end the session worker reads.
+///
+/// The handshake runs on the relay thread; a failure (including a plaintext
+/// client) is logged and closes the connection, which the worker observes as
+/// EOF before the greeting exchange completes.
+#[cfg(all(unix, feature = "tls"))]
+fn spawn_tls_relay(
+    server: &Arc<core::io::tls::TlsServer>,
+    tcp_stream: TcpStream,
+    log_sink: Option<&SharedLogSink>,
+) -> io::Result<UnixStream> {
+    let (local, relay_end) = UnixStream::pair()?;
+    let server = Arc::clone(server);
+    let log_sink = log_sink.cloned();
+    let peer = tcp_stream
+        .peer_addr()
+        .map_or_else(|_| String::from("unknown peer"), |addr| addr.to_string());
+    thread::Builder::new()
+        .name("tls-relay".to_owned())
+        .spawn(move || {
+            let session = match server.accept(tcp_stream, Some(TLS_HANDSHAKE_TIMEOUT)) {
+                Ok(session) => session,
+                Err(error) => {
+                    if let Some(log) = log_sink.as_ref() {
+                        let text = format!("TLS handshake with {peer} failed: {error}");
+                        let message = rsync_warning!(text).with_role(Role::Daemon);
+                        log_message(log, &message);
+                    }
+                    return;
+                }
+            };
+            // Relay errors reach the session worker as EOF on `local`.
+            let _ = session.relay(relay_end);
+        })?;
+    Ok(local)
+}
@@ -0,0 +1,157 @@
+// Unix domain socket listener for `--address unix:PATH`.
+//
+// oc-rsync extension with no upstream equivalent: upstream's
+// `socket.c:open_socket_in()` only binds TCP. Local deployments that separate
+// privileges (a front end talking to the daemon on the same host) can instead
+// point clients at a socket file whose directory permissions gate access. The
+// `@RSYNCD:` protocol over the socket is byte-identical to TCP.
+
+/// Synthetic peer address for sessions accepted on the Unix socket.
+///
+/// A Unix socket peer has no IP address, so sessions are logged and matched
+/// against `hosts allow` / `hosts deny` as the loopback host, the same
+/// convention the inetd and stdio paths use for inherited descriptors.
+#[cfg(unix)]
+const UNIX_SOCKET_PEER_ADDR: SocketAddr = SocketAddr::new(IpAddr::V4(Ipv4Addr::LOCALHOST), 0);
+
+/// Bound Unix domain socket listener that unlinks its socket file on drop.
+#[cfg(unix)]
+struct UnixSocketListener {
+    listener: UnixListener,
+    path: PathBuf,
+}
+
+#[cfg(unix)]
+impl UnixSocketListener {
+    /// Binds a listener at `path`, replacing a stale socket file left by a
+    /// daemon that exited without cleaning up.
+    ///
+    /// A socket file that still accepts connections belongs to a live daemon
+    /// and is never replaced. Paths that are not sockets are left alone, so
+    /// `bind(2)` reports `EADDRINUSE` rather than the daemon deleting an
+    /// unrelated file.
+    fn bind(path: PathBuf) -> Result<Self, DaemonError> {
+        if let Ok(metadata) = fs::symlink_metadata(&path)
+            && metadata.file_type().is_socket()
+        {
+            if UnixStream::connect(&path).is_ok() {
+                let error = io::Error::from(io::ErrorKind::AddrInUse);
+                return Err(network_error("bind listener", path.display(), error));
+            }
+            fs::remove_file(&path)
+                .map_err(|error| network_error("bind listener", path.display(), error))?;
+        }
+
+        let listener = UnixListener::bind(&path)
+            .map_err(|error| network_error("bind listener", path.display(), error))?;
+        Ok(Self { listener, path })
+    }
+
+    fn path(&self) -> &Path {
+        &self.path
+    }
+}
+
+#[cfg(unix)]
+impl Drop for UnixSocketListener {
+    fn drop(&mut self) {
+        let _ = fs::remove_file(&self.path);
+    }
+}
+
+/// Accept engine for the Unix domain socket listener.
+///
+/// Same shape as [`SingleListenerEngine`]: non-blocking `accept` with a 50ms
+/// idle sleep so the loop body re-checks signal flags promptly.
+#[cfg(unix)]
+struct UnixListenerEngine {
+    listener: Option<UnixSocketListener>,
+    log_sink: Option<SharedLogSink>,
+}
+
+#[cfg(unix)]
+impl UnixListenerEngine {
+    fn new(
+        listener: UnixSocketListener,
+        log_sink: Option<SharedLogSink>,
+    ) -> Result<Self, DaemonError> {
+        listener
+            .listener
+            .set_nonblocking(true)
+            .map_err(|error| network_error("bind listener", listener.path.display(), error))?;
+        Ok(Self {
+            listener: Some(listener),
+            log_sink,
+        })
+    }
+}
+
+#[cfg(unix)]
+impl AcceptEngine for UnixListenerEngine {
+    fn poll(&mut self) -> Result<AcceptOutcome, DaemonError> {
+        let Some(bound) = self.listener.as_ref() else {
+            return Ok(AcceptOutcome::Closed);
+        };
+        match bound.listener.accept() {
+            Ok((stream, _)) => {
+                // BSD kernels propagate the listener's O_NONBLOCK flag to the
+                // accepted socket; the session reader expects blocking I/O.
+                if let Err(error) = stream.set_nonblocking(false) {
+                    if let Some(log) = self.log_sink.as_ref() {
+                        let text = format!("failed to set accepted socket to blocking: {error}");
+                        let message = rsync_warning!(text).with_role(Role::Daemon);
+                        log_message(log, &message);
+                    }
+                    return Ok(AcceptOutcome::Idle);
+                }
+                Ok(AcceptOutcome::UnixConnection(stream))
+            }
+            Err(error) if error.kind() == io::ErrorKind::WouldBlock => {
+                thread::sleep(Duration::from_millis(50));
+                Ok(AcceptOutcome::Idle)
+            }
+            Err(error) if error.kind() == io::ErrorKind::Interrupted => Ok(AcceptOutcome::Idle),
+            Err(error) => {
+                warn_transient_accept_failure(
+                    self.log_sink.as_ref(),
+                    bound.path().display(),
+                    &error,
+                );
+                thread::sleep(Duration::from_millis(50));
+                Ok(AcceptOutcome::Idle)
+            }
+        }
+    }
+
+    fn shutdown(&mut self) {
+        // Dropping the listener closes it and unlinks the socket file, so
+        // clients connecting during a graceful drain are refused.
+        self.listener = None;
+    }
+}
+
+/// Admits one connection accepted on the Unix domain socket.
+///
+/// TCP-only tuning (keepalive, `TCP_NOTSENT_LOWAT`, `socket options`) does
+/// not apply; admission and worker spawn are shared with TCP.
+#[cfg(unix)]
+fn handle_accepted_unix_connection(stream: UnixStream, state: &mut AcceptLoopState<'_>) -> bool {
+    admit_connection(DaemonStream::unix(stream), UNIX_SOCKET_PEER_ADDR, state)
+}
+
+/// Error returned when `--address unix:PATH` is used on a platform without
+/// Unix domain sockets.
+#[cfg(not(unix))]
+fn unix_socket_unsupported_error(path: &Path) -> DaemonError {
+    DaemonError::new(
+        FEATURE_UNAVAILABLE_EXIT_CODE,
+        rsync_error!(
+            FEATURE_UNAVAILABLE_EXIT_CODE,
+            format!(
+                "cannot listen on unix:{}: Unix domain sockets are not supported on this platform",
+                path.display()
+            )
+        )
+        .with_role(Role::Daemon),
+    )
+}
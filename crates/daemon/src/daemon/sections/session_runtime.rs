@@ -9,6 +9,7 @@ struct SessionParams<'a> {
     daemon_limit: Option<NonZeroU64>,
     daemon_burst: Option<NonZeroU64>,
     log_sink: Option<SharedLogSink>,
+    observers: SessionObservers,
     reverse_lookup: bool,
     proxy_protocol: bool,
 }
@@ -27,6 +28,7 @@ struct LegacySessionParams<'a> {
     daemon_limit: Option<NonZeroU64>,
     daemon_burst: Option<NonZeroU64>,
     log_sink: Option<SharedLogSink>,
+    observers: SessionObservers,
     peer_host: Option<String>,
     reverse_lookup: bool,
 }
@@ -52,6 +54,7 @@ fn handle_session(
         daemon_limit,
         daemon_burst,
         log_sink,
+        observers,
         reverse_lookup,
         proxy_protocol,
     } = params;
@@ -105,6 +108,7 @@ fn handle_session(
     if let Some(log) = log_sink.as_ref() {
         log_connection(log, peer_host.as_deref(), peer_addr);
     }
+    log_connect_event(&observers, peer_host.as_deref(), peer_addr);
 
     match style {
         SessionStyle::Binary => handle_binary_session(stream, daemon_limit, daemon_burst, log_sink),
@@ -117,6 +121,7 @@ fn handle_session(
                 daemon_limit,
                 daemon_burst,
                 log_sink,
+                observers,
                 peer_host,
                 reverse_lookup,
             },
@@ -227,6 +232,7 @@ fn handle_legacy_session(
         daemon_limit,
         daemon_burst,
         log_sink,
+        observers,
         peer_host,
         reverse_lookup,
     } = params;
@@ -266,6 +272,7 @@ fn handle_legacy_session(
     let mut request = None;
     let mut refused_options = Vec::new();
     let mut negotiated_protocol = None;
+    let mut resume_offered = false;
     let mut early_input_data: Option<Vec<u8>> = None;
 
     // TCP_QUICKACK is one-shot; re-arm before each handshake read so every
@@ -295,6 +302,9 @@ fn handle_legacy_session(
                 // the version exchange. Sending OK here causes the client to misinterpret
                 // subsequent protocol messages.
                 negotiated_protocol = Some(version);
+                // oc-rsync extension: the client may offer a resume manifest
+                // in its digest list; it is accepted once the module is.
+                resume_offered = greeting_offers_resume(&line);
                 // FSM: Greeting -> ModuleSelect - version exchange complete,
                 // now waiting for the client to request a module name.
                 conn_state = conn_state
@@ -381,9 +391,11 @@ fn handle_legacy_session(
             peer_host.as_deref(),
             &refused_options,
             log_sink.as_ref(),
+            &observers,
             reverse_lookup,
             messages,
             negotiated_protocol,
+            resume_offered,
             early_input_data,
             conn_state,
         )?;
@@ -534,6 +546,7 @@ mod session_runtime_tests {
             daemon_limit: None,
             daemon_burst: None,
             log_sink: None,
+            observers: SessionObservers::default(),
             reverse_lookup: false,
             proxy_protocol: false,
         };
@@ -556,6 +569,7 @@ mod session_runtime_tests {
             daemon_limit: limit,
             daemon_burst: burst,
             log_sink: None,
+            observers: SessionObservers::default(),
             reverse_lookup: true,
             proxy_protocol: false,
         };
@@ -574,6 +588,7 @@ mod session_runtime_tests {
             daemon_limit: None,
             daemon_burst: None,
             log_sink: None,
+            observers: SessionObservers::default(),
             peer_host: None,
             reverse_lookup: false,
         };
@@ -591,6 +606,7 @@ mod session_runtime_tests {
             daemon_limit: None,
             daemon_burst: None,
             log_sink: None,
+            observers: SessionObservers::default(),
             peer_host: Some("example.com".to_owned()),
             reverse_lookup: true,
         };
@@ -120,6 +120,9 @@ pub fn run_stdio_session(
             daemon_limit: bandwidth_limit,
             daemon_burst: bandwidth_burst,
             log_sink,
+            // No `DaemonConfig` reaches this entry point, so there are no
+            // embedder observers to forward.
+            observers: SessionObservers::default(),
             peer_host: Some("localhost".to_owned()),
             reverse_lookup,
         },
@@ -12,6 +12,9 @@ struct VarExpansionContext<'a> {
     client_addr: &'a str,
     /// Resolved peer hostname, or falls back to `client_addr` when unavailable.
     client_host: &'a str,
+    /// Authenticated user name. `None` before authentication and for
+    /// anonymous modules, which leaves user tokens verbatim.
+    user_name: Option<&'a str>,
 }
 
 /// Expands `%`-delimited variables in a daemon config string value.
@@ -25,12 +28,37 @@ struct VarExpansionContext<'a> {
 /// - `%RSYNC_MODULE_NAME%` - same as `%MODULE%`
 /// - `%RSYNC_MODULE_PATH%` - the module's configured path
 /// - `%ADDR%` - the client's IP address
+/// - `%RSYNC_USER_NAME%` - the authenticated user, once known
 /// - `%%` - literal `%`
 /// - Unknown `%FOO%` tokens are left as-is
 ///
 /// upstream: `loadparm.c` - `lp_string()` calls `alloc_sub_advanced()` which
 /// walks the format string replacing `%`-delimited variable names.
 fn expand_config_vars(template: &str, ctx: &VarExpansionContext<'_>) -> String {
+    expand_vars(template, ctx, false)
+}
+
+/// Expands a module `path` template.
+///
+/// Accepts every [`expand_config_vars`] variable plus the single-character
+/// escapes familiar from `log format` and exec commands, so per-user and
+/// per-host roots can be written either way:
+///
+/// - `%u` - authenticated user (as `%RSYNC_USER_NAME%`)
+/// - `%h` - client hostname (as `%DIFFHOST%`)
+/// - `%a` - client address (as `%ADDR%`)
+/// - `%m` - module name (as `%MODULE%`)
+///
+/// A `%NAME%` variable takes precedence, so `%u%` style ambiguity never
+/// arises for the upper-case names. An escape whose value is unavailable -
+/// `%u` before authentication or on an anonymous module - is kept verbatim,
+/// leaving a path that cannot exist rather than one that collapses onto a
+/// shared parent directory.
+fn expand_module_path(template: &str, ctx: &VarExpansionContext<'_>) -> String {
+    expand_vars(template, ctx, true)
+}
+
+fn expand_vars(template: &str, ctx: &VarExpansionContext<'_>, short_escapes: bool) -> String {
     let mut result = String::with_capacity(template.len());
     let mut rest = template;
 
@@ -44,6 +72,23 @@ fn expand_config_vars(template: &str, ctx: &VarExpansionContext<'_>) -> String {
             continue;
         }
 
+        if let Some(end) = find_closing_percent(rest)
+            && let Some(value) = resolve_variable(&rest[..end], ctx)
+        {
+            result.push_str(value);
+            rest = &rest[end + 1..];
+            continue;
+        }
+
+        if short_escapes
+            && let Some(ch) = rest.chars().next()
+            && let Some(value) = resolve_short_escape(ch, ctx)
+        {
+            result.push_str(value);
+            rest = &rest[ch.len_utf8()..];
+            continue;
+        }
+
         match find_closing_percent(rest) {
             Some(end) => {
                 let var_name = &rest[..end];
@@ -82,10 +127,30 @@ fn resolve_variable<'a>(name: &str, ctx: &VarExpansionContext<'a>) -> Option<&'a
         "MODULE" | "RSYNC_MODULE_NAME" => Some(ctx.module_name),
         "RSYNC_MODULE_PATH" => Some(ctx.module_path),
         "ADDR" => Some(ctx.client_addr),
+        "RSYNC_USER_NAME" => ctx.user_name,
+        _ => None,
+    }
+}
+
+/// Maps a single-character path escape to its substitution value.
+fn resolve_short_escape<'a>(escape: char, ctx: &VarExpansionContext<'a>) -> Option<&'a str> {
+    match escape {
+        'u' => ctx.user_name,
+        'h' => Some(ctx.client_host),
+        'a' => Some(ctx.client_addr),
+        'm' => Some(ctx.module_name),
         _ => None,
     }
 }
 
+/// Returns `name` when it is usable as a single path component.
+///
+/// A user name containing `/`, or equal to `.` or `..`, would let the
+/// substituted module root escape its intended parent directory.
+fn path_safe_user_name(name: &str) -> Option<&str> {
+    (!name.is_empty() && name != "." && name != ".." && !name.contains('/')).then_some(name)
+}
+
 /// Applies `%`-variable expansion to all path-type fields of a module definition.
 ///
 /// Called after module selection when a client connects, before the module path
@@ -104,9 +169,10 @@ fn expand_module_vars(
         module_path: &module.path.display().to_string(),
         client_addr,
         client_host,
+        user_name: None,
     };
 
-    module.path = PathBuf::from(expand_config_vars(&module.path.display().to_string(), &ctx));
+    module.path = PathBuf::from(expand_module_path(&module.path.display().to_string(), &ctx));
 
     if let Some(ref dir) = module.temp_dir {
         module.temp_dir = Some(expand_config_vars(dir, &ctx));
@@ -141,6 +207,35 @@ fn expand_module_vars(
     }
 }
 
+/// Resolves a module path template against the authenticated user.
+///
+/// The pre-authentication [`expand_module_vars`] pass leaves `%u` and
+/// `%RSYNC_USER_NAME%` verbatim; this pass re-expands the raw `template`
+/// once the user is known. Returns `None` when the template does not depend
+/// on the user, so the earlier expansion stands.
+///
+/// upstream: `clientserver.c:rsync_module()` reads the module path after
+/// `auth_server()` has identified the user and exported `RSYNC_USER_NAME`.
+fn expand_module_path_for_user(
+    template: &str,
+    module_name: &str,
+    client_addr: &str,
+    client_host: &str,
+    user_name: Option<&str>,
+) -> Option<PathBuf> {
+    let mut ctx = VarExpansionContext {
+        module_name,
+        module_path: template,
+        client_addr,
+        client_host,
+        user_name: None,
+    };
+    let anonymous = expand_module_path(template, &ctx);
+    ctx.user_name = user_name.and_then(path_safe_user_name);
+    let resolved = expand_module_path(template, &ctx);
+    (resolved != anonymous).then(|| PathBuf::from(resolved))
+}
+
 /// Context for expanding single-character `%` variables in daemon paths.
 ///
 /// Upstream rsync expands `%`-escapes in certain config string values at
@@ -248,6 +343,7 @@ mod variable_expansion_tests {
             module_path: "/srv/backup",
             client_addr: "192.168.1.100",
             client_host: "client.example.com",
+            user_name: Some("alice"),
         }
     }
 
@@ -762,4 +858,68 @@ mod variable_expansion_tests {
             PathBuf::from("/var/log/rsync.log")
         );
     }
+
+    #[test]
+    fn module_path_short_escapes() {
+        let ctx = sample_ctx();
+        assert_eq!(
+            expand_module_path("/srv/%u/%h/%m-%a", &ctx),
+            "/srv/alice/client.example.com/backup-192.168.1.100"
+        );
+    }
+
+    #[test]
+    fn module_path_named_user_variable() {
+        let ctx = sample_ctx();
+        assert_eq!(
+            expand_module_path("/srv/%RSYNC_USER_NAME%/data", &ctx),
+            "/srv/alice/data"
+        );
+    }
+
+    #[test]
+    fn module_path_user_escape_kept_without_user() {
+        let ctx = VarExpansionContext {
+            user_name: None,
+            ..sample_ctx()
+        };
+        assert_eq!(expand_module_path("/srv/%u", &ctx), "/srv/%u");
+        assert_eq!(
+            expand_module_path("/srv/%RSYNC_USER_NAME%", &ctx),
+            "/srv/%RSYNC_USER_NAME%"
+        );
+    }
+
+    #[test]
+    fn config_vars_ignore_short_escapes() {
+        let ctx = sample_ctx();
+        assert_eq!(expand_config_vars("/tmp/%u", &ctx), "/tmp/%u");
+    }
+
+    #[test]
+    fn module_path_for_user_resolves_template() {
+        assert_eq!(
+            expand_module_path_for_user("/srv/%u", "home", "10.0.0.1", "host.local", Some("bob")),
+            Some(PathBuf::from("/srv/bob"))
+        );
+    }
+
+    #[test]
+    fn module_path_for_user_none_without_user_token() {
+        assert_eq!(
+            expand_module_path_for_user("/srv/%h", "home", "10.0.0.1", "host.local", Some("bob")),
+            None
+        );
+    }
+
+    #[test]
+    fn module_path_for_user_rejects_unsafe_names() {
+        for name in ["..", ".", "a/b", ""] {
+            assert_eq!(
+                expand_module_path_for_user("/srv/%u", "home", "10.0.0.1", "host", Some(name)),
+                None,
+                "{name:?} must not be substituted"
+            );
+        }
+    }
 }
@@ -107,15 +107,38 @@ fn build_post_xfer_command(command: &str, ctx: &XferExecContext<'_>) -> ProcessC
 /// upstream-compatible environment variables. If the command exits non-zero,
 /// returns an error indicating the connection should be denied.
 ///
+/// When the client sent `--early-input` data it is written to the command's
+/// stdin, so the script can base its decision on it (for example a key that
+/// unlocks the module's filesystem). Without early input stdin is closed.
+///
 /// Upstream: `clientserver.c` - `early_exec()` runs early in the connection,
-/// before authentication and argument exchange.
-fn run_early_exec(command: &str, ctx: &XferExecContext<'_>) -> io::Result<Result<(), String>> {
+/// before authentication and argument exchange; rsync.1 `--early-input`
+/// documents the data arriving on the early exec script's stdin.
+fn run_early_exec(
+    command: &str,
+    ctx: &XferExecContext<'_>,
+    early_input: Option<&[u8]>,
+) -> io::Result<Result<(), String>> {
     let mut cmd = build_pre_xfer_command(command, ctx);
-    cmd.stdin(Stdio::null());
+    if early_input.is_some() {
+        cmd.stdin(Stdio::piped());
+    } else {
+        cmd.stdin(Stdio::null());
+    }
     cmd.stdout(Stdio::null());
     cmd.stderr(Stdio::piped());
 
-    let output = cmd.output()?;
+    let mut child = cmd.spawn()?;
+    if let Some(data) = early_input {
+        if let Some(mut stdin) = child.stdin.take() {
+            // Best-effort write; a script that ignores its input may exit
+            // before reading it, which must not turn into a spawn failure.
+            let _ = stdin.write_all(data);
+            drop(stdin);
+        }
+    }
+
+    let output = child.wait_with_output()?;
 
     if output.status.success() {
         Ok(Ok(()))
@@ -408,7 +431,7 @@ mod xfer_exec_tests {
     #[test]
     fn run_early_exec_succeeds_on_zero_exit() {
         let ctx = test_context();
-        let result = run_early_exec("true", &ctx).expect("command should run");
+        let result = run_early_exec("true", &ctx, None).expect("command should run");
         assert!(result.is_ok());
     }
 
@@ -416,7 +439,7 @@ mod xfer_exec_tests {
     #[test]
     fn run_early_exec_fails_on_nonzero_exit() {
         let ctx = test_context();
-        let result = run_early_exec("false", &ctx).expect("command should run");
+        let result = run_early_exec("false", &ctx, None).expect("command should run");
         assert!(result.is_err());
         let msg = result.unwrap_err();
         assert!(msg.contains("early exec command failed"));
@@ -427,8 +450,8 @@ mod xfer_exec_tests {
     #[test]
     fn run_early_exec_captures_stderr() {
         let ctx = test_context();
-        let result =
-            run_early_exec("echo 'early error' >&2; exit 1", &ctx).expect("command should run");
+        let result = run_early_exec("echo 'early error' >&2; exit 1", &ctx, None)
+            .expect("command should run");
         assert!(result.is_err());
         let msg = result.unwrap_err();
         assert!(msg.contains("early error"));
@@ -441,11 +464,30 @@ mod xfer_exec_tests {
         let result = run_early_exec(
             "test \"$RSYNC_MODULE_NAME\" = \"testmod\" && test \"$RSYNC_HOST_ADDR\" = \"192.168.1.100\"",
             &ctx,
+            None,
         )
         .expect("command should run");
         assert!(result.is_ok(), "env vars should be set correctly");
     }
 
+    #[cfg(unix)]
+    #[test]
+    fn run_early_exec_decides_on_early_input() {
+        let ctx = test_context();
+        let script = "test \"$(cat)\" = 'open sesame' || { echo 'bad key' >&2; exit 1; }";
+
+        let accepted =
+            run_early_exec(script, &ctx, Some(b"open sesame")).expect("command should run");
+        assert!(accepted.is_ok(), "matching early input must be accepted");
+
+        let rejected = run_early_exec(script, &ctx, Some(b"wrong")).expect("command should run");
+        let msg = rejected.expect_err("mismatching early input must be rejected");
+        assert!(msg.contains("bad key"), "{msg}");
+
+        let missing = run_early_exec(script, &ctx, None).expect("command should run");
+        assert!(missing.is_err(), "closed stdin reads as empty input");
+    }
+
     #[cfg(unix)]
     #[test]
     fn run_pre_xfer_exec_succeeds_on_zero_exit() {
@@ -1,7 +1,9 @@
-//! Unified stream abstraction for plain TCP and stdio daemon connections.
+//! Unified stream abstraction for TCP, Unix socket, and stdio daemon
+//! connections.
 //!
-//! [`DaemonStream`] transparently handles plain `TcpStream` and stdio-based
-//! connections behind a single type that implements `Read + Write`. This lets
+//! [`DaemonStream`] transparently handles plain `TcpStream`, `UnixStream`,
+//! and stdio-based connections behind a single type that implements
+//! `Read + Write`. This lets
 //! the daemon's session handler, greeting exchange, and module access code
 //! operate identically regardless of the transport.
 //!
@@ -12,6 +14,8 @@
 
 use std::io::{self, Read, Write};
 use std::net::{Shutdown, TcpStream};
+#[cfg(unix)]
+use std::os::unix::net::UnixStream;
 use std::time::Duration;
 
 /// Joined stdin/stdout pair for daemon stdio mode.
@@ -44,11 +48,19 @@ impl StdioPair {
 /// # Variants
 ///
 /// - `Plain` - unencrypted TCP.
+/// - `Unix` - Unix domain socket (`--address unix:PATH`).
 /// - `Stdio` - stdin/stdout pair for `--server --daemon` remote-shell mode.
 pub enum DaemonStream {
     /// Unencrypted TCP connection.
     Plain(TcpStream),
 
+    /// Connection accepted on the daemon's Unix domain socket listener.
+    ///
+    /// Carries the same `@RSYNCD:` protocol as `Plain`; only the transport
+    /// differs. TCP-only tuning (`TCP_NODELAY`, keepalive) does not apply.
+    #[cfg(unix)]
+    Unix(UnixStream),
+
     /// Stdio-based connection for remote-shell daemon mode.
     ///
     /// Used when the daemon is invoked via `--server --daemon` over an
@@ -63,38 +75,51 @@ impl DaemonStream {
         Self::Plain(stream)
     }
 
+    /// Wraps a stream accepted on a Unix domain socket listener.
+    #[cfg(unix)]
+    pub fn unix(stream: UnixStream) -> Self {
+        Self::Unix(stream)
+    }
+
     /// Wraps a stdio pair for remote-shell daemon mode.
     pub fn stdio(pair: StdioPair) -> Self {
         Self::Stdio(pair)
     }
 
-    /// Configures the read timeout on the underlying TCP socket.
+    /// Configures the read timeout on the underlying socket.
     ///
-    /// Delegates to `TcpStream::set_read_timeout`. No-op for stdio streams
-    /// (pipes do not support socket timeouts).
+    /// Delegates to `TcpStream::set_read_timeout` or
+    /// `UnixStream::set_read_timeout`. No-op for stdio streams (pipes do not
+    /// support socket timeouts).
     pub fn set_read_timeout(&self, dur: Option<Duration>) -> io::Result<()> {
         match self {
             Self::Plain(s) => s.set_read_timeout(dur),
+            #[cfg(unix)]
+            Self::Unix(s) => s.set_read_timeout(dur),
             Self::Stdio(_) => Ok(()),
         }
     }
 
-    /// Configures the write timeout on the underlying TCP socket.
+    /// Configures the write timeout on the underlying socket.
     ///
     /// No-op for stdio streams.
     pub fn set_write_timeout(&self, dur: Option<Duration>) -> io::Result<()> {
         match self {
             Self::Plain(s) => s.set_write_timeout(dur),
+            #[cfg(unix)]
+            Self::Unix(s) => s.set_write_timeout(dur),
             Self::Stdio(_) => Ok(()),
         }
     }
 
     /// Enables or disables `TCP_NODELAY` on the underlying socket.
     ///
-    /// No-op for stdio streams.
+    /// No-op for Unix socket and stdio streams.
     pub fn set_nodelay(&self, nodelay: bool) -> io::Result<()> {
         match self {
             Self::Plain(s) => s.set_nodelay(nodelay),
+            #[cfg(unix)]
+            Self::Unix(_) => Ok(()),
             Self::Stdio(_) => Ok(()),
         }
     }
@@ -106,20 +131,34 @@ impl DaemonStream {
     pub fn shutdown(&self, how: Shutdown) -> io::Result<()> {
         match self {
             Self::Plain(s) => s.shutdown(how),
+            #[cfg(unix)]
+            Self::Unix(s) => s.shutdown(how),
             Self::Stdio(_) => Ok(()),
         }
     }
 
     /// Returns a reference to the underlying `TcpStream`, if available.
     ///
-    /// Returns `None` for stdio streams which have no underlying TCP socket.
+    /// Returns `None` for Unix socket and stdio streams, which have no
+    /// underlying TCP socket.
     pub fn tcp_stream(&self) -> Option<&TcpStream> {
         match self {
             Self::Plain(s) => Some(s),
+            #[cfg(unix)]
+            Self::Unix(_) => None,
             Self::Stdio(_) => None,
         }
     }
 
+    /// Returns a reference to the underlying `UnixStream`, if available.
+    #[cfg(unix)]
+    pub fn unix_stream(&self) -> Option<&UnixStream> {
+        match self {
+            Self::Unix(s) => Some(s),
+            Self::Plain(_) | Self::Stdio(_) => None,
+        }
+    }
+
     /// Returns `true` if this is a stdio-based connection.
     pub fn is_stdio(&self) -> bool {
         matches!(self, Self::Stdio(_))
@@ -131,10 +170,13 @@ impl DaemonStream {
     ///
     /// # Panics
     ///
-    /// Panics if called on a `Stdio` variant, which has no `TcpStream`.
+    /// Panics if called on a `Unix` or `Stdio` variant, which has no
+    /// `TcpStream`.
     pub fn into_tcp_stream(self) -> TcpStream {
         match self {
             Self::Plain(s) => s,
+            #[cfg(unix)]
+            Self::Unix(_) => panic!("cannot extract TcpStream from Unix variant"),
             Self::Stdio(_) => panic!("cannot extract TcpStream from Stdio variant"),
         }
     }
@@ -144,6 +186,8 @@ impl Read for DaemonStream {
     fn read(&mut self, buf: &mut [u8]) -> io::Result<usize> {
         match self {
             Self::Plain(s) => s.read(buf),
+            #[cfg(unix)]
+            Self::Unix(s) => s.read(buf),
             Self::Stdio(pair) => pair.reader.read(buf),
         }
     }
@@ -153,6 +197,8 @@ impl Write for DaemonStream {
     fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
         match self {
             Self::Plain(s) => s.write(buf),
+            #[cfg(unix)]
+            Self::Unix(s) => s.write(buf),
             Self::Stdio(pair) => pair.writer.write(buf),
         }
     }
@@ -160,6 +206,8 @@ impl Write for DaemonStream {
     fn flush(&mut self) -> io::Result<()> {
         match self {
             Self::Plain(s) => s.flush(),
+            #[cfg(unix)]
+            Self::Unix(s) => s.flush(),
             Self::Stdio(pair) => pair.writer.flush(),
         }
     }
@@ -169,6 +217,8 @@ impl std::fmt::Debug for DaemonStream {
     fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
         match self {
             Self::Plain(s) => f.debug_tuple("DaemonStream::Plain").field(s).finish(),
+            #[cfg(unix)]
+            Self::Unix(s) => f.debug_tuple("DaemonStream::Unix").field(s).finish(),
             Self::Stdio(_) => f
                 .debug_tuple("DaemonStream::Stdio")
                 .field(&"<stdio>")
@@ -269,6 +319,37 @@ mod tests {
         assert!(debug.contains("Plain"), "got: {debug}");
     }
 
+    #[cfg(unix)]
+    #[test]
+    fn unix_read_write_roundtrip() {
+        let (client, server) = UnixStream::pair().unwrap();
+        let mut daemon = DaemonStream::unix(server);
+        let mut client = client;
+
+        client.write_all(b"hello daemon").unwrap();
+        let mut buf = [0u8; 64];
+        let n = daemon.read(&mut buf).unwrap();
+        assert_eq!(&buf[..n], b"hello daemon");
+
+        daemon.write_all(b"hello client").unwrap();
+        let n = client.read(&mut buf).unwrap();
+        assert_eq!(&buf[..n], b"hello client");
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn unix_has_no_tcp_stream() {
+        let (_client, server) = UnixStream::pair().unwrap();
+        let daemon = DaemonStream::unix(server);
+        assert!(daemon.tcp_stream().is_none());
+        assert!(daemon.unix_stream().is_some());
+        assert!(!daemon.is_stdio());
+        daemon.set_nodelay(true).unwrap();
+        daemon
+            .set_read_timeout(Some(Duration::from_secs(5)))
+            .unwrap();
+    }
+
     #[test]
     fn stdio_read_write_roundtrip() {
         let input = b"hello from client";
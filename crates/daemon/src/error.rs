@@ -22,24 +22,33 @@ use std::error::Error;
 use std::fmt;
 
 use core::exit_code::{ErrorCodification, ExitCode, HasExitCode};
+use core::failure::Failure;
 use core::message::Message;
 
 /// Error returned when daemon orchestration fails.
 ///
 /// Uses the centralized `ExitCode` enum internally for type-safe exit code
 /// handling while maintaining backward compatibility with i32 interfaces.
+/// Protocol and timeout failures also expose a typed cause through
+/// [`Error::source`]; see [`core::failure`].
 #[derive(Clone, Debug)]
 pub struct DaemonError {
     exit_code: ExitCode,
     message: Message,
+    failure: Option<Failure>,
 }
 
 impl DaemonError {
     /// Creates a new [`DaemonError`] with a typed exit code.
     ///
     /// This is the preferred constructor when the exit code is known at compile time.
-    pub(crate) const fn with_code(exit_code: ExitCode, message: Message) -> Self {
-        Self { exit_code, message }
+    pub(crate) fn with_code(exit_code: ExitCode, message: Message) -> Self {
+        let failure = Failure::from_exit_code(exit_code, message.text());
+        Self {
+            exit_code,
+            message,
+            failure,
+        }
     }
 
     /// Creates a new [`DaemonError`] from the supplied message and i32 exit code.
@@ -67,6 +76,12 @@ impl DaemonError {
     pub const fn message(&self) -> &Message {
         &self.message
     }
+
+    /// Returns the typed failure cause, when the exit code implies one.
+    #[must_use]
+    pub const fn failure(&self) -> Option<&Failure> {
+        self.failure.as_ref()
+    }
 }
 
 impl HasExitCode for DaemonError {
@@ -157,7 +172,11 @@ impl fmt::Display for DaemonError {
     }
 }
 
-impl Error for DaemonError {}
+impl Error for DaemonError {
+    fn source(&self) -> Option<&(dyn Error + 'static)> {
+        self.failure.as_ref().map(|failure| failure.as_error() as _)
+    }
+}
 
 #[cfg(test)]
 mod tests {
@@ -184,6 +203,18 @@ mod tests {
             let _ = error.message();
         }
 
+        #[test]
+        fn timeout_exposes_typed_source() {
+            let message = rsync_error!(30, "timeout in data send").with_role(Role::Daemon);
+            let error = DaemonError::new(30, message);
+
+            let timeout = error
+                .source()
+                .and_then(|source| source.downcast_ref::<core::failure::TimeoutError>())
+                .expect("timeout cause");
+            assert!(!timeout.is_connect_timeout());
+        }
+
         #[test]
         fn clone() {
             let message = rsync_error!(1, "cloneable error").with_role(Role::Daemon);
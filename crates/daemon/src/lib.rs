@@ -24,6 +24,18 @@
 //! - [`DaemonConfig`] stores the caller-provided daemon arguments. A
 //!   [`DaemonConfigBuilder`] provides a fluent API for assembling the
 //!   configuration with branding, arguments, and default-path control.
+//! - [`DaemonLogger`] is an optional structured event sink installed through
+//!   [`DaemonConfigBuilder::logger`]. [`JsonLogger`] and [`TextLogger`] cover
+//!   JSON-lines and `key=value` output; the upstream-style text log remains
+//!   the default when no logger is installed.
+//! - [`DaemonMetrics`] is an optional metrics sink installed through
+//!   [`DaemonConfigBuilder::metrics`]. The daemon calls it for connections,
+//!   transfers, and session errors so embedders can export Prometheus-style
+//!   counters without this crate depending on a metrics library.
+//! - [`ShutdownHandle`], installed through
+//!   [`DaemonConfigBuilder::shutdown_handle`], stops an embedded daemon
+//!   gracefully: new connections are refused while in-flight sessions drain,
+//!   with a caller-chosen timeout on the wait.
 //! - The runtime honours the branded `OC_RSYNC_CONFIG` and
 //!   `OC_RSYNC_SECRETS` environment variables and falls back to the legacy
 //!   `RSYNCD_CONFIG`/`RSYNCD_SECRETS` overrides when the branded values are
@@ -185,6 +197,9 @@ mod daemon;
 /// Unified stream abstraction for plain TCP and stdio connections.
 pub mod daemon_stream;
 mod error;
+mod logger;
+mod metrics;
+mod shutdown;
 mod systemd;
 
 /// Test-only accessors for the LSM-SECCOMP worker filter.
@@ -220,3 +235,6 @@ pub use daemon::run_async_daemon;
 pub use daemon::{run_daemon, run_daemon_stdio, run_stdio_session};
 pub use daemon_stream::{DaemonStream, StdioPair};
 pub use error::DaemonError;
+pub use logger::{DaemonLogger, JsonLogger, LogLevel, LogRecord, LogValue, TextLogger};
+pub use metrics::{DaemonMetrics, SessionError, TransferMetrics};
+pub use shutdown::{ShutdownHandle, ShutdownTimedOut};
@@ -0,0 +1,364 @@
+//! Structured daemon event logging.
+//!
+//! The daemon's built-in diagnostics are the upstream-compatible text lines
+//! written to `log file` (or syslog) through [`logging_sink::MessageSink`].
+//! That output stays the default and is unchanged by this module.
+//!
+//! Embedders and operators who want machine-readable output install a
+//! [`DaemonLogger`] through [`crate::DaemonConfigBuilder::logger`]. The daemon
+//! then emits one [`LogRecord`] per connection lifecycle event in addition to
+//! the text log:
+//!
+//! | event        | fields                                                        |
+//! |--------------|---------------------------------------------------------------|
+//! | `connect`    | `peer`, `host`                                                |
+//! | `transfer`   | `module`, `peer`, `host`, `operation`, `protocol`, `bytes_read`, `bytes_written`, `duration_ms`, `status`, `error` |
+//! | `disconnect` | `peer`, `duration_ms`                                         |
+//!
+//! `host` is omitted when reverse lookup is disabled or fails, and `error` is
+//! only present on a failed transfer. `disconnect` comes from the TCP accept
+//! paths; inetd and remote-shell sessions end with the process instead.
+//!
+//! Two sink implementations ship with the crate: [`JsonLogger`] writes one
+//! JSON object per line, and [`TextLogger`] writes `key=value` lines for
+//! terminals and plain-text collectors.
+
+use std::fmt;
+use std::io::{self, Write};
+use std::sync::{Arc, Mutex, PoisonError};
+
+use serde::ser::{Serialize, SerializeMap, Serializer};
+
+/// Shared handle to an installed [`DaemonLogger`].
+pub(crate) type SharedDaemonLogger = Arc<dyn DaemonLogger>;
+
+/// Severity attached to a [`LogRecord`].
+#[derive(Clone, Copy, Debug, Eq, PartialEq, serde::Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum LogLevel {
+    /// Routine lifecycle event.
+    Info,
+    /// Recoverable problem; the daemon keeps serving.
+    Warning,
+    /// Failed operation.
+    Error,
+}
+
+impl LogLevel {
+    /// Returns the lowercase level name used by the bundled sinks.
+    #[must_use]
+    pub const fn as_str(self) -> &'static str {
+        match self {
+            Self::Info => "info",
+            Self::Warning => "warning",
+            Self::Error => "error",
+        }
+    }
+}
+
+impl fmt::Display for LogLevel {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(self.as_str())
+    }
+}
+
+/// Typed value carried by a [`LogRecord`] field.
+#[derive(Clone, Debug, Eq, PartialEq, serde::Serialize)]
+#[serde(untagged)]
+pub enum LogValue {
+    /// Free-form text (module names, addresses, error messages).
+    Str(String),
+    /// Unsigned counter (bytes, durations, protocol versions).
+    U64(u64),
+    /// Boolean flag.
+    Bool(bool),
+}
+
+impl fmt::Display for LogValue {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            Self::Str(value) => f.write_str(value),
+            Self::U64(value) => write!(f, "{value}"),
+            Self::Bool(value) => write!(f, "{value}"),
+        }
+    }
+}
+
+impl From<&str> for LogValue {
+    fn from(value: &str) -> Self {
+        Self::Str(value.to_owned())
+    }
+}
+
+impl From<String> for LogValue {
+    fn from(value: String) -> Self {
+        Self::Str(value)
+    }
+}
+
+impl From<u64> for LogValue {
+    fn from(value: u64) -> Self {
+        Self::U64(value)
+    }
+}
+
+impl From<u8> for LogValue {
+    fn from(value: u8) -> Self {
+        Self::U64(u64::from(value))
+    }
+}
+
+impl From<bool> for LogValue {
+    fn from(value: bool) -> Self {
+        Self::Bool(value)
+    }
+}
+
+/// One structured daemon event.
+///
+/// Fields keep their insertion order so sinks render them deterministically.
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub struct LogRecord {
+    level: LogLevel,
+    event: &'static str,
+    fields: Vec<(&'static str, LogValue)>,
+}
+
+impl LogRecord {
+    /// Creates a record for `event` with no fields.
+    #[must_use]
+    pub fn new(level: LogLevel, event: &'static str) -> Self {
+        Self {
+            level,
+            event,
+            fields: Vec::new(),
+        }
+    }
+
+    /// Appends a field and returns the record.
+    #[must_use]
+    pub fn with(mut self, key: &'static str, value: impl Into<LogValue>) -> Self {
+        self.fields.push((key, value.into()));
+        self
+    }
+
+    /// Appends a field when `value` is `Some`.
+    #[must_use]
+    pub fn with_opt<V: Into<LogValue>>(self, key: &'static str, value: Option<V>) -> Self {
+        match value {
+            Some(value) => self.with(key, value),
+            None => self,
+        }
+    }
+
+    /// Returns the record severity.
+    #[must_use]
+    pub const fn level(&self) -> LogLevel {
+        self.level
+    }
+
+    /// Returns the event name (`connect`, `transfer`, `disconnect`).
+    #[must_use]
+    pub const fn event(&self) -> &'static str {
+        self.event
+    }
+
+    /// Returns the fields in insertion order.
+    #[must_use]
+    pub fn fields(&self) -> &[(&'static str, LogValue)] {
+        &self.fields
+    }
+
+    /// Looks up a field by key.
+    #[must_use]
+    pub fn field(&self, key: &str) -> Option<&LogValue> {
+        self.fields
+            .iter()
+            .find(|(name, _)| *name == key)
+            .map(|(_, value)| value)
+    }
+}
+
+/// Serializes as a flat object: `level` and `event` first, then each field in
+/// insertion order.
+impl Serialize for LogRecord {
+    fn serialize<S: Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+        let mut map = serializer.serialize_map(Some(self.fields.len() + 2))?;
+        map.serialize_entry("level", &self.level)?;
+        map.serialize_entry("event", self.event)?;
+        for (key, value) in &self.fields {
+            map.serialize_entry(key, value)?;
+        }
+        map.end()
+    }
+}
+
+/// Sink for structured daemon events.
+///
+/// The daemon calls [`log`](Self::log) from its connection worker threads, so
+/// implementations must be `Send + Sync`. Sinks must not block for long: the
+/// call happens inline on the connection's thread.
+pub trait DaemonLogger: fmt::Debug + Send + Sync {
+    /// Records one event.
+    fn log(&self, record: &LogRecord);
+}
+
+/// [`DaemonLogger`] that writes one JSON object per line.
+///
+/// The object carries `level` and `event` followed by the record fields, for
+/// example `{"level":"info","event":"connect","peer":"127.0.0.1:40312"}`.
+/// Write errors are swallowed so a broken log pipe never fails a transfer,
+/// matching the text log's behaviour.
+#[derive(Debug)]
+pub struct JsonLogger<W> {
+    out: Mutex<W>,
+}
+
+impl<W: Write + Send> JsonLogger<W> {
+    /// Wraps `out` in a JSON-lines sink.
+    #[must_use]
+    pub fn new(out: W) -> Self {
+        Self {
+            out: Mutex::new(out),
+        }
+    }
+
+    /// Consumes the sink and returns the underlying writer.
+    pub fn into_inner(self) -> W {
+        self.out
+            .into_inner()
+            .unwrap_or_else(PoisonError::into_inner)
+    }
+}
+
+impl<W: Write + Send + fmt::Debug> DaemonLogger for JsonLogger<W> {
+    fn log(&self, record: &LogRecord) {
+        let Ok(mut line) = serde_json::to_string(record) else {
+            return;
+        };
+        line.push('\n');
+        let mut out = self.out.lock().unwrap_or_else(PoisonError::into_inner);
+        let _ = write_line(&mut *out, &line);
+    }
+}
+
+/// [`DaemonLogger`] that writes `key=value` lines.
+///
+/// Lines start with the level and event, for example
+/// `level=info event=transfer module=backup bytes_read=512`. String values
+/// containing whitespace, quotes, or `=` are double-quoted.
+#[derive(Debug)]
+pub struct TextLogger<W> {
+    out: Mutex<W>,
+}
+
+impl<W: Write + Send> TextLogger<W> {
+    /// Wraps `out` in a `key=value` sink.
+    #[must_use]
+    pub fn new(out: W) -> Self {
+        Self {
+            out: Mutex::new(out),
+        }
+    }
+
+    /// Consumes the sink and returns the underlying writer.
+    pub fn into_inner(self) -> W {
+        self.out
+            .into_inner()
+            .unwrap_or_else(PoisonError::into_inner)
+    }
+}
+
+impl<W: Write + Send + fmt::Debug> DaemonLogger for TextLogger<W> {
+    fn log(&self, record: &LogRecord) {
+        let mut line = format!("level={} event={}", record.level(), record.event());
+        for (key, value) in record.fields() {
+            line.push(' ');
+            line.push_str(key);
+            line.push('=');
+            let text = value.to_string();
+            if matches!(value, LogValue::Str(_)) && needs_quoting(&text) {
+                line.push_str(&format!("{text:?}"));
+            } else {
+                line.push_str(&text);
+            }
+        }
+        line.push('\n');
+        let mut out = self.out.lock().unwrap_or_else(PoisonError::into_inner);
+        let _ = write_line(&mut *out, &line);
+    }
+}
+
+fn write_line(out: &mut impl Write, line: &str) -> io::Result<()> {
+    out.write_all(line.as_bytes())?;
+    out.flush()
+}
+
+fn needs_quoting(text: &str) -> bool {
+    text.is_empty()
+        || text
+            .chars()
+            .any(|c| c.is_whitespace() || c == '"' || c == '=' || c.is_control())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn sample() -> LogRecord {
+        LogRecord::new(LogLevel::Info, "transfer")
+            .with("module", "backup")
+            .with("bytes_read", 512u64)
+            .with_opt::<&str>("host", None)
+            .with("error", "bad \"quote\"\n")
+    }
+
+    #[test]
+    fn record_field_lookup() {
+        let record = sample();
+        assert_eq!(record.event(), "transfer");
+        assert_eq!(record.level(), LogLevel::Info);
+        assert_eq!(record.field("module"), Some(&LogValue::from("backup")));
+        assert_eq!(record.field("bytes_read"), Some(&LogValue::U64(512)));
+        assert!(record.field("host").is_none());
+        assert_eq!(record.fields().len(), 3);
+    }
+
+    #[test]
+    fn json_logger_writes_one_object_per_line() {
+        let logger = JsonLogger::new(Vec::new());
+        logger.log(&sample());
+        logger.log(&LogRecord::new(LogLevel::Warning, "disconnect").with("ok", true));
+        let text = String::from_utf8(logger.into_inner()).unwrap();
+        let lines: Vec<&str> = text.lines().collect();
+        assert_eq!(
+            lines,
+            [
+                r#"{"level":"info","event":"transfer","module":"backup","bytes_read":512,"error":"bad \"quote\"\n"}"#,
+                r#"{"level":"warning","event":"disconnect","ok":true}"#,
+            ]
+        );
+    }
+
+    #[test]
+    fn json_escapes_control_characters() {
+        let logger = JsonLogger::new(Vec::new());
+        logger.log(&LogRecord::new(LogLevel::Error, "transfer").with("error", "a\u{1}b\\"));
+        let text = String::from_utf8(logger.into_inner()).unwrap();
+        assert_eq!(
+            text,
+            "{\"level\":\"error\",\"event\":\"transfer\",\"error\":\"a\\u0001b\\\\\"}\n"
+        );
+    }
+
+    #[test]
+    fn text_logger_quotes_only_when_needed() {
+        let logger = TextLogger::new(Vec::new());
+        logger.log(&sample());
+        let text = String::from_utf8(logger.into_inner()).unwrap();
+        assert_eq!(
+            text,
+            "level=info event=transfer module=backup bytes_read=512 error=\"bad \\\"quote\\\"\\n\"\n"
+        );
+    }
+}
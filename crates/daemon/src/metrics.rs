@@ -0,0 +1,120 @@
+//! Metrics hooks for the daemon.
+//!
+//! The daemon does not depend on any metrics library. Instead it calls a
+//! [`DaemonMetrics`] implementation, installed through
+//! [`crate::DaemonConfigBuilder::metrics`], at fixed points in every
+//! connection's lifecycle. An embedder backs the trait with Prometheus,
+//! OpenTelemetry, StatsD, or plain atomics.
+//!
+//! Call points, in order, for one TCP connection:
+//!
+//! 1. [`DaemonMetrics::connection_opened`] once the worker starts serving the
+//!    accepted socket.
+//! 2. [`DaemonMetrics::session_error`] for each refusal or failure
+//!    ([`SessionError`] lists the categories).
+//! 3. [`DaemonMetrics::transfer_finished`] after the transfer engine returns,
+//!    with byte and file totals and the wall-clock duration.
+//! 4. [`DaemonMetrics::connection_closed`] when the worker is done with the
+//!    socket, with the connection's total duration.
+//!
+//! A typical Prometheus mapping is a `connections_total` counter plus an
+//! `active_connections` gauge from steps 1 and 4, `bytes_received_total`,
+//! `bytes_sent_total`, and `files_total` counters plus a
+//! `transfer_duration_seconds` histogram from step 3, and an `errors_total`
+//! counter labelled by [`SessionError::as_str`] from step 2.
+//!
+//! Hooks run inline on the connection's thread, so implementations should
+//! only update counters and must not block. Every method has a no-op default,
+//! letting an implementation override only what it records.
+
+use std::fmt;
+use std::time::Duration;
+
+/// Shared handle to an installed [`DaemonMetrics`] sink.
+pub(crate) type SharedDaemonMetrics = std::sync::Arc<dyn DaemonMetrics>;
+
+/// Failure categories reported through [`DaemonMetrics::session_error`].
+#[derive(Clone, Copy, Debug, Eq, Hash, PartialEq)]
+#[non_exhaustive]
+pub enum SessionError {
+    /// The client requested a module that does not exist.
+    UnknownModule,
+    /// `hosts allow` / `hosts deny` rejected the client.
+    AccessDenied,
+    /// Challenge/response authentication failed.
+    AuthFailed,
+    /// The transfer engine returned an error.
+    TransferFailed,
+    /// The connection handler panicked; the daemon kept serving.
+    Panicked,
+}
+
+impl SessionError {
+    /// Returns a stable `snake_case` label suitable for a metric label value.
+    #[must_use]
+    pub const fn as_str(self) -> &'static str {
+        match self {
+            Self::UnknownModule => "unknown_module",
+            Self::AccessDenied => "access_denied",
+            Self::AuthFailed => "auth_failed",
+            Self::TransferFailed => "transfer_failed",
+            Self::Panicked => "panicked",
+        }
+    }
+}
+
+impl fmt::Display for SessionError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(self.as_str())
+    }
+}
+
+/// Totals for one module transfer, handed to
+/// [`DaemonMetrics::transfer_finished`].
+///
+/// Byte counts are seen from the daemon: `bytes_received` is what the client
+/// sent, `bytes_sent` what the daemon sent back (upstream
+/// `stats.total_read` / `stats.total_written`). A failed transfer reports
+/// zero bytes and files because the engine returns no stats.
+#[derive(Clone, Debug, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct TransferMetrics<'a> {
+    /// Module name as requested by the client.
+    pub module: &'a str,
+    /// `"send"` when the daemon sent files, `"recv"` when it received them.
+    pub operation: &'static str,
+    /// Bytes read from the client.
+    pub bytes_received: u64,
+    /// Bytes written to the client.
+    pub bytes_sent: u64,
+    /// Files transferred (sent or received).
+    pub files: u64,
+    /// Time spent in the transfer engine.
+    pub duration: Duration,
+    /// Whether the transfer completed without error.
+    pub success: bool,
+}
+
+/// Metrics sink invoked by the daemon at well-defined lifecycle points.
+///
+/// See the [module documentation](self) for the call order. Implementations
+/// must be `Send + Sync` because every connection worker shares one sink.
+pub trait DaemonMetrics: fmt::Debug + Send + Sync {
+    /// A connection was accepted and its session is starting.
+    fn connection_opened(&self) {}
+
+    /// A connection finished after `duration`.
+    fn connection_closed(&self, duration: Duration) {
+        let _ = duration;
+    }
+
+    /// A module transfer finished (successfully or not).
+    fn transfer_finished(&self, transfer: &TransferMetrics<'_>) {
+        let _ = transfer;
+    }
+
+    /// A session was refused or failed.
+    fn session_error(&self, error: SessionError) {
+        let _ = error;
+    }
+}
@@ -0,0 +1,183 @@
+//! Programmatic graceful shutdown for an embedded daemon.
+//!
+//! A [`ShutdownHandle`] installed through
+//! [`crate::DaemonConfigBuilder::shutdown_handle`] lets the embedder stop a
+//! running [`crate::run_daemon`] (or `run_async_daemon`) the way SIGUSR1
+//! does: the accept loop stops taking connections, the listening sockets are
+//! closed so new clients are refused, and every in-flight session runs to
+//! completion before the entry point returns. [`ShutdownHandle::shutdown`] blocks until that drain
+//! finishes or the timeout elapses, whichever comes first.
+//!
+//! Sessions run on threads that cannot be cancelled, so a timeout does not
+//! abort them. It only returns control to the caller, who can then exit the
+//! process or keep waiting on the daemon thread.
+
+use std::error::Error;
+use std::fmt;
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::{Arc, Condvar, Mutex, PoisonError};
+use std::time::{Duration, Instant};
+
+/// Handle used to request a drain-and-exit of a running daemon.
+///
+/// Clones share state, so the embedder keeps one clone and passes another to
+/// the builder.
+#[derive(Clone, Debug, Default)]
+pub struct ShutdownHandle {
+    inner: Arc<ShutdownState>,
+}
+
+#[derive(Debug, Default)]
+struct ShutdownState {
+    requested: AtomicBool,
+    stopped: Mutex<bool>,
+    stopped_changed: Condvar,
+}
+
+impl ShutdownHandle {
+    /// Creates a handle with no shutdown requested.
+    #[must_use]
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Stops accepting connections and waits up to `timeout` for in-flight
+    /// sessions to finish.
+    ///
+    /// Returns `Ok(())` once the daemon has drained every session and left its
+    /// accept loop. Calling it again after that returns immediately.
+    ///
+    /// # Errors
+    ///
+    /// Returns [`ShutdownTimedOut`] when sessions are still running at the
+    /// deadline. They keep running; a later call waits again.
+    pub fn shutdown(&self, timeout: Duration) -> Result<(), ShutdownTimedOut> {
+        self.inner.requested.store(true, Ordering::Relaxed);
+
+        let deadline = Instant::now() + timeout;
+        let mut stopped = self
+            .inner
+            .stopped
+            .lock()
+            .unwrap_or_else(PoisonError::into_inner);
+        while !*stopped {
+            let remaining = deadline.saturating_duration_since(Instant::now());
+            if remaining.is_zero() {
+                return Err(ShutdownTimedOut { timeout });
+            }
+            stopped = self
+                .inner
+                .stopped_changed
+                .wait_timeout(stopped, remaining)
+                .unwrap_or_else(PoisonError::into_inner)
+                .0;
+        }
+        Ok(())
+    }
+
+    /// Reports whether the daemon has finished draining and stopped.
+    #[must_use]
+    pub fn is_stopped(&self) -> bool {
+        *self
+            .inner
+            .stopped
+            .lock()
+            .unwrap_or_else(PoisonError::into_inner)
+    }
+
+    /// Reports whether [`shutdown`](Self::shutdown) has been called.
+    pub(crate) fn is_requested(&self) -> bool {
+        self.inner.requested.load(Ordering::Relaxed)
+    }
+
+    /// Returns a guard that marks the daemon stopped when dropped, so waiters
+    /// are released on every exit path, including startup errors.
+    pub(crate) fn stop_on_drop(&self) -> StopOnDrop {
+        StopOnDrop(self.clone())
+    }
+
+    fn mark_stopped(&self) {
+        let mut stopped = self
+            .inner
+            .stopped
+            .lock()
+            .unwrap_or_else(PoisonError::into_inner);
+        *stopped = true;
+        self.inner.stopped_changed.notify_all();
+    }
+}
+
+/// Marks the owning [`ShutdownHandle`] stopped on drop.
+pub(crate) struct StopOnDrop(ShutdownHandle);
+
+impl Drop for StopOnDrop {
+    fn drop(&mut self) {
+        self.0.mark_stopped();
+    }
+}
+
+/// Error returned when sessions outlive the [`ShutdownHandle::shutdown`]
+/// timeout.
+#[derive(Clone, Copy, Debug, Eq, PartialEq)]
+pub struct ShutdownTimedOut {
+    timeout: Duration,
+}
+
+impl ShutdownTimedOut {
+    /// Returns the timeout that elapsed.
+    #[must_use]
+    pub const fn timeout(&self) -> Duration {
+        self.timeout
+    }
+}
+
+impl fmt::Display for ShutdownTimedOut {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "daemon sessions still active after {:?} shutdown timeout",
+            self.timeout
+        )
+    }
+}
+
+impl Error for ShutdownTimedOut {}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::thread;
+
+    #[test]
+    fn shutdown_times_out_while_daemon_runs() {
+        let handle = ShutdownHandle::new();
+        let err = handle
+            .shutdown(Duration::from_millis(20))
+            .expect_err("nothing marked the daemon stopped");
+        assert_eq!(err.timeout(), Duration::from_millis(20));
+        assert!(handle.is_requested());
+        assert!(!handle.is_stopped());
+    }
+
+    #[test]
+    fn shutdown_returns_once_guard_drops() {
+        let handle = ShutdownHandle::new();
+        let daemon_side = handle.clone();
+        let daemon = thread::spawn(move || {
+            let _stop = daemon_side.stop_on_drop();
+            while !daemon_side.is_requested() {
+                thread::sleep(Duration::from_millis(5));
+            }
+        });
+
+        handle
+            .shutdown(Duration::from_secs(10))
+            .expect("guard drop releases the waiter");
+        assert!(handle.is_stopped());
+        daemon.join().expect("daemon thread");
+
+        handle
+            .shutdown(Duration::ZERO)
+            .expect("already stopped returns immediately");
+    }
+}
@@ -7,6 +7,7 @@ use std::io::{BufRead, BufReader, Read, Write};
 use std::net::{IpAddr, Ipv4Addr, Ipv6Addr, TcpStream};
 use std::num::{NonZeroU32, NonZeroU64};
 use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicU64, Ordering};
 use std::sync::{Arc, Barrier};
 use std::thread;
 use std::time::{Duration, Instant};
@@ -107,6 +108,16 @@ include!("tests/chunks/connection_limiter_enforces_limits_across_guards.rs");
 include!("tests/chunks/connection_limiter_open_preserves_existing_counts.rs");
 include!("tests/chunks/connection_limiter_propagates_io_errors.rs");
 include!("tests/chunks/connection_limiter_reclaims_slot_on_close_without_decrement.rs");
+include!("tests/chunks/connection_limiter_shares_slots_across_daemons.rs");
+include!("tests/chunks/daemon_sighup_reload_keeps_inflight_transfer.rs");
+include!("tests/chunks/daemon_shutdown_handle_drains_inflight_transfer.rs");
+include!("tests/chunks/daemon_module_timeout_overrides_global.rs");
+include!("tests/chunks/daemon_unix_socket_listing_and_pull.rs");
+include!("tests/chunks/daemon_tls_transfer_and_plaintext_rejection.rs");
+include!("tests/chunks/daemon_proxy_protocol_v1_forwards_client_address.rs");
+include!("tests/chunks/daemon_connection_rate_limit_refuses_burst.rs");
+include!("tests/chunks/daemon_atimes_pull_preserves_access_and_mtime.rs");
+include!("tests/chunks/daemon_file_hooks_pull_skips_vetoed_file.rs");
 include!("tests/chunks/connection_status_messages_describe_active_sessions.rs");
 include!("tests/chunks/default_config_candidates_prefer_legacy_for_upstream_brand.rs");
 include!("tests/chunks/default_config_candidates_prefer_oc_branding.rs");
@@ -200,6 +211,7 @@ include!("tests/chunks/read_trimmed_line_strips_crlf_terminators.rs");
 include!("tests/chunks/read_trimmed_line_strips_lf_only.rs");
 include!("tests/chunks/read_trimmed_line_strips_multiple_cr_lf.rs");
 include!("tests/chunks/run_daemon_accepts_valid_credentials.rs");
+include!("tests/chunks/run_daemon_resolves_user_templated_module_path.rs");
 include!("tests/chunks/daemon_error_payloads_match_upstream_wording.rs");
 include!("tests/chunks/daemon_max_connections_wire_bytes_match_upstream.rs");
 include!("tests/chunks/daemon_per_module_cap_wire_bytes_match_global_path.rs");
@@ -221,6 +233,7 @@ include!("tests/chunks/run_daemon_omits_unlisted_modules_from_listing.rs");
 include!("tests/chunks/run_daemon_panic_isolation_keeps_daemon_alive.rs");
 include!("tests/chunks/run_daemon_post_ok_refused_option_uses_multiplexed_error.rs");
 include!("tests/chunks/run_daemon_records_log_file_entries.rs");
+include!("tests/chunks/run_daemon_transfer_logging_writes_line_per_file.rs");
 include!("tests/chunks/run_daemon_refuses_disallowed_module_options.rs");
 include!("tests/chunks/run_daemon_rejects_duplicate_session_limits.rs");
 include!("tests/chunks/run_daemon_rejects_invalid_max_sessions.rs");
@@ -229,9 +242,12 @@ include!("tests/chunks/run_daemon_rejects_unknown_argument.rs");
 include!("tests/chunks/run_daemon_rejects_push_to_read_only_module.rs");
 include!("tests/chunks/run_daemon_runs_post_xfer_exec_on_read_only_refuse.rs");
 include!("tests/chunks/run_daemon_runs_post_xfer_exec_on_early_exec_failure.rs");
+include!("tests/chunks/run_daemon_post_xfer_exec_sees_transfer_error_status.rs");
+include!("tests/chunks/run_daemon_early_exec_reads_client_early_input.rs");
 include!("tests/chunks/run_daemon_serves_slow_handshake.rs");
 include!("tests/chunks/run_daemon_rejects_push_to_default_read_only_module.rs");
 include!("tests/chunks/daemon_pre_xfer_exec_rejects_on_nonzero_exit.rs");
+include!("tests/chunks/daemon_pre_xfer_exec_rejects_based_on_env.rs");
 include!("tests/chunks/run_daemon_requests_authentication_for_protected_module.rs");
 include!("tests/chunks/run_daemon_auth_failure_rejects_wrong_credentials.rs");
 include!("tests/chunks/run_daemon_sends_motd_before_unknown_module_error.rs");
@@ -255,15 +271,21 @@ include!("tests/chunks/daemon_files_from_stdin_pull.rs");
 // Daemon module filter rule merging with client-side filters (#1887)
 include!("tests/chunks/daemon_filter_merge_with_client_filters.rs");
 include!("tests/chunks/daemon_safe_links_filters_unsafe_symlinks_on_push.rs");
+include!("tests/chunks/daemon_ignore_nonreadable_skips_unreadable_on_pull.rs");
 include!("tests/chunks/daemon_safe_links_receive.rs");
 // Daemon `munge symlinks = yes` round-trip tests (push + pull)
 include!("tests/chunks/daemon_munge_symlinks_push.rs");
 include!("tests/chunks/daemon_munge_symlinks_pull.rs");
+// Daemon `use chroot = no` sender confinement test
+include!("tests/chunks/daemon_unchrooted_sender_stays_in_module.rs");
 include!("tests/chunks/daemon_compare_dest_push.rs");
 // Daemon relative receive end-to-end test
 include!("tests/chunks/daemon_relative_receive.rs");
 // Daemon sub-path pull resolution (UTS-3)
 include!("tests/chunks/daemon_pull_subpath.rs");
+include!("tests/chunks/daemon_module_bwlimit_throttles_unlimited_pull.rs");
+// Daemon single-file module pull
+include!("tests/chunks/daemon_single_file_module_pull.rs");
 // Daemon combined hardlinks + relative receive end-to-end test
 include!("tests/chunks/daemon_hardlinks_relative_receive.rs");
 // Daemon itemize end-to-end tests
@@ -413,6 +435,7 @@ include!("tests/chunks/daemon_acl_push.rs");
 include!("tests/chunks/daemon_inc_recurse_push.rs");
 // Daemon checksum mode push end-to-end test
 include!("tests/chunks/daemon_checksum_push.rs");
+include!("tests/chunks/daemon_sparse_push_beyond_4gib.rs");
 // Daemon compress push end-to-end test
 include!("tests/chunks/daemon_compress_push.rs");
 // Daemon compress pull goodbye-drain regression (UTS-v3 Cluster A)
@@ -427,3 +450,5 @@ include!("tests/chunks/daemon_delete_pull_emits_stats.rs");
 include!("tests/chunks/daemon_inplace_push.rs");
 // Daemon push/pull lifecycle end-to-end tests
 include!("tests/chunks/daemon_push_pull_lifecycle.rs");
+include!("tests/chunks/daemon_structured_logger_records_connection.rs");
+include!("tests/chunks/daemon_metrics_hooks_count_transfer.rs");
@@ -0,0 +1,43 @@
+// Two daemons pointed at the same `lock file` share one `max connections`
+// budget: each limiter instance below stands in for a separate daemon process
+// that opened the file itself, and a slot claimed through either one is
+// unavailable to the other until released.
+//
+// upstream: connection.c:26 `claim_connection()` - every daemon process locks
+// four-byte ranges of the shared lock file, so the count is mediated by the
+// file rather than by any in-process state.
+//
+// Requires open file description locks so the two instances in this one
+// process contend; only Linux provides them.
+#[cfg(target_os = "linux")]
+#[test]
+fn connection_limiter_shares_slots_across_daemons() {
+    let temp = tempdir().expect("lock dir");
+    let lock_path = temp.path().join("rsyncd.lock");
+    let first_daemon =
+        Arc::new(ConnectionLimiter::open(lock_path.clone()).expect("open lock file"));
+    let second_daemon = Arc::new(ConnectionLimiter::open(lock_path).expect("reopen lock file"));
+    let limit = NonZeroU32::new(2).expect("non-zero");
+
+    let held_by_first = first_daemon
+        .acquire("docs", limit)
+        .expect("first daemon claims a slot");
+    let held_by_second = second_daemon
+        .acquire("docs", limit)
+        .expect("second daemon claims the other slot");
+
+    for limiter in [&first_daemon, &second_daemon] {
+        assert!(matches!(
+            limiter.acquire("docs", limit),
+            Err(ModuleConnectionError::Limit(l)) if l == limit
+        ));
+    }
+
+    drop(held_by_second);
+    let reclaimed = first_daemon
+        .acquire("docs", limit)
+        .expect("slot released by the second daemon is reusable");
+
+    drop(reclaimed);
+    drop(held_by_first);
+}
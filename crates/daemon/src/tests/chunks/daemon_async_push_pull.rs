@@ -177,6 +177,83 @@ fn daemon_async_rejects_privileged_module() {
     );
 }
 
+#[cfg(all(unix, feature = "async-daemon"))]
+#[test]
+fn daemon_async_rejects_unix_socket_address() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+
+    let temp = tempdir().expect("tempdir");
+    let module_dir = temp.path().join("module");
+    fs::create_dir_all(&module_dir).expect("create module dir");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[files]\npath = {}\nuse chroot = no\n",
+        module_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let socket = temp.path().join("rsyncd.sock");
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--no-detach"),
+            OsString::from("--address"),
+            OsString::from(format!("unix:{}", socket.display())),
+        ])
+        .build();
+
+    let error = crate::run_async_daemon(daemon_config).expect_err("unix socket must be rejected");
+    let rendered = error.message().to_string();
+    assert!(
+        rendered.contains("async-daemon does not support --address unix:"),
+        "expected unix-socket refusal, got: {rendered}"
+    );
+    assert!(!socket.exists(), "no socket may be bound");
+}
+
+#[cfg(all(unix, feature = "async-daemon"))]
+#[test]
+fn daemon_async_rejects_max_connection_rate() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+
+    let temp = tempdir().expect("tempdir");
+    let module_dir = temp.path().join("module");
+    fs::create_dir_all(&module_dir).expect("create module dir");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "max connection rate = 3\n[files]\npath = {}\nuse chroot = no\n",
+        module_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    drop(held_listener);
+
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--no-detach"),
+            OsString::from("--address"),
+            OsString::from("127.0.0.1"),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+        ])
+        .build();
+
+    let error = crate::run_async_daemon(daemon_config).expect_err("rate limit must be rejected");
+    let rendered = error.message().to_string();
+    assert!(
+        rendered.contains("async-daemon does not support 'max connection rate'"),
+        "expected rate-limit refusal, got: {rendered}"
+    );
+}
+
 /// The async accept loop's worker-thread cap must never throttle below the
 /// operator's configured `max connections`, while still applying the
 /// flood-protection floor when the limit is unset or lower than the floor.
@@ -0,0 +1,81 @@
+/// `-U` / `--atimes` on a daemon pull carries each file's access time in the
+/// file list and the receiver restores it next to the mtime.
+///
+/// The atime is compared at second precision: upstream applies it with a
+/// zero nanosecond field (rsync.c:609), and the sender's own read may bump
+/// the source atime after the file list has already captured it.
+#[cfg(unix)]
+#[test]
+fn daemon_atimes_pull_preserves_access_and_mtime() {
+    use filetime::{FileTime, set_file_times};
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    let source_file = source_dir.join("stamped.txt");
+    fs::write(&source_file, b"atime payload\n").expect("write payload");
+    let atime = FileTime::from_unix_time(1_600_000_000, 0);
+    let mtime = FileTime::from_unix_time(1_650_000_000, 0);
+    set_file_times(&source_file, atime, mtime).expect("set source times");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[files]\npath = {}\nread only = true\nuse chroot = false\n",
+            source_dir.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            OsString::from(format!("rsync://127.0.0.1:{port}/files/")),
+            OsString::from(dest_dir.as_os_str()),
+        ])
+        .recursive(true)
+        .times(true)
+        .atimes(1)
+        .build();
+    let result = core::client::run_client(client_config);
+    if let Err(e) = &result {
+        panic!("pull with --atimes failed: {e}");
+    }
+
+    let metadata = fs::metadata(dest_dir.join("stamped.txt")).expect("dest metadata");
+    assert_eq!(
+        FileTime::from_last_modification_time(&metadata),
+        mtime,
+        "mtime must match the source"
+    );
+    assert_eq!(
+        FileTime::from_last_access_time(&metadata).unix_seconds(),
+        atime.unix_seconds(),
+        "atime must match the source with --atimes"
+    );
+
+    if let Some(result) = finish_daemon(daemon_handle) {
+        assert!(result.is_ok(), "daemon failed: {result:?}");
+    }
+}
@@ -0,0 +1,72 @@
+/// `max connection rate` refuses connections from one address once its
+/// per-minute allowance is spent.
+///
+/// With a rate of 3, the first three connections from loopback receive the
+/// `@RSYNCD:` greeting and every further one in the same burst is refused
+/// with `@ERROR:` before a session starts.
+#[test]
+fn daemon_connection_rate_limit_refuses_burst() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let dir = tempdir().expect("config dir");
+    let module_dir = dir.path().join("module");
+    fs::create_dir_all(&module_dir).expect("module dir");
+    let config_path = dir.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "max connection rate = 3\n[files]\npath = {}\nuse chroot = false\n",
+            module_dir.display()
+        ),
+    )
+    .expect("write config");
+
+    let (port, held_listener) = allocate_test_port();
+    let shutdown = crate::ShutdownHandle::new();
+    let config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--config"),
+            config_path.as_os_str().to_os_string(),
+        ])
+        .shutdown_handle(shutdown.clone())
+        .build();
+
+    let first_line = |stream: TcpStream| -> String {
+        let mut reader = BufReader::new(stream);
+        let mut line = String::new();
+        reader.read_line(&mut line).expect("first line");
+        line
+    };
+
+    let (probe, handle) = start_daemon(config, port, held_listener);
+    let mut replies = vec![first_line(probe)];
+    for _ in 0..4 {
+        replies.push(first_line(connect_with_retries(port)));
+    }
+
+    for (index, reply) in replies.iter().enumerate().take(3) {
+        assert!(
+            reply.starts_with("@RSYNCD:"),
+            "connection {index} is within the rate and must be greeted, got: {reply:?}"
+        );
+    }
+    for (index, reply) in replies.iter().enumerate().skip(3) {
+        assert_eq!(
+            reply, "@ERROR: connection rate limit (3 per minute) reached -- try again later\n",
+            "connection {index} exceeds the rate and must be refused"
+        );
+    }
+
+    shutdown
+        .shutdown(Duration::from_secs(20))
+        .expect("daemon drains after shutdown");
+    handle
+        .join()
+        .expect("daemon thread")
+        .expect("daemon exits cleanly");
+}
@@ -0,0 +1,99 @@
+/// Library file hooks run in the pulling client's receiver: a vetoed file is
+/// never requested from the daemon and is not written, and the start and done
+/// callbacks see only the files that were transferred.
+#[cfg(unix)]
+#[test]
+fn daemon_file_hooks_pull_skips_vetoed_file() {
+    use std::path::{Path, PathBuf};
+    use std::sync::{Arc, Mutex};
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    fs::write(source_dir.join("keep.txt"), b"keep\n").expect("write keep");
+    fs::write(source_dir.join("private.txt"), b"private\n").expect("write private");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[files]\npath = {}\nread only = true\nuse chroot = false\n",
+            source_dir.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let started = Arc::new(Mutex::new(Vec::new()));
+    let done = Arc::new(Mutex::new(Vec::new()));
+    let hooks = core::client::LocalCopyFileHooks::new()
+        .should_transfer(|file| file.relative_path() != Path::new("private.txt"))
+        .on_file_start({
+            let started = Arc::clone(&started);
+            move |file| {
+                started
+                    .lock()
+                    .unwrap()
+                    .push(file.relative_path().to_path_buf())
+            }
+        })
+        .on_file_done({
+            let done = Arc::clone(&done);
+            move |file, error| {
+                done.lock()
+                    .unwrap()
+                    .push((file.relative_path().to_path_buf(), error.is_some()));
+            }
+        });
+
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            OsString::from(format!("rsync://127.0.0.1:{port}/files/")),
+            OsString::from(dest_dir.as_os_str()),
+        ])
+        .recursive(true)
+        .file_hooks(hooks)
+        .build();
+    let result = core::client::run_client(client_config);
+    if let Err(e) = &result {
+        panic!("pull with file hooks failed: {e}");
+    }
+
+    assert_eq!(
+        fs::read(dest_dir.join("keep.txt")).expect("read keep"),
+        b"keep\n"
+    );
+    assert!(
+        !dest_dir.join("private.txt").exists(),
+        "vetoed file must not be written"
+    );
+    assert_eq!(*started.lock().unwrap(), vec![PathBuf::from("keep.txt")]);
+    assert_eq!(
+        *done.lock().unwrap(),
+        vec![(PathBuf::from("keep.txt"), false)]
+    );
+
+    if let Some(result) = finish_daemon(daemon_handle) {
+        assert!(result.is_ok(), "daemon failed: {result:?}");
+    }
+}
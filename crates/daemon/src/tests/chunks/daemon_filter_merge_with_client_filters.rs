@@ -342,3 +342,88 @@ fn daemon_exclude_from_file_matches_inline_exclude() {
 
     let _ = daemon_handle.join().expect("daemon thread");
 }
+
+/// Daemon `exclude = lost+found/` hides every `lost+found` directory, at the
+/// module root and below it, even when the client explicitly includes it and
+/// then asks for everything else.
+///
+/// Mirrors the interop fixture's module configuration. The pattern has no
+/// leading slash, so it matches at any depth (upstream `exclude.c`
+/// `rule_matches()` tries every path tail for unanchored patterns).
+#[cfg(unix)]
+#[test]
+fn daemon_exclude_lost_found_hidden_from_full_pull() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+
+    let source_dir = temp.path().join("source");
+    fs::create_dir_all(source_dir.join("lost+found")).expect("create lost+found");
+    fs::create_dir_all(source_dir.join("data/lost+found")).expect("create nested lost+found");
+    fs::write(source_dir.join("lost+found/#1234"), b"orphan\n").expect("write orphan");
+    fs::write(source_dir.join("data/lost+found/#5678"), b"orphan\n").expect("write nested orphan");
+    fs::write(source_dir.join("data/file.txt"), b"data\n").expect("write data file");
+
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[mod]\n\
+         path = {}\n\
+         read only = true\n\
+         use chroot = false\n\
+         exclude = lost+found/\n",
+        source_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+
+    let daemon_config = crate::DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let rsync_url = format!("rsync://127.0.0.1:{port}/mod/");
+    let client_config = core::client::ClientConfig::builder()
+        .recursive(true)
+        .transfer_args([OsString::from(&rsync_url), OsString::from(dest_dir.as_os_str())])
+        .add_filter_rule(core::client::FilterRuleSpec::include("lost+found/"))
+        .add_filter_rule(core::client::FilterRuleSpec::include("*"))
+        .build();
+
+    let result = core::client::run_client(client_config);
+
+    if let Err(e) = &result {
+        let _ = daemon_handle.join();
+        panic!("transfer failed: {e}");
+    }
+
+    assert_eq!(
+        fs::read(dest_dir.join("data/file.txt")).expect("read data/file.txt"),
+        b"data\n"
+    );
+    assert!(
+        !dest_dir.join("lost+found").exists(),
+        "daemon-excluded lost+found must not reach the client"
+    );
+    assert!(
+        !dest_dir.join("data/lost+found").exists(),
+        "unanchored exclude must hide nested lost+found too"
+    );
+
+    let _ = daemon_handle.join().expect("daemon thread");
+}
@@ -0,0 +1,92 @@
+/// End-to-end test for the `ignore nonreadable` module directive.
+///
+/// The module holds `visible.txt` and a mode-000 `secret.txt`. With the
+/// directive set, a pull succeeds and delivers only `visible.txt`: the sender
+/// drops `secret.txt` from the file list instead of failing to open it.
+///
+/// The test returns early when the process can read a mode-000 file (root or
+/// `CAP_DAC_OVERRIDE`), since the scenario cannot be produced there.
+///
+/// # Upstream Reference
+///
+/// - `flist.c:make_file()` - `lp_ignore_nonreadable(module_id)` plus
+///   `access(thisname, R_OK)`
+#[cfg(unix)]
+#[test]
+fn daemon_ignore_nonreadable_skips_unreadable_on_pull() {
+    use std::os::unix::fs::PermissionsExt;
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    fs::write(source_dir.join("visible.txt"), b"visible\n").expect("write visible.txt");
+    let secret = source_dir.join("secret.txt");
+    fs::write(&secret, b"secret\n").expect("write secret.txt");
+    fs::set_permissions(&secret, fs::Permissions::from_mode(0o000)).expect("chmod secret.txt");
+    if fs::File::open(&secret).is_ok() {
+        return;
+    }
+
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[hidden]\n\
+         path = {}\n\
+         read only = true\n\
+         use chroot = false\n\
+         ignore nonreadable = yes\n",
+        source_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let rsync_url = format!("rsync://127.0.0.1:{port}/hidden/");
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            OsString::from(&rsync_url),
+            OsString::from(dest_dir.as_os_str()),
+        ])
+        .recursive(true)
+        .build();
+
+    let result = core::client::run_client(client_config);
+    fs::set_permissions(&secret, fs::Permissions::from_mode(0o644)).expect("restore secret.txt");
+    if let Err(e) = &result {
+        let _ = daemon_handle.join();
+        panic!("pull from ignore nonreadable module failed: {e}");
+    }
+
+    assert_eq!(
+        fs::read(dest_dir.join("visible.txt")).expect("read visible.txt"),
+        b"visible\n"
+    );
+    assert!(
+        dest_dir.join("secret.txt").symlink_metadata().is_err(),
+        "unreadable file must be skipped"
+    );
+
+    let _ = daemon_handle.join().expect("daemon thread");
+}
@@ -0,0 +1,138 @@
+/// Fake [`DaemonMetrics`] sink backed by atomics, shaped like the counters an
+/// embedder would register with Prometheus.
+#[derive(Debug, Default)]
+struct CountingMetrics {
+    connections_opened: AtomicU64,
+    connections_closed: AtomicU64,
+    transfers: AtomicU64,
+    bytes_received: AtomicU64,
+    bytes_sent: AtomicU64,
+    files: AtomicU64,
+    errors: std::sync::Mutex<Vec<SessionError>>,
+    operations: std::sync::Mutex<Vec<(String, &'static str, bool)>>,
+}
+
+impl DaemonMetrics for CountingMetrics {
+    fn connection_opened(&self) {
+        self.connections_opened.fetch_add(1, Ordering::SeqCst);
+    }
+
+    fn connection_closed(&self, _duration: Duration) {
+        self.connections_closed.fetch_add(1, Ordering::SeqCst);
+    }
+
+    fn transfer_finished(&self, transfer: &TransferMetrics<'_>) {
+        self.transfers.fetch_add(1, Ordering::SeqCst);
+        self.bytes_received
+            .fetch_add(transfer.bytes_received, Ordering::SeqCst);
+        self.bytes_sent
+            .fetch_add(transfer.bytes_sent, Ordering::SeqCst);
+        self.files.fetch_add(transfer.files, Ordering::SeqCst);
+        self.operations.lock().expect("operations lock").push((
+            transfer.module.to_owned(),
+            transfer.operation,
+            transfer.success,
+        ));
+    }
+
+    fn session_error(&self, error: SessionError) {
+        self.errors.lock().expect("errors lock").push(error);
+    }
+}
+
+/// Metrics installed through `DaemonConfigBuilder::metrics` see one
+/// open/close pair per connection and the byte and file totals of the push,
+/// and an unknown-module request is counted as a session error.
+#[cfg(unix)]
+#[test]
+fn daemon_metrics_hooks_count_connections_and_bytes() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    let payload = vec![b'm'; 4096];
+    fs::write(source_dir.join("payload.bin"), &payload).expect("write payload");
+
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module dir");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[metricsmod]\n\
+         path = {}\n\
+         read only = false\n\
+         use chroot = false\n",
+        module_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let metrics = Arc::new(CountingMetrics::default());
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .metrics(metrics.clone())
+        .build();
+
+    // Session 1: the readiness probe asks for a module that does not exist.
+    let (mut stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    let mut reader = BufReader::new(stream.try_clone().expect("clone stream"));
+    let mut line = String::new();
+    reader.read_line(&mut line).expect("greeting");
+    stream
+        .write_all(b"@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4\nnosuchmod\n")
+        .expect("send module request");
+    stream.flush().expect("flush module request");
+    line.clear();
+    reader.read_line(&mut line).expect("error message");
+    assert_eq!(line, "@ERROR: Unknown module 'nosuchmod'\n");
+    drop(reader);
+    drop(stream);
+
+    // Session 2: the push.
+    let mut source_arg = source_dir.into_os_string();
+    source_arg.push("/");
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            source_arg,
+            OsString::from(format!("rsync://127.0.0.1:{port}/metricsmod/")),
+        ])
+        .build();
+    if let Err(error) = core::client::run_client(client_config) {
+        let _ = finish_daemon(daemon_handle);
+        panic!("push failed: {error}");
+    }
+    let _ = finish_daemon(daemon_handle);
+
+    assert_eq!(metrics.connections_opened.load(Ordering::SeqCst), 2);
+    assert_eq!(metrics.connections_closed.load(Ordering::SeqCst), 2);
+    assert_eq!(metrics.transfers.load(Ordering::SeqCst), 1);
+    assert_eq!(metrics.files.load(Ordering::SeqCst), 1);
+    assert!(
+        metrics.bytes_received.load(Ordering::SeqCst) >= payload.len() as u64,
+        "the pushed payload must be counted as received"
+    );
+    assert!(metrics.bytes_sent.load(Ordering::SeqCst) > 0);
+    assert_eq!(
+        metrics
+            .operations
+            .lock()
+            .expect("operations lock")
+            .as_slice(),
+        [("metricsmod".to_owned(), "recv", true)]
+    );
+    assert_eq!(
+        metrics.errors.lock().expect("errors lock").as_slice(),
+        [SessionError::UnknownModule]
+    );
+}
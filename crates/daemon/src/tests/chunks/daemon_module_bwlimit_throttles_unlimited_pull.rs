@@ -0,0 +1,87 @@
+/// End-to-end test for the module `bwlimit` directive during a daemon pull.
+///
+/// The client requests no bandwidth limit, yet the module caps the daemon at
+/// 1 KiB/s. The daemon-sender's transfer stream must still be paced, so the
+/// limiter records roughly one second of sleep per KiB of file data on top of
+/// the few bytes of greeting text.
+///
+/// # Upstream Reference
+///
+/// - `loadparm.c` - per-module `bwlimit` parameter
+/// - `options.c:parse_arguments()` - `daemon_bwlimit` caps the client's `bwlimit`
+/// - `io.c:sleep_for_bwlimit()` - writes are paced on the transfer socket
+#[cfg(unix)]
+#[test]
+fn daemon_module_bwlimit_throttles_unlimited_pull() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let mut recorder = bandwidth::recorded_sleep_session();
+    recorder.clear();
+
+    let temp = tempdir().expect("tempdir");
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module");
+    let payload: Vec<u8> = (0..2048u32).map(|i| (i * 7 % 251) as u8).collect();
+    fs::write(module_dir.join("payload.bin"), &payload).expect("write payload");
+
+    let dest = temp.path().join("pulled");
+    fs::create_dir(&dest).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[capped]\n\
+         path = {}\n\
+         read only = true\n\
+         use chroot = false\n\
+         bwlimit = 1\n",
+        module_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let url = format!("rsync://127.0.0.1:{port}/capped/");
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([OsString::from(&url), OsString::from(dest.as_os_str())])
+        .build();
+    let result = core::client::run_client(client_config);
+    if let Err(e) = &result {
+        let _ = daemon_handle.join();
+        panic!("capped pull failed: {e}");
+    }
+
+    assert_eq!(
+        fs::read(dest.join("payload.bin")).expect("read pulled payload"),
+        payload,
+        "throttled pull must still deliver the file intact"
+    );
+
+    let _ = daemon_handle.join();
+
+    let total_sleep = recorder
+        .take()
+        .into_iter()
+        .fold(Duration::ZERO, |acc, duration| acc + duration);
+    // 2 KiB of file data at 1 KiB/s; the greeting and module lines alone
+    // account for well under 100ms.
+    assert!(
+        total_sleep >= Duration::from_millis(1500),
+        "module bwlimit must throttle the transfer stream, slept {total_sleep:?}"
+    );
+}
@@ -0,0 +1,108 @@
+/// A module-level `timeout` overrides the global `timeout` for that module
+/// only.
+///
+/// With `timeout = 900` globally, `[quick]` sets `timeout = 1` while
+/// `[patient]` inherits the global value. A client that selects `[quick]` and
+/// then stalls is dropped within a few seconds; the same stall on `[patient]`
+/// keeps the connection open.
+///
+/// upstream: loadparm.c - `timeout` is a P_LOCAL parameter whose global value
+/// is the per-module default; clientserver.c:rsync_module() applies it.
+#[test]
+fn daemon_module_timeout_overrides_global() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module dir");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "timeout = 900\n\
+             use chroot = false\n\
+             \n\
+             [quick]\n\
+             path = {dir}\n\
+             timeout = 1\n\
+             \n\
+             [patient]\n\
+             path = {dir}\n",
+            dir = module_dir.display()
+        ),
+    )
+    .expect("write config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    let (quick_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+
+    // Negotiates up to `@RSYNCD: OK` for `module`, then returns the reader so
+    // the caller can stall where the daemon expects the client's argv.
+    let select_module = |stream: TcpStream, module: &str| {
+        let mut writer = stream.try_clone().expect("clone stream");
+        let mut reader = BufReader::new(stream);
+        let mut line = String::new();
+        reader.read_line(&mut line).expect("greeting");
+        assert!(
+            line.starts_with("@RSYNCD:"),
+            "expected greeting, got: {line}"
+        );
+        writer
+            .write_all(format!("@RSYNCD: 32.0\n{module}\n").as_bytes())
+            .expect("send module request");
+        line.clear();
+        reader.read_line(&mut line).expect("module ok");
+        assert_eq!(line, "@RSYNCD: OK\n", "module {module} refused");
+        reader
+    };
+
+    let mut quick = select_module(quick_stream, "quick");
+    quick
+        .get_ref()
+        .set_read_timeout(Some(Duration::from_secs(20)))
+        .expect("set client read timeout");
+    let stalled_at = Instant::now();
+    let mut rest = Vec::new();
+    // EOF or a reset both mean the daemon gave up on the stalled client.
+    let _ = quick.read_to_end(&mut rest);
+    let waited = stalled_at.elapsed();
+    assert!(
+        waited < Duration::from_secs(10),
+        "module timeout = 1 must drop the stalled client, waited {waited:?}"
+    );
+
+    let mut patient = select_module(connect_with_retries(port), "patient");
+    patient
+        .get_ref()
+        .set_read_timeout(Some(Duration::from_millis(2500)))
+        .expect("set client read timeout");
+    let mut byte = [0u8; 1];
+    let err = patient
+        .read(&mut byte)
+        .expect_err("global timeout = 900 keeps the stalled client connected");
+    assert!(
+        matches!(
+            err.kind(),
+            io::ErrorKind::WouldBlock | io::ErrorKind::TimedOut
+        ),
+        "expected the client-side read to time out, got {err}"
+    );
+    drop(patient);
+
+    let _ = finish_daemon(daemon_handle);
+}
@@ -0,0 +1,92 @@
+/// A `pre-xfer exec` script that decides from the exported `RSYNC_*`
+/// environment refuses the session, and its stderr (with the expanded
+/// variables) reaches the client in the `@ERROR` line.
+///
+/// upstream: clientserver.c:524 - `start_pre_exec()` exports the module and
+/// host variables to the hook; a non-zero exit refuses the transfer.
+#[cfg(unix)]
+#[test]
+fn daemon_pre_xfer_exec_rejects_based_on_env() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let dir = tempdir().expect("config dir");
+    let module_dir = dir.path().join("module");
+    fs::create_dir_all(&module_dir).expect("module dir");
+
+    let script = dir.path().join("gate.sh");
+    write_executable_script(
+        &script,
+        "#!/bin/sh\n\
+         if [ \"$RSYNC_MODULE_NAME\" = gated ] && [ \"$RSYNC_HOST_ADDR\" = 127.0.0.1 ]; then\n\
+         \techo \"refusing $RSYNC_MODULE_NAME for $RSYNC_HOST_ADDR\" >&2\n\
+         \texit 1\n\
+         fi\n\
+         exit 0\n",
+    );
+
+    let config_path = dir.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "[gated]\npath = {}\nread only = false\nuse chroot = false\npre-xfer exec = {}\n",
+            module_dir.display(),
+            script.display()
+        ),
+    )
+    .expect("write config");
+
+    let (port, held_listener) = allocate_test_port();
+
+    let config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--once"),
+            OsString::from("--config"),
+            config_path.as_os_str().to_os_string(),
+        ])
+        .build();
+
+    let (mut stream, handle) = start_daemon(config, port, held_listener);
+    let mut reader = BufReader::new(stream.try_clone().expect("clone stream"));
+
+    let mut line = String::new();
+    reader.read_line(&mut line).expect("greeting");
+    assert!(
+        line.starts_with("@RSYNCD:"),
+        "expected greeting, got: {line}"
+    );
+
+    stream
+        .write_all(b"@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4\ngated\n")
+        .expect("send module request");
+    stream.flush().expect("flush module request");
+
+    line.clear();
+    reader.read_line(&mut line).expect("ok message");
+    assert_eq!(line, "@RSYNCD: OK\n");
+
+    stream
+        .write_all(b"--server\0--sender\0-logDtpr\0.\0gated/\0\0")
+        .expect("send client args");
+    stream.flush().expect("flush client args");
+
+    line.clear();
+    reader.read_line(&mut line).expect("error message");
+    assert!(line.starts_with("@ERROR:"), "expected @ERROR, got: {line}");
+    assert!(
+        line.contains("refusing gated for 127.0.0.1"),
+        "hook must see RSYNC_MODULE_NAME and RSYNC_HOST_ADDR, got: {line}"
+    );
+
+    line.clear();
+    let read = reader.read_line(&mut line).expect("eof after error");
+    assert_eq!(read, 0, "session must end after the refusal, got: {line:?}");
+
+    drop(reader);
+    let result = handle.join().expect("daemon thread");
+    assert!(result.is_ok());
+}
@@ -0,0 +1,102 @@
+/// With `proxy protocol = true`, the address from a PROXY v1 header replaces
+/// the TCP peer for both the connect log line and `hosts allow`.
+///
+/// Both sessions arrive from loopback. The first forwards an allowed address
+/// and is admitted; the second forwards an address outside `hosts allow` and
+/// is refused, even though loopback itself is never consulted.
+#[test]
+fn daemon_proxy_protocol_v1_forwards_client_address() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let dir = tempdir().expect("config dir");
+    let module_dir = dir.path().join("module");
+    fs::create_dir_all(&module_dir).expect("module dir");
+    let log_path = dir.path().join("rsyncd.log");
+
+    let config_path = dir.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "proxy protocol = true\n\
+             reverse lookup = no\n\
+             [restricted]\n\
+             path = {}\n\
+             use chroot = false\n\
+             hosts allow = 203.0.113.0/24\n",
+            module_dir.display()
+        ),
+    )
+    .expect("write config");
+
+    let (port, held_listener) = allocate_test_port();
+    let config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+            OsString::from("--config"),
+            config_path.as_os_str().to_os_string(),
+            OsString::from("--log-file"),
+            log_path.as_os_str().to_os_string(),
+        ])
+        .build();
+
+    let (stream, handle) = start_daemon(config, port, held_listener);
+
+    // Returns the daemon's reply to a module request sent behind `header`.
+    let request_module = |mut stream: TcpStream, header: &[u8]| -> String {
+        stream
+            .set_read_timeout(Some(Duration::from_secs(10)))
+            .expect("set read timeout");
+        // upstream: clientserver.c:1298 - the header precedes the greeting.
+        stream.write_all(header).expect("send PROXY header");
+        let mut reader = BufReader::new(stream.try_clone().expect("clone"));
+        let mut line = String::new();
+        reader.read_line(&mut line).expect("greeting");
+        assert!(
+            line.starts_with("@RSYNCD:"),
+            "unexpected greeting: {line:?}"
+        );
+        stream
+            .write_all(b"@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4\n")
+            .expect("send version");
+        stream.write_all(b"restricted\n").expect("send module");
+        line.clear();
+        reader.read_line(&mut line).expect("module response");
+        line
+    };
+
+    let admitted = request_module(stream, b"PROXY TCP4 203.0.113.7 127.0.0.1 40000 873\r\n");
+    assert_eq!(admitted, "@RSYNCD: OK\n");
+
+    let refused = request_module(
+        connect_with_retries(port),
+        b"PROXY TCP4 198.51.100.9 127.0.0.1 40001 873\r\n",
+    );
+    assert!(
+        refused.contains("@ERROR:") && refused.contains("access denied"),
+        "forwarded address outside hosts allow must be refused, got: {refused:?}"
+    );
+
+    if let Some(result) = finish_daemon(handle) {
+        assert!(result.is_ok(), "daemon failed: {result:?}");
+    }
+
+    let log = fs::read_to_string(&log_path).expect("read daemon log");
+    assert!(
+        log.contains("connect from 203.0.113.7 (203.0.113.7)"),
+        "log must record the forwarded client, got:\n{log}"
+    );
+    assert!(
+        log.contains("(198.51.100.9)"),
+        "log must record the refused forwarded client, got:\n{log}"
+    );
+    assert!(
+        !log.contains("(127.0.0.1)"),
+        "the balancer address must not be logged as the client, got:\n{log}"
+    );
+}
@@ -0,0 +1,131 @@
+/// `ShutdownHandle::shutdown` refuses new connections at once but lets a
+/// session that is already transferring run to completion before the daemon
+/// returns.
+///
+/// A pull is parked inside its `pre-xfer exec` hook while the shutdown is
+/// requested. The listener must close (connects are refused) while the
+/// shutdown call is still waiting; releasing the hook lets the pull finish,
+/// after which the shutdown call and `run_daemon` both return `Ok`.
+///
+/// upstream: main.c - SIGUSR1 graceful exit, which this handle mirrors.
+#[cfg(unix)]
+#[test]
+fn daemon_shutdown_handle_drains_inflight_transfer() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    fs::write(source_dir.join("payload.txt"), b"drained\n").expect("write payload");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let started = temp.path().join("started");
+    let release = temp.path().join("release");
+    let hook = temp.path().join("park.sh");
+    write_executable_script(
+        &hook,
+        &format!(
+            "#!/bin/sh\n\
+             : > '{}'\n\
+             i=0\n\
+             while [ ! -e '{}' ] && [ $i -lt 300 ]; do sleep 0.1; i=$((i+1)); done\n\
+             exit 0\n",
+            started.display(),
+            release.display()
+        ),
+    );
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[slow]\n\
+             path = {}\n\
+             read only = true\n\
+             use chroot = false\n\
+             pre-xfer exec = {}\n",
+            source_dir.display(),
+            hook.display()
+        ),
+    )
+    .expect("write config");
+
+    let (port, held_listener) = allocate_test_port();
+    let shutdown = crate::ShutdownHandle::new();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+        ])
+        .shutdown_handle(shutdown.clone())
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let rsync_url = format!("rsync://127.0.0.1:{port}/slow/");
+    let pull_dest = dest_dir.clone();
+    let pull = thread::spawn(move || {
+        let client_config = core::client::ClientConfig::builder()
+            .transfer_args([
+                OsString::from(&rsync_url),
+                OsString::from(pull_dest.as_os_str()),
+            ])
+            .recursive(true)
+            .build();
+        core::client::run_client(client_config)
+            .map(|_| ())
+            .map_err(|e| e.to_string())
+    });
+
+    let deadline = Instant::now() + Duration::from_secs(10);
+    while !started.exists() {
+        assert!(
+            Instant::now() < deadline,
+            "pull never reached pre-xfer exec"
+        );
+        thread::sleep(Duration::from_millis(20));
+    }
+
+    let waiter_handle = shutdown.clone();
+    let waiter = thread::spawn(move || waiter_handle.shutdown(Duration::from_secs(30)));
+
+    let deadline = Instant::now() + Duration::from_secs(10);
+    while TcpStream::connect((Ipv4Addr::LOCALHOST, port)).is_ok() {
+        assert!(
+            Instant::now() < deadline,
+            "daemon kept accepting after shutdown was requested"
+        );
+        thread::sleep(Duration::from_millis(20));
+    }
+    assert!(
+        !shutdown.is_stopped(),
+        "shutdown must wait for the parked transfer"
+    );
+
+    fs::write(&release, b"").expect("release hook");
+    let result = pull.join().expect("pull thread");
+    if let Err(e) = &result {
+        panic!("in-flight pull was cut off by shutdown: {e}");
+    }
+    assert_eq!(
+        fs::read(dest_dir.join("payload.txt")).expect("read payload"),
+        b"drained\n"
+    );
+
+    waiter
+        .join()
+        .expect("shutdown thread")
+        .expect("drain finishes before the timeout");
+    assert!(shutdown.is_stopped());
+    daemon_handle
+        .join()
+        .expect("daemon thread")
+        .expect("daemon exits cleanly after draining");
+}
@@ -0,0 +1,177 @@
+/// SIGHUP reloads the module table for new connections while a session that
+/// was already accepted keeps the configuration it started with.
+///
+/// A pull from `[first]` is parked inside its `pre-xfer exec` hook. The test
+/// then rewrites the config to drop `[first]` and add `[second]`, raises
+/// SIGHUP, and waits for the reload log line. A fresh connection lists only
+/// `second`; releasing the hook then lets the parked pull finish against the
+/// removed module's snapshot.
+///
+/// upstream: main.c - `sighup_handler()` sets `got_sighup`, and the daemon
+/// re-reads the config for connections accepted afterwards.
+#[cfg(unix)]
+#[test]
+fn daemon_sighup_reload_keeps_inflight_transfer() {
+    use nix::sys::signal::{Signal, raise};
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let first_dir = temp.path().join("first");
+    let second_dir = temp.path().join("second");
+    fs::create_dir(&first_dir).expect("create first");
+    fs::create_dir(&second_dir).expect("create second");
+    fs::write(first_dir.join("payload.txt"), b"from the old config\n").expect("write payload");
+
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    // The hook announces that the session is in flight, then waits (bounded)
+    // for the test to release it.
+    let started = temp.path().join("started");
+    let release = temp.path().join("release");
+    let hook = temp.path().join("park.sh");
+    write_executable_script(
+        &hook,
+        &format!(
+            "#!/bin/sh\n\
+             : > '{}'\n\
+             i=0\n\
+             while [ ! -e '{}' ] && [ $i -lt 300 ]; do sleep 0.1; i=$((i+1)); done\n\
+             exit 0\n",
+            started.display(),
+            release.display()
+        ),
+    );
+
+    let log_file = temp.path().join("rsyncd.log");
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[first]\n\
+             path = {}\n\
+             read only = true\n\
+             use chroot = false\n\
+             pre-xfer exec = {}\n",
+            first_dir.display(),
+            hook.display()
+        ),
+    )
+    .expect("write initial config");
+
+    let (port, held_listener) = allocate_test_port();
+
+    // Probe, parked pull, and post-reload listing.
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--log-file"),
+            log_file.as_os_str().to_owned(),
+            OsString::from("--max-sessions"),
+            OsString::from("3"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let rsync_url = format!("rsync://127.0.0.1:{port}/first/");
+    let pull_dest = dest_dir.clone();
+    let pull = thread::spawn(move || {
+        let client_config = core::client::ClientConfig::builder()
+            .transfer_args([
+                OsString::from(&rsync_url),
+                OsString::from(pull_dest.as_os_str()),
+            ])
+            .recursive(true)
+            .build();
+        core::client::run_client(client_config)
+            .map(|_| ())
+            .map_err(|e| e.to_string())
+    });
+
+    let deadline = Instant::now() + Duration::from_secs(10);
+    while !started.exists() {
+        assert!(
+            Instant::now() < deadline,
+            "pull never reached pre-xfer exec"
+        );
+        thread::sleep(Duration::from_millis(20));
+    }
+
+    fs::write(
+        &config_file,
+        format!(
+            "[second]\n\
+             path = {}\n\
+             read only = true\n\
+             use chroot = false\n",
+            second_dir.display()
+        ),
+    )
+    .expect("rewrite config");
+    raise(Signal::SIGHUP).expect("raise SIGHUP");
+
+    let deadline = Instant::now() + Duration::from_secs(10);
+    loop {
+        let log = fs::read_to_string(&log_file).unwrap_or_default();
+        if log.contains("configuration reloaded successfully") {
+            break;
+        }
+        assert!(Instant::now() < deadline, "daemon never reloaded: {log}");
+        thread::sleep(Duration::from_millis(20));
+    }
+
+    let mut stream = connect_with_retries(port);
+    let mut reader = BufReader::new(stream.try_clone().expect("clone stream"));
+    let mut line = String::new();
+    reader.read_line(&mut line).expect("greeting");
+    assert!(
+        line.starts_with("@RSYNCD:"),
+        "expected greeting, got: {line}"
+    );
+    stream.write_all(b"\n").expect("request module list");
+    stream.flush().expect("flush module list request");
+    let mut listed = Vec::new();
+    loop {
+        line.clear();
+        reader.read_line(&mut line).expect("module list line");
+        if line.is_empty() || line == "@RSYNCD: EXIT\n" {
+            break;
+        }
+        listed.push(
+            line.split('\t')
+                .next()
+                .unwrap_or_default()
+                .trim()
+                .to_owned(),
+        );
+    }
+    assert_eq!(
+        listed,
+        ["second"],
+        "new connections must see the reloaded modules"
+    );
+    drop(reader);
+    drop(stream);
+
+    fs::write(&release, b"").expect("release hook");
+    let result = pull.join().expect("pull thread");
+    if let Err(e) = &result {
+        let _ = daemon_handle.join();
+        panic!("in-flight pull failed across reload: {e}");
+    }
+    assert_eq!(
+        fs::read(dest_dir.join("payload.txt")).expect("read payload"),
+        b"from the old config\n"
+    );
+
+    let _ = daemon_handle.join().expect("daemon thread");
+}
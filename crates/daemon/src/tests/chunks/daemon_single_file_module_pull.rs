@@ -0,0 +1,84 @@
+/// A module whose `path` names a regular file serves just that file: a pull
+/// of the bare module lands the file under its own name with its contents,
+/// mtime and permissions, and none of the parent directory's other entries.
+#[cfg(unix)]
+#[test]
+fn daemon_single_file_module_pull_retrieves_file() {
+    use filetime::{FileTime, set_file_mtime};
+    use std::os::unix::fs::PermissionsExt;
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let dist_dir = temp.path().join("dist");
+    fs::create_dir(&dist_dir).expect("create dist");
+    let served = dist_dir.join("release.tar");
+    fs::write(&served, b"single file module payload\n").expect("write served file");
+    fs::set_permissions(&served, fs::Permissions::from_mode(0o640)).expect("chmod served");
+    let mtime = FileTime::from_unix_time(1_650_000_000, 0);
+    set_file_mtime(&served, mtime).expect("set served mtime");
+    fs::write(dist_dir.join("sibling.tar"), b"not part of the module\n").expect("write sibling");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[release]\npath = {}\nuse chroot = false\n",
+            served.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            OsString::from(format!("rsync://127.0.0.1:{port}/release")),
+            OsString::from(dest_dir.as_os_str()),
+        ])
+        .times(true)
+        .permissions(true)
+        .build();
+    let result = core::client::run_client(client_config);
+    if let Err(e) = &result {
+        panic!("single-file module pull failed: {e}");
+    }
+
+    let pulled = dest_dir.join("release.tar");
+    assert_eq!(
+        fs::read(&pulled).expect("read pulled file"),
+        b"single file module payload\n"
+    );
+    let metadata = fs::metadata(&pulled).expect("pulled metadata");
+    assert_eq!(
+        FileTime::from_last_modification_time(&metadata),
+        mtime,
+        "mtime must match the served file"
+    );
+    assert_eq!(metadata.permissions().mode() & 0o777, 0o640);
+    assert!(
+        !dest_dir.join("sibling.tar").exists(),
+        "the parent directory's other entries are not part of the module"
+    );
+
+    if let Some(result) = finish_daemon(daemon_handle) {
+        assert!(result.is_ok(), "daemon failed: {result:?}");
+    }
+}
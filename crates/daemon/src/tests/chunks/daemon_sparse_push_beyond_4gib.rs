@@ -0,0 +1,114 @@
+/// End-to-end test for a daemon push of a sparse file larger than 4 GiB.
+///
+/// The source is a sparse file of `4 GiB + 4 KiB` with markers at the head,
+/// straddling the 2^32 boundary, and at the tail. The file-list size must
+/// travel in the 64-bit varlong form and every offset past 2^32 must survive
+/// the sender's read loop and the receiver's sparse writer, so the pushed copy
+/// must report the exact length and the same bytes around the boundary.
+///
+/// The source holes cost no disk, but the sender still reads and sends the full
+/// 4 GiB of zeros, so the test is ignored by default.
+///
+/// # Upstream Reference
+///
+/// - `flist.c:send_file_entry()` - `write_varlong30(f, F_LENGTH(file), 3)`
+/// - `io.c:write_varlong()` - 64-bit length encoding
+/// - `fileio.c:write_sparse()` - receiver seeks over zero runs
+#[cfg(unix)]
+#[test]
+#[ignore = "reads and sends 4 GiB of source data; run with --ignored"]
+fn daemon_sparse_push_preserves_content_beyond_4gib() {
+    use std::io::{Seek, SeekFrom};
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    const BOUNDARY: u64 = 1 << 32;
+    const FILE_SIZE: u64 = BOUNDARY + 4096;
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let markers: [(u64, &[u8]); 4] = [
+        (0, b"HEAD"),
+        (BOUNDARY - 6, b"BEFORE"),
+        (BOUNDARY, b"AFTER!"),
+        (FILE_SIZE - 4, b"TAIL"),
+    ];
+    {
+        let mut file = fs::File::create(source_dir.join("big.img")).expect("create big.img");
+        file.set_len(FILE_SIZE).expect("size big.img");
+        for (offset, bytes) in markers {
+            file.seek(SeekFrom::Start(offset)).expect("seek marker");
+            file.write_all(bytes).expect("write marker");
+        }
+    }
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[pushmod]\n\
+         path = {}\n\
+         read only = false\n\
+         use chroot = false\n",
+        dest_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let mut source_arg = source_dir.clone().into_os_string();
+    source_arg.push("/");
+    let rsync_url = format!("rsync://127.0.0.1:{port}/pushmod/");
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([source_arg, OsString::from(&rsync_url)])
+        .sparse(true)
+        .build();
+    if let Err(e) = core::client::run_client(client_config) {
+        let _ = daemon_handle.join();
+        panic!("sparse push failed: {e}");
+    }
+    let _ = daemon_handle.join();
+
+    let pushed = dest_dir.join("big.img");
+    assert_eq!(
+        fs::metadata(&pushed).expect("stat pushed big.img").len(),
+        FILE_SIZE,
+        "pushed file length must survive the 64-bit size encoding"
+    );
+
+    let mut file = fs::File::open(&pushed).expect("open pushed big.img");
+    for (offset, bytes) in markers {
+        let mut actual = vec![0u8; bytes.len()];
+        file.seek(SeekFrom::Start(offset))
+            .expect("seek pushed marker");
+        file.read_exact(&mut actual).expect("read pushed marker");
+        assert_eq!(actual, bytes, "marker at offset {offset} must match");
+    }
+
+    // The hole just past the boundary marker must read back as zeros.
+    let mut gap = vec![0xFFu8; 64];
+    file.seek(SeekFrom::Start(BOUNDARY + 6)).expect("seek gap");
+    file.read_exact(&mut gap).expect("read gap");
+    assert!(
+        gap.iter().all(|&b| b == 0),
+        "hole past 4 GiB must read as zeros"
+    );
+}
@@ -0,0 +1,121 @@
+/// Capturing [`DaemonLogger`] used to observe the structured event stream.
+#[derive(Debug, Default)]
+struct CapturingLogger {
+    records: std::sync::Mutex<Vec<LogRecord>>,
+}
+
+impl CapturingLogger {
+    fn records(&self) -> Vec<LogRecord> {
+        self.records.lock().expect("records lock").clone()
+    }
+}
+
+impl DaemonLogger for CapturingLogger {
+    fn log(&self, record: &LogRecord) {
+        self.records
+            .lock()
+            .expect("records lock")
+            .push(record.clone());
+    }
+}
+
+/// A logger injected through `DaemonConfigBuilder::logger` receives a
+/// `connect`/`disconnect` pair for every connection and a `transfer` record
+/// carrying the module, peer address, byte counts, and duration for the
+/// connection that ran a transfer.
+#[cfg(unix)]
+#[test]
+fn daemon_structured_logger_records_connection_attributes() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    fs::write(
+        source_dir.join("payload.txt"),
+        b"structured logging payload\n",
+    )
+    .expect("write payload");
+
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module dir");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[logmod]\n\
+         path = {}\n\
+         read only = false\n\
+         use chroot = false\n",
+        module_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let logger = Arc::new(CapturingLogger::default());
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .logger(logger.clone())
+        .build();
+
+    // The probe connection is the first of the two sessions.
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let mut source_arg = source_dir.into_os_string();
+    source_arg.push("/");
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            source_arg,
+            OsString::from(format!("rsync://127.0.0.1:{port}/logmod/")),
+        ])
+        .build();
+    if let Err(error) = core::client::run_client(client_config) {
+        let _ = finish_daemon(daemon_handle);
+        panic!("push failed: {error}");
+    }
+    let _ = finish_daemon(daemon_handle);
+
+    let records = logger.records();
+    let count = |event: &str| records.iter().filter(|r| r.event() == event).count();
+    assert_eq!(count("connect"), 2, "records: {records:?}");
+    assert_eq!(count("disconnect"), 2, "records: {records:?}");
+
+    for record in records.iter().filter(|r| r.event() != "transfer") {
+        let Some(LogValue::Str(peer)) = record.field("peer") else {
+            panic!("{} record without peer: {record:?}", record.event());
+        };
+        assert!(peer.starts_with("127.0.0.1:"), "unexpected peer {peer}");
+    }
+
+    let transfers: Vec<&LogRecord> = records.iter().filter(|r| r.event() == "transfer").collect();
+    assert_eq!(transfers.len(), 1, "records: {records:?}");
+    let transfer = transfers[0];
+    assert_eq!(transfer.level(), LogLevel::Info);
+    assert_eq!(transfer.field("module"), Some(&LogValue::from("logmod")));
+    assert_eq!(transfer.field("peer"), Some(&LogValue::from("127.0.0.1")));
+    assert_eq!(transfer.field("operation"), Some(&LogValue::from("recv")));
+    assert_eq!(transfer.field("status"), Some(&LogValue::from("success")));
+    assert!(transfer.field("error").is_none());
+    assert!(matches!(
+        transfer.field("duration_ms"),
+        Some(LogValue::U64(_))
+    ));
+    let Some(LogValue::U64(bytes_read)) = transfer.field("bytes_read") else {
+        panic!("transfer record without bytes_read: {transfer:?}");
+    };
+    assert!(*bytes_read > 0, "the pushed file must be counted");
+    assert!(matches!(
+        transfer.field("bytes_written"),
+        Some(LogValue::U64(_))
+    ));
+}
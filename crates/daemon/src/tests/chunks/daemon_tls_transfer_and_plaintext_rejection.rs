@@ -0,0 +1,97 @@
+/// A daemon configured with `tls cert file` / `tls key file` serves a `--tls`
+/// pull and drops a plaintext client before any `@RSYNCD:` greeting.
+///
+/// The fixture certificate is self-signed for `127.0.0.1`, so the client
+/// trusts it through `RSYNC_SSL_CA_CERT`.
+#[cfg(all(unix, feature = "tls"))]
+#[test]
+fn daemon_tls_transfer_and_plaintext_rejection() {
+    const CERT: &str = concat!(
+        env!("CARGO_MANIFEST_DIR"),
+        "/../rsync_io/tests/fixtures/tls/cert.pem"
+    );
+    const KEY: &str = concat!(
+        env!("CARGO_MANIFEST_DIR"),
+        "/../rsync_io/tests/fixtures/tls/key.pem"
+    );
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+    let _ca = EnvGuard::set("RSYNC_SSL_CA_CERT", OsStr::new(CERT));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    fs::write(source_dir.join("payload.txt"), b"over tls\n").expect("write payload");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "tls cert file = {CERT}\n\
+             tls key file = {KEY}\n\
+             [files]\n\
+             path = {}\n\
+             read only = true\n\
+             use chroot = false\n",
+            source_dir.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    // Plaintext session: the daemon reads our greeting as a TLS record,
+    // fails the handshake and closes without sending `@RSYNCD:`.
+    let (mut plaintext, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    plaintext
+        .set_read_timeout(Some(Duration::from_secs(10)))
+        .expect("set read timeout");
+    plaintext
+        .write_all(b"@RSYNCD: 32.0\n")
+        .expect("send plaintext greeting");
+    let mut response = Vec::new();
+    let _ = plaintext.read_to_end(&mut response);
+    assert!(
+        !response.starts_with(b"@RSYNCD:"),
+        "TLS daemon must not greet a plaintext client: {:?}",
+        String::from_utf8_lossy(&response)
+    );
+    drop(plaintext);
+
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            OsString::from(format!("rsync://127.0.0.1:{port}/files/")),
+            OsString::from(dest_dir.as_os_str()),
+        ])
+        .recursive(true)
+        .tls(true)
+        .build();
+    let result = core::client::run_client(client_config);
+    if let Err(e) = &result {
+        panic!("pull over TLS failed: {e}");
+    }
+
+    assert_eq!(
+        fs::read(dest_dir.join("payload.txt")).expect("read pulled payload"),
+        b"over tls\n"
+    );
+
+    if let Some(result) = finish_daemon(daemon_handle) {
+        assert!(result.is_ok(), "daemon failed: {result:?}");
+    }
+}
@@ -0,0 +1,136 @@
+/// End-to-end test for the sender of a `use chroot = no` module.
+///
+/// Without a chroot the kernel no longer stops paths at the module root, so
+/// the daemon sender itself must refuse to serve anything a symlink leads to
+/// outside it. Symlinks that stay inside the module are still followed.
+///
+/// # Scenario
+///
+/// Source (daemon module with `use chroot = no`):
+///   ok.txt           (regular file, "ok")
+///   sub/in.txt       (regular file, "in")
+///   inside           -> sub              (inside the module)
+///   etc              -> /etc             (outside the module)
+///   outside          -> <temp>/outside   (outside, holds secret.txt)
+///
+/// Pulls:
+///   `mod/` with `-rlL`      -> `inside/` arrives as a directory; `etc` and
+///                              `outside` arrive as symlinks, never as trees.
+///   `mod/outside/secret.txt` -> reported missing, nothing is written.
+///   `mod/etc/`               -> reported missing, nothing is written.
+///
+/// # Upstream Reference
+///
+/// - `clientserver.c:rsync_module()` - `sanitize_paths = 1` when the module
+///   is not chrooted.
+/// - `util1.c:sanitize_path()` - confines sender names to the module.
+#[cfg(unix)]
+#[test]
+fn daemon_unchrooted_sender_stays_in_module() {
+    use std::os::unix::fs as unix_fs;
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+
+    let outside_dir = temp.path().join("outside");
+    fs::create_dir(&outside_dir).expect("create outside");
+    fs::write(outside_dir.join("secret.txt"), b"secret\n").expect("write secret.txt");
+
+    let module_dir = temp.path().join("module");
+    fs::create_dir_all(module_dir.join("sub")).expect("create module");
+    fs::write(module_dir.join("ok.txt"), b"ok\n").expect("write ok.txt");
+    fs::write(module_dir.join("sub/in.txt"), b"in\n").expect("write in.txt");
+    unix_fs::symlink("sub", module_dir.join("inside")).expect("create inside");
+    unix_fs::symlink("/etc", module_dir.join("etc")).expect("create etc");
+    unix_fs::symlink(&outside_dir, module_dir.join("outside")).expect("create outside link");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[mod]\n\
+         path = {}\n\
+         read only = true\n\
+         use chroot = no\n",
+        module_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+
+    // One probe connection plus three pulls.
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("4"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let pull = |remote: &str, dest: &Path| {
+        fs::create_dir(dest).expect("create dest");
+        let client_config = core::client::ClientConfig::builder()
+            .transfer_args([
+                OsString::from(format!("rsync://127.0.0.1:{port}/{remote}")),
+                OsString::from(dest.as_os_str()),
+            ])
+            .recursive(true)
+            .links(true)
+            .copy_links(true)
+            .build();
+        core::client::run_client(client_config)
+    };
+
+    let whole = temp.path().join("whole");
+    if let Err(e) = pull("mod/", &whole) {
+        let _ = daemon_handle.join();
+        panic!("pull of the module root failed: {e}");
+    }
+    assert_eq!(
+        fs::read(whole.join("ok.txt")).expect("read ok.txt"),
+        b"ok\n"
+    );
+    assert!(
+        fs::symlink_metadata(whole.join("inside"))
+            .expect("stat inside")
+            .is_dir(),
+        "a symlink inside the module is still followed under --copy-links"
+    );
+    assert_eq!(
+        fs::read(whole.join("inside/in.txt")).expect("read in.txt"),
+        b"in\n"
+    );
+    for name in ["etc", "outside"] {
+        let meta = fs::symlink_metadata(whole.join(name)).expect("stat escaping link");
+        assert!(
+            meta.file_type().is_symlink(),
+            "{name} leads outside the module and must stay a symlink"
+        );
+    }
+
+    let file = temp.path().join("file");
+    let _ = pull("mod/outside/secret.txt", &file);
+    assert!(
+        !file.join("secret.txt").exists(),
+        "a file reached through a symlinked directory outside the module was served"
+    );
+
+    let contents = temp.path().join("contents");
+    let _ = pull("mod/etc/", &contents);
+    assert_eq!(
+        fs::read_dir(&contents).expect("read contents").count(),
+        0,
+        "the contents of /etc were served from a `use chroot = no` module"
+    );
+
+    let daemon_result = daemon_handle.join().expect("daemon thread");
+    let _ = daemon_result;
+}
@@ -0,0 +1,97 @@
+/// A daemon started with `--address unix:PATH` serves module listings and
+/// transfers over the Unix domain socket.
+///
+/// Both sessions dial the socket through the client's `unix:PATH` daemon
+/// address: the listing as a percent-encoded `rsync://` URL, the pull as a
+/// `host::module` operand.
+#[cfg(unix)]
+#[test]
+fn daemon_unix_socket_listing_and_pull() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    fs::write(source_dir.join("payload.txt"), b"over a unix socket\n").expect("write payload");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[files]\n\
+             path = {}\n\
+             read only = true\n\
+             use chroot = false\n",
+            source_dir.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let socket_path = temp.path().join("rsyncd.sock");
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--address"),
+            OsString::from(format!("unix:{}", socket_path.display())),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+    let daemon_handle = thread::spawn(move || run_daemon(daemon_config));
+
+    // The first listing that connects is the first session; until the
+    // daemon listens, connecting fails and the listing is retried.
+    let encoded_socket = socket_path.display().to_string().replace('/', "%2F");
+    let listing_url = OsString::from(format!("rsync://unix:{encoded_socket}/"));
+    let deadline = Instant::now() + Duration::from_secs(10);
+    let listing = loop {
+        let request = core::client::ModuleListRequest::from_operands(&[listing_url.clone()])
+            .expect("parse listing URL")
+            .expect("module listing request");
+        match core::client::run_module_list(request) {
+            Ok(listing) => break listing,
+            Err(error) => {
+                assert!(
+                    !daemon_handle.is_finished(),
+                    "daemon exited before listening: {:?}",
+                    daemon_handle.join()
+                );
+                assert!(Instant::now() < deadline, "socket never accepted: {error}");
+                thread::sleep(Duration::from_millis(20));
+            }
+        }
+    };
+    let names: Vec<&str> = listing.entries().iter().map(|entry| entry.name()).collect();
+    assert_eq!(names, ["files"]);
+
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            OsString::from(format!("unix:{}::files/", socket_path.display())),
+            OsString::from(dest_dir.as_os_str()),
+        ])
+        .recursive(true)
+        .build();
+    let result = core::client::run_client(client_config);
+    if let Err(e) = &result {
+        panic!("pull over the unix socket failed: {e}");
+    }
+
+    assert_eq!(
+        fs::read(dest_dir.join("payload.txt")).expect("read pulled payload"),
+        b"over a unix socket\n"
+    );
+
+    if let Some(result) = finish_daemon(daemon_handle) {
+        assert!(result.is_ok(), "daemon failed: {result:?}");
+        assert!(
+            !socket_path.exists(),
+            "daemon must unlink its socket file on exit"
+        );
+    }
+}
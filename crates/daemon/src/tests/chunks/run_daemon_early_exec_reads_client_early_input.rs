@@ -0,0 +1,88 @@
+/// Data a client sends with `--early-input` reaches the module's `early exec`
+/// script on stdin, and the script's verdict decides whether the module
+/// session continues. A wrong key makes the script exit non-zero, which the
+/// daemon reports back as `@ERROR` carrying the script's stderr.
+///
+/// upstream: clientserver.c - `#early_input=<len>` precedes the module name;
+/// rsync.1 `--early-input` - the data is the early exec script's stdin.
+#[cfg(unix)]
+#[test]
+fn run_daemon_early_exec_reads_client_early_input() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let dir = tempdir().expect("config dir");
+    let module_dir = dir.path().join("module");
+    fs::create_dir_all(&module_dir).expect("module dir");
+
+    // The script keeps a copy of its stdin, then admits only the right key.
+    let capture = dir.path().join("early.in");
+    let config_path = dir.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "[vault]\npath = {}\nuse chroot = false\nearly exec = tee {} | grep -qx 'open sesame' || {{ echo 'bad key' >&2; exit 1; }}\n",
+            module_dir.display(),
+            capture.display()
+        ),
+    )
+    .expect("write config");
+
+    let (port, held_listener) = allocate_test_port();
+
+    let config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--once"),
+            OsString::from("--config"),
+            config_path.as_os_str().to_os_string(),
+        ])
+        .build();
+
+    let (mut stream, handle) = start_daemon(config, port, held_listener);
+    let mut reader = BufReader::new(stream.try_clone().expect("clone stream"));
+
+    let mut line = String::new();
+    reader.read_line(&mut line).expect("greeting");
+    assert!(
+        line.starts_with("@RSYNCD:"),
+        "expected greeting, got: {line}"
+    );
+
+    let key = b"letmein";
+    stream
+        .write_all(b"@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4\n")
+        .expect("send handshake response");
+    stream
+        .write_all(format!("#early_input={}\n", key.len()).as_bytes())
+        .expect("send early input header");
+    stream.write_all(key).expect("send early input");
+    stream.write_all(b"vault\n").expect("send module request");
+    stream.flush().expect("flush module request");
+
+    let mut error = None;
+    loop {
+        line.clear();
+        if reader.read_line(&mut line).expect("daemon reply") == 0 {
+            break;
+        }
+        if line.starts_with("@ERROR:") {
+            error = Some(line.clone());
+            break;
+        }
+    }
+    let error = error.expect("a wrong key must be refused with @ERROR");
+    assert!(
+        error.contains("bad key"),
+        "script stderr is relayed: {error}"
+    );
+
+    drop(reader);
+    let result = handle.join().expect("daemon thread");
+    assert!(result.is_ok());
+
+    assert_eq!(fs::read(&capture).expect("captured early input"), key);
+}
@@ -0,0 +1,99 @@
+/// A pull whose source is missing ends as a partial transfer, and the module's
+/// `post-xfer exec` hook must see that outcome rather than a blanket failure
+/// code: the sender's `link_stat` failure sets `IOERR_GENERAL`, so the session
+/// exits `RERR_PARTIAL` (23) and the hook reads `RSYNC_EXIT_STATUS=23` with the
+/// matching raw wait status `23 << 8`.
+///
+/// upstream: clientserver.c:908-933 - the post-xfer parent sets
+/// `RSYNC_RAW_STATUS` and `RSYNC_EXIT_STATUS` from the module child's wait
+/// status; flist.c:1846 - a missing source sets `IOERR_GENERAL`, which
+/// log.c:log_exit() turns into `RERR_PARTIAL`.
+#[cfg(unix)]
+#[test]
+fn run_daemon_post_xfer_exec_sees_transfer_error_status() {
+    use std::os::unix::fs::PermissionsExt;
+
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module dir");
+    fs::write(module_dir.join("present.txt"), b"present\n").expect("write present.txt");
+    let dest_dir = temp.path().join("dest");
+    fs::create_dir(&dest_dir).expect("create dest");
+
+    // The hook records both status variables, one per line.
+    let marker = temp.path().join("post.out");
+    let hook = temp.path().join("post.sh");
+    fs::write(
+        &hook,
+        format!(
+            "#!/bin/sh\nprintf '%s\\n%s\\n' \"$RSYNC_EXIT_STATUS\" \"$RSYNC_RAW_STATUS\" > {}\n",
+            marker.display()
+        ),
+    )
+    .expect("write hook script");
+    fs::set_permissions(&hook, std::fs::Permissions::from_mode(0o755)).expect("chmod hook");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_file,
+        format!(
+            "[pullmod]\npath = {}\nread only = true\nuse chroot = false\npost-xfer exec = {}\n",
+            module_dir.display(),
+            hook.display()
+        ),
+    )
+    .expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            OsString::from(format!("rsync://127.0.0.1:{port}/pullmod/missing.txt")),
+            OsString::from(dest_dir.as_os_str()),
+        ])
+        .build();
+    // The client's own verdict is covered elsewhere; only the hook matters here.
+    let _ = core::client::run_client(client_config);
+    let _ = finish_daemon(daemon_handle);
+
+    let deadline = Instant::now() + Duration::from_secs(5);
+    let mut contents = String::new();
+    while Instant::now() < deadline {
+        if let Ok(text) = fs::read_to_string(&marker) {
+            if !text.trim().is_empty() {
+                contents = text;
+                break;
+            }
+        }
+        thread::sleep(Duration::from_millis(50));
+    }
+    let mut lines = contents.lines();
+    assert_eq!(
+        lines.next(),
+        Some("23"),
+        "post-xfer exec must see RSYNC_EXIT_STATUS=23 for a partial transfer"
+    );
+    assert_eq!(
+        lines.next(),
+        Some((23 << 8).to_string().as_str()),
+        "RSYNC_RAW_STATUS must be the wait-status encoding of the exit code"
+    );
+}
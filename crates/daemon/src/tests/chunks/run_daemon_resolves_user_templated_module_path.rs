@@ -0,0 +1,101 @@
+// upstream: clientserver.c:rsync_module() - the module path is read after
+// auth_server() identifies the user, so `path = /srv/%u` roots each
+// authenticated user in their own directory. Only the resolved directory
+// exists here; the daemon replies OK only if it validated that path.
+#[test]
+fn run_daemon_resolves_user_templated_module_path() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let dir = tempdir().expect("config dir");
+    let homes = dir.path().join("homes");
+    fs::create_dir_all(homes.join("alice")).expect("alice home");
+    let secrets_path = dir.path().join("secrets.txt");
+    fs::write(&secrets_path, "alice:password\n").expect("write secrets");
+
+    #[cfg(unix)]
+    {
+        use std::os::unix::fs::PermissionsExt;
+        fs::set_permissions(&secrets_path, PermissionsExt::from_mode(0o600))
+            .expect("chmod secrets");
+    }
+
+    let config_path = dir.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "[homes]\npath = {}/%u\nuse chroot = no\nauth users = alice\nsecrets file = {}\n",
+            homes.display(),
+            secrets_path.display()
+        ),
+    )
+    .expect("write config");
+
+    let (port, held_listener) = allocate_test_port();
+
+    let config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--once"),
+            OsString::from("--config"),
+            config_path.as_os_str().to_os_string(),
+        ])
+        .build();
+
+    let (mut stream, handle) = start_daemon(config, port, held_listener);
+    let mut reader = BufReader::new(stream.try_clone().expect("clone stream"));
+
+    let expected_greeting = legacy_daemon_greeting();
+    let mut line = String::new();
+    reader.read_line(&mut line).expect("greeting");
+    assert_eq!(line, expected_greeting);
+
+    stream
+        .write_all(b"@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4\n")
+        .expect("send handshake response");
+    stream.flush().expect("flush handshake response");
+
+    stream.write_all(b"homes\n").expect("send module request");
+    stream.flush().expect("flush module request");
+
+    // Daemon responds with AUTHREQD for protected modules after module selection
+    // (CAP is only sent for #list requests)
+    line.clear();
+    reader.read_line(&mut line).expect("auth request");
+    let challenge = line
+        .trim_end()
+        .strip_prefix("@RSYNCD: AUTHREQD ")
+        .expect("challenge prefix");
+
+    let mut hasher = Md5::new();
+    hasher.update(b"password");
+    hasher.update(challenge.as_bytes());
+    let digest = STANDARD_NO_PAD.encode(hasher.finalize());
+    let response_line = format!("alice {digest}\n");
+    stream
+        .write_all(response_line.as_bytes())
+        .expect("send credentials");
+    stream.flush().expect("flush credentials");
+
+    line.clear();
+    reader.read_line(&mut line).expect("post-auth reply");
+    assert_eq!(
+        line,
+        "@RSYNCD: OK\n",
+        "templated path must resolve to {}",
+        homes.join("alice").display()
+    );
+    assert!(!homes.join("%u").exists());
+
+    drop(stream);
+    drop(reader);
+
+    let result = handle.join().expect("daemon thread");
+    assert!(
+        result.is_ok(),
+        "daemon should handle connection close gracefully"
+    );
+}
@@ -0,0 +1,81 @@
+/// With `transfer logging = yes`, the daemon writes one line per transferred
+/// file to its log file, expanded from the module's `log format`.
+///
+/// upstream: log.c:log_item() - each completed file is logged through
+/// `log_formatted()` with the module's `log format`.
+#[cfg(unix)]
+#[test]
+fn run_daemon_transfer_logging_writes_line_per_file() {
+    let _lock = ENV_LOCK.lock().expect("env lock");
+    let _primary = EnvGuard::set(DAEMON_FALLBACK_ENV, OsStr::new("0"));
+    let _secondary = EnvGuard::set(CLIENT_FALLBACK_ENV, OsStr::new("0"));
+
+    let temp = tempdir().expect("tempdir");
+    let source_dir = temp.path().join("source");
+    fs::create_dir(&source_dir).expect("create source");
+    fs::write(source_dir.join("alpha.txt"), b"alpha").expect("write alpha");
+    fs::write(source_dir.join("beta.txt"), b"beta beta").expect("write beta");
+
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module dir");
+    let log_path = temp.path().join("rsyncd.log");
+
+    let config_file = temp.path().join("rsyncd.conf");
+    let config_content = format!(
+        "[xferlog]\n\
+         path = {}\n\
+         read only = false\n\
+         use chroot = false\n\
+         transfer logging = yes\n\
+         log format = %o [%a] %m %f %l\n",
+        module_dir.display()
+    );
+    fs::write(&config_file, config_content).expect("write daemon config");
+
+    let (port, held_listener) = allocate_test_port();
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--config"),
+            config_file.as_os_str().to_owned(),
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--log-file"),
+            log_path.as_os_str().to_owned(),
+            OsString::from("--max-sessions"),
+            OsString::from("2"),
+        ])
+        .build();
+
+    let (probe_stream, daemon_handle) = start_daemon(daemon_config, port, held_listener);
+    drop(probe_stream);
+
+    let mut source_arg = source_dir.into_os_string();
+    source_arg.push("/");
+    let client_config = core::client::ClientConfig::builder()
+        .transfer_args([
+            source_arg,
+            OsString::from(format!("rsync://127.0.0.1:{port}/xferlog/")),
+        ])
+        .build();
+    if let Err(error) = core::client::run_client(client_config) {
+        let _ = finish_daemon(daemon_handle);
+        panic!("push failed: {error}");
+    }
+    let _ = finish_daemon(daemon_handle);
+
+    let log_contents = fs::read_to_string(&log_path).expect("read log file");
+    let mut transfer_lines: Vec<&str> = log_contents
+        .lines()
+        .filter_map(|line| line.find("recv [127.0.0.1] xferlog ").map(|at| &line[at..]))
+        .collect();
+    transfer_lines.sort_unstable();
+    assert_eq!(
+        transfer_lines,
+        [
+            "recv [127.0.0.1] xferlog alpha.txt 5",
+            "recv [127.0.0.1] xferlog beta.txt 9",
+        ],
+        "log: {log_contents}"
+    );
+}
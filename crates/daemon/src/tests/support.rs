@@ -65,6 +65,8 @@ pub(super) fn base_module(name: &str) -> ModuleDefinition {
         name_converter: None,
         temp_dir: None,
         charset: None,
+        uid_map: None,
+        gid_map: None,
         forward_lookup: true,
         strict_modes: true,
         exclude_from: None,
@@ -74,6 +76,7 @@ pub(super) fn base_module(name: &str) -> ModuleDefinition {
         lock_file: None,
         syslog_tag: None,
         syslog_facility: None,
+        single_file: None,
         filter: Vec::new(),
         exclude: Vec::new(),
         include: Vec::new(),
@@ -128,6 +131,8 @@ pub(super) fn module_with_host_patterns(allow: &[&str], deny: &[&str]) -> Module
         name_converter: None,
         temp_dir: None,
         charset: None,
+        uid_map: None,
+        gid_map: None,
         forward_lookup: true,
         strict_modes: true,
         exclude_from: None,
@@ -137,6 +142,7 @@ pub(super) fn module_with_host_patterns(allow: &[&str], deny: &[&str]) -> Module
         lock_file: None,
         syslog_tag: None,
         syslog_facility: None,
+        single_file: None,
         filter: Vec::new(),
         exclude: Vec::new(),
         include: Vec::new(),
@@ -256,6 +256,10 @@ pub use daemon::{DaemonConfig, DaemonConfigBuilder, DaemonError};
 /// Re-export the native daemon loop for direct embedding.
 pub use daemon::run_daemon as run_daemon_config;
 
+/// Re-export the streaming remote walk so tooling can consume a daemon
+/// module's file list entry by entry without rendering `--list-only` text.
+pub use core::client::{ClientConfig, ClientError, RemoteFileEntry, RemoteWalk, walk_remote};
+
 /// Re-export server configuration and types for direct server embedding.
 pub use core::server::{
     GeneratorStats, HandshakeResult, ParsedServerFlags, ServerConfig, ServerResult, ServerRole,
@@ -3,7 +3,9 @@
 //! Run with: `cargo bench -p engine --bench local_copy_bench`
 //!
 //! Measures end-to-end local copy throughput across file sizes and directory
-//! structures using `LocalCopyPlan::execute`.
+//! structures using `LocalCopyPlan::execute`, and the cost of updating a
+//! modified file in whole-file mode (the local default) versus
+//! `--no-whole-file` delta mode.
 
 use std::ffi::OsString;
 use std::fs;
@@ -14,7 +16,7 @@ use criterion::{BenchmarkId, Criterion, Throughput, criterion_group, criterion_m
 use std::hint::black_box;
 use tempfile::TempDir;
 
-use engine::local_copy::{LocalCopyPlan, LocalCopySummary};
+use engine::local_copy::{LocalCopyExecution, LocalCopyOptions, LocalCopyPlan, LocalCopySummary};
 
 /// Creates a file filled with a deterministic byte pattern.
 fn create_file_with_size(path: &Path, size: usize) {
@@ -206,6 +208,114 @@ fn bench_directory_tree(c: &mut Criterion) {
     group.finish();
 }
 
+// ---------------------------------------------------------------------------
+// Copy buffer size
+// ---------------------------------------------------------------------------
+
+/// Benchmarks one 64 MB file streamed through copy buffers of varying size.
+///
+/// Sparse mode keeps the copy on the buffered read/write loop instead of the
+/// kernel `copy_file_range` path, so the timing reflects the per-chunk
+/// syscall count that `--copy-buffer-size` trades against memory.
+fn bench_copy_buffer_size(c: &mut Criterion) {
+    let mut group = c.benchmark_group("local_copy_buffer_size");
+
+    let size = 64 * 1_024 * 1_024;
+    group.throughput(Throughput::Bytes(size as u64));
+
+    let buffers: &[(&str, usize)] = &[
+        ("8KB", 8 * 1_024),
+        ("32KB", 32 * 1_024),
+        ("128KB", 128 * 1_024),
+        ("1MB", 1_024 * 1_024),
+        ("4MB", 4 * 1_024 * 1_024),
+    ];
+
+    for &(label, buffer_size) in buffers {
+        group.bench_with_input(
+            BenchmarkId::new("sparse_64MB", label),
+            &buffer_size,
+            |b, &buffer_size| {
+                b.iter_batched(
+                    || {
+                        let tmp = TempDir::new().expect("tempdir");
+                        let src = tmp.path().join("source.dat");
+                        let dst = tmp.path().join("dest.dat");
+                        create_file_with_size(&src, size);
+                        let plan = plan_copy(&src, &dst);
+                        (tmp, plan)
+                    },
+                    |(_tmp, plan)| {
+                        let options = LocalCopyOptions::default()
+                            .sparse(true)
+                            .copy_buffer_size(Some(buffer_size));
+                        let summary = plan
+                            .execute_with_options(LocalCopyExecution::Apply, options)
+                            .expect("copy succeeds");
+                        black_box(summary)
+                    },
+                    criterion::BatchSize::PerIteration,
+                );
+            },
+        );
+    }
+
+    group.finish();
+}
+
+// ---------------------------------------------------------------------------
+// Whole-file versus delta update
+// ---------------------------------------------------------------------------
+
+/// Benchmarks refreshing a 32 MB destination whose source changed in one
+/// 64 KB region.
+///
+/// `whole_file` rewrites the file through the kernel copy path. `delta`
+/// (`--no-whole-file`) checksums the basis and copies only the changed
+/// blocks, trading read-side hashing for fewer written bytes.
+fn bench_update_whole_file_vs_delta(c: &mut Criterion) {
+    let mut group = c.benchmark_group("local_copy_update");
+
+    let size = 32 * 1_024 * 1_024;
+    group.throughput(Throughput::Bytes(size as u64));
+
+    for (label, whole_file) in [("whole_file", true), ("delta", false)] {
+        group.bench_with_input(
+            BenchmarkId::new(label, "32MB"),
+            &whole_file,
+            |b, &whole_file| {
+                b.iter_batched(
+                    || {
+                        let tmp = TempDir::new().expect("tempdir");
+                        let src = tmp.path().join("source.dat");
+                        let dst = tmp.path().join("dest.dat");
+                        create_file_with_size(&dst, size);
+                        let mut data = fs::read(&dst).expect("read basis");
+                        for byte in &mut data[size / 2..size / 2 + 64 * 1_024] {
+                            *byte = !*byte;
+                        }
+                        fs::write(&src, &data).expect("write source");
+                        let plan = plan_copy(&src, &dst);
+                        (tmp, plan)
+                    },
+                    |(_tmp, plan)| {
+                        let options = LocalCopyOptions::default()
+                            .whole_file(whole_file)
+                            .ignore_times(true);
+                        let summary = plan
+                            .execute_with_options(LocalCopyExecution::Apply, options)
+                            .expect("copy succeeds");
+                        black_box(summary)
+                    },
+                    criterion::BatchSize::PerIteration,
+                );
+            },
+        );
+    }
+
+    group.finish();
+}
+
 // ---------------------------------------------------------------------------
 // Criterion harness
 // ---------------------------------------------------------------------------
@@ -218,7 +328,9 @@ criterion_group!(
     targets =
         bench_single_file_copy,
         bench_many_small_files,
-        bench_directory_tree
+        bench_directory_tree,
+        bench_copy_buffer_size,
+        bench_update_whole_file_vs_delta
 );
 
 criterion_main!(local_copy_benches);
@@ -12,7 +12,7 @@ use std::time::{Duration, Instant, SystemTime};
 use std::sync::Arc;
 
 use super::ActiveCompressor;
-use super::buffer_pool::{BufferPool, global_buffer_pool};
+use super::buffer_pool::{BufferPool, MAX_BUFFER_POOL_BLOCK_SIZE, global_buffer_pool};
 use super::deferred_sync::{DeferredSync, SyncStrategy};
 use super::filter_program::{
     ExcludeIfPresentLayers, ExcludeIfPresentRule, ExcludeIfPresentStack, FilterContext,
@@ -38,9 +38,9 @@ use super::{
     delete_extraneous_entries, filter_program_local_error, follow_symlink_metadata,
     load_dir_merge_rules_recursive, map_metadata_error, record_directory_subtree,
     remove_source_entry_if_requested, resolve_dir_merge_path, should_skip_copy,
-    symlink_target_is_safe, trace_make_backup_copy, trace_make_backup_device,
-    trace_make_backup_hlink, trace_make_backup_rename, trace_make_backup_symlink,
-    write_sparse_chunk,
+    symlink_target_is_safe, sync_file_flags_if_requested, trace_make_backup_copy,
+    trace_make_backup_device, trace_make_backup_hlink, trace_make_backup_rename,
+    trace_make_backup_symlink, write_sparse_chunk,
 };
 use crate::delta::DeltaSignatureIndex;
 use crate::signature::SignatureBlock;
@@ -186,12 +186,19 @@ pub(crate) struct CopyContext<'a> {
     // upstream: flist.c:1631 send_file1()
     iconv_conversion_error: bool,
     /// Set when a file entry could not be materialised because the operation is
-    /// unsupported on this platform without privilege (currently a Windows file
-    /// symbolic link created by an unprivileged user without Developer Mode).
-    /// The entry is skipped with a warning and this flag drives the final
-    /// `RERR_PARTIAL` (exit 23) exit code, mirroring upstream's `FERROR_XFER`
-    /// handling of a failed `do_symlink()`.
+    /// unsupported on this platform without privilege (a Windows file symbolic
+    /// link created by an unprivileged user without Developer Mode, or a device
+    /// or special node whose `mknod(2)` was refused). The entry is skipped with
+    /// a warning and this flag drives the final `RERR_PARTIAL` (exit 23) exit
+    /// code, mirroring upstream's `FERROR_XFER` handling of a failed
+    /// `do_symlink()` / `do_mknod()`.
     unsupported_operation_skipped: bool,
+    /// Set when an entry inside a directory failed with an I/O error (an
+    /// unreadable source, a failed write) and the copy moved on to its
+    /// siblings. Each failure is reported where it happens; this flag drives
+    /// the final `RERR_PARTIAL` (exit 23) with upstream's "see previous
+    /// errors" summary instead of re-raising only the first failure.
+    entry_transfer_failed: bool,
     /// Set when a `--remove-source-files` source was refused (it changed since
     /// it was copied, or it is the very inode just written to the destination)
     /// or its unlink failed. The entry is left in place and the run continues,
@@ -56,6 +56,51 @@ impl<'a> CopyContext<'a> {
         self.io_errors_occurred = true;
     }
 
+    /// Reports a device or special file whose node could not be created -
+    /// typically `mknod(2)` refusing a device with `EPERM` because the process
+    /// is not root. Prints upstream's diagnostic and sets the soft-error flag
+    /// so the remaining entries are still copied and the run exits
+    /// `RERR_PARTIAL` (23). No skip record is emitted: upstream reports the
+    /// failure only through the error line.
+    ///
+    /// upstream: generator.c:atomic_create() - `rsyserr(FERROR_XFER, errno,
+    /// "mknod %s failed", full_fname(fname))` then the generator moves on.
+    #[cfg(unix)]
+    pub(super) fn record_uncreatable_special(&mut self, destination: &Path, error: &io::Error) {
+        eprintln!(
+            "rsync: [generator] mknod \"{}\" failed: {}",
+            destination.display(),
+            crate::local_copy::upstream_io_error(error)
+        );
+        self.unsupported_operation_skipped = true;
+    }
+
+    /// Reports an entry whose copy failed with an I/O error while its
+    /// directory was being processed. The diagnostic is printed immediately,
+    /// so every failed path is listed in the order it was hit, and the caller
+    /// moves on to the next entry. The run then finishes `RERR_PARTIAL` (23).
+    ///
+    /// upstream: sender.c:send_files() / receiver.c:recv_files() - a per-file
+    /// failure is logged with `rsyserr(FERROR_XFER, ...)`, sets `io_error |=
+    /// IOERR_GENERAL`, and the loop continues; main.c then prints `some
+    /// files/attrs were not transferred (see previous errors)`.
+    pub(super) fn record_entry_transfer_failure(&mut self, error: &LocalCopyError) {
+        match error.kind() {
+            LocalCopyErrorKind::Io {
+                action,
+                path,
+                source,
+            } => eprintln!(
+                "rsync: [sender] failed to {action} \"{}\": {}",
+                path.display(),
+                crate::local_copy::upstream_io_error(source)
+            ),
+            _ => eprintln!("rsync: [sender] {error}"),
+        }
+        self.entry_transfer_failed = true;
+        self.io_errors_occurred = true;
+    }
+
     /// Records a skip event for a directory (when `-r` is not enabled).
     pub(super) fn record_skipped_directory(&mut self, relative: Option<&Path>) {
         if let Some(path) = relative {
@@ -23,7 +23,21 @@ impl<'a> CopyContext<'a> {
         let filter_program = options.filter_program().cloned();
         let timeout = options.timeout();
 
-        let buffer_pool = global_buffer_pool();
+        // A requested copy buffer size that differs from the shared pool's
+        // block size gets a private pool for this run, so other copies in the
+        // process keep the global default.
+        let buffer_pool = {
+            let global = global_buffer_pool();
+            match options
+                .copy_buffer_size_bytes()
+                .map(|size| size.min(MAX_BUFFER_POOL_BLOCK_SIZE))
+            {
+                Some(size) if size > 0 && size != global.buffer_size() => {
+                    Arc::new(BufferPool::with_buffer_size(global.max_buffers(), size))
+                }
+                _ => global,
+            }
+        };
 
         let deferred_sync = if options.fsync_enabled() {
             DeferredSync::new(SyncStrategy::Batched(100))
@@ -140,6 +154,7 @@ impl<'a> CopyContext<'a> {
             io_error_delete_warning_emitted: false,
             iconv_conversion_error: false,
             unsupported_operation_skipped: false,
+            entry_transfer_failed: false,
             sender_remove_error: false,
             delete_io_error: false,
             multi_source: false,
@@ -393,9 +408,6 @@ impl<'a> CopyContext<'a> {
             )?;
         }
 
-        #[cfg(not(any(all(unix, feature = "xattr"), all(any(unix, windows), feature = "acl"))))]
-        let _ = mode;
-
         // upstream: xattrs.c:set_stat_xattr() reads the *source* stat via
         // x_lstat() (get_stat_xattr layered over lstat), so a placeholder that
         // already carries a `user.rsync.%stat` xattr forwards those recorded
@@ -405,6 +417,10 @@ impl<'a> CopyContext<'a> {
         #[cfg(all(unix, feature = "xattr"))]
         store_effective_fake_super_if_requested(&metadata_options, source, destination, metadata)?;
 
+        // File flags go last: an immutable or append-only destination would
+        // reject every metadata update above.
+        sync_file_flags_if_requested(self.options.preserve_fileflags(), mode, source, destination)?;
+
         self.record_hard_link(metadata, destination);
         remove_source_entry_if_requested(self, source, destination, metadata, relative, file_type)?;
 
@@ -1342,6 +1358,13 @@ impl<'a> CopyContext<'a> {
         self.unsupported_operation_skipped
     }
 
+    /// Reports whether any entry failed with an I/O error that was reported
+    /// at its site and skipped, so the transfer finishes with exit code 23
+    /// (`RERR_PARTIAL`).
+    pub(super) const fn entry_transfer_failed(&self) -> bool {
+        self.entry_transfer_failed
+    }
+
     /// Records that a `--remove-source-files` source could not be removed - it
     /// was refused by a safety guard (changed since it was copied, or it is the
     /// same inode as the destination) or its unlink failed. The run continues
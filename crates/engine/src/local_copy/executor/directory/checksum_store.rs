@@ -0,0 +1,534 @@
+//! Persistent `--checksum` sums kept in `.rsync-checksums` side files.
+//!
+//! `-c` hashes every same-size source/destination pair on every run, which
+//! dominates repeated transfers of large, mostly unchanged trees. With a
+//! checksum cache directory configured, each directory processed by the
+//! prefetch pass gets one side file under that directory recording
+//! `(name, size, mtime, digest)` for the files it hashed. The next run reuses
+//! a recorded digest only while the file's size and mtime still equal the
+//! recorded values; any change invalidates the entry and the file is hashed
+//! again.
+//!
+//! Entries are recorded from a stat taken *before* the file is read, so a
+//! write that races the hash moves the mtime and invalidates the entry. Files
+//! modified within [`RACY_WINDOW`] of the run are never recorded: a second
+//! write inside the same mtime tick would otherwise be indistinguishable from
+//! the recorded state (the same reasoning as git's "racily clean" index
+//! entries).
+//!
+//! The cache is an oc-rsync extension with no upstream equivalent; upstream
+//! always recomputes sums (upstream: generator.c:quick_check_ok() calls
+//! `file_checksum()` for every same-size regular file). A missing,
+//! unreadable, or foreign side file is treated as empty, and a failed save
+//! only costs the next run its reuse.
+//!
+//! # Format
+//!
+//! ```text
+//! # oc-rsync checksums v1 <algorithm>
+//! # <directory>
+//! <size> <mtime-sec> <mtime-nsec> <hex-digest> <name>
+//! ```
+//!
+//! The name is the raw file name and runs to the end of the line; names
+//! containing a newline are not cached.
+
+use std::borrow::Cow;
+use std::collections::{HashMap, HashSet};
+use std::ffi::{OsStr, OsString};
+use std::fs;
+use std::io::{self, Write};
+use std::path::{Path, PathBuf};
+use std::time::{Duration, SystemTime, UNIX_EPOCH};
+
+use checksums::strong::{StrongDigest, Xxh3_128};
+
+use crate::signature::SignatureAlgorithm;
+
+/// Suffix of the per-directory side files written under the cache directory.
+pub(crate) const SIDE_FILE_SUFFIX: &str = ".rsync-checksums";
+
+/// Files modified this recently are hashed but never recorded.
+const RACY_WINDOW: Duration = Duration::from_secs(1);
+
+/// First header line; the algorithm is appended so a run with a different
+/// `--checksum-choice` or seed never reuses incompatible digests.
+const MAGIC: &str = "# oc-rsync checksums v1";
+
+/// A digest recorded together with the file state it was computed from.
+#[derive(Debug)]
+struct StoredSum {
+    size: u64,
+    mtime: (u64, u32),
+    digest: Vec<u8>,
+}
+
+/// Recorded sums for the files of one directory.
+#[derive(Debug)]
+pub(crate) struct ChecksumStore {
+    directory: PathBuf,
+    absolute: PathBuf,
+    side_file: PathBuf,
+    algorithm: SignatureAlgorithm,
+    entries: HashMap<OsString, StoredSum>,
+    seen: HashSet<OsString>,
+    dirty: bool,
+}
+
+impl ChecksumStore {
+    /// Loads the side file for `directory` from `cache_dir`.
+    ///
+    /// Never fails: a side file that is missing, unreadable, written for
+    /// another algorithm, or recorded for another directory yields an empty
+    /// store that will overwrite it on [`save`](Self::save).
+    pub(crate) fn open(cache_dir: &Path, directory: &Path, algorithm: SignatureAlgorithm) -> Self {
+        let absolute = std::path::absolute(directory).unwrap_or_else(|_| directory.to_path_buf());
+        let side_file = cache_dir.join(side_file_name(&absolute));
+        let entries = fs::read(&side_file)
+            .ok()
+            .and_then(|data| parse(&data, &absolute, algorithm))
+            .unwrap_or_default();
+        Self {
+            directory: directory.to_path_buf(),
+            absolute,
+            side_file,
+            algorithm,
+            entries,
+            seen: HashSet::new(),
+            dirty: false,
+        }
+    }
+
+    /// Returns the recorded digest for `name` when `metadata` still shows
+    /// the size and mtime it was recorded with.
+    ///
+    /// Takes `&self` so the parallel prefetch can probe the store from every
+    /// worker; hits are confirmed afterwards with [`keep`](Self::keep).
+    pub(crate) fn lookup(&self, name: &OsStr, metadata: &fs::Metadata) -> Option<&[u8]> {
+        let mtime = mtime_key(metadata)?;
+        self.entries
+            .get(name)
+            .filter(|sum| sum.size == metadata.len() && sum.mtime == mtime)
+            .map(|sum| sum.digest.as_slice())
+    }
+
+    /// Marks the entry for `name` as still in use so [`save`](Self::save)
+    /// retains it.
+    pub(crate) fn keep(&mut self, name: &OsStr) {
+        self.seen.insert(name.to_os_string());
+    }
+
+    /// Records `digest` for `name`, computed from the state in `metadata`.
+    ///
+    /// Entries whose mtime falls inside the racy window are dropped instead,
+    /// along with any older entry for the same name.
+    pub(crate) fn record(&mut self, name: &OsStr, metadata: &fs::Metadata, digest: Vec<u8>) {
+        self.seen.insert(name.to_os_string());
+        let Some(mtime) = mtime_key(metadata).filter(|_| !is_racy(metadata)) else {
+            self.dirty |= self.entries.remove(name).is_some();
+            return;
+        };
+        self.entries.insert(
+            name.to_os_string(),
+            StoredSum {
+                size: metadata.len(),
+                mtime,
+                digest,
+            },
+        );
+        self.dirty = true;
+    }
+
+    /// Writes the store back when it changed, keeping only the names looked
+    /// up or recorded during this run so entries for deleted files expire.
+    ///
+    /// The side file is replaced by rename, so a concurrent reader sees
+    /// either the old or the new contents.
+    pub(crate) fn save(&mut self) -> io::Result<()> {
+        let before = self.entries.len();
+        let seen = &self.seen;
+        self.entries.retain(|name, _| seen.contains(name));
+        if !self.dirty && self.entries.len() == before {
+            return Ok(());
+        }
+
+        let parent = self.side_file.parent().unwrap_or(Path::new("."));
+        fs::create_dir_all(parent)?;
+        let mut out = Vec::with_capacity(64 * (self.entries.len() + 2));
+        writeln!(out, "{MAGIC} {:?}", self.algorithm)?;
+        out.extend_from_slice(b"# ");
+        out.extend_from_slice(&name_bytes(self.absolute.as_os_str()).unwrap_or_default());
+        out.push(b'\n');
+        for (name, sum) in &self.entries {
+            let Some(name) = name_bytes(name) else {
+                continue;
+            };
+            write!(out, "{} {} {} ", sum.size, sum.mtime.0, sum.mtime.1)?;
+            for byte in &sum.digest {
+                write!(out, "{byte:02x}")?;
+            }
+            out.push(b' ');
+            out.extend_from_slice(&name);
+            out.push(b'\n');
+        }
+
+        let mut staging = self.side_file.clone().into_os_string();
+        staging.push(format!(".{}.tmp", std::process::id()));
+        let staging = PathBuf::from(staging);
+        fs::write(&staging, &out)?;
+        fs::rename(&staging, &self.side_file).inspect_err(|_| {
+            let _ = fs::remove_file(&staging);
+        })?;
+        self.dirty = false;
+        Ok(())
+    }
+
+    /// Directory whose files this store describes, as passed to
+    /// [`open`](Self::open).
+    pub(crate) fn directory(&self) -> &Path {
+        &self.directory
+    }
+}
+
+/// Source and destination stores for one directory pass, with counters of
+/// how each prefetched sum was obtained.
+#[derive(Debug)]
+pub(crate) struct ChecksumStores {
+    pub(crate) source: ChecksumStore,
+    pub(crate) destination: ChecksumStore,
+    hashed: usize,
+    reused: usize,
+}
+
+impl ChecksumStores {
+    /// Opens the stores for a source directory and its destination.
+    pub(crate) fn open(
+        cache_dir: &Path,
+        source: &Path,
+        destination: &Path,
+        algorithm: SignatureAlgorithm,
+    ) -> Self {
+        Self {
+            source: ChecksumStore::open(cache_dir, source, algorithm),
+            destination: ChecksumStore::open(cache_dir, destination, algorithm),
+            hashed: 0,
+            reused: 0,
+        }
+    }
+
+    /// Counts one side of a pair as hashed from disk or reused from a store.
+    pub(crate) fn count(&mut self, reused: bool) {
+        if reused {
+            self.reused += 1;
+        } else {
+            self.hashed += 1;
+        }
+    }
+
+    /// Number of files read and hashed during this pass.
+    #[allow(dead_code)] // Reported by tests to prove reuse
+    pub(crate) fn hashed(&self) -> usize {
+        self.hashed
+    }
+
+    /// Number of digests taken from the stores instead of hashed.
+    #[allow(dead_code)] // Reported by tests to prove reuse
+    pub(crate) fn reused(&self) -> usize {
+        self.reused
+    }
+
+    /// Saves both stores. Failures are ignored: the cache only saves work,
+    /// so losing an update must not fail the transfer.
+    pub(crate) fn save(&mut self) {
+        let _ = self.source.save();
+        let _ = self.destination.save();
+    }
+}
+
+/// Side file name for `directory`: a 128-bit hash of its absolute path, so
+/// every directory maps to one flat file under the cache directory.
+fn side_file_name(directory: &Path) -> String {
+    let mut hasher = Xxh3_128::new(0);
+    hasher.update(&name_bytes(directory.as_os_str()).unwrap_or_default());
+    let mut name = String::with_capacity(32 + SIDE_FILE_SUFFIX.len());
+    for byte in hasher.finalize().as_ref() {
+        name.push_str(&format!("{byte:02x}"));
+    }
+    name.push_str(SIDE_FILE_SUFFIX);
+    name
+}
+
+/// Parses a side file, returning `None` when its header does not match
+/// `directory` and `algorithm`. Malformed entry lines are skipped.
+fn parse(
+    data: &[u8],
+    directory: &Path,
+    algorithm: SignatureAlgorithm,
+) -> Option<HashMap<OsString, StoredSum>> {
+    let mut lines = data.split(|&b| b == b'\n');
+    let magic = format!("{MAGIC} {algorithm:?}");
+    if lines.next()? != magic.as_bytes() {
+        return None;
+    }
+    let dir_line = lines.next()?.strip_prefix(b"# ")?;
+    if dir_line != name_bytes(directory.as_os_str())?.as_ref() {
+        return None;
+    }
+
+    let mut entries = HashMap::new();
+    for line in lines {
+        if let Some((name, sum)) = parse_entry(line) {
+            entries.insert(name, sum);
+        }
+    }
+    Some(entries)
+}
+
+fn parse_entry(line: &[u8]) -> Option<(OsString, StoredSum)> {
+    let mut fields = line.splitn(5, |&b| b == b' ');
+    let size = parse_number(fields.next()?)?;
+    let sec = parse_number(fields.next()?)?;
+    let nsec = u32::try_from(parse_number(fields.next()?)?).ok()?;
+    let digest = parse_hex(fields.next()?)?;
+    let name = name_from_bytes(fields.next().filter(|name| !name.is_empty())?)?;
+    Some((
+        name,
+        StoredSum {
+            size,
+            mtime: (sec, nsec),
+            digest,
+        },
+    ))
+}
+
+pub(super) fn parse_number(field: &[u8]) -> Option<u64> {
+    std::str::from_utf8(field).ok()?.parse().ok()
+}
+
+pub(super) fn parse_hex(field: &[u8]) -> Option<Vec<u8>> {
+    if field.is_empty() || field.len() % 2 != 0 {
+        return None;
+    }
+    field
+        .chunks_exact(2)
+        .map(|pair| u8::from_str_radix(std::str::from_utf8(pair).ok()?, 16).ok())
+        .collect()
+}
+
+/// Modification time as whole seconds and nanoseconds since the epoch, or
+/// `None` when unavailable or before 1970 (such files are never cached).
+pub(super) fn mtime_key(metadata: &fs::Metadata) -> Option<(u64, u32)> {
+    let since_epoch = metadata.modified().ok()?.duration_since(UNIX_EPOCH).ok()?;
+    Some((since_epoch.as_secs(), since_epoch.subsec_nanos()))
+}
+
+fn is_racy(metadata: &fs::Metadata) -> bool {
+    let Ok(modified) = metadata.modified() else {
+        return true;
+    };
+    match SystemTime::now().duration_since(modified) {
+        Ok(age) => age < RACY_WINDOW,
+        // A future mtime can still be rewritten within the same tick.
+        Err(_) => true,
+    }
+}
+
+#[cfg(unix)]
+fn name_bytes(name: &OsStr) -> Option<Cow<'_, [u8]>> {
+    use std::os::unix::ffi::OsStrExt;
+    let bytes = name.as_bytes();
+    (!bytes.contains(&b'\n')).then_some(Cow::Borrowed(bytes))
+}
+
+#[cfg(not(unix))]
+fn name_bytes(name: &OsStr) -> Option<Cow<'_, [u8]>> {
+    let name = name.to_str()?;
+    (!name.contains('\n')).then_some(Cow::Borrowed(name.as_bytes()))
+}
+
+#[cfg(unix)]
+fn name_from_bytes(bytes: &[u8]) -> Option<OsString> {
+    use std::os::unix::ffi::OsStrExt;
+    Some(OsStr::from_bytes(bytes).to_os_string())
+}
+
+#[cfg(not(unix))]
+fn name_from_bytes(bytes: &[u8]) -> Option<OsString> {
+    std::str::from_utf8(bytes).ok().map(OsString::from)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use filetime::{FileTime, set_file_mtime};
+    use test_support::create_tempdir;
+
+    const ALGORITHM: SignatureAlgorithm = SignatureAlgorithm::Xxh3_128 { seed: 0 };
+
+    /// Writes `data` to `path` and backdates it out of the racy window.
+    fn write_settled(path: &Path, data: &[u8], mtime: i64) {
+        fs::write(path, data).unwrap();
+        set_file_mtime(path, FileTime::from_unix_time(mtime, 0)).unwrap();
+    }
+
+    #[test]
+    fn recorded_sum_survives_reopen() {
+        let dir = create_tempdir();
+        let cache = dir.path().join("cache");
+        let file = dir.path().join("a.bin");
+        write_settled(&file, b"payload", 1_600_000_000);
+        let meta = fs::metadata(&file).unwrap();
+
+        let mut store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        assert!(store.lookup(OsStr::new("a.bin"), &meta).is_none());
+        store.record(OsStr::new("a.bin"), &meta, vec![0xde, 0xad]);
+        store.save().unwrap();
+
+        let store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        assert_eq!(
+            store.lookup(OsStr::new("a.bin"), &meta),
+            Some(&[0xde, 0xad][..])
+        );
+        let side_files: Vec<_> = fs::read_dir(&cache).unwrap().collect();
+        assert_eq!(side_files.len(), 1);
+    }
+
+    #[test]
+    fn size_or_mtime_change_invalidates_entry() {
+        let dir = create_tempdir();
+        let cache = dir.path().join("cache");
+        let file = dir.path().join("a.bin");
+        write_settled(&file, b"payload", 1_600_000_000);
+
+        let mut store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        store.record(OsStr::new("a.bin"), &fs::metadata(&file).unwrap(), vec![1]);
+        store.save().unwrap();
+
+        let store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        set_file_mtime(&file, FileTime::from_unix_time(1_600_000_001, 0)).unwrap();
+        assert!(
+            store
+                .lookup(OsStr::new("a.bin"), &fs::metadata(&file).unwrap())
+                .is_none()
+        );
+
+        write_settled(&file, b"payload+", 1_600_000_000);
+        assert!(
+            store
+                .lookup(OsStr::new("a.bin"), &fs::metadata(&file).unwrap())
+                .is_none()
+        );
+    }
+
+    #[test]
+    fn racy_entries_are_not_recorded() {
+        let dir = create_tempdir();
+        let cache = dir.path().join("cache");
+        let file = dir.path().join("fresh.bin");
+        fs::write(&file, b"just written").unwrap();
+        let meta = fs::metadata(&file).unwrap();
+
+        let mut store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        store.record(OsStr::new("fresh.bin"), &meta, vec![1]);
+        store.save().unwrap();
+
+        let store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        assert!(store.lookup(OsStr::new("fresh.bin"), &meta).is_none());
+    }
+
+    #[test]
+    fn other_algorithm_ignores_side_file() {
+        let dir = create_tempdir();
+        let cache = dir.path().join("cache");
+        let file = dir.path().join("a.bin");
+        write_settled(&file, b"payload", 1_600_000_000);
+        let meta = fs::metadata(&file).unwrap();
+
+        let mut store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        store.record(OsStr::new("a.bin"), &meta, vec![1]);
+        store.save().unwrap();
+
+        let store = ChecksumStore::open(&cache, dir.path(), SignatureAlgorithm::Sha1);
+        assert!(store.lookup(OsStr::new("a.bin"), &meta).is_none());
+    }
+
+    #[test]
+    fn save_drops_entries_not_seen_this_run() {
+        let dir = create_tempdir();
+        let cache = dir.path().join("cache");
+        let kept = dir.path().join("kept.bin");
+        let gone = dir.path().join("gone.bin");
+        write_settled(&kept, b"kept", 1_600_000_000);
+        write_settled(&gone, b"gone", 1_600_000_000);
+        let kept_meta = fs::metadata(&kept).unwrap();
+        let gone_meta = fs::metadata(&gone).unwrap();
+
+        let mut store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        store.record(OsStr::new("kept.bin"), &kept_meta, vec![1]);
+        store.record(OsStr::new("gone.bin"), &gone_meta, vec![2]);
+        store.save().unwrap();
+
+        let mut store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        assert!(store.lookup(OsStr::new("kept.bin"), &kept_meta).is_some());
+        store.keep(OsStr::new("kept.bin"));
+        store.save().unwrap();
+
+        let store = ChecksumStore::open(&cache, dir.path(), ALGORITHM);
+        assert!(store.lookup(OsStr::new("kept.bin"), &kept_meta).is_some());
+        assert!(store.lookup(OsStr::new("gone.bin"), &gone_meta).is_none());
+    }
+
+    #[test]
+    fn second_prefetch_hashes_nothing() {
+        use super::super::parallel_checksum::{ChecksumCache, FilePair};
+
+        let dir = create_tempdir();
+        let cache = dir.path().join("cache");
+        let src = dir.path().join("src");
+        let dst = dir.path().join("dst");
+        fs::create_dir_all(&src).unwrap();
+        fs::create_dir_all(&dst).unwrap();
+        let pairs: Vec<FilePair> = (0..4)
+            .map(|i| {
+                let name = format!("f{i}.bin");
+                write_settled(&src.join(&name), &[i as u8; 4096], 1_600_000_000);
+                write_settled(&dst.join(&name), &[i as u8; 4096], 1_600_000_000);
+                FilePair {
+                    source: src.join(&name),
+                    destination: dst.join(&name),
+                    source_size: 4096,
+                    destination_size: 4096,
+                }
+            })
+            .collect();
+
+        let run = || {
+            let mut stores = ChecksumStores::open(&cache, &src, &dst, ALGORITHM);
+            let checksums =
+                ChecksumCache::from_prefetch_cached(&pairs, ALGORITHM, None, &mut stores);
+            assert!(
+                pairs
+                    .iter()
+                    .all(|p| checksums.lookup(&p.source) == Some(true))
+            );
+            stores.save();
+            (stores.hashed(), stores.reused())
+        };
+
+        assert_eq!(run(), (8, 0), "first run hashes both sides of every pair");
+        assert_eq!(run(), (0, 8), "second run reads every sum from the cache");
+    }
+
+    #[test]
+    fn parse_rejects_malformed_lines() {
+        assert!(parse_entry(b"12 1 2 zz name").is_none());
+        assert!(parse_entry(b"12 1 2 abc name").is_none());
+        assert!(parse_entry(b"12 1 2 ab").is_none());
+        let (name, sum) = parse_entry(b"12 1 2 ab01 with space").unwrap();
+        assert_eq!(name, OsString::from("with space"));
+        assert_eq!(
+            (sum.size, sum.mtime, sum.digest),
+            (12, (1, 2), vec![0xab, 1])
+        );
+    }
+}
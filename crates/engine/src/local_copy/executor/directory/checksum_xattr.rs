@@ -0,0 +1,169 @@
+//! Precomputed `--checksum` sums read from an extended attribute.
+//!
+//! Trees that are checksummed by some other process (an ingest pipeline, a
+//! backup tool) can leave each file's whole-file digest in an extended
+//! attribute. With an attribute name configured, the `-c` prefetch reads it
+//! before hashing a source or destination file and takes the recorded digest
+//! instead of rereading the data, as long as the file still has the size and
+//! mtime the digest was recorded for. The attribute is never written.
+//!
+//! Like the `.rsync-checksums` side files this is an oc-rsync extension;
+//! upstream always recomputes sums (upstream: generator.c:quick_check_ok()
+//! calls `file_checksum()` for every same-size regular file).
+//!
+//! # Format
+//!
+//! ```text
+//! <algorithm> <size> <mtime-sec> <mtime-nsec> <hex-digest>
+//! ```
+//!
+//! `<algorithm>` is the `--checksum-choice` name of an unseeded algorithm
+//! (`md4`, `md5`, `sha1`, `xxh64`, `xxh3`, `xxh128`). A value recorded for a
+//! different algorithm, a seeded one, or with a digest of the wrong length is
+//! ignored and the file is hashed as usual.
+
+use std::ffi::OsStr;
+use std::fs;
+use std::path::Path;
+
+use crate::signature::SignatureAlgorithm;
+
+use super::checksum_store::{mtime_key, parse_hex, parse_number};
+
+/// Attribute name suggested for precomputed sums.
+pub const DEFAULT_CHECKSUM_XATTR: &str = "user.rsync.checksum";
+
+/// Returns the digest recorded in `path`'s `name` attribute when it was
+/// computed with `algorithm` from the state `metadata` still shows.
+pub(crate) fn xattr_checksum(
+    path: &Path,
+    name: &OsStr,
+    metadata: &fs::Metadata,
+    algorithm: SignatureAlgorithm,
+) -> Option<Vec<u8>> {
+    read_checksum_xattr(
+        path,
+        name,
+        metadata,
+        algorithm_name(algorithm)?,
+        algorithm.digest_len(),
+    )
+}
+
+/// Returns the `digest_len`-byte digest recorded in `path`'s `name` attribute
+/// when it names `algorithm` and the size and mtime `metadata` still shows.
+///
+/// `algorithm` is the `--checksum-choice` name of an unseeded algorithm. The
+/// transfer role calls this with the negotiated `-c` algorithm, so remote
+/// transfers trust exactly the values local copies do.
+pub fn read_checksum_xattr(
+    path: &Path,
+    name: &OsStr,
+    metadata: &fs::Metadata,
+    algorithm: &str,
+    digest_len: usize,
+) -> Option<Vec<u8>> {
+    let value = ::metadata::read_xattr(path, name.as_encoded_bytes()).ok()??;
+
+    let mut fields = value.split(|&b| b == b' ');
+    if fields.next()? != algorithm.as_bytes() {
+        return None;
+    }
+    let size = parse_number(fields.next()?)?;
+    let sec = parse_number(fields.next()?)?;
+    let nsec = u32::try_from(parse_number(fields.next()?)?).ok()?;
+    let digest = parse_hex(fields.next()?.trim_ascii_end())?;
+    if fields.next().is_some()
+        || digest.len() != digest_len
+        || size != metadata.len()
+        || Some((sec, nsec)) != mtime_key(metadata)
+    {
+        return None;
+    }
+    Some(digest)
+}
+
+/// `--checksum-choice` name of `algorithm`, or `None` for a seeded variant
+/// whose digest depends on the session and so cannot be precomputed.
+fn algorithm_name(algorithm: SignatureAlgorithm) -> Option<&'static str> {
+    match algorithm {
+        SignatureAlgorithm::Md4 => Some("md4"),
+        SignatureAlgorithm::Md5 { seed_config } if seed_config.value.is_none() => Some("md5"),
+        SignatureAlgorithm::Sha1 => Some("sha1"),
+        SignatureAlgorithm::Xxh64 { seed: 0 } => Some("xxh64"),
+        SignatureAlgorithm::Xxh3 { seed: 0 } => Some("xxh3"),
+        SignatureAlgorithm::Xxh3_128 { seed: 0 } => Some("xxh128"),
+        _ => None,
+    }
+}
+
+#[cfg(all(test, unix, feature = "xattr"))]
+mod tests {
+    use super::*;
+    use filetime::{FileTime, set_file_mtime};
+
+    const NAME: &str = DEFAULT_CHECKSUM_XATTR;
+    const ALGORITHM: SignatureAlgorithm = SignatureAlgorithm::Xxh3_128 { seed: 0 };
+
+    /// Writes `a.bin` with a fixed mtime and returns it, or `None` when the
+    /// filesystem refuses user xattrs.
+    fn file_with_xattr(dir: &Path, value: &str) -> Option<std::path::PathBuf> {
+        let file = dir.join("a.bin");
+        fs::write(&file, b"payload").unwrap();
+        set_file_mtime(&file, FileTime::from_unix_time(1_600_000_000, 5)).unwrap();
+        xattr::set(&file, NAME, value.as_bytes()).ok()?;
+        Some(file)
+    }
+
+    #[test]
+    fn matching_value_yields_digest() {
+        let dir = tempfile::tempdir().unwrap();
+        let digest = "ab".repeat(16);
+        let value = format!("xxh128 7 1600000000 5 {digest}\n");
+        let Some(file) = file_with_xattr(dir.path(), &value) else {
+            eprintln!("user xattrs unsupported, skipping");
+            return;
+        };
+        let meta = fs::metadata(&file).unwrap();
+        assert_eq!(
+            xattr_checksum(&file, OsStr::new(NAME), &meta, ALGORITHM),
+            Some(vec![0xab; 16])
+        );
+    }
+
+    #[test]
+    fn stale_or_foreign_values_are_ignored() {
+        let digest = "ab".repeat(16);
+        for value in [
+            format!("xxh128 8 1600000000 5 {digest}"),
+            format!("xxh128 7 1600000001 5 {digest}"),
+            format!("md5 7 1600000000 5 {digest}"),
+            "xxh128 7 1600000000 5 abab".to_owned(),
+            format!("xxh128 7 1600000000 5 {digest} extra"),
+        ] {
+            let dir = tempfile::tempdir().unwrap();
+            let Some(file) = file_with_xattr(dir.path(), &value) else {
+                eprintln!("user xattrs unsupported, skipping");
+                return;
+            };
+            let meta = fs::metadata(&file).unwrap();
+            assert_eq!(
+                xattr_checksum(&file, OsStr::new(NAME), &meta, ALGORITHM),
+                None,
+                "{value:?} must not be trusted"
+            );
+        }
+    }
+
+    #[test]
+    fn seeded_algorithms_never_consult_the_attribute() {
+        assert_eq!(
+            algorithm_name(SignatureAlgorithm::Xxh3_128 { seed: 9 }),
+            None
+        );
+        assert_eq!(
+            algorithm_name(SignatureAlgorithm::Md4Seeded { seed: 1 }),
+            None
+        );
+    }
+}
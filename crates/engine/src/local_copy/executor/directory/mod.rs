@@ -4,9 +4,12 @@ mod planner;
 mod recursive;
 mod support;
 
+mod checksum_store;
+mod checksum_xattr;
 mod parallel_checksum;
 mod parallel_planner;
 
+pub use checksum_xattr::{DEFAULT_CHECKSUM_XATTR, read_checksum_xattr};
 pub(crate) use parallel_checksum::ChecksumCache;
 pub(crate) use recursive::capture_batch_file_entry;
 pub(crate) use recursive::copy_directory_recursive;
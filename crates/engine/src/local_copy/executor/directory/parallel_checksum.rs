@@ -19,6 +19,8 @@
 //!    checksums, maintaining correct ordering.
 
 use std::collections::HashMap;
+use std::ffi::OsStr;
+use std::fs;
 #[cfg(unix)]
 use std::fs::File;
 use std::io::{self, Read};
@@ -36,6 +38,9 @@ use fast_io::windows_chunked_reader::WindowsChunkedReader;
 use crate::local_copy::buffer_pool::{BufferPool, global_buffer_pool};
 use crate::signature::SignatureAlgorithm;
 
+use super::checksum_store::{ChecksumStore, ChecksumStores};
+use super::checksum_xattr::xattr_checksum;
+
 /// Precomputed checksum for a file.
 #[derive(Debug, Clone)]
 pub(crate) struct FileChecksum {
@@ -86,6 +91,7 @@ pub(crate) struct FilePair {
 ///
 /// * `pairs` - File pairs to compute checksums for
 /// * `algorithm` - Checksum algorithm to use
+/// * `xattr` - Extended attribute that may hold a precomputed sum
 ///
 /// # Returns
 ///
@@ -93,6 +99,7 @@ pub(crate) struct FilePair {
 pub(crate) fn prefetch_checksums(
     pairs: &[FilePair],
     algorithm: SignatureAlgorithm,
+    xattr: Option<&OsStr>,
 ) -> HashMap<PathBuf, ChecksumPrefetchResult> {
     let buffer_pool = global_buffer_pool();
 
@@ -113,13 +120,22 @@ pub(crate) fn prefetch_checksums(
             let pool_dst = Arc::clone(&buffer_pool);
 
             let (source_checksum, destination_checksum) = rayon::join(
-                || compute_file_checksum(&pair.source, pair.source_size, algorithm, &pool_src),
                 || {
-                    compute_file_checksum(
+                    recorded_or_computed_checksum(
+                        &pair.source,
+                        pair.source_size,
+                        algorithm,
+                        &pool_src,
+                        xattr,
+                    )
+                },
+                || {
+                    recorded_or_computed_checksum(
                         &pair.destination,
                         pair.destination_size,
                         algorithm,
                         &pool_dst,
+                        xattr,
                     )
                 },
             );
@@ -141,6 +157,180 @@ pub(crate) fn prefetch_checksums(
     map
 }
 
+/// Same as [`prefetch_checksums`], but reuses digests recorded in the
+/// persistent `--checksum` cache and records the ones it computes.
+///
+/// Stores are only consulted for files directly inside their directory;
+/// anything else is hashed as if no cache were configured. The parallel pass
+/// only reads the stores; hits and fresh digests are settled afterwards on
+/// the calling thread. A destination sum is kept only when it matched its
+/// source, since a mismatched destination is about to be rewritten.
+pub(crate) fn prefetch_checksums_cached(
+    pairs: &[FilePair],
+    algorithm: SignatureAlgorithm,
+    xattr: Option<&OsStr>,
+    stores: &mut ChecksumStores,
+) -> HashMap<PathBuf, ChecksumPrefetchResult> {
+    let buffer_pool = global_buffer_pool();
+    let shared = &*stores;
+
+    let results: Vec<_> = pairs
+        .par_iter()
+        .filter(|pair| pair.source_size == pair.destination_size)
+        .map(|pair| {
+            let pool_src = Arc::clone(&buffer_pool);
+            let pool_dst = Arc::clone(&buffer_pool);
+            rayon::join(
+                || {
+                    cached_file_checksum(
+                        &pair.source,
+                        pair.source_size,
+                        algorithm,
+                        &pool_src,
+                        xattr,
+                        &shared.source,
+                    )
+                },
+                || {
+                    cached_file_checksum(
+                        &pair.destination,
+                        pair.destination_size,
+                        algorithm,
+                        &pool_dst,
+                        xattr,
+                        &shared.destination,
+                    )
+                },
+            )
+        })
+        .collect();
+
+    let mut map = HashMap::with_capacity(pairs.len());
+    let eligible = pairs
+        .iter()
+        .filter(|pair| pair.source_size == pair.destination_size);
+    for (pair, (source, destination)) in eligible.zip(results) {
+        let result = ChecksumPrefetchResult {
+            source_checksum: source.checksum.clone(),
+            destination_checksum: destination.checksum.clone(),
+        };
+        let matched = result.checksums_match();
+        settle_cached_checksum(stores, &pair.source, source, true, true);
+        settle_cached_checksum(stores, &pair.destination, destination, false, matched);
+        map.insert(pair.source.clone(), result);
+    }
+    for pair in pairs
+        .iter()
+        .filter(|pair| pair.source_size != pair.destination_size)
+    {
+        map.insert(
+            pair.source.clone(),
+            ChecksumPrefetchResult {
+                source_checksum: None,
+                destination_checksum: None,
+            },
+        );
+    }
+    map
+}
+
+/// One side of a cached prefetch: the digest, the pre-read stat it is keyed
+/// by, and whether it came from the store.
+struct CachedChecksum {
+    checksum: Option<FileChecksum>,
+    metadata: Option<fs::Metadata>,
+    reused: bool,
+}
+
+/// Returns the stored digest for `path` when its size and mtime are
+/// unchanged, otherwise takes it from `xattr` or hashes the file.
+///
+/// The stat precedes the read, so a write racing the hash leaves a newer
+/// mtime on disk than the one the fresh digest is recorded under.
+fn cached_file_checksum(
+    path: &Path,
+    file_size: u64,
+    algorithm: SignatureAlgorithm,
+    buffer_pool: &Arc<BufferPool>,
+    xattr: Option<&OsStr>,
+    store: &ChecksumStore,
+) -> CachedChecksum {
+    let metadata = (path.parent() == Some(store.directory()))
+        .then(|| fs::metadata(path).ok())
+        .flatten()
+        .filter(|metadata| metadata.len() == file_size);
+
+    let stored = match (&metadata, path.file_name()) {
+        (Some(metadata), Some(name)) => store.lookup(name, metadata),
+        _ => None,
+    };
+    if let Some(digest) = stored {
+        return CachedChecksum {
+            checksum: Some(FileChecksum {
+                digest: digest.to_vec(),
+                size: file_size,
+            }),
+            metadata,
+            reused: true,
+        };
+    }
+
+    CachedChecksum {
+        checksum: recorded_or_computed_checksum(path, file_size, algorithm, buffer_pool, xattr),
+        metadata,
+        reused: false,
+    }
+}
+
+/// Takes the digest precomputed in the `xattr` attribute when it is still
+/// valid for `path`, otherwise hashes the file.
+fn recorded_or_computed_checksum(
+    path: &Path,
+    file_size: u64,
+    algorithm: SignatureAlgorithm,
+    buffer_pool: &Arc<BufferPool>,
+    xattr: Option<&OsStr>,
+) -> Option<FileChecksum> {
+    let recorded = xattr.and_then(|name| {
+        let metadata = fs::metadata(path)
+            .ok()
+            .filter(|metadata| metadata.len() == file_size)?;
+        xattr_checksum(path, name, &metadata, algorithm)
+    });
+    if let Some(digest) = recorded {
+        return Some(FileChecksum {
+            digest,
+            size: file_size,
+        });
+    }
+    compute_file_checksum(path, file_size, algorithm, buffer_pool)
+}
+
+/// Counts one side of a cached prefetch and, when `retain` is set, keeps
+/// its reused entry or records its fresh digest.
+fn settle_cached_checksum(
+    stores: &mut ChecksumStores,
+    path: &Path,
+    side: CachedChecksum,
+    is_source: bool,
+    retain: bool,
+) {
+    stores.count(side.reused);
+    let store = if is_source {
+        &mut stores.source
+    } else {
+        &mut stores.destination
+    };
+    let Some(name) = path.file_name().filter(|_| retain) else {
+        return;
+    };
+    match (side.reused, side.checksum, side.metadata) {
+        (true, _, _) => store.keep(name),
+        (false, Some(checksum), Some(metadata)) => store.record(name, &metadata, checksum.digest),
+        _ => {}
+    }
+}
+
 /// Computes the checksum of a single file.
 ///
 /// upstream: checksum.c:402 `file_checksum()` reads the file through
@@ -278,7 +468,7 @@ pub(crate) fn should_skip_with_prefetched_checksum(
 ///
 /// ```ignore
 /// let pairs = collect_file_pairs(&planned_entries);
-/// let cache = ChecksumCache::from_prefetch(&pairs, algorithm);
+/// let cache = ChecksumCache::from_prefetch(&pairs, algorithm, None);
 ///
 /// // Later, during copy decision:
 /// if let Some(matches) = cache.lookup(source_path) {
@@ -302,9 +492,26 @@ impl ChecksumCache {
     ///
     /// This is the primary constructor, computing all checksums in parallel
     /// using rayon.
-    pub(crate) fn from_prefetch(pairs: &[FilePair], algorithm: SignatureAlgorithm) -> Self {
+    pub(crate) fn from_prefetch(
+        pairs: &[FilePair],
+        algorithm: SignatureAlgorithm,
+        xattr: Option<&OsStr>,
+    ) -> Self {
+        Self {
+            inner: prefetch_checksums(pairs, algorithm, xattr),
+        }
+    }
+
+    /// Creates a checksum cache like [`from_prefetch`](Self::from_prefetch),
+    /// reusing and updating the persistent sums in `stores`.
+    pub(crate) fn from_prefetch_cached(
+        pairs: &[FilePair],
+        algorithm: SignatureAlgorithm,
+        xattr: Option<&OsStr>,
+        stores: &mut ChecksumStores,
+    ) -> Self {
         Self {
-            inner: prefetch_checksums(pairs, algorithm),
+            inner: prefetch_checksums_cached(pairs, algorithm, xattr, stores),
         }
     }
 
@@ -362,7 +569,7 @@ mod tests {
             seed_config: checksums::strong::Md5Seed::none(),
         };
 
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         let result = results.get(&source).unwrap();
 
         assert!(result.checksums_match());
@@ -388,7 +595,7 @@ mod tests {
             seed_config: checksums::strong::Md5Seed::none(),
         };
 
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         let result = results.get(&source).unwrap();
 
         assert!(!result.checksums_match());
@@ -414,7 +621,7 @@ mod tests {
             seed_config: checksums::strong::Md5Seed::none(),
         };
 
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         let result = results.get(&source).unwrap();
 
         assert!(result.source_checksum.is_none());
@@ -441,7 +648,7 @@ mod tests {
             seed_config: checksums::strong::Md5Seed::none(),
         };
 
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         let result = results.get(&source).unwrap();
 
         assert!(result.source_checksum.is_some());
@@ -474,7 +681,7 @@ mod tests {
             seed_config: checksums::strong::Md5Seed::none(),
         };
 
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
 
         assert_eq!(results.len(), 100);
         for pair in &pairs {
@@ -502,7 +709,7 @@ mod tests {
 
         let algorithm = SignatureAlgorithm::Xxh3 { seed: 0 };
 
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         let result = results.get(&source).unwrap();
 
         assert!(result.checksums_match());
@@ -528,7 +735,7 @@ mod tests {
         }];
 
         let algorithm = SignatureAlgorithm::Xxh3_128 { seed: 0 };
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         let result = results.get(&source).unwrap();
 
         assert!(result.checksums_match());
@@ -558,7 +765,7 @@ mod tests {
         }];
 
         let algorithm = SignatureAlgorithm::Xxh3_128 { seed: 0 };
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         let result = results.get(&source).unwrap();
 
         assert!(!result.checksums_match());
@@ -668,7 +875,7 @@ mod tests {
             seed_config: checksums::strong::Md5Seed::none(),
         };
 
-        let prefetched = prefetch_checksums(&pairs, algorithm);
+        let prefetched = prefetch_checksums(&pairs, algorithm, None);
         let result = should_skip_with_prefetched_checksum(&prefetched, &source);
 
         assert_eq!(result, Some(true));
@@ -707,7 +914,7 @@ mod tests {
             });
         }
 
-        let results = prefetch_checksums(&pairs, algorithm);
+        let results = prefetch_checksums(&pairs, algorithm, None);
         assert_eq!(results.len(), sizes.len());
         for (pair, &n) in pairs.iter().zip(sizes.iter()) {
             let result = results.get(&pair.source).expect("result for source");
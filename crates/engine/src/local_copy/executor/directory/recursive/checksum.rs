@@ -10,6 +10,7 @@ use std::path::{Path, PathBuf};
 use crate::local_copy::CopyContext;
 
 use super::super::super::transcode_filename_component;
+use super::super::checksum_store::ChecksumStores;
 use super::super::parallel_checksum::{ChecksumCache, FilePair};
 use super::super::planner::{DirectoryPlan, EntryAction};
 use protocol::iconv::FilenameConverter;
@@ -128,6 +129,12 @@ pub(crate) fn collect_file_pairs_for_checksum(
 ///
 /// A populated [`ChecksumCache`] if checksum mode is enabled and there are
 /// eligible file pairs, or an empty cache otherwise.
+///
+/// With a checksum cache directory configured, sums recorded by a previous
+/// run are reused for files whose size and mtime are unchanged, and the
+/// sums computed here are saved for the next run. With a checksum xattr
+/// configured, a valid sum precomputed in that attribute is used in place of
+/// hashing the file.
 pub(crate) fn prefetch_directory_checksums(
     context: &mut CopyContext,
     plan: &DirectoryPlan<'_>,
@@ -154,5 +161,13 @@ pub(crate) fn prefetch_directory_checksums(
 
     // Compute checksums in parallel
     let algorithm = context.options().checksum_algorithm();
-    ChecksumCache::from_prefetch(&pairs, algorithm)
+    let xattr = context.options().checksum_xattr();
+    let Some(cache_dir) = context.options().checksum_cache_dir() else {
+        return ChecksumCache::from_prefetch(&pairs, algorithm, xattr);
+    };
+    let source = pairs[0].source.parent().unwrap_or(Path::new(""));
+    let mut stores = ChecksumStores::open(cache_dir, source, destination, algorithm);
+    let cache = ChecksumCache::from_prefetch_cached(&pairs, algorithm, xattr, &mut stores);
+    stores.save();
+    cache
 }
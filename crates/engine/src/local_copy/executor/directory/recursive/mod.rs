@@ -571,11 +571,10 @@ fn copy_directory_recursive_inner(
             Err(error) if error.is_io_error() => {
                 // upstream: rsync continues transferring remaining entries when
                 // individual files fail with I/O errors (permission denied, etc.),
-                // regardless of whether --delete is active.
-                context.record_io_error();
-                if first_entry_io_error.is_none() {
-                    first_entry_io_error = Some(error);
-                }
+                // regardless of whether --delete is active. Each failure is
+                // reported here rather than propagated, so a second unreadable
+                // file is listed too instead of being masked by the first.
+                context.record_entry_transfer_failure(&error);
             }
             Err(error) if error.is_delete_limit_error() => {
                 // upstream: main.c:1356 - a --max-delete limit hit while
@@ -643,8 +642,9 @@ fn copy_directory_recursive_inner(
         return Err(error);
     }
 
-    // If there were I/O errors during entry processing, propagate the first
-    // one now that deletions and metadata finalization have completed.
+    // If an entry vanished during processing, propagate the first such error
+    // now that deletions and metadata finalization have completed. Other I/O
+    // failures were already reported and recorded on the context.
     if let Some(error) = first_entry_io_error {
         return Err(error);
     }
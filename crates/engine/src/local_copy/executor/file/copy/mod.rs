@@ -16,7 +16,8 @@ use std::time::Duration;
 use logging::debug_log;
 
 use crate::local_copy::{
-    CopyContext, LocalCopyAction, LocalCopyError, LocalCopyMetadata, LocalCopyRecord,
+    CopyContext, LocalCopyAction, LocalCopyError, LocalCopyFileInfo, LocalCopyMetadata,
+    LocalCopyRecord, is_device, is_fifo, map_metadata_error,
 };
 
 #[cfg(test)]
@@ -29,13 +30,58 @@ use transfer::{TransferFlags, execute_transfer};
 /// Returns `Ok(true)` when the file was processed (transferred, matched, or
 /// otherwise kept in the destination).  Returns `Ok(false)` when the file
 /// was silently skipped due to size-based filters (`--min-size` /
-/// `--max-size`), which is relevant for `--prune-empty-dirs` accounting.
+/// `--max-size`) or vetoed by a [`LocalCopyFileHooks`] filter, which is
+/// relevant for `--prune-empty-dirs` accounting.
+///
+/// [`LocalCopyFileHooks`]: crate::local_copy::LocalCopyFileHooks
 pub(crate) fn copy_file(
     context: &mut CopyContext,
     source: &Path,
     destination: &Path,
     metadata: &fs::Metadata,
     relative: Option<&Path>,
+) -> Result<bool, LocalCopyError> {
+    if context.options().file_hooks().is_empty() {
+        return copy_file_impl(context, source, destination, metadata, relative);
+    }
+
+    let hooks = context.options().file_hooks().clone();
+    let record_path = record_path_for(source, destination, relative);
+    let info = LocalCopyFileInfo::new(&record_path, source, destination, metadata);
+    if !hooks.allows(&info) {
+        debug_log!(
+            Send,
+            3,
+            "copy_file {} vetoed by hook",
+            record_path.display()
+        );
+        return Ok(false);
+    }
+    hooks.notify_start(&info);
+    let result = copy_file_impl(context, source, destination, metadata, relative);
+    hooks.notify_done(&info, result.as_ref().err());
+    result
+}
+
+/// Returns the path reported for `source` in records and hooks.
+fn record_path_for(source: &Path, destination: &Path, relative: Option<&Path>) -> PathBuf {
+    relative
+        .map(Path::to_path_buf)
+        .or_else(|| source.file_name().map(PathBuf::from))
+        .unwrap_or_else(|| {
+            destination
+                .file_name()
+                .map(PathBuf::from)
+                .unwrap_or_default()
+        })
+}
+
+fn copy_file_impl(
+    context: &mut CopyContext,
+    source: &Path,
+    destination: &Path,
+    metadata: &fs::Metadata,
+    relative: Option<&Path>,
 ) -> Result<bool, LocalCopyError> {
     context.enforce_timeout()?;
     let mut metadata_options = context.metadata_options();
@@ -47,15 +93,7 @@ pub(crate) fn copy_file(
     #[cfg(all(any(unix, windows), feature = "acl"))]
     let preserve_acls = context.acls_enabled();
 
-    let record_path = relative
-        .map(Path::to_path_buf)
-        .or_else(|| source.file_name().map(PathBuf::from))
-        .unwrap_or_else(|| {
-            destination
-                .file_name()
-                .map(PathBuf::from)
-                .unwrap_or_default()
-        });
+    let record_path = record_path_for(source, destination, relative);
     // upstream: flist.c:1419-1424 - `--copy-devices` streams a device as a
     // regular file, so its readable length (not the zero stat size) drives the
     // size checks, stats, and the copy byte count.
@@ -123,6 +161,19 @@ pub(crate) fn copy_file(
         }
     }
 
+    if let Some(existing) = existing_metadata.as_ref()
+        && (is_fifo(existing.file_type()) || is_device(existing.file_type()))
+    {
+        // upstream: generator.c:recv_generator() - any non-regular destination
+        // is removed with delete_item(..., DEL_FOR_FILE) before a regular file
+        // is received; only a directory needs DEL_RECURSE, so no --force is
+        // required. Quick-checking or reading a FIFO or device as the basis
+        // would block on the pipe or compare device contents instead.
+        context.force_remove_destination(destination, relative, existing)?;
+        destination_previously_existed = false;
+        existing_metadata = None;
+    }
+
     if context.existing_only_enabled() && existing_metadata.is_none() {
         context.summary_mut().record_regular_file_skipped_missing();
         let metadata_snapshot = LocalCopyMetadata::from_metadata(metadata, None)
@@ -232,7 +283,18 @@ pub(crate) fn copy_file(
     // permissions for new files vs keeping existing permissions for updates.
     metadata_options = metadata_options.with_destination_is_new(!destination_previously_existed);
 
-    let link_outcome = links::process_links(
+    // upstream: rsync-patches fileflags.diff - with --force-change the
+    // receiver calls make_mutable() on a protected destination before
+    // replacing it, and restores the flags once the update is done.
+    let force_change = context.options().force_change();
+    let cleared_flags = match existing_metadata.as_ref() {
+        Some(existing) if existing.file_type().is_file() && !force_change.is_none() => {
+            ::metadata::make_mutable(destination, force_change).map_err(map_metadata_error)?
+        }
+        _ => None,
+    };
+
+    let link_outcome = match links::process_links(
         context,
         source,
         destination,
@@ -251,9 +313,16 @@ pub(crate) fn copy_file(
         preserve_xattrs,
         #[cfg(all(any(unix, windows), feature = "acl"))]
         preserve_acls,
-    )?;
+    ) {
+        Ok(outcome) => outcome,
+        Err(error) => {
+            abandon_force_change(destination, cleared_flags);
+            return Err(error);
+        }
+    };
 
     if link_outcome.completed {
+        finish_force_change(context, source, destination, cleared_flags)?;
         return Ok(true);
     }
 
@@ -274,7 +343,7 @@ pub(crate) fn copy_file(
         preserve_acls,
     };
 
-    execute_transfer(
+    if let Err(error) = execute_transfer(
         context,
         source,
         destination,
@@ -289,10 +358,44 @@ pub(crate) fn copy_file(
         mode,
         link_outcome.copy_source_override,
         link_outcome.reference_basis,
-    )?;
+    ) {
+        abandon_force_change(destination, cleared_flags);
+        return Err(error);
+    }
+    finish_force_change(context, source, destination, cleared_flags)?;
     Ok(true)
 }
 
+/// Puts protective flags back after `--force-change` let a file be updated.
+///
+/// Under `--fileflags` the destination takes the source's flags; otherwise it
+/// gets back the flags it had before [`::metadata::make_mutable`] cleared them.
+fn finish_force_change(
+    context: &CopyContext,
+    source: &Path,
+    destination: &Path,
+    cleared_flags: Option<u32>,
+) -> Result<(), LocalCopyError> {
+    let Some(previous) = cleared_flags else {
+        return Ok(());
+    };
+    if context.options().preserve_fileflags() {
+        ::metadata::sync_file_flags(source, destination)
+    } else {
+        ::metadata::undo_make_mutable(destination, previous)
+    }
+    .map_err(map_metadata_error)
+}
+
+/// Restores the flags of a destination whose update failed.
+///
+/// Best effort: the transfer error is what gets reported.
+fn abandon_force_change(destination: &Path, cleared_flags: Option<u32>) {
+    if let Some(previous) = cleared_flags {
+        let _ = ::metadata::undo_make_mutable(destination, previous);
+    }
+}
+
 /// Records a `--max-size` / `--min-size` skip as a client event.
 ///
 /// Mirrors the `SkippedMissingDestination` recording path so the notice renders
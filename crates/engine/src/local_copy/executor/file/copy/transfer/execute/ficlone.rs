@@ -9,6 +9,10 @@
 //! a single concern; the parent `mod.rs` already gates this module on
 //! `target_os = "linux"`, so no inner `#![cfg(...)]` is needed.
 //!
+//! A `--copy-dest` reconstruction (or a `--link-dest` basis whose attributes
+//! differ) clones the matching basis file instead of the source, so the new
+//! destination shares extents with the basis as well.
+//!
 //! Cross-filesystem, unsupported-filesystem, and read-only-fs failures are
 //! mapped to `Ok(false)` so the caller falls through to the generic copy
 //! path. Any I/O error after a successful reflink propagates as
@@ -38,11 +42,15 @@ use super::super::finalize::finalize_guard_and_metadata;
 /// staging directories, or xattr filter rules must fall through to the
 /// regular copy path. The destination must also be a fresh file - FICLONE
 /// fails if it already exists.
+///
+/// Unlike the macOS gate, a copy source override is accepted: a `--copy-dest`
+/// or `--link-dest` basis only reaches the executor when its data already
+/// matches the source (match level 2 or 3), so cloning the basis yields the
+/// same bytes the read/write loop would copy out of it.
 pub(super) fn eligible(
     context: &CopyContext,
     existing_metadata: Option<&fs::Metadata>,
     flags: TransferFlags,
-    copy_source_override_present: bool,
 ) -> bool {
     let TransferFlags {
         whole_file_enabled,
@@ -59,7 +67,6 @@ pub(super) fn eligible(
         && !partial_enabled
         && !use_sparse_writes
         && !compress_enabled
-        && !copy_source_override_present
         && !context.has_bandwidth_limiter()
         && !context.delay_updates_enabled()
         && context.temp_directory_path().is_none();
@@ -94,10 +101,17 @@ pub(super) fn eligible(
 /// `rustix`). FICLONE failures - cross-device (`EXDEV`), unsupported
 /// filesystem (`EOPNOTSUPP`), permission, etc. - are mapped to
 /// `Ok(false)` so the caller falls through to the generic read/write loop.
+///
+/// `copy_source_override` names the file whose extents are cloned when it
+/// differs from `source`; `reference_basis` marks that clone as a
+/// `--copy-dest` reconstruction, itemized against the basis exactly as the
+/// read/write loop itemizes it.
 #[allow(clippy::too_many_arguments)]
 pub(super) fn try_clone(
     context: &mut CopyContext,
     source: &Path,
+    copy_source_override: Option<&Path>,
+    reference_basis: Option<&Path>,
     destination: &Path,
     metadata: &fs::Metadata,
     metadata_options: MetadataOptions,
@@ -111,6 +125,18 @@ pub(super) fn try_clone(
 ) -> Result<bool, LocalCopyError> {
     let file_size = metadata.len();
 
+    // A basis that vanished or cannot be stat'ed is left to the read/write
+    // loop, which reports the open failure with upstream's wording.
+    let override_metadata = match copy_source_override {
+        Some(path) => match fs::metadata(path) {
+            Ok(meta) => Some(meta),
+            Err(_) => return Ok(false),
+        },
+        None => None,
+    };
+    let clone_source = copy_source_override.unwrap_or(source);
+    let clone_source_metadata = override_metadata.as_ref().unwrap_or(metadata);
+
     // REFLINK-2: skip the FICLONE ioctl when source and destination live on
     // different filesystems. ioctl(FICLONE) only reflinks within one
     // filesystem and fails with EXDEV across mounts - but not before
@@ -121,11 +147,13 @@ pub(super) fn try_clone(
     // one directory do not each re-statx the parent. When the device ids
     // cannot be determined the helper returns None and we fall through to let
     // try_ficlone decide.
-    if context.same_filesystem_as_source(source, metadata, destination) == Some(false) {
+    if context.same_filesystem_as_source(clone_source, clone_source_metadata, destination)
+        == Some(false)
+    {
         return Ok(false);
     }
 
-    if fast_io::try_ficlone(source, destination).is_err() {
+    if fast_io::try_ficlone(clone_source, destination).is_err() {
         let _ = std::fs::remove_file(destination);
         return Ok(false);
     }
@@ -159,27 +187,40 @@ pub(super) fn try_clone(
     let metadata_snapshot = LocalCopyMetadata::from_metadata(metadata, None)
         .virtualize_fake_super(source, metadata_options.fake_super_enabled());
     let total_bytes = Some(metadata_snapshot.len());
+    // upstream: generator.c:1039 - a reference-basis reconstruction itemizes
+    // against the basis and never sets ITEM_IS_NEW, matching the read/write
+    // loop's handling of `reference_basis`.
+    let is_reference_copy = reference_basis.is_some();
     let change_set = LocalCopyChangeSet::for_file(
         metadata,
-        existing_metadata,
+        if is_reference_copy {
+            override_metadata.as_ref()
+        } else {
+            existing_metadata
+        },
         &metadata_options,
-        destination_previously_existed,
-        true,
+        is_reference_copy || destination_previously_existed,
+        !is_reference_copy,
         flags.xattrs_enabled(),
         flags.acls_enabled(),
         context.options().modify_window(),
     );
+    let action = if is_reference_copy {
+        LocalCopyAction::ReferenceCopied
+    } else {
+        LocalCopyAction::DataCopied
+    };
     context.record(
         LocalCopyRecord::new(
             record_path.to_path_buf(),
-            LocalCopyAction::DataCopied,
+            action,
             file_size,
             total_bytes,
             start.elapsed(),
             Some(metadata_snapshot),
         )
         .with_change_set(change_set)
-        .with_creation(true),
+        .with_creation(!is_reference_copy),
     );
 
     // FICLONE preserves source metadata verbatim. Normalize so finalize
@@ -299,19 +299,17 @@ pub(in crate::local_copy) fn execute_transfer(
     }
 
     // Fast path: Linux FICLONE reflink for new whole-file copies on Btrfs,
-    // XFS (reflink enabled), and bcachefs. Cross-filesystem / unsupported-fs
-    // failures degrade to the generic read/write loop transparently.
+    // XFS (reflink enabled), and bcachefs. A `--copy-dest` basis is cloned in
+    // place of the source. Cross-filesystem / unsupported-fs failures degrade
+    // to the generic read/write loop transparently.
     #[cfg(target_os = "linux")]
     if device_as_file_size.is_none()
-        && ficlone::eligible(
-            context,
-            existing_metadata,
-            flags,
-            copy_source_override.is_some(),
-        )
+        && ficlone::eligible(context, existing_metadata, flags)
         && ficlone::try_clone(
             context,
             source,
+            copy_source_override.as_deref(),
+            reference_basis.as_deref(),
             destination,
             metadata,
             metadata_options.clone(),
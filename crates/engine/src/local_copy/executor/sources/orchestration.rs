@@ -61,10 +61,17 @@ pub(crate) fn copy_sources(
             )
         })?;
 
+    // Operands of PATH_MAX bytes or more are reached through anchored
+    // aliases, which stay open until the flush below has finished. Relative
+    // names beneath the operands are bounded the same way upstream's
+    // MAXPATHLEN bounds them after util1.c:change_dir().
+    let anchored_plan = plan.reach_long_operands()?;
+    let plan = anchored_plan.as_ref().unwrap_or(plan);
+
     // upstream: main.c:1763 `starttime = time(NULL)` - the transfer rate span
     // is measured between two whole-second time_t marks, not a fractional clock.
     let run_start_secs = whole_unix_seconds();
-    let destination_root = plan.destination_spec().path().to_path_buf();
+    let destination_root = plan.destination_spec().io_path().to_path_buf();
     let mut context = CopyContext::new(mode, options, handler, destination_root);
     context.set_multi_source(plan.sources().len() > 1);
 
@@ -87,7 +94,7 @@ pub(crate) fn copy_sources(
         let context = &mut context;
         (|| -> Result<(), LocalCopyError> {
             let multiple_sources = plan.sources().len() > 1;
-            let destination_path = plan.destination_spec().path();
+            let destination_path = plan.destination_spec().io_path();
             let mut destination_state = query_destination_state(destination_path)?;
             if context.keep_dirlinks_enabled() && destination_state.symlink_to_dir {
                 destination_state.is_dir = true;
@@ -140,8 +147,8 @@ pub(crate) fn copy_sources(
                 && !destination_state.is_dir
                 && let Some(source) = plan.sources().first()
                 && !source.copy_contents()
-                && fs::symlink_metadata(source.path()).is_ok_and(|meta| meta.is_dir())
-                && fs::read_dir(source.path()).is_ok_and(|mut entries| entries.next().is_some())
+                && fs::symlink_metadata(source.io_path()).is_ok_and(|meta| meta.is_dir())
+                && fs::read_dir(source.io_path()).is_ok_and(|mut entries| entries.next().is_some())
             {
                 // A pre-existing non-directory destination blocks the mkdir.
                 // When force replacements are enabled, remove it first (mirrors
@@ -243,9 +250,12 @@ pub(crate) fn copy_sources(
             // empty queues drain cleanly without side effects).
             if context.mode().is_dry_run() && context.options().get_batch_writer().is_some() {
                 if let Some(error) = first_io_error {
-                    return Err(error);
+                    if !(error.is_vanished_error() && context.entry_transfer_failed()) {
+                        return Err(error);
+                    }
                 }
-                if context.iconv_conversion_error_occurred()
+                if context.entry_transfer_failed()
+                    || context.iconv_conversion_error_occurred()
                     || context.unsupported_operation_skipped()
                 {
                     return Err(LocalCopyError::partial_transfer());
@@ -255,8 +265,19 @@ pub(crate) fn copy_sources(
 
             flush_deferred_operations(context)?;
 
+            // upstream: cleanup.c:_exit_cleanup() - any io_error bit other than
+            // IOERR_VANISHED yields RERR_PARTIAL before RERR_VANISHED, so an
+            // entry failure reported inside a directory makes the run exit
+            // RERR_PARTIAL (23) even when a source operand also vanished.
             if let Some(error) = first_io_error {
-                return Err(error);
+                if !(error.is_vanished_error() && context.entry_transfer_failed()) {
+                    return Err(error);
+                }
+            }
+            // Entries that failed inside a directory were each reported where
+            // they happened; finish with the "see previous errors" summary.
+            if context.entry_transfer_failed() {
+                return Err(LocalCopyError::partial_transfer());
             }
             // A platform-unsupported entry (a Windows unprivileged file symlink)
             // was skipped with a warning; finish RERR_PARTIAL (23) like upstream
@@ -324,7 +345,7 @@ fn process_single_source(
     context.set_safety_depth_offset(0);
     context.enforce_timeout()?;
 
-    let source_path = source.path();
+    let source_path = source.io_path();
     let metadata_start = Instant::now();
 
     let (relative_root, relative_parent) = compute_relative_paths(context, source);
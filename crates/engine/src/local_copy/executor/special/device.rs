@@ -363,8 +363,11 @@ pub(crate) fn copy_device(
         // --fake-super is active (mirrors upstream
         // syscall.c:do_mknod()'s am_root < 0 branch).
         let fake_super = metadata_options.fake_super_enabled();
-        create_device_node_with_fake_super(destination, metadata, fake_super)
-            .map_err(map_metadata_error)?;
+        if let Err(error) = create_device_node_with_fake_super(destination, metadata, fake_super) {
+            context.record_uncreatable_special(destination, error.source_error());
+            context.register_progress();
+            return Ok(());
+        }
 
         context.register_created_path(
             destination,
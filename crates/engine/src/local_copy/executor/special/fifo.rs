@@ -349,8 +349,11 @@ pub(crate) fn copy_fifo(
     #[cfg(unix)]
     {
         let fake_super = metadata_options.fake_super_enabled();
-        create_fifo_with_fake_super(destination, metadata, fake_super)
-            .map_err(map_metadata_error)?;
+        if let Err(error) = create_fifo_with_fake_super(destination, metadata, fake_super) {
+            context.record_uncreatable_special(destination, error.source_error());
+            context.register_progress();
+            return Ok(());
+        }
     }
     #[cfg(not(unix))]
     {
@@ -0,0 +1,255 @@
+//! Per-file callbacks for programs embedding the transfer engines.
+//!
+//! [`LocalCopyFileHooks`] lets a caller observe each regular file as the
+//! generator reaches it and veto individual files without writing a filter
+//! rule. The veto is consulted before any destination mutation, so a rejected
+//! file is left exactly as it was, the same as a file excluded by
+//! `--max-size`.
+//!
+//! The hooks are local-only: they run in this process's generator, which is
+//! the local copy executor or, on a pull over a remote shell or daemon, the
+//! local receiver. A push runs the generator on the remote host, out of the
+//! callbacks' reach, so the client refuses hooks for pushes.
+//!
+//! Only regular files (and devices streamed by `--copy-devices`) reach the
+//! hooks; directories, symlinks and specials are handled without them.
+
+use std::fmt;
+use std::fs;
+use std::path::Path;
+use std::sync::Arc;
+
+use super::LocalCopyError;
+
+type StartHook = dyn Fn(&LocalCopyFileInfo<'_>) + Send + Sync;
+type DoneHook = dyn Fn(&LocalCopyFileInfo<'_>, Option<&LocalCopyError>) + Send + Sync;
+type FilterHook = dyn Fn(&LocalCopyFileInfo<'_>) -> bool + Send + Sync;
+
+/// Description of the file a hook is invoked for.
+#[derive(Clone, Copy, Debug)]
+pub struct LocalCopyFileInfo<'a> {
+    relative_path: &'a Path,
+    source: &'a Path,
+    destination: &'a Path,
+    size: u64,
+    metadata: Option<&'a fs::Metadata>,
+}
+
+impl<'a> LocalCopyFileInfo<'a> {
+    pub(crate) const fn new(
+        relative_path: &'a Path,
+        source: &'a Path,
+        destination: &'a Path,
+        metadata: &'a fs::Metadata,
+    ) -> Self {
+        Self {
+            relative_path,
+            source,
+            destination,
+            size: metadata.len(),
+            metadata: Some(metadata),
+        }
+    }
+
+    /// Describes a file a pull receives from a remote sender.
+    ///
+    /// The source lives on the remote host, so [`source`](Self::source) is
+    /// the name from the sender's file list and no metadata is available.
+    #[must_use]
+    pub const fn received(relative_path: &'a Path, destination: &'a Path, size: u64) -> Self {
+        Self {
+            relative_path,
+            source: relative_path,
+            destination,
+            size,
+            metadata: None,
+        }
+    }
+
+    /// Returns the path relative to the transfer root, as shown by `-v`.
+    #[must_use]
+    pub const fn relative_path(&self) -> &'a Path {
+        self.relative_path
+    }
+
+    /// Returns the source path being read.
+    ///
+    /// On a pull this is the name in the remote sender's file list.
+    #[must_use]
+    pub const fn source(&self) -> &'a Path {
+        self.source
+    }
+
+    /// Returns the destination path that will be written.
+    #[must_use]
+    pub const fn destination(&self) -> &'a Path {
+        self.destination
+    }
+
+    /// Returns the source file's size in bytes.
+    #[must_use]
+    pub const fn size(&self) -> u64 {
+        self.size
+    }
+
+    /// Returns the source metadata captured during traversal, or `None` on a
+    /// pull, where the source is on the remote host.
+    #[must_use]
+    pub const fn metadata(&self) -> Option<&'a fs::Metadata> {
+        self.metadata
+    }
+}
+
+/// Callbacks invoked for each regular file processed by the local generator.
+///
+/// The hooks see local copies and pulls only; see the [module
+/// documentation](self) for why a push cannot run them. All hooks are optional. They receive shared references and must be
+/// `Send + Sync`; use interior mutability to collect state. Equality compares
+/// the installed callbacks by identity so configurations holding hooks stay
+/// comparable.
+#[derive(Clone, Default)]
+pub struct LocalCopyFileHooks {
+    on_file_start: Option<Arc<StartHook>>,
+    on_file_done: Option<Arc<DoneHook>>,
+    should_transfer: Option<Arc<FilterHook>>,
+}
+
+impl LocalCopyFileHooks {
+    /// Creates an empty hook set.
+    #[must_use]
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Installs a callback run just before a file is transferred or checked.
+    #[must_use]
+    pub fn on_file_start<F>(mut self, hook: F) -> Self
+    where
+        F: Fn(&LocalCopyFileInfo<'_>) + Send + Sync + 'static,
+    {
+        self.on_file_start = Some(Arc::new(hook));
+        self
+    }
+
+    /// Installs a callback run once a file has been handled.
+    ///
+    /// The error is `Some` when processing the file failed; the same error is
+    /// then returned from the copy. On a pull the callback runs once the
+    /// file's data has arrived, before it is committed to disk.
+    #[must_use]
+    pub fn on_file_done<F>(mut self, hook: F) -> Self
+    where
+        F: Fn(&LocalCopyFileInfo<'_>, Option<&LocalCopyError>) + Send + Sync + 'static,
+    {
+        self.on_file_done = Some(Arc::new(hook));
+        self
+    }
+
+    /// Installs a filter consulted before each file; returning `false` skips
+    /// the file without touching the destination.
+    ///
+    /// Skipped files get neither a start nor a done callback.
+    #[must_use]
+    pub fn should_transfer<F>(mut self, hook: F) -> Self
+    where
+        F: Fn(&LocalCopyFileInfo<'_>) -> bool + Send + Sync + 'static,
+    {
+        self.should_transfer = Some(Arc::new(hook));
+        self
+    }
+
+    /// Reports whether no callback is installed.
+    #[must_use]
+    pub const fn is_empty(&self) -> bool {
+        self.on_file_start.is_none()
+            && self.on_file_done.is_none()
+            && self.should_transfer.is_none()
+    }
+
+    /// Reports whether a [`should_transfer`](Self::should_transfer) filter is
+    /// installed.
+    #[must_use]
+    pub const fn has_filter(&self) -> bool {
+        self.should_transfer.is_some()
+    }
+
+    /// Runs the filter for `file`; `true` when none is installed.
+    pub fn allows(&self, file: &LocalCopyFileInfo<'_>) -> bool {
+        self.should_transfer.as_ref().is_none_or(|hook| hook(file))
+    }
+
+    /// Runs the start callback for `file`, if any.
+    pub fn notify_start(&self, file: &LocalCopyFileInfo<'_>) {
+        if let Some(hook) = &self.on_file_start {
+            hook(file);
+        }
+    }
+
+    /// Runs the done callback for `file`, if any.
+    pub fn notify_done(&self, file: &LocalCopyFileInfo<'_>, error: Option<&LocalCopyError>) {
+        if let Some(hook) = &self.on_file_done {
+            hook(file, error);
+        }
+    }
+}
+
+fn same_hook<T: ?Sized>(a: &Option<Arc<T>>, b: &Option<Arc<T>>) -> bool {
+    match (a, b) {
+        (Some(a), Some(b)) => Arc::ptr_eq(a, b),
+        (None, None) => true,
+        _ => false,
+    }
+}
+
+impl PartialEq for LocalCopyFileHooks {
+    fn eq(&self, other: &Self) -> bool {
+        same_hook(&self.on_file_start, &other.on_file_start)
+            && same_hook(&self.on_file_done, &other.on_file_done)
+            && same_hook(&self.should_transfer, &other.should_transfer)
+    }
+}
+
+impl Eq for LocalCopyFileHooks {}
+
+impl fmt::Debug for LocalCopyFileHooks {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.debug_struct("LocalCopyFileHooks")
+            .field("on_file_start", &self.on_file_start.is_some())
+            .field("on_file_done", &self.on_file_done.is_some())
+            .field("should_transfer", &self.should_transfer.is_some())
+            .finish()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn empty_hooks_allow_everything() {
+        let hooks = LocalCopyFileHooks::new();
+        assert!(hooks.is_empty());
+        let metadata = fs::metadata(".").expect("metadata");
+        let info =
+            LocalCopyFileInfo::new(Path::new("a"), Path::new("a"), Path::new("b"), &metadata);
+        assert!(hooks.allows(&info));
+        assert_eq!(info.size(), metadata.len());
+        assert!(info.metadata().is_some());
+    }
+
+    #[test]
+    fn received_info_has_no_source_metadata() {
+        let info = LocalCopyFileInfo::received(Path::new("d/a"), Path::new("/dest/d/a"), 7);
+        assert_eq!(info.source(), Path::new("d/a"));
+        assert_eq!(info.size(), 7);
+        assert!(info.metadata().is_none());
+    }
+
+    #[test]
+    fn equality_tracks_installed_callbacks() {
+        let hooks = LocalCopyFileHooks::new().should_transfer(|_| true);
+        assert_eq!(hooks, hooks.clone());
+        assert_ne!(hooks, LocalCopyFileHooks::new());
+        assert_ne!(hooks, LocalCopyFileHooks::new().should_transfer(|_| true));
+    }
+}
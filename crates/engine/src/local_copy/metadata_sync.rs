@@ -1,18 +1,10 @@
-//! Helpers for synchronizing extended attributes and ACLs.
+//! Helpers for synchronizing extended attributes, ACLs and file flags.
 
 use super::LocalCopyError;
 use ::metadata::MetadataError;
 
-#[cfg(any(
-    all(unix, any(feature = "acl", feature = "xattr")),
-    all(windows, feature = "acl")
-))]
 use std::path::Path;
 
-#[cfg(any(
-    all(unix, any(feature = "acl", feature = "xattr")),
-    all(windows, feature = "acl")
-))]
 use super::LocalCopyExecution;
 
 #[cfg(all(unix, feature = "xattr"))]
@@ -122,6 +114,28 @@ pub(crate) fn sync_nfsv4_acls_if_requested(
     Ok(())
 }
 
+/// Copies file flags (`chattr`/`chflags`) from source to destination if requested.
+///
+/// No-op when `preserve_fileflags` is false or in dry-run mode. Privileged
+/// flags the receiver may not change and filesystems without flag support are
+/// skipped by [`::metadata::sync_file_flags`].
+///
+/// # Errors
+///
+/// Returns [`LocalCopyError`] if the flags cannot be read or applied.
+// upstream: rsync-patches fileflags.diff - set_file_attrs() applies F_FFLAGS
+pub(crate) fn sync_file_flags_if_requested(
+    preserve_fileflags: bool,
+    mode: LocalCopyExecution,
+    source: &Path,
+    destination: &Path,
+) -> Result<(), LocalCopyError> {
+    if preserve_fileflags && !mode.is_dry_run() {
+        ::metadata::sync_file_flags(source, destination).map_err(map_metadata_error)?;
+    }
+    Ok(())
+}
+
 /// Stores the effective fake-super `user.rsync.%stat` xattr on the destination.
 ///
 /// Under `--fake-super` the source may be a placeholder whose real
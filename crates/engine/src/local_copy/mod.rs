@@ -70,6 +70,7 @@ mod error;
 mod executor;
 mod filter_program;
 mod hard_links;
+mod hooks;
 mod metadata_sync;
 mod operands;
 mod options;
@@ -99,6 +100,8 @@ pub use options::{
     ReferenceDirectoryKind,
 };
 
+pub use hooks::{LocalCopyFileHooks, LocalCopyFileInfo};
+
 pub use error::{LocalCopyArgumentError, LocalCopyError, LocalCopyErrorKind, upstream_io_error};
 
 #[cfg(test)]
@@ -121,16 +124,18 @@ pub(crate) use dir_merge::{
 
 pub(crate) use executor::*;
 pub use executor::{
-    DestinationWriteGuard, PartialFileManager, PartialMode, SparseDetectStrategy, SparseDetector,
-    SparseReader, SparseRegion, compute_backup_path, remove_existing_destination,
-    remove_incomplete_destination, trace_make_backup_copy, trace_make_backup_device,
-    trace_make_backup_hlink, trace_make_backup_rename, trace_make_backup_symlink,
+    DEFAULT_CHECKSUM_XATTR, DestinationWriteGuard, PartialFileManager, PartialMode,
+    SparseDetectStrategy, SparseDetector, SparseReader, SparseRegion, compute_backup_path,
+    read_checksum_xattr, remove_existing_destination, remove_incomplete_destination,
+    trace_make_backup_copy, trace_make_backup_device, trace_make_backup_hlink,
+    trace_make_backup_rename, trace_make_backup_symlink,
 };
 
 pub(crate) use hard_links::HardLinkTracker;
 pub use hard_links::{HardlinkApplyResult, HardlinkApplyTracker};
 
 pub(crate) use metadata_sync::map_metadata_error;
+pub(crate) use metadata_sync::sync_file_flags_if_requested;
 
 #[cfg(all(any(unix, windows), feature = "acl"))]
 pub(crate) use metadata_sync::sync_acls_if_requested;
@@ -3,6 +3,8 @@
 use std::ffi::{OsStr, OsString};
 use std::path::{Component, Path, PathBuf};
 
+use fast_io::ReachablePath;
+
 use super::{LocalCopyArgumentError, LocalCopyError};
 
 /// Source operand within a [`LocalCopyPlan`](super::LocalCopyPlan).
@@ -12,6 +14,7 @@ pub(crate) struct SourceSpec {
     copy_contents: bool,
     relative_prefix_components: Option<usize>,
     has_dot_dir_marker: bool,
+    reachable: Option<ReachablePath>,
 }
 
 impl SourceSpec {
@@ -36,6 +39,7 @@ impl SourceSpec {
             copy_contents,
             relative_prefix_components: detect_relative_prefix_components(operand.as_os_str()),
             has_dot_dir_marker,
+            reachable: None,
         })
     }
 
@@ -111,6 +115,18 @@ impl SourceSpec {
         &self.path
     }
 
+    /// Path handed to filesystem calls: the anchored alias recorded by
+    /// [`Self::reach_long_path`], otherwise [`Self::path`].
+    pub(crate) fn io_path(&self) -> &Path {
+        io_path(&self.path, self.reachable.as_ref())
+    }
+
+    /// Anchors the operand when it is `PATH_MAX` bytes or longer, returning
+    /// whether an alias was recorded.
+    pub(crate) fn reach_long_path(&mut self) -> Result<bool, LocalCopyError> {
+        reach_long_path(&self.path, &mut self.reachable)
+    }
+
     pub(crate) const fn copy_contents(&self) -> bool {
         self.copy_contents
     }
@@ -121,6 +137,7 @@ impl SourceSpec {
 pub(crate) struct DestinationSpec {
     path: PathBuf,
     force_directory: bool,
+    reachable: Option<ReachablePath>,
 }
 
 impl DestinationSpec {
@@ -129,6 +146,7 @@ impl DestinationSpec {
         Self {
             path: PathBuf::from(operand),
             force_directory,
+            reachable: None,
         }
     }
 
@@ -136,11 +154,46 @@ impl DestinationSpec {
         &self.path
     }
 
+    /// Path handed to filesystem calls; see [`SourceSpec::io_path`].
+    pub(crate) fn io_path(&self) -> &Path {
+        io_path(&self.path, self.reachable.as_ref())
+    }
+
+    /// Anchors the operand when it is `PATH_MAX` bytes or longer; see
+    /// [`SourceSpec::reach_long_path`].
+    pub(crate) fn reach_long_path(&mut self) -> Result<bool, LocalCopyError> {
+        reach_long_path(&self.path, &mut self.reachable)
+    }
+
     pub(crate) const fn force_directory(&self) -> bool {
         self.force_directory
     }
 }
 
+fn io_path<'a>(path: &'a Path, reachable: Option<&'a ReachablePath>) -> &'a Path {
+    reachable.map_or(path, ReachablePath::path)
+}
+
+/// Records an anchored alias for an operand the kernel would reject with
+/// `ENAMETOOLONG`.
+///
+/// Upstream never hands the kernel the long path either: `util1.c:change_dir()`
+/// moves into the transfer root and later calls use names relative to it. The
+/// alias resolves through an open directory in the same way, without changing
+/// the process working directory.
+fn reach_long_path(
+    path: &Path,
+    reachable: &mut Option<ReachablePath>,
+) -> Result<bool, LocalCopyError> {
+    let anchored = fast_io::reach_long_path(path)
+        .map_err(|error| LocalCopyError::io("resolve long path", path, error))?;
+    if !anchored.is_anchored() {
+        return Ok(false);
+    }
+    *reachable = Some(anchored);
+    Ok(true)
+}
+
 /// Returns `true` when `operand` contains an explicit `./` (or `\./`) dot-dir
 /// component used to anchor the `--relative` source root. Upstream tracks the
 /// same signal via the `implied_dot_dir` flag in `flist.c`.
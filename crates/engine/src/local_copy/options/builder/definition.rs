@@ -6,7 +6,7 @@ use std::path::PathBuf;
 use std::sync::{Arc, Mutex};
 use std::time::{Duration, SystemTime};
 
-use ::metadata::{ChmodModifiers, CopyAsIds, GroupMapping, ModifyWindow, UserMapping};
+use ::metadata::{ChmodModifiers, CopyAsIds, ForceChange, GroupMapping, ModifyWindow, UserMapping};
 use compress::algorithm::CompressionAlgorithm;
 use compress::zlib::CompressionLevel;
 use fast_io::{DefaultPlatformCopy, PlatformCopy};
@@ -16,6 +16,7 @@ use protocol::iconv::FilenameConverter;
 use crate::batch::BatchWriter;
 use crate::local_copy::executor::{DEFAULT_XXH64_DEDUP_SIZE_LIMIT, SparseDetectStrategy};
 use crate::local_copy::filter_program::FilterProgram;
+use crate::local_copy::hooks::LocalCopyFileHooks;
 use crate::local_copy::options::types::{DeleteTiming, LinkDestEntry, ReferenceDirectory};
 use crate::local_copy::skip_compress::SkipCompressList;
 use crate::signature::SignatureAlgorithm;
@@ -83,6 +84,8 @@ pub struct LocalCopyOptionsBuilder {
     pub(super) preserve_times: bool,
     pub(super) preserve_atimes: bool,
     pub(super) preserve_crtimes: bool,
+    pub(super) preserve_fileflags: bool,
+    pub(super) force_change: ForceChange,
     pub(super) omit_link_times: bool,
     pub(super) owner_override: Option<u32>,
     pub(super) group_override: Option<u32>,
@@ -168,6 +171,10 @@ pub struct LocalCopyOptionsBuilder {
     pub(super) log_file_format: Option<String>,
 
     pub(super) platform_copy: Arc<dyn PlatformCopy>,
+
+    pub(super) file_hooks: LocalCopyFileHooks,
+
+    pub(super) copy_buffer_size: Option<usize>,
 }
 
 impl Default for LocalCopyOptionsBuilder {
@@ -218,6 +225,8 @@ impl LocalCopyOptionsBuilder {
             preserve_times: false,
             preserve_atimes: false,
             preserve_crtimes: false,
+            preserve_fileflags: false,
+            force_change: ForceChange::NONE,
             owner_override: None,
             group_override: None,
             copy_as: None,
@@ -287,6 +296,8 @@ impl LocalCopyOptionsBuilder {
             log_file: None,
             log_file_format: None,
             platform_copy: Arc::new(DefaultPlatformCopy::new()),
+            file_hooks: LocalCopyFileHooks::new(),
+            copy_buffer_size: None,
         }
     }
 
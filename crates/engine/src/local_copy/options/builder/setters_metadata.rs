@@ -1,6 +1,6 @@
 //! Setter methods for metadata preservation, extended attributes, and modifier options.
 
-use ::metadata::{ChmodModifiers, CopyAsIds, GroupMapping, UserMapping};
+use ::metadata::{ChmodModifiers, CopyAsIds, ForceChange, GroupMapping, UserMapping};
 
 use super::LocalCopyOptionsBuilder;
 
@@ -101,6 +101,22 @@ impl LocalCopyOptionsBuilder {
         self
     }
 
+    /// Enables file flag preservation.
+    #[must_use]
+    #[doc(alias = "--fileflags")]
+    pub fn preserve_fileflags(mut self, enabled: bool) -> Self {
+        self.preserve_fileflags = enabled;
+        self
+    }
+
+    /// Sets which immutable/append-only flags may be cleared to update a file.
+    #[must_use]
+    #[doc(alias = "--force-change")]
+    pub fn force_change(mut self, force: ForceChange) -> Self {
+        self.force_change = force;
+        self
+    }
+
     /// Enables omitting link times from preservation.
     #[must_use]
     pub fn omit_link_times(mut self, enabled: bool) -> Self {
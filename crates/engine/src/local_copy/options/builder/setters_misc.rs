@@ -10,6 +10,7 @@ use metadata::ModifyWindow;
 use super::LocalCopyOptionsBuilder;
 use crate::batch::BatchWriter;
 use crate::local_copy::executor::SparseDetectStrategy;
+use crate::local_copy::hooks::LocalCopyFileHooks;
 use crate::signature::SignatureAlgorithm;
 
 impl LocalCopyOptionsBuilder {
@@ -187,4 +188,11 @@ impl LocalCopyOptionsBuilder {
         self.platform_copy = platform_copy;
         self
     }
+
+    /// Installs per-file callbacks; see [`LocalCopyFileHooks`].
+    #[must_use]
+    pub fn file_hooks(mut self, hooks: LocalCopyFileHooks) -> Self {
+        self.file_hooks = hooks;
+        self
+    }
 }
@@ -30,6 +30,13 @@ impl LocalCopyOptionsBuilder {
         self
     }
 
+    /// Sets the size of the buffer file contents are copied through.
+    #[must_use]
+    pub fn copy_buffer_size(mut self, size: Option<usize>) -> Self {
+        self.copy_buffer_size = size;
+        self
+    }
+
     /// Enables removal of source files after successful transfer.
     #[must_use]
     pub fn remove_source_files(mut self, enabled: bool) -> Self {
@@ -142,6 +142,8 @@ impl LocalCopyOptionsBuilder {
             preserve_times: self.preserve_times,
             preserve_atimes: self.preserve_atimes,
             preserve_crtimes: self.preserve_crtimes,
+            preserve_fileflags: self.preserve_fileflags,
+            force_change: self.force_change,
             omit_link_times: self.omit_link_times,
             owner_override: self.owner_override,
             group_override: self.group_override,
@@ -211,6 +213,8 @@ impl LocalCopyOptionsBuilder {
             log_file: self.log_file,
             log_file_format: self.log_file_format,
             platform_copy: self.platform_copy,
+            file_hooks: self.file_hooks,
+            copy_buffer_size: self.copy_buffer_size,
         };
         options.apply_delay_updates_partial_dir_default();
         options
@@ -0,0 +1,40 @@
+//! Per-file hook installation for local copy options.
+//!
+//! Exposes a setter and accessor for the `file_hooks` field on
+//! [`LocalCopyOptions`]. The executor consults the hooks for every regular
+//! file it reaches; see [`LocalCopyFileHooks`].
+
+use super::types::LocalCopyOptions;
+use crate::local_copy::hooks::LocalCopyFileHooks;
+
+impl LocalCopyOptions {
+    /// Installs per-file start, done and veto callbacks.
+    #[must_use]
+    pub fn with_file_hooks(mut self, hooks: LocalCopyFileHooks) -> Self {
+        self.file_hooks = hooks;
+        self
+    }
+
+    /// Returns the installed per-file callbacks.
+    #[must_use]
+    pub const fn file_hooks(&self) -> &LocalCopyFileHooks {
+        &self.file_hooks
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn default_has_no_file_hooks() {
+        assert!(LocalCopyOptions::new().file_hooks().is_empty());
+    }
+
+    #[test]
+    fn with_file_hooks_installs_callbacks() {
+        let hooks = LocalCopyFileHooks::new().on_file_start(|_| {});
+        let options = LocalCopyOptions::new().with_file_hooks(hooks.clone());
+        assert_eq!(options.file_hooks(), &hooks);
+    }
+}
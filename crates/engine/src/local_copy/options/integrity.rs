@@ -2,7 +2,9 @@
 //! [`LocalCopyOptions`]: checksum mode, size-only, time-related toggles,
 //! existence filters, block-size override, and modify-window tolerance.
 
+use std::ffi::{OsStr, OsString};
 use std::num::NonZeroU32;
+use std::path::{Path, PathBuf};
 
 use metadata::ModifyWindow;
 
@@ -39,6 +41,32 @@ impl LocalCopyOptions {
         self
     }
 
+    /// Selects the directory that persists `--checksum` sums across runs.
+    ///
+    /// Each source or destination directory gets one `.rsync-checksums` side
+    /// file under `directory`. A cached sum is reused only while the file's
+    /// size and mtime still match the values recorded with it, so `-c` skips
+    /// rehashing files that have not changed since the previous run.
+    #[must_use]
+    #[doc(alias = "--checksum-cache")]
+    pub fn with_checksum_cache_dir<P: Into<PathBuf>>(mut self, directory: Option<P>) -> Self {
+        self.checksum_cache_dir = directory.map(Into::into);
+        self
+    }
+
+    /// Names the extended attribute that may hold a file's precomputed
+    /// `--checksum` sum, such as
+    /// [`DEFAULT_CHECKSUM_XATTR`](crate::local_copy::DEFAULT_CHECKSUM_XATTR).
+    ///
+    /// Before hashing a source or destination file, `-c` reads the attribute
+    /// and uses the digest it records when the recorded algorithm, size and
+    /// mtime still match the file. The attribute is only read, never written.
+    #[must_use]
+    pub fn with_checksum_xattr<N: Into<OsString>>(mut self, name: Option<N>) -> Self {
+        self.checksum_xattr = name.map(Into::into);
+        self
+    }
+
     /// Enables the internal xxh64 file-dedup heuristic.
     ///
     /// When set, the receiver hashes both the source and the existing
@@ -157,6 +185,17 @@ impl LocalCopyOptions {
         self.checksum_seed
     }
 
+    /// Returns the persistent `--checksum` cache directory, if configured.
+    pub fn checksum_cache_dir(&self) -> Option<&Path> {
+        self.checksum_cache_dir.as_deref()
+    }
+
+    /// Returns the extended attribute consulted for precomputed
+    /// `--checksum` sums, if configured.
+    pub fn checksum_xattr(&self) -> Option<&OsStr> {
+        self.checksum_xattr.as_deref()
+    }
+
     /// Reports whether the internal xxh64 file-dedup heuristic is enabled.
     #[must_use]
     pub const fn xxh64_dedup_enabled(&self) -> bool {
@@ -233,6 +272,21 @@ mod tests {
         assert!(opts.checksum_enabled());
     }
 
+    #[test]
+    fn checksum_cache_dir_round_trips() {
+        let opts = LocalCopyOptions::new().with_checksum_cache_dir(Some("/var/cache/sums"));
+        assert_eq!(
+            opts.checksum_cache_dir(),
+            Some(Path::new("/var/cache/sums"))
+        );
+        assert!(
+            LocalCopyOptions::new()
+                .with_checksum_cache_dir(None::<PathBuf>)
+                .checksum_cache_dir()
+                .is_none()
+        );
+    }
+
     #[test]
     fn checksum_disables() {
         let opts = LocalCopyOptions::new().checksum(true).checksum(false);
@@ -23,6 +23,21 @@ impl LocalCopyOptions {
         self
     }
 
+    /// Sets the size of the buffer file contents are streamed through.
+    ///
+    /// Applies to the read/write loop used when the kernel copy fast path is
+    /// not taken (sparse, compressed, rate-limited or delta copies, and
+    /// filesystems without `copy_file_range`). `None` keeps the shared pool's
+    /// block size; values above
+    /// [`MAX_BUFFER_POOL_BLOCK_SIZE`](crate::local_copy::MAX_BUFFER_POOL_BLOCK_SIZE)
+    /// are clamped.
+    #[must_use]
+    #[doc(alias = "--copy-buffer-size")]
+    pub const fn copy_buffer_size(mut self, size: Option<usize>) -> Self {
+        self.copy_buffer_size = size;
+        self
+    }
+
     /// Requests that source files be removed after successful transfer.
     #[must_use]
     #[doc(alias = "--remove-source-files")]
@@ -127,6 +142,11 @@ impl LocalCopyOptions {
     pub const fn stop_at(&self) -> Option<SystemTime> {
         self.stop_at
     }
+
+    /// Returns the configured copy buffer size, if any.
+    pub const fn copy_buffer_size_bytes(&self) -> Option<usize> {
+        self.copy_buffer_size
+    }
 }
 
 #[cfg(test)]
@@ -262,5 +282,12 @@ mod tests {
         assert!(opts.bandwidth_burst_bytes().is_none());
         assert!(opts.timeout().is_none());
         assert!(opts.stop_at().is_none());
+        assert!(opts.copy_buffer_size_bytes().is_none());
+    }
+
+    #[test]
+    fn copy_buffer_size_sets_value() {
+        let opts = LocalCopyOptions::new().copy_buffer_size(Some(256 * 1024));
+        assert_eq!(opts.copy_buffer_size_bytes(), Some(256 * 1024));
     }
 }
@@ -6,7 +6,7 @@
 //! xattrs) are gated behind the same `cfg` flags as their setter
 //! counterparts.
 
-use ::metadata::{ChmodModifiers, CopyAsIds, GroupMapping, UserMapping};
+use ::metadata::{ChmodModifiers, CopyAsIds, ForceChange, GroupMapping, UserMapping};
 
 use super::super::types::LocalCopyOptions;
 
@@ -86,6 +86,18 @@ impl LocalCopyOptions {
         self.preserve_crtimes
     }
 
+    /// Reports whether file flags (immutable, append-only, ...) should be preserved.
+    #[must_use]
+    pub const fn preserve_fileflags(&self) -> bool {
+        self.preserve_fileflags
+    }
+
+    /// Returns which immutable/append-only flags may be cleared to update a file.
+    #[must_use]
+    pub const fn force_change(&self) -> ForceChange {
+        self.force_change
+    }
+
     /// Reports whether directory modification times should be skipped during metadata preservation.
     #[must_use]
     pub const fn omit_dir_times_enabled(&self) -> bool {
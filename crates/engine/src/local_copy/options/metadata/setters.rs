@@ -5,7 +5,7 @@
 //! chains. These methods control which metadata attributes are preserved
 //! during local copy operations.
 
-use ::metadata::{ChmodModifiers, CopyAsIds, GroupMapping, UserMapping};
+use ::metadata::{ChmodModifiers, CopyAsIds, ForceChange, GroupMapping, UserMapping};
 
 use super::super::types::LocalCopyOptions;
 
@@ -129,6 +129,34 @@ impl LocalCopyOptions {
         self
     }
 
+    /// Requests that file flags be copied onto regular files.
+    ///
+    /// Covers the Linux inode flags managed by `chattr` and the BSD/macOS
+    /// `chflags` flags. Privileged flags such as immutable and append-only are
+    /// only applied when the process may change them. This corresponds to the
+    /// `--fileflags` option; on platforms without file flags it has no effect.
+    #[must_use]
+    #[doc(alias = "--fileflags")]
+    pub const fn fileflags(mut self, preserve: bool) -> Self {
+        self.preserve_fileflags = preserve;
+        self
+    }
+
+    /// Allows clearing immutable and append-only flags to update a file.
+    ///
+    /// Before an existing destination is replaced, the selected flags are
+    /// dropped; afterwards the file gets the source's flags under
+    /// [`Self::fileflags`] or its previous flags otherwise. This corresponds
+    /// to `--force-change`, `--force-uchange` and `--force-schange`.
+    #[must_use]
+    #[doc(alias = "--force-change")]
+    #[doc(alias = "--force-uchange")]
+    #[doc(alias = "--force-schange")]
+    pub const fn with_force_change(mut self, force: ForceChange) -> Self {
+        self.force_change = force;
+        self
+    }
+
     /// Skips preserving directory modification times even when [`Self::times`] is enabled.
     #[must_use]
     #[doc(alias = "--omit-dir-times")]
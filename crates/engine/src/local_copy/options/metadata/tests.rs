@@ -3,7 +3,7 @@
 
 use super::super::types::LocalCopyOptions;
 use super::accessors::{effective_am_root, is_effective_root};
-use ::metadata::CopyAsIds;
+use ::metadata::{CopyAsIds, ForceChange};
 
 #[test]
 fn owner_preservation() {
@@ -71,6 +71,20 @@ fn crtimes_defaults_to_false() {
     assert!(!options.preserve_crtimes());
 }
 
+#[test]
+fn fileflags_preservation() {
+    let options = LocalCopyOptions::new().fileflags(true);
+    assert!(options.preserve_fileflags());
+    assert!(!LocalCopyOptions::new().preserve_fileflags());
+}
+
+#[test]
+fn force_change_defaults_to_none() {
+    assert!(LocalCopyOptions::new().force_change().is_none());
+    let options = LocalCopyOptions::new().with_force_change(ForceChange::ALL);
+    assert_eq!(options.force_change(), ForceChange::ALL);
+}
+
 #[test]
 fn omit_dir_times() {
     let options = LocalCopyOptions::new().omit_dir_times(true);
@@ -11,6 +11,7 @@ mod builder;
 mod compression;
 mod deletion;
 mod filters;
+mod hooks;
 mod integrity;
 mod limits;
 mod link_dest;
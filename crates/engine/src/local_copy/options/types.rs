@@ -11,7 +11,7 @@ use std::path::PathBuf;
 use std::sync::{Arc, Mutex};
 use std::time::{Duration, SystemTime};
 
-use ::metadata::{ChmodModifiers, CopyAsIds, GroupMapping, ModifyWindow, UserMapping};
+use ::metadata::{ChmodModifiers, CopyAsIds, ForceChange, GroupMapping, ModifyWindow, UserMapping};
 use compress::algorithm::CompressionAlgorithm;
 use compress::zlib::CompressionLevel;
 use fast_io::{DefaultPlatformCopy, PlatformCopy};
@@ -21,6 +21,7 @@ use protocol::iconv::FilenameConverter;
 use crate::batch::BatchWriter;
 use crate::local_copy::executor::{DEFAULT_XXH64_DEDUP_SIZE_LIMIT, SparseDetectStrategy};
 use crate::local_copy::filter_program::FilterProgram;
+use crate::local_copy::hooks::LocalCopyFileHooks;
 use crate::local_copy::skip_compress::SkipCompressList;
 use crate::signature::SignatureAlgorithm;
 
@@ -137,6 +138,8 @@ pub struct LocalCopyOptions {
     pub(super) preserve_times: bool,
     pub(super) preserve_atimes: bool,
     pub(super) preserve_crtimes: bool,
+    pub(super) preserve_fileflags: bool,
+    pub(super) force_change: ForceChange,
     pub(super) omit_link_times: bool,
     pub(super) owner_override: Option<u32>,
     pub(super) group_override: Option<u32>,
@@ -172,6 +175,12 @@ pub struct LocalCopyOptions {
     pub(super) checksum: bool,
     pub(super) checksum_algorithm: SignatureAlgorithm,
     pub(super) checksum_seed: Option<u32>,
+    /// Directory holding persistent `--checksum` sums keyed by path, size,
+    /// and mtime, so unchanged files are not rehashed on the next run.
+    pub(super) checksum_cache_dir: Option<PathBuf>,
+    /// Extended attribute holding a precomputed `--checksum` sum, consulted
+    /// before a file is hashed.
+    pub(super) checksum_xattr: Option<OsString>,
     /// Enables the internal xxh64 file-dedup heuristic.
     ///
     /// When set, the receiver hashes both the source and the existing
@@ -266,6 +275,12 @@ pub struct LocalCopyOptions {
     /// macOS, ReFS reflink/CopyFileExW on Windows) with portable fallback.
     /// Tests can inject a fake implementation to verify dispatch.
     pub(super) platform_copy: Arc<dyn PlatformCopy>,
+    /// Per-file callbacks installed by an embedding program.
+    pub(super) file_hooks: LocalCopyFileHooks,
+    /// Size of the buffer each file's contents are streamed through when the
+    /// kernel copy fast path is not taken. `None` uses the global buffer
+    /// pool's block size.
+    pub(super) copy_buffer_size: Option<usize>,
 }
 
 impl LocalCopyOptions {
@@ -310,6 +325,8 @@ impl LocalCopyOptions {
             preserve_times: false,
             preserve_atimes: false,
             preserve_crtimes: false,
+            preserve_fileflags: false,
+            force_change: ForceChange::NONE,
             owner_override: None,
             group_override: None,
             copy_as: None,
@@ -326,6 +343,8 @@ impl LocalCopyOptions {
             checksum: false,
             checksum_algorithm: SignatureAlgorithm::Xxh3_128 { seed: 0 },
             checksum_seed: None,
+            checksum_cache_dir: None,
+            checksum_xattr: None,
             enable_xxh64_dedup: false,
             xxh64_dedup_size_limit: DEFAULT_XXH64_DEDUP_SIZE_LIMIT,
             size_only: false,
@@ -379,6 +398,8 @@ impl LocalCopyOptions {
             log_file: None,
             log_file_format: None,
             platform_copy: Arc::new(DefaultPlatformCopy::new()),
+            file_hooks: LocalCopyFileHooks::new(),
+            copy_buffer_size: None,
         }
     }
 }
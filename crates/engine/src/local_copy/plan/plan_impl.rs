@@ -111,6 +111,22 @@ impl LocalCopyPlan {
         &self.destination
     }
 
+    /// Returns a copy of the plan whose operands of `PATH_MAX` bytes or more
+    /// carry anchored aliases, or `None` when every operand can be used as
+    /// given.
+    ///
+    /// The aliases stay valid for as long as the returned plan is alive.
+    pub(in crate::local_copy) fn reach_long_operands(
+        &self,
+    ) -> Result<Option<Self>, LocalCopyError> {
+        let mut anchored = self.clone();
+        let mut any = anchored.destination.reach_long_path()?;
+        for source in &mut anchored.sources {
+            any |= source.reach_long_path()?;
+        }
+        Ok(any.then_some(anchored))
+    }
+
     /// Executes the planned copy.
     ///
     /// # Errors
@@ -604,3 +604,97 @@ fn delete_delay_preserves_symlinks_when_appropriate() {
         assert_eq!(summary.items_deleted(), 1);
     }
 }
+
+#[test]
+fn delete_delay_removes_nothing_until_the_final_phase() {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("source");
+    let dest = temp.path().join("dest");
+    let stale = ["a/stale.txt", "b/stale.txt", "c/stale.txt", "gone/old.txt"];
+    for dir in ["a", "b", "c"] {
+        fs::create_dir_all(source.join(dir)).expect("create source dir");
+        fs::create_dir_all(dest.join(dir)).expect("create dest dir");
+        fs::write(source.join(dir).join("file.txt"), b"new").expect("write file");
+    }
+    fs::create_dir_all(dest.join("gone")).expect("create extraneous dir");
+    for name in stale {
+        fs::write(dest.join(name), b"stale").expect("write stale");
+    }
+
+    /// Counts the stale entries still present at every copy, and the order
+    /// in which copies and deletions are reported.
+    struct PhaseObserver {
+        dest: PathBuf,
+        stale: Vec<&'static str>,
+        present_at_copy: Vec<usize>,
+        actions: Vec<LocalCopyAction>,
+    }
+
+    impl LocalCopyRecordHandler for PhaseObserver {
+        fn handle(&mut self, record: LocalCopyRecord) {
+            match record.action() {
+                LocalCopyAction::DataCopied => {
+                    let present = self
+                        .stale
+                        .iter()
+                        .filter(|name| self.dest.join(name).exists())
+                        .count();
+                    self.present_at_copy.push(present);
+                }
+                LocalCopyAction::EntryDeleted => {}
+                _ => return,
+            }
+            self.actions.push(record.action().clone());
+        }
+    }
+
+    let mut observer = PhaseObserver {
+        dest: dest.clone(),
+        stale: stale.to_vec(),
+        present_at_copy: Vec::new(),
+        actions: Vec::new(),
+    };
+
+    let mut source_operand = source.into_os_string();
+    source_operand.push(std::path::MAIN_SEPARATOR.to_string());
+    let operands = vec![source_operand, dest.clone().into_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    let summary = plan
+        .execute_with_options_and_handler(
+            LocalCopyExecution::Apply,
+            LocalCopyOptions::default().delete_delay(true),
+            Some(&mut observer),
+        )
+        .expect("copy succeeds");
+
+    assert_eq!(
+        observer.present_at_copy,
+        vec![stale.len(); 3],
+        "no extraneous entry may be removed while files are still transferring"
+    );
+    let first_delete = observer
+        .actions
+        .iter()
+        .position(|action| *action == LocalCopyAction::EntryDeleted)
+        .expect("deletions reported");
+    assert!(
+        observer.actions[first_delete..]
+            .iter()
+            .all(|action| *action == LocalCopyAction::EntryDeleted),
+        "deletions form one batch after the last copy: {:?}",
+        observer.actions
+    );
+
+    for name in stale {
+        assert!(
+            !dest.join(name).exists(),
+            "{name} removed in the final phase"
+        );
+    }
+    assert!(!dest.join("gone").exists());
+    for dir in ["a", "b", "c"] {
+        assert!(dest.join(dir).join("file.txt").exists());
+    }
+    assert_eq!(summary.files_copied(), 3);
+}
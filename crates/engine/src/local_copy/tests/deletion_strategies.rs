@@ -77,6 +77,68 @@ fn delete_during_removes_files_incrementally() {
     assert_eq!(summary.items_deleted(), 2);
 }
 
+/// Tests that --delete-during sweeps each directory when the walk reaches it:
+/// `a/stale.txt` is gone before `a/` receives its file while `b/stale.txt`
+/// survives until `b/` is processed.
+#[test]
+fn delete_during_sweeps_each_directory_as_it_is_processed() {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("source");
+    let dest = temp.path().join("dest");
+    for dir in ["a", "b"] {
+        fs::create_dir_all(source.join(dir)).expect("create source dir");
+        fs::create_dir_all(dest.join(dir)).expect("create dest dir");
+        fs::write(source.join(dir).join("file.txt"), b"new").expect("write file");
+        fs::write(dest.join(dir).join("stale.txt"), b"stale").expect("write stale");
+    }
+
+    /// Records, at each directory's first copy, which stale files remain.
+    struct SweepObserver {
+        dest: PathBuf,
+        seen: Vec<(PathBuf, bool, bool)>,
+    }
+
+    impl LocalCopyRecordHandler for SweepObserver {
+        fn handle(&mut self, record: LocalCopyRecord) {
+            if record.action() == &LocalCopyAction::DataCopied {
+                self.seen.push((
+                    record.relative_path().to_path_buf(),
+                    self.dest.join("a/stale.txt").exists(),
+                    self.dest.join("b/stale.txt").exists(),
+                ));
+            }
+        }
+    }
+
+    let mut observer = SweepObserver {
+        dest: dest.clone(),
+        seen: Vec::new(),
+    };
+
+    let mut source_operand = source.into_os_string();
+    source_operand.push(std::path::MAIN_SEPARATOR.to_string());
+    let operands = vec![source_operand, dest.clone().into_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    let summary = plan
+        .execute_with_options_and_handler(
+            LocalCopyExecution::Apply,
+            LocalCopyOptions::default().delete_during(),
+            Some(&mut observer),
+        )
+        .expect("copy succeeds");
+
+    assert_eq!(
+        observer.seen,
+        vec![
+            (PathBuf::from("a/file.txt"), false, true),
+            (PathBuf::from("b/file.txt"), false, false),
+        ],
+        "each directory is swept just before its own entries are transferred"
+    );
+    assert_eq!(summary.items_deleted(), 2);
+}
+
 /// Tests that --delete-after preserves files during transfer.
 #[test]
 fn delete_after_preserves_files_during_transfer() {
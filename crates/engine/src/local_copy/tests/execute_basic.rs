@@ -121,6 +121,54 @@ fn execute_with_relative_preserves_parent_directories() {
     assert_eq!(summary.files_copied(), 1);
 }
 
+#[test]
+fn execute_with_relative_reproduces_only_the_path_after_the_dot_anchor() {
+    let temp = create_tempdir();
+    let source_root = temp.path().join("src");
+    let destination_root = temp.path().join("dest");
+    fs::create_dir_all(source_root.join("a/b/sub")).expect("create source tree");
+    fs::create_dir_all(&destination_root).expect("create destination root");
+    fs::write(source_root.join("a/b/file.txt"), b"top").expect("write file");
+    fs::write(source_root.join("a/b/sub/deep.txt"), b"deep").expect("write nested file");
+    fs::write(source_root.join("a/skipped.txt"), b"outside").expect("write sibling");
+
+    let operand = source_root.join(".").join("a").join("b");
+    let operands = vec![
+        operand.into_os_string(),
+        destination_root.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    plan.execute_with_options(
+        LocalCopyExecution::Apply,
+        LocalCopyOptions::default()
+            .recursive(true)
+            .relative_paths(true),
+    )
+    .expect("copy succeeds");
+
+    let names = |dir: &Path| {
+        let mut names: Vec<_> = fs::read_dir(dir)
+            .expect("read dir")
+            .map(|entry| entry.expect("entry").file_name())
+            .collect();
+        names.sort();
+        names
+    };
+    // Nothing before the anchor (`src`, the temp dir) is reproduced, and
+    // `a` holds only the implied path to `b`.
+    assert_eq!(names(&destination_root), ["a"]);
+    assert_eq!(names(&destination_root.join("a")), ["b"]);
+    assert_eq!(
+        fs::read(destination_root.join("a/b/file.txt")).expect("read file"),
+        b"top"
+    );
+    assert_eq!(
+        fs::read(destination_root.join("a/b/sub/deep.txt")).expect("read nested file"),
+        b"deep"
+    );
+}
+
 #[test]
 fn execute_with_relative_requires_directory_destination() {
     let temp = create_tempdir();
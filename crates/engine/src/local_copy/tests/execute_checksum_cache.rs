@@ -0,0 +1,105 @@
+// Tests for the persistent --checksum cache (`.rsync-checksums` side files).
+//
+// With a checksum cache directory configured, -c records each hashed file's
+// digest together with its size and mtime. A later run reuses the recorded
+// digest instead of rereading the file while size and mtime are unchanged.
+//
+// Key behaviors tested:
+// 1. The first run writes one side file per source and destination directory
+// 2. The second run trusts the recorded sums (observable by rewriting a file
+//    in place without changing its size or mtime)
+// 3. Changing a file's mtime invalidates its entry and forces a fresh hash
+
+fn checksum_cache_fixture() -> (tempfile::TempDir, PathBuf, PathBuf, PathBuf) {
+    let temp = tempdir().expect("tempdir");
+    let source_root = temp.path().join("source");
+    let dest_root = temp.path().join("dest");
+    let cache_dir = temp.path().join("cache");
+    fs::create_dir_all(&source_root).expect("create source root");
+    fs::create_dir_all(&dest_root).expect("create dest root");
+
+    // Backdate everything past the cache's racy window so entries are kept.
+    let timestamp = FileTime::from_unix_time(1_700_000_000, 0);
+    for name in ["a.txt", "b.txt"] {
+        fs::write(source_root.join(name), b"cached content").expect("write source");
+        fs::write(dest_root.join(name), b"cached content").expect("write dest");
+        set_file_mtime(source_root.join(name), timestamp).expect("set source time");
+        set_file_mtime(dest_root.join(name), timestamp).expect("set dest time");
+    }
+
+    (temp, source_root, dest_root, cache_dir)
+}
+
+fn run_with_checksum_cache(
+    source_root: &Path,
+    dest_root: &Path,
+    cache_dir: &Path,
+) -> LocalCopySummary {
+    let mut source_operand = source_root.as_os_str().to_os_string();
+    source_operand.push(std::path::MAIN_SEPARATOR.to_string());
+    let operands = vec![source_operand, dest_root.as_os_str().to_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    plan.execute_with_options(
+        LocalCopyExecution::Apply,
+        LocalCopyOptions::default()
+            .checksum(true)
+            .with_checksum_cache_dir(Some(cache_dir)),
+    )
+    .expect("copy succeeds")
+}
+
+#[test]
+fn checksum_cache_writes_side_file_per_directory() {
+    let (_temp, source_root, dest_root, cache_dir) = checksum_cache_fixture();
+
+    let summary = run_with_checksum_cache(&source_root, &dest_root, &cache_dir);
+    assert_eq!(summary.files_copied(), 0);
+    assert_eq!(summary.regular_files_matched(), 2);
+
+    let side_files: Vec<_> = fs::read_dir(&cache_dir)
+        .expect("cache dir exists")
+        .map(|entry| entry.expect("dir entry").file_name())
+        .collect();
+    assert_eq!(
+        side_files.len(),
+        2,
+        "one side file each for source and dest"
+    );
+    assert!(
+        side_files
+            .iter()
+            .all(|name| name.to_string_lossy().ends_with(".rsync-checksums"))
+    );
+}
+
+#[test]
+fn checksum_cache_second_run_reads_recorded_sums() {
+    let (_temp, source_root, dest_root, cache_dir) = checksum_cache_fixture();
+    run_with_checksum_cache(&source_root, &dest_root, &cache_dir);
+
+    // Rewrite the destination in place, keeping size and mtime: only a run
+    // that rereads the file can notice.
+    let tampered = dest_root.join("a.txt");
+    fs::write(&tampered, b"CACHED CONTENT").expect("rewrite dest");
+    set_file_mtime(&tampered, FileTime::from_unix_time(1_700_000_000, 0))
+        .expect("restore dest time");
+
+    let summary = run_with_checksum_cache(&source_root, &dest_root, &cache_dir);
+    assert_eq!(summary.files_copied(), 0, "recorded sums were reused");
+    assert_eq!(fs::read(&tampered).expect("read dest"), b"CACHED CONTENT");
+}
+
+#[test]
+fn checksum_cache_mtime_change_forces_rehash() {
+    let (_temp, source_root, dest_root, cache_dir) = checksum_cache_fixture();
+    run_with_checksum_cache(&source_root, &dest_root, &cache_dir);
+
+    let tampered = dest_root.join("a.txt");
+    fs::write(&tampered, b"CACHED CONTENT").expect("rewrite dest");
+    set_file_mtime(&tampered, FileTime::from_unix_time(1_700_000_060, 0)).expect("move dest time");
+
+    let summary = run_with_checksum_cache(&source_root, &dest_root, &cache_dir);
+    assert_eq!(summary.files_copied(), 1, "stale entry was not trusted");
+    assert_eq!(fs::read(&tampered).expect("read dest"), b"cached content");
+}
@@ -0,0 +1,104 @@
+// Tests for --checksum sums precomputed into an extended attribute.
+//
+// With a checksum xattr configured, -c takes a file's digest from the
+// attribute instead of hashing the file, provided the recorded algorithm,
+// size and mtime still match.
+//
+// Key behaviors tested:
+// 1. A valid recorded sum is trusted (observable by seeding both sides with
+//    the same digest although their contents differ)
+// 2. A recorded mtime that no longer matches forces a fresh hash
+// 3. Without the option the attribute is ignored
+
+const CHECKSUM_XATTR_MTIME: i64 = 1_700_000_000;
+
+/// Writes differing same-size `a.txt` files and tags both with the same
+/// `xxh128` digest recorded for `recorded_mtime`. Returns `None` when the
+/// filesystem refuses user xattrs.
+#[cfg(all(unix, feature = "xattr"))]
+fn checksum_xattr_fixture(recorded_mtime: i64) -> Option<(tempfile::TempDir, PathBuf, PathBuf)> {
+    let temp = tempdir().expect("tempdir");
+    let source_root = temp.path().join("source");
+    let dest_root = temp.path().join("dest");
+    fs::create_dir_all(&source_root).expect("create source root");
+    fs::create_dir_all(&dest_root).expect("create dest root");
+
+    let value = format!("xxh128 14 {recorded_mtime} 0 {}", "00".repeat(16));
+    for (root, data) in [
+        (&source_root, b"cached content"),
+        (&dest_root, b"CACHED CONTENT"),
+    ] {
+        let file = root.join("a.txt");
+        fs::write(&file, data).expect("write file");
+        set_file_mtime(&file, FileTime::from_unix_time(CHECKSUM_XATTR_MTIME, 0))
+            .expect("set file time");
+        xattr::set(&file, DEFAULT_CHECKSUM_XATTR, value.as_bytes()).ok()?;
+    }
+
+    Some((temp, source_root, dest_root))
+}
+
+#[cfg(all(unix, feature = "xattr"))]
+fn run_with_checksum_xattr(
+    source_root: &Path,
+    dest_root: &Path,
+    xattr: Option<&str>,
+) -> LocalCopySummary {
+    let mut source_operand = source_root.as_os_str().to_os_string();
+    source_operand.push(std::path::MAIN_SEPARATOR.to_string());
+    let operands = vec![source_operand, dest_root.as_os_str().to_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    plan.execute_with_options(
+        LocalCopyExecution::Apply,
+        LocalCopyOptions::default()
+            .checksum(true)
+            .with_checksum_xattr(xattr),
+    )
+    .expect("copy succeeds")
+}
+
+#[cfg(all(unix, feature = "xattr"))]
+#[test]
+fn checksum_xattr_sum_replaces_hashing() {
+    let Some((_temp, source_root, dest_root)) = checksum_xattr_fixture(CHECKSUM_XATTR_MTIME) else {
+        eprintln!("user xattrs unsupported, skipping");
+        return;
+    };
+
+    let summary = run_with_checksum_xattr(&source_root, &dest_root, Some(DEFAULT_CHECKSUM_XATTR));
+    assert_eq!(summary.files_copied(), 0, "recorded sums were used");
+    assert_eq!(
+        fs::read(dest_root.join("a.txt")).expect("read dest"),
+        b"CACHED CONTENT"
+    );
+}
+
+#[cfg(all(unix, feature = "xattr"))]
+#[test]
+fn checksum_xattr_stale_mtime_forces_hash() {
+    let Some((_temp, source_root, dest_root)) = checksum_xattr_fixture(CHECKSUM_XATTR_MTIME + 60)
+    else {
+        eprintln!("user xattrs unsupported, skipping");
+        return;
+    };
+
+    let summary = run_with_checksum_xattr(&source_root, &dest_root, Some(DEFAULT_CHECKSUM_XATTR));
+    assert_eq!(summary.files_copied(), 1, "stale sum was not trusted");
+    assert_eq!(
+        fs::read(dest_root.join("a.txt")).expect("read dest"),
+        b"cached content"
+    );
+}
+
+#[cfg(all(unix, feature = "xattr"))]
+#[test]
+fn checksum_xattr_ignored_unless_configured() {
+    let Some((_temp, source_root, dest_root)) = checksum_xattr_fixture(CHECKSUM_XATTR_MTIME) else {
+        eprintln!("user xattrs unsupported, skipping");
+        return;
+    };
+
+    let summary = run_with_checksum_xattr(&source_root, &dest_root, None);
+    assert_eq!(summary.files_copied(), 1);
+}
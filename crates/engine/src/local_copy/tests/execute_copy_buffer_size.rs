@@ -0,0 +1,58 @@
+// Tests for LocalCopyOptions::copy_buffer_size.
+
+#[test]
+fn copy_buffer_size_gives_context_a_pool_of_that_size() {
+    let options = LocalCopyOptions::default().copy_buffer_size(Some(16 * 1024));
+    let context = CopyContext::new(LocalCopyExecution::Apply, options, None, PathBuf::from("."));
+    assert_eq!(context.buffer_pool().buffer_size(), 16 * 1024);
+}
+
+#[test]
+fn copy_buffer_size_unset_uses_global_pool() {
+    let context = CopyContext::new(
+        LocalCopyExecution::Apply,
+        LocalCopyOptions::default(),
+        None,
+        PathBuf::from("."),
+    );
+    assert!(std::sync::Arc::ptr_eq(
+        &context.buffer_pool(),
+        &crate::local_copy::global_buffer_pool()
+    ));
+}
+
+#[test]
+fn copy_buffer_size_is_clamped_to_pool_maximum() {
+    let requested = crate::local_copy::MAX_BUFFER_POOL_BLOCK_SIZE + 1;
+    let options = LocalCopyOptions::default().copy_buffer_size(Some(requested));
+    let context = CopyContext::new(LocalCopyExecution::Apply, options, None, PathBuf::from("."));
+    assert_eq!(
+        context.buffer_pool().buffer_size(),
+        crate::local_copy::MAX_BUFFER_POOL_BLOCK_SIZE
+    );
+}
+
+#[test]
+fn small_copy_buffer_streams_file_in_many_chunks() {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("source.bin");
+    let destination = temp.path().join("dest.bin");
+    // Several times the buffer, with a length that is not a multiple of it.
+    let payload: Vec<u8> = (0..300_001u32).map(|i| (i % 251) as u8 + 1).collect();
+    fs::write(&source, &payload).expect("write source");
+
+    let plan = LocalCopyPlan::from_operands(&[
+        source.into_os_string(),
+        destination.clone().into_os_string(),
+    ])
+    .expect("plan");
+    // Sparse writes bypass the kernel copy fast path, so the bytes go
+    // through the configured buffer.
+    let options = LocalCopyOptions::default()
+        .sparse(true)
+        .copy_buffer_size(Some(4096));
+    plan.execute_with_options(LocalCopyExecution::Apply, options)
+        .expect("copy succeeds");
+
+    assert_eq!(fs::read(&destination).expect("read dest"), payload);
+}
@@ -947,6 +947,46 @@ fn execute_recursive_disabled_only_copies_single_level() {
     assert!(!dest_root.join("source").join("root.txt").exists(), "files should not be copied without recursion");
 }
 
+#[test]
+fn execute_dirs_with_trailing_separator_creates_subdirectory_empty() {
+    // upstream: flist.c:2477 - `-d src/` sends src's immediate children, so a
+    // subdirectory is created but nothing inside it is transferred.
+    let temp = create_tempdir();
+    let source_root = temp.path().join("source");
+    let nested = source_root.join("nested");
+    fs::create_dir_all(&nested).expect("create nested");
+    fs::write(source_root.join("root.txt"), b"root").expect("write root file");
+    fs::write(nested.join("nested.txt"), b"nested").expect("write nested file");
+
+    let dest_root = temp.path().join("dest");
+    fs::create_dir_all(&dest_root).expect("create dest");
+
+    let mut source_operand = source_root.into_os_string();
+    source_operand.push(std::path::MAIN_SEPARATOR.to_string());
+    let operands = vec![source_operand, dest_root.clone().into_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let options = LocalCopyOptions::default().recursive(false).dirs(true);
+
+    plan.execute_with_options(LocalCopyExecution::Apply, options)
+        .expect("copy succeeds");
+
+    assert_eq!(
+        fs::read(dest_root.join("root.txt")).expect("read root"),
+        b"root"
+    );
+    assert!(
+        dest_root.join("nested").is_dir(),
+        "subdirectory should be created"
+    );
+    assert!(
+        fs::read_dir(dest_root.join("nested"))
+            .expect("read nested")
+            .next()
+            .is_none(),
+        "subdirectory contents must not be transferred"
+    );
+}
+
 #[cfg(unix)]
 #[test]
 fn execute_directory_fails_on_permission_denied_when_creating() {
@@ -1989,6 +2029,35 @@ fn execute_mkpath_with_existing_parents_succeeds() {
     assert_eq!(fs::read(&destination).expect("read"), b"data");
 }
 
+// upstream: main.c:736 make_path(dest_path, 0) - with a trailing slash the
+// whole destination operand names a directory, so --mkpath creates every
+// component (including `c`) and the file lands inside it under its own name.
+#[test]
+fn execute_mkpath_trailing_slash_creates_all_components() {
+    let temp = create_tempdir();
+    let source = temp.path().join("file.txt");
+    fs::write(&source, b"mkpath slash").expect("write source");
+
+    let dest_dir = temp.path().join("dest").join("a").join("b").join("c");
+    let mut dest_operand = dest_dir.clone().into_os_string();
+    dest_operand.push(std::path::MAIN_SEPARATOR.to_string());
+
+    let operands = vec![source.into_os_string(), dest_operand];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let options = LocalCopyOptions::default().mkpath(true);
+
+    let summary = plan
+        .execute_with_options(LocalCopyExecution::Apply, options)
+        .expect("copy succeeds");
+
+    assert!(dest_dir.is_dir(), "final component must be a directory");
+    assert_eq!(
+        fs::read(dest_dir.join("file.txt")).expect("read"),
+        b"mkpath slash"
+    );
+    assert_eq!(summary.files_copied(), 1);
+}
+
 #[test]
 fn execute_prune_empty_dirs_nested_hierarchy_file_at_bottom() {
     // Deep nesting: only the very deepest directory has a file
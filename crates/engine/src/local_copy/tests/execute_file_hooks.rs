@@ -0,0 +1,80 @@
+// Tests for per-file hooks installed through LocalCopyOptions::with_file_hooks.
+
+#[test]
+fn file_hooks_veto_skips_file_and_report_others() {
+    use std::sync::{Arc, Mutex};
+
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("source");
+    let dest = temp.path().join("dest");
+    fs::create_dir_all(&source).expect("create source");
+    fs::write(source.join("keep.txt"), b"keep").expect("write keep");
+    fs::write(source.join("secret.txt"), b"secret").expect("write secret");
+
+    let started = Arc::new(Mutex::new(Vec::new()));
+    let done = Arc::new(Mutex::new(Vec::new()));
+    let hooks = LocalCopyFileHooks::new()
+        .should_transfer(|file| file.relative_path() != Path::new("secret.txt"))
+        .on_file_start({
+            let started = Arc::clone(&started);
+            move |file| {
+                started
+                    .lock()
+                    .unwrap()
+                    .push(file.relative_path().to_path_buf())
+            }
+        })
+        .on_file_done({
+            let done = Arc::clone(&done);
+            move |file, error| {
+                done.lock()
+                    .unwrap()
+                    .push((file.relative_path().to_path_buf(), error.is_some()));
+            }
+        });
+
+    let mut source_operand = source.clone().into_os_string();
+    source_operand.push(std::path::MAIN_SEPARATOR.to_string());
+    let operands = vec![source_operand, dest.clone().into_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let summary = plan
+        .execute_with_options(
+            LocalCopyExecution::Apply,
+            LocalCopyOptions::default().with_file_hooks(hooks),
+        )
+        .expect("copy succeeds");
+
+    assert_eq!(summary.files_copied(), 1);
+    assert_eq!(fs::read(dest.join("keep.txt")).expect("read keep"), b"keep");
+    assert!(
+        !dest.join("secret.txt").exists(),
+        "vetoed file must not be written"
+    );
+    assert_eq!(*started.lock().unwrap(), vec![PathBuf::from("keep.txt")]);
+    assert_eq!(
+        *done.lock().unwrap(),
+        vec![(PathBuf::from("keep.txt"), false)]
+    );
+}
+
+#[test]
+fn file_hooks_veto_leaves_existing_destination_untouched() {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("source.txt");
+    let dest = temp.path().join("dest.txt");
+    fs::write(&source, b"new contents").expect("write source");
+    fs::write(&dest, b"old").expect("write dest");
+
+    let operands = vec![source.into_os_string(), dest.clone().into_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let summary = plan
+        .execute_with_options(
+            LocalCopyExecution::Apply,
+            LocalCopyOptions::default()
+                .with_file_hooks(LocalCopyFileHooks::new().should_transfer(|_| false)),
+        )
+        .expect("copy succeeds");
+
+    assert_eq!(summary.files_copied(), 0);
+    assert_eq!(fs::read(&dest).expect("read dest"), b"old");
+}
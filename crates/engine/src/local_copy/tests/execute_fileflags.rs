@@ -0,0 +1,171 @@
+// Tests for --fileflags and --force-change.
+//
+// Setting append-only or immutable flags needs CAP_LINUX_IMMUTABLE on Linux
+// and a filesystem that stores inode flags (tmpfs only gained them recently),
+// so each test probes the flag first and returns early where it cannot be set.
+
+/// The append-only flag: `FS_APPEND_FL` on Linux, `UF_APPEND` on the BSDs.
+#[cfg(target_os = "linux")]
+const APPEND_FLAG: u32 = 0x0000_0020;
+#[cfg(any(
+    target_os = "macos",
+    target_os = "freebsd",
+    target_os = "dragonfly",
+    target_os = "netbsd",
+    target_os = "openbsd"
+))]
+const APPEND_FLAG: u32 = 0x0000_0004;
+
+#[cfg(any(
+    target_os = "linux",
+    target_os = "macos",
+    target_os = "freebsd",
+    target_os = "dragonfly",
+    target_os = "netbsd",
+    target_os = "openbsd"
+))]
+fn has_append_flag(path: &Path) -> bool {
+    ::metadata::read_file_flags(path)
+        .ok()
+        .flatten()
+        .is_some_and(|flags| flags & APPEND_FLAG != 0)
+}
+
+#[cfg(any(
+    target_os = "linux",
+    target_os = "macos",
+    target_os = "freebsd",
+    target_os = "dragonfly",
+    target_os = "netbsd",
+    target_os = "openbsd"
+))]
+#[test]
+fn fileflags_preserves_append_only_flag() {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("audit.log");
+    let destination = temp.path().join("copy.log");
+    fs::write(&source, b"append only").expect("write source");
+
+    if ::metadata::apply_file_flags(&source, APPEND_FLAG).is_err() || !has_append_flag(&source) {
+        eprintln!("skipping: cannot set the append-only flag here");
+        return;
+    }
+
+    let operands = vec![
+        source.clone().into_os_string(),
+        destination.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let result = plan.execute_with_options(
+        LocalCopyExecution::Apply,
+        LocalCopyOptions::default().fileflags(true),
+    );
+    let preserved = has_append_flag(&destination);
+    let contents = fs::read(&destination);
+
+    // Clear the flags again so the tempdir can be removed.
+    let _ = ::metadata::apply_file_flags(&source, 0);
+    let _ = ::metadata::apply_file_flags(&destination, 0);
+
+    let summary = result.expect("copy succeeds");
+    assert_eq!(summary.files_copied(), 1);
+    assert_eq!(contents.expect("read destination"), b"append only");
+    assert!(preserved, "destination lost the append-only flag");
+}
+
+#[cfg(target_os = "linux")]
+#[test]
+fn fileflags_disabled_leaves_destination_flags_clear() {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("audit.log");
+    let destination = temp.path().join("copy.log");
+    fs::write(&source, b"append only").expect("write source");
+
+    if ::metadata::apply_file_flags(&source, APPEND_FLAG).is_err() || !has_append_flag(&source) {
+        eprintln!("skipping: cannot set the append-only flag here");
+        return;
+    }
+
+    let operands = vec![
+        source.clone().into_os_string(),
+        destination.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let result = plan.execute_with_options(LocalCopyExecution::Apply, LocalCopyOptions::default());
+    let preserved = has_append_flag(&destination);
+
+    let _ = ::metadata::apply_file_flags(&source, 0);
+    let _ = ::metadata::apply_file_flags(&destination, 0);
+
+    result.expect("copy succeeds");
+    assert!(!preserved, "append-only flag copied without --fileflags");
+}
+
+// --force-change: an immutable destination can only be replaced after the
+// receiver drops the flag, and the flag is put back afterwards.
+
+#[cfg(target_os = "linux")]
+const IMMUTABLE_FLAG: u32 = 0x0000_0010;
+
+#[cfg(target_os = "linux")]
+fn copy_over_immutable(force_change: ::metadata::ForceChange) -> Option<(bool, Vec<u8>, bool)> {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("config");
+    let destination = temp.path().join("installed");
+    fs::write(&source, b"new contents").expect("write source");
+    fs::write(&destination, b"old").expect("write destination");
+
+    if ::metadata::apply_file_flags(&destination, IMMUTABLE_FLAG).is_err()
+        || ::metadata::read_file_flags(&destination)
+            .ok()
+            .flatten()
+            .is_none_or(|flags| flags & IMMUTABLE_FLAG == 0)
+    {
+        return None;
+    }
+
+    let operands = vec![
+        source.clone().into_os_string(),
+        destination.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let result = plan.execute_with_options(
+        LocalCopyExecution::Apply,
+        LocalCopyOptions::default()
+            .whole_file(true)
+            .with_force_change(force_change),
+    );
+    let contents = fs::read(&destination).expect("read destination");
+    let still_immutable = ::metadata::read_file_flags(&destination)
+        .ok()
+        .flatten()
+        .is_some_and(|flags| flags & IMMUTABLE_FLAG != 0);
+
+    let _ = ::metadata::apply_file_flags(&destination, 0);
+    Some((result.is_ok(), contents, still_immutable))
+}
+
+#[cfg(target_os = "linux")]
+#[test]
+fn force_change_updates_immutable_destination() {
+    let Some((without_ok, without_contents, _)) =
+        copy_over_immutable(::metadata::ForceChange::NONE)
+    else {
+        eprintln!("skipping: cannot set the immutable flag here");
+        return;
+    };
+    assert!(
+        !without_ok,
+        "replacing an immutable file must fail without --force-change"
+    );
+    assert_eq!(without_contents, b"old");
+
+    let (with_ok, with_contents, still_immutable) =
+        copy_over_immutable(::metadata::ForceChange::ALL).expect("immutable flag settable");
+    assert!(with_ok, "--force-change should allow the update");
+    assert_eq!(with_contents, b"new contents");
+    assert!(
+        still_immutable,
+        "--force-change must restore the immutable flag"
+    );
+}
@@ -786,6 +786,54 @@ fn execute_hardlink_dry_run_mode() {
 }
 
 /// Test that hardlink count tracking is accurate across operations.
+#[cfg(unix)]
+#[test]
+fn execute_hardlink_group_transfers_data_once() {
+    use std::os::unix::fs::MetadataExt;
+
+    let temp = tempdir().expect("tempdir");
+    let source_root = temp.path().join("source");
+    fs::create_dir_all(&source_root).expect("create source root");
+
+    let payload = vec![0x5a_u8; 64 * 1024];
+    fs::write(source_root.join("a.bin"), &payload).expect("write a");
+    fs::hard_link(source_root.join("a.bin"), source_root.join("b.bin")).expect("link b");
+    fs::hard_link(source_root.join("a.bin"), source_root.join("c.bin")).expect("link c");
+
+    let dest_root = temp.path().join("dest");
+    let operands = vec![
+        source_root.into_os_string(),
+        dest_root.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    let options = LocalCopyOptions::default().hard_links(true);
+    let summary = plan
+        .execute_with_options(LocalCopyExecution::Apply, options)
+        .expect("copy succeeds");
+
+    // upstream: hlink.c - only the group leader's data is sent; the other
+    // names are linked to it on the receiver.
+    let size = payload.len() as u64;
+    assert_eq!(summary.files_copied(), 1);
+    assert_eq!(summary.bytes_copied(), size);
+    assert_eq!(summary.transferred_file_size(), size);
+    assert_eq!(summary.hard_links_created(), 2);
+
+    let dest = dest_root.join("source");
+    let inode = fs::metadata(dest.join("a.bin")).expect("a").ino();
+    for name in ["b.bin", "c.bin"] {
+        let metadata = fs::metadata(dest.join(name)).expect("linked file");
+        assert_eq!(
+            metadata.ino(),
+            inode,
+            "{name} should share the leader's inode"
+        );
+        assert_eq!(metadata.nlink(), 3);
+    }
+    assert_eq!(fs::read(dest.join("c.bin")).expect("read c"), payload);
+}
+
 #[cfg(unix)]
 #[test]
 fn execute_hardlink_summary_counts_accurate() {
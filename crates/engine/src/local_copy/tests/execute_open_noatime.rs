@@ -0,0 +1,65 @@
+// Tests for --open-noatime source access-time preservation.
+//
+// upstream: syscall.c:do_open() adds O_NOATIME when open_noatime is set, so
+// reading a source file for the transfer leaves its atime untouched. The
+// check only means something on a filesystem that updates atimes on read, so
+// the test first probes the temp directory and returns early on noatime
+// mounts.
+
+/// Returns whether reading a file under `dir` with a plain open bumps an atime
+/// that is older than the file's mtime (true under `strictatime` and the
+/// default `relatime`).
+#[cfg(target_os = "linux")]
+fn reads_update_atime(dir: &Path) -> bool {
+    let probe = dir.join("atime-probe");
+    fs::write(&probe, b"probe").expect("write probe");
+    let old_atime = FileTime::from_unix_time(1_500_000_000, 0);
+    let mtime = FileTime::from_unix_time(1_700_000_000, 0);
+    set_file_times(&probe, old_atime, mtime).expect("set probe times");
+    fs::read(&probe).expect("read probe");
+    let atime = FileTime::from_last_access_time(&fs::metadata(&probe).expect("probe metadata"));
+    atime != old_atime
+}
+
+#[cfg(target_os = "linux")]
+#[test]
+fn open_noatime_leaves_source_atime_unchanged() {
+    let temp = tempdir().expect("tempdir");
+    if !reads_update_atime(temp.path()) {
+        eprintln!("skipping: filesystem does not update atime on read");
+        return;
+    }
+
+    let source = temp.path().join("source.txt");
+    let destination = temp.path().join("dest.txt");
+    fs::write(&source, b"read me without touching atime").expect("write source");
+    let old_atime = FileTime::from_unix_time(1_500_000_000, 0);
+    let mtime = FileTime::from_unix_time(1_700_000_000, 0);
+    set_file_times(&source, old_atime, mtime).expect("set source times");
+
+    let operands = vec![
+        source.clone().into_os_string(),
+        destination.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let summary = plan
+        .execute_with_options(
+            LocalCopyExecution::Apply,
+            LocalCopyOptions::default()
+                .whole_file(true)
+                .open_noatime(true),
+        )
+        .expect("copy succeeds");
+
+    assert_eq!(summary.files_copied(), 1);
+    assert_eq!(
+        fs::read(&destination).expect("read destination"),
+        b"read me without touching atime"
+    );
+    let source_atime =
+        FileTime::from_last_access_time(&fs::metadata(&source).expect("source metadata"));
+    assert_eq!(
+        source_atime, old_atime,
+        "--open-noatime must not bump the source atime"
+    );
+}
@@ -1215,6 +1215,63 @@ fn execute_device_file_skipped_without_devices_or_copy_devices() {
 }
 
 
+/// A non-root copy cannot `mknod(2)` a device. The device is skipped with an
+/// `mknod ... failed` diagnostic, the regular file and FIFO from the same run
+/// still arrive, and the copy finishes with `RERR_PARTIAL` (23) instead of
+/// aborting at the device.
+///
+/// upstream: generator.c:atomic_create() - `rsyserr(FERROR_XFER, ...)` on a
+/// failed `do_mknod()`, then the next entry.
+#[cfg(unix)]
+#[test]
+fn execute_non_root_skips_uncreatable_device_and_finishes_partial() {
+    use std::os::unix::fs::FileTypeExt;
+
+    if rustix::process::geteuid().as_raw() == 0 {
+        return; // root can create the device node
+    }
+
+    let temp = create_tempdir();
+    let source_root = temp.path().join("source");
+    fs::create_dir_all(&source_root).expect("create source root");
+    fs::write(source_root.join("file.txt"), b"payload").expect("write file");
+    mkfifo_for_tests(&source_root.join("pipe"), 0o644).expect("mkfifo");
+    let dest_root = temp.path().join("dest");
+    fs::create_dir_all(&dest_root).expect("create dest root");
+
+    let mut source_operand = source_root.into_os_string();
+    source_operand.push("/");
+    let operands = vec![
+        source_operand,
+        OsString::from("/dev/null"),
+        dest_root.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    let error = plan
+        .execute_with_options(
+            LocalCopyExecution::Apply,
+            LocalCopyOptions::default().devices(true).specials(true),
+        )
+        .expect_err("a skipped device must surface as a partial transfer");
+
+    assert_eq!(error.exit_code(), 23);
+    assert_eq!(
+        fs::read(dest_root.join("file.txt")).expect("regular file copied"),
+        b"payload"
+    );
+    assert!(
+        fs::symlink_metadata(dest_root.join("pipe"))
+            .expect("fifo copied")
+            .file_type()
+            .is_fifo()
+    );
+    assert!(
+        fs::symlink_metadata(dest_root.join("null")).is_err(),
+        "uncreatable device must be left absent"
+    );
+}
+
 #[cfg(unix)]
 #[test]
 fn execute_copy_links_follows_symlink_to_fifo_specials_disabled_skips() {
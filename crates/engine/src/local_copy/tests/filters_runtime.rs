@@ -28,6 +28,44 @@ fn execute_respects_exclude_filter() {
     assert!(summary.files_copied() >= 1);
 }
 
+/// `build/` matches only directories: the `build` directory and its contents
+/// are skipped while a regular file named `build` elsewhere is still copied.
+///
+/// upstream: exclude.c:rule_matches() - a FILTRULE_DIRECTORY rule reports no
+/// match for a non-directory name.
+#[test]
+fn execute_directory_only_exclude_skips_directory_but_not_file() {
+    let temp = tempdir().expect("tempdir");
+    let source = temp.path().join("source");
+    let dest = temp.path().join("dest");
+    fs::create_dir_all(source.join("build")).expect("create build dir");
+    fs::create_dir_all(source.join("sub")).expect("create sub dir");
+    fs::create_dir_all(&dest).expect("create dest");
+    fs::write(source.join("build").join("out.o"), b"object").expect("write out.o");
+    fs::write(source.join("sub").join("build"), b"script").expect("write build file");
+
+    let operands = vec![
+        source.into_os_string(),
+        dest.clone().into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let filters = FilterSet::from_rules([filters::FilterRule::exclude("build/")])
+        .expect("compile filters");
+    let options = LocalCopyOptions::default().filters(Some(filters));
+
+    plan.execute_with_options(LocalCopyExecution::Apply, options)
+        .expect("copy succeeds");
+
+    let target_root = dest.join("source");
+    assert!(
+        !target_root.join("build").exists(),
+        "the build directory is excluded"
+    );
+    let file = target_root.join("sub").join("build");
+    assert!(file.is_file(), "a regular file named build is still copied");
+    assert_eq!(fs::read(file).expect("read build file"), b"script");
+}
+
 #[test]
 fn execute_prunes_empty_directories_when_enabled() {
     let temp = tempdir().expect("tempdir");
@@ -1050,6 +1050,8 @@ include!("execute_super.rs");
 include!("execute_archive.rs");
 include!("execute_ignore_errors.rs");
 include!("execute_checksum_seed.rs");
+include!("execute_checksum_cache.rs");
+include!("execute_checksum_xattr.rs");
 include!("timeout_handling.rs");
 include!("execute_contimeout.rs");
 include!("execute_log_file.rs");
@@ -1059,3 +1061,7 @@ include!("execute_direct_write.rs");
 include!("execute_dry_run.rs");
 include!("execute_xxh64_dedup.rs");
 include!("files_from_vanished.rs");
+include!("execute_open_noatime.rs");
+include!("execute_fileflags.rs");
+include!("execute_file_hooks.rs");
+include!("execute_copy_buffer_size.rs");
@@ -90,7 +90,7 @@ fn permission_read_denied_single_file() {
 }
 
 #[test]
-fn permission_read_denied_returns_io_error_with_multiple_files() {
+fn permission_read_denied_with_multiple_files_copies_rest_and_reports_partial() {
     if is_root() {
         return; // Skip: root bypasses permission checks
     }
@@ -117,31 +117,70 @@ fn permission_read_denied_returns_io_error_with_multiple_files() {
     // Restore permissions for cleanup
     restore_permissions(&unreadable, 0o644);
 
-    // Current implementation returns an error when permission denied occurs
-    // This matches rsync behavior where errors cause partial transfer exit code
-    match result {
-        Ok(_report) => {
-            // If it succeeds, the readable file should have been copied
-            // and unreadable file should be missing
-            assert!(
-                !dest_root.join("unreadable.txt").exists(),
-                "unreadable file should not be copied"
-            );
-        }
-        Err(error) => {
-            // Error is expected for permission denied
-            match error.kind() {
-                LocalCopyErrorKind::Io { source, .. } => {
-                    assert_eq!(
-                        source.kind(),
-                        io::ErrorKind::PermissionDenied,
-                        "expected PermissionDenied error"
-                    );
-                }
-                other => panic!("unexpected error kind: {other:?}"),
-            }
-        }
+    // The unreadable file is reported where it fails and skipped; the run
+    // finishes with a partial-transfer error rather than the first I/O error.
+    let error = result.expect_err("unreadable entry should make the run partial");
+    assert!(
+        matches!(error.kind(), LocalCopyErrorKind::PartialTransfer),
+        "expected PartialTransfer, got {:?}",
+        error.kind()
+    );
+    assert_eq!(error.exit_code(), 23);
+    assert_eq!(
+        fs::read(dest_root.join("readable.txt")).expect("readable copied"),
+        b"can read this"
+    );
+    assert!(
+        !dest_root.join("unreadable.txt").exists(),
+        "unreadable file should not be copied"
+    );
+}
+
+#[test]
+fn permission_read_denied_on_several_files_continues_past_each() {
+    if is_root() {
+        return; // Skip: root bypasses permission checks
     }
+
+    let temp = tempdir().expect("tempdir");
+    let source_root = temp.path().join("source");
+    let nested = source_root.join("nested");
+    fs::create_dir_all(&nested).expect("create nested");
+
+    fs::write(source_root.join("a.txt"), b"a").expect("write a");
+    fs::write(nested.join("b.txt"), b"b").expect("write b");
+    fs::write(source_root.join("z.txt"), b"z").expect("write z");
+    let first = create_unreadable_file(&source_root, "m.txt", b"secret");
+    let second = create_unreadable_file(&nested, "c.txt", b"secret");
+
+    let dest_root = temp.path().join("dest");
+    fs::create_dir_all(&dest_root).expect("create dest");
+
+    let mut source_operand = source_root.clone().into_os_string();
+    source_operand.push(std::path::MAIN_SEPARATOR.to_string());
+    let operands = vec![source_operand, dest_root.clone().into_os_string()];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+
+    let result = plan.execute();
+
+    restore_permissions(&first, 0o644);
+    restore_permissions(&second, 0o644);
+
+    let error = result.expect_err("unreadable entries should make the run partial");
+    assert!(
+        matches!(error.kind(), LocalCopyErrorKind::PartialTransfer),
+        "expected PartialTransfer, got {:?}",
+        error.kind()
+    );
+    assert_eq!(error.exit_code(), 23);
+    assert_eq!(fs::read(dest_root.join("a.txt")).expect("a copied"), b"a");
+    assert_eq!(
+        fs::read(dest_root.join("nested/b.txt")).expect("b copied"),
+        b"b"
+    );
+    assert_eq!(fs::read(dest_root.join("z.txt")).expect("z copied"), b"z");
+    assert!(!dest_root.join("m.txt").exists());
+    assert!(!dest_root.join("nested/c.txt").exists());
 }
 
 #[test]
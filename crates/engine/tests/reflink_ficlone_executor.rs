@@ -8,13 +8,25 @@
 //! dispatches through, so a positive probe guarantees the executor path is
 //! exercised - a negative probe (FICLONE returns EOPNOTSUPP / EXDEV) means the
 //! mount can't satisfy the test, not that the wiring is broken.
+//!
+//! The `--copy-dest` tests cover both outcomes: on a CoW mount the
+//! reconstructed file must share its extents with the basis (checked through
+//! `FS_IOC_FIEMAP`), and on any other mount the same run must fall back to a
+//! plain copy with identical bytes.
 
 #![cfg(target_os = "linux")]
 
 use std::fs;
 use std::io::Write;
+use std::os::unix::fs::MetadataExt;
+use std::os::unix::io::AsRawFd;
+use std::path::Path;
+use std::time::{Duration, UNIX_EPOCH};
 
-use engine::local_copy::{LocalCopyExecution, LocalCopyOptions, LocalCopyPlan};
+use engine::local_copy::{
+    LocalCopyExecution, LocalCopyOptions, LocalCopyPlan, LocalCopySummary, ReferenceDirectory,
+    ReferenceDirectoryKind,
+};
 use tempfile::tempdir;
 
 fn detect_reflink_support(dir: &std::path::Path) -> bool {
@@ -38,7 +50,6 @@ fn detect_reflink_support(dir: &std::path::Path) -> bool {
 /// but we CAN prove the clone produced byte-identical content with O(1)
 /// behaviour by clamping the run to <1s on a 1 MiB file.
 fn file_blocks(p: &std::path::Path) -> u64 {
-    use std::os::unix::fs::MetadataExt;
     fs::metadata(p).map(|m| m.blocks()).unwrap_or(0)
 }
 
@@ -156,3 +167,195 @@ fn executor_uses_ficlone_for_plain_archive_without_xattrs() {
         summary.copy_method_breakdown()
     );
 }
+
+/// `FS_IOC_FIEMAP` - `_IOWR('f', 11, struct fiemap)`.
+const FS_IOC_FIEMAP: u64 = 0xC020_660B;
+const FIEMAP_FLAG_SYNC: u32 = 0x0000_0001;
+const FIEMAP_EXTENT_LAST: u32 = 0x0000_0001;
+const FIEMAP_EXTENT_SHARED: u32 = 0x0000_2000;
+const FIEMAP_MAX_EXTENTS: usize = 32;
+
+/// `struct fiemap_extent` from `<linux/fiemap.h>`.
+#[repr(C)]
+#[derive(Clone, Copy, Default)]
+#[allow(dead_code)] // Laid out for the kernel; only the flags are read back.
+struct FiemapExtent {
+    fe_logical: u64,
+    fe_physical: u64,
+    fe_length: u64,
+    fe_reserved64: [u64; 2],
+    fe_flags: u32,
+    fe_reserved: [u32; 3],
+}
+
+/// `struct fiemap` followed by a fixed-size extent array.
+#[repr(C)]
+#[derive(Default)]
+#[allow(dead_code)]
+struct Fiemap {
+    fm_start: u64,
+    fm_length: u64,
+    fm_flags: u32,
+    fm_mapped_extents: u32,
+    fm_extent_count: u32,
+    fm_reserved: u32,
+    fm_extents: [FiemapExtent; FIEMAP_MAX_EXTENTS],
+}
+
+/// Returns whether every extent of `path` carries `FIEMAP_EXTENT_SHARED`, or
+/// `None` when the filesystem does not answer FIEMAP for it.
+fn all_extents_shared(path: &Path) -> Option<bool> {
+    let file = fs::File::open(path).ok()?;
+    let mut map = Fiemap {
+        fm_length: u64::MAX,
+        fm_flags: FIEMAP_FLAG_SYNC,
+        fm_extent_count: FIEMAP_MAX_EXTENTS as u32,
+        ..Fiemap::default()
+    };
+    // SAFETY: `map` is a correctly laid out `struct fiemap` with room for
+    // `fm_extent_count` extents, and the fd stays open for the call.
+    let ret = unsafe {
+        libc::ioctl(
+            file.as_raw_fd(),
+            FS_IOC_FIEMAP as _,
+            &mut map as *mut Fiemap,
+        )
+    };
+    if ret != 0 || map.fm_mapped_extents == 0 {
+        return None;
+    }
+    let extents = &map.fm_extents[..map.fm_mapped_extents as usize];
+    if extents
+        .last()
+        .is_none_or(|e| e.fe_flags & FIEMAP_EXTENT_LAST == 0)
+    {
+        // More extents than the buffer holds; the sample is not conclusive.
+        return None;
+    }
+    Some(
+        extents
+            .iter()
+            .all(|e| e.fe_flags & FIEMAP_EXTENT_SHARED != 0),
+    )
+}
+
+/// Builds `src/file.bin` and an identical `basis/file.bin` with the same
+/// mtime, then copies `src/` into a fresh `dest/` with `basis/` as a
+/// `--copy-dest` directory. Returns the destination file and the summary.
+fn run_copy_dest(root: &Path, payload: &[u8]) -> (std::path::PathBuf, LocalCopySummary) {
+    let src = root.join("src");
+    let basis = root.join("basis");
+    let dest = root.join("dest");
+    for dir in [&src, &basis, &dest] {
+        fs::create_dir_all(dir).expect("create dir");
+    }
+    let mtime = UNIX_EPOCH + Duration::from_secs(1_700_000_000);
+    for dir in [&src, &basis] {
+        let path = dir.join("file.bin");
+        fs::write(&path, payload).expect("write payload");
+        fs::File::options()
+            .write(true)
+            .open(&path)
+            .and_then(|f| f.set_modified(mtime))
+            .expect("set mtime");
+    }
+
+    let operands = vec![
+        src.join("file.bin").into_os_string(),
+        dest.join("file.bin").into_os_string(),
+    ];
+    let plan = LocalCopyPlan::from_operands(&operands).expect("plan");
+    let options = LocalCopyOptions::default()
+        .times(true)
+        .extend_reference_directories([ReferenceDirectory::new(
+            ReferenceDirectoryKind::Copy,
+            &basis,
+        )]);
+    let summary = plan
+        .execute_with_options(LocalCopyExecution::Apply, options)
+        .expect("copy succeeds");
+    (dest.join("file.bin"), summary)
+}
+
+fn used_ficlone(summary: &LocalCopySummary) -> bool {
+    summary
+        .copy_method_breakdown()
+        .iter()
+        .any(|(label, count)| label.starts_with("FICLONE") && *count > 0)
+}
+
+#[test]
+fn copy_dest_basis_is_reflinked_on_cow_fs() {
+    let dir = tempdir().expect("tempdir");
+    if !detect_reflink_support(dir.path()) {
+        eprintln!(
+            "skipping copy-dest reflink test - {:?} is not a reflink-capable filesystem",
+            dir.path()
+        );
+        return;
+    }
+
+    let payload: Vec<u8> = (0..512u32 * 1024).map(|i| (i % 251) as u8).collect();
+    let (dest_file, summary) = run_copy_dest(dir.path(), &payload);
+    let basis_file = dir.path().join("basis/file.bin");
+
+    assert_eq!(fs::read(&dest_file).expect("read dest"), payload);
+    assert!(
+        used_ficlone(&summary),
+        "copy-dest reconstruction on a CoW fs must clone the basis, got methods {:?}",
+        summary.copy_method_breakdown()
+    );
+    let dest_meta = fs::metadata(&dest_file).expect("dest metadata");
+    let basis_meta = fs::metadata(&basis_file).expect("basis metadata");
+    assert_ne!(
+        dest_meta.ino(),
+        basis_meta.ino(),
+        "a reflink is a separate inode, not a hard link"
+    );
+    assert_eq!(dest_meta.mtime(), 1_700_000_000, "source mtime applied");
+
+    match (
+        all_extents_shared(&dest_file),
+        all_extents_shared(&basis_file),
+    ) {
+        (Some(dest_shared), Some(basis_shared)) => {
+            assert!(dest_shared, "destination extents must be shared");
+            assert!(basis_shared, "basis extents must be shared");
+        }
+        _ => eprintln!(
+            "FIEMAP unavailable on {:?}; extent sharing not checked",
+            dir.path()
+        ),
+    }
+}
+
+#[test]
+fn copy_dest_falls_back_to_plain_copy_without_reflink() {
+    let dir = tempdir().expect("tempdir");
+    if detect_reflink_support(dir.path()) {
+        eprintln!(
+            "skipping copy-dest fallback test - {:?} supports reflinks",
+            dir.path()
+        );
+        return;
+    }
+
+    let payload: Vec<u8> = (0..512u32 * 1024).map(|i| (i % 251) as u8).collect();
+    let (dest_file, summary) = run_copy_dest(dir.path(), &payload);
+
+    assert_eq!(fs::read(&dest_file).expect("read dest"), payload);
+    assert_eq!(summary.files_copied(), 1);
+    assert!(
+        !used_ficlone(&summary),
+        "FICLONE cannot succeed on a non-CoW fs, got methods {:?}",
+        summary.copy_method_breakdown()
+    );
+    let dest_meta = fs::metadata(&dest_file).expect("dest metadata");
+    let basis_meta = fs::metadata(dir.path().join("basis/file.bin")).expect("basis metadata");
+    assert_ne!(
+        dest_meta.ino(),
+        basis_meta.ino(),
+        "copy-dest never hard-links"
+    );
+    assert_eq!(dest_meta.mtime(), 1_700_000_000, "source mtime applied");
+}
@@ -0,0 +1,171 @@
+//! Benchmark for the framed sendfile path used by uncompressed whole-file
+//! sends.
+//!
+//! Both strategies emit the same byte stream onto a loopback TCP socket: one
+//! 8-byte frame header (`MSG_DATA` header + token length) per 32 KiB run of
+//! file data, the shape the sender puts on the wire for literal tokens.
+//!
+//! - `buffered_write` - `read(2)` 256 KiB at a time, then `write_all(2)` of
+//!   header + data per 32 KiB token. Mirrors the buffered sender path.
+//! - `send_framed_file_range` - header via `send(MSG_MORE)`, data via
+//!   `sendfile(2)` through [`fast_io::sendfile::send_framed_file_range`].
+//!
+//! Cross-platform: the meaningful code is gated to Linux. On other targets
+//! the bench compiles to an empty criterion main so that
+//! `cargo bench -p fast_io --bench sendfile_framed` still builds.
+//!
+//! Run with: `cargo bench -p fast_io --bench sendfile_framed`
+
+use criterion::{Criterion, criterion_group, criterion_main};
+
+#[cfg(target_os = "linux")]
+fn bench_sendfile_framed(c: &mut Criterion) {
+    use std::fs::File;
+    use std::io::{Read, Write};
+    use std::net::{TcpListener, TcpStream};
+    use std::os::fd::AsRawFd;
+    use std::thread;
+
+    use criterion::{BenchmarkId, Throughput};
+    use std::hint::black_box;
+    use tempfile::NamedTempFile;
+
+    /// Payload sizes exercised by the bench: the sender's zero-copy floor,
+    /// a typical medium file, and a large file that dwarfs socket buffers.
+    const SIZES: &[(&str, usize)] = &[
+        ("256KB", 256 * 1024),
+        ("4MB", 4 * 1024 * 1024),
+        ("64MB", 64 * 1024 * 1024),
+    ];
+
+    /// Literal token size on the wire (`CHUNK_SIZE` in the protocol crate).
+    const TOKEN_SIZE: usize = 32 * 1024;
+
+    /// Read size of the buffered sender path.
+    const READ_SIZE: usize = 256 * 1024;
+
+    fn create_payload(size: usize) -> NamedTempFile {
+        let mut file = NamedTempFile::new().expect("create temp file");
+        let chunk: Vec<u8> = (0..READ_SIZE).map(|i| (i % 251) as u8).collect();
+        let mut remaining = size;
+        while remaining > 0 {
+            let n = remaining.min(READ_SIZE);
+            file.write_all(&chunk[..n]).expect("write payload");
+            remaining -= n;
+        }
+        file.flush().expect("flush payload");
+        file
+    }
+
+    fn frame_header(len: usize) -> [u8; 8] {
+        // MSG_DATA (0) + MPLEX_BASE (7) in the high byte, payload length below.
+        let mux = ((7u32 << 24) | (4 + len) as u32).to_le_bytes();
+        let mut header = [0u8; 8];
+        header[..4].copy_from_slice(&mux);
+        header[4..].copy_from_slice(&(len as i32).to_le_bytes());
+        header
+    }
+
+    /// Connects a loopback TCP pair and drains the accepted end on a thread.
+    fn make_drained_socket() -> (TcpStream, thread::JoinHandle<()>) {
+        let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+        let client = TcpStream::connect(listener.local_addr().expect("addr")).expect("connect");
+        let (mut server, _) = listener.accept().expect("accept");
+        let drain = thread::spawn(move || {
+            let mut sink = vec![0u8; READ_SIZE];
+            loop {
+                match server.read(&mut sink) {
+                    Ok(0) => break,
+                    Ok(_) => continue,
+                    Err(e) if e.kind() == std::io::ErrorKind::Interrupted => continue,
+                    Err(_) => break,
+                }
+            }
+        });
+        (client, drain)
+    }
+
+    fn run_buffered(payload: &NamedTempFile, size: usize, mut socket: TcpStream) {
+        let mut src = File::open(payload.path()).expect("open payload");
+        let mut buffer = vec![0u8; 8 + READ_SIZE];
+        let mut remaining = size;
+        while remaining > 0 {
+            let to_read = remaining.min(READ_SIZE);
+            src.read_exact(&mut buffer[8..8 + to_read])
+                .expect("read payload");
+            let mut off = 0;
+            while off < to_read {
+                let chunk = (to_read - off).min(TOKEN_SIZE);
+                buffer[off..off + 8].copy_from_slice(&frame_header(chunk));
+                socket
+                    .write_all(&buffer[off..off + 8 + chunk])
+                    .expect("write_all");
+                off += chunk;
+            }
+            remaining -= to_read;
+        }
+        black_box(size);
+    }
+
+    fn run_sendfile(payload: &NamedTempFile, size: usize, socket: TcpStream) {
+        let src = File::open(payload.path()).expect("open payload");
+        let fd = socket.as_raw_fd();
+        let mut offset = 0;
+        while offset < size {
+            let chunk = (size - offset).min(TOKEN_SIZE);
+            fast_io::sendfile::send_framed_file_range(
+                fd,
+                &frame_header(chunk),
+                &src,
+                offset as u64,
+                chunk,
+            )
+            .expect("send_framed_file_range");
+            offset += chunk;
+        }
+        black_box(size);
+    }
+
+    let mut group = c.benchmark_group("sendfile_framed");
+    group.sample_size(10);
+
+    for &(label, size) in SIZES {
+        let payload = create_payload(size);
+        group.throughput(Throughput::Bytes(size as u64));
+        group.bench_with_input(BenchmarkId::new("buffered_write", label), &(), |b, _| {
+            b.iter_with_setup(make_drained_socket, |(socket, drain)| {
+                run_buffered(&payload, size, socket);
+                drain.join().expect("drain thread");
+            });
+        });
+        group.bench_with_input(
+            BenchmarkId::new("send_framed_file_range", label),
+            &(),
+            |b, _| {
+                b.iter_with_setup(make_drained_socket, |(socket, drain)| {
+                    run_sendfile(&payload, size, socket);
+                    drain.join().expect("drain thread");
+                });
+            },
+        );
+    }
+
+    group.finish();
+}
+
+#[cfg(not(target_os = "linux"))]
+fn bench_sendfile_framed(c: &mut Criterion) {
+    use std::hint::black_box;
+
+    // The framed sendfile path is Linux-only. Define an empty group on other
+    // targets so the criterion harness still emits a report.
+    let mut group = c.benchmark_group("sendfile_framed");
+    group.sample_size(10);
+    group.bench_function("noop_non_linux", |b| {
+        b.iter(|| black_box(0u64));
+    });
+    group.finish();
+}
+
+criterion_group!(sendfile_framed, bench_sendfile_framed);
+criterion_main!(sendfile_framed);
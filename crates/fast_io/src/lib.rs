@@ -105,6 +105,9 @@ pub mod kernel_version;
 /// the SEC-1.l audit) and do not depend on this module.
 #[cfg(unix)]
 pub mod linux_capabilities;
+/// Anchored aliases for paths of `PATH_MAX` bytes or more, standing in for
+/// upstream rsync's `chdir()` into the transfer root.
+pub mod long_path;
 /// Receiver-side socket-read seam: the `NetReader` abstraction and the
 /// `for_socket` factory that selects an accelerated socket reader by policy
 /// with a behaviour-preserving standard-read fallback.
@@ -329,6 +332,7 @@ pub use container::{
 pub use copy_basis_range::{
     COPY_BASIS_RANGE_MIN_BYTES, copy_basis_range, copy_file_range_supported,
 };
+pub use long_path::{ReachablePath, reach_long_path};
 pub use page_aligned::{PageAlignedBuffer, page_size, round_up_to_page};
 pub use parallel::{ParallelExecutor, ParallelResult};
 pub use platform_copy::{
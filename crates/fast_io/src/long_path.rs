@@ -0,0 +1,247 @@
+//! Reaching paths of `PATH_MAX` bytes or more.
+//!
+//! Path-based syscalls reject a path of `PATH_MAX` bytes or more with
+//! `ENAMETOOLONG`, however short each of its components is. Upstream rsync
+//! sidesteps the limit by `chdir()`ing into the transfer root
+//! (`util1.c:change_dir()`) and handing the kernel names relative to it. A
+//! process-wide working directory does not suit a multi-threaded transfer,
+//! so [`reach_long_path`] does the per-path equivalent: it opens the path's
+//! existing directories one component at a time with `openat(2)` and returns
+//! an alias of the form `/proc/self/fd/<fd>/<tail>`. The kernel resolves the
+//! `/proc` magic link to the open directory, so every path-based call made on
+//! the alias - or on a name joined below it - lands on the original location
+//! while the string handed to the kernel stays short.
+//!
+//! # Platform behaviour
+//!
+//! - Linux with procfs mounted: long paths are anchored as described above.
+//! - Everywhere else, and for any path already short enough, the path is
+//!   returned unchanged and the kernel reports a long one as it always has.
+
+use std::io;
+use std::path::{Path, PathBuf};
+#[cfg(target_os = "linux")]
+use std::sync::Arc;
+
+/// Paths at least this long are anchored. The kernel's limit includes the
+/// terminating NUL, so a path of exactly `PATH_MAX` bytes already fails.
+#[cfg(target_os = "linux")]
+const PATH_MAX_BYTES: usize = libc::PATH_MAX as usize;
+
+/// A path the kernel accepts, standing in for one that may be too long.
+///
+/// [`path`](Self::path) is either the original path or its
+/// `/proc/self/fd/<fd>/<tail>` alias. An alias is only valid while the value
+/// (or a clone) is alive, because the anchoring directory descriptor closes
+/// with the last clone. Equality compares the usable path only.
+#[derive(Clone, Debug)]
+pub struct ReachablePath {
+    path: PathBuf,
+    #[cfg(target_os = "linux")]
+    anchor: Option<Arc<std::os::fd::OwnedFd>>,
+}
+
+impl ReachablePath {
+    fn unchanged(path: &Path) -> Self {
+        Self {
+            path: path.to_path_buf(),
+            #[cfg(target_os = "linux")]
+            anchor: None,
+        }
+    }
+
+    /// The path to hand to path-based syscalls.
+    #[must_use]
+    pub fn path(&self) -> &Path {
+        &self.path
+    }
+
+    /// Reports whether [`path`](Self::path) is an alias below an anchoring
+    /// directory rather than the original path.
+    #[must_use]
+    pub fn is_anchored(&self) -> bool {
+        #[cfg(target_os = "linux")]
+        {
+            self.anchor.is_some()
+        }
+        #[cfg(not(target_os = "linux"))]
+        {
+            false
+        }
+    }
+}
+
+impl PartialEq for ReachablePath {
+    fn eq(&self, other: &Self) -> bool {
+        self.path == other.path
+    }
+}
+
+impl Eq for ReachablePath {}
+
+/// Returns a form of `path` that path-based syscalls accept.
+///
+/// A path shorter than `PATH_MAX` comes back unchanged. A longer one is
+/// anchored at its deepest existing directory: the leaf itself is never
+/// opened, so it may be a file, a symlink or not exist yet, and components
+/// past the first missing directory stay in the alias's tail.
+///
+/// # Errors
+///
+/// - Any error other than `ENOENT` / `ENOTDIR` from opening a directory
+///   component (for example `EACCES` on a directory without search
+///   permission).
+/// - `ENAMETOOLONG` when even the alias is `PATH_MAX` bytes or more, which
+///   happens when the missing tail of the path is itself that long.
+pub fn reach_long_path(path: &Path) -> io::Result<ReachablePath> {
+    imp::reach_long_path(path)
+}
+
+#[cfg(target_os = "linux")]
+mod imp {
+    use super::*;
+    use std::ffi::OsStr;
+    use std::os::fd::{AsFd, AsRawFd, OwnedFd};
+    use std::path::Component;
+
+    use rustix::fs::{CWD, Mode, OFlags};
+
+    const PROC_SELF_FD: &str = "/proc/self/fd";
+
+    pub(super) fn reach_long_path(path: &Path) -> io::Result<ReachablePath> {
+        if path.as_os_str().len() < PATH_MAX_BYTES || !Path::new(PROC_SELF_FD).is_dir() {
+            return Ok(ReachablePath::unchanged(path));
+        }
+
+        let components: Vec<Component<'_>> = path.components().collect();
+        let Some((_, parents)) = components.split_last() else {
+            return Ok(ReachablePath::unchanged(path));
+        };
+
+        let start = if path.has_root() { "/" } else { "." };
+        let mut dir = open_dir(CWD, OsStr::new(start))?;
+        let mut consumed = parents.len();
+        for (index, component) in parents.iter().enumerate() {
+            let name = match component {
+                Component::RootDir | Component::CurDir | Component::Prefix(_) => continue,
+                Component::ParentDir => OsStr::new(".."),
+                Component::Normal(name) => name,
+            };
+            match open_dir(dir.as_fd(), name) {
+                Ok(child) => dir = child,
+                Err(error)
+                    if matches!(
+                        error.raw_os_error(),
+                        Some(libc::ENOENT) | Some(libc::ENOTDIR)
+                    ) =>
+                {
+                    consumed = index;
+                    break;
+                }
+                Err(error) => return Err(error),
+            }
+        }
+
+        let mut alias = PathBuf::from(format!("{PROC_SELF_FD}/{}", dir.as_raw_fd()));
+        for component in &components[consumed..] {
+            match component {
+                Component::RootDir | Component::CurDir | Component::Prefix(_) => {}
+                other => alias.push(other.as_os_str()),
+            }
+        }
+        if alias.as_os_str().len() >= PATH_MAX_BYTES {
+            return Err(io::Error::from_raw_os_error(libc::ENAMETOOLONG));
+        }
+        Ok(ReachablePath {
+            path: alias,
+            anchor: Some(Arc::new(dir)),
+        })
+    }
+
+    /// `openat(O_PATH | O_DIRECTORY | O_CLOEXEC)`: enough to resolve names
+    /// below the directory without needing read permission on it.
+    fn open_dir<Fd: AsFd>(parent: Fd, name: &OsStr) -> io::Result<OwnedFd> {
+        let flags = OFlags::PATH | OFlags::DIRECTORY | OFlags::CLOEXEC;
+        rustix::fs::openat(parent, name, flags, Mode::empty())
+            .map_err(|errno| io::Error::from_raw_os_error(errno.raw_os_error()))
+    }
+}
+
+#[cfg(not(target_os = "linux"))]
+mod imp {
+    use super::*;
+
+    pub(super) fn reach_long_path(path: &Path) -> io::Result<ReachablePath> {
+        Ok(ReachablePath::unchanged(path))
+    }
+}
+
+#[cfg(all(test, target_os = "linux"))]
+mod tests {
+    use super::*;
+    use std::fs;
+
+    /// Builds `root/dddd.../dddd...` until the absolute path passes
+    /// `PATH_MAX`, creating each level through an alias so no syscall ever
+    /// sees the long path.
+    fn deep_dir(root: &Path) -> PathBuf {
+        let name = "d".repeat(200);
+        let mut dir = root.to_path_buf();
+        while dir.as_os_str().len() < PATH_MAX_BYTES + 200 {
+            dir.push(&name);
+            let reachable = reach_long_path(&dir).expect("reach new level");
+            fs::create_dir(reachable.path()).expect("create level");
+        }
+        dir
+    }
+
+    #[test]
+    fn short_path_is_unchanged() {
+        let reachable = reach_long_path(Path::new("/tmp/short")).unwrap();
+        assert_eq!(reachable.path(), Path::new("/tmp/short"));
+        assert!(!reachable.is_anchored());
+    }
+
+    #[test]
+    fn long_path_is_reached_through_an_anchor() {
+        let tmp = tempfile::tempdir().expect("tempdir");
+        let dir = deep_dir(tmp.path());
+        let file = dir.join("leaf.txt");
+        let error = fs::write(&file, b"x").expect_err("kernel rejects the long path");
+        assert_eq!(error.raw_os_error(), Some(libc::ENAMETOOLONG));
+
+        let reachable = reach_long_path(&file).expect("reach leaf");
+        assert!(reachable.is_anchored());
+        assert!(reachable.path().as_os_str().len() < PATH_MAX_BYTES);
+        fs::write(reachable.path(), b"payload").expect("write via alias");
+
+        let again = reach_long_path(&file).expect("reach leaf again");
+        assert_eq!(fs::read(again.path()).unwrap(), b"payload");
+        assert!(
+            fs::metadata(reach_long_path(&dir).unwrap().path())
+                .unwrap()
+                .is_dir()
+        );
+    }
+
+    #[test]
+    fn missing_components_stay_in_the_tail() {
+        let tmp = tempfile::tempdir().expect("tempdir");
+        let dir = deep_dir(tmp.path());
+        let missing = dir.join("new").join("leaf");
+        let reachable = reach_long_path(&missing).expect("reach missing leaf");
+        assert!(reachable.path().ends_with("new/leaf"));
+        fs::create_dir(reachable.path().parent().unwrap()).expect("create via alias");
+        fs::write(reachable.path(), b"x").expect("write via alias");
+    }
+
+    #[test]
+    fn alias_stays_valid_while_a_clone_lives() {
+        let tmp = tempfile::tempdir().expect("tempdir");
+        let dir = deep_dir(tmp.path());
+        let reachable = reach_long_path(&dir.join("f")).unwrap();
+        let clone = reachable.clone();
+        drop(reachable);
+        fs::write(clone.path(), b"x").expect("alias outlives the original");
+    }
+}
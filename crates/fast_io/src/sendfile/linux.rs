@@ -76,3 +76,139 @@ pub(super) fn try_sendfile(source: &File, dest_fd: i32, length: u64) -> io::Resu
 
     Ok(total)
 }
+
+/// Writes `header` and then `length` bytes of `source` starting at `offset`.
+///
+/// The header goes out with `send(MSG_MORE)` so the kernel coalesces it with
+/// the payload instead of emitting a tiny segment on a `TCP_NODELAY` socket;
+/// a non-socket `dest_fd` (`ENOTSOCK`) takes a plain `write`. The payload is
+/// moved with `sendfile(2)` through an explicit offset, so the source file
+/// position is left untouched. When the kernel refuses `sendfile` for this
+/// file (`EINVAL`/`ENOSYS`/`EOPNOTSUPP`) the remainder is copied with
+/// `pread` + `write` instead.
+///
+/// A zero-length `sendfile` before `length` bytes have moved means the source
+/// shrank underneath us and is reported as `UnexpectedEof`: the header has
+/// already promised the full payload, so the caller cannot recover the frame.
+pub(super) fn send_framed_range(
+    dest_fd: i32,
+    header: &[u8],
+    source: &File,
+    offset: u64,
+    length: usize,
+) -> io::Result<()> {
+    use std::os::fd::AsRawFd;
+
+    send_all_more(dest_fd, header)?;
+
+    let src_fd = source.as_raw_fd();
+    let mut file_offset = libc::off_t::try_from(offset)
+        .map_err(|_| io::Error::new(io::ErrorKind::InvalidInput, "sendfile offset overflow"))?;
+    let mut remaining = length;
+
+    while remaining > 0 {
+        let chunk = remaining.min(SENDFILE_CHUNK_SIZE);
+        // SAFETY: both descriptors are valid for the duration of the call
+        // (`source` is borrowed, `dest_fd` is owned by the caller) and
+        // `file_offset` is a live `off_t` the kernel advances in place.
+        let result = unsafe { libc::sendfile(dest_fd, src_fd, &mut file_offset, chunk) };
+
+        if result < 0 {
+            let error = io::Error::last_os_error();
+            match error.raw_os_error() {
+                Some(libc::EINTR) => continue,
+                Some(libc::EINVAL | libc::ENOSYS | libc::EOPNOTSUPP) => {
+                    return write_range_via_pread(dest_fd, source, file_offset as u64, remaining);
+                }
+                _ => return Err(error),
+            }
+        }
+        if result == 0 {
+            return Err(io::Error::new(
+                io::ErrorKind::UnexpectedEof,
+                "source file shrank during sendfile",
+            ));
+        }
+        remaining -= result as usize;
+    }
+
+    Ok(())
+}
+
+/// Sends `buf` in full, hinting `MSG_MORE`; falls back to `write` for
+/// non-socket descriptors.
+fn send_all_more(dest_fd: i32, buf: &[u8]) -> io::Result<()> {
+    let mut sent = 0;
+    while sent < buf.len() {
+        let rest = &buf[sent..];
+        // SAFETY: `rest` is a valid initialised slice and `dest_fd` is owned
+        // by the caller for the duration of the call.
+        let result = unsafe {
+            libc::send(
+                dest_fd,
+                rest.as_ptr().cast::<libc::c_void>(),
+                rest.len(),
+                libc::MSG_MORE,
+            )
+        };
+        if result < 0 {
+            let error = io::Error::last_os_error();
+            match error.raw_os_error() {
+                Some(libc::EINTR) => continue,
+                Some(libc::ENOTSOCK) => return write_all_fd(dest_fd, rest),
+                _ => return Err(error),
+            }
+        }
+        sent += result as usize;
+    }
+    Ok(())
+}
+
+/// Copies `length` bytes from `source` at `offset` to `dest_fd` through a
+/// userspace buffer, without moving the source file position.
+fn write_range_via_pread(
+    dest_fd: i32,
+    source: &File,
+    mut offset: u64,
+    mut remaining: usize,
+) -> io::Result<()> {
+    use std::os::unix::fs::FileExt;
+
+    let mut buf = vec![0u8; remaining.min(256 * 1024)];
+    while remaining > 0 {
+        let want = remaining.min(buf.len());
+        let n = source.read_at(&mut buf[..want], offset)?;
+        if n == 0 {
+            return Err(io::Error::new(
+                io::ErrorKind::UnexpectedEof,
+                "source file shrank during sendfile fallback",
+            ));
+        }
+        write_all_fd(dest_fd, &buf[..n])?;
+        offset += n as u64;
+        remaining -= n;
+    }
+    Ok(())
+}
+
+/// `write(2)` loop that drains `buf` into `dest_fd`.
+fn write_all_fd(dest_fd: i32, mut buf: &[u8]) -> io::Result<()> {
+    while !buf.is_empty() {
+        // SAFETY: `buf` is a valid initialised slice and `dest_fd` is owned by
+        // the caller for the duration of the call.
+        let result =
+            unsafe { libc::write(dest_fd, buf.as_ptr().cast::<libc::c_void>(), buf.len()) };
+        if result < 0 {
+            let error = io::Error::last_os_error();
+            if error.kind() == io::ErrorKind::Interrupted {
+                continue;
+            }
+            return Err(error);
+        }
+        if result == 0 {
+            return Err(io::Error::from(io::ErrorKind::WriteZero));
+        }
+        buf = &buf[result as usize..];
+    }
+    Ok(())
+}
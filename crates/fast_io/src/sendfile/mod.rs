@@ -53,7 +53,7 @@ mod macos;
 mod fallback;
 
 #[cfg(target_os = "linux")]
-use linux::try_sendfile;
+use linux::{send_framed_range, try_sendfile};
 #[cfg(target_os = "macos")]
 use macos::try_sendfile_macos;
 
@@ -197,6 +197,32 @@ pub fn send_file_to_fd(source: &File, _dest_fd: i32, length: u64) -> io::Result<
     send_file_to_writer(source, &mut io::sink(), length)
 }
 
+/// Writes a frame `header` followed by `length` bytes of `source` read from
+/// `offset`, moving the payload with `sendfile(2)` (Linux).
+///
+/// Built for protocol framing where a short header must precede each run of
+/// file bytes: the header is sent with `MSG_MORE` so it shares a segment with
+/// the payload, and there is no size threshold - every call attempts the
+/// zero-copy path. The source file position is not used or changed. When the
+/// kernel refuses `sendfile` for the source the payload is copied with
+/// `pread` + `write`, so the bytes on `dest_fd` are identical either way.
+///
+/// # Errors
+///
+/// Returns the underlying I/O error, or `UnexpectedEof` when `source` holds
+/// fewer than `offset + length` bytes. Part of the frame may already have been
+/// written when an error is returned.
+#[cfg(target_os = "linux")]
+pub fn send_framed_file_range(
+    dest_fd: i32,
+    header: &[u8],
+    source: &File,
+    offset: u64,
+    length: usize,
+) -> io::Result<()> {
+    send_framed_range(dest_fd, header, source, offset, length)
+}
+
 /// Policy-aware variant of [`send_file_to_fd`].
 ///
 /// When `policy` is [`ZeroCopyPolicy::Disabled`](crate::ZeroCopyPolicy::Disabled),
@@ -195,6 +195,61 @@ fn test_send_file_to_fd_socketpair() {
     unsafe { libc::close(recv_fd) };
 }
 
+#[cfg(target_os = "linux")]
+#[test]
+fn test_send_framed_file_range_socket_from_offset() {
+    use std::io::Read;
+    use std::os::fd::AsRawFd;
+    use std::os::unix::net::UnixStream;
+
+    let content: Vec<u8> = (0..200_000u32).map(|i| (i % 251) as u8).collect();
+    let source = create_temp_file(&content).unwrap();
+    let (sender, mut receiver) = UnixStream::pair().unwrap();
+
+    let reader = std::thread::spawn(move || {
+        let mut received = Vec::new();
+        receiver.read_to_end(&mut received).unwrap();
+        received
+    });
+    send_framed_file_range(sender.as_raw_fd(), b"HDR!", source.as_file(), 1000, 150_000).unwrap();
+    drop(sender);
+
+    let received = reader.join().unwrap();
+    assert_eq!(&received[..4], b"HDR!");
+    assert_eq!(&received[4..], &content[1000..151_000]);
+    // The explicit offset leaves the file position where it was.
+    let mut handle = source.as_file();
+    assert_eq!(handle.stream_position().unwrap(), 0);
+}
+
+#[cfg(target_os = "linux")]
+#[test]
+fn test_send_framed_file_range_non_socket_destination() {
+    use std::os::fd::AsRawFd;
+
+    // A regular file is not a socket: the header takes the `write` fallback.
+    let content = b"framed payload for a plain file";
+    let source = create_temp_file(content).unwrap();
+    let dest = NamedTempFile::new().unwrap();
+
+    send_framed_file_range(dest.as_file().as_raw_fd(), b"[8]", source.as_file(), 7, 7).unwrap();
+
+    assert_eq!(std::fs::read(dest.path()).unwrap(), b"[8]payload");
+}
+
+#[cfg(target_os = "linux")]
+#[test]
+fn test_send_framed_file_range_short_source_is_eof() {
+    use std::os::fd::AsRawFd;
+
+    let source = create_temp_file(b"tiny").unwrap();
+    let dest = NamedTempFile::new().unwrap();
+
+    let error = send_framed_file_range(dest.as_file().as_raw_fd(), b"", source.as_file(), 0, 64)
+        .unwrap_err();
+    assert_eq!(error.kind(), io::ErrorKind::UnexpectedEof);
+}
+
 #[cfg(target_os = "linux")]
 #[test]
 fn test_send_file_to_fd_large() {
@@ -0,0 +1,95 @@
+//! Criterion benchmark: path evaluation against thousands of filter rules.
+//!
+//! A `FilterSet` with a long include/exclude list is consulted once per file
+//! during the sender's walk. A plain scan costs one `wildmatch()` per rule
+//! until the first match, so a path no rule matches walks the whole list.
+//! Rules whose last segment is a literal, a `*suffix` or a `prefix*` are
+//! grouped into a per-chain index, and a lookup only evaluates the rules that
+//! could match.
+//!
+//! # Workloads (Criterion group `large_filter_set`)
+//!
+//! - `literal_keyed/{100,1000,5000}` - a mix of bare names, `*.extN` suffixes
+//!   and `tmpN*` prefixes, all served by the index.
+//! - `glob_only/{100,1000,5000}` - the same number of `?`-bearing patterns
+//!   with no usable literal, so every lookup scans the whole chain. This is
+//!   the unindexed baseline to compare against.
+//!
+//! Each iteration evaluates every path of a 2,000-entry synthetic tree with
+//! [`FilterSet::allows_during_traversal`]. Almost none of the paths match a
+//! rule, which is the worst case for a linear scan.
+//!
+//! Run: `cargo bench -p filters --bench large_filter_set`
+
+use std::hint::black_box;
+use std::path::PathBuf;
+
+use criterion::{BenchmarkId, Criterion, Throughput, criterion_group, criterion_main};
+use filters::{FilterRule, FilterSet};
+
+/// Rule counts swept by both workloads.
+const RULE_COUNTS: [usize; 3] = [100, 1_000, 5_000];
+
+/// Number of paths evaluated per iteration.
+const PATH_COUNT: usize = 2_000;
+
+/// Exclude rules served by the index: one third each of literal names,
+/// suffixes and prefixes.
+fn literal_keyed_rules(count: usize) -> Vec<FilterRule> {
+    (0..count)
+        .map(|i| match i % 3 {
+            0 => FilterRule::exclude(format!("generated_{i}")),
+            1 => FilterRule::exclude(format!("*.ext{i}")),
+            _ => FilterRule::exclude(format!("tmp{i}*")),
+        })
+        .collect()
+}
+
+/// Exclude rules with no usable literal, evaluated for every path.
+fn glob_only_rules(count: usize) -> Vec<FilterRule> {
+    (0..count)
+        .map(|i| FilterRule::exclude(format!("?enerated_{i}")))
+        .collect()
+}
+
+/// `src/mod{n}/file{n}.rs`-style relative paths, a few of which hit a rule.
+fn tree_paths() -> Vec<PathBuf> {
+    (0..PATH_COUNT)
+        .map(|i| match i % 100 {
+            0 => PathBuf::from(format!("out/generated_{}", i % 300)),
+            1 => PathBuf::from(format!("data/blob.ext{}", i % 300)),
+            _ => PathBuf::from(format!("src/mod{}/file{i}.rs", i % 40)),
+        })
+        .collect()
+}
+
+fn bench_large_filter_set(c: &mut Criterion) {
+    let paths = tree_paths();
+    let mut group = c.benchmark_group("large_filter_set");
+    group.throughput(Throughput::Elements(paths.len() as u64));
+
+    for count in RULE_COUNTS {
+        let workloads = [
+            ("literal_keyed", literal_keyed_rules(count)),
+            ("glob_only", glob_only_rules(count)),
+        ];
+        for (name, rules) in workloads {
+            let set = FilterSet::from_rules(rules).expect("rules compile");
+            group.bench_with_input(BenchmarkId::new(name, count), &set, |b, set| {
+                b.iter(|| {
+                    let mut allowed = 0usize;
+                    for path in &paths {
+                        if set.allows_during_traversal(black_box(path), false) {
+                            allowed += 1;
+                        }
+                    }
+                    black_box(allowed)
+                });
+            });
+        }
+    }
+    group.finish();
+}
+
+criterion_group!(benches, bench_large_filter_set);
+criterion_main!(benches);
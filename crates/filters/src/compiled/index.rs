@@ -0,0 +1,310 @@
+//! Literal-key prefilter over a compiled rule chain.
+//!
+//! Evaluating a path against a long include/exclude list costs one
+//! `wildmatch()` per rule until the first match. The rules in a large list are
+//! mostly a bare name (`core`), a pure suffix (`*.o`) or a literal prefix
+//! (`tmp*`), and such a rule can only match a path that has a component equal
+//! to, ending with or starting with that literal. [`RuleIndex`] groups those
+//! rules by their literal so a lookup returns just the rules that *can* match,
+//! plus every rule without a usable literal. The caller still evaluates each
+//! candidate with its full matcher in definition order, so first-match-wins,
+//! negation, directory-only rules and descendant matchers behave exactly as a
+//! plain scan of the chain - the index only skips rules that could not match.
+
+use std::collections::HashMap;
+use std::path::Path;
+
+use super::pattern::path_match_bytes;
+use super::rule::CompiledRule;
+
+/// Chains shorter than this are scanned directly; building and probing the
+/// index costs more than the few `wildmatch()` calls it would save.
+const MIN_INDEXED_RULES: usize = 16;
+
+/// The literal a rule needs some path component to carry before any of its
+/// matchers can fire.
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub(super) enum RuleKey {
+    /// A component equal to the literal (`core`, `/build`, `src/main.c`).
+    Component(Vec<u8>),
+    /// A component ending with the literal (`*.o`).
+    Suffix(Vec<u8>),
+    /// A component starting with the literal (`tmp*`).
+    Prefix(Vec<u8>),
+    /// No literal constrains the match; the rule is a candidate for every path.
+    Any,
+}
+
+impl RuleKey {
+    /// Derives the key from a rule's normalised core pattern.
+    ///
+    /// Only patterns without `[` or `\` qualify, so splitting on `/` yields the
+    /// pattern's real segments. The final segment must be a literal,
+    /// `*literal` or `literal*`: a lone `*` never matches `/`
+    /// (lib/wildmatch.c:dowild()), so in every matcher built from the core -
+    /// direct, `**/`-prefixed and `/**` descendant alike - that segment
+    /// matches exactly one path component.
+    pub(super) fn for_core(core: &str) -> Self {
+        if core.contains(['[', '\\']) {
+            return Self::Any;
+        }
+        let last = core.rsplit('/').next().unwrap_or(core);
+        let is_literal = |text: &str| !text.is_empty() && !text.contains(['*', '?']);
+
+        if is_literal(last) {
+            Self::Component(last.as_bytes().to_vec())
+        } else if let Some(rest) = last.strip_prefix('*')
+            && is_literal(rest)
+        {
+            Self::Suffix(rest.as_bytes().to_vec())
+        } else if let Some(rest) = last.strip_suffix('*')
+            && is_literal(rest)
+        {
+            Self::Prefix(rest.as_bytes().to_vec())
+        } else {
+            Self::Any
+        }
+    }
+}
+
+/// Rule positions of one chain grouped by [`RuleKey`].
+///
+/// The default value is an unbuilt index, which makes callers scan the whole
+/// chain.
+#[derive(Debug, Default)]
+pub(crate) struct RuleIndex {
+    built: bool,
+    always: Vec<usize>,
+    components: HashMap<Vec<u8>, Vec<usize>>,
+    suffixes: HashMap<Vec<u8>, Vec<usize>>,
+    prefixes: HashMap<Vec<u8>, Vec<usize>>,
+    /// Distinct key lengths in `suffixes` / `prefixes`, ascending, so a
+    /// component is probed once per length rather than once per rule.
+    suffix_lens: Vec<usize>,
+    prefix_lens: Vec<usize>,
+}
+
+impl RuleIndex {
+    /// Indexes `rules`, or returns an unbuilt index when the chain is too short
+    /// or has no keyed rule to benefit from one.
+    pub(crate) fn new(rules: &[CompiledRule]) -> Self {
+        if rules.len() < MIN_INDEXED_RULES {
+            return Self::default();
+        }
+        let index = Self::build(rules);
+        if index.always.len() == rules.len() {
+            return Self::default();
+        }
+        index
+    }
+
+    fn build(rules: &[CompiledRule]) -> Self {
+        let mut index = Self {
+            built: true,
+            ..Self::default()
+        };
+        for (position, rule) in rules.iter().enumerate() {
+            let (map, literal) = match &rule.key {
+                RuleKey::Component(literal) => (&mut index.components, literal),
+                RuleKey::Suffix(literal) => (&mut index.suffixes, literal),
+                RuleKey::Prefix(literal) => (&mut index.prefixes, literal),
+                RuleKey::Any => {
+                    index.always.push(position);
+                    continue;
+                }
+            };
+            map.entry(literal.clone()).or_default().push(position);
+        }
+        index.suffix_lens = distinct_lengths(&index.suffixes);
+        index.prefix_lens = distinct_lengths(&index.prefixes);
+        index
+    }
+
+    /// Returns the positions of the rules that may match `path`, ascending, or
+    /// `None` when the index is unbuilt and every rule must be tried.
+    pub(crate) fn candidates(&self, path: &Path) -> Option<Vec<usize>> {
+        if !self.built {
+            return None;
+        }
+        let rendered = path_match_bytes(path);
+        let mut hits = self.always.clone();
+        for component in rendered.split(|&byte| byte == b'/') {
+            if let Some(positions) = self.components.get(component) {
+                hits.extend_from_slice(positions);
+            }
+            for &len in self
+                .suffix_lens
+                .iter()
+                .take_while(|&&len| len <= component.len())
+            {
+                if let Some(positions) = self.suffixes.get(&component[component.len() - len..]) {
+                    hits.extend_from_slice(positions);
+                }
+            }
+            for &len in self
+                .prefix_lens
+                .iter()
+                .take_while(|&&len| len <= component.len())
+            {
+                if let Some(positions) = self.prefixes.get(&component[..len]) {
+                    hits.extend_from_slice(positions);
+                }
+            }
+        }
+        hits.sort_unstable();
+        hits.dedup();
+        Some(hits)
+    }
+}
+
+fn distinct_lengths(map: &HashMap<Vec<u8>, Vec<usize>>) -> Vec<usize> {
+    let mut lengths: Vec<usize> = map.keys().map(Vec::len).collect();
+    lengths.sort_unstable();
+    lengths.dedup();
+    lengths
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::FilterRule;
+
+    fn compile(patterns: &[&str]) -> Vec<CompiledRule> {
+        patterns
+            .iter()
+            .map(|pattern| CompiledRule::new(FilterRule::exclude(*pattern)).expect("compile"))
+            .collect()
+    }
+
+    #[test]
+    fn key_for_bare_name_is_component() {
+        assert_eq!(
+            RuleKey::for_core("core"),
+            RuleKey::Component(b"core".to_vec())
+        );
+        assert_eq!(
+            RuleKey::for_core("src/main.c"),
+            RuleKey::Component(b"main.c".to_vec())
+        );
+        assert_eq!(
+            RuleKey::for_core("**/build"),
+            RuleKey::Component(b"build".to_vec())
+        );
+    }
+
+    #[test]
+    fn key_for_star_suffix_and_prefix() {
+        assert_eq!(RuleKey::for_core("*.o"), RuleKey::Suffix(b".o".to_vec()));
+        assert_eq!(
+            RuleKey::for_core("lib/*.so"),
+            RuleKey::Suffix(b".so".to_vec())
+        );
+        assert_eq!(RuleKey::for_core("tmp*"), RuleKey::Prefix(b"tmp".to_vec()));
+    }
+
+    #[test]
+    fn key_for_general_glob_is_any() {
+        for core in [
+            "*", "**", "a?c", "*.t*", "**.o", "x**", "[ab].c", "a\\*b", "dir/", "",
+        ] {
+            assert_eq!(RuleKey::for_core(core), RuleKey::Any, "{core:?}");
+        }
+    }
+
+    #[test]
+    fn negated_rule_is_always_a_candidate() {
+        let rule = FilterRule::exclude("*.o").with_negate(true);
+        assert_eq!(CompiledRule::new(rule).unwrap().key, RuleKey::Any);
+    }
+
+    #[test]
+    fn short_chain_stays_unbuilt() {
+        let rules = compile(&["*.o", "core"]);
+        assert!(
+            RuleIndex::new(&rules)
+                .candidates(Path::new("a.o"))
+                .is_none()
+        );
+    }
+
+    #[test]
+    fn chain_of_only_globs_stays_unbuilt() {
+        let patterns = vec!["a?"; MIN_INDEXED_RULES];
+        let rules = compile(&patterns);
+        assert!(RuleIndex::new(&rules).candidates(Path::new("ab")).is_none());
+    }
+
+    #[test]
+    fn candidates_keep_definition_order_and_unkeyed_rules() {
+        let rules = compile(&["*.o", "x?z", "build", "tmp*", "*.o"]);
+        let index = RuleIndex::build(&rules);
+        assert_eq!(
+            index.candidates(Path::new("build/tmpfile.o")),
+            Some(vec![0, 1, 2, 3, 4])
+        );
+        assert_eq!(index.candidates(Path::new("src/lib.rs")), Some(vec![1]));
+        assert_eq!(index.candidates(Path::new("src/a.o")), Some(vec![0, 1, 4]));
+    }
+
+    #[test]
+    fn index_never_drops_a_matching_rule() {
+        let patterns = [
+            "*.o",
+            "/build",
+            "build/",
+            "tmp*",
+            "src/*.rs",
+            "cache/",
+            "**/node_modules",
+            "a/**/b.log",
+            "*.tar.gz",
+            "x**/y",
+            "data/*",
+            "*~",
+            "/docs/*.md",
+            ".git",
+        ];
+        let paths = [
+            "main.o",
+            "src/main.o",
+            "build",
+            "build/out",
+            "deep/build/x",
+            "tmpdir",
+            "a/tmp.txt",
+            "src/lib.rs",
+            "other/src/lib.rs",
+            "cache",
+            "cache/entry",
+            "web/node_modules",
+            "node_modules/pkg",
+            "a/b.log",
+            "a/x/y/b.log",
+            "pkg.tar.gz",
+            "x/y",
+            "xa/z/y",
+            "data/file",
+            "notes~",
+            "docs/guide.md",
+            "sub/docs/guide.md",
+            ".git/HEAD",
+            "plain.txt",
+        ];
+        let rules = compile(&patterns);
+        let index = RuleIndex::build(&rules);
+        for path in paths {
+            let candidates = index.candidates(Path::new(path)).unwrap();
+            for is_dir in [false, true] {
+                for (position, rule) in rules.iter().enumerate() {
+                    let matches = rule.matches(Path::new(path), is_dir, true)
+                        || rule.matches_for_deletion(Path::new(path), is_dir, true);
+                    assert!(
+                        !matches || candidates.contains(&position),
+                        "{path:?} matches {:?} but the index skipped it",
+                        patterns[position]
+                    );
+                }
+            }
+        }
+    }
+}
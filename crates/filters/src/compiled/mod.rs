@@ -6,8 +6,10 @@
 //! - `pattern` - pattern normalisation and glob compilation
 //! - `rule` - the `CompiledRule` struct with matching and side-clearing logic
 //! - `clear` - bulk clear-rule application over rule vectors
+//! - `index` - literal-key prefilter that narrows long chains to candidate rules
 
 mod clear;
+mod index;
 mod pattern;
 mod rule;
 mod xattr;
@@ -17,6 +19,8 @@ use std::collections::HashSet;
 use crate::{FilterAction, FilterError, FilterRule};
 
 pub(crate) use clear::apply_clear_rule;
+pub(crate) use index::RuleIndex;
+use index::RuleKey;
 use pattern::{compile_patterns, normalise_pattern};
 pub(crate) use rule::CompiledRule;
 pub(crate) use xattr::CompiledXattrRule;
@@ -172,6 +176,13 @@ impl CompiledRule {
         // Anchored patterns (`/**/*`) whose stem starts with `**` after the
         // leading-`/` strip are NOT WILD2_PREFIX and must not get the prepend.
         let wild2_prefix = !anchored && core_pattern.starts_with("**");
+        // A negated rule matches the paths its pattern does not, so no literal
+        // in the pattern narrows where it can fire.
+        let key = if negate {
+            RuleKey::Any
+        } else {
+            RuleKey::for_core(&core_pattern)
+        };
         let direct_matchers = compile_patterns(direct_patterns, wild2_prefix)?;
         let descendant_matchers = compile_patterns(descendant_patterns, wild2_prefix)?;
         let deletion_descendant_matchers =
@@ -189,6 +200,7 @@ impl CompiledRule {
             perishable,
             negate,
             order: 0,
+            key,
         })
     }
 }
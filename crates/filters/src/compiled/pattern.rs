@@ -63,7 +63,7 @@ impl CompiledPattern {
 /// rendering (preserving `.`/`..` and single components) is what `wildmatch()`
 /// expects. Backslashes are folded to `/` on Windows so matching is identical
 /// across platforms.
-fn path_match_bytes(path: &Path) -> Vec<u8> {
+pub(super) fn path_match_bytes(path: &Path) -> Vec<u8> {
     let rendered = path.to_string_lossy();
     if cfg!(windows) && rendered.contains('\\') {
         rendered.replace('\\', "/").into_bytes()
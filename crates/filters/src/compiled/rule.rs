@@ -2,6 +2,7 @@ use std::path::Path;
 
 use logging::debug_log;
 
+use super::index::RuleKey;
 use super::pattern::CompiledPattern;
 use crate::FilterAction;
 
@@ -50,6 +51,9 @@ pub(crate) struct CompiledRule {
     /// built outside `from_rules` (per-dir implied includes), which never mix
     /// the two chains against one path.
     pub(crate) order: usize,
+    /// Literal some path component must carry for any matcher to fire, used
+    /// by [`RuleIndex`](super::RuleIndex) to skip rules on long chains.
+    pub(super) key: RuleKey,
 }
 
 impl CompiledRule {
@@ -4,7 +4,7 @@ use logging::debug_log;
 
 use crate::{
     FilterAction,
-    compiled::{CompiledRule, CompiledXattrRule},
+    compiled::{CompiledRule, CompiledXattrRule, RuleIndex},
 };
 
 /// Internal rule storage shared by [`FilterSet`](crate::FilterSet) instances.
@@ -18,14 +18,48 @@ use crate::{
 ///   kept separate because upstream `exclude.c:914` never matches an
 ///   `x`-modifier rule against a path nor an ordinary rule against an xattr
 ///   name.
+///
+/// The two path chains each carry a [`RuleIndex`] that narrows a lookup to the
+/// rules that can match; an unbuilt (default) index scans the whole chain.
 #[derive(Debug, Default)]
 pub(crate) struct FilterSetInner {
     pub(crate) include_exclude: Vec<CompiledRule>,
     pub(crate) protect_risk: Vec<CompiledRule>,
     pub(crate) xattr: Vec<CompiledXattrRule>,
+    pub(crate) include_exclude_index: RuleIndex,
+    pub(crate) protect_risk_index: RuleIndex,
 }
 
 impl FilterSetInner {
+    /// Assembles the compiled chains and indexes the path chains.
+    pub(crate) fn new(
+        include_exclude: Vec<CompiledRule>,
+        protect_risk: Vec<CompiledRule>,
+        xattr: Vec<CompiledXattrRule>,
+    ) -> Self {
+        Self {
+            include_exclude_index: RuleIndex::new(&include_exclude),
+            protect_risk_index: RuleIndex::new(&protect_risk),
+            include_exclude,
+            protect_risk,
+            xattr,
+        }
+    }
+
+    fn include_exclude_chain(&self) -> Chain<'_> {
+        Chain {
+            rules: &self.include_exclude,
+            index: &self.include_exclude_index,
+        }
+    }
+
+    fn protect_risk_chain(&self) -> Chain<'_> {
+        Chain {
+            rules: &self.protect_risk,
+            index: &self.protect_risk_index,
+        }
+    }
+
     /// Resolves whether an xattr `name` is allowed by the `x`-modifier rules.
     ///
     /// Evaluates the xattr chain first-match-wins and returns the matching
@@ -114,14 +148,14 @@ impl FilterSetInner {
             // `check_descendants == false`) needs no gating - the walk prunes
             // excluded subtrees directly.
             DecisionContext::Transfer if check_descendants => transfer_rule_with_pruning(
-                &self.include_exclude,
+                self.include_exclude_chain(),
                 path,
                 is_dir,
                 |rule| rule.applies_to_sender,
                 true,
             ),
             DecisionContext::Transfer => first_matching_rule(
-                &self.include_exclude,
+                self.include_exclude_chain(),
                 path,
                 is_dir,
                 |rule| rule.applies_to_sender,
@@ -130,7 +164,7 @@ impl FilterSetInner {
                 false,
             ),
             DecisionContext::Deletion => first_matching_rule(
-                &self.include_exclude,
+                self.include_exclude_chain(),
                 path,
                 is_dir,
                 |rule| rule.applies_to_receiver,
@@ -142,7 +176,7 @@ impl FilterSetInner {
 
         if matches!(context, DecisionContext::Deletion)
             && let Some(rule) = first_matching_rule(
-                &self.include_exclude,
+                self.include_exclude_chain(),
                 path,
                 is_dir,
                 |rule| rule.applies_to_receiver,
@@ -181,7 +215,7 @@ impl FilterSetInner {
 
         let protection_rule = match context {
             DecisionContext::Transfer => first_matching_rule(
-                &self.protect_risk,
+                self.protect_risk_chain(),
                 path,
                 is_dir,
                 |rule| rule.applies_to_sender,
@@ -190,7 +224,7 @@ impl FilterSetInner {
                 false,
             ),
             DecisionContext::Deletion => first_matching_rule(
-                &self.protect_risk,
+                self.protect_risk_chain(),
                 path,
                 is_dir,
                 |rule| rule.applies_to_receiver,
@@ -243,7 +277,7 @@ impl FilterSetInner {
     /// upstream: exclude.c:1046-1050 check_filter()
     pub(crate) fn transfer_match_order(&self, path: &Path, is_dir: bool) -> Option<usize> {
         first_matching_rule(
-            &self.include_exclude,
+            self.include_exclude_chain(),
             path,
             is_dir,
             |rule| rule.applies_to_sender,
@@ -267,7 +301,7 @@ impl FilterSetInner {
     /// upstream: exclude.c:1038 check_filter()
     pub(crate) fn deletion_match_order(&self, path: &Path, is_dir: bool) -> Option<usize> {
         let include_exclude = first_matching_rule(
-            &self.include_exclude,
+            self.include_exclude_chain(),
             path,
             is_dir,
             |rule| rule.applies_to_receiver,
@@ -276,7 +310,7 @@ impl FilterSetInner {
             true,
         );
         let protect_risk = first_matching_rule(
-            &self.protect_risk,
+            self.protect_risk_chain(),
             path,
             is_dir,
             |rule| rule.applies_to_receiver,
@@ -322,7 +356,7 @@ impl FilterSetInner {
         let include_perishable = true;
         let for_deletion = matches!(context, DecisionContext::Deletion);
         if first_matching_rule(
-            &self.include_exclude,
+            self.include_exclude_chain(),
             path,
             is_dir,
             applies,
@@ -335,7 +369,7 @@ impl FilterSetInner {
             return true;
         }
         first_matching_rule(
-            &self.protect_risk,
+            self.protect_risk_chain(),
             path,
             is_dir,
             applies,
@@ -357,7 +391,7 @@ impl FilterSetInner {
     /// is an exclude rule whose pattern is NOT directory-only.
     pub(crate) fn excluded_dir_by_non_dir_rule(&self, path: &Path) -> bool {
         if let Some(rule) = first_matching_rule(
-            &self.include_exclude,
+            self.include_exclude_chain(),
             path,
             true,
             |rule| rule.applies_to_sender,
@@ -398,7 +432,7 @@ impl FilterSetInner {
 /// 2. `applies(rule)` returns true
 /// 3. The rule's pattern matches `path` considering `is_dir`
 fn first_matching_rule<'a, F>(
-    rules: &'a [CompiledRule],
+    rules: Chain<'a>,
     path: &Path,
     is_dir: bool,
     mut applies: F,
@@ -409,7 +443,7 @@ fn first_matching_rule<'a, F>(
 where
     F: FnMut(&CompiledRule) -> bool,
 {
-    rules.iter().find(|rule| {
+    rules.find(path, |rule| {
         (include_perishable || !rule.perishable)
             && applies(rule)
             && if for_deletion {
@@ -420,6 +454,34 @@ where
     })
 }
 
+/// A rule chain paired with the index that narrows it per path.
+#[derive(Clone, Copy)]
+struct Chain<'a> {
+    rules: &'a [CompiledRule],
+    index: &'a RuleIndex,
+}
+
+impl<'a> Chain<'a> {
+    /// Returns the first rule, in definition order, that satisfies
+    /// `predicate`, trying only the rules the index leaves as candidates for
+    /// `path`. Every skipped rule is one whose matchers cannot fire for
+    /// `path`, so the result equals a scan of the whole chain for any
+    /// `predicate` that requires the rule to match `path`.
+    fn find<P>(self, path: &Path, mut predicate: P) -> Option<&'a CompiledRule>
+    where
+        P: FnMut(&CompiledRule) -> bool,
+    {
+        let rules = self.rules;
+        match self.index.candidates(path) {
+            Some(candidates) => candidates
+                .into_iter()
+                .map(|position| &rules[position])
+                .find(|rule| predicate(rule)),
+            None => rules.iter().find(|rule| predicate(rule)),
+        }
+    }
+}
+
 /// Merges the include/exclude and protect/risk first-matches into the single
 /// source-ordered verdict upstream's `check_filter()` produces for deletion.
 ///
@@ -463,7 +525,7 @@ fn deletion_first_match_deletable(
 /// upstream: exclude.c:check_filter() first-match-wins plus the send_directory
 /// subtree pruning that the descendant matchers emulate for single-path queries.
 fn transfer_rule_with_pruning<'a, F>(
-    rules: &'a [CompiledRule],
+    rules: Chain<'a>,
     path: &Path,
     is_dir: bool,
     mut applies: F,
@@ -472,24 +534,20 @@ fn transfer_rule_with_pruning<'a, F>(
 where
     F: FnMut(&CompiledRule) -> bool,
 {
-    for rule in rules {
+    rules.find(path, |rule| {
         if (!include_perishable && rule.perishable) || !applies(rule) {
-            continue;
+            return false;
         }
         // A genuine per-path match wins immediately (upstream rule_matches).
         if rule.matches(path, is_dir, false) {
-            return Some(rule);
+            return true;
         }
         // A descendant-only exclude prunes `path` only when the directory it
         // excludes is itself the first match under first-match-wins.
-        if matches!(rule.action, FilterAction::Exclude)
+        matches!(rule.action, FilterAction::Exclude)
             && rule.matches(path, is_dir, true)
             && ancestor_pruned_by_exclude(rules, path, &mut applies, include_perishable)
-        {
-            return Some(rule);
-        }
-    }
-    None
+    })
 }
 
 /// Returns `true` when a proper ancestor directory of `path` is excluded under
@@ -497,7 +555,7 @@ where
 /// stops at the shallowest ancestor whose first matching rule is an exclude and
 /// never reaches `path`; an include keeps the directory and the walk descends.
 fn ancestor_pruned_by_exclude<F>(
-    rules: &[CompiledRule],
+    rules: Chain<'_>,
     path: &Path,
     mut applies: F,
     include_perishable: bool,
@@ -875,4 +933,203 @@ mod tests {
             "Transfer+traversal: foo/*/ must NOT exclude foo/sub/file1 (#6015)",
         );
     }
+
+    /// Compiles `rules` twice: indexed the way [`FilterSet`](crate::FilterSet)
+    /// builds them, and with unbuilt indexes so every lookup scans the chain.
+    fn indexed_and_naive(rules: &[FilterRule]) -> (FilterSetInner, FilterSetInner) {
+        let compile = || {
+            let mut include_exclude = Vec::new();
+            let mut protect_risk = Vec::new();
+            for (order, rule) in rules.iter().enumerate() {
+                let mut compiled = CompiledRule::new(rule.clone()).expect("compile");
+                compiled.order = order;
+                match rule.action {
+                    FilterAction::Include | FilterAction::Exclude => include_exclude.push(compiled),
+                    _ => protect_risk.push(compiled),
+                }
+            }
+            (include_exclude, protect_risk)
+        };
+        let (include_exclude, protect_risk) = compile();
+        let indexed = FilterSetInner::new(include_exclude, protect_risk, Vec::new());
+        let (include_exclude, protect_risk) = compile();
+        let naive = FilterSetInner {
+            include_exclude,
+            protect_risk,
+            ..FilterSetInner::default()
+        };
+        (indexed, naive)
+    }
+
+    /// Asserts every lookup `FilterSet` exposes gives the same answer with and
+    /// without the rule index.
+    fn assert_same_verdicts(indexed: &FilterSetInner, naive: &FilterSetInner, path: &Path) {
+        for is_dir in [false, true] {
+            for context in [DecisionContext::Transfer, DecisionContext::Deletion] {
+                for traversal in [false, true] {
+                    assert_eq!(
+                        indexed.decision_with_traversal(path, is_dir, context, traversal),
+                        naive.decision_with_traversal(path, is_dir, context, traversal),
+                        "{path:?} is_dir={is_dir} {context:?} traversal={traversal}"
+                    );
+                }
+                assert_eq!(
+                    indexed.has_matching_rule(path, is_dir, context),
+                    naive.has_matching_rule(path, is_dir, context),
+                    "{path:?} is_dir={is_dir} {context:?} has_matching_rule"
+                );
+            }
+            assert_eq!(
+                indexed.transfer_match_order(path, is_dir),
+                naive.transfer_match_order(path, is_dir),
+                "{path:?} is_dir={is_dir} transfer_match_order"
+            );
+            assert_eq!(
+                indexed.deletion_match_order(path, is_dir),
+                naive.deletion_match_order(path, is_dir),
+                "{path:?} is_dir={is_dir} deletion_match_order"
+            );
+        }
+        assert_eq!(
+            indexed.excluded_dir_by_non_dir_rule(path),
+            naive.excluded_dir_by_non_dir_rule(path),
+            "{path:?} excluded_dir_by_non_dir_rule"
+        );
+    }
+
+    /// The rule index only skips rules that cannot match, so a long mixed chain
+    /// - literal names, suffixes, prefixes, globs, negated, perishable,
+    /// one-sided and directory-only rules in both chains - decides every path
+    /// exactly like a plain first-match scan.
+    #[test]
+    fn indexed_chain_matches_naive_scan() {
+        let rules = vec![
+            FilterRule::include("keep.o"),
+            FilterRule::exclude("*.o"),
+            FilterRule::exclude("/build"),
+            FilterRule::exclude("cache/"),
+            FilterRule::include("src/*.rs"),
+            FilterRule::exclude("tmp*"),
+            FilterRule::exclude("*.log").with_perishable(true),
+            FilterRule::exclude("*.bak").with_negate(true),
+            FilterRule::exclude("node_modules").with_sides(true, false),
+            FilterRule::include("docs/"),
+            FilterRule::exclude("docs/*.md"),
+            FilterRule::exclude("a/**/b.txt"),
+            FilterRule::exclude("x**/y"),
+            FilterRule::exclude("[ab]?.c"),
+            FilterRule::exclude("lib/*/"),
+            FilterRule::exclude("*~"),
+            FilterRule::protect("important*"),
+            FilterRule::risk("important.tmp"),
+            FilterRule::protect("/etc/"),
+            FilterRule::exclude(".git"),
+            FilterRule::include("*/"),
+            FilterRule::exclude("*"),
+        ];
+        let (indexed, naive) = indexed_and_naive(&rules);
+        assert!(
+            indexed
+                .include_exclude_index
+                .candidates(Path::new("probe"))
+                .is_some(),
+            "the include/exclude chain is long enough to be indexed"
+        );
+
+        let components = [
+            "keep.o",
+            "main.o",
+            "build",
+            "cache",
+            "src",
+            "lib.rs",
+            "tmpdir",
+            "run.log",
+            "notes.bak",
+            "node_modules",
+            "docs",
+            "guide.md",
+            "a",
+            "b.txt",
+            "x",
+            "xa",
+            "y",
+            "aa.c",
+            "lib",
+            "sub",
+            "file~",
+            "important.tmp",
+            "importantly",
+            "etc",
+            ".git",
+            "plain",
+        ];
+        for first in components {
+            assert_same_verdicts(&indexed, &naive, Path::new(first));
+            for second in components {
+                assert_same_verdicts(&indexed, &naive, &Path::new(first).join(second));
+                for third in ["y", "b.txt", "guide.md", "z.o", "plain"] {
+                    let path = Path::new(first).join(second).join(third);
+                    assert_same_verdicts(&indexed, &naive, &path);
+                }
+            }
+        }
+    }
+
+    mod index_properties {
+        use super::*;
+        use proptest::prelude::*;
+
+        fn pattern() -> impl Strategy<Value = String> {
+            proptest::collection::vec(
+                prop_oneof![
+                    Just("a"),
+                    Just("b"),
+                    Just(".o"),
+                    Just("*"),
+                    Just("**"),
+                    Just("?"),
+                    Just("/"),
+                    Just("[ab]"),
+                ],
+                1..6,
+            )
+            .prop_map(|parts| parts.concat())
+        }
+
+        fn rule() -> impl Strategy<Value = FilterRule> {
+            (pattern(), 0..4u8, any::<bool>(), any::<bool>()).prop_map(
+                |(pattern, action, negate, perishable)| {
+                    let rule = match action {
+                        0 => FilterRule::include(pattern),
+                        1 => FilterRule::exclude(pattern),
+                        2 => FilterRule::protect(pattern),
+                        _ => FilterRule::risk(pattern),
+                    };
+                    rule.with_negate(negate).with_perishable(perishable)
+                },
+            )
+        }
+
+        fn path() -> impl Strategy<Value = String> {
+            proptest::collection::vec(
+                prop_oneof![Just("a"), Just("b"), Just("ab"), Just("a.o"), Just("ba.o")],
+                1..4,
+            )
+            .prop_map(|components| components.join("/"))
+        }
+
+        proptest! {
+            #[test]
+            fn indexed_lookup_equals_naive_scan(
+                rules in proptest::collection::vec(rule(), 32..64),
+                paths in proptest::collection::vec(path(), 1..16),
+            ) {
+                let (indexed, naive) = indexed_and_naive(&rules);
+                for path in &paths {
+                    assert_same_verdicts(&indexed, &naive, Path::new(path));
+                }
+            }
+        }
+    }
 }
@@ -139,11 +139,7 @@ impl FilterSet {
         }
 
         Ok(Self {
-            inner: Arc::new(FilterSetInner {
-                include_exclude,
-                protect_risk,
-                xattr,
-            }),
+            inner: Arc::new(FilterSetInner::new(include_exclude, protect_risk, xattr)),
         })
     }
 
@@ -413,3 +413,59 @@ fn exclude_lsh_scenario_tail_matching() {
     assert!(set.allows(Path::new("foo/sub"), true));
     assert!(set.allows(Path::new("bar/down/to/foo/file1"), false));
 }
+
+/// Asserts `set` excludes `path` for transfer and shields it from `--delete`
+/// when `excluded`, and leaves it alone otherwise. Each query the sender or
+/// receiver makes is checked, for a file and for a directory of that name.
+fn assert_excluded_everywhere(set: &FilterSet, path: &str, excluded: bool) {
+    for is_dir in [false, true] {
+        let path = Path::new(path);
+        assert_eq!(
+            set.allows(path, is_dir),
+            !excluded,
+            "allows({path:?}, is_dir={is_dir})"
+        );
+        assert_eq!(
+            set.allows_during_traversal(path, is_dir),
+            !excluded,
+            "allows_during_traversal({path:?}, is_dir={is_dir})"
+        );
+        assert_eq!(
+            set.allows_deletion(path, is_dir),
+            !excluded,
+            "allows_deletion({path:?}, is_dir={is_dir})"
+        );
+    }
+}
+
+/// `/foo` is anchored to the transfer root: only the top-level `foo` is
+/// excluded, never a `foo` further down or a name that merely starts with it.
+///
+/// upstream: exclude.c:rule_matches() - FILTRULE_ABS_PATH matches the full
+/// transfer-relative name instead of its trailing components.
+#[test]
+fn leading_slash_matches_only_top_level_name() {
+    let set = FilterSet::from_rules([FilterRule::exclude("/foo")]).unwrap();
+
+    assert_excluded_everywhere(&set, "foo", true);
+    assert_excluded_everywhere(&set, "bar/foo", false);
+    assert_excluded_everywhere(&set, "bar/baz/foo", false);
+    assert_excluded_everywhere(&set, "foobar", false);
+    assert_excluded_everywhere(&set, "bar/foobar", false);
+}
+
+/// `foo` without a leading slash matches a component named `foo` at any
+/// depth, including the top level.
+///
+/// upstream: exclude.c:rule_matches() - an unanchored pattern is matched
+/// against the trailing components of the name.
+#[test]
+fn no_leading_slash_matches_name_at_any_depth() {
+    let set = FilterSet::from_rules([FilterRule::exclude("foo")]).unwrap();
+
+    assert_excluded_everywhere(&set, "foo", true);
+    assert_excluded_everywhere(&set, "bar/foo", true);
+    assert_excluded_everywhere(&set, "bar/baz/foo", true);
+    assert_excluded_everywhere(&set, "foobar", false);
+    assert_excluded_everywhere(&set, "bar/foobar", false);
+}
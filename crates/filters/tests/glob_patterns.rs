@@ -497,3 +497,96 @@ fn javascript_project_patterns() {
     assert!(set.allows(Path::new(".env.example"), false));
     assert!(set.allows(Path::new("src/app.js"), false));
 }
+
+/// Checks `pattern` against `(path, matches)` pairs in both directions: as
+/// `- pattern` a matching path is excluded, and as `+ pattern` followed by
+/// `- *` a matching path is the only kind still included.
+fn assert_pattern_table(pattern: &str, table: &[(&str, bool)]) {
+    let exclude = FilterSet::from_rules([FilterRule::exclude(pattern)]).unwrap();
+    let include =
+        FilterSet::from_rules([FilterRule::include(pattern), FilterRule::exclude("*")]).unwrap();
+
+    for &(path, matches) in table {
+        assert_eq!(
+            exclude.allows(Path::new(path), false),
+            !matches,
+            "- {pattern} on {path}"
+        );
+        assert_eq!(
+            include.allows(Path::new(path), false),
+            matches,
+            "+ {pattern} / - * on {path}"
+        );
+    }
+}
+
+/// Verifies `a/**/b`: `**` spans one or more whole directories, and the
+/// unanchored pattern tail-matches at any depth.
+#[test]
+fn double_star_table_outcomes() {
+    assert_pattern_table(
+        "a/**/b",
+        &[
+            ("a/x/b", true),
+            ("a/x/y/z/b", true),
+            ("top/a/x/b", true),
+            // upstream `**/` consumes a real `/`: no directory between `a` and `b`.
+            ("a/b", false),
+            ("a/x/bb", false),
+            ("ab/x/b", false),
+            ("a/x/c", false),
+        ],
+    );
+}
+
+/// Verifies `*.log`: `*` stays inside one component, so only the final name's
+/// suffix decides.
+#[test]
+fn star_suffix_table_outcomes() {
+    assert_pattern_table(
+        "*.log",
+        &[
+            ("app.log", true),
+            (".log", true),
+            ("var/log/app.log", true),
+            ("app.log.1", false),
+            ("app.logs", false),
+            ("logs/app.txt", false),
+        ],
+    );
+}
+
+/// Verifies `[abc]?.txt`: the class takes exactly one listed character and
+/// `?` exactly one character other than `/`.
+#[test]
+fn class_and_question_table_outcomes() {
+    assert_pattern_table(
+        "[abc]?.txt",
+        &[
+            ("a1.txt", true),
+            ("cz.txt", true),
+            ("dir/bq.txt", true),
+            ("d1.txt", false),
+            ("a.txt", false),
+            ("a12.txt", false),
+            ("a/.txt", false),
+        ],
+    );
+}
+
+/// Verifies `*` and `**` are not interchangeable: under the same anchored
+/// directory `*` reaches only direct children while `**` reaches any depth.
+#[test]
+fn star_and_double_star_differ_across_slashes() {
+    let single = FilterSet::from_rules([FilterRule::exclude("/src/*")]).unwrap();
+    let double = FilterSet::from_rules([FilterRule::exclude("/src/**")]).unwrap();
+
+    assert!(!single.allows(Path::new("src/main.rs"), false));
+    assert!(!double.allows(Path::new("src/main.rs"), false));
+
+    assert!(single.allows(Path::new("src/nested/deep.rs"), false));
+    assert!(!double.allows(Path::new("src/nested/deep.rs"), false));
+
+    assert!(single.allows(Path::new("src/a/b/c.rs"), false));
+    assert!(!double.allows(Path::new("src/a/b/c.rs"), false));
+}
@@ -185,7 +185,7 @@ fn count_admits(
         let window = &target[pos..pos + block_len];
         let digest = RollingDigest::from_bytes(window);
         probes += 1;
-        if index.tag_admits(digest.sum1()) {
+        if index.tag_admits(digest) {
             tag_admits += 1;
             if index.bithash_admits(digest.value()) {
                 bithash_admits += 1;
@@ -0,0 +1,256 @@
+//! Two-level hash search comparison-count benchmark.
+//!
+//! Scans a large, lightly modified target against its basis and counts how
+//! much work the sender's matcher does per rolling-checksum position, before
+//! and after the tag table was sized and addressed like upstream's
+//! `match.c:build_hash_table()`.
+//!
+//! Run with:
+//! ```
+//! cargo bench -p matching --features bench-internal --bench hash_search_comparisons
+//! ```
+//!
+//! # What this benchmark reports
+//!
+//! A one-shot stderr summary per basis size, then Criterion timings for
+//! the full lookup scan:
+//!
+//! - `sum1_tag` / `tag`: positions admitted by the former fixed `2^16`
+//!   table keyed on `sum1` alone, versus the upstream-sized table keyed on
+//!   `SUM2HASH2` / `BIG_SUM2HASH`. The former saturates once the basis has
+//!   more than ~60k blocks.
+//! - `eager_strong`: strong checksums the former matcher computed. It
+//!   hashed every window that passed the tag and the bithash, whether or
+//!   not any chain entry matched its full rolling sum.
+//! - `strong`: strong checksums the matcher computes now, lazily, from
+//!   [`DeltaSignatureIndex::hash_search_counters`]. Upstream computes its
+//!   strong sum the same way (`done_csum2`).
+//!
+//! # Methodology notes
+//!
+//! - Basis sizes: 16 MiB with the default block size, and 128 MiB with
+//!   1 KiB blocks (131 072 blocks, past the traditional table size).
+//! - The target is the basis with one unaligned 97-byte overwrite every
+//!   64 KiB, so most of each block still matches after the edits shift
+//!   the alignment.
+//! - Positions are rolled one byte at a time like the generator's scan,
+//!   with the generator's copy-then-skip-a-block advance on a match.
+
+#![cfg(feature = "bench-internal")]
+
+use std::hint::black_box;
+use std::num::{NonZeroU8, NonZeroU32};
+use std::sync::OnceLock;
+
+use checksums::{RollingChecksum, RollingDigest};
+use criterion::{BenchmarkId, Criterion, Throughput, criterion_group, criterion_main};
+
+use matching::DeltaSignatureIndex;
+use protocol::ProtocolVersion;
+use signature::{
+    SignatureAlgorithm, SignatureLayoutParams, calculate_signature_layout, generate_file_signature,
+};
+
+/// `(basis size, forced block length, label)`.
+const BASIS_SIZES: &[(usize, Option<u32>, &str)] = &[
+    (16 << 20, None, "16MiB"),
+    (128 << 20, Some(1024), "128MiB-1KiB"),
+];
+
+/// Distance between edits in the modified target.
+const EDIT_STRIDE: usize = 64 * 1024;
+
+/// Length of each edit.
+const EDIT_LEN: usize = 97;
+
+/// Deterministic SplitMix64 byte stream, as in `bithash_rejection`.
+fn pseudo_random_bytes(seed: u64, len: usize) -> Vec<u8> {
+    let mut state = seed.wrapping_add(0x9E37_79B9_7F4A_7C15);
+    let mut out = vec![0u8; len];
+    for chunk in out.chunks_mut(8) {
+        state = state.wrapping_add(0x9E37_79B9_7F4A_7C15);
+        let mut z = state;
+        z = (z ^ (z >> 30)).wrapping_mul(0xBF58_476D_1CE4_E5B9);
+        z = (z ^ (z >> 27)).wrapping_mul(0x94D0_49BB_1331_11EB);
+        z ^= z >> 31;
+        let bytes = z.to_le_bytes();
+        chunk.copy_from_slice(&bytes[..chunk.len()]);
+    }
+    out
+}
+
+fn modified_target(basis: &[u8]) -> Vec<u8> {
+    let mut target = basis.to_vec();
+    let patch = pseudo_random_bytes(0x0ED1_7ED1, EDIT_LEN);
+    let mut offset = 12_345;
+    while offset + EDIT_LEN <= target.len() {
+        target[offset..offset + EDIT_LEN].copy_from_slice(&patch);
+        offset += EDIT_STRIDE;
+    }
+    target
+}
+
+fn build_index(data: &[u8], block_len: Option<u32>) -> DeltaSignatureIndex {
+    let params = SignatureLayoutParams::new(
+        data.len() as u64,
+        block_len.map(|len| NonZeroU32::new(len).expect("non-zero")),
+        ProtocolVersion::NEWEST,
+        NonZeroU8::new(16).expect("non-zero"),
+    );
+    let layout = calculate_signature_layout(params).expect("layout");
+    let signature =
+        generate_file_signature(data, layout, SignatureAlgorithm::Md4).expect("signature");
+    DeltaSignatureIndex::from_signature(&signature, SignatureAlgorithm::Md4).expect("index")
+}
+
+struct Fixture {
+    label: &'static str,
+    target: Vec<u8>,
+    index: DeltaSignatureIndex,
+    /// Occupancy of the former `sum1`-keyed tag table, rebuilt from the
+    /// indexed blocks for comparison.
+    sum1_tags: Vec<bool>,
+}
+
+impl Fixture {
+    fn new(size: usize, block_len: Option<u32>, label: &'static str) -> Self {
+        let basis = pseudo_random_bytes(0xBA51_5BA5, size);
+        let target = modified_target(&basis);
+        let index = build_index(&basis, block_len);
+        let mut sum1_tags = vec![false; 1 << 16];
+        for i in 0..index.block_count() {
+            let block = index.block(i);
+            if block.len() == index.block_length() {
+                sum1_tags[usize::from(block.rolling().sum1())] = true;
+            }
+        }
+        Self {
+            label,
+            target,
+            index,
+            sum1_tags,
+        }
+    }
+}
+
+fn fixtures() -> &'static [Fixture] {
+    static CACHE: OnceLock<Vec<Fixture>> = OnceLock::new();
+    CACHE.get_or_init(|| {
+        BASIS_SIZES
+            .iter()
+            .map(|&(size, block_len, label)| Fixture::new(size, block_len, label))
+            .collect()
+    })
+}
+
+#[derive(Default)]
+struct ScanCounts {
+    positions: u64,
+    sum1_tag: u64,
+    tag: u64,
+    eager_strong: u64,
+    matches: u64,
+}
+
+/// Rolls over `target`, calling `probe` at every position and skipping a
+/// block past each match.
+fn scan(target: &[u8], block_len: usize, mut probe: impl FnMut(RollingDigest, &[u8]) -> bool) {
+    if target.len() < block_len {
+        return;
+    }
+    let mut rolling = RollingChecksum::new();
+    rolling.update(&target[..block_len]);
+    let mut pos = 0;
+    loop {
+        if probe(rolling.digest(), &target[pos..pos + block_len]) {
+            pos += block_len;
+            if pos + block_len > target.len() {
+                return;
+            }
+            rolling.reset();
+            rolling.update(&target[pos..pos + block_len]);
+        } else {
+            if pos + block_len >= target.len() {
+                return;
+            }
+            rolling
+                .roll(target[pos], target[pos + block_len])
+                .expect("rolling window is non-empty");
+            pos += 1;
+        }
+    }
+}
+
+fn count(fixture: &Fixture) -> ScanCounts {
+    let index = &fixture.index;
+    let mut counts = ScanCounts::default();
+    scan(&fixture.target, index.block_length(), |digest, window| {
+        counts.positions += 1;
+        let bithash = index.bithash_admits(digest.value());
+        if fixture.sum1_tags[usize::from(digest.sum1())] {
+            counts.sum1_tag += 1;
+            counts.eager_strong += u64::from(bithash);
+        }
+        counts.tag += u64::from(index.tag_admits(digest));
+        let found = index.find_match_bytes(digest, window).is_some();
+        counts.matches += u64::from(found);
+        found
+    });
+    counts
+}
+
+fn report_comparison_summary() {
+    eprintln!(
+        "hash search summary (size,blocks,tablesize,positions,sum1_tag,tag,chain_walks,\
+         eager_strong,strong,matches)"
+    );
+    for fixture in fixtures() {
+        let counters = fixture.index.hash_search_counters();
+        counters.reset();
+        let counts = count(fixture);
+        eprintln!(
+            "hash_search[{label}] blocks={blocks} tablesize={size} positions={positions} \
+             sum1_tag={sum1_tag} tag={tag} chain_walks={walks} eager_strong={eager} \
+             strong={strong} matches={matches}",
+            label = fixture.label,
+            blocks = fixture.index.block_count(),
+            size = fixture.index.tag_table_size(),
+            positions = counts.positions,
+            sum1_tag = counts.sum1_tag,
+            tag = counts.tag,
+            walks = counters.chain_walks(),
+            eager = counts.eager_strong,
+            strong = counters.strong_sums(),
+            matches = counts.matches,
+        );
+    }
+}
+
+fn bench_hash_search(c: &mut Criterion) {
+    report_comparison_summary();
+
+    let mut group = c.benchmark_group("hash_search_comparisons");
+    group.sample_size(10);
+    for fixture in fixtures() {
+        group.throughput(Throughput::Bytes(fixture.target.len() as u64));
+        group.bench_with_input(
+            BenchmarkId::new("modified_target_scan", fixture.label),
+            fixture,
+            |b, fx| {
+                b.iter(|| {
+                    let mut matches = 0u64;
+                    scan(&fx.target, fx.index.block_length(), |digest, window| {
+                        let found = fx.index.find_match_bytes(digest, window).is_some();
+                        matches += u64::from(found);
+                        found
+                    });
+                    black_box(matches)
+                });
+            },
+        );
+    }
+    group.finish();
+}
+
+criterion_group!(benches, bench_hash_search);
+criterion_main!(benches);
@@ -9,7 +9,7 @@ use signature::{FileSignature, SignatureAlgorithm, SignatureBlock};
 use super::compact_lookup::CompactLookup;
 use super::trace::{HashtableRole, trace_created, trace_growing};
 use super::{
-    BitHash, CONSUMED_BITS_PER_WORD, DeltaSignatureIndex, NEXT_MATCH_NONE, TAG_TABLE_SIZE,
+    BitHash, CONSUMED_BITS_PER_WORD, DeltaSignatureIndex, NEXT_MATCH_NONE, TagTable,
     build_consumed_words,
 };
 
@@ -26,7 +26,7 @@ use super::{
 fn populate_index(
     blocks: &[SignatureBlock],
     block_length: usize,
-    tag_table: &mut [bool],
+    tag_table: &mut TagTable,
     bithash: &mut BitHash,
     lookup: &mut CompactLookup,
     next_match: &mut [u32],
@@ -39,7 +39,7 @@ fn populate_index(
         }
         has_full_blocks = true;
         let digest = block.rolling();
-        tag_table[digest.sum1() as usize] = true;
+        tag_table.insert(digest);
         bithash.insert(digest.value());
         lookup.insert(digest.sum1(), digest.sum2(), index as u32);
         if let Some(prev) = prev_full {
@@ -117,7 +117,7 @@ impl DeltaSignatureIndex {
 
         let requested = blocks.len();
         let mut lookup = CompactLookup::with_capacity(requested);
-        let mut tag_table = vec![false; TAG_TABLE_SIZE];
+        let mut tag_table = TagTable::with_block_count(requested);
         let mut bithash = BitHash::with_block_count(requested);
         // The seq-match link table holds one slot per signature block, sized
         // to the raw signature length (including any trailing partial block)
@@ -153,6 +153,8 @@ impl DeltaSignatureIndex {
             last_traced_size: size,
             #[cfg(any(test, feature = "bench-internal"))]
             seq_match_counters: std::sync::Arc::new(super::SeqMatchCounters::default()),
+            #[cfg(any(test, feature = "bench-internal"))]
+            hash_search_counters: std::sync::Arc::new(super::HashSearchCounters::default()),
         };
         // upstream: hashtable.c:45-53 - one HASH,1 emission per hashtable
         // creation, with optional `req:` prefix when the rounded-up size
@@ -180,7 +182,7 @@ impl DeltaSignatureIndex {
         self.blocks.clear();
         self.blocks.extend_from_slice(signature.blocks());
         self.lookup.clear();
-        self.tag_table.iter_mut().for_each(|v| *v = false);
+        self.tag_table.reset(self.blocks.len());
         self.bithash.clear();
         // Per ZSO-7 isolation: every link from the prior segment must be
         // cleared before re-population so a stale successor never leaks
@@ -198,6 +200,8 @@ impl DeltaSignatureIndex {
         }
         #[cfg(any(test, feature = "bench-internal"))]
         self.seq_match_counters.reset();
+        #[cfg(any(test, feature = "bench-internal"))]
+        self.hash_search_counters.reset();
 
         let ok = populate_index(
             &self.blocks,
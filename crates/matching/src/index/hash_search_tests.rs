@@ -0,0 +1,266 @@
+//! Correctness of the two-level hash search against a naive reference.
+//!
+//! The index gates every rolling-checksum probe on the upstream-sized
+//! [`super::TagTable`] and the bithash, walks the `(sum1, sum2)` chain, and
+//! only then computes the strong checksum. These tests replay a large
+//! modified target against a reference matcher built on the naive layout -
+//! a `HashMap` from the full rolling sum to block indices, verified with an
+//! eagerly computed strong sum - and pin three properties:
+//!
+//! 1. Every window resolves to the same block (or the same miss) as the
+//!    reference, for both the contiguous and the two-slice lookup.
+//! 2. The [`crate::DeltaGenerator`] script is identical to the reference
+//!    greedy delta, and reconstructs the target.
+//! 3. A strong checksum is computed exactly once per window whose full
+//!    rolling sum matches an indexed block, never for prefilter survivors
+//!    that miss in the chain.
+//!
+//! # Upstream Reference
+//!
+//! - `match.c:hash_search()` - tag slot, chain walk on the full rolling sum,
+//!   lazy `done_csum2` strong sum.
+
+use std::collections::HashMap;
+use std::io::Cursor;
+use std::num::{NonZeroU8, NonZeroU32};
+
+use checksums::{RollingChecksum, strong::Md5Seed};
+use protocol::ProtocolVersion;
+use signature::{
+    SignatureAlgorithm, SignatureLayoutParams, calculate_signature_layout, generate_file_signature,
+};
+
+use super::DeltaSignatureIndex;
+use crate::{DeltaGenerator, DeltaToken, apply_delta};
+
+/// Basis size: a whole number of blocks, so every basis block is indexed.
+const BASIS_LEN: usize = 4 * 1024 * 1024;
+
+/// Forced block length.
+const BLOCK_LEN: usize = 1024;
+
+fn pseudo_random_bytes(len: usize, seed: u64) -> Vec<u8> {
+    let mut state = seed;
+    let mut out = Vec::with_capacity(len + 8);
+    while out.len() < len {
+        // splitmix64
+        state = state.wrapping_add(0x9E37_79B9_7F4A_7C15);
+        let mut z = state;
+        z = (z ^ (z >> 30)).wrapping_mul(0xBF58_476D_1CE4_E5B9);
+        z = (z ^ (z >> 27)).wrapping_mul(0x94D0_49BB_1331_11EB);
+        out.extend_from_slice(&(z ^ (z >> 31)).to_le_bytes());
+    }
+    out.truncate(len);
+    out
+}
+
+/// Applies unaligned edits to `basis`: overwrites, an insertion, and a
+/// deletion, so matches resume at shifted offsets. No basis region appears
+/// twice, which keeps the consumed-block prune from changing any decision.
+fn modified_target(basis: &[u8]) -> Vec<u8> {
+    let mut target = basis.to_vec();
+    for (i, offset) in [100_003usize, 1_500_017, 2_900_000].into_iter().enumerate() {
+        let patch = pseudo_random_bytes(777, 0xED17 + i as u64);
+        target[offset..offset + patch.len()].copy_from_slice(&patch);
+    }
+    let insert_at = 1_000_111;
+    let inserted = pseudo_random_bytes(4321, 0x1A5E);
+    target.splice(insert_at..insert_at, inserted);
+    let delete_at = 3_300_333;
+    target.drain(delete_at..delete_at + 5000);
+    // Trailing bytes shorter than a block end the target in literal data.
+    target.extend_from_slice(&pseudo_random_bytes(300, 0x7A11));
+    target
+}
+
+fn build_index(basis: &[u8]) -> DeltaSignatureIndex {
+    let algorithm = SignatureAlgorithm::Md5 {
+        seed_config: Md5Seed::none(),
+    };
+    let params = SignatureLayoutParams::new(
+        basis.len() as u64,
+        Some(NonZeroU32::new(BLOCK_LEN as u32).expect("block length is non-zero")),
+        ProtocolVersion::NEWEST,
+        NonZeroU8::new(16).expect("strong length is non-zero"),
+    );
+    let layout = calculate_signature_layout(params).expect("signature layout");
+    let signature =
+        generate_file_signature(Cursor::new(basis.to_vec()), layout, algorithm).expect("signature");
+    DeltaSignatureIndex::from_signature(&signature, algorithm).expect("delta signature index")
+}
+
+/// The naive single-level layout: full rolling sum to block indices, with
+/// the strong sum computed for every window that hits the map.
+struct ReferenceIndex<'a> {
+    index: &'a DeltaSignatureIndex,
+    by_rsum: HashMap<u32, Vec<usize>>,
+}
+
+impl<'a> ReferenceIndex<'a> {
+    fn new(index: &'a DeltaSignatureIndex) -> Self {
+        let mut by_rsum: HashMap<u32, Vec<usize>> = HashMap::new();
+        for i in 0..index.block_count() {
+            let block = index.block(i);
+            if block.len() == index.block_length() {
+                by_rsum.entry(block.rolling().value()).or_default().push(i);
+            }
+        }
+        Self { index, by_rsum }
+    }
+
+    /// Returns `(rsum_hit, matched_block)` for a full-length window.
+    fn find(&self, rsum: u32, window: &[u8]) -> (bool, Option<usize>) {
+        let Some(candidates) = self.by_rsum.get(&rsum) else {
+            return (false, None);
+        };
+        let strong = self
+            .index
+            .algorithm
+            .compute_truncated(window, self.index.strong_length);
+        let found = candidates
+            .iter()
+            .copied()
+            .find(|&i| strong.as_slice() == self.index.block(i).strong());
+        (true, found)
+    }
+}
+
+/// One copied basis block or one run of literal bytes.
+#[derive(Debug, PartialEq, Eq)]
+enum Op {
+    Copy(u64),
+    Literal(Vec<u8>),
+}
+
+fn push_literal(ops: &mut Vec<Op>, bytes: &[u8]) {
+    if let Some(Op::Literal(run)) = ops.last_mut() {
+        run.extend_from_slice(bytes);
+    } else {
+        ops.push(Op::Literal(bytes.to_vec()));
+    }
+}
+
+/// Normalizes a script: fat copies split per block, literals coalesced.
+fn normalize(tokens: &[DeltaToken]) -> Vec<Op> {
+    let mut ops = Vec::new();
+    for token in tokens {
+        match token {
+            DeltaToken::Literal(bytes) => push_literal(&mut ops, bytes),
+            DeltaToken::Copy { index, len } => {
+                assert_eq!(len % BLOCK_LEN, 0, "basis has no partial block");
+                ops.extend((0..(len / BLOCK_LEN) as u64).map(|k| Op::Copy(index + k)));
+            }
+        }
+    }
+    ops
+}
+
+/// Greedy delta over the reference index: copy on a match and skip a block,
+/// otherwise emit one literal byte and roll.
+fn reference_delta(reference: &ReferenceIndex<'_>, target: &[u8]) -> Vec<Op> {
+    let mut ops = Vec::new();
+    let mut pos = 0;
+    while pos + BLOCK_LEN <= target.len() {
+        let window = &target[pos..pos + BLOCK_LEN];
+        let mut rolling = RollingChecksum::new();
+        rolling.update(window);
+        if let (_, Some(i)) = reference.find(rolling.value(), window) {
+            ops.push(Op::Copy(i as u64));
+            pos += BLOCK_LEN;
+        } else {
+            push_literal(&mut ops, &target[pos..pos + 1]);
+            pos += 1;
+        }
+    }
+    push_literal(&mut ops, &target[pos..]);
+    ops
+}
+
+#[test]
+fn lookups_agree_with_naive_map_at_every_offset() {
+    let basis = pseudo_random_bytes(BASIS_LEN, 0xBA515);
+    let target = modified_target(&basis);
+    let index = build_index(&basis);
+    let reference = ReferenceIndex::new(&index);
+    let counters = index.hash_search_counters();
+
+    let mut rolling = RollingChecksum::new();
+    rolling.update(&target[..BLOCK_LEN]);
+    let mut rsum_hits = 0u64;
+    let mut matches = 0u64;
+    for offset in 0..=target.len() - BLOCK_LEN {
+        if offset > 0 {
+            rolling
+                .roll(target[offset - 1], target[offset + BLOCK_LEN - 1])
+                .expect("rolling window is non-empty");
+        }
+        let window = &target[offset..offset + BLOCK_LEN];
+        let digest = rolling.digest();
+        let (rsum_hit, expected) = reference.find(digest.value(), window);
+        rsum_hits += u64::from(rsum_hit);
+        matches += u64::from(expected.is_some());
+        assert_eq!(
+            index.find_match_bytes(digest, window),
+            expected,
+            "contiguous lookup diverged at offset {offset}",
+        );
+    }
+
+    let probes = counters.probes();
+    let chain_walks = counters.chain_walks();
+    let strong_sums = counters.strong_sums();
+    assert_eq!(probes, (target.len() - BLOCK_LEN + 1) as u64);
+    assert!(matches >= 4000, "edits left {matches} matching windows");
+    // Lazy strong sums: one per full rolling-sum hit, none for windows that
+    // only survived the tag table and bithash.
+    assert_eq!(strong_sums, rsum_hits);
+    assert!(
+        strong_sums < chain_walks,
+        "eager verify would compute {chain_walks} strong sums, lazy computed {strong_sums}",
+    );
+}
+
+#[test]
+fn slice_lookups_agree_with_contiguous_lookups() {
+    let basis = pseudo_random_bytes(BASIS_LEN, 0xBA515);
+    let target = modified_target(&basis);
+    let index = build_index(&basis);
+
+    let mut rolling = RollingChecksum::new();
+    rolling.update(&target[..BLOCK_LEN]);
+    for offset in 0..=target.len() - BLOCK_LEN {
+        if offset > 0 {
+            rolling
+                .roll(target[offset - 1], target[offset + BLOCK_LEN - 1])
+                .expect("rolling window is non-empty");
+        }
+        let window = &target[offset..offset + BLOCK_LEN];
+        let digest = rolling.digest();
+        let (first, second) = window.split_at(offset % BLOCK_LEN);
+        assert_eq!(
+            index.find_match_slices(digest, first, second),
+            index.find_match_bytes(digest, window),
+            "split lookup diverged at offset {offset}",
+        );
+    }
+}
+
+#[test]
+fn generated_delta_matches_naive_reference() {
+    let basis = pseudo_random_bytes(BASIS_LEN, 0xBA515);
+    let target = modified_target(&basis);
+    let index = build_index(&basis);
+    let reference = ReferenceIndex::new(&index);
+
+    let script = DeltaGenerator::new()
+        .generate(Cursor::new(target.as_slice()), &index)
+        .expect("delta generation");
+    assert_eq!(
+        normalize(script.tokens()),
+        reference_delta(&reference, &target)
+    );
+
+    let mut rebuilt = Vec::with_capacity(target.len());
+    apply_delta(Cursor::new(basis.as_slice()), &mut rebuilt, &index, &script).expect("apply delta");
+    assert_eq!(rebuilt, target);
+}
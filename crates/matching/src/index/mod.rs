@@ -15,6 +15,7 @@ mod bithash;
 mod builder;
 mod compact_lookup;
 mod matched_blocks;
+mod tag_table;
 mod trace;
 
 #[cfg(test)]
@@ -22,6 +23,8 @@ mod bithash_tests;
 #[cfg(test)]
 mod compact_key_tests;
 #[cfg(test)]
+mod hash_search_tests;
+#[cfg(test)]
 mod matched_blocks_tests;
 #[cfg(test)]
 mod prune_tests;
@@ -42,18 +45,12 @@ use signature::{SignatureAlgorithm, SignatureBlock};
 use bithash::BitHash;
 use compact_lookup::CompactLookup;
 pub use matched_blocks::MatchedBlocks;
+use tag_table::TagTable;
 pub use trace::{
     HASH_KEY_BITS, HashtableRole, trace_created as trace_hashtable_created,
     trace_destroyed as trace_hashtable_destroyed, trace_growing as trace_hashtable_growing,
 };
 
-/// Size of the tag table for quick rolling checksum rejection (2^16 entries).
-///
-/// Upstream rsync uses a boolean array indexed by the low 16 bits (sum1) of the
-/// rolling checksum to reject non-matching positions before probing the hash
-/// table. This constant matches upstream's `TABLESIZE` in `match.c`.
-const TAG_TABLE_SIZE: usize = 1 << 16;
-
 /// Sentinel marking the absence of a stored successor in [`DeltaSignatureIndex::next_match`].
 ///
 /// The link table stores `u32` block indices; `u32::MAX` is reserved to mean
@@ -68,8 +65,9 @@ const NEXT_MATCH_NONE: u32 = u32::MAX;
 /// half of the rolling sum (`sum2`) for O(1) block lookup with excellent
 /// cache locality. The lower half (`sum1`) lives inside each chain entry as
 /// an in-bucket discriminator, mirroring zsync's `librcksum`
-/// `rsum_a_mask` trick (ZSO-4). A tag table indexed by `sum1` still provides
-/// upstream-rsync-style fast-path rejection before the bucket walk, and the
+/// `rsum_a_mask` trick (ZSO-4). A [`TagTable`] sized and addressed like
+/// upstream's `match.c` hash table provides fast-path rejection before the
+/// bucket walk, and the
 /// bithash prefilter (ZSO-1) rejects the bulk of post-tag misses before the
 /// chain probe.
 #[derive(Debug)]
@@ -82,9 +80,10 @@ pub struct DeltaSignatureIndex {
     /// (`rsum >> 16`); the lower 16 bits live inside each chain entry as
     /// the in-bucket discriminator. See the ZSO-4 module-level docs.
     lookup: CompactLookup,
-    /// Tag table for O(1) rejection using sum1 (low 16 bits of rolling checksum).
-    /// upstream: match.c - `tag_table[s1]` check before hash probe.
-    tag_table: Vec<bool>,
+    /// First-level tag table over both rolling-sum halves, grown with the
+    /// block count like upstream's `tablesize`.
+    /// upstream: match.c:build_hash_table() - `SUM2HASH2` / `BIG_SUM2HASH`.
+    tag_table: TagTable,
     /// Bithash prefilter mixing both rolling-sum halves.
     ///
     /// Sized to roughly 8x the rsum-bucket count (~1 byte per indexed block),
@@ -143,6 +142,10 @@ pub struct DeltaSignatureIndex {
     /// skip the field entirely so the hot path carries zero overhead.
     #[cfg(any(test, feature = "bench-internal"))]
     seq_match_counters: std::sync::Arc<SeqMatchCounters>,
+    /// Test-only full-lookup counters, shared across clones like
+    /// [`Self::seq_match_counters`].
+    #[cfg(any(test, feature = "bench-internal"))]
+    hash_search_counters: std::sync::Arc<HashSearchCounters>,
 }
 
 /// Number of bits stored per `consumed` word.
@@ -186,6 +189,8 @@ impl Clone for DeltaSignatureIndex {
             last_traced_size: self.last_traced_size,
             #[cfg(any(test, feature = "bench-internal"))]
             seq_match_counters: std::sync::Arc::clone(&self.seq_match_counters),
+            #[cfg(any(test, feature = "bench-internal"))]
+            hash_search_counters: std::sync::Arc::clone(&self.hash_search_counters),
         }
     }
 }
@@ -237,6 +242,57 @@ impl SeqMatchCounters {
     }
 }
 
+/// Test-only full-lookup counters.
+///
+/// Track how far each [`DeltaSignatureIndex::find_match_bytes`] (or
+/// slice-form) probe gets through the two-level search:
+///
+/// - `probes`: full-length windows offered to the lookup.
+/// - `chain_walks`: probes admitted by the tag table and bithash.
+/// - `strong_sums`: strong checksums computed, at most one per chain walk
+///   and only once a chain entry matches the full rolling sum.
+#[cfg(any(test, feature = "bench-internal"))]
+#[derive(Debug, Default)]
+pub struct HashSearchCounters {
+    probes: std::sync::atomic::AtomicU64,
+    chain_walks: std::sync::atomic::AtomicU64,
+    strong_sums: std::sync::atomic::AtomicU64,
+}
+
+#[cfg(any(test, feature = "bench-internal"))]
+impl HashSearchCounters {
+    /// Returns the number of full-length windows probed since the last reset.
+    #[must_use]
+    pub fn probes(&self) -> u64 {
+        self.probes.load(std::sync::atomic::Ordering::Relaxed)
+    }
+
+    /// Returns the number of probes that reached the bucket chain.
+    #[must_use]
+    pub fn chain_walks(&self) -> u64 {
+        self.chain_walks.load(std::sync::atomic::Ordering::Relaxed)
+    }
+
+    /// Returns the number of strong checksums computed.
+    #[must_use]
+    pub fn strong_sums(&self) -> u64 {
+        self.strong_sums.load(std::sync::atomic::Ordering::Relaxed)
+    }
+
+    /// Clears every counter back to zero.
+    pub fn reset(&self) {
+        self.probes.store(0, std::sync::atomic::Ordering::Relaxed);
+        self.chain_walks
+            .store(0, std::sync::atomic::Ordering::Relaxed);
+        self.strong_sums
+            .store(0, std::sync::atomic::Ordering::Relaxed);
+    }
+
+    fn bump(counter: &std::sync::atomic::AtomicU64) {
+        counter.fetch_add(1, std::sync::atomic::Ordering::Relaxed);
+    }
+}
+
 impl DeltaSignatureIndex {
     /// Returns the bucket address the compact lookup would use for `rsum`.
     ///
@@ -455,6 +511,15 @@ impl DeltaSignatureIndex {
         std::sync::Arc::clone(&self.seq_match_counters)
     }
 
+    /// Returns a shared handle to the test-only full-lookup counters.
+    ///
+    /// Gated like [`Self::seq_match_counters`].
+    #[cfg(any(test, feature = "bench-internal"))]
+    #[must_use]
+    pub fn hash_search_counters(&self) -> std::sync::Arc<HashSearchCounters> {
+        std::sync::Arc::clone(&self.hash_search_counters)
+    }
+
     /// Returns `true` when the basis block at `idx` has been marked as
     /// consumed by a prior `Copy`-token emission.
     ///
@@ -546,9 +611,13 @@ impl DeltaSignatureIndex {
             return None;
         }
 
-        // upstream: match.c - tag_table[s1] fast-path rejects most non-matching
-        // positions before the more expensive hash probe.
-        if !self.tag_table[digest.sum1() as usize] {
+        #[cfg(any(test, feature = "bench-internal"))]
+        HashSearchCounters::bump(&self.hash_search_counters.probes);
+
+        // upstream: match.c:hash_search() - the hash table slot for both
+        // rolling-sum halves rejects most non-matching positions before the
+        // chain walk.
+        if !self.tag_table.contains(digest) {
             return None;
         }
 
@@ -559,7 +628,13 @@ impl DeltaSignatureIndex {
             return None;
         }
 
-        let strong = self.algorithm.compute_truncated(window, self.strong_length);
+        #[cfg(any(test, feature = "bench-internal"))]
+        HashSearchCounters::bump(&self.hash_search_counters.chain_walks);
+
+        // upstream: match.c:hash_search() - the strong sum is computed
+        // lazily (`done_csum2`), only once a chain entry passes the cheaper
+        // checks, and then reused for the rest of the chain.
+        let mut strong: Option<signature::DigestBuf> = None;
         for index in self.lookup.find_all(digest.sum1(), digest.sum2()) {
             if matches!(matched, Some(m) if m.is_matched(index)) {
                 continue;
@@ -573,6 +648,11 @@ impl DeltaSignatureIndex {
             }
             let block = &self.blocks[index];
             debug_assert_eq!(block.len(), self.block_length);
+            let strong = strong.get_or_insert_with(|| {
+                #[cfg(any(test, feature = "bench-internal"))]
+                HashSearchCounters::bump(&self.hash_search_counters.strong_sums);
+                self.algorithm.compute_truncated(window, self.strong_length)
+            });
             if strong.as_slice() == block.strong() {
                 return Some(index);
             }
@@ -614,7 +694,10 @@ impl DeltaSignatureIndex {
             return None;
         }
 
-        if !self.tag_table[digest.sum1() as usize] {
+        #[cfg(any(test, feature = "bench-internal"))]
+        HashSearchCounters::bump(&self.hash_search_counters.probes);
+
+        if !self.tag_table.contains(digest) {
             return None;
         }
 
@@ -622,9 +705,10 @@ impl DeltaSignatureIndex {
             return None;
         }
 
-        let strong = self
-            .algorithm
-            .compute_truncated_slices(first, second, self.strong_length);
+        #[cfg(any(test, feature = "bench-internal"))]
+        HashSearchCounters::bump(&self.hash_search_counters.chain_walks);
+
+        let mut strong: Option<signature::DigestBuf> = None;
         for index in self.lookup.find_all(digest.sum1(), digest.sum2()) {
             if matches!(matched, Some(m) if m.is_matched(index)) {
                 continue;
@@ -637,6 +721,12 @@ impl DeltaSignatureIndex {
             }
             let block = &self.blocks[index];
             debug_assert_eq!(block.len(), self.block_length);
+            let strong = strong.get_or_insert_with(|| {
+                #[cfg(any(test, feature = "bench-internal"))]
+                HashSearchCounters::bump(&self.hash_search_counters.strong_sums);
+                self.algorithm
+                    .compute_truncated_slices(first, second, self.strong_length)
+            });
             if strong.as_slice() == block.strong() {
                 return Some(index);
             }
@@ -905,14 +995,20 @@ impl DeltaSignatureIndex {
         self.bithash.contains(rsum)
     }
 
-    /// Returns `true` when the `tag_table` would let `sum1` through to
+    /// Returns `true` when the `tag_table` would let `digest` through to
     /// the bithash probe.
     ///
     /// Mirrors the first-line tag-table gate so the bench can isolate
     /// post-tag bithash rejection from the upstream-style fast path.
     #[must_use]
-    pub fn tag_admits(&self, sum1: u16) -> bool {
-        self.tag_table[sum1 as usize]
+    pub fn tag_admits(&self, digest: RollingDigest) -> bool {
+        self.tag_table.contains(digest)
+    }
+
+    /// Slot count of the tag table (upstream's `tablesize`).
+    #[must_use]
+    pub fn tag_table_size(&self) -> u32 {
+        self.tag_table.size()
     }
 
     /// Bucket-slot count of the underlying compact lookup table.
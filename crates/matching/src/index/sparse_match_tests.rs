@@ -90,11 +90,11 @@ const PLANTED_TARGET_OFFSET: usize = 64 * 1024 * 1024;
 ///
 /// The design-note 7/8 (87.5 %) figure is the theoretical saturation bound for
 /// a fully uniform-random rsum distribution. In practice the rolling
-/// checksum's `value()` is `(sum2 << 16) | sum1`, and at 100 MiB / 1 KiB
-/// blocks the tag table (low 16 bits = sum1) is fully saturated, so every
-/// probe reaches the bithash and only the upper 6 bits drawn from sum2
-/// distinguish them. That clustering pulls the observed rejection rate down
-/// to roughly 0.78, which is still well above "most probes". The floor is
+/// checksum's `value()` is `(sum2 << 16) | sum1` and the bithash masks its
+/// low bits, so probes cluster on the sum1 half. When the tag table was a
+/// fixed `2^16` array keyed by sum1 it saturated at 100 MiB / 1 KiB blocks
+/// and the observed rate sat near 0.78; the upstream-sized tag table now
+/// mixes both halves, but the bithash's own clustering is unchanged. The floor is
 /// pinned at 0.70 so a regression that drops it below 70 % (the point at
 /// which the bithash stops carrying its weight) trips the assertion. The
 /// floor matches the rejection-rate gate language in
@@ -246,7 +246,7 @@ fn sparse_match_100mib_single_overlap() {
     };
     if !in_planted(0) {
         let d = rolling.digest();
-        if index.tag_table[d.sum1() as usize] {
+        if index.tag_table.contains(d) {
             post_tag_probes += 1;
             if !index.bithash.contains(d.value()) {
                 bithash_rejects += 1;
@@ -263,7 +263,7 @@ fn sparse_match_100mib_single_overlap() {
             continue;
         }
         let d = rolling.digest();
-        if !index.tag_table[d.sum1() as usize] {
+        if !index.tag_table.contains(d) {
             continue;
         }
         post_tag_probes += 1;
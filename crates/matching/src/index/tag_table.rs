@@ -0,0 +1,165 @@
+//! First-level tag table for the [`DeltaSignatureIndex`].
+//!
+//! Upstream rsync gates every rolling-checksum probe on a hash table whose
+//! slot is derived from *both* halves of the rolling sum, and grows that
+//! table with the block count so the load stays near 80% on big files
+//! (upstream: match.c:build_hash_table()). The previous index keyed a fixed
+//! `2^16` table on `sum1` alone, which saturated past ~60k blocks and let
+//! almost every probe through to the chain walk.
+//!
+//! This table keeps upstream's sizing and slot functions:
+//!
+//! - Up to `TRADITIONAL_TABLESIZE` slots, the slot is
+//!   `SUM2HASH2(s1, s2) = (s1 + s2) & 0xFFFF`, the 16-bit tag upstream has
+//!   used since its first releases.
+//! - Beyond that, the table holds `(count / 8) * 10 + 11` slots and the slot
+//!   is `BIG_SUM2HASH(sum) = sum % tablesize`. The size is odd so `s2` spans
+//!   the whole table.
+//!
+//! Upstream stores chain heads in the slots; here the chains live in
+//! [`super::CompactLookup`], so each slot only records occupancy as one bit.
+//! The `2^16`-slot table therefore fits in 8 KiB instead of 64 KiB of
+//! `bool`s, small enough to stay L1-resident while the window rolls.
+//!
+//! Like the [`super::BitHash`] prefilter the table is one-sided: an indexed
+//! rolling sum is never rejected. It is in-memory only; no wire, capability,
+//! or golden-byte surface is affected.
+
+use checksums::RollingDigest;
+
+/// Upstream's `TRADITIONAL_TABLESIZE` (`1 << 16`), the table size for files
+/// with up to `52 421` blocks.
+pub(super) const TRADITIONAL_TABLESIZE: u32 = 1 << 16;
+
+/// Occupancy bitset addressed by upstream's rolling-sum tag functions.
+#[derive(Clone, Debug)]
+pub(super) struct TagTable {
+    bits: Vec<u64>,
+    size: u32,
+}
+
+impl TagTable {
+    /// Creates an empty table sized for `n_blocks` indexed blocks.
+    pub(super) fn with_block_count(n_blocks: usize) -> Self {
+        let size = table_size_for(n_blocks);
+        Self {
+            bits: vec![0; words_for(size)],
+            size,
+        }
+    }
+
+    /// Clears every slot and re-sizes the table for `n_blocks`, reusing the
+    /// allocation where it is large enough.
+    pub(super) fn reset(&mut self, n_blocks: usize) {
+        self.size = table_size_for(n_blocks);
+        self.bits.clear();
+        self.bits.resize(words_for(self.size), 0);
+    }
+
+    /// Marks the slot for an indexed block's rolling sum.
+    #[inline]
+    pub(super) fn insert(&mut self, digest: RollingDigest) {
+        let slot = self.slot(digest);
+        self.bits[slot / 64] |= 1u64 << (slot % 64);
+    }
+
+    /// Returns `false` when no indexed block can have this rolling sum.
+    #[inline]
+    pub(super) fn contains(&self, digest: RollingDigest) -> bool {
+        let slot = self.slot(digest);
+        (self.bits[slot / 64] >> (slot % 64)) & 1 == 1
+    }
+
+    /// Number of slots (upstream's `tablesize`).
+    #[cfg(any(test, feature = "bench-internal"))]
+    pub(super) const fn size(&self) -> u32 {
+        self.size
+    }
+
+    #[inline]
+    fn slot(&self, digest: RollingDigest) -> usize {
+        if self.size == TRADITIONAL_TABLESIZE {
+            // upstream: match.c - SUM2HASH2(s1, s2)
+            (usize::from(digest.sum1()) + usize::from(digest.sum2())) & 0xFFFF
+        } else {
+            // upstream: match.c - BIG_SUM2HASH(sum)
+            (digest.value() % self.size) as usize
+        }
+    }
+}
+
+/// Upstream's dynamic table size: about 80% load, never below the
+/// traditional `2^16`.
+fn table_size_for(n_blocks: usize) -> u32 {
+    let n = u32::try_from(n_blocks).unwrap_or(u32::MAX);
+    (n / 8)
+        .saturating_mul(10)
+        .saturating_add(11)
+        .max(TRADITIONAL_TABLESIZE)
+}
+
+fn words_for(size: u32) -> usize {
+    (size as usize).div_ceil(64)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn digest(sum1: u16, sum2: u16) -> RollingDigest {
+        RollingDigest::new(sum1, sum2, 1024)
+    }
+
+    #[test]
+    fn small_files_use_traditional_size() {
+        assert_eq!(TagTable::with_block_count(0).size(), TRADITIONAL_TABLESIZE);
+        assert_eq!(
+            TagTable::with_block_count(52_421).size(),
+            TRADITIONAL_TABLESIZE
+        );
+        assert_eq!(TagTable::with_block_count(0).bits.len() * 8, 8 * 1024);
+    }
+
+    #[test]
+    fn large_files_grow_to_odd_size() {
+        let table = TagTable::with_block_count(1_000_000);
+        assert_eq!(table.size(), 1_250_011);
+        assert_eq!(table.size() % 2, 1);
+    }
+
+    #[test]
+    fn traditional_slot_mixes_both_halves() {
+        let mut table = TagTable::with_block_count(16);
+        table.insert(digest(0x1234, 0x0001));
+        // Same sum1, different sum2: rejected, unlike a sum1-only tag.
+        assert!(!table.contains(digest(0x1234, 0x0002)));
+        // Different halves with the same (s1 + s2) & 0xFFFF share the slot.
+        assert!(table.contains(digest(0x1233, 0x0002)));
+    }
+
+    #[test]
+    fn indexed_sums_are_never_rejected() {
+        for n_blocks in [16, 200_000] {
+            let mut table = TagTable::with_block_count(n_blocks);
+            let digests: Vec<_> = (0..n_blocks as u32)
+                .map(|i| {
+                    let v = i.wrapping_mul(0x9E37_79B9);
+                    digest(v as u16, (v >> 16) as u16)
+                })
+                .collect();
+            for &d in &digests {
+                table.insert(d);
+            }
+            assert!(digests.iter().all(|&d| table.contains(d)));
+        }
+    }
+
+    #[test]
+    fn reset_clears_and_resizes() {
+        let mut table = TagTable::with_block_count(200_000);
+        table.insert(digest(7, 9));
+        table.reset(16);
+        assert_eq!(table.size(), TRADITIONAL_TABLESIZE);
+        assert!(!table.contains(digest(7, 9)));
+    }
+}
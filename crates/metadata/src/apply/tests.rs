@@ -310,6 +310,90 @@ fn symlink_times_are_preserved_without_following_target() {
     assert_eq!(dest_target, target);
 }
 
+// upstream: rsync.c:set_file_attrs() - a symlink's times and ownership go
+// through do_lutimes()/do_lchown(), so the entry's values land on the link and
+// the file it points at keeps its own.
+#[cfg(unix)]
+#[test]
+fn symlink_entry_times_are_set_on_the_link_not_the_target() {
+    use protocol::flist::FileEntry;
+    use std::os::unix::fs::symlink;
+
+    let temp = tempdir().expect("tempdir");
+    let target = temp.path().join("target.txt");
+    let dest_link = temp.path().join("dest-link");
+    fs::write(&target, b"data").expect("write target");
+    let target_mtime = FileTime::from_unix_time(1_600_000_000, 0);
+    set_file_times(&target, target_mtime, target_mtime).expect("set target times");
+    symlink("target.txt", &dest_link).expect("create dest link");
+
+    let mut entry = FileEntry::new_symlink("dest-link".into(), "target.txt".into());
+    entry.set_mtime(1_700_050_000, 0);
+
+    apply_symlink_metadata_from_entry(
+        &dest_link,
+        &entry,
+        &MetadataOptions::new().preserve_times(true),
+    )
+    .expect("apply symlink metadata");
+
+    let link_meta = fs::symlink_metadata(&dest_link).expect("link metadata");
+    assert_eq!(
+        FileTime::from_last_modification_time(&link_meta),
+        FileTime::from_unix_time(1_700_050_000, 0)
+    );
+    let target_meta = fs::metadata(&target).expect("target metadata");
+    assert_eq!(
+        FileTime::from_last_modification_time(&target_meta),
+        target_mtime,
+        "the symlink's target must keep its own mtime"
+    );
+}
+
+#[cfg(unix)]
+#[test]
+fn symlink_entry_ownership_is_set_on_the_link_not_the_target() {
+    use protocol::flist::FileEntry;
+    use std::os::unix::fs::symlink;
+
+    if !rustix::process::geteuid().is_root() {
+        // Only root can give the link a foreign owner; the non-root gate is
+        // covered by the override tests below.
+        return;
+    }
+
+    let temp = tempdir().expect("tempdir");
+    let target = temp.path().join("target.txt");
+    let dest_link = temp.path().join("dest-link");
+    fs::write(&target, b"data").expect("write target");
+    symlink("target.txt", &dest_link).expect("create dest link");
+    let target_before = fs::metadata(&target).expect("target metadata");
+
+    let mut entry = FileEntry::new_symlink("dest-link".into(), "target.txt".into());
+    entry.set_uid(12345);
+    entry.set_gid(23456);
+
+    apply_symlink_metadata_from_entry(
+        &dest_link,
+        &entry,
+        &MetadataOptions::new()
+            .preserve_owner(true)
+            .preserve_group(true)
+            .numeric_ids(true),
+    )
+    .expect("apply symlink metadata");
+
+    let link_meta = fs::symlink_metadata(&dest_link).expect("link metadata");
+    assert_eq!(link_meta.uid(), 12345);
+    assert_eq!(link_meta.gid(), 23456);
+    let target_after = fs::metadata(&target).expect("target metadata");
+    assert_eq!(
+        (target_after.uid(), target_after.gid()),
+        (target_before.uid(), target_before.gid()),
+        "the symlink's target must keep its own owner"
+    );
+}
+
 #[cfg(unix)]
 #[test]
 fn symlink_metadata_with_options_no_times() {
@@ -72,7 +72,8 @@ fn set_times_via(
 /// Non-Unix counterpart to [`set_times_via`] using the [`filetime`] crate.
 ///
 /// Windows has no `utimensat`; `filetime` opens the target to set its times.
-/// The `None`-atime case updates only the mtime.
+/// A `None` slot is filled from the current metadata of the same node, so
+/// `follow_symlinks = false` never reads or writes the link's target.
 #[cfg(not(unix))]
 fn set_times_via(
     destination: &Path,
@@ -81,21 +82,27 @@ fn set_times_via(
     follow_symlinks: bool,
     _keep_dirlinks: bool,
 ) -> std::io::Result<()> {
-    match (atime, mtime) {
-        (Some(atime), Some(mtime)) => {
-            if follow_symlinks {
-                filetime::set_file_times(destination, atime, mtime)
+    if atime.is_none() && mtime.is_none() {
+        return Ok(());
+    }
+    let (atime, mtime) = match (atime, mtime) {
+        (Some(atime), Some(mtime)) => (atime, mtime),
+        (atime, mtime) => {
+            let current = if follow_symlinks {
+                fs::metadata(destination)?
             } else {
-                filetime::set_symlink_file_times(destination, atime, mtime)
-            }
+                fs::symlink_metadata(destination)?
+            };
+            (
+                atime.unwrap_or_else(|| FileTime::from_last_access_time(&current)),
+                mtime.unwrap_or_else(|| FileTime::from_last_modification_time(&current)),
+            )
         }
-        (None, Some(mtime)) => filetime::set_file_mtime(destination, mtime),
-        (Some(atime), None) => {
-            let current = fs::metadata(destination)?;
-            let mtime = FileTime::from_last_modification_time(&current);
-            filetime::set_file_times(destination, atime, mtime)
-        }
-        (None, None) => Ok(()),
+    };
+    if follow_symlinks {
+        filetime::set_file_times(destination, atime, mtime)
+    } else {
+        filetime::set_symlink_file_times(destination, atime, mtime)
     }
 }
 
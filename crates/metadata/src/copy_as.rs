@@ -13,6 +13,9 @@
 //! - **Unix**: Uses `seteuid(2)` / `setegid(2)` via libc. Requires the
 //!   process to be running as root (euid 0) or to possess `CAP_SETUID` /
 //!   `CAP_SETGID`.
+//! - **Linux**: Additionally offers [`switch_fs_ids`], a per-thread switch
+//!   through `setfsuid(2)` / `setfsgid(2)` that a multi-threaded receiver
+//!   can take around each file it writes.
 //! - **Windows**: Returns a descriptive `Unsupported` error. The POSIX
 //!   `--copy-as=USER` flow takes no password and assumes the process can
 //!   change effective identity without one (root or a `CAP_SETUID`
@@ -387,6 +390,99 @@ pub fn switch_effective_ids(_ids: &CopyAsIds) -> io::Result<CopyAsGuard> {
     ))
 }
 
+/// RAII guard that restores the calling thread's filesystem UID/GID on drop.
+///
+/// Created by [`switch_fs_ids`]. Linux tracks the filesystem identity per
+/// thread, so the guard is `!Send`: it must be dropped on the thread that
+/// took it.
+#[cfg(target_os = "linux")]
+#[derive(Debug)]
+pub struct CopyAsFsGuard {
+    original_fsuid: u32,
+    original_fsgid: u32,
+    switched_fsgid: bool,
+    _thread_bound: std::marker::PhantomData<*const ()>,
+}
+
+#[cfg(target_os = "linux")]
+impl Drop for CopyAsFsGuard {
+    fn drop(&mut self) {
+        // Restore fsuid first: the fs capabilities it brings back are not
+        // needed for setfsgid, but it mirrors the switch in reverse.
+        // SAFETY: `setfsuid`/`setfsgid` only change the calling thread's
+        // filesystem identity; the values were the thread's own.
+        unsafe {
+            libc::setfsuid(self.original_fsuid);
+            if self.switched_fsgid {
+                libc::setfsgid(self.original_fsgid);
+            }
+        }
+    }
+}
+
+/// Switches the calling thread's filesystem UID and optionally GID.
+///
+/// Unlike [`switch_effective_ids`], which changes the identity of the whole
+/// process, `setfsuid(2)` / `setfsgid(2)` change only the identity the kernel
+/// uses for permission checks and ownership of new files on the calling
+/// thread. A receiver can therefore create each destination file as the
+/// `--copy-as` user while other threads keep their identity, and the switch
+/// can be taken and released around every file.
+///
+/// Dropping from root to a non-zero fsuid also clears the filesystem
+/// capabilities (`CAP_CHOWN`, `CAP_DAC_OVERRIDE`, `CAP_FOWNER`, ...) for as
+/// long as the guard lives, so writes are checked exactly as if the user
+/// performed them.
+///
+/// # Errors
+///
+/// `setfsuid(2)` reports no error; the switch is verified by reading the
+/// identity back, and `PermissionDenied` is returned (with the thread left
+/// unchanged) when the kernel refused it.
+#[cfg(target_os = "linux")]
+#[allow(unsafe_code)]
+pub fn switch_fs_ids(ids: &CopyAsIds) -> io::Result<CopyAsFsGuard> {
+    // `setfsuid(-1)` is always rejected and returns the current value, which
+    // makes it a side-effect-free read.
+    const QUERY: u32 = u32::MAX;
+
+    // SAFETY: see `CopyAsFsGuard::drop`; every call affects only this thread.
+    unsafe {
+        let original_fsgid = libc::setfsgid(QUERY) as u32;
+        let mut switched_fsgid = false;
+        if let Some(gid) = ids.gid {
+            libc::setfsgid(gid);
+            if libc::setfsgid(QUERY) as u32 != gid {
+                return Err(fs_switch_denied("group", gid));
+            }
+            switched_fsgid = true;
+        }
+
+        let original_fsuid = libc::setfsuid(ids.uid) as u32;
+        if libc::setfsuid(QUERY) as u32 != ids.uid {
+            if switched_fsgid {
+                libc::setfsgid(original_fsgid);
+            }
+            return Err(fs_switch_denied("user", ids.uid));
+        }
+
+        Ok(CopyAsFsGuard {
+            original_fsuid,
+            original_fsgid,
+            switched_fsgid,
+            _thread_bound: std::marker::PhantomData,
+        })
+    }
+}
+
+#[cfg(target_os = "linux")]
+fn fs_switch_denied(kind: &str, id: u32) -> io::Error {
+    io::Error::new(
+        io::ErrorKind::PermissionDenied,
+        format!("cannot switch filesystem {kind} to {id} for --copy-as"),
+    )
+}
+
 #[cfg(not(unix))]
 fn resolve_user_by_name(name: &str) -> io::Result<u32> {
     Err(io::Error::new(
@@ -506,6 +602,80 @@ mod tests {
         assert_eq!(unsafe { libc::getegid() }, egid);
     }
 
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn fs_switch_to_current_ids_round_trips() {
+        // SAFETY: `geteuid`/`getegid` are POSIX accessors with no side effects.
+        let euid = unsafe { libc::geteuid() };
+        let egid = unsafe { libc::getegid() };
+        let guard = switch_fs_ids(&CopyAsIds {
+            uid: euid,
+            gid: Some(egid),
+        })
+        .unwrap();
+        assert_eq!(guard.original_fsuid, euid);
+        assert_eq!(guard.original_fsgid, egid);
+    }
+
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn fs_switch_creates_files_as_copy_as_user() {
+        use std::os::unix::fs::MetadataExt;
+
+        // SAFETY: `geteuid` is a POSIX accessor with no side effects.
+        if unsafe { libc::geteuid() } != 0 {
+            eprintln!("skipping: switching the filesystem uid requires root");
+            return;
+        }
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::set_permissions(
+            dir.path(),
+            std::os::unix::fs::PermissionsExt::from_mode(0o777),
+        )
+        .unwrap();
+        let path = dir.path().join("owned");
+
+        let ids = CopyAsIds {
+            uid: 65534,
+            gid: Some(65534),
+        };
+        let guard = switch_fs_ids(&ids).unwrap();
+        std::fs::write(&path, b"data").unwrap();
+        drop(guard);
+
+        let meta = std::fs::metadata(&path).unwrap();
+        assert_eq!((meta.uid(), meta.gid()), (65534, 65534));
+        // The process identity and the thread's filesystem identity are back.
+        assert_eq!(unsafe { libc::geteuid() }, 0);
+        std::fs::write(dir.path().join("root-owned"), b"data").unwrap();
+        assert_eq!(
+            std::fs::metadata(dir.path().join("root-owned"))
+                .unwrap()
+                .uid(),
+            0
+        );
+    }
+
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn fs_switch_denied_without_privilege() {
+        // SAFETY: `geteuid` is a POSIX accessor with no side effects.
+        let euid = unsafe { libc::geteuid() };
+        if euid == 0 {
+            eprintln!("skipping: root may switch to any filesystem uid");
+            return;
+        }
+        let target = if euid == 65534 { 65533 } else { 65534 };
+        let err = switch_fs_ids(&CopyAsIds {
+            uid: target,
+            gid: None,
+        })
+        .unwrap_err();
+        assert_eq!(err.kind(), io::ErrorKind::PermissionDenied);
+        // The failed switch left the thread untouched.
+        assert_eq!(unsafe { libc::setfsuid(u32::MAX) } as u32, euid);
+    }
+
     #[test]
     fn copy_as_ids_clone() {
         let ids = CopyAsIds {
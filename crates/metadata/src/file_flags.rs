@@ -0,0 +1,483 @@
+//! File flag preservation for `--fileflags`.
+//!
+//! Linux keeps per-inode flags (`chattr +i`, `+a`, ...) behind the
+//! `FS_IOC_GETFLAGS`/`FS_IOC_SETFLAGS` ioctls; the BSDs and macOS expose them
+//! as `st_flags` and change them with `chflags(2)`. Only the flags that record
+//! a user's intent are copied - immutable, append-only, nodump, noatime and
+//! friends. Bits the filesystem manages itself (extents, inline data,
+//! encryption, snapshots) keep whatever value the destination already has.
+//!
+//! The system immutable and append-only flags need privilege
+//! (`CAP_LINUX_IMMUTABLE`, or the superuser on BSD). When the receiver lacks
+//! it, those bits are left as they are on the destination and the remaining
+//! flags are still applied, so an unprivileged copy keeps `nodump` and the
+//! user-settable BSD flags.
+//!
+//! A destination filesystem that cannot store flags at all (tmpfs on older
+//! kernels, FAT, NFS) is skipped silently, the same way unsupported xattrs and
+//! ACLs are.
+//!
+//! upstream: rsync-patches `fileflags.diff` - `--fileflags` reads `st_flags`
+//! on the sender and reapplies them on the receiver via `set_file_attrs()`.
+
+use crate::error::MetadataError;
+use std::io;
+use std::path::Path;
+
+/// Returns `true` when this platform can read and write file flags.
+#[must_use]
+pub const fn file_flags_supported() -> bool {
+    cfg!(any(
+        target_os = "linux",
+        target_os = "macos",
+        target_os = "ios",
+        target_os = "freebsd",
+        target_os = "dragonfly",
+        target_os = "netbsd",
+        target_os = "openbsd"
+    ))
+}
+
+/// Reads the preservable file flags of `path` without following symlinks.
+///
+/// Returns `Ok(None)` when the platform or the filesystem does not support
+/// file flags.
+///
+/// # Errors
+///
+/// Returns the underlying I/O error when `path` cannot be opened or queried
+/// for any other reason.
+pub fn read_file_flags(path: &Path) -> io::Result<Option<u32>> {
+    imp::read(path).map(|flags| flags.map(|value| value & imp::PRESERVED))
+}
+
+/// Copies the preservable file flags from `source` onto `destination`.
+///
+/// Flags outside the preservable set keep the destination's current value.
+/// Privileged flags the caller may not change are left untouched rather than
+/// failing the whole update.
+///
+/// # Errors
+///
+/// Returns [`MetadataError`] when either path cannot be inspected or the new
+/// flags cannot be applied.
+pub fn sync_file_flags(source: &Path, destination: &Path) -> Result<(), MetadataError> {
+    let Some(source_flags) = read_file_flags(source)
+        .map_err(|error| MetadataError::new("read file flags", source, error))?
+    else {
+        return Ok(());
+    };
+    apply_file_flags(destination, source_flags)
+}
+
+/// Applies the preservable subset of `flags` to `path`.
+///
+/// # Errors
+///
+/// Returns [`MetadataError`] when the destination cannot be inspected or
+/// updated.
+pub fn apply_file_flags(path: &Path, flags: u32) -> Result<(), MetadataError> {
+    let Some(current) =
+        imp::read(path).map_err(|error| MetadataError::new("read file flags", path, error))?
+    else {
+        return Ok(());
+    };
+
+    let wanted = merge_flags(current, flags, imp::PRESERVED);
+    if wanted == current {
+        return Ok(());
+    }
+
+    match imp::write(path, wanted) {
+        Ok(()) => Ok(()),
+        Err(error) if error.kind() == io::ErrorKind::PermissionDenied => {
+            // Keep the privileged bits as they are and retry with the rest.
+            let unprivileged = merge_flags(current, flags, imp::PRESERVED & !imp::PRIVILEGED);
+            if unprivileged == current {
+                return Ok(());
+            }
+            imp::write(path, unprivileged)
+                .map_err(|error| MetadataError::new("set file flags", path, error))
+        }
+        Err(error) if imp::is_unsupported(&error) => Ok(()),
+        Err(error) => Err(MetadataError::new("set file flags", path, error)),
+    }
+}
+
+/// Which immutable and append-only flags `--force-change` may clear.
+///
+/// The BSDs split these into user flags (`uchg`, `uappnd`), which the owner
+/// may toggle, and system flags (`schg`, `sappnd`), which need the superuser.
+/// Linux only has the privileged kind, so it is treated as a system flag.
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+pub struct ForceChange {
+    user: bool,
+    system: bool,
+}
+
+impl ForceChange {
+    /// Never clears any flag.
+    pub const NONE: Self = Self::new(false, false);
+
+    /// Clears both user and system flags (`--force-change`).
+    pub const ALL: Self = Self::new(true, true);
+
+    /// Creates a selection covering the user and/or system flags.
+    #[must_use]
+    pub const fn new(user: bool, system: bool) -> Self {
+        Self { user, system }
+    }
+
+    /// Reports whether user immutable/append-only flags may be cleared.
+    #[must_use]
+    pub const fn user(self) -> bool {
+        self.user
+    }
+
+    /// Reports whether system immutable/append-only flags may be cleared.
+    #[must_use]
+    pub const fn system(self) -> bool {
+        self.system
+    }
+
+    /// Reports whether no flag may be cleared.
+    #[must_use]
+    pub const fn is_none(self) -> bool {
+        !self.user && !self.system
+    }
+
+    /// Combines two selections.
+    #[must_use]
+    pub const fn union(self, other: Self) -> Self {
+        Self::new(self.user || other.user, self.system || other.system)
+    }
+
+    const fn mask(self) -> u32 {
+        let mut mask = 0;
+        if self.user {
+            mask |= imp::USER_PROTECTIVE;
+        }
+        if self.system {
+            mask |= imp::SYSTEM_PROTECTIVE;
+        }
+        mask
+    }
+}
+
+/// Clears the immutable and append-only flags selected by `force` on `path`.
+///
+/// Returns the flags `path` carried before the change when anything was
+/// cleared, so the caller can put them back with [`undo_make_mutable`].
+/// Returns `Ok(None)` when nothing needed clearing or the filesystem has no
+/// file flags.
+///
+/// # Errors
+///
+/// Returns [`MetadataError`] when the flags cannot be read or cleared, for
+/// example when the caller lacks the privilege to drop a system flag.
+// upstream: rsync-patches fileflags.diff - syscall.c:make_mutable()
+pub fn make_mutable(path: &Path, force: ForceChange) -> Result<Option<u32>, MetadataError> {
+    let mask = force.mask();
+    if mask == 0 {
+        return Ok(None);
+    }
+    let Some(current) =
+        imp::read(path).map_err(|error| MetadataError::new("read file flags", path, error))?
+    else {
+        return Ok(None);
+    };
+    if current & mask == 0 {
+        return Ok(None);
+    }
+    imp::write(path, current & !mask)
+        .map_err(|error| MetadataError::new("clear immutable file flags", path, error))?;
+    Ok(Some(current & imp::PRESERVED))
+}
+
+/// Restores flags previously returned by [`make_mutable`].
+///
+/// # Errors
+///
+/// Returns [`MetadataError`] when the flags cannot be reapplied.
+// upstream: rsync-patches fileflags.diff - syscall.c:undo_make_mutable()
+pub fn undo_make_mutable(path: &Path, flags: u32) -> Result<(), MetadataError> {
+    apply_file_flags(path, flags)
+}
+
+/// Replaces the bits of `current` selected by `mask` with those of `source`.
+const fn merge_flags(current: u32, source: u32, mask: u32) -> u32 {
+    (current & !mask) | (source & mask)
+}
+
+#[cfg(target_os = "linux")]
+mod imp {
+    use rustix::fs::{IFlags, Mode, OFlags, ioctl_getflags, ioctl_setflags, open};
+    use std::io;
+    use std::path::Path;
+
+    /// Flags that describe user intent rather than on-disk layout.
+    pub(super) const PRESERVED: u32 = IFlags::APPEND
+        .union(IFlags::IMMUTABLE)
+        .union(IFlags::NODUMP)
+        .union(IFlags::NOATIME)
+        .union(IFlags::SYNC)
+        .union(IFlags::DIRSYNC)
+        .union(IFlags::NOCOW)
+        .union(IFlags::COMPRESSED)
+        .union(IFlags::NOTAIL)
+        .union(IFlags::SECURE_REMOVAL)
+        .union(IFlags::UNRM)
+        .union(IFlags::TOPDIR)
+        .union(IFlags::PROJECT_INHERIT)
+        .bits();
+
+    /// Flags that need `CAP_LINUX_IMMUTABLE` to set or clear.
+    pub(super) const PRIVILEGED: u32 = IFlags::APPEND.union(IFlags::IMMUTABLE).bits();
+
+    /// Linux has no owner-settable immutable flag.
+    pub(super) const USER_PROTECTIVE: u32 = 0;
+
+    /// Flags that block writes, renames and unlinks.
+    pub(super) const SYSTEM_PROTECTIVE: u32 = PRIVILEGED;
+
+    pub(super) fn read(path: &Path) -> io::Result<Option<u32>> {
+        let fd = open_for_flags(path)?;
+        match ioctl_getflags(&fd) {
+            Ok(flags) => Ok(Some(flags.bits())),
+            Err(errno) => {
+                let error = io::Error::from(errno);
+                if is_unsupported(&error) {
+                    Ok(None)
+                } else {
+                    Err(error)
+                }
+            }
+        }
+    }
+
+    pub(super) fn write(path: &Path, flags: u32) -> io::Result<()> {
+        let fd = open_for_flags(path)?;
+        ioctl_setflags(&fd, IFlags::from_bits_retain(flags)).map_err(io::Error::from)
+    }
+
+    /// Opens `path` read-only without following symlinks or blocking on FIFOs.
+    fn open_for_flags(path: &Path) -> io::Result<rustix::fd::OwnedFd> {
+        open(
+            path,
+            OFlags::RDONLY | OFlags::NONBLOCK | OFlags::NOFOLLOW | OFlags::CLOEXEC,
+            Mode::empty(),
+        )
+        .map_err(io::Error::from)
+    }
+
+    pub(super) fn is_unsupported(error: &io::Error) -> bool {
+        matches!(
+            error.raw_os_error(),
+            Some(libc::ENOTTY | libc::EOPNOTSUPP | libc::EINVAL | libc::ENOSYS)
+        )
+    }
+}
+
+#[cfg(any(
+    target_os = "macos",
+    target_os = "ios",
+    target_os = "freebsd",
+    target_os = "dragonfly",
+    target_os = "netbsd",
+    target_os = "openbsd"
+))]
+mod imp {
+    use nix::sys::stat::FileFlag;
+    use std::io;
+    use std::path::Path;
+
+    /// Owner-settable flags plus the superuser-settable ones.
+    pub(super) const PRESERVED: u32 =
+        FileFlag::UF_SETTABLE.bits() as u32 | FileFlag::SF_SETTABLE.bits() as u32;
+
+    /// Flags only the superuser may change.
+    pub(super) const PRIVILEGED: u32 = FileFlag::SF_SETTABLE.bits() as u32;
+
+    /// `uchg` and `uappnd`.
+    pub(super) const USER_PROTECTIVE: u32 =
+        FileFlag::UF_IMMUTABLE.bits() as u32 | FileFlag::UF_APPEND.bits() as u32;
+
+    /// `schg` and `sappnd`.
+    pub(super) const SYSTEM_PROTECTIVE: u32 =
+        FileFlag::SF_IMMUTABLE.bits() as u32 | FileFlag::SF_APPEND.bits() as u32;
+
+    pub(super) fn read(path: &Path) -> io::Result<Option<u32>> {
+        let stat = rustix::fs::lstat(path)?;
+        Ok(Some(stat.st_flags as u32))
+    }
+
+    pub(super) fn write(path: &Path, flags: u32) -> io::Result<()> {
+        nix::unistd::chflags(path, FileFlag::from_bits_retain(flags as _)).map_err(io::Error::from)
+    }
+
+    pub(super) fn is_unsupported(error: &io::Error) -> bool {
+        matches!(error.raw_os_error(), Some(libc::EOPNOTSUPP))
+    }
+}
+
+#[cfg(not(any(
+    target_os = "linux",
+    target_os = "macos",
+    target_os = "ios",
+    target_os = "freebsd",
+    target_os = "dragonfly",
+    target_os = "netbsd",
+    target_os = "openbsd"
+)))]
+mod imp {
+    use std::io;
+    use std::path::Path;
+
+    pub(super) const PRESERVED: u32 = 0;
+    pub(super) const PRIVILEGED: u32 = 0;
+    pub(super) const USER_PROTECTIVE: u32 = 0;
+    pub(super) const SYSTEM_PROTECTIVE: u32 = 0;
+
+    pub(super) fn read(_path: &Path) -> io::Result<Option<u32>> {
+        Ok(None)
+    }
+
+    pub(super) fn write(_path: &Path, _flags: u32) -> io::Result<()> {
+        Ok(())
+    }
+
+    pub(super) fn is_unsupported(_error: &io::Error) -> bool {
+        true
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn merge_flags_replaces_only_masked_bits() {
+        assert_eq!(merge_flags(0b1010, 0b0101, 0b0011), 0b1001);
+        assert_eq!(merge_flags(0b1111, 0, 0), 0b1111);
+    }
+
+    #[test]
+    fn privileged_flags_are_preserved_flags() {
+        assert_eq!(imp::PRIVILEGED & !imp::PRESERVED, 0);
+    }
+
+    #[test]
+    fn force_change_selections() {
+        assert!(ForceChange::NONE.is_none());
+        assert_eq!(ForceChange::NONE.mask(), 0);
+        let user = ForceChange::new(true, false);
+        let system = ForceChange::new(false, true);
+        assert_eq!(user.union(system), ForceChange::ALL);
+        assert_eq!(ForceChange::ALL.mask() & !imp::PRESERVED, 0);
+    }
+
+    #[test]
+    fn make_mutable_without_selection_is_noop() {
+        let dir = tempfile::tempdir().expect("tempdir");
+        let path = dir.path().join("file");
+        std::fs::write(&path, b"data").expect("write");
+        assert_eq!(make_mutable(&path, ForceChange::NONE).expect("noop"), None);
+    }
+
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn make_mutable_clears_and_restores_immutable() {
+        use rustix::fs::IFlags;
+
+        let dir = tempfile::tempdir().expect("tempdir");
+        let path = dir.path().join("locked");
+        std::fs::write(&path, b"data").expect("write");
+
+        let immutable = IFlags::IMMUTABLE.bits();
+        if apply_file_flags(&path, immutable).is_err()
+            || read_file_flags(&path).ok().flatten().unwrap_or(0) & immutable == 0
+        {
+            return;
+        }
+
+        let previous = make_mutable(&path, ForceChange::ALL);
+        let cleared = read_file_flags(&path).ok().flatten();
+        let writable = std::fs::write(&path, b"new").is_ok();
+        if let Ok(Some(flags)) = &previous {
+            undo_make_mutable(&path, *flags).expect("restore flags");
+        }
+        let restored = read_file_flags(&path).ok().flatten();
+        let _ = apply_file_flags(&path, 0);
+
+        assert_eq!(
+            previous.expect("make mutable").map(|f| f & immutable),
+            Some(immutable)
+        );
+        assert_eq!(cleared.map(|f| f & immutable), Some(0));
+        assert!(writable);
+        assert_eq!(restored.map(|f| f & immutable), Some(immutable));
+    }
+
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn append_only_flag_is_copied_when_privileged() {
+        use rustix::fs::IFlags;
+
+        let dir = tempfile::tempdir().expect("tempdir");
+        let source = dir.path().join("source.log");
+        let destination = dir.path().join("dest.log");
+        std::fs::write(&source, b"log").expect("write source");
+        std::fs::write(&destination, b"log").expect("write destination");
+
+        let append = IFlags::APPEND.bits();
+        // Setting `+a` needs CAP_LINUX_IMMUTABLE and a filesystem with inode
+        // flags; skip where either is missing.
+        if apply_file_flags(&source, append).is_err()
+            || read_file_flags(&source).ok().flatten().unwrap_or(0) & append == 0
+        {
+            return;
+        }
+
+        let result = sync_file_flags(&source, &destination);
+        let copied = read_file_flags(&destination).ok().flatten();
+
+        // Clear the flags again so the tempdir can be removed.
+        let _ = apply_file_flags(&source, 0);
+        let _ = apply_file_flags(&destination, 0);
+
+        result.expect("sync file flags");
+        assert_eq!(copied.map(|flags| flags & append), Some(append));
+    }
+
+    #[cfg(any(
+        target_os = "macos",
+        target_os = "freebsd",
+        target_os = "dragonfly",
+        target_os = "netbsd",
+        target_os = "openbsd"
+    ))]
+    #[test]
+    fn user_append_flag_is_copied() {
+        use nix::sys::stat::FileFlag;
+
+        let dir = tempfile::tempdir().expect("tempdir");
+        let source = dir.path().join("source.log");
+        let destination = dir.path().join("dest.log");
+        std::fs::write(&source, b"log").expect("write source");
+        std::fs::write(&destination, b"log").expect("write destination");
+
+        let append = FileFlag::UF_APPEND.bits() as u32;
+        if apply_file_flags(&source, append).is_err() {
+            return;
+        }
+
+        let result = sync_file_flags(&source, &destination);
+        let copied = read_file_flags(&destination).ok().flatten();
+
+        let _ = apply_file_flags(&source, 0);
+        let _ = apply_file_flags(&destination, 0);
+
+        result.expect("sync file flags");
+        assert_eq!(copied.map(|flags| flags & append), Some(append));
+    }
+}
@@ -107,6 +107,7 @@ mod chmod;
 /// Privilege switching for `--copy-as=USER[:GROUP]`.
 pub mod copy_as;
 mod error;
+mod file_flags;
 
 /// Signed `--modify-window` tolerance and the `same_time()` mtime comparison.
 pub mod modify_window;
@@ -267,6 +268,11 @@ pub use chmod::{ChmodError, ChmodModifiers, directory_transfer_mode, transfer_ro
 
 pub use error::MetadataError;
 
+pub use file_flags::{
+    ForceChange, apply_file_flags, file_flags_supported, make_mutable, read_file_flags,
+    sync_file_flags, undo_make_mutable,
+};
+
 #[cfg(unix)]
 pub use mapping::{GroupMapping, MappingKind, MappingParseError, NameMapping, UserMapping};
 
@@ -284,14 +290,18 @@ pub use special::{
 
 #[cfg(all(feature = "xattr", any(unix, windows)))]
 pub use xattr::{
-    apply_xattrs_from_list, read_xattrs_for_wire, strip_source_xattrs, sync_xattrs, xattrs_match,
+    apply_xattrs_from_list, read_xattr, read_xattrs_for_wire, strip_source_xattrs, sync_xattrs,
+    xattrs_match,
 };
 
 #[cfg(not(all(feature = "xattr", any(unix, windows))))]
 pub use xattr_stub::{
-    apply_xattrs_from_list, read_xattrs_for_wire, strip_source_xattrs, sync_xattrs, xattrs_match,
+    apply_xattrs_from_list, read_xattr, read_xattrs_for_wire, strip_source_xattrs, sync_xattrs,
+    xattrs_match,
 };
 
+#[cfg(target_os = "linux")]
+pub use copy_as::{CopyAsFsGuard, switch_fs_ids};
 pub use copy_as::{CopyAsGuard, CopyAsIds, parse_copy_as_spec, switch_effective_ids};
 
 pub use fake_super::{
@@ -195,6 +195,16 @@ pub fn read_xattrs_for_wire(
     Ok(XattrList::with_entries(entries))
 }
 
+/// Reads the value of the extended attribute `name` on `path`, following
+/// symlinks.
+///
+/// Returns `Ok(None)` when the attribute is absent. Unlike the transfer
+/// helpers above, no namespace policy is applied: the caller names the one
+/// attribute it wants.
+pub fn read_xattr(path: &Path, name: &[u8]) -> Result<Option<Vec<u8>>, MetadataError> {
+    read_attribute(path, name, true)
+}
+
 /// Removes from `destination` every extended attribute that also exists on
 /// `source`, mirroring upstream `-a` without `-X`: a freshly written
 /// destination carries none of the source's xattrs.
@@ -55,6 +55,13 @@ pub fn strip_source_xattrs(
     Ok(())
 }
 
+/// Reads the value of the extended attribute `name` on `path`.
+///
+/// On platforms without xattr support, no attribute is ever present.
+pub fn read_xattr(_path: &Path, _name: &[u8]) -> Result<Option<Vec<u8>>, MetadataError> {
+    Ok(None)
+}
+
 /// Reads xattr data from a file and returns it as a wire-format `XattrList`.
 ///
 /// On platforms without xattr support, returns an empty list.
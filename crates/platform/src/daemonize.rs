@@ -21,6 +21,10 @@ use std::io;
 /// The parent process exits immediately after fork. The child continues as
 /// a background daemon. This matches upstream rsync's `become_daemon()`.
 ///
+/// The parent leaves through `_exit()` rather than [`std::process::exit`] so
+/// it does not run `atexit` handlers or flush stdio buffers the child also
+/// inherited, which would otherwise emit buffered output twice.
+///
 /// Must be called before spawning threads (fork is not async-signal-safe
 /// with threads).
 ///
@@ -34,7 +38,8 @@ pub fn become_daemon() -> io::Result<()> {
     match pid {
         -1 => return Err(io::Error::last_os_error()),
         0 => {}
-        _ => std::process::exit(0),
+        // SAFETY: _exit() is async-signal-safe and only terminates the parent.
+        _ => unsafe { libc::_exit(0) },
     }
 
     // upstream: clientserver.c:1478
@@ -0,0 +1,221 @@
+#![deny(unsafe_code)]
+//! Resume manifest exchange for daemon pulls (oc-rsync extension).
+//!
+//! A client reconnecting after an interrupted pull names the files it already
+//! holds, each with the MD5 digest of its contents. The daemon compares each
+//! digest against its copy and leaves matching files out of the file list, so
+//! the transfer continues with the remaining files instead of re-listing and
+//! re-checking everything.
+//!
+//! Upstream rsync has no equivalent, so both sides must opt in before any
+//! extra bytes travel:
+//!
+//! 1. The client appends [`RESUME_GREETING_TOKEN`] to the digest list of its
+//!    `@RSYNCD:` greeting. An upstream daemon drops digest names it does not
+//!    know, so the token is inert there (upstream: compat.c:parse_nni_str()).
+//! 2. An oc-rsync daemon that sees the token answers with
+//!    [`RESUME_ACCEPTED_LINE`] before `@RSYNCD: OK`. Without that line the
+//!    client sends nothing extra.
+//! 3. Once the daemon accepted, the client follows its argument list with the
+//!    manifest block, which may be empty.
+//!
+//! # Wire Format
+//!
+//! Each entry is one line: the digest as 32 lowercase hex digits, a space,
+//! and the path relative to the transfer root. An empty line ends the block:
+//!
+//! ```text
+//! 0123456789abcdef0123456789abcdef dir/file.txt\n
+//! \n
+//! ```
+//!
+//! Paths containing a newline cannot be expressed and are never sent.
+
+use std::io::{self, BufRead, Read, Write};
+
+/// Digest-list token a client adds to its greeting to offer the exchange.
+pub const RESUME_GREETING_TOKEN: &str = "oc-resume";
+
+/// Line an oc-rsync daemon sends before `@RSYNCD: OK` to accept the offer.
+pub const RESUME_ACCEPTED_LINE: &str = "@RSYNCD: OC-RESUME";
+
+/// Maximum number of entries a daemon accepts in one manifest block.
+pub const RESUME_MAX_ENTRIES: usize = 1 << 20;
+
+/// Longest manifest line accepted, including the digest and separator.
+const MAX_LINE_LEN: usize = 32 + 1 + 4096;
+
+/// One file the client already holds.
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub struct ResumeEntry {
+    /// MD5 digest of the file contents.
+    pub digest: [u8; 16],
+    /// Path relative to the transfer root, `/`-separated.
+    pub path: String,
+}
+
+/// Reports whether a client greeting carries [`RESUME_GREETING_TOKEN`].
+///
+/// Only the digest list after the version is searched.
+#[must_use]
+pub fn greeting_offers_resume(greeting: &str) -> bool {
+    greeting
+        .split_ascii_whitespace()
+        .skip(2)
+        .any(|token| token == RESUME_GREETING_TOKEN)
+}
+
+/// Writes a manifest block: one line per entry, then the empty terminator.
+///
+/// Entries whose path is empty or contains a newline are skipped.
+pub fn write_resume_manifest<W: Write + ?Sized>(
+    writer: &mut W,
+    entries: &[ResumeEntry],
+) -> io::Result<()> {
+    let mut line = String::with_capacity(MAX_LINE_LEN);
+    for entry in entries {
+        if entry.path.is_empty() || entry.path.contains('\n') {
+            continue;
+        }
+        line.clear();
+        for byte in entry.digest {
+            line.push(char::from(HEX[usize::from(byte >> 4)]));
+            line.push(char::from(HEX[usize::from(byte & 0x0f)]));
+        }
+        line.push(' ');
+        line.push_str(&entry.path);
+        line.push('\n');
+        writer.write_all(line.as_bytes())?;
+    }
+    writer.write_all(b"\n")?;
+    writer.flush()
+}
+
+/// Reads a manifest block up to its empty terminator line.
+///
+/// Malformed lines are skipped. Entries past `max_entries` are read and
+/// dropped so the stream stays aligned. EOF before the terminator ends the
+/// block; a line longer than the path limit is an error.
+pub fn read_resume_manifest<R: BufRead>(
+    reader: &mut R,
+    max_entries: usize,
+) -> io::Result<Vec<ResumeEntry>> {
+    let mut entries = Vec::new();
+    let mut line = Vec::new();
+    loop {
+        line.clear();
+        let read = reader
+            .by_ref()
+            .take(MAX_LINE_LEN as u64 + 1)
+            .read_until(b'\n', &mut line)?;
+        if read == 0 {
+            break;
+        }
+        if line.last() != Some(&b'\n') {
+            if read > MAX_LINE_LEN {
+                return Err(io::Error::new(
+                    io::ErrorKind::InvalidData,
+                    "resume manifest line exceeds the path length limit",
+                ));
+            }
+            // EOF mid-line: the client went away.
+            break;
+        }
+        line.pop();
+        if line.last() == Some(&b'\r') {
+            line.pop();
+        }
+        if line.is_empty() {
+            break;
+        }
+        if entries.len() < max_entries
+            && let Some(entry) = parse_entry(&line)
+        {
+            entries.push(entry);
+        }
+    }
+    Ok(entries)
+}
+
+const HEX: &[u8; 16] = b"0123456789abcdef";
+
+fn parse_entry(line: &[u8]) -> Option<ResumeEntry> {
+    let text = std::str::from_utf8(line).ok()?;
+    let (hex, path) = text.split_once(' ')?;
+    if hex.len() != 32 || path.is_empty() {
+        return None;
+    }
+    let mut digest = [0u8; 16];
+    for (slot, pair) in digest.iter_mut().zip(hex.as_bytes().chunks_exact(2)) {
+        let high = char::from(pair[0]).to_digit(16)?;
+        let low = char::from(pair[1]).to_digit(16)?;
+        *slot = ((high << 4) | low) as u8;
+    }
+    Some(ResumeEntry {
+        digest,
+        path: path.to_owned(),
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::io::Cursor;
+
+    fn entry(seed: u8, path: &str) -> ResumeEntry {
+        ResumeEntry {
+            digest: [seed; 16],
+            path: path.to_owned(),
+        }
+    }
+
+    #[test]
+    fn manifest_round_trips_and_stops_at_terminator() {
+        let entries = vec![entry(0xab, "a.txt"), entry(0x01, "dir/with space.bin")];
+        let mut wire = Vec::new();
+        write_resume_manifest(&mut wire, &entries).expect("write");
+        wire.extend_from_slice(b"trailing protocol bytes");
+
+        let mut reader = Cursor::new(wire);
+        let decoded = read_resume_manifest(&mut reader, RESUME_MAX_ENTRIES).expect("read");
+        assert_eq!(decoded, entries);
+
+        let mut rest = String::new();
+        reader.read_to_string(&mut rest).expect("rest");
+        assert_eq!(rest, "trailing protocol bytes");
+    }
+
+    #[test]
+    fn writer_skips_unrepresentable_paths() {
+        let mut wire = Vec::new();
+        write_resume_manifest(&mut wire, &[entry(0, "bad\nname"), entry(0, "")]).expect("write");
+        assert_eq!(wire, b"\n");
+    }
+
+    #[test]
+    fn reader_skips_malformed_lines_and_caps_entries() {
+        let wire = format!(
+            "nothex {p}\n{h} \n{h} one\n{h} two\n\n",
+            p = "x",
+            h = "00".repeat(16)
+        );
+        let decoded = read_resume_manifest(&mut Cursor::new(wire), 1).expect("read");
+        assert_eq!(decoded, vec![entry(0, "one")]);
+    }
+
+    #[test]
+    fn reader_rejects_overlong_lines() {
+        let wire = format!("{} {}\n\n", "00".repeat(16), "a".repeat(MAX_LINE_LEN));
+        let err = read_resume_manifest(&mut Cursor::new(wire), 8).expect_err("too long");
+        assert_eq!(err.kind(), io::ErrorKind::InvalidData);
+    }
+
+    #[test]
+    fn greeting_token_is_found_only_in_digest_list() {
+        assert!(greeting_offers_resume(
+            "@RSYNCD: 32.0 sha512 sha256 sha1 md5 md4 oc-resume"
+        ));
+        assert!(!greeting_offers_resume("@RSYNCD: 32.0 sha512 md5 md4"));
+        assert!(!greeting_offers_resume("oc-resume"));
+    }
+}
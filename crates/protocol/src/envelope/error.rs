@@ -19,6 +19,14 @@ pub enum EnvelopeError {
     /// The payload length exceeded the representable range.
     #[error("multiplexed payload length {0} exceeds maximum {MAX_PAYLOAD_LENGTH}")]
     OversizedPayload(u32),
+    /// A control message carried a payload length its code never uses.
+    #[error("invalid multi-message {code}:{len}")]
+    InvalidPayloadLength {
+        /// Numeric message code from the header.
+        code: u8,
+        /// Payload length from the header.
+        len: usize,
+    },
 }
 
 #[cfg(test)]
@@ -60,4 +68,12 @@ mod tests {
             format!("multiplexed payload length 42 exceeds maximum {MAX_PAYLOAD_LENGTH}"),
         );
     }
+
+    #[test]
+    fn display_formats_invalid_payload_length() {
+        assert_display(
+            EnvelopeError::InvalidPayloadLength { code: 9, len: 3 },
+            String::from("invalid multi-message 9:3"),
+        );
+    }
 }
@@ -232,6 +232,34 @@ impl MessageCode {
         )
     }
 
+    /// Checks a received payload length against the size this code requires.
+    ///
+    /// Control messages that carry a file index, an error-flag word or an exit
+    /// code have a fixed payload, so any other length means the stream lost
+    /// frame synchronisation. Codes with free-form payloads accept any length.
+    /// `MSG_SUCCESS` from a `local_server` peer appends a dev/ino guard after
+    /// the index, so it only needs the leading 4 bytes.
+    ///
+    /// upstream: io.c:read_a_msg() - `goto invalid_msg` when `msg_bytes` does
+    /// not match, which exits `RERR_STREAMIO`.
+    pub const fn validate_payload_len(self, len: usize) -> Result<(), EnvelopeError> {
+        let valid = match self {
+            Self::Redo | Self::IoError | Self::IoTimeout | Self::NoSend => len == 4,
+            Self::NoOp => len == 0,
+            Self::ErrorExit => len == 0 || len == 4,
+            Self::Success => len >= 4,
+            _ => true,
+        };
+        if valid {
+            Ok(())
+        } else {
+            Err(EnvelopeError::InvalidPayloadLength {
+                code: self.as_u8(),
+                len,
+            })
+        }
+    }
+
     /// Reports whether this message carries human-readable logging output.
     #[inline]
     #[must_use]
@@ -385,6 +413,41 @@ mod tests {
         assert_eq!(MessageCode::all().len(), 18);
     }
 
+    #[test]
+    fn validate_payload_len_enforces_fixed_sizes() {
+        for code in [
+            MessageCode::Redo,
+            MessageCode::IoError,
+            MessageCode::IoTimeout,
+            MessageCode::NoSend,
+        ] {
+            assert!(code.validate_payload_len(4).is_ok(), "{code:?}");
+            assert_eq!(
+                code.validate_payload_len(3),
+                Err(EnvelopeError::InvalidPayloadLength {
+                    code: code.as_u8(),
+                    len: 3
+                })
+            );
+        }
+        assert!(MessageCode::NoOp.validate_payload_len(0).is_ok());
+        assert!(MessageCode::NoOp.validate_payload_len(1).is_err());
+        assert!(MessageCode::ErrorExit.validate_payload_len(0).is_ok());
+        assert!(MessageCode::ErrorExit.validate_payload_len(4).is_ok());
+        assert!(MessageCode::ErrorExit.validate_payload_len(2).is_err());
+        assert!(MessageCode::Success.validate_payload_len(12).is_ok());
+        assert!(MessageCode::Success.validate_payload_len(2).is_err());
+    }
+
+    #[test]
+    fn validate_payload_len_accepts_any_free_form_payload() {
+        for code in [MessageCode::Data, MessageCode::Info, MessageCode::Deleted] {
+            for len in [0, 1, 4, 4096] {
+                assert!(code.validate_payload_len(len).is_ok(), "{code:?} {len}");
+            }
+        }
+    }
+
     #[test]
     fn flush_alias_equals_info() {
         assert_eq!(MessageCode::FLUSH, MessageCode::Info);
@@ -79,6 +79,23 @@ impl NegotiationError {
         }
     }
 
+    /// Names the offending version and the supported protocol span for an
+    /// out-of-range advertisement.
+    ///
+    /// The [`UnsupportedVersion`](Self::UnsupportedVersion) Display text is
+    /// upstream's verbatim wording, which names neither value. Handshake code
+    /// prefixes this hint so the diagnostic is actionable while the upstream
+    /// lines stay intact for tools that grep for them. Returns `None` for
+    /// other variants.
+    #[must_use]
+    pub fn supported_range_hint(&self) -> Option<String> {
+        let version = self.unsupported_version()?;
+        let (oldest, newest) = crate::version::SUPPORTED_PROTOCOL_BOUNDS;
+        Some(format!(
+            "peer advertised protocol version {version}, but only versions {oldest} through {newest} are supported"
+        ))
+    }
+
     /// Returns the malformed legacy greeting that triggered a parsing failure, if available.
     ///
     /// Daemon negotiations frequently log the offending banner to aid debugging. Providing a
@@ -133,6 +150,21 @@ mod tests {
         assert_eq!(err.unsupported_version(), Some(27));
     }
 
+    #[test]
+    fn range_hint_names_version_and_bounds() {
+        let hint = NegotiationError::UnsupportedVersion(99)
+            .supported_range_hint()
+            .expect("hint for unsupported version");
+        assert_eq!(
+            hint,
+            "peer advertised protocol version 99, but only versions 28 through 32 are supported"
+        );
+        let malformed = NegotiationError::MalformedLegacyGreeting {
+            input: String::new(),
+        };
+        assert_eq!(malformed.supported_range_hint(), None);
+    }
+
     #[test]
     fn display_echoes_malformed_legacy_greetings() {
         let err = NegotiationError::MalformedLegacyGreeting {
@@ -2,8 +2,10 @@
 //!
 //! upstream: `flist.c:make_file()` - creates `file_struct` from stat data.
 
-use std::path::PathBuf;
+use std::path::{Path, PathBuf};
+use std::sync::Arc;
 
+use super::super::intern::PathInterner;
 use super::FileEntry;
 use super::core::{PRESENT_CONTENT_DIR, extract_dirname};
 use super::extras::FileEntryExtras;
@@ -131,18 +133,16 @@ impl FileEntry {
     /// Creates a file entry from raw bytes (wire format, optimized).
     ///
     /// This avoids UTF-8 validation overhead during protocol decoding
-    /// by converting bytes directly to PathBuf on Unix (zero-copy).
+    /// by taking ownership of the bytes as the path on Unix (zero-copy).
     /// UTF-8 validation is deferred until display via `name()`.
     ///
     /// The dirname is extracted from the path automatically. For interned
-    /// dirname sharing, use [`Self::set_dirname`] after construction with a
-    /// value from [`super::super::intern::PathInterner`].
+    /// dirname sharing, use [`Self::from_raw_bytes_interned`], which avoids
+    /// allocating a per-entry dirname that would be replaced immediately.
     ///
     /// The `flags` parameter carries wire-encoding flags. Only the three
     /// semantically persistent bits (`top_dir`, `hlinked`, `hlink_first`)
     /// are stored; the remaining delta-encoding flags are discarded.
-    ///
-    /// This is the preferred constructor for wire protocol decoding.
     #[must_use]
     pub fn from_raw_bytes(
         name: Vec<u8>,
@@ -152,22 +152,43 @@ impl FileEntry {
         mtime_nsec: u32,
         flags: super::super::flags::FileFlags,
     ) -> Self {
-        #[cfg(unix)]
-        let path = {
-            use std::ffi::OsStr;
-            use std::os::unix::ffi::OsStrExt;
-            PathBuf::from(OsStr::from_bytes(&name))
-        };
-        #[cfg(not(unix))]
-        let path = {
-            // Non-Unix targets cannot use `OsStr::from_bytes`; lossy UTF-8
-            // conversion preserves displayability for non-UTF-8 names.
-            PathBuf::from(String::from_utf8_lossy(&name).into_owned())
-        };
-
+        let path = path_from_raw_bytes(name);
         let dirname = extract_dirname(&path);
+        Self::from_raw_parts(path, dirname, size, mode, mtime, mtime_nsec, flags)
+    }
+
+    /// Creates a file entry from raw bytes with its dirname taken from
+    /// `interner`.
+    ///
+    /// Entries in the same directory share one `Arc<Path>`, so a decoded list
+    /// allocates one dirname per directory rather than one per entry. This
+    /// is the preferred constructor for wire protocol decoding.
+    #[must_use]
+    pub fn from_raw_bytes_interned(
+        name: Vec<u8>,
+        size: u64,
+        mode: u32,
+        mtime: i64,
+        mtime_nsec: u32,
+        flags: super::super::flags::FileFlags,
+        interner: &mut PathInterner,
+    ) -> Self {
+        let path = path_from_raw_bytes(name);
+        let dirname = interner.intern(path.parent().unwrap_or_else(|| Path::new("")));
+        Self::from_raw_parts(path, dirname, size, mode, mtime, mtime_nsec, flags)
+    }
+
+    fn from_raw_parts(
+        name: PathBuf,
+        dirname: Arc<Path>,
+        size: u64,
+        mode: u32,
+        mtime: i64,
+        mtime_nsec: u32,
+        flags: super::super::flags::FileFlags,
+    ) -> Self {
         let mut entry = Self {
-            name: path,
+            name,
             dirname,
             size,
             mtime,
@@ -187,3 +208,19 @@ impl FileEntry {
         entry
     }
 }
+
+/// Converts wire name bytes into a path, reusing the buffer on Unix.
+fn path_from_raw_bytes(name: Vec<u8>) -> PathBuf {
+    #[cfg(unix)]
+    {
+        use std::ffi::OsString;
+        use std::os::unix::ffi::OsStringExt;
+        PathBuf::from(OsString::from_vec(name))
+    }
+    #[cfg(not(unix))]
+    {
+        // Non-Unix targets cannot reinterpret raw bytes as an `OsString`;
+        // lossy UTF-8 conversion preserves displayability for non-UTF-8 names.
+        PathBuf::from(String::from_utf8_lossy(&name).into_owned())
+    }
+}
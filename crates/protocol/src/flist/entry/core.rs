@@ -1,5 +1,5 @@
 use std::path::{Path, PathBuf};
-use std::sync::Arc;
+use std::sync::{Arc, OnceLock};
 
 use super::extras::FileEntryExtras;
 
@@ -129,14 +129,21 @@ pub struct FileEntry {
 /// Extracts the parent directory from a path.
 ///
 /// Returns the parent component as `Arc<Path>`. For paths without a directory
-/// separator (root-level entries), returns an `Arc` pointing to the empty path.
+/// separator (root-level entries), returns the shared [`empty_dirname`], so
+/// those entries carry no dirname allocation of their own.
 pub(super) fn extract_dirname(path: &Path) -> Arc<Path> {
     match path.parent() {
         Some(parent) if !parent.as_os_str().is_empty() => Arc::from(parent),
-        _ => Arc::from(Path::new("")),
+        _ => empty_dirname(),
     }
 }
 
+/// Process-wide dirname shared by every root-level entry.
+pub(crate) fn empty_dirname() -> Arc<Path> {
+    static EMPTY: OnceLock<Arc<Path>> = OnceLock::new();
+    Arc::clone(EMPTY.get_or_init(|| Arc::from(Path::new(""))))
+}
+
 impl Clone for FileEntry {
     fn clone(&self) -> Self {
         Self {
@@ -19,5 +19,6 @@ mod file_type;
 #[cfg(test)]
 mod tests;
 
+pub(crate) use self::core::empty_dirname;
 pub use self::core::FileEntry;
 pub use self::file_type::FileType;
@@ -21,6 +21,8 @@ use std::collections::HashMap;
 use std::path::{Path, PathBuf};
 use std::sync::Arc;
 
+use super::entry::empty_dirname;
+
 /// Deduplicates directory paths by mapping each unique path to a shared `Arc<Path>`.
 ///
 /// During file list construction, the interner is consulted for each entry's parent
@@ -43,7 +45,8 @@ use std::sync::Arc;
 pub struct PathInterner {
     /// Map from directory path to shared reference.
     map: HashMap<PathBuf, Arc<Path>>,
-    /// Cached `Arc<Path>` for the empty path (root-level entries with no dirname).
+    /// Cached `Arc<Path>` for the empty path (root-level entries with no dirname),
+    /// shared with entries built outside any interner.
     empty: Arc<Path>,
 }
 
@@ -59,7 +62,7 @@ impl PathInterner {
     pub fn new() -> Self {
         Self {
             map: HashMap::new(),
-            empty: Arc::from(Path::new("")),
+            empty: empty_dirname(),
         }
     }
 
@@ -69,7 +72,7 @@ impl PathInterner {
     pub fn with_capacity(capacity: usize) -> Self {
         Self {
             map: HashMap::with_capacity(capacity),
-            empty: Arc::from(Path::new("")),
+            empty: empty_dirname(),
         }
     }
 
@@ -0,0 +1,181 @@
+//! Caps on the file list a receiver accepts from an untrusted sender.
+//!
+//! `--max-alloc` bounds the memory the decoded list occupies, but its 1 GiB
+//! default leaves room for millions of entries and hundreds of megabytes of
+//! names before it trips. A client pulling from a daemon it does not control
+//! applies the tighter [`FileListLimits::default`] caps on top, so a
+//! malicious server cannot make it build an arbitrarily large list. An explicit
+//! `--max-alloc` scales those caps with it ([`FileListLimits::for_max_alloc`]),
+//! so a user pulling a legitimately huge tree raises both with one option.
+//! Local and server-side readers keep [`FileListLimits::UNLIMITED`].
+
+use std::error::Error;
+use std::fmt;
+use std::io;
+
+use crate::max_alloc::DEFAULT_MAX_ALLOC;
+
+/// Default cap on the number of entries accepted from an untrusted sender.
+pub const DEFAULT_MAX_FILE_LIST_ENTRIES: usize = 8 * 1024 * 1024;
+
+/// Default cap on the total bytes of entry names accepted from an untrusted
+/// sender (256 MiB).
+pub const DEFAULT_MAX_FILE_LIST_NAME_BYTES: usize = 256 * 1024 * 1024;
+
+/// Caps on the size of a received file list.
+///
+/// Both counts run across every segment read by one
+/// [`FileListReader`](super::FileListReader), so incremental recursion cannot
+/// side-step them by spreading the list over many directories.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct FileListLimits {
+    /// Largest number of entries accepted.
+    pub max_entries: usize,
+    /// Largest total length, in bytes, of the received entry names.
+    pub max_name_bytes: usize,
+}
+
+impl FileListLimits {
+    /// No cap beyond `--max-alloc`; used when the sender is trusted.
+    pub const UNLIMITED: Self = Self {
+        max_entries: usize::MAX,
+        max_name_bytes: usize::MAX,
+    };
+
+    /// Scales the default caps by `max_alloc` relative to the 1 GiB
+    /// [`DEFAULT_MAX_ALLOC`], so `--max-alloc=8G` admits eight times the
+    /// entries and name bytes and `--max-alloc=512M` half of them.
+    #[must_use]
+    pub const fn for_max_alloc(max_alloc: u64) -> Self {
+        Self {
+            max_entries: scale_cap(DEFAULT_MAX_FILE_LIST_ENTRIES, max_alloc),
+            max_name_bytes: scale_cap(DEFAULT_MAX_FILE_LIST_NAME_BYTES, max_alloc),
+        }
+    }
+
+    /// Checks the running totals after an entry has been decoded.
+    ///
+    /// # Errors
+    ///
+    /// Returns an [`OutOfMemory`](io::ErrorKind::OutOfMemory) error tagged
+    /// with [`FileListLimitExceeded`] once either total passes its cap.
+    pub fn check(&self, entries: usize, name_bytes: usize) -> io::Result<()> {
+        let exceeded = if entries > self.max_entries {
+            FileListLimitExceeded {
+                limit: self.max_entries,
+                what: "entries",
+            }
+        } else if name_bytes > self.max_name_bytes {
+            FileListLimitExceeded {
+                limit: self.max_name_bytes,
+                what: "bytes of names",
+            }
+        } else {
+            return Ok(());
+        };
+        Err(io::Error::new(io::ErrorKind::OutOfMemory, exceeded))
+    }
+}
+
+impl Default for FileListLimits {
+    /// The caps applied to a list received from an untrusted daemon.
+    fn default() -> Self {
+        Self {
+            max_entries: DEFAULT_MAX_FILE_LIST_ENTRIES,
+            max_name_bytes: DEFAULT_MAX_FILE_LIST_NAME_BYTES,
+        }
+    }
+}
+
+const fn scale_cap(default: usize, max_alloc: u64) -> usize {
+    let scaled = default as u128 * max_alloc as u128 / DEFAULT_MAX_ALLOC as u128;
+    if scaled > usize::MAX as u128 {
+        usize::MAX
+    } else {
+        scaled as usize
+    }
+}
+
+/// Inner marker error identifying an [`io::Error`] as a file list refused by
+/// its [`FileListLimits`].
+///
+/// Like [`MaxAllocExceeded`](crate::MaxAllocExceeded), the core exit-code
+/// mapper classifies it as `RERR_MALLOC` (22).
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct FileListLimitExceeded {
+    /// The cap that was passed.
+    pub limit: usize,
+    /// What the cap counts, e.g. `entries`.
+    pub what: &'static str,
+}
+
+impl fmt::Display for FileListLimitExceeded {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "received file list exceeds the limit of {} {} (raise it with --max-alloc)",
+            self.limit, self.what
+        )
+    }
+}
+
+impl Error for FileListLimitExceeded {}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn unlimited_accepts_any_total() {
+        assert!(
+            FileListLimits::UNLIMITED
+                .check(usize::MAX, usize::MAX)
+                .is_ok()
+        );
+    }
+
+    #[test]
+    fn for_max_alloc_scales_the_default_caps() {
+        assert_eq!(
+            FileListLimits::for_max_alloc(DEFAULT_MAX_ALLOC as u64),
+            FileListLimits::default()
+        );
+
+        let raised = FileListLimits::for_max_alloc(8 * DEFAULT_MAX_ALLOC as u64);
+        assert_eq!(raised.max_entries, 8 * DEFAULT_MAX_FILE_LIST_ENTRIES);
+        assert_eq!(raised.max_name_bytes, 8 * DEFAULT_MAX_FILE_LIST_NAME_BYTES);
+        assert!(raised.check(DEFAULT_MAX_FILE_LIST_ENTRIES + 1, 0).is_ok());
+
+        let lowered = FileListLimits::for_max_alloc(DEFAULT_MAX_ALLOC as u64 / 2);
+        assert_eq!(lowered.max_entries, DEFAULT_MAX_FILE_LIST_ENTRIES / 2);
+
+        let huge = FileListLimits::for_max_alloc(u64::MAX);
+        assert!(huge.max_entries >= DEFAULT_MAX_FILE_LIST_ENTRIES);
+    }
+
+    #[test]
+    fn check_refuses_totals_past_either_cap() {
+        let limits = FileListLimits {
+            max_entries: 10,
+            max_name_bytes: 100,
+        };
+        assert!(limits.check(10, 100).is_ok());
+
+        let err = limits.check(11, 0).expect_err("too many entries");
+        assert_eq!(err.kind(), io::ErrorKind::OutOfMemory);
+        assert_eq!(
+            err.to_string(),
+            "received file list exceeds the limit of 10 entries (raise it with --max-alloc)"
+        );
+
+        let err = limits.check(1, 101).expect_err("too many name bytes");
+        assert_eq!(
+            err.get_ref()
+                .and_then(|inner| inner.downcast_ref::<FileListLimitExceeded>()),
+            Some(&FileListLimitExceeded {
+                limit: 100,
+                what: "bytes of names"
+            })
+        );
+    }
+}
@@ -38,6 +38,7 @@ mod flags;
 mod hardlink;
 mod incremental;
 mod intern;
+mod limits;
 mod name_cmp;
 mod read;
 mod sort;
@@ -64,6 +65,10 @@ pub use incremental::{
     process_ready_entries, process_ready_entry,
 };
 pub use intern::PathInterner;
+pub use limits::{
+    DEFAULT_MAX_FILE_LIST_ENTRIES, DEFAULT_MAX_FILE_LIST_NAME_BYTES, FileListLimitExceeded,
+    FileListLimits,
+};
 pub use name_cmp::{f_name_cmp, f_name_cmp_components, name_cmp_eq};
 #[cfg(feature = "tokio-transfer")]
 pub use read::read_entry_with_flist_async;
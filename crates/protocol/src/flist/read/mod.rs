@@ -23,7 +23,6 @@ pub use async_read::read_entry_with_flist_async;
 pub use step::EntryStep;
 
 use std::io::{self, Read};
-use std::path::Path;
 
 use logging::debug_log;
 
@@ -37,6 +36,7 @@ use crate::xattr::XattrCache;
 use super::entry::FileEntry;
 use super::flags::FileFlags;
 use super::intern::PathInterner;
+use super::limits::FileListLimits;
 use super::state::{FileListCompressionState, FileListStats};
 
 pub use flags::FlagsResult;
@@ -157,6 +157,20 @@ pub struct FileListReader {
     /// Each file entry stores an index into this cache rather than duplicating
     /// the full xattr list. Mirrors upstream rsync's `rsync_xal_l`.
     xattr_cache: XattrCache,
+    /// Ceiling on the memory the decoded file list may occupy, in bytes.
+    ///
+    /// Seeded from the process-wide `--max-alloc` value when the reader is
+    /// built. upstream: util2.c:75 `my_alloc()` refuses any request that
+    /// reaches `max_alloc`.
+    max_alloc: usize,
+    /// Bytes occupied by the entries decoded so far, across every segment.
+    decoded_bytes: usize,
+    /// Caps on the entry count and total name bytes of the list.
+    limits: FileListLimits,
+    /// Entries decoded so far, across every segment.
+    decoded_entries: usize,
+    /// Name bytes decoded so far, across every segment.
+    decoded_name_bytes: usize,
 }
 
 impl FileListReader {
@@ -194,6 +208,11 @@ impl FileListReader {
             io_error: 0,
             acl_cache: AclCache::new(),
             xattr_cache: XattrCache::new(),
+            max_alloc: crate::max_alloc::effective_max_alloc(),
+            decoded_bytes: 0,
+            limits: FileListLimits::UNLIMITED,
+            decoded_entries: 0,
+            decoded_name_bytes: 0,
         }
     }
 
@@ -231,6 +250,11 @@ impl FileListReader {
             io_error: 0,
             acl_cache: AclCache::new(),
             xattr_cache: XattrCache::new(),
+            max_alloc: crate::max_alloc::effective_max_alloc(),
+            decoded_bytes: 0,
+            limits: FileListLimits::UNLIMITED,
+            decoded_entries: 0,
+            decoded_name_bytes: 0,
         }
     }
 
@@ -410,6 +434,29 @@ impl FileListReader {
         self
     }
 
+    /// Overrides the `--max-alloc` ceiling the decoded file list may occupy.
+    ///
+    /// Defaults to [`effective_max_alloc`](crate::effective_max_alloc) at
+    /// construction time.
+    #[inline]
+    #[must_use]
+    pub const fn with_max_alloc(mut self, bytes: usize) -> Self {
+        self.max_alloc = bytes;
+        self
+    }
+
+    /// Caps the entry count and total name bytes the reader accepts.
+    ///
+    /// Defaults to [`FileListLimits::UNLIMITED`]. A client pulling from a
+    /// daemon passes [`FileListLimits::default`] so an untrusted server
+    /// cannot grow the list without bound.
+    #[inline]
+    #[must_use]
+    pub const fn with_limits(mut self, limits: FileListLimits) -> Self {
+        self.limits = limits;
+        self
+    }
+
     /// Returns the statistics collected during file list reading.
     #[must_use]
     pub const fn stats(&self) -> &FileListStats {
@@ -687,26 +734,20 @@ impl FileListReader {
         // In --relative mode, leading slashes are stripped instead.
         let cleaned_name = self.clean_and_validate_name(converted_name)?;
 
-        // Construct entry from raw bytes (avoids UTF-8 validation on Unix)
-        let mut entry = FileEntry::from_raw_bytes(
+        // Construct entry from raw bytes (avoids UTF-8 validation on Unix) and
+        // intern the dirname so entries in the same directory share a single
+        // Arc<Path> allocation instead of each holding a separate copy.
+        // This mirrors upstream rsync's shared dirname pointer pool.
+        let mut entry = FileEntry::from_raw_bytes_interned(
             cleaned_name,
             size,
             metadata.mode,
             metadata.mtime,
             metadata.nsec,
             flags,
+            &mut self.dirname_interner,
         );
 
-        // Intern the dirname so entries in the same directory share
-        // a single Arc<Path> allocation instead of each holding a separate copy.
-        // This mirrors upstream rsync's shared dirname pointer pool.
-        let parent = entry.path().parent().filter(|p| !p.as_os_str().is_empty());
-        let interned_dirname = match parent {
-            Some(p) => self.dirname_interner.intern(p),
-            None => self.dirname_interner.intern(Path::new("")),
-        };
-        entry.set_dirname(interned_dirname);
-
         if let Some(target) = link_target {
             entry.set_link_target(target);
         }
@@ -768,6 +809,19 @@ impl FileListReader {
             entry.set_xattr_ndx(xattr_ndx);
         }
 
+        // upstream: flist.c:flist_expand() grows the file list and the
+        // file_struct pool through my_alloc(), which aborts with RERR_MALLOC
+        // once a request reaches --max-alloc. Charge each entry against the
+        // ceiling so a peer cannot grow the list without bound.
+        self.decoded_bytes = self.decoded_bytes.saturating_add(entry_footprint(&entry));
+        crate::max_alloc::check_max_alloc(self.decoded_bytes, self.max_alloc, "file list")?;
+        self.decoded_entries += 1;
+        self.decoded_name_bytes = self
+            .decoded_name_bytes
+            .saturating_add(entry.path().as_os_str().len());
+        self.limits
+            .check(self.decoded_entries, self.decoded_name_bytes)?;
+
         self.update_stats(&entry);
 
         debug_log!(
@@ -792,6 +846,17 @@ impl FileListReader {
     }
 }
 
+/// Approximate bytes `entry` keeps alive: the entry itself plus its
+/// variable-length name, symlink target and checksum.
+fn entry_footprint(entry: &FileEntry) -> usize {
+    std::mem::size_of::<FileEntry>()
+        + entry.path().as_os_str().len()
+        + entry
+            .link_target()
+            .map_or(0, |target| target.as_os_str().len())
+        + entry.checksum().map_or(0, <[u8]>::len)
+}
+
 /// Convenience function for reading individual entries without maintaining
 /// reader state. For reading multiple entries, use [`FileListReader`] to
 /// benefit from cross-entry compression.
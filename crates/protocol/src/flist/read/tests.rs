@@ -1364,6 +1364,134 @@ fn read_entry_accepts_name_below_maxpathlen() {
     assert_eq!(read_entry.name().len(), 255);
 }
 
+// --max-alloc file-list ceiling tests
+// upstream: flist.c:flist_expand() allocates through util2.c:my_alloc(), which
+// aborts with RERR_MALLOC once a request reaches --max-alloc.
+
+/// Encodes `count` 200-byte-named regular files followed by the end marker.
+fn oversized_file_list(count: usize) -> Vec<u8> {
+    use crate::flist::write::FileListWriter;
+
+    let mut data = Vec::new();
+    let mut writer = FileListWriter::new(test_protocol());
+    for i in 0..count {
+        let name = format!("{i:0>200}");
+        let mut entry = FileEntry::new_file(name.into(), 1, 0o100644);
+        entry.set_mtime(1700000000, 0);
+        writer.write_entry(&mut data, &entry).unwrap();
+    }
+    writer.write_end(&mut data, None).unwrap();
+    data
+}
+
+#[test]
+fn file_list_beyond_max_alloc_aborts() {
+    let data = oversized_file_list(1000);
+    let limit = 64 * 1024;
+    let mut cursor = Cursor::new(&data[..]);
+    let mut reader = FileListReader::new(test_protocol()).with_max_alloc(limit);
+
+    let mut decoded = 0;
+    let err = loop {
+        match reader.read_entry(&mut cursor) {
+            Ok(Some(_)) => decoded += 1,
+            Ok(None) => panic!("a {limit}-byte ceiling accepted all {decoded} entries"),
+            Err(err) => break err,
+        }
+    };
+    assert!(
+        decoded > 0 && decoded < 1000,
+        "aborted after {decoded} entries"
+    );
+    assert_eq!(err.kind(), io::ErrorKind::OutOfMemory);
+    assert!(
+        err.get_ref()
+            .is_some_and(|inner| inner.is::<crate::MaxAllocExceeded>())
+    );
+    assert_eq!(
+        err.to_string(),
+        "exceeded --max-alloc=65,536 setting (file list)"
+    );
+}
+
+#[test]
+fn file_list_within_max_alloc_is_read_in_full() {
+    let data = oversized_file_list(1000);
+    let mut cursor = Cursor::new(&data[..]);
+    let mut reader = FileListReader::new(test_protocol()).with_max_alloc(16 * 1024 * 1024);
+
+    let mut decoded = 0;
+    while reader.read_entry(&mut cursor).unwrap().is_some() {
+        decoded += 1;
+    }
+    assert_eq!(decoded, 1000);
+}
+
+// Untrusted-sender file-list caps
+
+/// Reads `data` to the end, returning the entries decoded before an error.
+fn read_until_error(reader: &mut FileListReader, data: &[u8]) -> (usize, io::Error) {
+    let mut cursor = Cursor::new(data);
+    let mut decoded = 0;
+    loop {
+        match reader.read_entry(&mut cursor) {
+            Ok(Some(_)) => decoded += 1,
+            Ok(None) => panic!("the whole list of {decoded} entries was accepted"),
+            Err(err) => return (decoded, err),
+        }
+    }
+}
+
+#[test]
+fn file_list_beyond_entry_limit_aborts() {
+    let data = oversized_file_list(100);
+    let mut reader = FileListReader::new(test_protocol()).with_limits(FileListLimits {
+        max_entries: 40,
+        ..FileListLimits::default()
+    });
+
+    let (decoded, err) = read_until_error(&mut reader, &data);
+    assert_eq!(decoded, 40);
+    assert_eq!(err.kind(), io::ErrorKind::OutOfMemory);
+    assert!(
+        err.get_ref()
+            .is_some_and(|inner| inner.is::<crate::flist::FileListLimitExceeded>())
+    );
+    assert_eq!(
+        err.to_string(),
+        "received file list exceeds the limit of 40 entries (raise it with --max-alloc)"
+    );
+}
+
+#[test]
+fn file_list_beyond_name_byte_limit_aborts() {
+    let data = oversized_file_list(100);
+    let mut reader = FileListReader::new(test_protocol()).with_limits(FileListLimits {
+        max_name_bytes: 10 * 200,
+        ..FileListLimits::default()
+    });
+
+    let (decoded, err) = read_until_error(&mut reader, &data);
+    assert_eq!(decoded, 10);
+    assert_eq!(
+        err.to_string(),
+        "received file list exceeds the limit of 2000 bytes of names (raise it with --max-alloc)"
+    );
+}
+
+#[test]
+fn default_limits_accept_an_ordinary_file_list() {
+    let data = oversized_file_list(1000);
+    let mut cursor = Cursor::new(&data[..]);
+    let mut reader = FileListReader::new(test_protocol()).with_limits(FileListLimits::default());
+
+    let mut decoded = 0;
+    while reader.read_entry(&mut cursor).unwrap().is_some() {
+        decoded += 1;
+    }
+    assert_eq!(decoded, 1000);
+}
+
 // Zero-length filename validation tests
 // upstream: flist.c:1909 - sender rejects empty names. These tests verify
 // that the receiver also rejects zero-length filenames as defense-in-depth.
@@ -60,6 +60,46 @@ fn write_then_read_round_trip() {
     assert_eq!(read_entry.size(), 1024);
 }
 
+/// The setuid, setgid, and sticky bits ride in the wire mode word alongside
+/// the type and permission bits, so a round trip must keep all twelve
+/// permission bits - including across `XMIT_SAME_MODE` reuse.
+#[test]
+fn write_then_read_preserves_special_mode_bits() {
+    use super::super::super::read::FileListReader;
+    use std::io::Cursor;
+
+    let entries = [
+        FileEntry::new_file("setuid".into(), 10, 0o4755),
+        FileEntry::new_file("setuid_again".into(), 10, 0o4755),
+        FileEntry::new_file("setuid_setgid".into(), 10, 0o6711),
+        FileEntry::new_directory("setgid_dir".into(), 0o2775),
+        FileEntry::new_directory("sticky_dir".into(), 0o1777),
+    ];
+
+    for version in [28u8, 30, 32] {
+        let protocol = ProtocolVersion::try_from(version).unwrap();
+        let mut buf = Vec::new();
+        let mut writer = FileListWriter::new(protocol);
+        for entry in &entries {
+            writer.write_entry(&mut buf, entry).unwrap();
+        }
+        writer.write_end(&mut buf, None).unwrap();
+
+        let mut cursor = Cursor::new(&buf[..]);
+        let mut reader = FileListReader::new(protocol);
+        for expected in &entries {
+            let read_entry = reader.read_entry(&mut cursor).unwrap().unwrap();
+            assert_eq!(read_entry.name(), expected.name());
+            assert_eq!(
+                read_entry.mode(),
+                expected.mode(),
+                "protocol {version}: mode of {} changed on the wire",
+                expected.name()
+            );
+        }
+    }
+}
+
 #[test]
 fn write_end_with_safe_file_list_enabled_transmits_error() {
     let protocol = test_protocol();
@@ -404,3 +404,86 @@ fn special_file_does_not_reset_carried_rdev_major() {
         assert_eq!(dev2.rdev_minor(), Some(5), "proto {proto_ver} dev2 minor");
     }
 }
+
+/// Pins the rdev bytes against upstream's encoding for every supported
+/// protocol, using numbers that exceed the 8-bit fields of the legacy
+/// `dev_t` layout. A device entry without hardlinks, ACLs or xattrs ends with
+/// its rdev, so the tail of each entry is exactly the encoded major/minor.
+///
+/// upstream: flist.c:send_file_entry() - `write_varint30(f, major(rdev))`
+/// unless XMIT_SAME_RDEV_MAJOR, then `write_varint` (30+), `write_byte` under
+/// XMIT_RDEV_MINOR_8_pre30, or `write_int` for the minor.
+#[test]
+fn device_rdev_wire_bytes_match_upstream_per_protocol() {
+    use super::super::super::read::FileListReader;
+    use std::io::Cursor;
+
+    // (protocol, first entry rdev tail, second entry rdev tail). The first
+    // device is (259, 65538); the second shares major 259 with minor 17, so
+    // its major is omitted via XMIT_SAME_RDEV_MAJOR.
+    let cases: [(u8, &[u8], &[u8]); 5] = [
+        (
+            28,
+            &[0x03, 0x01, 0x00, 0x00, 0x02, 0x00, 0x01, 0x00],
+            &[0x11],
+        ),
+        (
+            29,
+            &[0x03, 0x01, 0x00, 0x00, 0x02, 0x00, 0x01, 0x00],
+            &[0x11],
+        ),
+        (30, &[0x81, 0x03, 0xC1, 0x02, 0x00], &[0x11]),
+        (31, &[0x81, 0x03, 0xC1, 0x02, 0x00], &[0x11]),
+        (32, &[0x81, 0x03, 0xC1, 0x02, 0x00], &[0x11]),
+    ];
+
+    for (proto_ver, first_tail, second_tail) in cases {
+        let protocol = ProtocolVersion::try_from(proto_ver).unwrap();
+        let mut writer = FileListWriter::new(protocol)
+            .with_preserve_devices(true)
+            .with_preserve_specials(true);
+
+        let mut first = Vec::new();
+        writer
+            .write_entry(
+                &mut first,
+                &FileEntry::new_char_device("nvme".into(), 0o600, 259, 65538),
+            )
+            .unwrap();
+        let mut second = Vec::new();
+        writer
+            .write_entry(
+                &mut second,
+                &FileEntry::new_block_device("nvme1".into(), 0o660, 259, 17),
+            )
+            .unwrap();
+        assert!(
+            first.ends_with(first_tail),
+            "proto {proto_ver}: first rdev bytes {first:02x?} do not end with {first_tail:02x?}"
+        );
+        assert!(
+            second.ends_with(second_tail),
+            "proto {proto_ver}: second rdev bytes {second:02x?} do not end with {second_tail:02x?}"
+        );
+
+        let mut buf = first;
+        buf.extend_from_slice(&second);
+        writer.write_end(&mut buf, None).unwrap();
+        let mut cursor = Cursor::new(&buf[..]);
+        let mut reader = FileListReader::new(protocol)
+            .with_preserve_devices(true)
+            .with_preserve_specials(true);
+        let dev1 = reader.read_entry(&mut cursor).unwrap().unwrap();
+        let dev2 = reader.read_entry(&mut cursor).unwrap().unwrap();
+        assert!(dev1.is_char_device(), "proto {proto_ver} dev1 kind");
+        assert_eq!(dev1.rdev_major(), Some(259), "proto {proto_ver} dev1 major");
+        assert_eq!(
+            dev1.rdev_minor(),
+            Some(65538),
+            "proto {proto_ver} dev1 minor"
+        );
+        assert!(dev2.is_block_device(), "proto {proto_ver} dev2 kind");
+        assert_eq!(dev2.rdev_major(), Some(259), "proto {proto_ver} dev2 major");
+        assert_eq!(dev2.rdev_minor(), Some(17), "proto {proto_ver} dev2 minor");
+    }
+}
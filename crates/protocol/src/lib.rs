@@ -112,6 +112,8 @@ pub mod cmd;
 /// [`codec::NdxCodec`] for file-list index encoding. See [`codec`] for details.
 pub mod codec;
 mod compatibility;
+/// Resume manifest exchange for daemon pulls (oc-rsync extension).
+pub mod daemon_resume;
 /// Debug I/O tracing for protocol wire operations.
 pub mod debug_io;
 /// Debug tracing system for protocol analysis.
@@ -139,6 +141,8 @@ mod multiplex;
 mod negotiation;
 /// `--debug=NSTR` producer emissions for algorithm-negotiation strings.
 pub mod nstr;
+/// Marker error type for a multiplexed stream that lost framing (RERR_STREAMIO).
+pub mod protocol_desync;
 /// Marker error type for genuine protocol violations (RERR_PROTOCOL).
 pub mod protocol_violation;
 /// Secluded-args (protect-args) stdin argument transmission protocol.
@@ -187,7 +191,9 @@ pub use legacy::{
     parse_legacy_error_message_bytes, parse_legacy_warning_message,
     parse_legacy_warning_message_bytes, write_legacy_daemon_greeting, write_legacy_daemon_message,
 };
-pub use max_alloc::{DEFAULT_MAX_ALLOC, effective_max_alloc, set_max_alloc};
+pub use max_alloc::{
+    DEFAULT_MAX_ALLOC, MaxAllocExceeded, check_max_alloc, effective_max_alloc, set_max_alloc,
+};
 #[cfg(feature = "async")]
 #[cfg_attr(docsrs, doc(cfg(feature = "async")))]
 pub use multiplex::MultiplexCodec;
@@ -206,6 +212,7 @@ pub use negotiation::{
     read_and_parse_legacy_daemon_greeting, read_and_parse_legacy_daemon_greeting_details,
     read_legacy_daemon_line,
 };
+pub use protocol_desync::{ProtocolDesync, protocol_desync};
 pub use protocol_violation::{ProtocolViolation, protocol_violation};
 pub use stats::{CreatedStats, DeleteStats, TransferStats};
 pub use varint::{
@@ -18,6 +18,9 @@
 //! - `util2.c:73-81` - `my_alloc()` aborts with `RERR_MALLOC` once a request
 //!   reaches `max_alloc`.
 
+use std::error::Error;
+use std::fmt;
+use std::io;
 use std::sync::atomic::{AtomicUsize, Ordering};
 
 /// Default `--max-alloc` ceiling in bytes (1 GiB).
@@ -56,9 +59,76 @@ pub fn effective_max_alloc() -> usize {
     MAX_ALLOC.load(Ordering::Relaxed)
 }
 
+/// Inner marker error identifying an [`io::Error`] as an allocation refused
+/// by the `--max-alloc` ceiling.
+///
+/// Carried by an [`OutOfMemory`](io::ErrorKind::OutOfMemory) error; the core
+/// exit-code mapper classifies it as `RERR_MALLOC` (22). The
+/// [`Display`](fmt::Display) matches upstream's `exceeded --max-alloc=SIZE
+/// setting` diagnostic, naming what was being allocated.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct MaxAllocExceeded {
+    /// The ceiling in force when the allocation was refused.
+    pub limit: usize,
+    /// What was being allocated, e.g. `file list`.
+    pub what: &'static str,
+}
+
+impl fmt::Display for MaxAllocExceeded {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "exceeded --max-alloc={} setting ({})",
+            big_num(self.limit),
+            self.what
+        )
+    }
+}
+
+impl Error for MaxAllocExceeded {}
+
+/// Checks a prospective allocation of `bytes` against `limit`.
+///
+/// Like upstream's `my_alloc()`, a request that meets or exceeds the ceiling
+/// is refused, so a peer cannot make the receiver grow a structure past the
+/// bound the user configured.
+///
+/// # Errors
+///
+/// Returns an [`OutOfMemory`](io::ErrorKind::OutOfMemory) error tagged with
+/// [`MaxAllocExceeded`] when `bytes >= limit`.
+///
+/// upstream: `util2.c:75` - `if (max_alloc && num >= max_alloc/size)`.
+pub fn check_max_alloc(bytes: usize, limit: usize, what: &'static str) -> io::Result<()> {
+    if bytes >= limit {
+        return Err(io::Error::new(
+            io::ErrorKind::OutOfMemory,
+            MaxAllocExceeded { limit, what },
+        ));
+    }
+    Ok(())
+}
+
+/// Formats `value` with comma separators, matching upstream's
+/// `do_big_num()` as used in the `--max-alloc` diagnostic.
+fn big_num(value: usize) -> String {
+    let digits = value.to_string();
+    let len = digits.len();
+    let mut out = String::with_capacity(len + len / 3);
+    for (i, ch) in digits.chars().enumerate() {
+        if i > 0 && (len - i) % 3 == 0 {
+            out.push(',');
+        }
+        out.push(ch);
+    }
+    out
+}
+
 #[cfg(test)]
 mod tests {
-    use super::{DEFAULT_MAX_ALLOC, effective_max_alloc, set_max_alloc};
+    use super::{
+        DEFAULT_MAX_ALLOC, MaxAllocExceeded, check_max_alloc, effective_max_alloc, set_max_alloc,
+    };
 
     /// The ceiling defaults to the upstream `DEFAULT_MAX_ALLOC` so that, absent
     /// an explicit `--max-alloc`, decoders enforce the same 1 GiB bound upstream
@@ -88,4 +158,26 @@ mod tests {
         assert_eq!(effective_max_alloc(), 4096);
         set_max_alloc(restore);
     }
+
+    /// A request that reaches the ceiling is refused with the tagged
+    /// `OutOfMemory` error and upstream's wording; one below it passes.
+    /// upstream: util2.c:75-79.
+    #[test]
+    fn check_refuses_requests_reaching_the_limit() {
+        assert!(check_max_alloc(4095, 4096, "file list").is_ok());
+        let err = check_max_alloc(4096, 4096, "file list").expect_err("at the limit");
+        assert_eq!(err.kind(), std::io::ErrorKind::OutOfMemory);
+        assert_eq!(
+            err.get_ref()
+                .and_then(|inner| inner.downcast_ref::<MaxAllocExceeded>()),
+            Some(&MaxAllocExceeded {
+                limit: 4096,
+                what: "file list"
+            })
+        );
+        assert_eq!(
+            err.to_string(),
+            "exceeded --max-alloc=4,096 setting (file list)"
+        );
+    }
 }
@@ -15,16 +15,22 @@ pub(super) fn decode_header(header_bytes: &[u8; HEADER_LEN]) -> io::Result<Messa
     MessageHeader::decode(header_bytes).map_err(map_envelope_error)
 }
 
+/// Maps a header that failed to decode off the wire to a desync error.
+///
+/// A received tag outside the message table means the reader is no longer
+/// aligned on a frame boundary.
+///
+/// upstream: io.c:read_a_msg() - `unexpected tag`, `exit_cleanup(RERR_STREAMIO)`.
 #[cold]
 pub(super) fn map_envelope_error(err: EnvelopeError) -> io::Error {
-    io::Error::new(io::ErrorKind::InvalidData, err)
+    crate::protocol_desync(err.to_string())
 }
 
 #[cold]
 pub(super) fn map_envelope_error_for_input(err: EnvelopeError) -> io::Error {
     match err {
         EnvelopeError::OversizedPayload(_) => io::Error::new(io::ErrorKind::InvalidInput, err),
-        other => map_envelope_error(other),
+        other => io::Error::new(io::ErrorKind::InvalidData, other),
     }
 }
 
@@ -184,6 +190,12 @@ mod tests {
         let err = EnvelopeError::InvalidTag(5);
         let io_err = map_envelope_error(err);
         assert_eq!(io_err.kind(), io::ErrorKind::InvalidData);
+        assert!(
+            io_err
+                .get_ref()
+                .is_some_and(|inner| inner.is::<crate::ProtocolDesync>())
+        );
+        assert!(io_err.to_string().starts_with("protocol desync: "));
     }
 
     #[test]
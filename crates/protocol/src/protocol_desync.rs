@@ -0,0 +1,73 @@
+//! Marker error for a multiplexed stream that lost frame synchronisation.
+//!
+//! When the two sides disagree about where one multiplexed message ends and
+//! the next begins - a peer bug, a truncated write, or corruption in transit -
+//! the bytes read as a header are really payload. The tag then decodes to a
+//! value outside the message table, or a control message carries a payload
+//! whose length its code never uses. Reading on would misinterpret every
+//! following byte, so the reader stops at the first such frame instead of
+//! hanging on a length that was never meant as one.
+//!
+//! [`ProtocolDesync`] tags those errors. Like [`crate::ProtocolViolation`] it
+//! is the inner error of an [`InvalidData`](std::io::ErrorKind::InvalidData)
+//! [`std::io::Error`], but it maps to `RERR_STREAMIO` (12) - the code upstream
+//! exits with for a broken multiplexed stream - rather than `RERR_PROTOCOL`.
+//!
+//! # Upstream Reference
+//!
+//! `io.c:read_a_msg()` - an unknown tag reports `unexpected tag` and a
+//! control message of the wrong size reports `invalid multi-message`; both
+//! call `exit_cleanup(RERR_STREAMIO)`.
+
+use std::error::Error;
+use std::fmt;
+use std::io;
+
+/// Inner marker error identifying an [`io::Error`] as a lost-framing
+/// condition on the multiplexed stream.
+///
+/// Its [`Display`](fmt::Display) prefixes the detail with `protocol desync:`
+/// so the diagnostic names the failure class.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ProtocolDesync(pub String);
+
+impl fmt::Display for ProtocolDesync {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "protocol desync: {}", self.0)
+    }
+}
+
+impl Error for ProtocolDesync {}
+
+/// Builds an [`io::Error`] of kind [`InvalidData`](io::ErrorKind::InvalidData)
+/// tagged as a [`ProtocolDesync`].
+///
+/// The core exit-code mapper classifies it as `RERR_STREAMIO` (12), the same
+/// as any other untagged `InvalidData` error.
+pub fn protocol_desync(detail: impl Into<String>) -> io::Error {
+    io::Error::new(io::ErrorKind::InvalidData, ProtocolDesync(detail.into()))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn display_names_the_failure_class() {
+        let err = protocol_desync("invalid multi-message 9:3");
+        assert_eq!(err.kind(), io::ErrorKind::InvalidData);
+        assert_eq!(
+            err.to_string(),
+            "protocol desync: invalid multi-message 9:3"
+        );
+    }
+
+    #[test]
+    fn inner_downcasts_to_marker() {
+        let err = protocol_desync("unexpected tag");
+        let inner = err
+            .get_ref()
+            .and_then(|e| e.downcast_ref::<ProtocolDesync>());
+        assert_eq!(inner, Some(&ProtocolDesync("unexpected tag".into())));
+    }
+}
@@ -0,0 +1,144 @@
+//! Heap allocation counts for building a 100K-entry file list.
+//!
+//! A counting global allocator records allocations made on the test thread
+//! while entries are constructed from pre-built wire names, so the numbers
+//! isolate the per-entry representation cost from fixture setup.
+//!
+//! - Root-level entries share one process-wide empty dirname and, on Unix,
+//!   take ownership of the wire name buffer: no allocations at all.
+//! - Nested entries built with `from_raw_bytes_interned` allocate once per
+//!   directory, where `from_raw_bytes` followed by `set_dirname` (the
+//!   decoder's former shape) still allocates a throwaway dirname per entry.
+
+#![cfg(unix)]
+
+use std::alloc::{GlobalAlloc, Layout, System};
+use std::cell::Cell;
+use std::path::Path;
+
+use protocol::flist::{FileEntry, FileFlags, PathInterner};
+
+/// Number of entries in the synthetic list.
+const FILES: usize = 100_000;
+
+/// Number of distinct parent directories in the nested list.
+const DIRS: usize = 1_000;
+
+struct CountingAlloc;
+
+thread_local! {
+    static ALLOCATIONS: Cell<usize> = const { Cell::new(0) };
+}
+
+// SAFETY: forwards every call to `System` unchanged; the thread-local counter
+// is const-initialised and has no destructor, so touching it never allocates.
+unsafe impl GlobalAlloc for CountingAlloc {
+    unsafe fn alloc(&self, layout: Layout) -> *mut u8 {
+        let _ = ALLOCATIONS.try_with(|n| n.set(n.get() + 1));
+        // SAFETY: caller upholds `GlobalAlloc::alloc`'s contract.
+        unsafe { System.alloc(layout) }
+    }
+
+    unsafe fn dealloc(&self, ptr: *mut u8, layout: Layout) {
+        // SAFETY: caller upholds `GlobalAlloc::dealloc`'s contract.
+        unsafe { System.dealloc(ptr, layout) }
+    }
+
+    unsafe fn realloc(&self, ptr: *mut u8, layout: Layout, new_size: usize) -> *mut u8 {
+        let _ = ALLOCATIONS.try_with(|n| n.set(n.get() + 1));
+        // SAFETY: caller upholds `GlobalAlloc::realloc`'s contract.
+        unsafe { System.realloc(ptr, layout, new_size) }
+    }
+}
+
+#[global_allocator]
+static GLOBAL: CountingAlloc = CountingAlloc;
+
+/// Returns the allocations `f` performs on the current thread.
+fn count_allocations(f: impl FnOnce()) -> usize {
+    let before = ALLOCATIONS.with(Cell::get);
+    f();
+    ALLOCATIONS.with(Cell::get) - before
+}
+
+fn root_names() -> Vec<Vec<u8>> {
+    (0..FILES)
+        .map(|i| format!("file{i:06}").into_bytes())
+        .collect()
+}
+
+fn nested_names() -> Vec<Vec<u8>> {
+    (0..FILES)
+        .map(|i| format!("d{:04}/sub/file{i:06}", i % DIRS).into_bytes())
+        .collect()
+}
+
+fn entry(name: Vec<u8>) -> FileEntry {
+    FileEntry::from_raw_bytes(name, 1024, 0o100644, 0, 0, FileFlags::default())
+}
+
+#[test]
+fn root_level_entries_allocate_nothing() {
+    // Initialise the shared empty dirname outside the measured region.
+    drop(entry(b"warmup".to_vec()));
+
+    let names = root_names();
+    let mut list = Vec::with_capacity(FILES);
+    let allocations = count_allocations(|| {
+        list.extend(names.into_iter().map(entry));
+    });
+
+    assert_eq!(list.len(), FILES);
+    assert!(list.iter().all(|e| e.dirname().as_os_str().is_empty()));
+    // Previously two per entry: a copy of the name and an empty dirname `Arc`.
+    assert_eq!(allocations, 0, "root-level entries must not allocate");
+}
+
+#[test]
+fn interned_nested_entries_allocate_per_directory() {
+    let mut interner = PathInterner::with_capacity(DIRS);
+    let names = nested_names();
+    let mut before_list = Vec::with_capacity(FILES);
+    let before = count_allocations(|| {
+        for name in names {
+            let mut e = entry(name);
+            let dirname = interner.intern(e.path().parent().unwrap_or(Path::new("")));
+            e.set_dirname(dirname);
+            before_list.push(e);
+        }
+    });
+
+    let mut interner = PathInterner::with_capacity(DIRS);
+    let names = nested_names();
+    let mut after_list = Vec::with_capacity(FILES);
+    let after = count_allocations(|| {
+        for name in names {
+            after_list.push(FileEntry::from_raw_bytes_interned(
+                name,
+                1024,
+                0o100644,
+                0,
+                0,
+                FileFlags::default(),
+                &mut interner,
+            ));
+        }
+    });
+
+    assert_eq!(interner.len(), DIRS);
+    assert!(
+        before_list
+            .iter()
+            .zip(&after_list)
+            .all(|(b, a)| b.path() == a.path() && b.dirname() == a.dirname())
+    );
+    // One interner key plus one shared `Arc<Path>` per directory.
+    assert!(
+        after <= 2 * DIRS,
+        "{after} allocations for {FILES} entries in {DIRS} dirs"
+    );
+    assert!(
+        before >= FILES + after,
+        "per-entry dirname path: {before}, interned path: {after}"
+    );
+}
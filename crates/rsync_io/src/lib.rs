@@ -76,6 +76,9 @@ mod negotiation;
 mod session;
 /// SSH transport implementations and helpers.
 pub mod ssh;
+/// TLS transport for daemon connections (oc-rsync extension).
+#[cfg(all(unix, feature = "tls"))]
+pub mod tls;
 
 pub use binary::{
     BinaryHandshake, BinaryHandshakeParts, negotiate_binary_session,
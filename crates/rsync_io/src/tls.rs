@@ -0,0 +1,538 @@
+//! TLS transport for daemon connections.
+//!
+//! oc-rsync extension with no upstream equivalent. Upstream rsync leaves
+//! daemon encryption to an external proxy (`rsync-ssl` on the client, stunnel
+//! in front of the daemon); this module terminates TLS in-process so
+//! `oc-rsync --tls` can talk to an `oc-rsync --daemon` configured with
+//! `tls cert file` / `tls key file`. The bytes inside the TLS session are the
+//! unchanged `@RSYNCD:` protocol, but no stock rsync speaks the outer layer:
+//! both ends must be oc-rsync, or the stock peer must sit behind a TLS proxy.
+//!
+//! The protocol layers never see the TLS session. [`TlsStream::relay`] copies
+//! bytes between the encrypted socket and a local plaintext socket - the same
+//! shape as stunnel in front of a plain daemon - so socket timeouts, cloned
+//! read/write halves and half-close keep working on an ordinary socket.
+
+use std::io::{self, Read, Write};
+use std::net::{Shutdown, TcpStream};
+use std::os::fd::AsFd;
+use std::os::unix::net::UnixStream;
+use std::path::Path;
+use std::time::{Duration, Instant};
+
+use nix::errno::Errno;
+use nix::poll::{PollFd, PollFlags, PollTimeout, poll};
+use openssl::error::ErrorStack;
+use openssl::ssl::{
+    self, ErrorCode, HandshakeError, SslAcceptor, SslConnector, SslFiletype, SslMethod, SslStream,
+};
+use openssl::x509::X509VerifyResult;
+
+/// Environment variable naming a PEM bundle of CA certificates that `--tls`
+/// trusts in addition to the system store.
+///
+/// Same name and meaning as in upstream's `rsync-ssl` helper script, so an
+/// existing deployment's trust configuration carries over.
+pub const TLS_CA_CERT_ENV: &str = "RSYNC_SSL_CA_CERT";
+
+/// Size of each relay copy buffer, one maximum-size TLS record.
+const RELAY_BUFFER_SIZE: usize = 16 * 1024;
+
+/// How long the relay keeps forwarding peer bytes after the local side has
+/// closed, before giving up on a peer that never closes its half.
+const RELAY_LINGER: Duration = Duration::from_secs(30);
+
+/// Server-side TLS configuration for the daemon listener.
+pub struct TlsServer {
+    acceptor: SslAcceptor,
+}
+
+impl TlsServer {
+    /// Loads a PEM certificate chain and its private key.
+    ///
+    /// The chain file holds the server certificate first, followed by any
+    /// intermediates. Fails when either file cannot be read or the key does
+    /// not match the certificate.
+    pub fn from_pem_files(certificate: &Path, key: &Path) -> io::Result<Self> {
+        let mut builder =
+            SslAcceptor::mozilla_intermediate_v5(SslMethod::tls_server()).map_err(stack_error)?;
+        builder
+            .set_certificate_chain_file(certificate)
+            .map_err(|error| file_error("certificate", certificate, error))?;
+        builder
+            .set_private_key_file(key, SslFiletype::PEM)
+            .map_err(|error| file_error("private key", key, error))?;
+        builder.check_private_key().map_err(|error| {
+            io::Error::new(
+                io::ErrorKind::InvalidInput,
+                format!(
+                    "private key {} does not match certificate {}: {error}",
+                    key.display(),
+                    certificate.display()
+                ),
+            )
+        })?;
+        Ok(Self {
+            acceptor: builder.build(),
+        })
+    }
+
+    /// Performs the server side of the TLS handshake on an accepted socket.
+    ///
+    /// `timeout` bounds the handshake so a client that never speaks TLS - a
+    /// plaintext rsync client waiting for the daemon greeting - cannot hold
+    /// the connection open indefinitely.
+    pub fn accept(&self, stream: TcpStream, timeout: Option<Duration>) -> io::Result<TlsStream> {
+        set_handshake_timeout(&stream, timeout)?;
+        let inner = self.acceptor.accept(stream).map_err(handshake_error)?;
+        Ok(TlsStream { inner })
+    }
+}
+
+/// Client-side TLS configuration for `--tls` daemon connections.
+pub struct TlsClient {
+    connector: SslConnector,
+}
+
+impl TlsClient {
+    /// Builds a client that verifies the daemon certificate against the
+    /// system trust store, plus the PEM bundle at `ca_file` when given.
+    pub fn new(ca_file: Option<&Path>) -> io::Result<Self> {
+        let mut builder = SslConnector::builder(SslMethod::tls_client()).map_err(stack_error)?;
+        if let Some(path) = ca_file {
+            builder
+                .set_ca_file(path)
+                .map_err(|error| file_error("CA certificate", path, error))?;
+        }
+        Ok(Self {
+            connector: builder.build(),
+        })
+    }
+
+    /// Builds a client trusting the bundle named by [`TLS_CA_CERT_ENV`], if
+    /// set, in addition to the system store.
+    pub fn from_env() -> io::Result<Self> {
+        let ca_file = std::env::var_os(TLS_CA_CERT_ENV).filter(|value| !value.is_empty());
+        Self::new(ca_file.as_deref().map(Path::new))
+    }
+
+    /// Performs the client side of the TLS handshake.
+    ///
+    /// The daemon certificate must be valid for `host`, a DNS name or an IP
+    /// literal (IPv6 literals may keep their URL brackets).
+    pub fn connect(
+        &self,
+        host: &str,
+        stream: TcpStream,
+        timeout: Option<Duration>,
+    ) -> io::Result<TlsStream> {
+        set_handshake_timeout(&stream, timeout)?;
+        let host = host
+            .strip_prefix('[')
+            .and_then(|rest| rest.strip_suffix(']'))
+            .unwrap_or(host);
+        let inner = self
+            .connector
+            .connect(host, stream)
+            .map_err(handshake_error)?;
+        Ok(TlsStream { inner })
+    }
+}
+
+/// An established TLS session over TCP.
+pub struct TlsStream {
+    inner: SslStream<TcpStream>,
+}
+
+impl TlsStream {
+    /// Copies bytes between the TLS session and `local` until both
+    /// directions have closed.
+    ///
+    /// Blocks the calling thread; callers run it on a dedicated thread and
+    /// hand the other end of `local` to the protocol layer. End of stream on
+    /// either side is forwarded as a half-close to the other (`close_notify`
+    /// followed by `shutdown(SHUT_WR)` towards the peer), so the protocol's
+    /// own end-of-session exchange is unaffected.
+    pub fn relay<L: RelaySocket>(self, mut local: L) -> io::Result<()> {
+        let mut tls = self.inner;
+        tls.get_ref().set_read_timeout(None)?;
+        tls.get_ref().set_write_timeout(None)?;
+        tls.get_ref().set_nonblocking(true)?;
+        local.set_nonblocking(true)?;
+
+        let mut inbound = RelayBuffer::new();
+        let mut outbound = RelayBuffer::new();
+        let mut peer_eof = false;
+        let mut local_eof = false;
+        let mut peer_closed = false;
+        let mut local_closed = false;
+        let mut linger_deadline: Option<Instant> = None;
+
+        loop {
+            let mut progress = false;
+            // OpenSSL may need the opposite readiness to make progress, e.g.
+            // a read that must first flush a key update.
+            let mut tls_wants_read = false;
+            let mut tls_wants_write = false;
+
+            // Peer -> local.
+            if !peer_eof && inbound.is_empty() {
+                match tls.ssl_read(inbound.spare()) {
+                    Ok(count) => {
+                        inbound.fill(count);
+                        progress = true;
+                    }
+                    Err(error) => match error.code() {
+                        ErrorCode::WANT_READ => tls_wants_read = true,
+                        ErrorCode::WANT_WRITE => tls_wants_write = true,
+                        ErrorCode::ZERO_RETURN => {
+                            peer_eof = true;
+                            progress = true;
+                        }
+                        // A TCP FIN without close_notify; treat it as end of
+                        // stream rather than failing a finished session.
+                        ErrorCode::SYSCALL if error.io_error().is_none() => {
+                            peer_eof = true;
+                            progress = true;
+                        }
+                        _ => return Err(ssl_error(error)),
+                    },
+                }
+            }
+            if !inbound.is_empty() {
+                match local.write(inbound.pending()) {
+                    Ok(0) => return Err(io::ErrorKind::WriteZero.into()),
+                    Ok(count) => {
+                        inbound.consume(count);
+                        progress = true;
+                    }
+                    Err(error) if is_retryable(&error) => {}
+                    Err(error) => return Err(error),
+                }
+            } else if peer_eof && !local_closed {
+                let _ = local.shutdown_write();
+                local_closed = true;
+                progress = true;
+            }
+
+            // Local -> peer.
+            if !local_eof && outbound.is_empty() {
+                match local.read(outbound.spare()) {
+                    Ok(0) => {
+                        local_eof = true;
+                        linger_deadline = Some(Instant::now() + RELAY_LINGER);
+                        progress = true;
+                    }
+                    Ok(count) => {
+                        outbound.fill(count);
+                        progress = true;
+                    }
+                    Err(error) if is_retryable(&error) => {}
+                    Err(error) => return Err(error),
+                }
+            }
+            if !outbound.is_empty() {
+                match tls.ssl_write(outbound.pending()) {
+                    Ok(count) => {
+                        outbound.consume(count);
+                        progress = true;
+                    }
+                    Err(error) => match error.code() {
+                        ErrorCode::WANT_WRITE => tls_wants_write = true,
+                        ErrorCode::WANT_READ => tls_wants_read = true,
+                        _ => return Err(ssl_error(error)),
+                    },
+                }
+            } else if local_eof && !peer_closed {
+                // Best effort: a close_notify that cannot be sent right now
+                // is covered by the TCP half-close that follows.
+                let _ = tls.shutdown();
+                let _ = tls.get_ref().shutdown(Shutdown::Write);
+                peer_closed = true;
+                progress = true;
+            }
+
+            if local_closed && peer_closed {
+                return Ok(());
+            }
+            if progress {
+                continue;
+            }
+
+            let timeout = match linger_deadline {
+                Some(deadline) => {
+                    let remaining = deadline.saturating_duration_since(Instant::now());
+                    if remaining.is_zero() {
+                        return Ok(());
+                    }
+                    PollTimeout::try_from(remaining).unwrap_or(PollTimeout::MAX)
+                }
+                None => PollTimeout::NONE,
+            };
+
+            let mut tls_events = PollFlags::empty();
+            if tls_wants_read || (!peer_eof && inbound.is_empty()) {
+                tls_events |= PollFlags::POLLIN;
+            }
+            if tls_wants_write {
+                tls_events |= PollFlags::POLLOUT;
+            }
+            let mut local_events = PollFlags::empty();
+            if !local_eof && outbound.is_empty() {
+                local_events |= PollFlags::POLLIN;
+            }
+            if !inbound.is_empty() {
+                local_events |= PollFlags::POLLOUT;
+            }
+
+            // A descriptor with no wanted events is left out entirely: poll(2)
+            // always reports POLLHUP, which would spin the loop on a side
+            // that has already finished.
+            let mut fds = Vec::with_capacity(2);
+            if !tls_events.is_empty() {
+                fds.push(PollFd::new(tls.get_ref().as_fd(), tls_events));
+            }
+            if !local_events.is_empty() {
+                fds.push(PollFd::new(local.as_fd(), local_events));
+            }
+            match poll(&mut fds, timeout) {
+                Ok(_) | Err(Errno::EINTR) => {}
+                Err(errno) => return Err(errno.into()),
+            }
+        }
+    }
+}
+
+/// Plaintext socket on the local side of a [`TlsStream::relay`].
+pub trait RelaySocket: Read + Write + AsFd {
+    /// Switches the socket between blocking and non-blocking mode.
+    fn set_nonblocking(&self, nonblocking: bool) -> io::Result<()>;
+
+    /// Shuts down the write half, signalling end of stream to the reader.
+    fn shutdown_write(&self) -> io::Result<()>;
+}
+
+impl RelaySocket for TcpStream {
+    fn set_nonblocking(&self, nonblocking: bool) -> io::Result<()> {
+        TcpStream::set_nonblocking(self, nonblocking)
+    }
+
+    fn shutdown_write(&self) -> io::Result<()> {
+        self.shutdown(Shutdown::Write)
+    }
+}
+
+impl RelaySocket for UnixStream {
+    fn set_nonblocking(&self, nonblocking: bool) -> io::Result<()> {
+        UnixStream::set_nonblocking(self, nonblocking)
+    }
+
+    fn shutdown_write(&self) -> io::Result<()> {
+        self.shutdown(Shutdown::Write)
+    }
+}
+
+/// One direction's in-flight bytes: filled by a read, drained by writes.
+struct RelayBuffer {
+    data: Box<[u8]>,
+    start: usize,
+    end: usize,
+}
+
+impl RelayBuffer {
+    fn new() -> Self {
+        Self {
+            data: vec![0; RELAY_BUFFER_SIZE].into_boxed_slice(),
+            start: 0,
+            end: 0,
+        }
+    }
+
+    fn is_empty(&self) -> bool {
+        self.start == self.end
+    }
+
+    /// The whole buffer, for a read into an empty buffer.
+    fn spare(&mut self) -> &mut [u8] {
+        debug_assert!(self.is_empty());
+        &mut self.data
+    }
+
+    fn fill(&mut self, count: usize) {
+        self.start = 0;
+        self.end = count;
+    }
+
+    fn pending(&self) -> &[u8] {
+        &self.data[self.start..self.end]
+    }
+
+    fn consume(&mut self, count: usize) {
+        self.start += count;
+    }
+}
+
+fn is_retryable(error: &io::Error) -> bool {
+    matches!(
+        error.kind(),
+        io::ErrorKind::WouldBlock | io::ErrorKind::Interrupted
+    )
+}
+
+/// Applies the handshake bound; [`TlsStream::relay`] clears it afterwards.
+fn set_handshake_timeout(stream: &TcpStream, timeout: Option<Duration>) -> io::Result<()> {
+    stream.set_read_timeout(timeout)?;
+    stream.set_write_timeout(timeout)
+}
+
+fn handshake_error(error: HandshakeError<TcpStream>) -> io::Error {
+    match error {
+        HandshakeError::SetupFailure(stack) => stack_error(stack),
+        // A blocking socket only reports WouldBlock once its timeout expires.
+        HandshakeError::WouldBlock(_) => {
+            io::Error::new(io::ErrorKind::TimedOut, "TLS handshake timed out")
+        }
+        HandshakeError::Failure(mid) => {
+            let verify = mid.ssl().verify_result();
+            let error = mid.into_error();
+            let text = if verify == X509VerifyResult::OK {
+                format!("TLS handshake failed: {error}")
+            } else {
+                format!("TLS handshake failed: {error} ({})", verify.error_string())
+            };
+            io::Error::new(io::ErrorKind::InvalidData, text)
+        }
+    }
+}
+
+fn ssl_error(error: ssl::Error) -> io::Error {
+    error
+        .into_io_error()
+        .unwrap_or_else(|error| io::Error::new(io::ErrorKind::InvalidData, error.to_string()))
+}
+
+fn stack_error(error: ErrorStack) -> io::Error {
+    io::Error::other(format!("TLS setup failed: {error}"))
+}
+
+fn file_error(what: &str, path: &Path, error: ErrorStack) -> io::Error {
+    io::Error::new(
+        io::ErrorKind::InvalidInput,
+        format!("failed to load TLS {what} {}: {error}", path.display()),
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::net::TcpListener;
+    use std::path::PathBuf;
+    use std::thread;
+
+    fn fixture(name: &str) -> PathBuf {
+        Path::new(env!("CARGO_MANIFEST_DIR"))
+            .join("tests/fixtures/tls")
+            .join(name)
+    }
+
+    fn server() -> TlsServer {
+        TlsServer::from_pem_files(&fixture("cert.pem"), &fixture("key.pem")).expect("load fixture")
+    }
+
+    #[test]
+    fn relay_round_trips_and_forwards_half_close() {
+        let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+        let addr = listener.local_addr().expect("addr");
+        let server = server();
+
+        // Server: terminate TLS and relay into a local socket pair whose
+        // far end echoes one request back and then closes.
+        let server_thread = thread::spawn(move || {
+            let (tcp, _) = listener.accept().expect("accept");
+            let tls = server
+                .accept(tcp, Some(Duration::from_secs(10)))
+                .expect("server handshake");
+            let (relay_end, mut app_end) = UnixStream::pair().expect("socket pair");
+            let relay = thread::spawn(move || tls.relay(relay_end));
+            let mut request = Vec::new();
+            app_end.read_to_end(&mut request).expect("read request");
+            app_end.write_all(&request).expect("echo");
+            drop(app_end);
+            relay.join().expect("relay thread").expect("relay");
+        });
+
+        let client = TlsClient::new(Some(&fixture("cert.pem"))).expect("client");
+        let tcp = TcpStream::connect(addr).expect("connect");
+        let tls = client
+            .connect("127.0.0.1", tcp, Some(Duration::from_secs(10)))
+            .expect("client handshake");
+        let (relay_end, mut app_end) = UnixStream::pair().expect("socket pair");
+        let relay = thread::spawn(move || tls.relay(relay_end));
+
+        let payload: Vec<u8> = (0..200_000u32).map(|i| (i % 251) as u8).collect();
+        let writer = {
+            let mut app_end = app_end.try_clone().expect("clone");
+            let payload = payload.clone();
+            thread::spawn(move || {
+                app_end.write_all(&payload).expect("write payload");
+                app_end.shutdown(Shutdown::Write).expect("half-close");
+            })
+        };
+        let mut echoed = Vec::new();
+        app_end.read_to_end(&mut echoed).expect("read echo");
+        writer.join().expect("writer thread");
+        drop(app_end);
+
+        assert_eq!(echoed, payload);
+        relay.join().expect("relay thread").expect("relay");
+        server_thread.join().expect("server thread");
+    }
+
+    #[test]
+    fn client_rejects_untrusted_certificate() {
+        let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+        let addr = listener.local_addr().expect("addr");
+        let server = server();
+        let server_thread = thread::spawn(move || {
+            let (tcp, _) = listener.accept().expect("accept");
+            assert!(server.accept(tcp, Some(Duration::from_secs(10))).is_err());
+        });
+
+        // No CA bundle: the self-signed fixture is not in the system store.
+        let client = TlsClient::new(None).expect("client");
+        let tcp = TcpStream::connect(addr).expect("connect");
+        let error = client
+            .connect("127.0.0.1", tcp, Some(Duration::from_secs(10)))
+            .err()
+            .expect("verification must fail");
+        assert_eq!(error.kind(), io::ErrorKind::InvalidData);
+        server_thread.join().expect("server thread");
+    }
+
+    #[test]
+    fn server_rejects_plaintext_client() {
+        let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+        let addr = listener.local_addr().expect("addr");
+        let server = server();
+        let server_thread = thread::spawn(move || {
+            let (tcp, _) = listener.accept().expect("accept");
+            server.accept(tcp, Some(Duration::from_secs(10))).err()
+        });
+
+        let mut tcp = TcpStream::connect(addr).expect("connect");
+        tcp.write_all(b"@RSYNCD: 32.0\n").expect("send greeting");
+        let error = server_thread
+            .join()
+            .expect("server thread")
+            .expect("handshake must fail");
+        assert_eq!(error.kind(), io::ErrorKind::InvalidData);
+    }
+
+    #[test]
+    fn mismatched_key_is_rejected() {
+        let error = TlsServer::from_pem_files(&fixture("cert.pem"), &fixture("cert.pem"))
+            .err()
+            .expect("a certificate is not a private key");
+        assert_eq!(error.kind(), io::ErrorKind::InvalidInput);
+    }
+}
@@ -437,4 +437,40 @@ mod tests {
         // 1 GB: sqrt(2^30) = 32768.
         assert_eq!(calculate_block_length(1_073_741_824, 31, None), 32768);
     }
+
+    /// The heuristic is `floor(sqrt(len))` rounded down to a multiple of 8,
+    /// raised to `BLOCK_SIZE` and capped at the protocol's `max_blength`
+    /// (upstream: generator.c:sum_sizes_sqroot()). Checks the bit-serial
+    /// implementation against that closed form, and that `-B` bypasses it.
+    #[test]
+    fn heuristic_matches_closed_form() {
+        fn expected(len: u64, max: u32) -> u32 {
+            let root = (len.isqrt() & !7).max(u64::from(DEFAULT_BLOCK_SIZE));
+            root.min(u64::from(max)) as u32
+        }
+
+        let sizes = [
+            490_000,
+            490_001,
+            700 * 704,
+            1_000_000,
+            5_555_555,
+            1 << 30,
+            (1 << 34) - 1,
+            1 << 34,
+            (1 << 34) + 1,
+            1 << 40,
+            1 << 50,
+        ];
+        for (protocol, max) in [(31, MAX_BLOCK_SIZE_V30), (29, MAX_BLOCK_SIZE_OLD)] {
+            for len in sizes {
+                assert_eq!(
+                    calculate_block_length(len, protocol, None),
+                    expected(len, max),
+                    "len {len}, protocol {protocol}"
+                );
+                assert_eq!(calculate_block_length(len, protocol, Some(2048)), 2048);
+            }
+        }
+    }
 }
@@ -6,13 +6,8 @@ use protocol::ProtocolVersion;
 use thiserror::Error;
 
 use crate::block_size::MAX_SUM_LENGTH as SUM_LENGTH;
+use crate::block_size::calculate_block_length;
 
-/// Default block length used by rsync when files are small.
-const BLOCK_SIZE: u32 = 700;
-/// Maximum block size supported by protocol versions 30 and newer.
-const MAX_BLOCK_SIZE: u32 = 1 << 17;
-/// Maximum block size accepted by legacy protocol versions (< 30).
-const OLD_MAX_BLOCK_SIZE: u32 = 1 << 29;
 /// Bias applied when computing strong checksum lengths for larger files.
 const BLOCKSUM_BIAS: i32 = 10;
 
@@ -208,20 +203,13 @@ pub fn calculate_signature_layout(
         });
     }
 
-    let mut block_length = match params.forced_block_length() {
-        Some(length) => length.get(),
-        None => derive_block_length(params.file_length(), params.protocol()),
-    };
-
-    let max_block = if params.protocol().as_u8() < 30 {
-        OLD_MAX_BLOCK_SIZE
-    } else {
-        MAX_BLOCK_SIZE
-    };
-
-    if block_length > max_block {
-        block_length = max_block;
-    }
+    // upstream: generator.c:sum_sizes_sqroot() - an explicit -B wins over the
+    // square-root heuristic; both are capped at the protocol's max_blength.
+    let block_length = calculate_block_length(
+        params.file_length(),
+        params.protocol().as_u8(),
+        params.forced_block_length().map(NonZeroU32::get),
+    );
 
     let block_length_non_zero =
         NonZeroU32::new(block_length).expect("block length must be non-zero after clamping");
@@ -255,48 +243,6 @@ pub fn calculate_signature_layout(
     })
 }
 
-/// Derives a block length from file size using rsync's square-root heuristic.
-///
-/// Files at or below `BLOCK_SIZE²` use the default block size; larger files use
-/// an approximation of `sqrt(file_length)` rounded down to a multiple of 8 and
-/// clamped to the protocol-specific maximum.
-fn derive_block_length(file_length: u64, protocol: ProtocolVersion) -> u32 {
-    if file_length <= u64::from(BLOCK_SIZE).saturating_mul(u64::from(BLOCK_SIZE)) {
-        return BLOCK_SIZE;
-    }
-
-    let max_block_length = if protocol.as_u8() < 30 {
-        OLD_MAX_BLOCK_SIZE
-    } else {
-        MAX_BLOCK_SIZE
-    };
-
-    let mut c: u64 = 1;
-    let mut l = file_length;
-    while l >> 2 != 0 {
-        c <<= 1;
-        l >>= 2;
-    }
-
-    if c >= u64::from(max_block_length) {
-        return max_block_length;
-    }
-
-    let mut block_length = 0u64;
-    let mut current = c;
-    while current >= 8 {
-        block_length |= current;
-        let candidate = u128::from(block_length);
-        if u128::from(file_length) < candidate.saturating_mul(candidate) {
-            block_length &= !current;
-        }
-        current >>= 1;
-    }
-
-    let block_length = block_length.max(u64::from(BLOCK_SIZE));
-    block_length as u32
-}
-
 /// Computes the strong checksum length for a given file and block size.
 ///
 /// Behavior depends on the transfer phase:
@@ -365,7 +311,7 @@ fn derive_strong_sum_length(
 #[cfg(test)]
 mod tests {
     use super::*;
-    use crate::block_size::SHORT_SUM_LENGTH;
+    use crate::block_size::{DEFAULT_BLOCK_SIZE as BLOCK_SIZE, SHORT_SUM_LENGTH};
     use core::num::NonZeroU8;
     use std::convert::TryFrom;
 
@@ -0,0 +1,123 @@
+//! Criterion benchmark: serial versus rayon-parallel `--checksum` file-list
+//! sums, parameterised on worker count.
+//!
+//! # Why this exists
+//!
+//! Under `-c` the sender hashes every regular file before the file list is
+//! sent (upstream: flist.c:1444). `GeneratorContext::fill_flist_checksums`
+//! runs that pass through `map_blocking` on the rayon pool instead of inline
+//! in `create_entry`. This bench replays the same per-file work - open,
+//! read in 256 KiB windows, unseeded digest - over a fixture tree so the
+//! speedup can be checked against a serial baseline on the reviewer's host.
+//!
+//! # What it measures
+//!
+//! Wall time to hash `FILES` files of `FILE_SIZE` bytes each with the
+//! protocol-32 default (MD5) and with XXH3, once serially and once on a
+//! private pool for each of `WORKER_COUNTS`. Files are read once before
+//! measuring, so the numbers reflect page-cache reads: the CPU-bound case
+//! where the parallel pass helps most. Cold-cache runs on spinning media
+//! narrow the gap.
+//!
+//! Run: `cargo bench -p transfer --bench flist_checksum_parallel`
+
+#![deny(unsafe_code)]
+
+use std::fs::File;
+use std::hint::black_box;
+use std::io::{Read, Write};
+use std::path::{Path, PathBuf};
+
+use criterion::{BenchmarkId, Criterion, Throughput, criterion_group, criterion_main};
+use protocol::ChecksumAlgorithm;
+use rayon::ThreadPoolBuilder;
+use rayon::iter::{IntoParallelIterator, ParallelIterator};
+use tempfile::TempDir;
+use transfer::delta_apply::ChecksumVerifier;
+
+/// Number of fixture files, above the default checksum threshold.
+const FILES: usize = 256;
+
+/// Size of each fixture file.
+const FILE_SIZE: usize = 256 * 1024;
+
+/// Worker counts to sweep, matching the other rayon benches.
+const WORKER_COUNTS: &[usize] = &[1, 4, 8, 16];
+
+/// Writes the fixture tree and returns its paths.
+fn create_fixture(dir: &Path) -> Vec<PathBuf> {
+    (0..FILES)
+        .map(|i| {
+            let path = dir.join(format!("file{i:04}.bin"));
+            let data: Vec<u8> = (0..FILE_SIZE).map(|b| (b ^ i) as u8).collect();
+            let mut file = File::create(&path).expect("create fixture");
+            file.write_all(&data).expect("write fixture");
+            path
+        })
+        .collect()
+}
+
+/// Same per-file work as `flist_file_checksum`.
+fn file_sum(path: &Path, algorithm: ChecksumAlgorithm) -> Vec<u8> {
+    let mut file = File::open(path).expect("open fixture");
+    let mut verifier = ChecksumVerifier::for_algorithm(algorithm);
+    let mut buf = vec![0u8; 256 * 1024];
+    let mut remaining = FILE_SIZE;
+    while remaining > 0 {
+        let to_read = buf.len().min(remaining);
+        file.read_exact(&mut buf[..to_read]).expect("read fixture");
+        verifier.update(&buf[..to_read]);
+        remaining -= to_read;
+    }
+    let mut digest = [0u8; ChecksumVerifier::MAX_DIGEST_LEN];
+    let len = verifier.finalize_into(&mut digest);
+    digest[..len].to_vec()
+}
+
+fn bench_flist_checksum(c: &mut Criterion) {
+    let dir = TempDir::new().expect("tempdir");
+    let paths = create_fixture(dir.path());
+    for path in &paths {
+        black_box(file_sum(path, ChecksumAlgorithm::XXH3));
+    }
+
+    let mut group = c.benchmark_group("flist_checksum_parallel");
+    group.sample_size(10);
+    group.throughput(Throughput::Bytes((FILES * FILE_SIZE) as u64));
+
+    for (label, algorithm) in [
+        ("md5", ChecksumAlgorithm::MD5),
+        ("xxh3", ChecksumAlgorithm::XXH3),
+    ] {
+        group.bench_function(BenchmarkId::new(format!("{label}/serial"), FILES), |b| {
+            b.iter(|| {
+                let sums: Vec<Vec<u8>> = paths.iter().map(|p| file_sum(p, algorithm)).collect();
+                black_box(sums);
+            });
+        });
+
+        for &threads in WORKER_COUNTS {
+            let pool = ThreadPoolBuilder::new()
+                .num_threads(threads)
+                .build()
+                .expect("failed to build rayon pool");
+            group.bench_function(BenchmarkId::new(format!("{label}/rayon"), threads), |b| {
+                b.iter(|| {
+                    let sums: Vec<Vec<u8>> = pool.install(|| {
+                        paths
+                            .clone()
+                            .into_par_iter()
+                            .map(|p| file_sum(&p, algorithm))
+                            .collect()
+                    });
+                    black_box(sums);
+                });
+            });
+        }
+    }
+
+    group.finish();
+}
+
+criterion_group!(benches, bench_flist_checksum);
+criterion_main!(benches);
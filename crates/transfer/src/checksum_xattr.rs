@@ -0,0 +1,73 @@
+//! Precomputed `--checksum` sums read from an extended attribute.
+//!
+//! With `--checksum-xattr=NAME` both transfer roles look for a file's
+//! whole-file digest in the `NAME` attribute before hashing it for `-c`: the
+//! sender when it fills the file-list sums, the receiver when its quick-check
+//! compares the destination file against them. The value format and the
+//! staleness rules are the local-copy executor's
+//! ([`engine::local_copy::read_checksum_xattr`]), so a tree annotated once is
+//! trusted identically by local and remote transfers.
+//!
+//! An oc-rsync extension; upstream always recomputes sums
+//! (upstream: checksum.c:file_checksum()).
+
+use std::ffi::OsStr;
+use std::fs;
+use std::path::Path;
+
+use crate::delta_apply::ChecksumVerifier;
+
+/// Returns the `algorithm` digest recorded in `path`'s `name` attribute when
+/// it still describes the file's current size and mtime.
+pub(crate) fn recorded_checksum(
+    path: &Path,
+    name: &OsStr,
+    algorithm: protocol::ChecksumAlgorithm,
+) -> Option<Vec<u8>> {
+    if algorithm == protocol::ChecksumAlgorithm::None {
+        return None;
+    }
+    let metadata = fs::metadata(path).ok()?;
+    if !metadata.is_file() {
+        return None;
+    }
+    engine::local_copy::read_checksum_xattr(
+        path,
+        name,
+        &metadata,
+        algorithm.as_str(),
+        ChecksumVerifier::for_algorithm(algorithm).digest_len(),
+    )
+}
+
+#[cfg(all(test, unix, feature = "xattr"))]
+mod tests {
+    use super::*;
+    use filetime::{FileTime, set_file_mtime};
+
+    const NAME: &str = "user.rsync.checksum";
+
+    #[test]
+    fn matching_value_yields_digest() {
+        let dir = tempfile::tempdir().unwrap();
+        let file = dir.path().join("a.bin");
+        fs::write(&file, b"payload").unwrap();
+        set_file_mtime(&file, FileTime::from_unix_time(1_600_000_000, 0)).unwrap();
+        let value = format!("xxh64 7 1600000000 0 {}", "cd".repeat(8));
+        if xattr::set(&file, NAME, value.as_bytes()).is_err() {
+            eprintln!("user xattrs unsupported, skipping");
+            return;
+        }
+
+        let algorithm = protocol::ChecksumAlgorithm::XXH64;
+        assert_eq!(
+            recorded_checksum(&file, OsStr::new(NAME), algorithm),
+            Some(vec![0xcd; 8])
+        );
+        assert_eq!(
+            recorded_checksum(&file, OsStr::new(NAME), protocol::ChecksumAlgorithm::MD5),
+            None,
+            "a sum recorded for another algorithm is not trusted"
+        );
+    }
+}
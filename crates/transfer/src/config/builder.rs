@@ -22,10 +22,12 @@ use std::path::PathBuf;
 use std::time::SystemTime;
 
 use compress::zlib::CompressionLevel;
+use engine::local_copy::LocalCopyFileHooks;
 use metadata::{ChmodModifiers, GroupMapping, ModifyWindow, UserMapping};
 use protocol::FilenameConverter;
 use protocol::ProtocolVersion;
 use protocol::filters::FilterRuleWireFormat;
+use protocol::flist::FileListLimits;
 
 use super::error::BuilderError;
 use super::{
@@ -33,6 +35,7 @@ use super::{
     WriteConfig,
 };
 use crate::flags::ParsedServerFlags;
+use crate::resume_skip::ResumeSkipList;
 use crate::role::ServerRole;
 
 /// Builder for constructing [`ServerConfig`] with validation at build time.
@@ -87,10 +90,17 @@ pub struct ServerConfigBuilder {
     fake_super: bool,
     daemon_incoming_chmod: Option<ChmodModifiers>,
     daemon_outgoing_chmod: Option<ChmodModifiers>,
+    daemon_user_mapping: Option<UserMapping>,
+    daemon_group_mapping: Option<GroupMapping>,
     chmod: Option<ChmodModifiers>,
     user_mapping: Option<UserMapping>,
     group_mapping: Option<GroupMapping>,
     munge_symlinks: bool,
+    sanitize_root: Option<PathBuf>,
+    file_list_limits: FileListLimits,
+    copy_as: Option<metadata::CopyAsIds>,
+    resume_skip: ResumeSkipList,
+    file_hooks: LocalCopyFileHooks,
 }
 
 impl Default for ServerConfigBuilder {
@@ -129,10 +139,17 @@ impl ServerConfigBuilder {
             fake_super: false,
             daemon_incoming_chmod: None,
             daemon_outgoing_chmod: None,
+            daemon_user_mapping: None,
+            daemon_group_mapping: None,
             chmod: None,
             user_mapping: None,
             group_mapping: None,
             munge_symlinks: false,
+            sanitize_root: None,
+            file_list_limits: FileListLimits::UNLIMITED,
+            copy_as: None,
+            resume_skip: ResumeSkipList::default(),
+            file_hooks: LocalCopyFileHooks::new(),
         }
     }
 
@@ -255,6 +272,12 @@ impl ServerConfigBuilder {
         self
     }
 
+    /// Enables or disables replacing non-empty directories (`--force`).
+    pub fn force_delete(&mut self, enabled: bool) -> &mut Self {
+        self.deletion.force_delete = enabled;
+        self
+    }
+
     /// Enables or disables deletion despite I/O errors.
     pub fn ignore_errors(&mut self, enabled: bool) -> &mut Self {
         self.deletion.ignore_errors = enabled;
@@ -540,6 +563,26 @@ impl ServerConfigBuilder {
         self
     }
 
+    /// Sets the parsed `uid map = SPEC` rules from the daemon module
+    /// definition.
+    ///
+    /// Pull transfers (daemon to client) apply them when building the file
+    /// list to rewrite the owner emitted on the wire. Push transfers ignore
+    /// them.
+    pub fn daemon_user_mapping(&mut self, mapping: Option<UserMapping>) -> &mut Self {
+        self.daemon_user_mapping = mapping;
+        self
+    }
+
+    /// Sets the parsed `gid map = SPEC` rules from the daemon module
+    /// definition.
+    ///
+    /// Group counterpart of [`Self::daemon_user_mapping`].
+    pub fn daemon_group_mapping(&mut self, mapping: Option<GroupMapping>) -> &mut Self {
+        self.daemon_group_mapping = mapping;
+        self
+    }
+
     /// Enables or disables symlink munging.
     ///
     /// When enabled, the daemon prepends `/rsyncd-munged/` to incoming
@@ -558,6 +601,46 @@ impl ServerConfigBuilder {
         self
     }
 
+    /// Confines the sender to `root`, the directory of a daemon module served
+    /// without `use chroot`.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `clientserver.c:rsync_module()` - `sanitize_paths = 1` when the
+    ///   module is not chrooted.
+    pub fn sanitize_root(&mut self, root: Option<PathBuf>) -> &mut Self {
+        self.sanitize_root = root;
+        self
+    }
+
+    /// Caps the entry count and total name bytes of the received file list.
+    pub fn file_list_limits(&mut self, limits: FileListLimits) -> &mut Self {
+        self.file_list_limits = limits;
+        self
+    }
+
+    /// Sets the identity the receiver writes destination files as.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `main.c:become_copy_as_user()` - `--copy-as=USER[:GROUP]`.
+    pub fn copy_as(&mut self, ids: Option<metadata::CopyAsIds>) -> &mut Self {
+        self.copy_as = ids;
+        self
+    }
+
+    /// Sets the files the client already holds (oc-rsync resume extension).
+    pub fn resume_skip(&mut self, list: ResumeSkipList) -> &mut Self {
+        self.resume_skip = list;
+        self
+    }
+
+    /// Installs the per-file callbacks a pulling client's receiver runs.
+    pub fn file_hooks(&mut self, hooks: LocalCopyFileHooks) -> &mut Self {
+        self.file_hooks = hooks;
+        self
+    }
+
     /// Validates the builder configuration.
     fn validate(&self) -> Result<(), BuilderError> {
         // upstream: options.c:2934 - --inplace and --delay-updates are mutually exclusive
@@ -647,10 +730,17 @@ impl ServerConfigBuilder {
             fake_super: self.fake_super,
             daemon_incoming_chmod: self.daemon_incoming_chmod.clone(),
             daemon_outgoing_chmod: self.daemon_outgoing_chmod.clone(),
+            daemon_user_mapping: self.daemon_user_mapping.clone(),
+            daemon_group_mapping: self.daemon_group_mapping.clone(),
             chmod: self.chmod.clone(),
             user_mapping: self.user_mapping.clone(),
             group_mapping: self.group_mapping.clone(),
             munge_symlinks: self.munge_symlinks,
+            sanitize_root: self.sanitize_root.clone(),
+            file_list_limits: self.file_list_limits,
+            copy_as: self.copy_as,
+            resume_skip: self.resume_skip.clone(),
+            file_hooks: self.file_hooks.clone(),
         }
     }
 }
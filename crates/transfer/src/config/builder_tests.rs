@@ -84,12 +84,14 @@ mod chaining {
             .max_delete(Some(100))
             .ignore_errors(true)
             .late_delete(true)
+            .force_delete(true)
             .build()
             .expect("valid config");
 
         assert_eq!(config.deletion.max_delete, Some(100));
         assert!(config.deletion.ignore_errors);
         assert!(config.deletion.late_delete);
+        assert!(config.deletion.force_delete);
     }
 
     #[test]
@@ -408,6 +410,7 @@ mod composite_setters {
             late_delete: true,
             delete_after: false,
             delete_excluded: false,
+            force_delete: true,
         };
         let config = ServerConfigBuilder::new()
             .deletion(deletion.clone())
@@ -10,12 +10,15 @@ use std::ffi::OsString;
 use std::time::SystemTime;
 
 use compress::zlib::CompressionLevel;
+use engine::local_copy::LocalCopyFileHooks;
 use metadata::{ChmodModifiers, GroupMapping, ModifyWindow, UserMapping};
 use protocol::FilenameConverter;
 use protocol::ProtocolVersion;
 use protocol::filters::FilterRuleWireFormat;
+use protocol::flist::FileListLimits;
 
 use super::flags::ParsedServerFlags;
+use super::resume_skip::ResumeSkipList;
 use super::role::ServerRole;
 
 /// Reference directory types for remote transfers.
@@ -80,6 +83,13 @@ pub struct WriteConfig {
     /// - `syscall.c:228` - `do_open()` ORs `O_NOATIME` into flags.
     /// - `syscall.c:687` - `do_open_nofollow()` (added in 3.4.2).
     pub open_noatime: bool,
+    /// Buffer size for streamed file data (`--copy-buffer-size=SIZE`).
+    ///
+    /// An oc-rsync extension that tunes only this process: the sender reads
+    /// source files through a buffer of this size and the receiver's disk
+    /// commit thread stages writes in one. `None` keeps the defaults, an
+    /// adaptive read buffer and upstream's 256 KB write buffer.
+    pub copy_buffer_size: Option<usize>,
 }
 
 impl Default for WriteConfig {
@@ -94,6 +104,7 @@ impl Default for WriteConfig {
             io_uring_depth: None,
             zero_copy_policy: fast_io::ZeroCopyPolicy::Auto,
             open_noatime: false,
+            copy_buffer_size: None,
         }
     }
 }
@@ -146,6 +157,13 @@ pub struct DeletionConfig {
     /// upstream: `options.c` `delete_excluded` global; `exclude.c:rule_matches()`
     /// drops the protection an exclude would otherwise grant during deletion.
     pub delete_excluded: bool,
+    /// Remove a non-empty destination directory that stands where a
+    /// non-directory arrives (`--force`).
+    ///
+    /// upstream: `options.c` `force_delete`; `generator.c:recv_generator()`
+    /// adds `DEL_RECURSE` to the make-room `delete_item()` when it or
+    /// `--delete` is set.
+    pub force_delete: bool,
 }
 
 /// Connection and protocol context configuration.
@@ -282,6 +300,15 @@ pub struct FileSelectionConfig {
     /// `util1.c:same_time()` (the signed `int modify_window`) consulted via
     /// `generator.c:quick_check_ok()`.
     pub modify_window: ModifyWindow,
+    /// Extended attribute holding precomputed `--checksum` sums
+    /// (`--checksum-xattr=NAME`).
+    ///
+    /// An oc-rsync extension that only changes how this process obtains a
+    /// file's `-c` digest: the sender's file-list sums and the receiver's
+    /// quick-check take the recorded digest when it names the negotiated
+    /// algorithm and still matches the file's size and mtime, and hash the
+    /// file otherwise. Never sent to the peer.
+    pub checksum_xattr: Option<OsString>,
     /// Path for `--files-from` when the server reads the file list directly.
     pub files_from_path: Option<String>,
     /// Use NUL bytes as delimiters for `--files-from` input (`--from0`).
@@ -314,6 +341,18 @@ pub struct FileSelectionConfig {
     /// - `flist.c:send_file_list()` - `missing_args == 2`
     /// - `options.c:818` - `--delete-missing-args`
     pub delete_missing_args: bool,
+    /// Silently omit source entries the sender cannot read.
+    ///
+    /// Set from the daemon module's `ignore nonreadable` directive; there is
+    /// no client option. Unreadable files and directories are left out of
+    /// the file list, so they are neither transferred nor reported as errors.
+    /// Symlinks are exempt because their readability is that of the target.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `flist.c:make_file()` - `lp_ignore_nonreadable(module_id)` skips
+    ///   entries failing `access(thisname, R_OK)`, except symlinks
+    pub ignore_nonreadable: bool,
 }
 
 /// Configuration supplied to the server entry point.
@@ -538,6 +577,26 @@ pub struct ServerConfig {
     /// - `loadparm.c` - `outgoing chmod` module parameter
     /// - `flist.c:make_file()` - `daemon_chmod_modes` applied during flist build
     pub daemon_outgoing_chmod: Option<ChmodModifiers>,
+    /// Daemon module `uid map` rules applied to sent files.
+    ///
+    /// Parsed from the module's `uid map = SPEC` directive, which takes the
+    /// same syntax as `--usermap`. When set, the sender rewrites each file
+    /// list entry's uid, and the user name sent with it, before the entry
+    /// reaches the wire, so the client never sees the on-disk owner. Pull
+    /// transfers (daemon to client) consult this value; push transfers
+    /// ignore it.
+    ///
+    /// Numeric rules match the on-disk uid; name and wildcard rules match
+    /// its local name and are skipped under `--numeric-ids`, where no names
+    /// are sent.
+    ///
+    /// Daemon-config-driven; never populated from a client `--usermap` flag.
+    pub daemon_user_mapping: Option<UserMapping>,
+    /// Daemon module `gid map` rules applied to sent files.
+    ///
+    /// Group counterpart of [`Self::daemon_user_mapping`], parsed from the
+    /// module's `gid map = SPEC` directive with `--groupmap` syntax.
+    pub daemon_group_mapping: Option<GroupMapping>,
     /// Client `--chmod` modifiers applied by the receiver on a pull.
     ///
     /// Parsed from the client's `--chmod=SPEC` flag. `--chmod` is never
@@ -596,6 +655,58 @@ pub struct ServerConfig {
     /// - `flist.c:234-238` - sender strips the prefix.
     /// - `flist.c:1150-1154` - receiver prepends the prefix.
     pub munge_symlinks: bool,
+    /// Module root a daemon sender running without `use chroot` confines itself to.
+    ///
+    /// Set by the daemon (canonicalized) when the module is served without a
+    /// chroot, so the kernel no longer stops paths at the module boundary.
+    /// While set, the generator refuses any source argument whose resolved
+    /// location lies outside this directory, and never follows a symlink
+    /// (`--copy-links`, `--copy-dirlinks`, `--copy-unsafe-links`) whose target
+    /// does: such a symlink is sent as a symlink instead. `None` everywhere
+    /// else.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `clientserver.c:rsync_module()` - `sanitize_paths = 1` when the
+    ///   module is not chrooted.
+    /// - `util1.c:sanitize_path()` - confines every sender-side name to the
+    ///   module directory.
+    pub sanitize_root: Option<std::path::PathBuf>,
+    /// Caps on the file list the receiver accepts from the sender.
+    ///
+    /// [`FileListLimits::UNLIMITED`] leaves only `--max-alloc` in force. A
+    /// client pulling from a daemon sets the tighter untrusted-sender defaults
+    /// so a malicious server cannot exhaust its memory with an endless list.
+    pub file_list_limits: FileListLimits,
+    /// Identity the receiver writes destination files as (`--copy-as=USER[:GROUP]`).
+    ///
+    /// When set, the receiver creates, renames, and updates destination
+    /// entries as this user and group so a root-run transfer lands with
+    /// reduced privileges. On Linux the switch is per thread (`setfsuid(2)` /
+    /// `setfsgid(2)`) and taken around each committed file; elsewhere the
+    /// process effective identity is switched for the receiver run. Ignored
+    /// by the generator role.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `main.c:become_copy_as_user()` - called from `do_recv()` before the
+    ///   receiver touches the destination.
+    pub copy_as: Option<metadata::CopyAsIds>,
+    /// Files the client already holds, left out of the file list
+    /// (oc-rsync resume extension).
+    ///
+    /// Filled by the daemon from the client's resume manifest on pulls; a
+    /// listed regular file is skipped only while its contents still match
+    /// the client's digest. Empty everywhere else. Upstream rsync has no
+    /// equivalent.
+    pub resume_skip: ResumeSkipList,
+    /// Per-file callbacks an embedding client installed (oc-rsync extension).
+    ///
+    /// Consulted only by a receiver running in the client process, i.e. a
+    /// pull: the veto drops a file from the generator's requests before its
+    /// destination is touched, and the start and done callbacks bracket each
+    /// requested file. Empty everywhere else.
+    pub file_hooks: LocalCopyFileHooks,
 }
 
 impl Default for ServerConfig {
@@ -626,10 +737,17 @@ impl Default for ServerConfig {
             fake_super: false,
             daemon_incoming_chmod: None,
             daemon_outgoing_chmod: None,
+            daemon_user_mapping: None,
+            daemon_group_mapping: None,
             chmod: None,
             user_mapping: None,
             group_mapping: None,
             munge_symlinks: false,
+            sanitize_root: None,
+            file_list_limits: FileListLimits::UNLIMITED,
+            copy_as: None,
+            resume_skip: ResumeSkipList::default(),
+            file_hooks: LocalCopyFileHooks::new(),
         }
     }
 }
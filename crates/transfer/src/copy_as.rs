@@ -0,0 +1,68 @@
+//! `--copy-as=USER[:GROUP]` identity scoping for receiver-side writes.
+//!
+//! On Linux the receiver switches the filesystem identity of each thread
+//! that touches the destination, using `setfsuid(2)` / `setfsgid(2)`. The
+//! network thread holds the identity for the whole receiver run (directory
+//! creation, symlinks, devices, deletions), and the disk-commit thread takes
+//! it around every file it commits. Other threads - the rayon pool hashing
+//! basis files, for instance - keep the original identity, so reading a
+//! basis file the copy-as user cannot read still works as root.
+//!
+//! Without a per-thread switch the receiver falls back to the process-wide
+//! effective identity, held for the receiver run exactly like the local-copy
+//! executor does.
+//!
+//! # Upstream Reference
+//!
+//! - `main.c:become_copy_as_user()` - switches identity in `do_recv()`
+//!   before the receiver touches the destination.
+
+use std::io;
+
+use metadata::CopyAsIds;
+
+/// Identity held while the scope is alive; restored on drop.
+#[derive(Debug)]
+pub(crate) enum CopyAsScope {
+    /// Per-thread filesystem identity.
+    #[cfg(target_os = "linux")]
+    Thread(#[allow(dead_code)] metadata::CopyAsFsGuard),
+    /// Process-wide effective identity.
+    #[cfg(not(target_os = "linux"))]
+    Process(#[allow(dead_code)] metadata::CopyAsGuard),
+}
+
+/// Takes the copy-as identity on the receiver's network thread for the
+/// duration of the receiver run.
+pub(crate) fn enter_receiver(ids: Option<&CopyAsIds>) -> io::Result<Option<CopyAsScope>> {
+    let Some(ids) = ids else {
+        return Ok(None);
+    };
+    #[cfg(target_os = "linux")]
+    {
+        metadata::switch_fs_ids(ids).map(|guard| Some(CopyAsScope::Thread(guard)))
+    }
+    #[cfg(not(target_os = "linux"))]
+    {
+        metadata::switch_effective_ids(ids).map(|guard| Some(CopyAsScope::Process(guard)))
+    }
+}
+
+/// Runs `commit` with the copy-as identity on the calling worker thread.
+///
+/// Used by the disk-commit thread around each file. Where the identity is
+/// process-wide, [`enter_receiver`] already covers this thread and `commit`
+/// runs unchanged.
+pub(crate) fn with_file_identity<T>(
+    ids: Option<&CopyAsIds>,
+    commit: impl FnOnce() -> io::Result<T>,
+) -> io::Result<T> {
+    #[cfg(target_os = "linux")]
+    if let Some(ids) = ids {
+        let _guard = metadata::switch_fs_ids(ids)?;
+        return commit();
+    }
+    #[cfg(not(target_os = "linux"))]
+    let _ = ids;
+    commit()
+}
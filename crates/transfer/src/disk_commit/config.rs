@@ -12,6 +12,8 @@ use filters::FilterSet;
 use metadata::MetadataOptions;
 use protocol::acl::AclCache;
 
+use crate::receiver_fs::ReceiverFs;
+
 /// Controls partial file retention on interrupted transfers.
 ///
 /// When a transfer is interrupted (signal, connection drop, error), upstream
@@ -198,6 +200,29 @@ pub struct DiskCommitConfig {
     ///
     /// - `receiver.c:357-373` - `if (append_mode == 2 && mapbuf)` prefix `sum_update`
     pub append_verify: bool,
+    /// Optional receiver-side filesystem hook.
+    ///
+    /// When `Some`, the temp-file create, the temp -> final rename, and the
+    /// permission / ownership / mtime steps of the metadata pass dispatch
+    /// through the hook instead of the sandbox-anchored `std::fs` paths, so
+    /// an embedder can redirect destination writes to a custom backend. ACLs
+    /// and xattrs still apply through the metadata crate against the path
+    /// the hook returned. `None` (the default) keeps the built-in paths.
+    pub receiver_fs: Option<Arc<dyn ReceiverFs>>,
+    /// `--copy-as` identity the disk thread takes around each file commit.
+    ///
+    /// Only consulted where the identity switch is per-thread (Linux
+    /// `setfsuid`); elsewhere the receiver holds a process-wide switch and
+    /// this thread inherits it.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `main.c:become_copy_as_user()`
+    pub copy_as: Option<metadata::CopyAsIds>,
+    /// Size of the reusable write buffer (`--copy-buffer-size`).
+    ///
+    /// `None` keeps upstream's 256 KB `wf_writeBufSize` (fileio.c:161).
+    pub write_buf_size: Option<usize>,
 }
 
 impl Default for DiskCommitConfig {
@@ -223,6 +248,9 @@ impl Default for DiskCommitConfig {
             partial_mode: PartialMode::None,
             delay_updates: false,
             append_verify: false,
+            receiver_fs: None,
+            copy_as: None,
+            write_buf_size: None,
         }
     }
 }
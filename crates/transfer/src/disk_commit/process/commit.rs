@@ -172,26 +172,11 @@ pub(super) fn commit_file(
     })
 }
 
-/// Removes the `--partial-dir` basis file after a successful commit and
-/// rmdir's the (now-possibly-empty) partial directory for a relative
-/// `--partial-dir`, mirroring upstream `handle_partial_dir(PDIR_DELETE)`.
-///
-/// Best-effort: a missing partial file or a non-empty partial-dir leaves the
-/// filesystem untouched. Absolute `--partial-dir` values are never rmdir'd,
-/// matching upstream `util1.c:1343` (`if (!create && *partial_dir == '/')`).
+/// Removes the `--partial-dir` basis file after a successful commit; see
+/// [`crate::temp_guard::remove_partial_dir_file`].
 fn remove_partial_dir_basis(config: &DiskCommitConfig, dest_path: &Path) {
-    let PartialMode::PartialDir(ref dir) = config.partial_mode else {
-        return;
-    };
-    let Some(partial) = crate::temp_guard::partial_dir_fname(dest_path, dir) else {
-        return;
-    };
-    let _ = fs::remove_file(&partial);
-    // upstream: handle_partial_dir() only rmdir's a relative partial-dir.
-    if !dir.is_absolute() {
-        if let Some(parent) = partial.parent() {
-            let _ = fs::remove_dir(parent);
-        }
+    if let PartialMode::PartialDir(ref dir) = config.partial_mode {
+        crate::temp_guard::remove_partial_dir_file(dest_path, dir);
     }
 }
 
@@ -372,6 +357,9 @@ pub(super) fn rename_with_io_uring_fallback(old_path: &Path, new_path: &Path) ->
 /// rename is never regressed. The anchored path shares the destination subtree
 /// for both endpoints, so EXDEV cannot arise there.
 ///
+/// An installed [`crate::receiver_fs::ReceiverFs`] hook takes precedence over
+/// both paths: the embedder owns the commit and reports no copy fallback.
+///
 /// Returns `Ok(false)` for an in-place rename, `Ok(true)` when the EXDEV
 /// copy+remove fallback ran.
 #[cfg(unix)]
@@ -380,6 +368,10 @@ pub(super) fn rename_config_sandboxed(
     old_path: &Path,
     new_path: &Path,
 ) -> io::Result<bool> {
+    if let Some(hook) = config.receiver_fs.as_deref() {
+        hook.rename(old_path, new_path)?;
+        return Ok(false);
+    }
     if let (Some(sandbox), Some(dest_dir)) = (config.sandbox.as_ref(), config.dest_dir.as_deref())
         && let (Ok(old_rel), Ok(new_rel)) = (
             old_path.strip_prefix(dest_dir),
@@ -415,10 +407,14 @@ pub(super) fn rename_config_sandboxed(
 /// [`rename_with_io_uring_fallback`] with no behavior change.
 #[cfg(not(unix))]
 pub(super) fn rename_config_sandboxed(
-    _config: &DiskCommitConfig,
+    config: &DiskCommitConfig,
     old_path: &Path,
     new_path: &Path,
 ) -> io::Result<bool> {
+    if let Some(hook) = config.receiver_fs.as_deref() {
+        hook.rename(old_path, new_path)?;
+        return Ok(false);
+    }
     #[cfg(windows)]
     {
         crate::temp_guard::commit_rename_no_follow(old_path, new_path)
@@ -59,7 +59,7 @@ fn sum_append_prefix(
     let mut remaining = begin.append_offset;
     let mut buf = vec![0u8; 256 * 1024];
     while remaining > 0 {
-        let to_read = buf.len().min(remaining as usize);
+        let to_read = remaining.min(buf.len() as u64) as usize;
         file.read_exact(&mut buf[..to_read])?;
         verifier.update(&buf[..to_read]);
         remaining -= to_read as u64;
@@ -747,6 +747,12 @@ fn open_output_file(
             TempFileGuard::keep_dest(begin.file_path.clone()),
             false,
         ))
+    } else if let Some(hook) = config.receiver_fs.as_deref() {
+        // Receiver VFS hook: the embedder owns temp placement. The guard
+        // falls back to the path-based unlink since the hook's temp may live
+        // outside the sandbox root.
+        let (file, temp_path) = hook.create_temp(&begin.file_path, config.temp_dir.as_deref())?;
+        Ok((file, TempFileGuard::new(temp_path), true))
     } else {
         // SEC-1.r: when the dest_dir + sandbox carrier is plumbed, route the
         // temp-file create through `openat(dirfd, leaf, O_WRONLY|O_CREAT|
@@ -13,6 +13,7 @@ use protocol::acl::AclCache;
 
 use crate::delta_apply::ChecksumVerifier;
 use crate::pipeline::messages::{BeginMessage, ComputedChecksum};
+use crate::receiver_fs::ReceiverFs;
 
 use super::super::config::DiskCommitConfig;
 
@@ -60,10 +61,49 @@ pub(super) fn apply_file_metadata(
             begin.xattr_list.as_ref(),
             config.xattr_filter.as_deref(),
             pre_transfer_meta,
+            config.receiver_fs.as_deref(),
         )
     }
 }
 
+/// Applies ownership, permissions, and mtime through an installed
+/// [`ReceiverFs`] hook, in the same chown -> chmod -> utimes order the
+/// metadata crate uses (a chown may clear setuid/setgid bits, so the chmod
+/// must follow it).
+///
+/// Only the attributes the session preserves are dispatched: `-o`/`-g` (or an
+/// explicit `--chown` override) for ownership, `-p` for permissions, and `-t`
+/// for the mtime. The hook sees the sender's raw ids; remapping via
+/// `--usermap`/`--groupmap` is the backend's concern.
+fn apply_attrs_via_hook(
+    hook: &dyn ReceiverFs,
+    file_path: &Path,
+    entry: &protocol::flist::FileEntry,
+    opts: &metadata::MetadataOptions,
+) -> std::io::Result<()> {
+    let uid = if opts.owner() {
+        opts.owner_override().or(entry.uid())
+    } else {
+        opts.owner_override()
+    };
+    let gid = if opts.group() {
+        opts.group_override().or(entry.gid())
+    } else {
+        opts.group_override()
+    };
+    if uid.is_some() || gid.is_some() {
+        hook.chown(file_path, uid, gid)?;
+    }
+    if opts.permissions() {
+        hook.set_permissions(file_path, entry.permissions())?;
+    }
+    if opts.times() {
+        let mtime = filetime::FileTime::from_unix_time(entry.mtime(), entry.mtime_nsec());
+        hook.set_mtime(file_path, mtime)?;
+    }
+    Ok(())
+}
+
 /// Applies file metadata, ACLs, and xattrs from the receiver's caches.
 ///
 /// Combines `apply_metadata_from_file_entry` with `apply_acls_from_cache` and
@@ -82,6 +122,7 @@ fn apply_metadata_acls_and_xattrs(
     xattr_list: Option<&protocol::xattr::XattrList>,
     xattr_filter: Option<&filters::FilterSet>,
     pre_transfer_meta: Option<std::fs::Metadata>,
+    receiver_fs: Option<&dyn ReceiverFs>,
 ) -> Option<(PathBuf, String)> {
     let (opts, entry) = match (metadata_opts, file_entry) {
         (Some(o), Some(e)) => (o, e),
@@ -93,14 +134,19 @@ fn apply_metadata_acls_and_xattrs(
     // Pass the PRE-transfer stat instead so `set_file_attrs()`'s dest_mode()
     // chmod keeps an existing file's prior perm bits and applies the
     // umask-masked source mode to a brand-new file.
-    if let Err(e) = metadata::apply_metadata_with_pre_transfer_stat(
-        file_path,
-        entry,
-        opts,
-        None,
-        pre_transfer_meta,
-    ) {
-        return Some((file_path.to_path_buf(), e.to_string()));
+    let applied = match receiver_fs {
+        Some(hook) => apply_attrs_via_hook(hook, file_path, entry, opts).map_err(|e| e.to_string()),
+        None => metadata::apply_metadata_with_pre_transfer_stat(
+            file_path,
+            entry,
+            opts,
+            None,
+            pre_transfer_meta,
+        )
+        .map_err(|e| e.to_string()),
+    };
+    if let Err(e) = applied {
+        return Some((file_path.to_path_buf(), e));
     }
 
     // upstream: set_file_attrs() calls set_acl() after perms/times/ownership
@@ -37,6 +37,12 @@ fn config_io_uring_depth_default_is_none() {
     assert_eq!(config.io_uring_depth, None);
 }
 
+#[test]
+fn config_write_buf_size_default_is_none() {
+    let config = DiskCommitConfig::default();
+    assert_eq!(config.write_buf_size, None);
+}
+
 #[test]
 fn config_io_uring_depth_override() {
     let config = DiskCommitConfig {
@@ -630,6 +636,49 @@ fn commit_file_rename_via_io_uring_or_fallback() {
     h.join_handle.join().unwrap();
 }
 
+/// A `--copy-buffer-size` smaller than each chunk still commits every byte.
+#[test]
+fn commit_with_small_write_buffer_writes_every_chunk() {
+    let _registry_lock = test_support::cleanup_registry_test_guard();
+    let dir = test_support::create_tempdir();
+    let file_path = dir.path().join("small_write_buf.dat");
+
+    let config = DiskCommitConfig {
+        io_uring_policy: fast_io::IoUringPolicy::Disabled,
+        write_buf_size: Some(4),
+        ..DiskCommitConfig::default()
+    };
+    let h = spawn_disk_thread(config).unwrap();
+
+    h.file_tx
+        .send(FileMessage::Begin(Box::new(BeginMessage {
+            file_path: file_path.clone(),
+            target_size: 13,
+            file_entry_index: 0,
+            checksum_verifier: None,
+            is_device_target: false,
+            is_inplace: false,
+            append_offset: 0,
+            xattr_list: None,
+        })))
+        .unwrap();
+    for chunk in [&b"abc"[..], b"defgh", b"ijklm"] {
+        h.file_tx.send(FileMessage::Chunk(chunk.to_vec())).unwrap();
+    }
+    h.file_tx
+        .send(FileMessage::Commit {
+            expected_checksum: Default::default(),
+        })
+        .unwrap();
+
+    let result = h.result_rx.recv().unwrap().unwrap();
+    assert_eq!(result.bytes_written, 13);
+    assert_eq!(fs::read(&file_path).unwrap(), b"abcdefghijklm");
+
+    h.file_tx.send(FileMessage::Shutdown).unwrap();
+    h.join_handle.join().unwrap();
+}
+
 /// Verifies the io_uring rename works when replacing an existing destination.
 #[test]
 fn commit_file_rename_replaces_existing_via_io_uring_or_fallback() {
@@ -3606,3 +3655,82 @@ fn non_sparse_commit_preserves_source_mtime() {
     h.file_tx.send(FileMessage::Shutdown).unwrap();
     h.join_handle.join().unwrap();
 }
+
+/// An installed `ReceiverFs` hook sees the full per-file commit sequence in
+/// upstream order: `open_tmpfile()`, then `set_file_attrs()` against the temp
+/// file (chmod before utimes), then the rename onto the destination
+/// (`rsync.c:finish_transfer()`). The recorder forwards to `std::fs`, so the
+/// destination must still carry the payload and the source mtime.
+#[test]
+fn receiver_fs_hook_observes_commit_sequence() {
+    use crate::receiver_fs::{ReceiverFsOp, RecordingReceiverFs};
+
+    let _registry_lock = test_support::cleanup_registry_test_guard();
+    let dir = test_support::create_tempdir();
+    let file_path = dir.path().join("hooked.dat");
+
+    let hook = std::sync::Arc::new(RecordingReceiverFs::new());
+    let mut config = sparse_mtime_config(false, 11);
+    config.receiver_fs = Some(hook.clone());
+    let h = spawn_disk_thread(config).unwrap();
+
+    h.file_tx
+        .send(FileMessage::Begin(Box::new(BeginMessage {
+            file_path: file_path.clone(),
+            target_size: 11,
+            file_entry_index: 0,
+            checksum_verifier: None,
+            is_device_target: false,
+            is_inplace: false,
+            append_offset: 0,
+            xattr_list: None,
+        })))
+        .unwrap();
+    h.file_tx
+        .send(FileMessage::Chunk(b"hello hooks".to_vec()))
+        .unwrap();
+    h.file_tx
+        .send(FileMessage::Commit {
+            expected_checksum: Default::default(),
+        })
+        .unwrap();
+
+    let result = h.result_rx.recv().unwrap().unwrap();
+    assert_eq!(result.bytes_written, 11);
+    assert!(result.metadata_error.is_none());
+
+    h.file_tx.send(FileMessage::Shutdown).unwrap();
+    h.join_handle.join().unwrap();
+
+    let ops = hook.ops();
+    assert_eq!(ops.len(), 4, "unexpected op sequence: {ops:?}");
+    assert_eq!(
+        ops[0],
+        ReceiverFsOp::CreateTemp {
+            dest: file_path.clone()
+        }
+    );
+    let ReceiverFsOp::SetPermissions { path: temp, mode } = &ops[1] else {
+        panic!("expected SetPermissions, got {:?}", ops[1]);
+    };
+    assert_ne!(temp, &file_path, "attributes must land on the temp file");
+    assert_eq!(*mode, 0o644);
+    assert_eq!(
+        ops[2],
+        ReceiverFsOp::SetMtime {
+            path: temp.clone(),
+            mtime: filetime::FileTime::from_unix_time(SPARSE_MTIME_SECS, 0),
+        }
+    );
+    assert_eq!(
+        ops[3],
+        ReceiverFsOp::Rename {
+            from: temp.clone(),
+            to: file_path.clone(),
+        }
+    );
+
+    assert!(!temp.exists());
+    assert_eq!(fs::read(&file_path).unwrap(), b"hello hooks");
+    assert_eq!(dest_mtime_secs(&file_path), SPARSE_MTIME_SECS);
+}
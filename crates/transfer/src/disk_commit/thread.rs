@@ -13,12 +13,16 @@
 //! batch is available or sparse mode is requested, the thread falls back to
 //! the buffered writer using a reusable 256 KB scratch buffer that mirrors
 //! upstream's static `wf_writeBuf` (fileio.c:161).
+//!
+//! With `--copy-as`, each file is committed under the copy-as filesystem
+//! identity, taken and dropped per file on this thread.
 
 use std::io;
 use std::thread::{self, JoinHandle};
 
 use logging::debug_log;
 
+use crate::copy_as::with_file_identity;
 use crate::pipeline::messages::{CommitResult, FileMessage};
 use crate::pipeline::spsc;
 
@@ -193,8 +197,9 @@ fn log_iocp_status(policy: fast_io::IocpPolicy, batch_created: bool) {
 
 /// Main loop of the disk commit thread.
 ///
-/// Allocates a single 256KB write buffer reused across all files, matching
-/// upstream rsync's static `wf_writeBuf` (fileio.c:161). On Linux 5.6+
+/// Allocates a single write buffer reused across all files, matching
+/// upstream rsync's static `wf_writeBuf` (fileio.c:161). It is 256KB unless
+/// `--copy-buffer-size` overrides it. On Linux 5.6+
 /// with io_uring support, a batched ring writer is created once and reused
 /// across all files for reduced syscall overhead.
 fn disk_thread_main(
@@ -203,7 +208,7 @@ fn disk_thread_main(
     buf_return_tx: spsc::Sender<Vec<u8>>,
     config: DiskCommitConfig,
 ) {
-    let mut write_buf = Vec::with_capacity(WRITE_BUF_SIZE);
+    let mut write_buf = Vec::with_capacity(config.write_buf_size.unwrap_or(WRITE_BUF_SIZE));
     let mut disk_batch = try_create_disk_batch(config.io_uring_policy, config.io_uring_depth);
     // io_uring takes precedence on Linux; only attempt IOCP if io_uring is
     // not active. In practice the two backends are mutually exclusive by
@@ -221,15 +226,17 @@ fn disk_thread_main(
         match msg {
             FileMessage::Shutdown => break,
             FileMessage::Begin(begin) => {
-                let result = process_file(
-                    &file_rx,
-                    &buf_return_tx,
-                    &config,
-                    *begin,
-                    &mut write_buf,
-                    disk_batch.as_mut(),
-                    iocp_batch.as_mut(),
-                );
+                let result = with_file_identity(config.copy_as.as_ref(), || {
+                    process_file(
+                        &file_rx,
+                        &buf_return_tx,
+                        &config,
+                        *begin,
+                        &mut write_buf,
+                        disk_batch.as_mut(),
+                        iocp_batch.as_mut(),
+                    )
+                });
                 // process_file consumes Shutdown/disconnect from the channel.
                 // When it returns Interrupted (shutdown) or BrokenPipe
                 // (disconnect), the main loop must exit - no more messages
@@ -247,16 +254,18 @@ fn disk_thread_main(
                 data,
                 expected_checksum,
             } => {
-                let result = process_whole_file(
-                    &buf_return_tx,
-                    &config,
-                    *begin,
-                    data,
-                    expected_checksum,
-                    &mut write_buf,
-                    disk_batch.as_mut(),
-                    iocp_batch.as_mut(),
-                );
+                let result = with_file_identity(config.copy_as.as_ref(), || {
+                    process_whole_file(
+                        &buf_return_tx,
+                        &config,
+                        *begin,
+                        data,
+                        expected_checksum,
+                        &mut write_buf,
+                        disk_batch.as_mut(),
+                        iocp_batch.as_mut(),
+                    )
+                });
                 if result_tx.send(result).is_err() {
                     break;
                 }
@@ -11,7 +11,7 @@ use std::sync::Arc;
 use std::sync::atomic::Ordering;
 
 use ::filters::FilterChain;
-use protocol::flist::{DualFileList, FileEntry};
+use protocol::flist::{DualFileList, FileEntry, PathInterner};
 use protocol::idlist::IdList;
 use protocol::stats::DeleteStats;
 use protocol::{CompatibilityFlags, NegotiationResult, ProtocolVersion};
@@ -78,6 +78,13 @@ pub struct GeneratorContext {
     /// base, so caching the last `Arc<Path>` collapses them onto a single shared
     /// allocation without the overhead of a full interning map.
     last_source_base: Option<Arc<Path>>,
+    /// Keeps the anchored aliases of source bases of `PATH_MAX` bytes or more
+    /// open; a `source_bases` entry naming an alias resolves only while its
+    /// anchor lives.
+    pub(crate) source_anchors: Vec<fast_io::ReachablePath>,
+    /// Shares one dirname allocation between entries of the same directory,
+    /// as the receiver's `FileListReader` does for decoded entries.
+    dirname_interner: PathInterner,
     /// Per-directory scoped filter chain for file list building and deletion.
     ///
     /// Combines global filter rules (from command-line or wire) with per-directory
@@ -131,6 +138,9 @@ pub struct GeneratorContext {
     /// Different operations have different overhead profiles: CPU-bound signature
     /// computation benefits from parallelism at lower counts than I/O-bound stat calls.
     pub(crate) parallel_thresholds: crate::parallel_io::ParallelThresholds,
+    /// Set while a file-list build defers `--checksum` sums to the parallel
+    /// pass in `fill_flist_checksums`, so `create_entry` skips hashing inline.
+    pub(crate) defer_flist_checksums: bool,
     /// Transfer pipeline FSM tracking the current protocol phase.
     ///
     /// Enforces the linear phase progression through the transfer lifecycle.
@@ -138,6 +148,10 @@ pub struct GeneratorContext {
     /// advanced through `FileListTransfer`, `DeltaTransfer`, `Finalization`,
     /// and `Complete` as the generator progresses.
     pub(crate) pipeline: TransferPipeline,
+    /// Destination socket for zero-copy whole-file literals, supplied by a
+    /// caller that owns a TCP transport. `None` keeps every literal on the
+    /// buffered write path.
+    pub(crate) sendfile_socket: Option<crate::writer::SendfileSocket>,
 }
 
 impl GeneratorContext {
@@ -172,6 +186,8 @@ impl GeneratorContext {
             file_list: DualFileList::new(),
             source_bases: Vec::new(),
             last_source_base: None,
+            source_anchors: Vec::new(),
+            dirname_interner: PathInterner::new(),
             filter_chain: FilterChain::empty(),
             negotiated_algorithms: handshake.negotiated_algorithms,
             compat_flags: handshake.compat_flags,
@@ -185,10 +201,23 @@ impl GeneratorContext {
             delete_stats: DeleteStats::new(),
             flist_send_stats: super::FlistSendStats::default(),
             parallel_thresholds: crate::parallel_io::ParallelThresholds::default(),
+            defer_flist_checksums: false,
             pipeline,
+            sendfile_socket: None,
         }
     }
 
+    /// Installs the destination socket used for zero-copy whole-file literals.
+    ///
+    /// The generator still falls back to the buffered path per file whenever
+    /// compression, batch recording, `--no-zero-copy` or a non-file source
+    /// rules the zero-copy sender out.
+    #[must_use]
+    pub fn with_sendfile_socket(mut self, socket: Option<crate::writer::SendfileSocket>) -> Self {
+        self.sendfile_socket = socket;
+        self
+    }
+
     /// Creates a generator context for unit testing with a default pipeline.
     ///
     /// The pipeline is initialized at `FilterExchange`, matching the state
@@ -424,17 +453,20 @@ impl GeneratorContext {
     /// this derives and interns the source-directory base so that
     /// `base.join(entry.name())` reproduces `full_path` byte-for-byte (see
     /// [`reconstruct_source_path`](Self::reconstruct_source_path)). Entries from
-    /// one source argument share a single base allocation.
+    /// one source argument share a single base allocation, and entries from one
+    /// directory share a single dirname allocation.
     ///
     /// This method maintains the invariant that `file_list` and `source_bases`
     /// have the same length and corresponding entries at each index.
-    pub(crate) fn push_file_item(&mut self, entry: FileEntry, full_path: PathBuf) {
+    pub(crate) fn push_file_item(&mut self, mut entry: FileEntry, full_path: PathBuf) {
         debug_assert_eq!(
             self.file_list.len(),
             self.source_bases.len(),
             "file_list and source_bases must be kept in sync before push"
         );
         let base = self.intern_source_base(&full_path, entry.path());
+        let dirname = self.dirname_interner.intern(entry.dirname());
+        entry.set_dirname(dirname);
         self.file_list.push(entry);
         self.source_bases.push(base);
     }
@@ -471,6 +503,8 @@ impl GeneratorContext {
         self.file_list = DualFileList::new();
         self.source_bases.clear();
         self.last_source_base = None;
+        self.source_anchors.clear();
+        self.dirname_interner.clear();
     }
 
     /// Determines if input multiplex should be activated based on mode and protocol.
@@ -577,6 +611,10 @@ impl GeneratorContext {
     /// because `IoUringReader::open` does not accept custom open flags;
     /// matching upstream `do_open` semantics is the user-requested invariant.
     ///
+    /// A FIFO or socket that has taken the place of a listed file is refused
+    /// rather than opened (a blocking open of a FIFO waits for a writer); see
+    /// the `open_source` module.
+    ///
     /// # Upstream Reference
     ///
     /// - `syscall.c:228 do_open` / `syscall.c:687 do_open_nofollow` (3.4.2
@@ -603,6 +641,7 @@ impl GeneratorContext {
             && file_size >= IO_URING_READ_THRESHOLD
             && self.config.write.io_uring_policy != fast_io::IoUringPolicy::Disabled
             && !self.source_is_copy_device(path)
+            && !open_source::source_is_pipe(path)
         {
             // Windows gets IOCP where Linux gets io_uring, std elsewhere. The
             // IOCP reader mirrors the disk-commit writer's dispatch: runtime
@@ -632,10 +671,12 @@ impl GeneratorContext {
         }
 
         let f = open_source::open_source_with_noatime(path, use_noatime)?;
-        Ok(Box::new(std::io::BufReader::with_capacity(
-            adaptive_buffer_size(file_size),
-            f,
-        )))
+        let capacity = self
+            .config
+            .write
+            .copy_buffer_size
+            .unwrap_or_else(|| adaptive_buffer_size(file_size));
+        Ok(Box::new(std::io::BufReader::with_capacity(capacity, f)))
     }
 
     /// Returns whether `--copy-devices` is active and `path` is a block/char
@@ -661,6 +702,35 @@ impl GeneratorContext {
         false
     }
 
+    /// Returns the socket for a zero-copy whole-file send of `path`, or `None`
+    /// when the buffered path must be used.
+    ///
+    /// The zero-copy sender engages only on Linux, for an uncompressed stream
+    /// (`use_compression` false and `writer` in plain multiplex mode with no
+    /// batch recorder), when `--no-zero-copy` is not in effect, a caller
+    /// supplied a socket, and the source is a regular file of at least
+    /// [`SENDFILE_MIN_FILE_SIZE`](super::delta::SENDFILE_MIN_FILE_SIZE) bytes.
+    /// Devices under `--copy-devices` report a zero stat size and stay on the
+    /// read loop.
+    pub(crate) fn zero_copy_socket<W: std::io::Write>(
+        &self,
+        writer: &crate::writer::ServerWriter<W>,
+        use_compression: bool,
+        file_size: u64,
+        path: &std::path::Path,
+    ) -> Option<crate::writer::SendfileSocket> {
+        if !cfg!(target_os = "linux")
+            || use_compression
+            || file_size < super::delta::SENDFILE_MIN_FILE_SIZE
+            || self.config.write.zero_copy_policy == fast_io::ZeroCopyPolicy::Disabled
+            || !writer.supports_sendfile()
+            || self.source_is_copy_device(path)
+        {
+            return None;
+        }
+        self.sendfile_socket.clone()
+    }
+
     /// Opens a source file without intermediate buffering.
     ///
     /// Identical to [`open_source_reader`](Self::open_source_reader) except the
@@ -698,6 +768,7 @@ impl GeneratorContext {
             && file_size >= IO_URING_READ_THRESHOLD
             && self.config.write.io_uring_policy != fast_io::IoUringPolicy::Disabled
             && !self.source_is_copy_device(path)
+            && !open_source::source_is_pipe(path)
         {
             // Windows gets IOCP where Linux gets io_uring, std elsewhere. The
             // IOCP reader mirrors the disk-commit writer's dispatch: runtime
@@ -458,7 +458,9 @@ pub(super) fn stream_whole_file_transfer<R: Read, W: Write>(
     // constraints apply, and the platform supports it, dispatch to a
     // sendfile/TransmitFile/splice sender (NSV-6..10) instead of the read->hash->
     // write loop below. Until then the bytes flow through the existing path
-    // unchanged, so wire output and stats are byte-for-byte identical.
+    // unchanged, so wire output and stats are byte-for-byte identical. (The
+    // client push path already picks `sendfile_whole_file_transfer` before
+    // calling here when the caller supplied its socket.)
     let _ = &serve_fds;
 
     if file_size > LARGE_FILE_WARNING_THRESHOLD {
@@ -481,14 +483,16 @@ pub(super) fn stream_whole_file_transfer<R: Read, W: Write>(
     // Read buffer sized for fewer syscalls (up to 256KB per read).
     // Buffer is reused across files - no allocation after the first large file.
     const MAX_READ_SIZE: usize = 256 * 1024;
-    let read_size = (file_size as usize).clamp(1, MAX_READ_SIZE);
+    // Clamp in u64 before narrowing: `file_size as usize` wraps sizes past
+    // 4 GiB on 32-bit targets, and a wrapped zero stalls the read loop.
+    let read_size = file_size.clamp(1, MAX_READ_SIZE as u64) as usize;
 
     let mut remaining = file_size;
 
     if let Some(encoder) = encoder {
         buf.resize(read_size, 0);
         while remaining > 0 {
-            let to_read = buf.len().min(remaining as usize);
+            let to_read = remaining.min(buf.len() as u64) as usize;
             source.read_exact(&mut buf[..to_read])?;
             verifier.update(&buf[..to_read]);
             encoder.send_literal(writer, &buf[..to_read])?;
@@ -504,7 +508,7 @@ pub(super) fn stream_whole_file_transfer<R: Read, W: Write>(
         // and bypassing one memcpy. Upstream reference: match.c send_token().
         buf.resize(4 + read_size, 0);
         while remaining > 0 {
-            let to_read = (buf.len() - 4).min(remaining as usize);
+            let to_read = remaining.min((buf.len() - 4) as u64) as usize;
             source.read_exact(&mut buf[4..4 + to_read])?;
             verifier.update(&buf[4..4 + to_read]);
             // Write wire chunks with combined [length_prefix + data].
@@ -531,6 +535,85 @@ pub(super) fn stream_whole_file_transfer<R: Read, W: Write>(
     })
 }
 
+/// Smallest whole file sent through the zero-copy path.
+///
+/// Each 32KB token costs a header `send` plus a `sendfile` call, where the
+/// buffered path batches two tokens per `write`. Below this size the saved copy
+/// does not pay for the extra syscalls.
+pub(super) const SENDFILE_MIN_FILE_SIZE: u64 = 256 * 1024;
+
+/// Streams an uncompressed whole file to the wire with `sendfile(2)`.
+///
+/// The zero-copy counterpart of [`stream_whole_file_transfer`] for the case
+/// with no token encoder. The whole-file checksum is computed from a read-only
+/// mapping of `source`, and each 32KB literal token is moved from the page
+/// cache to `socket` by the kernel, so the data is never copied through a
+/// userspace buffer on its way out. The wire bytes are identical to the
+/// buffered path: one `MSG_DATA` frame per `[write_int(len) + data]` token,
+/// then the `write_int(0)` end marker through the normal writer.
+///
+/// Returns the checksum together with the number of token-stream bytes
+/// written, matching what a `CountingWriter` around the buffered path reports.
+///
+/// upstream: match.c:match_sums() - `sum_update()` and `send_token()` run on
+/// the same pass over the file data.
+#[allow(clippy::too_many_arguments)]
+pub(super) fn sendfile_whole_file_transfer<W: Write>(
+    writer: &mut crate::writer::ServerWriter<W>,
+    socket: &crate::writer::SendfileSocket,
+    source: &std::fs::File,
+    file_size: u64,
+    checksum_algorithm: ChecksumAlgorithm,
+    checksum_seed: i32,
+    protocol: protocol::ProtocolVersion,
+) -> io::Result<(StreamResult, u64)> {
+    if file_size > LARGE_FILE_WARNING_THRESHOLD {
+        debug_log!(
+            Send,
+            1,
+            "Large whole-file transfer: {} bytes ({:.2} GB). Consider using delta mode.",
+            file_size,
+            file_size as f64 / (1024.0 * 1024.0 * 1024.0)
+        );
+    }
+
+    let map = fast_io::MmapReader::from_file(source.try_clone()?)?;
+    let len = usize::try_from(file_size)
+        .map_err(|_| io::Error::new(io::ErrorKind::InvalidInput, "file too large to map"))?;
+    if map.as_slice().len() < len {
+        return Err(io::Error::new(
+            io::ErrorKind::UnexpectedEof,
+            "source file shrank during transfer",
+        ));
+    }
+    let _ = map.advise_sequential();
+
+    let mut verifier =
+        ChecksumVerifier::for_algorithm_seeded(checksum_algorithm, checksum_seed, protocol);
+    let mut wire_bytes = 0u64;
+    let mut offset = 0usize;
+    while offset < len {
+        let chunk = (len - offset).min(CHUNK_SIZE);
+        verifier.update(map.slice_range(offset, offset + chunk));
+        writer.send_literal_from_file(socket, source, offset as u64, chunk)?;
+        wire_bytes += 4 + chunk as u64;
+        offset += chunk;
+    }
+    write_token_end(writer)?;
+    wire_bytes += 4;
+
+    let mut checksum_buf = [0u8; ChecksumVerifier::MAX_DIGEST_LEN];
+    let checksum_len = verifier.finalize_into(&mut checksum_buf);
+
+    Ok((
+        StreamResult {
+            checksum_buf,
+            checksum_len,
+        },
+        wire_bytes,
+    ))
+}
+
 /// Streams the appended tail of a file to the wire in append mode.
 ///
 /// In append mode the receiver already holds the first `flength` bytes, so the
@@ -568,9 +651,9 @@ pub(super) fn stream_append_transfer<R: Read, W: Write>(
     // sum (trust). Either way the prefix bytes are never sent as tokens.
     let mut prefix_remaining = flength.min(file_size);
     if prefix_remaining > 0 {
-        buf.resize((prefix_remaining as usize).clamp(1, MAX_READ_SIZE), 0);
+        buf.resize(prefix_remaining.clamp(1, MAX_READ_SIZE as u64) as usize, 0);
         while prefix_remaining > 0 {
-            let to_read = buf.len().min(prefix_remaining as usize);
+            let to_read = prefix_remaining.min(buf.len() as u64) as usize;
             source.read_exact(&mut buf[..to_read])?;
             if append_verify {
                 verifier.update(&buf[..to_read]);
@@ -581,12 +664,12 @@ pub(super) fn stream_append_transfer<R: Read, W: Write>(
 
     // Stream [flength, file_size) as literal tokens, folding into the checksum.
     let mut remaining = file_size.saturating_sub(flength);
-    let read_size = (remaining as usize).clamp(1, MAX_READ_SIZE);
+    let read_size = remaining.clamp(1, MAX_READ_SIZE as u64) as usize;
 
     if let Some(encoder) = encoder {
         buf.resize(read_size, 0);
         while remaining > 0 {
-            let to_read = buf.len().min(remaining as usize);
+            let to_read = remaining.min(buf.len() as u64) as usize;
             source.read_exact(&mut buf[..to_read])?;
             verifier.update(&buf[..to_read]);
             encoder.send_literal(writer, &buf[..to_read])?;
@@ -596,7 +679,7 @@ pub(super) fn stream_append_transfer<R: Read, W: Write>(
     } else {
         buf.resize(4 + read_size, 0);
         while remaining > 0 {
-            let to_read = (buf.len() - 4).min(remaining as usize);
+            let to_read = remaining.min((buf.len() - 4) as u64) as usize;
             source.read_exact(&mut buf[4..4 + to_read])?;
             verifier.update(&buf[4..4 + to_read]);
             let mut wire_off = 0;
@@ -1028,6 +1111,86 @@ mod tests {
         );
     }
 
+    // WHY: the sendfile path must be invisible to the receiver. Over a real TCP
+    // socket it has to produce the exact multiplexed byte stream and whole-file
+    // checksum of the buffered path, including a short final token and the
+    // end marker that follows the kernel-sent frames.
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn sendfile_whole_file_matches_buffered_wire_bytes() {
+        use std::io::{Read, Write};
+        use std::net::{TcpListener, TcpStream};
+
+        let data: Vec<u8> = (0..(5 * CHUNK_SIZE + 1234))
+            .map(|i| (i.wrapping_mul(31) ^ (i >> 7)) as u8)
+            .collect();
+        let mut file = tempfile::tempfile().expect("temp source");
+        file.write_all(&data).expect("write source");
+
+        let seed = 0x1234_5678;
+        let proto = protocol::ProtocolVersion::NEWEST;
+
+        let mut expected_wire = Vec::new();
+        let mut buffered = crate::writer::ServerWriter::new_plain(&mut expected_wire)
+            .activate_multiplex()
+            .expect("multiplex");
+        let mut buf = Vec::new();
+        let expected = stream_whole_file_transfer(
+            &mut buffered,
+            &data[..],
+            data.len() as u64,
+            ChecksumAlgorithm::XXH3,
+            seed,
+            proto,
+            None,
+            &mut buf,
+            None,
+        )
+        .expect("buffered stream");
+        buffered.flush().expect("flush buffered");
+        drop(buffered);
+
+        let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+        let client = TcpStream::connect(listener.local_addr().expect("addr")).expect("connect");
+        let (mut server, _) = listener.accept().expect("accept");
+        let drain = std::thread::spawn(move || {
+            let mut wire = Vec::new();
+            server.read_to_end(&mut wire).expect("read wire");
+            wire
+        });
+
+        let socket = crate::writer::SendfileSocket::new(client.try_clone().expect("clone"));
+        let mut writer = crate::writer::ServerWriter::new_plain(client)
+            .activate_multiplex()
+            .expect("multiplex");
+        assert!(writer.supports_sendfile());
+        let (result, token_bytes) = sendfile_whole_file_transfer(
+            &mut writer,
+            &socket,
+            &file,
+            data.len() as u64,
+            ChecksumAlgorithm::XXH3,
+            seed,
+            proto,
+        )
+        .expect("sendfile stream");
+        writer.flush().expect("flush sendfile");
+        drop(writer);
+        drop(socket);
+        let wire = drain.join().expect("drain thread");
+
+        assert_eq!(
+            wire, expected_wire,
+            "sendfile wire bytes must match buffered"
+        );
+        assert_eq!(
+            &result.checksum_buf[..result.checksum_len],
+            &expected.checksum_buf[..expected.checksum_len],
+        );
+        // Five full tokens, one short token, the end marker.
+        assert_eq!(token_bytes, data.len() as u64 + 7 * 4);
+    }
+
     #[cfg(feature = "zstd")]
     #[test]
     fn create_token_encoder_zstd_no_workers() {
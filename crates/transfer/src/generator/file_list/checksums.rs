@@ -0,0 +1,235 @@
+//! Parallel `--checksum` (`-c`) sums for a freshly built file list.
+//!
+//! Hashing every regular file inline in `create_entry` serialises the sender
+//! on one core while the walk waits on each read. A full file-list build
+//! instead defers the sums and hashes all entries in one pass on rayon's
+//! work-stealing pool once the walk is done and before the list is sorted,
+//! so the result is identical to the serial path and nothing reaches the
+//! wire before every sum is in place.
+//!
+//! The worker count is rayon's global pool size, which `--rayon-threads`
+//! bounds; lists below the [`ParallelOp::Checksum`] threshold hash
+//! sequentially.
+//!
+//! # Upstream Reference
+//!
+//! - `flist.c:1444-1447` - `always_checksum && am_sender && S_ISREG` computes
+//!   `file_checksum()` per entry inside `make_file()`
+
+use std::io;
+use std::path::PathBuf;
+
+use crate::parallel_io::{ParallelOp, map_blocking};
+
+use super::super::GeneratorContext;
+use super::entry::flist_file_checksum;
+
+impl GeneratorContext {
+    /// Runs one file-list build with `--checksum` sums deferred.
+    ///
+    /// `build` must call [`fill_flist_checksums`](Self::fill_flist_checksums)
+    /// before the list is sorted or sent. The deferral ends when `build`
+    /// returns, including through an early `?`, so entries created later
+    /// (incremental segments, on-demand fetches) are hashed inline again.
+    pub(super) fn with_deferred_flist_checksums<T>(
+        &mut self,
+        build: impl FnOnce(&mut Self) -> io::Result<T>,
+    ) -> io::Result<T> {
+        self.defer_flist_checksums = self.config.flags.checksum;
+        let result = build(self);
+        self.defer_flist_checksums = false;
+        result
+    }
+
+    /// Computes the deferred `--checksum` sum of every regular file in the
+    /// list and stores it on its entry.
+    ///
+    /// Results are zipped back by position (`map_blocking` preserves input
+    /// order), so each entry receives exactly the digest the inline path
+    /// would have produced. An entry whose read fails keeps no sum, matching
+    /// the inline path's fallback.
+    pub(super) fn fill_flist_checksums(&mut self) {
+        if !std::mem::take(&mut self.defer_flist_checksums) {
+            return;
+        }
+
+        let work: Vec<(usize, PathBuf, u64)> = self
+            .file_list
+            .iter()
+            .enumerate()
+            .filter(|(_, entry)| entry.is_file())
+            .map(|(idx, entry)| (idx, self.reconstruct_source_path(idx), entry.size()))
+            .collect();
+
+        let algorithm = self.get_checksum_algorithm();
+        let checksum_xattr = self.config.file_selection.checksum_xattr.clone();
+        let threshold = self.parallel_thresholds.for_op(ParallelOp::Checksum);
+        let sums = map_blocking(work, threshold, move |(idx, path, size)| {
+            (
+                idx,
+                flist_file_checksum(&path, size, algorithm, checksum_xattr.as_deref()),
+            )
+        });
+
+        for (idx, sum) in sums {
+            if let Some(sum) = sum {
+                self.file_list[idx].set_checksum(sum);
+            }
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use crate::config::ServerConfig;
+    use crate::generator::GeneratorContext;
+    use crate::handshake::HandshakeResult;
+    use crate::parallel_io::ParallelOp;
+    use crate::role::ServerRole;
+    use protocol::ProtocolVersion;
+    use std::ffi::OsString;
+    use std::fs;
+    use std::path::Path;
+    use tempfile::TempDir;
+
+    fn generator(root: &Path) -> GeneratorContext {
+        let handshake = HandshakeResult {
+            protocol: ProtocolVersion::try_from(32u8).unwrap(),
+            buffered: Vec::new(),
+            compat_exchanged: false,
+            client_args: None,
+            io_timeout: None,
+            negotiated_algorithms: None,
+            compat_flags: None,
+            checksum_seed: 0,
+        };
+        let mut config = ServerConfig {
+            role: ServerRole::Generator,
+            protocol: ProtocolVersion::try_from(32u8).unwrap(),
+            flag_string: "-logDtpre.".to_owned(),
+            args: vec![OsString::from(root)],
+            ..Default::default()
+        };
+        config.flags.checksum = true;
+        config.flags.recursive = true;
+        config.flags.numeric_ids = crate::NumericIds::Explicit;
+        GeneratorContext::new_for_test(&handshake, config)
+    }
+
+    /// Builds the list under `threshold` and returns `(name, sum)` per entry.
+    fn build(root: &Path, threshold: usize) -> Vec<(String, Vec<u8>)> {
+        let mut ctx = generator(root);
+        ctx.parallel_thresholds = ctx
+            .parallel_thresholds
+            .with_op(ParallelOp::Checksum, threshold);
+        ctx.build_file_list(&[root.to_path_buf()]).unwrap();
+        assert!(
+            !ctx.defer_flist_checksums,
+            "deferral must end with the build"
+        );
+        ctx.file_list
+            .iter()
+            .map(|e| {
+                (
+                    e.name().to_owned(),
+                    e.checksum().unwrap_or_default().to_vec(),
+                )
+            })
+            .collect()
+    }
+
+    #[test]
+    fn failed_build_ends_the_deferral() {
+        let dir = TempDir::new().unwrap();
+        let mut ctx = generator(dir.path());
+        let result = ctx.with_deferred_flist_checksums(|ctx| {
+            assert!(ctx.defer_flist_checksums);
+            Err::<(), _>(std::io::Error::other("walk failed"))
+        });
+        assert!(result.is_err());
+        assert!(
+            !ctx.defer_flist_checksums,
+            "an early return must not leave inline hashing disabled"
+        );
+    }
+
+    #[test]
+    fn parallel_sums_match_serial_sums() {
+        let dir = TempDir::new().unwrap();
+        let root = dir.path().join("src");
+        fs::create_dir_all(root.join("nested")).unwrap();
+        for i in 0..48u32 {
+            let sub = if i % 3 == 0 { "nested/" } else { "" };
+            let data: Vec<u8> = (0..(i * 4099) as usize)
+                .map(|b| (b as u32 ^ i) as u8)
+                .collect();
+            fs::write(root.join(format!("{sub}file{i:02}.bin")), data).unwrap();
+        }
+
+        let serial = build(&root, usize::MAX);
+        let parallel = build(&root, 1);
+
+        assert_eq!(serial, parallel);
+        let summed = parallel.iter().filter(|(_, sum)| !sum.is_empty()).count();
+        assert_eq!(summed, 48, "every regular file carries a sum");
+    }
+
+    #[cfg(all(unix, feature = "xattr"))]
+    #[test]
+    fn recorded_xattr_sum_replaces_hashing() {
+        use filetime::{FileTime, set_file_mtime};
+
+        let dir = TempDir::new().unwrap();
+        let root = dir.path().join("src");
+        fs::create_dir_all(&root).unwrap();
+        let path = root.join("a.bin");
+        fs::write(&path, b"payload").unwrap();
+        set_file_mtime(&path, FileTime::from_unix_time(1_600_000_000, 0)).unwrap();
+        let name = "user.rsync.checksum";
+        // The generator's default -c algorithm at protocol 32 is MD5.
+        let value = format!("md5 7 1600000000 0 {}", "cd".repeat(16));
+        if xattr::set(&path, name, value.as_bytes()).is_err() {
+            eprintln!("user xattrs unsupported, skipping");
+            return;
+        }
+
+        let mut ctx = generator(&root);
+        ctx.config.file_selection.checksum_xattr = Some(name.into());
+        let meta = fs::symlink_metadata(&path).unwrap();
+        let inline = ctx.create_entry(&path, "a.bin".into(), &meta).unwrap();
+        assert_eq!(inline.checksum(), Some(&[0xcd; 16][..]));
+
+        ctx.build_file_list(&[root.clone()]).unwrap();
+        let entry = ctx
+            .file_list
+            .iter()
+            .find(|e| e.name().ends_with("a.bin"))
+            .expect("file entry");
+        assert_eq!(entry.checksum(), Some(&[0xcd; 16][..]));
+    }
+
+    #[test]
+    fn deferred_sum_equals_inline_sum() {
+        let dir = TempDir::new().unwrap();
+        let root = dir.path().join("src");
+        fs::create_dir_all(&root).unwrap();
+        let path = root.join("payload.bin");
+        fs::write(&path, vec![0xA5u8; 300 * 1024]).unwrap();
+
+        let ctx = generator(&root);
+        let meta = fs::symlink_metadata(&path).unwrap();
+        let inline = ctx
+            .create_entry(&path, "payload.bin".into(), &meta)
+            .unwrap()
+            .checksum()
+            .unwrap()
+            .to_vec();
+
+        let built = build(&root, 1);
+        let (_, deferred) = built
+            .iter()
+            .find(|(name, _)| name.ends_with("payload.bin"))
+            .expect("payload entry");
+        assert_eq!(deferred, &inline);
+    }
+}
@@ -295,14 +295,31 @@ impl GeneratorContext {
         // preserves the original ownership.
         #[cfg(unix)]
         if self.config.flags.owner {
-            let uid = fake_super_override
+            let mut uid = fake_super_override
                 .as_ref()
                 .map_or_else(|| metadata.uid(), |s| s.uid);
+            let names = self.config.flags.numeric_ids.is_off();
+            // A daemon module's `uid map` rewrites the served owner before the
+            // entry is recorded, so the id list and the inline name below both
+            // describe the remapped account rather than the on-disk one.
+            if let Some(mapping) = self.config.daemon_user_mapping.as_ref() {
+                let source_name = if names {
+                    metadata::id_lookup::lookup_user_name_cached(uid)
+                        .ok()
+                        .flatten()
+                } else {
+                    None
+                };
+                if let Ok(Some(mapped)) = mapping.map_uid_named(uid, source_name.as_deref(), !names)
+                {
+                    uid = mapped;
+                }
+            }
             entry.set_uid(uid);
             // upstream: flist.c:478-482 - add_uid() looks up name for inline
             // sending via XMIT_USER_NAME_FOLLOWS when INC_RECURSE is active.
             // Without names, the receiver can't map uid->name on the remote.
-            if self.config.flags.numeric_ids.is_off() {
+            if names {
                 if let Ok(Some(name_bytes)) = metadata::id_lookup::lookup_user_name_cached(uid) {
                     if let Ok(name) = String::from_utf8(name_bytes) {
                         entry.set_user_name(name);
@@ -312,13 +329,27 @@ impl GeneratorContext {
         }
         #[cfg(unix)]
         if self.config.flags.group {
-            let gid = fake_super_override
+            let mut gid = fake_super_override
                 .as_ref()
                 .map_or_else(|| metadata.gid(), |s| s.gid);
+            let names = self.config.flags.numeric_ids.is_off();
+            if let Some(mapping) = self.config.daemon_group_mapping.as_ref() {
+                let source_name = if names {
+                    metadata::id_lookup::lookup_group_name_cached(gid)
+                        .ok()
+                        .flatten()
+                } else {
+                    None
+                };
+                if let Ok(Some(mapped)) = mapping.map_gid_named(gid, source_name.as_deref(), !names)
+                {
+                    gid = mapped;
+                }
+            }
             entry.set_gid(gid);
             // upstream: flist.c:488-492 - add_gid() looks up name for inline
             // sending via XMIT_GROUP_NAME_FOLLOWS when INC_RECURSE is active.
-            if self.config.flags.numeric_ids.is_off() {
+            if names {
                 if let Ok(Some(name_bytes)) = metadata::id_lookup::lookup_group_name_cached(gid) {
                     if let Ok(name) = String::from_utf8(name_bytes) {
                         entry.set_group_name(name);
@@ -422,7 +453,9 @@ impl GeneratorContext {
         // it the sender emits an all-zero checksum, the receiver's `-c`
         // quick-check never matches, and every content-identical file is
         // needlessly re-transferred.
-        if self.config.flags.checksum && entry.is_file() {
+        // A full file-list build hashes every file afterwards on the rayon
+        // pool (`fill_flist_checksums`); entries created outside one hash here.
+        if self.config.flags.checksum && !self.defer_flist_checksums && entry.is_file() {
             if let Some(sum) = self.compute_flist_checksum(full_path, entry.size()) {
                 entry.set_checksum(sum);
             }
@@ -442,23 +475,12 @@ impl GeneratorContext {
     /// so the transfer falls back to sending the file (upstream sets an
     /// all-zero sum on open failure, which likewise never matches).
     fn compute_flist_checksum(&self, path: &Path, file_size: u64) -> Option<Vec<u8>> {
-        use std::io::Read;
-
-        let mut file = std::fs::File::open(path).ok()?;
-        let mut verifier =
-            crate::delta_apply::ChecksumVerifier::for_algorithm(self.get_checksum_algorithm());
-        // upstream: rsync.h MAX_MAP_SIZE = 256*1024 - the map_file() window.
-        let mut buf = vec![0u8; 256 * 1024];
-        let mut remaining = file_size;
-        while remaining > 0 {
-            let to_read = buf.len().min(remaining as usize);
-            file.read_exact(&mut buf[..to_read]).ok()?;
-            verifier.update(&buf[..to_read]);
-            remaining -= to_read as u64;
-        }
-        let mut digest = [0u8; crate::delta_apply::ChecksumVerifier::MAX_DIGEST_LEN];
-        let len = verifier.finalize_into(&mut digest);
-        Some(digest[..len].to_vec())
+        flist_file_checksum(
+            path,
+            file_size,
+            self.get_checksum_algorithm(),
+            self.config.file_selection.checksum_xattr.as_deref(),
+        )
     }
 
     /// Reads the source-side `user.rsync.%stat` xattr when fake-super is active.
@@ -507,6 +529,45 @@ impl GeneratorContext {
     // call site later should reintroduce a stub here.
 }
 
+/// Reads `file_size` bytes of `path` and returns their unseeded `algorithm`
+/// digest, or `None` on any I/O error.
+///
+/// With `--checksum-xattr` a digest still valid for the file is taken from
+/// the `checksum_xattr` attribute instead of reading the data.
+///
+/// Shared by the inline path in `create_entry` and the parallel pass in
+/// `fill_flist_checksums`, so both produce byte-identical sums.
+pub(in crate::generator) fn flist_file_checksum(
+    path: &Path,
+    file_size: u64,
+    algorithm: protocol::ChecksumAlgorithm,
+    checksum_xattr: Option<&std::ffi::OsStr>,
+) -> Option<Vec<u8>> {
+    use std::io::Read;
+
+    if let Some(sum) = checksum_xattr
+        .and_then(|name| crate::checksum_xattr::recorded_checksum(path, name, algorithm))
+    {
+        return Some(sum);
+    }
+
+    // Opened without blocking so a FIFO swapped in since the stat is skipped.
+    let mut file = super::super::super::open_source::open_source_with_noatime(path, false).ok()?;
+    let mut verifier = crate::delta_apply::ChecksumVerifier::for_algorithm(algorithm);
+    // upstream: rsync.h MAX_MAP_SIZE = 256*1024 - the map_file() window.
+    let mut buf = vec![0u8; 256 * 1024];
+    let mut remaining = file_size;
+    while remaining > 0 {
+        let to_read = remaining.min(buf.len() as u64) as usize;
+        file.read_exact(&mut buf[..to_read]).ok()?;
+        verifier.update(&buf[..to_read]);
+        remaining -= to_read as u64;
+    }
+    let mut digest = [0u8; crate::delta_apply::ChecksumVerifier::MAX_DIGEST_LEN];
+    let len = verifier.finalize_into(&mut digest);
+    Some(digest[..len].to_vec())
+}
+
 #[cfg(all(test, unix, feature = "xattr"))]
 mod fake_super_round_trip_tests {
     //! End-to-end sender override: place a fake-super xattr on a regular
@@ -1479,3 +1540,130 @@ mod flist_checksum_tests {
         );
     }
 }
+
+#[cfg(all(test, unix))]
+mod daemon_id_map_tests {
+    //! Daemon `uid map` / `gid map` regression: a module serving files must
+    //! rewrite each entry's owner and group, together with the names sent
+    //! alongside them, before the entry reaches the wire. Numeric rules keep
+    //! working under `--numeric-ids`; name rules need names and are skipped.
+
+    use crate::config::ServerConfig;
+    use crate::generator::GeneratorContext;
+    use crate::handshake::HandshakeResult;
+    use crate::role::ServerRole;
+    use ::metadata::id_lookup::{lookup_group_name_cached, lookup_user_name_cached};
+    use ::metadata::{GroupMapping, UserMapping};
+    use protocol::ProtocolVersion;
+    use protocol::flist::FileEntry;
+    use std::ffi::OsString;
+    use std::os::unix::fs::MetadataExt;
+    use std::path::PathBuf;
+    use tempfile::TempDir;
+
+    fn make_generator(
+        user_mapping: Option<UserMapping>,
+        group_mapping: Option<GroupMapping>,
+        numeric_ids: crate::NumericIds,
+    ) -> GeneratorContext {
+        let handshake = HandshakeResult {
+            protocol: ProtocolVersion::try_from(32u8).unwrap(),
+            buffered: Vec::new(),
+            compat_exchanged: false,
+            client_args: None,
+            io_timeout: None,
+            negotiated_algorithms: None,
+            compat_flags: None,
+            checksum_seed: 0,
+        };
+        let mut config = ServerConfig {
+            role: ServerRole::Generator,
+            protocol: ProtocolVersion::try_from(32u8).unwrap(),
+            flag_string: "-logDtpre.".to_owned(),
+            args: vec![OsString::from(".")],
+            daemon_user_mapping: user_mapping,
+            daemon_group_mapping: group_mapping,
+            ..Default::default()
+        };
+        config.flags.owner = true;
+        config.flags.group = true;
+        config.flags.numeric_ids = numeric_ids;
+        GeneratorContext::new_for_test(&handshake, config)
+    }
+
+    fn served_entry(ctx: &GeneratorContext) -> (FileEntry, std::fs::Metadata, TempDir) {
+        let tmp = TempDir::new().expect("tempdir");
+        let path = tmp.path().join("served.txt");
+        std::fs::write(&path, b"payload").expect("write");
+        let meta = std::fs::symlink_metadata(&path).expect("metadata");
+        let entry = ctx
+            .create_entry(&path, PathBuf::from("served.txt"), &meta)
+            .expect("create_entry");
+        (entry, meta, tmp)
+    }
+
+    fn name_of(bytes: Option<Vec<u8>>) -> Option<String> {
+        bytes.and_then(|b| String::from_utf8(b).ok())
+    }
+
+    /// A wildcard map to root must report uid/gid 0 and root's names in the
+    /// served entry regardless of who owns the file on disk.
+    #[test]
+    fn wildcard_map_rewrites_owner_and_names() {
+        let ctx = make_generator(
+            Some(UserMapping::parse("*:0").expect("parse uid map")),
+            Some(GroupMapping::parse("*:0").expect("parse gid map")),
+            crate::NumericIds::Off,
+        );
+        let (entry, _meta, _tmp) = served_entry(&ctx);
+
+        assert_eq!(entry.uid(), Some(0));
+        assert_eq!(entry.gid(), Some(0));
+        assert_eq!(
+            entry.user_name().map(str::to_owned),
+            name_of(lookup_user_name_cached(0).ok().flatten())
+        );
+        assert_eq!(
+            entry.group_name().map(str::to_owned),
+            name_of(lookup_group_name_cached(0).ok().flatten())
+        );
+    }
+
+    /// Numeric rules match the on-disk id even when `--numeric-ids` drops
+    /// the names, so the served owner is still rewritten.
+    #[test]
+    fn numeric_map_applies_under_numeric_ids() {
+        let probe = TempDir::new().expect("tempdir");
+        let probe_meta = std::fs::metadata(probe.path()).expect("metadata");
+        let ctx = make_generator(
+            Some(UserMapping::parse(&format!("{}:54321", probe_meta.uid())).unwrap()),
+            Some(GroupMapping::parse(&format!("{}:54322", probe_meta.gid())).unwrap()),
+            crate::NumericIds::Explicit,
+        );
+        let (entry, _meta, _tmp) = served_entry(&ctx);
+
+        assert_eq!(entry.uid(), Some(54321));
+        assert_eq!(entry.gid(), Some(54322));
+        assert!(entry.user_name().is_none());
+        assert!(entry.group_name().is_none());
+    }
+
+    /// Under `--numeric-ids` there is no name to match, so a name rule
+    /// leaves the on-disk ids untouched.
+    #[test]
+    fn name_map_is_skipped_under_numeric_ids() {
+        let probe = TempDir::new().expect("tempdir");
+        let probe_meta = std::fs::metadata(probe.path()).expect("metadata");
+        let Some(owner) = name_of(lookup_user_name_cached(probe_meta.uid()).ok().flatten()) else {
+            return;
+        };
+        let ctx = make_generator(
+            Some(UserMapping::parse(&format!("{owner}:54321")).unwrap()),
+            None,
+            crate::NumericIds::Explicit,
+        );
+        let (entry, meta, _tmp) = served_entry(&ctx);
+
+        assert_eq!(entry.uid(), Some(meta.uid()));
+    }
+}
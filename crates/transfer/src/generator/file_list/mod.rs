@@ -7,6 +7,7 @@
 //! # Submodules
 //!
 //! - `batch_stat` - Parallel metadata resolution for directory children
+//! - `checksums` - Parallel `--checksum` sums for the built list
 //! - `walk` - Recursive directory traversal and symlink resolution
 //! - `entry` - `FileEntry` construction from filesystem metadata
 //! - `hardlinks` - Hardlink index assignment and UID/GID collection
@@ -19,6 +20,7 @@
 //! - `hlink.c:match_hard_links()` - post-sort hardlink index assignment
 
 mod batch_stat;
+mod checksums;
 mod entry;
 mod hardlinks;
 mod iconv;
@@ -72,6 +74,10 @@ impl GeneratorContext {
     ///
     /// Mirrors upstream recursive directory scanning and file list construction behavior.
     pub fn build_file_list(&mut self, base_paths: &[PathBuf]) -> io::Result<usize> {
+        self.with_deferred_flist_checksums(|ctx| ctx.build_file_list_from_paths(base_paths))
+    }
+
+    fn build_file_list_from_paths(&mut self, base_paths: &[PathBuf]) -> io::Result<usize> {
         // upstream: stats.flist_buildtime
         self.timing.flist_build_start = Some(Instant::now());
 
@@ -105,12 +111,26 @@ impl GeneratorContext {
             } else {
                 non_relative_walk_base(base_path)
             };
+            let (base, path) = self.reach_source_base(base, path);
             // upstream: flist.c:2254-2272 - pre-stat each top-level source and
             // apply missing_args handling. Separates "source never existed" from
             // "source vanished during recursive walk".
+            let listed_before = self.file_list.len();
             if !self.try_walk_source_entry(&base, &path)? {
                 continue;
             }
+            // upstream: flist.c:2477 - `xfer_dirs && name_type != NORMAL_NAME`:
+            // with `--dirs` but no `-r`, a trailing-slash or `.` source still
+            // sends its immediate children. Subdirectories among them are
+            // listed (and so created) but not entered.
+            if self.config.flags.dirs
+                && !self.config.flags.recursive
+                && self.file_list.len() > listed_before
+                && names_directory_contents(base_path)
+                && std::fs::metadata(&path).is_ok_and(|meta| meta.is_dir())
+            {
+                self.scan_files_from_marker_dir(&base, &path)?;
+            }
             // upstream: flist.c:2257-2258 - `if (relative_paths &&
             // protocol_version >= 30) implied_dirs = 1;` forces the sender to
             // emit flagged implied parent dirs at protocol >= 30 regardless of
@@ -130,6 +150,8 @@ impl GeneratorContext {
         // cannot be strictly transcoded under --iconv before ndx assignment and
         // INC_RECURSE segmentation, so sender/receiver ndx values stay aligned.
         self.drop_unconvertible_entries();
+        // upstream: flist.c:1444 - `-c` sums, hashed here in parallel.
+        self.fill_flist_checksums();
 
         // upstream: flist.c:f_name_cmp() - sort both arrays via indirect permutation.
         // --qsort uses unstable sort (flist.c:2991).
@@ -191,6 +213,16 @@ impl GeneratorContext {
         &mut self,
         base_dir: &Path,
         entries: &[super::filters::FilesFromEntry],
+    ) -> io::Result<usize> {
+        self.with_deferred_flist_checksums(|ctx| {
+            ctx.build_file_list_from_entries(base_dir, entries)
+        })
+    }
+
+    fn build_file_list_from_entries(
+        &mut self,
+        base_dir: &Path,
+        entries: &[super::filters::FilesFromEntry],
     ) -> io::Result<usize> {
         self.timing.flist_build_start = Some(Instant::now());
 
@@ -377,6 +409,8 @@ impl GeneratorContext {
         // The --files-from build path runs send_file_name() per source exactly
         // like the recursive walk, so the same strict drop applies here.
         self.drop_unconvertible_entries();
+        // upstream: flist.c:1444 - `-c` sums, hashed here in parallel.
+        self.fill_flist_checksums();
 
         // upstream: flist.c:f_name_cmp() - sort via indirect permutation
         {
@@ -413,6 +447,32 @@ impl GeneratorContext {
         Ok(count)
     }
 
+    /// Replaces a walk base of `PATH_MAX` bytes or more with its anchored
+    /// alias, rebasing `path` below it.
+    ///
+    /// Upstream `chdir()`s into the base before walking (`flist.c:2349`
+    /// `change_dir(dir)`), so only names relative to it reach the kernel. The
+    /// alias is the per-operand equivalent; its anchor stays in
+    /// `source_anchors` until the list is cleared, so the later opens through
+    /// `source_bases` still resolve. Wire names are stripped from the alias and
+    /// so match the operand's. When the base cannot be reached the pair is
+    /// returned unchanged and the walk reports the error as before.
+    fn reach_source_base(&mut self, base: PathBuf, path: PathBuf) -> (PathBuf, PathBuf) {
+        let Ok(anchor) = fast_io::reach_long_path(&base) else {
+            return (base, path);
+        };
+        if !anchor.is_anchored() {
+            return (base, path);
+        }
+        let alias = anchor.path().to_path_buf();
+        let path = match path.strip_prefix(&base) {
+            Ok(rest) if !rest.as_os_str().is_empty() => alias.join(rest),
+            _ => alias.clone(),
+        };
+        self.source_anchors.push(anchor);
+        (alias, path)
+    }
+
     /// Emits a directory entry for every implied ancestor of a `--relative`
     /// source path between `base` and `path`.
     ///
@@ -544,6 +604,16 @@ fn find_dot_dir_anchor(path: &Path) -> Option<usize> {
 ///   * `/srv/mod/`     -> base=`/srv/mod/`, path=`/srv/mod/`     (dotdir)
 ///   * `/`             -> base=`/`,         path=`/`             (dotdir)
 ///   * `foo`           -> base=`.`,         path=`foo`
+/// Reports whether a source operand names a directory's contents rather
+/// than the directory itself: `dir/`, `dir/.` or `.`.
+///
+/// upstream: flist.c:2312-2330 - these are the SLASH_ENDING_NAME and
+/// DOTDIR_NAME cases of `send_file_list()`.
+fn names_directory_contents(path: &Path) -> bool {
+    let bytes = path.as_os_str().as_encoded_bytes();
+    bytes.ends_with(b"/") || bytes.ends_with(b"/.") || bytes == b"."
+}
+
 fn non_relative_walk_base(path: &Path) -> (PathBuf, PathBuf) {
     // Upstream's DOTDIR_NAME branch (flist.c:2312-2322) preserves a
     // trailing slash to signal "transfer the contents only". Preserve
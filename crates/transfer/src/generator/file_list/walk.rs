@@ -83,7 +83,18 @@ impl GeneratorContext {
         // upstream: flist.c:2425 - link_stat() once, then pass &st to
         // send_file_name(). Reuse the metadata to avoid a redundant stat
         // inside walk_path_with_metadata.
-        match self.resolve_symlink_metadata(path, base) {
+        //
+        // A non-chrooted daemon module has no kernel wall at its root, so a
+        // source reached through a symlinked directory (`mod/etc/passwd`
+        // with `etc -> /etc`) would be served from outside it. Report such a
+        // source as missing, exactly as the chroot would.
+        let follow_leaf = super::names_directory_contents(path);
+        let resolved = if self.leaves_sanitize_root(path, follow_leaf) {
+            Err(io::Error::from_raw_os_error(libc::ENOENT))
+        } else {
+            self.resolve_symlink_metadata(path, base)
+        };
+        match resolved {
             Ok(metadata) => {
                 // If a prior pass already emitted this directory (e.g. the
                 // implied-parent loop in build_file_list_with_base), skip the
@@ -260,6 +271,22 @@ impl GeneratorContext {
             }
         }
 
+        // upstream: flist.c:make_file() - a daemon module with `ignore
+        // nonreadable` drops entries it cannot read (symlinks exempt) before
+        // they reach the file list, so no open error is ever reported.
+        if self.config.file_selection.ignore_nonreadable
+            && !metadata.file_type().is_symlink()
+            && !is_readable(&path)
+        {
+            return Ok(());
+        }
+
+        // oc-rsync extension: a resuming client already holds this file with
+        // identical contents, so it is left out of the file list entirely.
+        if metadata.is_file() && self.config.resume_skip.skips(&relative, &path) {
+            return Ok(());
+        }
+
         let mut entry = match self.create_entry(&path, relative, &metadata) {
             Ok(e) => e,
             Err(e) => {
@@ -347,6 +374,9 @@ impl GeneratorContext {
     /// `try_walk_source_entry_dedup`; this helper just adds the children at
     /// the same level a global `-r` would have produced.
     ///
+    /// `build_file_list` reuses it for positional trailing-slash sources under
+    /// `--dirs` without `-r`, the same SLASH_ENDING_NAME rule.
+    ///
     /// Wraps `scan_directory_batched` in the per-directory filter scope
     /// (`enter_directory` / `leave_directory`) so per-dir merge files are
     /// honoured for the recursion the way they are during a normal walk.
@@ -430,11 +460,21 @@ impl GeneratorContext {
             return Ok(());
         }
 
+        // Visit children in byte order rather than readdir order. The wire
+        // list is re-sorted by f_name_cmp() after the walk, but diagnostics
+        // and the placement of equal names under --qsort follow the walk
+        // order, so fixing it here keeps repeated runs over the same tree
+        // identical (e.g. for --write-batch).
+        child_paths.sort_unstable();
+
         // Phase 2: determine stat mode and batch-resolve metadata.
         // --copy-links: follow all symlinks (fs::metadata)
         // default: lstat (fs::symlink_metadata)
         // --copy-unsafe-links needs post-batch fixup for unsafe symlinks
-        let follow = self.config.flags.copy_links;
+        // A sanitize root turns --copy-links into a post-batch fixup too, so
+        // each symlink's target can be checked before it is followed.
+        let confine_links = self.config.flags.copy_links && self.config.sanitize_root.is_some();
+        let follow = self.config.flags.copy_links && !confine_links;
         let stat_results = batch_stat_dir_entries(child_paths, follow, &self.parallel_thresholds);
 
         // Phase 3: process each (path, metadata) pair
@@ -442,6 +482,20 @@ impl GeneratorContext {
             let StatResult { path, metadata } = result;
             match metadata {
                 Ok(mut meta) => {
+                    if confine_links
+                        && meta.file_type().is_symlink()
+                        && !self.leaves_sanitize_root(&path, true)
+                    {
+                        match std::fs::metadata(&path) {
+                            Ok(followed) => meta = followed,
+                            Err(e) => {
+                                self.log_stat_error(&path, &e);
+                                self.record_io_error(&e);
+                                continue;
+                            }
+                        }
+                    }
+
                     // upstream: flist.c:1362-1370 link_stat() - with
                     // --copy-dirlinks (follow_dirlinks), a symlink whose
                     // target is a directory is transmitted as a real
@@ -450,7 +504,11 @@ impl GeneratorContext {
                     // readlink_stat() re-examines S_ISLNK. Only symlinks to
                     // directories are followed; symlinks to files stay
                     // symlinks (distinct from --copy-links, which follows all).
-                    if !follow && self.config.flags.copy_dirlinks && meta.file_type().is_symlink() {
+                    if !follow
+                        && self.config.flags.copy_dirlinks
+                        && meta.file_type().is_symlink()
+                        && !self.leaves_sanitize_root(&path, true)
+                    {
                         if let Ok(followed) = std::fs::metadata(&path) {
                             if followed.file_type().is_dir() {
                                 meta = followed;
@@ -470,7 +528,8 @@ impl GeneratorContext {
                             if super::super::super::symlink_safety::is_unsafe_symlink(
                                 target.as_os_str(),
                                 relative,
-                            ) {
+                            ) && !self.leaves_sanitize_root(&path, true)
+                            {
                                 // upstream: flist.c:229 - INFO_GTE(SYMSAFE, 1)
                                 // fires before the target is dereferenced.
                                 info_log!(
@@ -522,6 +581,42 @@ impl GeneratorContext {
         }
     }
 
+    /// Reports whether `path` resolves outside the configured sanitize root.
+    ///
+    /// Only a daemon module served without `use chroot` sets the root; with
+    /// none configured nothing is outside it. The directories leading to
+    /// `path` are always resolved; the final component is resolved only when
+    /// `follow_leaf` is true, i.e. when the caller is about to follow it. A
+    /// path that cannot be resolved at all is left for the caller's own stat
+    /// to report.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `util1.c:sanitize_path()` - confines sender names to the module
+    ///   directory when `sanitize_paths` is set.
+    fn leaves_sanitize_root(&self, path: &Path, follow_leaf: bool) -> bool {
+        let Some(root) = self.config.sanitize_root.as_deref() else {
+            return false;
+        };
+        let mut components = path.components();
+        let resolved = match components.next_back() {
+            Some(std::path::Component::Normal(name)) if !follow_leaf => {
+                let parent = components.as_path();
+                let parent = if parent.as_os_str().is_empty() {
+                    Path::new(".")
+                } else {
+                    parent
+                };
+                std::fs::canonicalize(parent).map(|dir| dir.join(name))
+            }
+            _ => std::fs::canonicalize(path),
+        };
+        match resolved {
+            Ok(resolved) => !resolved.starts_with(root),
+            Err(_) => false,
+        }
+    }
+
     /// Resolves symlink metadata following upstream `flist.c:readlink_stat()`.
     ///
     /// Three modes of symlink resolution:
@@ -555,7 +650,8 @@ impl GeneratorContext {
             }
         };
 
-        if self.config.flags.copy_links {
+        // A symlink leading out of the sanitize root stays a symlink.
+        if self.config.flags.copy_links && !self.leaves_sanitize_root(path, true) {
             return std::fs::metadata(path);
         }
 
@@ -568,7 +664,10 @@ impl GeneratorContext {
         // (dirlink follow) running before readlink_stat()'s S_ISLNK
         // re-examination. Only symlinks to directories are followed;
         // symlinks to files stay symlinks (distinct from --copy-links).
-        if self.config.flags.copy_dirlinks && meta.file_type().is_symlink() {
+        if self.config.flags.copy_dirlinks
+            && meta.file_type().is_symlink()
+            && !self.leaves_sanitize_root(path, true)
+        {
             if let Ok(followed) = std::fs::metadata(path) {
                 if followed.file_type().is_dir() {
                     return Ok(followed);
@@ -581,6 +680,7 @@ impl GeneratorContext {
             let target = std::fs::read_link(path)?;
             let relative = path.strip_prefix(base).unwrap_or(path);
             if super::super::super::symlink_safety::is_unsafe_symlink(target.as_os_str(), relative)
+                && !self.leaves_sanitize_root(path, true)
             {
                 // upstream: flist.c:229 - INFO_GTE(SYMSAFE, 1) fires before
                 // the unsafe symlink is dereferenced into a regular entry.
@@ -599,6 +699,25 @@ impl GeneratorContext {
     }
 }
 
+/// Reports whether the sender may read `path`.
+///
+/// Asks the kernel with `access(R_OK)` rather than opening the entry, so a
+/// FIFO or device node is never opened and a large tree pays no extra open
+/// per file.
+// upstream: flist.c:make_file() - `ignore_nonreadable` drops an entry when
+// `access(thisname, R_OK) != 0`, with no open of the entry itself.
+#[cfg(unix)]
+fn is_readable(path: &Path) -> bool {
+    rustix::fs::access(path, rustix::fs::Access::READ_OK).is_ok()
+}
+
+/// Windows has no read permission bit for `access(R_OK)` to test; an ACL
+/// that denies reading surfaces when the sender opens the file instead.
+#[cfg(not(unix))]
+fn is_readable(_path: &Path) -> bool {
+    true
+}
+
 #[cfg(test)]
 mod rsyserr_wording_tests {
     //! Pin per-file `rsyserr`-equivalent wording to upstream rsync 3.4.1
@@ -10,34 +10,99 @@
 //!
 //! On every other target the function is a thin wrapper over
 //! `File::open(path)` because `O_NOATIME` is not defined.
+//!
+//! On Unix every open also carries `O_NONBLOCK`. The file list only admits
+//! regular files to the send loop, but a FIFO can take a file's place before
+//! it is opened, and a blocking `open(2)` of a FIFO waits forever for a
+//! writer. With `O_NONBLOCK` the open returns at once and the source is
+//! refused as not a regular file. The flag has no effect on regular-file
+//! reads; a `--copy-devices` device is reopened without it.
 
 use std::fs;
 use std::io;
 use std::path::Path;
 
-#[cfg(any(target_os = "linux", target_os = "android"))]
-use std::os::unix::fs::OpenOptionsExt;
+#[cfg(unix)]
+use std::os::unix::fs::{FileTypeExt, OpenOptionsExt};
 
 #[cfg(any(target_os = "linux", target_os = "android"))]
 use libc::{EACCES, EINVAL, ENOTSUP, EPERM, EROFS, O_NOATIME};
 
+/// Extra open flags that keep a FIFO from blocking the open.
+#[cfg(unix)]
+const NONBLOCK: i32 = libc::O_NONBLOCK;
+
 /// Opens a source file for reading, honouring `--open-noatime`.
 ///
+/// # Errors
+///
+/// Besides the open error itself, returns
+/// [`InvalidInput`](io::ErrorKind::InvalidInput) when `path` turns out to be
+/// a FIFO or socket.
+///
 /// upstream: syscall.c do_open / do_open_nofollow (3.4.2 propagates
 /// `O_NOATIME` through both paths via the `open_noatime` global).
 pub(super) fn open_source_with_noatime(path: &Path, use_noatime: bool) -> io::Result<fs::File> {
+    #[cfg(unix)]
+    {
+        let file = open_with_flags(path, use_noatime, NONBLOCK)?;
+        let file_type = file.metadata()?.file_type();
+        if file_type.is_fifo() || file_type.is_socket() {
+            return Err(io::Error::new(
+                io::ErrorKind::InvalidInput,
+                format!("{} is not a regular file", path.display()),
+            ));
+        }
+        if file_type.is_block_device() || file_type.is_char_device() {
+            drop(file);
+            return open_with_flags(path, use_noatime, 0);
+        }
+        Ok(file)
+    }
+    #[cfg(not(unix))]
+    {
+        open_with_flags(path, use_noatime, 0)
+    }
+}
+
+/// Returns whether `path` currently names a FIFO or socket.
+///
+/// The io_uring fast path opens sources itself without `O_NONBLOCK`, so it
+/// checks this first and leaves such a path to [`open_source_with_noatime`].
+pub(super) fn source_is_pipe(path: &Path) -> bool {
+    #[cfg(unix)]
+    {
+        fs::metadata(path).is_ok_and(|m| {
+            let file_type = m.file_type();
+            file_type.is_fifo() || file_type.is_socket()
+        })
+    }
+    #[cfg(not(unix))]
+    {
+        let _ = path;
+        false
+    }
+}
+
+fn open_with_flags(path: &Path, use_noatime: bool, flags: i32) -> io::Result<fs::File> {
     if use_noatime {
-        if let Some(file) = try_open_noatime(path)? {
+        if let Some(file) = try_open_noatime(path, flags)? {
             return Ok(file);
         }
     }
-    fs::File::open(path)
+    let mut options = fs::OpenOptions::new();
+    options.read(true);
+    #[cfg(unix)]
+    options.custom_flags(flags);
+    #[cfg(not(unix))]
+    let _ = flags;
+    options.open(path)
 }
 
 #[cfg(any(target_os = "linux", target_os = "android"))]
-fn try_open_noatime(path: &Path) -> io::Result<Option<fs::File>> {
+fn try_open_noatime(path: &Path, flags: i32) -> io::Result<Option<fs::File>> {
     let mut options = fs::OpenOptions::new();
-    options.read(true).custom_flags(O_NOATIME);
+    options.read(true).custom_flags(O_NOATIME | flags);
     match options.open(path) {
         Ok(file) => Ok(Some(file)),
         Err(error) => match error.raw_os_error() {
@@ -48,7 +113,7 @@ fn try_open_noatime(path: &Path) -> io::Result<Option<fs::File>> {
 }
 
 #[cfg(not(any(target_os = "linux", target_os = "android")))]
-fn try_open_noatime(_path: &Path) -> io::Result<Option<fs::File>> {
+fn try_open_noatime(_path: &Path, _flags: i32) -> io::Result<Option<fs::File>> {
     Ok(None)
 }
 
@@ -83,6 +148,22 @@ mod tests {
         assert_eq!(contents, b"payload");
     }
 
+    /// A FIFO with no writer is refused at once instead of blocking the open.
+    #[cfg(unix)]
+    #[test]
+    fn open_source_refuses_fifo_without_blocking() {
+        let dir = tempfile::tempdir().unwrap();
+        let path = dir.path().join("pipe");
+        metadata::create_fifo_node_from_parts(&path, 0o644, false, false).unwrap();
+
+        for use_noatime in [false, true] {
+            let err = open_source_with_noatime(&path, use_noatime).expect_err("fifo refused");
+            assert_eq!(err.kind(), io::ErrorKind::InvalidInput);
+        }
+        assert!(source_is_pipe(&path));
+        assert!(!source_is_pipe(dir.path()));
+    }
+
     /// Linux-only regression test for upstream 3.4.2 `O_NOATIME` parity.
     ///
     /// Backdates the source file's atime, reads through
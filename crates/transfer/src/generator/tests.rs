@@ -2667,6 +2667,75 @@ fn to_exit_code_no_errors_returns_zero() {
     assert_eq!(io_error_flags::to_exit_code(0), 0);
 }
 
+/// Builds a recursive file list over a tree holding one mode-000 file and
+/// returns the entry names, or `None` when the process can read it anyway
+/// (root or `CAP_DAC_OVERRIDE`), which makes the scenario untestable.
+#[cfg(unix)]
+fn file_list_with_unreadable_file(ignore_nonreadable: bool) -> Option<Vec<String>> {
+    use std::os::unix::fs::PermissionsExt;
+
+    let temp_dir = TempDir::new().unwrap();
+    let src = temp_dir.path().join("src");
+    fs::create_dir_all(&src).unwrap();
+    fs::write(src.join("readable.txt"), b"ok").unwrap();
+    let secret = src.join("secret.txt");
+    fs::write(&secret, b"hidden").unwrap();
+    fs::set_permissions(&secret, fs::Permissions::from_mode(0o000)).unwrap();
+    if fs::File::open(&secret).is_ok() {
+        return None;
+    }
+
+    let handshake = test_handshake();
+    let mut config = test_config();
+    config.flags.recursive = true;
+    config.args = vec![OsString::from(&src)];
+    config.file_selection.ignore_nonreadable = ignore_nonreadable;
+    let mut ctx = GeneratorContext::new_for_test(&handshake, config);
+    ctx.build_file_list(&[src.clone()]).unwrap();
+    assert_eq!(
+        ctx.io_error(),
+        0,
+        "building the list must not flag io errors"
+    );
+    let names = ctx
+        .file_list()
+        .iter()
+        .map(|e| e.name().to_owned())
+        .collect();
+
+    fs::set_permissions(&secret, fs::Permissions::from_mode(0o644)).unwrap();
+    Some(names)
+}
+
+#[cfg(unix)]
+#[test]
+fn build_file_list_ignore_nonreadable_skips_unreadable_file() {
+    // upstream: flist.c:make_file() - `ignore nonreadable` drops entries
+    // failing access(R_OK) so the sender never reports them.
+    let Some(names) = file_list_with_unreadable_file(true) else {
+        return;
+    };
+    assert!(
+        names.iter().any(|n| n.ends_with("readable.txt")),
+        "{names:?}"
+    );
+    assert!(
+        !names.iter().any(|n| n.ends_with("secret.txt")),
+        "{names:?}"
+    );
+}
+
+#[cfg(unix)]
+#[test]
+fn build_file_list_without_ignore_nonreadable_keeps_unreadable_file() {
+    // Without the directive the entry stays in the list and the open error
+    // surfaces later when the sender tries to read it.
+    let Some(names) = file_list_with_unreadable_file(false) else {
+        return;
+    };
+    assert!(names.iter().any(|n| n.ends_with("secret.txt")), "{names:?}");
+}
+
 /// Tests for legacy goodbye handshake (protocol 28/29).
 ///
 /// Protocol 28/29 uses a simpler goodbye sequence: the receiver sends
@@ -3886,6 +3955,56 @@ mod files_from {
         );
     }
 
+    #[test]
+    fn dirs_without_recursion_lists_one_level_of_trailing_slash_source() {
+        // upstream: flist.c:2477 - `-d payload/` sends the immediate children;
+        // `sub` is listed so the receiver creates it, `sub/inner.txt` is not.
+        let temp_dir = TempDir::new().unwrap();
+        let src_dir = temp_dir.path().join("payload");
+        std::fs::create_dir_all(src_dir.join("sub")).unwrap();
+        std::fs::write(src_dir.join("top.txt"), b"x").unwrap();
+        std::fs::write(src_dir.join("sub").join("inner.txt"), b"y").unwrap();
+
+        let handshake = test_handshake();
+        let mut config = test_config();
+        config.flags.relative = false;
+        config.flags.recursive = false;
+        config.flags.dirs = true;
+        let mut ctx = GeneratorContext::new_for_test(&handshake, config);
+        let mut with_slash = src_dir.as_os_str().to_owned();
+        with_slash.push("/");
+        ctx.build_file_list(&[std::path::PathBuf::from(with_slash)])
+            .unwrap();
+
+        let names: Vec<&str> = ctx.file_list().iter().map(|e| e.name()).collect();
+        assert!(names.contains(&"."), "expected '.' entry in {names:?}");
+        assert!(
+            names.contains(&"top.txt"),
+            "expected 'top.txt' in {names:?}"
+        );
+        assert!(names.contains(&"sub"), "expected 'sub' in {names:?}");
+        assert_eq!(names.len(), 3, "sub must not be entered: {names:?}");
+    }
+
+    #[test]
+    fn dirs_without_recursion_lists_only_named_directory() {
+        let temp_dir = TempDir::new().unwrap();
+        let src_dir = temp_dir.path().join("payload");
+        std::fs::create_dir_all(&src_dir).unwrap();
+        std::fs::write(src_dir.join("top.txt"), b"x").unwrap();
+
+        let handshake = test_handshake();
+        let mut config = test_config();
+        config.flags.relative = false;
+        config.flags.recursive = false;
+        config.flags.dirs = true;
+        let mut ctx = GeneratorContext::new_for_test(&handshake, config);
+        ctx.build_file_list(&[src_dir]).unwrap();
+
+        let names: Vec<&str> = ctx.file_list().iter().map(|e| e.name()).collect();
+        assert_eq!(names, ["payload"]);
+    }
+
     #[test]
     fn build_file_list_with_base_skips_missing_files() {
         // FFV-4: default mode emits link_stat error and sets IOERR_GENERAL (exit 23).
@@ -5437,3 +5556,61 @@ fn remove_source_files_mid_commit_teardown_retains_unconfirmed_sources() {
         "the two unconfirmed removals stay pending after the drop"
     );
 }
+
+/// Builds a recursive file list over `sources` and returns the entry names in
+/// list order.
+fn file_list_names(sources: &[PathBuf], qsort: bool) -> Vec<String> {
+    let handshake = test_handshake();
+    let mut config = test_config();
+    config.flags.recursive = true;
+    config.qsort = qsort;
+    config.args = sources.iter().map(OsString::from).collect();
+    let mut ctx = GeneratorContext::new_for_test(&handshake, config);
+    ctx.build_file_list(sources).unwrap();
+    ctx.file_list()
+        .iter()
+        .map(|e| e.name().to_owned())
+        .collect()
+}
+
+#[test]
+fn file_list_order_is_reproducible() {
+    // Names chosen so byte order differs from creation order and from the
+    // hashed readdir order of common filesystems.
+    let temp = create_test_structure(&[
+        "tree/zeta.txt",
+        "tree/Alpha.txt",
+        "tree/beta/",
+        "tree/beta/2.dat",
+        "tree/beta/10.dat",
+        "tree/_under",
+        "tree/a.b",
+        "tree/a-b",
+    ]);
+    let sources = vec![temp.path().join("tree")];
+
+    for qsort in [false, true] {
+        let first = file_list_names(&sources, qsort);
+        let second = file_list_names(&sources, qsort);
+        assert_eq!(first, second, "qsort={qsort}: two builds must match");
+        assert_eq!(first.len(), 9, "qsort={qsort}: unexpected list {first:?}");
+    }
+}
+
+#[test]
+fn qsort_places_duplicate_names_reproducibly() {
+    // The same relative name from two sources compares equal under
+    // f_name_cmp, so its placement depends on walk order.
+    let temp = create_test_structure(&["one/dup.txt", "one/x.txt", "two/dup.txt", "two/y.txt"]);
+    let mut sources = Vec::new();
+    for dir in ["one", "two"] {
+        let mut with_slash = temp.path().join(dir).into_os_string();
+        with_slash.push("/");
+        sources.push(PathBuf::from(with_slash));
+    }
+
+    let first = file_list_names(&sources, true);
+    for _ in 0..4 {
+        assert_eq!(file_list_names(&sources, true), first);
+    }
+}
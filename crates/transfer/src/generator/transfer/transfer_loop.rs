@@ -18,8 +18,9 @@ use protocol::codec::{
 use protocol::stats::DeleteStats;
 
 use super::super::delta::{
-    create_token_encoder, script_to_wire_delta, stream_append_transfer, stream_whole_file_transfer,
-    whole_stream_compression_level, write_delta_with_inline_checksum,
+    create_token_encoder, script_to_wire_delta, sendfile_whole_file_transfer,
+    stream_append_transfer, stream_whole_file_transfer, whole_stream_compression_level,
+    write_delta_with_inline_checksum,
 };
 use super::super::item_flags::ItemFlags;
 use super::super::protocol_io::NdxAttrs;
@@ -771,6 +772,51 @@ impl GeneratorContext {
                     cw.bytes_written()
                 };
                 bytes_sent += wire_bytes;
+            } else if let Some(socket) =
+                self.zero_copy_socket(writer, use_compression, file_size, &source_path)
+            {
+                // upstream: sender.c:385-400 - whole-file path; MSG_NO_SEND on open failure.
+                // Uncompressed literals go from the page cache to the socket with
+                // sendfile(2); the wire bytes are those of the buffered branch below.
+                let source = match super::super::open_source::open_source_with_noatime(
+                    &source_path,
+                    self.config.write.open_noatime,
+                ) {
+                    Ok(file) => file,
+                    Err(e) => {
+                        self.record_open_failure(&mut *writer, wire_ndx, &e, &source_path_display)?;
+                        continue;
+                    }
+                };
+
+                self.write_ndx_and_attrs(
+                    &mut *writer,
+                    &mut ndx_write_codec,
+                    &NdxAttrs {
+                        ndx: wire_ndx,
+                        iflags: &iflags,
+                        fnamecmp_type,
+                        xname: xname.as_deref(),
+                    },
+                    &sum_head,
+                    pending_xattr_response.as_mut(),
+                )?;
+
+                let checksum_algorithm = self.get_checksum_algorithm();
+                let (result, token_bytes) = sendfile_whole_file_transfer(
+                    &mut *writer,
+                    &socket,
+                    &source,
+                    file_size,
+                    checksum_algorithm,
+                    self.checksum_seed,
+                    self.protocol,
+                )?;
+                writer.write_all(&result.checksum_buf[..result.checksum_len])?;
+                // upstream: io.c:859 - stats.total_written counts the same token
+                // and checksum bytes the CountingWriter sees on the buffered branch.
+                bytes_sent += token_bytes + result.checksum_len as u64;
+                literal_data += file_size;
             } else {
                 // upstream: sender.c:385-400 - whole-file path; MSG_NO_SEND on open failure
                 // Use unbuffered reader: stream_whole_file_transfer manages its
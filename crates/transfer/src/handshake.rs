@@ -203,21 +203,28 @@ pub fn perform_handshake_with_max(
 fn read_client_version(stdin: &mut dyn Read) -> io::Result<ProtocolVersion> {
     let mut buf = [0u8; 4];
     stdin.read_exact(&mut buf)?;
+    // upstream: io.c read_int() - the version is a full little-endian int,
+    // so stray high bytes make the value out of range rather than being
+    // silently dropped.
+    let advertised = u32::from_le_bytes(buf);
 
-    let version_byte = buf[0];
     // upstream: compat.c:619-623 setup_protocol - an out-of-range remote
     // protocol version is a protocol incompatibility (RERR_PROTOCOL, exit 2),
     // distinct from a truncated stream (RERR_STREAMIO, exit 12). The
     // read_exact above keeps its stream-error mapping; only the version-value
-    // checks are tagged as protocol violations.
-    if version_byte == 0 {
-        return Err(protocol::protocol_violation(
-            "received invalid protocol version 0",
-        ));
-    }
+    // checks are tagged as protocol violations. Versions above ours but
+    // within MAX_PROTOCOL_VERSION are accepted and clamped to our newest.
+    ProtocolVersion::from_peer_advertisement(advertised).map_err(unsupported_version_error)
+}
 
-    ProtocolVersion::try_from(version_byte)
-        .map_err(|e| protocol::protocol_violation(format!("invalid protocol version: {e}")))
+/// Builds the protocol violation for a peer version outside the supported
+/// range, naming the version and the supported span before upstream's
+/// verbatim mismatch lines.
+fn unsupported_version_error(error: protocol::NegotiationError) -> io::Error {
+    match error.supported_range_hint() {
+        Some(hint) => protocol::protocol_violation(format!("{hint}: {error}")),
+        None => protocol::protocol_violation(error.to_string()),
+    }
 }
 
 /// Writes the server's protocol version advertisement.
@@ -266,7 +273,7 @@ pub fn perform_legacy_handshake(
             )
         })?;
 
-    let version_number: u8 = version_str
+    let version_number: u32 = version_str
         .split('.')
         .next()
         .unwrap_or("0")
@@ -280,8 +287,8 @@ pub fn perform_legacy_handshake(
 
     // upstream: compat.c:619-623 setup_protocol - an out-of-range peer protocol
     // version is RERR_PROTOCOL (exit 2), not RERR_STREAMIO (12).
-    let client_version = ProtocolVersion::try_from(version_number)
-        .map_err(|e| protocol::protocol_violation(format!("unsupported protocol version: {e}")))?;
+    let client_version = ProtocolVersion::from_peer_advertisement(version_number)
+        .map_err(unsupported_version_error)?;
 
     let negotiated = select_highest_mutual([client_version]).map_err(|e| {
         protocol::protocol_violation(format!(
@@ -384,6 +391,17 @@ mod tests {
         assert!(result.is_err());
     }
 
+    #[test]
+    fn binary_handshake_clamps_future_version_within_upstream_window() {
+        // upstream: compat.c:606-607 - a peer above our version but within
+        // MAX_PROTOCOL_VERSION negotiates down to ours.
+        let mut stdin = Cursor::new(vec![35, 0, 0, 0]);
+        let mut stdout = Vec::new();
+
+        let result = perform_handshake(&mut stdin, &mut stdout).expect("handshake succeeds");
+        assert_eq!(result.protocol, ProtocolVersion::NEWEST);
+    }
+
     #[test]
     fn binary_handshake_rejects_version_zero() {
         let mut stdin = Cursor::new(vec![0, 0, 0, 0]);
@@ -540,6 +558,29 @@ mod tests {
         assert_maps_to_rerr_protocol(&error);
     }
 
+    // WHY: an out-of-range peer must get an error naming its version and our
+    // supported span, not just upstream's generic mismatch lines, and the
+    // handshake must stop after our own advertisement instead of reading on.
+    #[test]
+    fn binary_handshake_out_of_range_version_names_supported_range() {
+        // 32 with a stray high byte is 288 as a little-endian int.
+        let mut stdin = Cursor::new(vec![32, 1, 0, 0, 0xff, 0xff]);
+        let mut stdout = Vec::new();
+
+        let error = perform_handshake(&mut stdin, &mut stdout)
+            .expect_err("version 288 is outside the supported range");
+        let message = error.to_string();
+        assert!(
+            message.contains("peer advertised protocol version 288"),
+            "{message}"
+        );
+        assert!(message.contains("28 through 32"), "{message}");
+        assert!(message.contains("protocol version mismatch"), "{message}");
+        assert_maps_to_rerr_protocol(&error);
+        assert_eq!(stdout, [ProtocolVersion::NEWEST.as_u8(), 0, 0, 0]);
+        assert_eq!(stdin.position(), 4, "nothing past the version is consumed");
+    }
+
     // WHY: a zero version byte is an invalid protocol version, not a stream
     // error; upstream treats an out-of-range remote_protocol as RERR_PROTOCOL.
     #[test]
@@ -120,9 +120,11 @@ pub(crate) fn is_early_close_error(e: &io::Error) -> bool {
     generator::is_early_close_error(e)
 }
 
+mod checksum_xattr;
 mod compressed_reader;
 mod compressed_writer;
 pub mod config;
+mod copy_as;
 pub mod delta_apply;
 pub mod delta_config;
 pub mod delta_transfer;
@@ -132,6 +134,8 @@ pub mod generator;
 pub mod handshake;
 mod reader;
 pub mod receiver;
+pub mod receiver_fs;
+pub mod resume_skip;
 pub mod role;
 pub(crate) mod role_trailer;
 pub mod sanitize_path;
@@ -178,24 +182,29 @@ pub use self::handshake::{
 };
 pub use self::reader::RemoteExitError;
 pub use self::receiver::{ListOnlyEntry, ReceiverContext, SumHead, TransferStats};
+pub use self::receiver_fs::{OsReceiverFs, ReceiverFs, ReceiverFsOp, RecordingReceiverFs};
+pub use self::resume_skip::{ResumeSkipList, resume_file_digest};
 pub use self::role::ServerRole;
 pub use self::shared::{ChecksumFactory, TransferDeadline};
 pub use self::temp_cleanup::cleanup_stale_temp_files;
-pub use self::writer::{CountingWriter, MsgInfoSender, ServerWriter, shutdown_send_side};
+pub use self::writer::{
+    CountingWriter, MsgInfoSender, SendfileSocket, ServerWriter, shutdown_send_side,
+};
 pub use delta_pipeline::{
     DEFAULT_PARALLEL_THRESHOLD, ParallelDeltaPipeline, ReceiverDeltaPipeline,
     SequentialDeltaPipeline, ThresholdDeltaPipeline,
 };
 pub use parallel_io::{
-    DEFAULT_DELETION_THRESHOLD, DEFAULT_METADATA_THRESHOLD, DEFAULT_SIGNATURE_THRESHOLD,
-    DEFAULT_STAT_THRESHOLD, ParallelOp, ParallelThresholds,
+    DEFAULT_CHECKSUM_THRESHOLD, DEFAULT_DELETION_THRESHOLD, DEFAULT_METADATA_THRESHOLD,
+    DEFAULT_SIGNATURE_THRESHOLD, DEFAULT_STAT_THRESHOLD, ParallelOp, ParallelThresholds,
 };
 pub use pipeline::{
     DEFAULT_PIPELINE_WINDOW, MAX_PIPELINE_WINDOW, MIN_PIPELINE_WINDOW, PendingTransfer,
     PipelineConfig, PipelineState,
 };
 pub use progress::{
-    ItemizeCallback, ItemizeRow, OwnedItemizeRow, TransferProgressCallback, TransferProgressEvent,
+    FileListCallback, ItemizeCallback, ItemizeRow, OwnedItemizeRow, TransferProgressCallback,
+    TransferProgressEvent,
 };
 pub use transfer_state::{InvalidTransition, TransferPhase, TransferPipeline};
 
@@ -500,6 +509,8 @@ pub fn run_server_with_handshake<W: Write>(
             batch,
             itemize,
             io_timeout_reapply: None,
+            sendfile_socket: None,
+            file_list: None,
         },
     )
 }
@@ -508,7 +519,8 @@ pub fn run_server_with_handshake<W: Write>(
 ///
 /// Groups the per-transfer callbacks and hooks so the entry point stays within
 /// a reasonable argument count: live progress, batch recording, the push
-/// itemize callback, and the client-receiver I/O-timeout re-apply hook.
+/// itemize callback, the client-receiver I/O-timeout re-apply hook, the
+/// sender's zero-copy destination socket, and the receiver's file-list sink.
 #[derive(Default)]
 pub struct ServerTransferHooks<'p, 'i> {
     /// Live per-file progress callback.
@@ -521,6 +533,13 @@ pub struct ServerTransferHooks<'p, 'i> {
     /// `Some` only on the daemon-pull (client receiver) path.
     /// upstream: io.c:1551-1561 `read_a_msg()` case `MSG_IO_TIMEOUT`.
     pub io_timeout_reapply: Option<IoTimeoutReapply>,
+    /// Destination socket for zero-copy whole-file literals. Consulted only
+    /// when the local side is the generator; `None` keeps every literal on the
+    /// buffered write path.
+    pub sendfile_socket: Option<SendfileSocket>,
+    /// Receives each file-list entry as its segment is decoded. Consulted
+    /// only when the local side is the receiver.
+    pub file_list: Option<Box<dyn FileListCallback>>,
 }
 
 /// Runs a server transfer that may adopt a daemon-advertised `MSG_IO_TIMEOUT`.
@@ -546,6 +565,8 @@ pub fn run_server_with_handshake_adopting<W: Write>(
         batch,
         itemize,
         io_timeout_reapply,
+        sendfile_socket,
+        file_list,
     } = hooks;
     // FSM: begin at Handshake (version exchange is already complete when this
     // function is called - either via run_server_stdio or daemon greeting).
@@ -768,6 +789,13 @@ pub fn run_server_with_handshake_adopting<W: Write>(
         });
     }
 
+    // upstream: log.c:rwrite() - under `--stderr=all` (`msgs2stderr == 1`) the
+    // client writes every message it receives from the peer, info included, to
+    // stderr. The other modes keep the peer's info on stdout.
+    if config.connection.client_mode && config.flags.msgs_to_stderr {
+        reader.route_info_to_stderr();
+    }
+
     // MultiplexWriter provides 64KB buffering (matching upstream iobuf_out).
     let mut writer = writer::ServerWriter::new_plain(stdout);
 
@@ -890,7 +918,11 @@ pub fn run_server_with_handshake_adopting<W: Write>(
 
     match config.role {
         ServerRole::Receiver => {
+            // upstream: main.c:do_recv() - become_copy_as_user() before the
+            // receiver touches the destination.
+            let _copy_as = copy_as::enter_receiver(config.copy_as.as_ref())?;
             let mut ctx = ReceiverContext::new(&handshake, config, pipeline);
+            ctx.set_file_list_callback(file_list);
             // upstream: io.c:859 - stats.total_written tracking
             let mut counting_writer = writer::CountingWriter::new(&mut writer);
             let mut stats = ctx.run(chained_reader, &mut counting_writer, progress)?;
@@ -919,7 +951,8 @@ pub fn run_server_with_handshake_adopting<W: Write>(
             let mut paths = Vec::with_capacity(config.args.len());
             paths.extend(config.args.iter().map(std::path::PathBuf::from));
 
-            let mut ctx = GeneratorContext::new(&handshake, config, pipeline);
+            let mut ctx = GeneratorContext::new(&handshake, config, pipeline)
+                .with_sendfile_socket(sendfile_socket);
             let stats = ctx.run(chained_reader, &mut writer, &paths, progress, itemize)?;
 
             Ok(ServerStats::Generator(stats))
@@ -32,6 +32,12 @@ pub const DEFAULT_METADATA_THRESHOLD: usize = 64;
 /// per-item cost is higher than a single stat call.
 pub const DEFAULT_DELETION_THRESHOLD: usize = 64;
 
+/// Default threshold for parallel `--checksum` file-list sums.
+///
+/// Each item reads and hashes a whole file, so the per-item cost dwarfs
+/// dispatch overhead even for small lists.
+pub const DEFAULT_CHECKSUM_THRESHOLD: usize = 16;
+
 /// Per-operation cost classification driving threshold selection.
 ///
 /// Each variant gates one rayon dual-path call site. The variant identity
@@ -60,6 +66,10 @@ pub enum ParallelOp {
     /// Medium (one `read_dir` plus per-entry stat). Default crossover:
     /// [`DEFAULT_DELETION_THRESHOLD`].
     Deletion,
+    /// Whole-file strong checksums for `--checksum` file-list entries.
+    /// Expensive (a full read plus hash per file). Default crossover:
+    /// [`DEFAULT_CHECKSUM_THRESHOLD`].
+    Checksum,
 }
 
 /// Per-operation thresholds for switching between sequential and parallel execution.
@@ -85,6 +95,8 @@ pub struct ParallelThresholds {
     pub metadata: usize,
     /// Minimum directory count for parallel deletion scanning.
     pub deletion: usize,
+    /// Minimum file count for parallel `--checksum` file-list sums.
+    pub checksum: usize,
 }
 
 impl Default for ParallelThresholds {
@@ -94,6 +106,7 @@ impl Default for ParallelThresholds {
             signature: DEFAULT_SIGNATURE_THRESHOLD,
             metadata: DEFAULT_METADATA_THRESHOLD,
             deletion: DEFAULT_DELETION_THRESHOLD,
+            checksum: DEFAULT_CHECKSUM_THRESHOLD,
         }
     }
 }
@@ -112,6 +125,7 @@ impl ParallelThresholds {
             ParallelOp::Signature => self.signature,
             ParallelOp::Metadata => self.metadata,
             ParallelOp::Deletion => self.deletion,
+            ParallelOp::Checksum => self.checksum,
         }
     }
 
@@ -127,6 +141,7 @@ impl ParallelThresholds {
             ParallelOp::Signature => self.signature = threshold,
             ParallelOp::Metadata => self.metadata = threshold,
             ParallelOp::Deletion => self.deletion = threshold,
+            ParallelOp::Checksum => self.checksum = threshold,
         }
         self
     }
@@ -154,6 +169,12 @@ impl ParallelThresholds {
     pub const fn with_deletion(self, threshold: usize) -> Self {
         self.with_op(ParallelOp::Deletion, threshold)
     }
+
+    /// Sets the `--checksum` file-list sum threshold.
+    #[must_use]
+    pub const fn with_checksum(self, threshold: usize) -> Self {
+        self.with_op(ParallelOp::Checksum, threshold)
+    }
 }
 
 /// Runs `f` on each item in parallel using rayon's work-stealing pool.
@@ -233,6 +254,7 @@ mod tests {
         assert_eq!(t.signature, DEFAULT_SIGNATURE_THRESHOLD);
         assert_eq!(t.metadata, DEFAULT_METADATA_THRESHOLD);
         assert_eq!(t.deletion, DEFAULT_DELETION_THRESHOLD);
+        assert_eq!(t.checksum, DEFAULT_CHECKSUM_THRESHOLD);
     }
 
     #[test]
@@ -242,6 +264,7 @@ mod tests {
         assert_eq!(t.signature, 32);
         assert_eq!(t.metadata, 64);
         assert_eq!(t.deletion, 64);
+        assert_eq!(t.checksum, 16);
     }
 
     #[test]
@@ -281,6 +304,7 @@ mod tests {
         assert_eq!(t.for_op(ParallelOp::Signature), DEFAULT_SIGNATURE_THRESHOLD);
         assert_eq!(t.for_op(ParallelOp::Metadata), DEFAULT_METADATA_THRESHOLD);
         assert_eq!(t.for_op(ParallelOp::Deletion), DEFAULT_DELETION_THRESHOLD);
+        assert_eq!(t.for_op(ParallelOp::Checksum), DEFAULT_CHECKSUM_THRESHOLD);
     }
 
     #[test]
@@ -330,12 +354,14 @@ mod tests {
             .with_op(ParallelOp::Stat, 11)
             .with_op(ParallelOp::Signature, 12)
             .with_op(ParallelOp::Metadata, 13)
-            .with_op(ParallelOp::Deletion, 14);
+            .with_op(ParallelOp::Deletion, 14)
+            .with_op(ParallelOp::Checksum, 15);
         let via_field = ParallelThresholds::default()
             .with_stat(11)
             .with_signature(12)
             .with_metadata(13)
-            .with_deletion(14);
+            .with_deletion(14)
+            .with_checksum(15);
         assert_eq!(via_op, via_field);
     }
 
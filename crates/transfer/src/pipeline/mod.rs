@@ -34,7 +34,7 @@
 //!    requests. This simplifies synchronization.
 //!
 //! 2. **Bounded pipeline window**: Limits memory usage and prevents overwhelming
-//!    the sender. Configurable via `--pipeline-window` CLI option.
+//!    the sender. Configurable via [`PipelineConfig::with_window_size`].
 //!
 //! 3. **Signature generation during wait**: While waiting for responses, we can
 //!    generate signatures for upcoming files, utilizing otherwise idle CPU time.
@@ -113,6 +113,11 @@ impl PipelineConfig {
 
 #[cfg(test)]
 mod tests {
+    use std::path::PathBuf;
+    use std::sync::mpsc;
+    use std::thread;
+    use std::time::{Duration, Instant};
+
     use super::*;
 
     #[test]
@@ -150,4 +155,71 @@ mod tests {
         let config = PipelineConfig::default().with_window_size(100);
         assert_eq!(config.window_size, 100);
     }
+
+    /// Drives `files` requests through a link whose one-way delay is
+    /// `one_way`, using the receiver loop's shape: fill the window, then
+    /// retire one in-order response before refilling. Returns elapsed time.
+    fn run_over_simulated_link(config: PipelineConfig, files: i32, one_way: Duration) -> Duration {
+        let (req_tx, req_rx) = mpsc::channel::<(i32, Instant)>();
+        let (resp_tx, resp_rx) = mpsc::channel::<(i32, Instant)>();
+
+        // Simulated sender: answers each request once it has crossed the
+        // link, and stamps when the response will reach the receiver.
+        let sender = thread::spawn(move || {
+            for (ndx, sent_at) in req_rx {
+                thread::sleep((sent_at + one_way).saturating_duration_since(Instant::now()));
+                if resp_tx.send((ndx, Instant::now() + one_way)).is_err() {
+                    break;
+                }
+            }
+        });
+
+        let start = Instant::now();
+        let mut state = PipelineState::new(config);
+        let mut next = 0;
+        while next < files || !state.is_empty() {
+            while next < files && state.can_send() {
+                state.push(PendingTransfer::new_full_transfer(
+                    next,
+                    PathBuf::from(format!("file{next}")),
+                    0,
+                ));
+                req_tx.send((next, Instant::now())).unwrap();
+                next += 1;
+            }
+            let (ndx, arrives_at) = resp_rx.recv().unwrap();
+            thread::sleep(arrives_at.saturating_duration_since(Instant::now()));
+            assert_eq!(
+                state.pop().map(|t| t.ndx()),
+                Some(ndx),
+                "in-order responses"
+            );
+        }
+        let elapsed = start.elapsed();
+
+        drop(req_tx);
+        sender.join().unwrap();
+        elapsed
+    }
+
+    #[test]
+    fn pipelined_window_hides_link_latency() {
+        const FILES: i32 = 32;
+        const ONE_WAY: Duration = Duration::from_millis(5);
+
+        let serial = run_over_simulated_link(PipelineConfig::synchronous(), FILES, ONE_WAY);
+        let pipelined = run_over_simulated_link(
+            PipelineConfig::default().with_window_size(16),
+            FILES,
+            ONE_WAY,
+        );
+
+        // Serial pays one round trip per file (>= 320ms here); a 16-deep
+        // window pays roughly one per window plus scheduling noise.
+        assert!(serial >= ONE_WAY * 2 * FILES as u32);
+        assert!(
+            pipelined * 3 < serial,
+            "pipelined {pipelined:?} should be well under serial {serial:?}"
+        );
+    }
 }
@@ -7,6 +7,8 @@
 
 use std::path::Path;
 
+use crate::receiver::ListOnlyEntry;
+
 /// Progress event emitted when a file transfer completes.
 ///
 /// Reports per-file completion along with aggregate counters that enable
@@ -80,6 +82,38 @@ impl<F: FnMut(&str)> ItemizeCallback for F {
     }
 }
 
+/// Callback trait for file-list entries as the receiver decodes them.
+///
+/// The receiver calls it once per entry as soon as the segment holding the
+/// entry has been read, sorted and validated: the initial list first, then
+/// each INC_RECURSE sub-list as it arrives. Tooling that walks a remote tree
+/// can therefore consume entries while the sender is still scanning, instead
+/// of waiting for the transfer to finish and reading
+/// [`TransferStats::list_only_entries`](crate::TransferStats::list_only_entries).
+///
+/// Owned rather than borrowed because the receiver keeps it for the whole
+/// transfer; `Send` so a caller may hand it a channel sender.
+///
+/// # Upstream Reference
+///
+/// - `flist.c:recv_file_list()` - one call per received (sub-)list
+pub trait FileListCallback: Send {
+    /// Called with one received file-list entry.
+    fn on_file_list_entry(&mut self, entry: &ListOnlyEntry);
+}
+
+impl<F: FnMut(&ListOnlyEntry) + Send> FileListCallback for F {
+    fn on_file_list_entry(&mut self, entry: &ListOnlyEntry) {
+        self(entry);
+    }
+}
+
+impl std::fmt::Debug for dyn FileListCallback {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.write_str("FileListCallback")
+    }
+}
+
 /// Structured per-file data for one client-visible itemize/name emission.
 ///
 /// Carries both the pre-formatted default line (`%i %n%L` or `%n%L`) and the raw
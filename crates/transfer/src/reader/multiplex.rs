@@ -176,6 +176,11 @@ pub(crate) struct MultiplexReader<R> {
     /// every other reader leaves it `None` so the frame is dropped like upstream
     /// drops it on `am_server`. upstream: log.c:870-874.
     deleted_render: Option<DeletedRender>,
+    /// Routes the peer's info-class messages (`MSG_INFO`, `MSG_CLIENT`, and
+    /// rendered `MSG_DELETED` lines) to stderr instead of stdout. Set on the
+    /// client for `--msgs2stderr` / `--stderr=all`. upstream: log.c:rwrite()
+    /// picks stderr for FINFO/FCLIENT when `msgs2stderr == 1`.
+    info_to_stderr: bool,
 }
 
 /// Exit code for partial transfer due to error.
@@ -256,6 +261,24 @@ impl<R> MultiplexReader<R> {
             io_timeout_reapply: None,
             io_timeout_invalid: false,
             deleted_render: None,
+            info_to_stderr: false,
+        }
+    }
+
+    /// Sends the peer's info-class messages to stderr along with its errors.
+    ///
+    /// upstream: log.c:rwrite() - with `msgs2stderr == 1` (`--stderr=all`)
+    /// FINFO and FCLIENT go to stderr; otherwise they go to stdout.
+    pub(super) fn set_info_to_stderr(&mut self, enabled: bool) {
+        self.info_to_stderr = enabled;
+    }
+
+    /// Emits an info-class message on the stream `--stderr` selected.
+    fn emit_info<S: MuxSink>(&self, sink: &mut S, msg: &str) {
+        if self.info_to_stderr {
+            sink.error(msg);
+        } else {
+            sink.info(msg);
         }
     }
 
@@ -542,6 +565,23 @@ impl<R> MultiplexReader<R> {
         Ok(())
     }
 
+    /// Reads the next frame into `buffer` and checks its payload length.
+    ///
+    /// A control message whose payload is not the size its code carries means
+    /// the stream lost frame alignment; reading on would treat payload bytes as
+    /// headers and could block on a length that was never sent. Both that and
+    /// an undecodable header surface as a [`protocol::ProtocolDesync`] error,
+    /// which maps to exit code 12.
+    ///
+    /// upstream: io.c:read_a_msg() - `invalid_msg` reports the code and length
+    /// and exits `RERR_STREAMIO`.
+    fn recv_frame(&mut self) -> io::Result<protocol::MessageCode> {
+        let code = protocol::recv_msg_into(&mut self.inner, &mut self.buffer)?;
+        code.validate_payload_len(self.buffer.len())
+            .map_err(|err| protocol::protocol_desync(err.to_string()))?;
+        Ok(code)
+    }
+
     /// Dispatches a non-data message code using the production [`RealSink`].
     ///
     /// Byte-identical to the previous inline dispatch: routes `MSG_INFO`/
@@ -574,9 +614,10 @@ impl<R> MultiplexReader<R> {
         match code {
             protocol::MessageCode::Data => return true,
             protocol::MessageCode::Info | protocol::MessageCode::Client => {
-                // upstream: log.c:rwrite() - FINFO and FCLIENT go to stdout
+                // upstream: log.c:rwrite() - FINFO and FCLIENT go to stdout,
+                // or to stderr under `--stderr=all`.
                 if let Ok(msg) = std::str::from_utf8(&self.buffer) {
-                    sink.info(msg);
+                    self.emit_info(sink, msg);
                 }
             }
             protocol::MessageCode::Warning | protocol::MessageCode::Log => {
@@ -650,7 +691,7 @@ impl<R> MultiplexReader<R> {
                 // matching upstream's non-rendering `am_server` path.
                 if let Some(render) = self.deleted_render {
                     if let Some(line) = render.format(&self.buffer) {
-                        sink.info(&line);
+                        self.emit_info(sink, &line);
                     }
                 }
             }
@@ -689,7 +730,7 @@ impl<R: Read> MultiplexReader<R> {
                 self.buffer.clear();
                 self.pos = 0;
 
-                let code = protocol::recv_msg_into(&mut self.inner, &mut self.buffer)?;
+                let code = self.recv_frame()?;
 
                 if self.dispatch_message(code) {
                     break;
@@ -781,7 +822,7 @@ impl<R: Read> Read for MultiplexReader<R> {
             self.buffer.clear();
             self.pos = 0;
 
-            let code = protocol::recv_msg_into(&mut self.inner, &mut self.buffer)?;
+            let code = self.recv_frame()?;
 
             if self.dispatch_message(code) {
                 // upstream: io.c io_start_multiplex_out() sends a length-0
@@ -923,6 +964,57 @@ mod deleted_render_tests {
 /// stricter timeout, ignore a non-stricter one, treat a bad length or the
 /// wrong role as an invalid (fatal) message, and re-apply the adopted value to
 /// the live socket.
+/// Tests for the client's `--stderr` routing of peer messages: warnings and
+/// errors always reach stderr, while info messages follow the mode - stdout
+/// for `errors` and `client`, stderr for `all`. upstream: log.c:rwrite().
+#[cfg(test)]
+mod stderr_routing_tests {
+    use super::*;
+
+    #[derive(Default)]
+    struct StreamSink {
+        stdout: Vec<String>,
+        stderr: Vec<String>,
+    }
+
+    impl MuxSink for StreamSink {
+        fn info(&mut self, msg: &str) {
+            self.stdout.push(msg.to_owned());
+        }
+        fn error(&mut self, msg: &str) {
+            self.stderr.push(msg.to_owned());
+        }
+    }
+
+    fn route(info_to_stderr: bool) -> StreamSink {
+        let mut reader = MultiplexReader::new(io::empty());
+        reader.set_info_to_stderr(info_to_stderr);
+        let mut sink = StreamSink::default();
+        for (code, payload) in [
+            (protocol::MessageCode::Warning, "remote warning\n"),
+            (protocol::MessageCode::Info, "remote info\n"),
+        ] {
+            reader.buffer = payload.as_bytes().to_vec();
+            assert!(!reader.dispatch_message_with(code, &mut sink));
+        }
+        sink
+    }
+
+    #[test]
+    fn default_and_client_modes_keep_info_on_stdout() {
+        let sink = route(false);
+        assert_eq!(sink.stderr, ["remote warning\n"]);
+        assert_eq!(sink.stdout, ["remote info\n"]);
+    }
+
+    #[test]
+    fn all_mode_sends_warning_and_info_to_stderr() {
+        let sink = route(true);
+        assert_eq!(sink.stderr, ["remote warning\n", "remote info\n"]);
+        assert!(sink.stdout.is_empty());
+    }
+}
+
 #[cfg(test)]
 mod io_timeout_adoption_tests {
     use super::*;
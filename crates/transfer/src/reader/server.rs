@@ -27,6 +27,9 @@ pub struct ServerReader<R: Read> {
     /// `MultiplexReader` on multiplex activation. `Some` only on a push where
     /// the remote receiver performs `--delete`. upstream: log.c:870-874.
     pending_deleted_render: Option<super::DeletedRender>,
+    /// Client `--stderr=all` routing, applied to the `MultiplexReader` on
+    /// multiplex activation. upstream: log.c:rwrite() `msgs2stderr == 1`.
+    pending_info_to_stderr: bool,
 }
 
 #[allow(private_interfaces)]
@@ -49,9 +52,21 @@ impl<R: Read> ServerReader<R> {
             pending_batch_recorder: None,
             pending_io_timeout_adoption: None,
             pending_deleted_render: None,
+            pending_info_to_stderr: false,
         }
     }
 
+    /// Routes the peer's info messages to stderr instead of stdout.
+    ///
+    /// Applied to the `MultiplexReader` on `activate_multiplex`. The client
+    /// calls this for `--msgs2stderr` / `--stderr=all`.
+    ///
+    /// upstream: log.c:rwrite() - FINFO/FCLIENT go to stderr when
+    /// `msgs2stderr == 1`.
+    pub(crate) fn route_info_to_stderr(&mut self) {
+        self.pending_info_to_stderr = true;
+    }
+
     /// Enables client-side rendering of received `MSG_DELETED` frames.
     ///
     /// Applied to the `MultiplexReader` on `activate_multiplex`. Called only on
@@ -125,11 +140,13 @@ impl<R: Read> ServerReader<R> {
                 if let Some(render) = self.pending_deleted_render {
                     mux.set_deleted_render(render);
                 }
+                mux.set_info_to_stderr(self.pending_info_to_stderr);
                 Ok(Self {
                     inner: ServerReaderInner::Multiplex(mux),
                     pending_batch_recorder: None,
                     pending_io_timeout_adoption: None,
                     pending_deleted_render: None,
+                    pending_info_to_stderr: false,
                 })
             }
             ServerReaderInner::Multiplex(_) => Err(io::Error::new(
@@ -169,6 +186,7 @@ impl<R: Read> ServerReader<R> {
                     pending_batch_recorder: None,
                     pending_io_timeout_adoption: None,
                     pending_deleted_render: None,
+                    pending_info_to_stderr: false,
                 })
             }
             ServerReaderInner::Plain(_) => Err(io::Error::new(
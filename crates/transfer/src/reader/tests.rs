@@ -221,7 +221,7 @@ fn multiplex_reader_accumulates_msg_io_error() {
 }
 
 #[test]
-fn multiplex_reader_io_error_wrong_payload_length_ignored() {
+fn multiplex_reader_io_error_wrong_payload_length_is_desync() {
     // upstream: io.c:1543 `if (msg_bytes != 4) goto invalid_msg;`
     let mut stream = Vec::new();
 
@@ -231,9 +231,7 @@ fn multiplex_reader_io_error_wrong_payload_length_ignored() {
 
     let mut mux = MultiplexReader::new(Cursor::new(stream));
     let mut buf = [0u8; 2];
-    let n = mux.read(&mut buf).unwrap();
-    assert_eq!(n, 2);
-    assert_eq!(&buf, b"ok");
+    assert_desync(mux.read(&mut buf).unwrap_err());
 
     assert_eq!(mux.io_error, 0);
 }
@@ -429,7 +427,7 @@ fn multiplex_reader_accumulates_msg_no_send() {
 }
 
 #[test]
-fn multiplex_reader_no_send_wrong_payload_length_ignored() {
+fn multiplex_reader_no_send_wrong_payload_length_is_desync() {
     // upstream: io.c:1640 `if (msg_bytes != 4) goto invalid_msg;`
     let mut stream = Vec::new();
 
@@ -439,9 +437,7 @@ fn multiplex_reader_no_send_wrong_payload_length_ignored() {
 
     let mut mux = MultiplexReader::new(Cursor::new(stream));
     let mut buf = [0u8; 2];
-    let n = mux.read(&mut buf).unwrap();
-    assert_eq!(n, 2);
-    assert_eq!(&buf, b"ok");
+    assert_desync(mux.read(&mut buf).unwrap_err());
 
     assert!(mux.no_send_indices.is_empty());
 }
@@ -525,7 +521,7 @@ fn multiplex_reader_accumulates_msg_redo() {
 }
 
 #[test]
-fn multiplex_reader_redo_wrong_payload_length_ignored() {
+fn multiplex_reader_redo_wrong_payload_length_is_desync() {
     // upstream: io.c:1537 reads exactly 4 bytes for val
     let mut stream = Vec::new();
 
@@ -535,13 +531,63 @@ fn multiplex_reader_redo_wrong_payload_length_ignored() {
 
     let mut mux = MultiplexReader::new(Cursor::new(stream));
     let mut buf = [0u8; 2];
-    let n = mux.read(&mut buf).unwrap();
-    assert_eq!(n, 2);
-    assert_eq!(&buf, b"ok");
+    assert_desync(mux.read(&mut buf).unwrap_err());
 
     assert!(mux.redo_indices.is_empty());
 }
 
+/// Asserts `err` is the framing-loss error: `InvalidData` carrying a
+/// [`protocol::ProtocolDesync`], which the exit-code mapper turns into 12.
+fn assert_desync(err: std::io::Error) {
+    assert_eq!(err.kind(), std::io::ErrorKind::InvalidData);
+    assert!(
+        err.get_ref()
+            .is_some_and(|inner| inner.is::<protocol::ProtocolDesync>()),
+        "expected a protocol desync error, got {err:?}"
+    );
+    assert!(err.to_string().starts_with("protocol desync: "), "{err}");
+}
+
+/// A header whose tag byte is outside the multiplex range - what the reader
+/// sees when it lands mid-payload - aborts with a desync error instead of
+/// reading the bogus length as a frame.
+#[test]
+fn multiplex_reader_corrupted_tag_is_desync() {
+    let mut stream = Vec::new();
+    protocol::send_msg(&mut stream, protocol::MessageCode::Data, b"ok").unwrap();
+    // Header `[len_lo, len_mid, len_hi, tag]` with tag 0x00 (< MPLEX_BASE)
+    // and a length the stream will never deliver.
+    stream.extend_from_slice(&[0xff, 0xff, 0xff, 0x00]);
+
+    let mut mux = MultiplexReader::new(Cursor::new(stream));
+    let mut buf = [0u8; 2];
+    assert_eq!(mux.read(&mut buf).unwrap(), 2);
+    let err = mux.read(&mut buf).unwrap_err();
+    assert!(err.to_string().contains("invalid tag byte"), "{err}");
+    assert_desync(err);
+}
+
+/// A `MSG_NOOP` that carries a payload means the frame boundary shifted; the
+/// error names the code and length like upstream's `invalid multi-message`.
+#[test]
+fn multiplex_reader_noop_with_payload_is_desync() {
+    let mut stream = Vec::new();
+    protocol::send_msg(&mut stream, protocol::MessageCode::NoOp, &[0; 5]).unwrap();
+    protocol::send_msg(&mut stream, protocol::MessageCode::Data, b"ok").unwrap();
+
+    let mut mux = MultiplexReader::new(Cursor::new(stream));
+    let mut buf = [0u8; 2];
+    let err = mux.read(&mut buf).unwrap_err();
+    assert_eq!(
+        err.to_string(),
+        format!(
+            "protocol desync: invalid multi-message {}:5",
+            protocol::MessageCode::NoOp.as_u8()
+        )
+    );
+    assert_desync(err);
+}
+
 #[test]
 fn server_reader_take_redo_indices_plain_returns_empty() {
     let mut reader = ServerReader::new_plain(Cursor::new(vec![]));
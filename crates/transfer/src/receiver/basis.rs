@@ -378,6 +378,19 @@ fn generate_basis_signature(
     xname: Option<Vec<u8>>,
     config: SignatureGenerationConfig,
 ) -> BasisFileResult {
+    // upstream: generator.c:generate_and_send_sums() - a zero-length basis gets
+    // a zeroed sum_struct instead of sum_sizes_sqroot()'s layout, so the wire
+    // sum_head is all zeros and no blocks follow. Sizing one here would
+    // advertise a block length and s2length for a file with no blocks. The
+    // basis selection itself (`fnamecmp_type`, `xname`) is still reported.
+    if basis_size == 0 {
+        return BasisFileResult {
+            fnamecmp_type,
+            xname,
+            ..BasisFileResult::EMPTY
+        };
+    }
+
     // Cap the per-file strong-sum length by the negotiated transfer checksum's
     // digest width. `sum_sizes_sqroot()` clamps s2length to
     // `max_s2length = MIN(SUM_LENGTH, xfer_sum_len)`, so it never exceeds the
@@ -1032,6 +1045,34 @@ mod tests {
         assert_eq!(via_default.basis_path.as_deref(), Some(path.as_path()));
     }
 
+    /// A zero-length basis yields no signature, so the request carries the
+    /// all-zero sum_head upstream writes instead of a sized empty layout.
+    #[test]
+    fn zero_length_basis_sends_no_sums() {
+        let tmp = tempfile::tempdir().expect("tempdir");
+        let path = tmp.path().join("empty.bin");
+        fs::File::create(&path).expect("create empty basis");
+
+        let cfg = SignatureGenerationConfig {
+            protocol: ProtocolVersion::NEWEST,
+            checksum_length: NonZeroU8::new(16).unwrap(),
+            checksum_algorithm: SignatureAlgorithm::Md4,
+            compat_flags: None,
+        };
+        let result = generate_basis_signature(
+            fast_io::open_basis_nofollow(&path).expect("open basis"),
+            0,
+            path.clone(),
+            protocol::FnameCmpType::BasisDir(0),
+            None,
+            cfg,
+        );
+
+        assert!(result.is_empty(), "an empty basis has no blocks to sign");
+        assert!(result.basis_path.is_none());
+        assert_eq!(result.fnamecmp_type, protocol::FnameCmpType::BasisDir(0));
+    }
+
     /// Issue #264 regression: when the destination is absent but an
     /// interrupted transfer left a same-named file under `--partial-dir`, the
     /// generator must select that partial file as the delta basis and tag it
@@ -22,6 +22,8 @@ use engine::delete::DeleteContext;
 
 use crate::config::ServerConfig;
 use crate::handshake::HandshakeResult;
+use crate::progress::FileListCallback;
+use crate::receiver_fs::ReceiverFs;
 use crate::shared::ChecksumFactory;
 use crate::transfer_state::TransferPipeline;
 
@@ -213,6 +215,17 @@ pub struct ReceiverContext {
     /// This is wired by task DDP-B3 (#2257) and consumed by the emitter
     /// wiring in tasks DDP-E1-E5.
     pub(in crate::receiver) delete_ctx: Option<Arc<DeleteContext>>,
+    /// Filesystem hook forwarded to the disk-commit thread.
+    ///
+    /// When `Some`, temp-file creation, the final rename, and the
+    /// permission/ownership/mtime updates on regular files are dispatched
+    /// through the hook instead of the sandbox-anchored `std::fs` paths.
+    pub(in crate::receiver) receiver_fs: Option<Arc<dyn ReceiverFs>>,
+    /// Sink handed every file-list entry as its segment is decoded.
+    ///
+    /// `None` on every path except a caller that streams the listing (the
+    /// client-side remote walk); the receive path is otherwise unchanged.
+    pub(in crate::receiver) file_list_callback: Option<Box<dyn FileListCallback>>,
     /// Deletion stats produced by the receiver's pre-transfer `--delete` sweep.
     ///
     /// Populated by `delete_extraneous_files` from both `run_pipelined` and
@@ -245,6 +258,12 @@ pub struct ReceiverContext {
     /// gate drops. `emit_itemize` reads this to decide whether to force the
     /// created-directory glyph for the root entry.
     pub(in crate::receiver) dest_root_created: bool,
+    /// Whether the destination filesystem matches names case-insensitively.
+    ///
+    /// Probed once by `setup_transfer`; while set, every received file-list
+    /// segment is checked for names that differ only in case and would land
+    /// on the same destination entry.
+    pub(in crate::receiver) case_insensitive_dest: bool,
     /// Whether the sender has finished transmitting the file list.
     ///
     /// Set to `true` once `receive_file_list` completes on a non-INC_RECURSE
@@ -423,9 +442,12 @@ impl ReceiverContext {
             flist_io_error: 0,
             parallel_thresholds: ParallelThresholds::default(),
             delete_ctx: None,
+            receiver_fs: None,
+            file_list_callback: None,
             pending_del_stats: DeleteStats::new(),
             pipeline,
             dest_root_created: false,
+            case_insensitive_dest: false,
             flist_eof: false,
             // upstream: generator.c:2304 - MIN_FILECNT_LOOKAHEAD / 2 (1000 / 2).
             hardlink_lookahead_target: 500,
@@ -494,6 +516,25 @@ impl ReceiverContext {
         self.delete_ctx.as_ref().map(Arc::clone)
     }
 
+    /// Installs a [`ReceiverFs`] hook for regular-file commits.
+    ///
+    /// The hook is cloned into every disk-commit thread the receiver spawns,
+    /// including the redo pass. Pass `None` to restore the default
+    /// `std::fs` behaviour. Must be called before [`run`](Self::run).
+    pub fn set_receiver_fs(&mut self, fs: Option<Arc<dyn ReceiverFs>>) {
+        self.receiver_fs = fs;
+    }
+
+    /// Installs a [`FileListCallback`] fed each received file-list entry.
+    ///
+    /// The initial list is reported once it is sorted and cleaned, and each
+    /// INC_RECURSE sub-list once it has been validated, so the callback sees
+    /// exactly the entries the transfer acts on. Must be called before
+    /// [`run`](Self::run).
+    pub fn set_file_list_callback(&mut self, callback: Option<Box<dyn FileListCallback>>) {
+        self.file_list_callback = callback;
+    }
+
     /// Converts a wire NDX value to a flat file list array index.
     ///
     /// Inverse of [`Self::flat_to_wire_ndx`]. Walks the segment table
@@ -634,7 +675,8 @@ impl ReceiverContext {
         .with_preserve_xattrs(self.config.flags.xattrs)
         .with_preserve_atimes(self.config.flags.atimes)
         .with_delete_missing_args(self.config.file_selection.delete_missing_args)
-        .with_relative_paths(self.config.flags.relative);
+        .with_relative_paths(self.config.flags.relative)
+        .with_limits(self.config.file_list_limits);
 
         // upstream: flist.c - always_checksum includes per-file checksums in the file list
         if self.config.flags.checksum {
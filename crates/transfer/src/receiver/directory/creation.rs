@@ -32,15 +32,16 @@ enum DirDestination {
     /// Destination already usable as a directory - reuse it. Either a real
     /// directory, or a symlink-to-directory followed under `--keep-dirlinks`.
     Existing,
-    /// A conflicting symlink was removed - create a real directory in its
-    /// place. The destination existed, so `--existing` does NOT skip it.
-    ReplacedSymlink,
+    /// A conflicting non-directory (symlink, regular file, device or special)
+    /// was removed - create a real directory in its place. The destination
+    /// existed, so `--existing` does NOT skip it.
+    ReplacedNonDirectory,
 }
 
 impl DirDestination {
     /// Whether a directory must be materialised (mkdir) for this outcome.
     const fn needs_mkdir(self) -> bool {
-        matches!(self, Self::Missing | Self::ReplacedSymlink)
+        matches!(self, Self::Missing | Self::ReplacedNonDirectory)
     }
 }
 
@@ -72,8 +73,13 @@ fn dir_needs_writable_transfer_mode(
 }
 
 impl ReceiverContext {
-    /// Classifies a directory destination, removing a conflicting symlink first
-    /// when required.
+    /// Classifies a directory destination, removing a conflicting
+    /// non-directory first when required.
+    ///
+    /// Making way for a directory never needs `--force`: only a non-directory
+    /// is ever removed here, and upstream's `delete_item()` unlinks those
+    /// unconditionally. `--force` governs the opposite conflict, a directory
+    /// standing where a file arrives.
     ///
     /// A `.exists()`-style probe would be wrong here: it follows symlinks, so a
     /// destination symlink-to-directory would always be treated as an existing
@@ -92,18 +98,33 @@ impl ReceiverContext {
                 } else {
                     // upstream: generator.c:1454 - delete_item(fname, ...,
                     // DEL_FOR_DIR) removes the conflicting symlink before mkdir.
-                    fs::remove_file(dir_path)?;
-                    Ok(DirDestination::ReplacedSymlink)
+                    self.remove_for_dir(dir_path)?;
+                    Ok(DirDestination::ReplacedNonDirectory)
                 }
             }
-            // An existing real directory (or any other existing non-symlink
-            // entry, matching the prior `.exists()` semantics) is reused.
-            Ok(_) => Ok(DirDestination::Existing),
+            Ok(existing) if existing.file_type().is_dir() => Ok(DirDestination::Existing),
+            // upstream: generator.c:1454 - any other non-directory (a regular
+            // file, device or special) is deleted the same way before mkdir.
+            Ok(_) => {
+                self.remove_for_dir(dir_path)?;
+                Ok(DirDestination::ReplacedNonDirectory)
+            }
             Err(error) if error.kind() == io::ErrorKind::NotFound => Ok(DirDestination::Missing),
             Err(error) => Err(error),
         }
     }
 
+    /// Unlinks the non-directory standing where a directory is created.
+    ///
+    /// upstream: syscall.c:do_unlink() returns success without unlinking under
+    /// `dry_run`, so `-n` still reports the replacement but leaves the file.
+    fn remove_for_dir(&self, dir_path: &Path) -> io::Result<()> {
+        if self.config.flags.skip_dest_writes() {
+            return Ok(());
+        }
+        fs::remove_file(dir_path)
+    }
+
     /// Creates directories from the file list, applying metadata in parallel.
     ///
     /// Two-phase approach: directory creation is sequential (cheap, respects
@@ -237,8 +258,8 @@ impl ReceiverContext {
             let is_new = dir_dest.needs_mkdir();
             dir_was_new.push(is_new);
             // upstream: generator.c:1401 - --existing (ignore_non_existing) only
-            // skips a genuinely absent destination (statret == -1); a symlink
-            // being replaced existed, so it is not skipped.
+            // skips a genuinely absent destination (statret == -1); a
+            // non-directory being replaced existed, so it is not skipped.
             if dir_dest == DirDestination::Missing && existing_only {
                 // upstream: generator.c:1374-1378 - "not creating new directory".
                 // Record in the skip set (not `failed_dir_paths`) so the
@@ -664,7 +685,7 @@ impl ReceiverContext {
         // failed-ancestor check above on subsequent entries.
         //
         // upstream: generator.c:1401 - --existing only skips a genuinely absent
-        // destination; a replaced symlink existed and is not skipped.
+        // destination; a replaced non-directory existed and is not skipped.
         if dir_dest == DirDestination::Missing && self.config.file_selection.existing_only {
             if self.config.flags.verbose && self.config.connection.client_mode {
                 info_log!(
@@ -690,6 +711,11 @@ impl ReceiverContext {
         } else {
             self.existing_dir_iflags(entry, &dir_path)
         };
+        // upstream: syscall.c:do_mkdir() is likewise a no-op under dry_run, and
+        // the attribute updates below never run; report the itemize row only.
+        if self.config.flags.skip_dest_writes() {
+            return Ok(Some((is_new, iflags)));
+        }
         if is_new {
             #[cfg(unix)]
             let create_result = fast_io::mkdirat_via_sandbox_or_fallback(
@@ -1494,7 +1520,7 @@ mod touch_up_dirs_tests {
             .expect("classify succeeds");
         assert_eq!(
             decision,
-            super::DirDestination::ReplacedSymlink,
+            super::DirDestination::ReplacedNonDirectory,
             "without -K the conflicting dest symlink must be replaced"
         );
         assert!(decision.needs_mkdir(), "a real directory must be created");
@@ -1562,7 +1588,7 @@ mod touch_up_dirs_tests {
             .expect("classify succeeds");
         assert_eq!(
             decision,
-            super::DirDestination::ReplacedSymlink,
+            super::DirDestination::ReplacedNonDirectory,
             "-K follows only symlinks-to-directories; a symlink-to-file is replaced"
         );
         assert!(
@@ -1570,4 +1596,28 @@ mod touch_up_dirs_tests {
             "the symlink-to-file conflict must have been removed"
         );
     }
+
+    /// A regular file standing where the source has a directory is a type
+    /// conflict: upstream deletes it via `delete_item(..., DEL_FOR_DIR)` and
+    /// creates the directory (`generator.c:1451-1455`), with no `--force`
+    /// needed because only a non-directory is removed.
+    #[test]
+    fn regular_file_in_place_of_dir_is_replaced() {
+        let dir = test_support::create_tempdir();
+        let clash = dir.path().join("d");
+        fs::write(&clash, b"file").unwrap();
+
+        let hs = handshake();
+        let ctx = ReceiverContext::new_for_test(&hs, config_with_times(false));
+
+        let decision = ctx
+            .classify_dir_destination(&clash)
+            .expect("classify succeeds");
+        assert_eq!(decision, super::DirDestination::ReplacedNonDirectory);
+        assert!(decision.needs_mkdir(), "a real directory must be created");
+        assert!(
+            fs::symlink_metadata(&clash).is_err(),
+            "the conflicting file must have been removed"
+        );
+    }
 }
@@ -444,9 +444,14 @@ impl ReceiverContext {
         let emit_itemize = self.should_emit_itemize();
         let server_mode = !self.config.connection.client_mode;
         let protocol = self.protocol.as_u8();
+        let dry_run = self.config.flags.skip_dest_writes();
         for entry in victims {
             let path = dest_dir.join(&entry.rel);
-            let result = if entry.is_dir {
+            // upstream: syscall.c:do_unlink()/do_rmdir() - a dry run reports
+            // success without touching the filesystem.
+            let result = if dry_run {
+                Ok(())
+            } else if entry.is_dir {
                 // upstream: delete.c:delete_item() -> delete_dir_contents() for a
                 // directory victim; recursive removal mirrors the immediate pass.
                 #[cfg(unix)]
@@ -847,6 +852,10 @@ impl ReceiverContext {
         let backup_dir_owned: Option<PathBuf> =
             self.config.backup_dir.as_deref().map(PathBuf::from);
         let backup_suffix: String = self.config.effective_backup_suffix().to_owned();
+        // upstream: syscall.c:do_unlink()/do_rmdir() return success without
+        // touching the filesystem under dry_run, so `-n --delete` still walks,
+        // counts and logs every victim while removing nothing.
+        let dry_run = self.config.flags.skip_dest_writes();
 
         // Collect deleted relative paths for post-parallel itemize emission.
         // The writer is not Send, so MSG_INFO frames are emitted sequentially
@@ -1070,7 +1079,8 @@ impl ReceiverContext {
                         // the victim via remember_delete() and defers the unlink;
                         // in collect_only mode the worker only records the entry so
                         // the physical removal runs later in do_delayed_deletions().
-                        let result = if collect_only {
+                        // A dry run records it the same way but keeps the entry.
+                        let result = if collect_only || dry_run {
                             Ok(())
                         } else if is_dir {
                             // SEC-1.q2 audit row #6
@@ -1289,6 +1299,7 @@ impl ReceiverContext {
             backup: self.config.flags.backup,
             backup_dir: self.config.backup_dir.as_deref().map(PathBuf::from),
             backup_suffix: self.config.effective_backup_suffix().to_owned(),
+            dry_run: self.config.flags.skip_dest_writes(),
             writer,
         };
 
@@ -1460,6 +1471,8 @@ struct CappedDeleteState<'w, W: ?Sized> {
     backup_dir: Option<PathBuf>,
     /// Effective backup suffix (`~` by default, `""` when `--backup-dir` is set).
     backup_suffix: String,
+    /// `--dry-run`: count and log each victim without removing it.
+    dry_run: bool,
     writer: &'w mut W,
 }
 
@@ -1646,6 +1659,11 @@ impl<W: crate::writer::MsgInfoSender + ?Sized> CappedDeleteState<'_, W> {
     /// A file victim is first backed up when `--backup` is set (upstream
     /// delete.c:165-174); directories are never backed up here.
     fn raw_unlink(&self, rel: &Path, path: &Path, is_dir: bool) -> io::Result<()> {
+        // upstream: syscall.c:do_unlink()/do_rmdir() - a dry run reports
+        // success without touching the filesystem.
+        if self.dry_run {
+            return Ok(());
+        }
         #[cfg(unix)]
         {
             if is_dir {
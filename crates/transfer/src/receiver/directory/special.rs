@@ -48,17 +48,21 @@ impl ReceiverContext {
     /// fresh node can be created. Fake-super substitutes a `0600` placeholder
     /// for the node, mirroring `syscall.c:do_mknod()`'s `am_root < 0` branch.
     ///
-    /// A per-entry creation failure is logged and skipped rather than aborting
-    /// the transfer, mirroring upstream's `do_mknod` failure path which records
-    /// an I/O error and continues with the next entry.
+    /// A per-entry creation failure - typically `EPERM` from `mknod(2)` when a
+    /// non-root receiver is handed a device - is reported with upstream's
+    /// `mknod "%s" failed` diagnostic and skipped rather than aborting the
+    /// transfer. The remaining entries are still created and the run finishes
+    /// with `RERR_PARTIAL` (23).
     ///
     /// # Upstream Reference
     ///
     /// - `generator.c:1627` - `if (preserve_devices && IS_DEVICE(file->mode))`
     /// - `generator.c:1675` - `atomic_create(file, fname, NULL, ...)`
+    /// - `generator.c:atomic_create()` - `rsyserr(FERROR_XFER, errno,
+    ///   "mknod %s failed", full_fname(fname))` on a failed `do_mknod()`
     #[cfg(unix)]
     pub(in crate::receiver) fn create_specials<W: crate::writer::MsgInfoSender + ?Sized>(
-        &self,
+        &mut self,
         dest_dir: &Path,
         sandbox: Option<&fast_io::DirSandbox>,
         writer: &mut W,
@@ -69,6 +73,11 @@ impl ReceiverContext {
             return Ok(());
         }
 
+        // A node that could not be created is skipped per-entry; record it here
+        // and fold it into `flist_io_error` after the loop so the immutable
+        // borrow of `self.file_list` never overlaps the mutable field write.
+        let mut creation_failed = false;
+
         for entry in &self.file_list {
             let is_device = entry.is_device();
             let is_special = entry.is_special();
@@ -209,16 +218,20 @@ impl ReceiverContext {
                     )
                 };
                 if let Err(error) = create_result {
-                    // upstream: generator.c do_mknod failure - rsyserr() then
-                    // io_error |= IOERR_GENERAL and continue with the next
-                    // entry rather than aborting the whole transfer.
-                    debug_log!(
-                        Recv,
-                        1,
-                        "failed to create special file {}: {}",
-                        node_path.display(),
-                        error
+                    // upstream: generator.c:atomic_create() - a failed
+                    // do_mknod() is an FERROR_XFER naming the node; the
+                    // generator moves on to the next entry and the run exits
+                    // RERR_PARTIAL. A non-root receiver lands here for every
+                    // device (mknod(2) needs CAP_MKNOD).
+                    let _ = self.emit_error_line(
+                        writer,
+                        &format!(
+                            "rsync: [generator] mknod \"{}\" failed: {}\n",
+                            node_path.display(),
+                            engine::local_copy::upstream_io_error(error.source_error()),
+                        ),
                     );
+                    creation_failed = true;
                     continue;
                 }
             }
@@ -264,6 +277,10 @@ impl ReceiverContext {
                 }
             }
         }
+
+        if creation_failed {
+            self.flist_io_error |= crate::generator::io_error_flags::IOERR_GENERAL;
+        }
         Ok(())
     }
 
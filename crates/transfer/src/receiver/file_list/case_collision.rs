@@ -0,0 +1,154 @@
+//! Case-insensitive destination collision detection.
+//!
+//! On a case-insensitive destination filesystem (the macOS and Windows
+//! defaults) two received names that differ only in letter case resolve to the
+//! same destination entry, so whichever the receiver writes last replaces the
+//! other without a trace. Upstream has no such check. The receiver probes the
+//! destination once during setup and, when it folds case, warns about every
+//! colliding pair so the lost entry is visible.
+//!
+//! Two colliding paths first differ (ignoring case) at a component whose
+//! parent they share, and that parent's children always arrive together - in
+//! the initial list, or in one INC_RECURSE sub-list. Checking each segment as
+//! it is received therefore finds the top-most colliding pair; entries below a
+//! colliding directory are not reported again.
+
+use std::collections::{HashMap, HashSet};
+use std::ffi::OsStr;
+use std::fs;
+use std::path::Path;
+
+use logging::info_log;
+use protocol::flist::FileEntry;
+
+use super::super::ReceiverContext;
+
+/// Case sensitivity assumed when the destination cannot be probed.
+const PLATFORM_FOLDS_CASE: bool = cfg!(any(target_os = "macos", windows));
+
+impl ReceiverContext {
+    /// Warns about received entries from index `from` onward whose names
+    /// collide on a case-insensitive destination.
+    ///
+    /// No-op unless setup found the destination to be case-insensitive.
+    /// Returns the number of colliding pairs reported.
+    pub(in crate::receiver) fn warn_case_collisions(&self, from: usize) -> usize {
+        if !self.case_insensitive_dest {
+            return 0;
+        }
+        let entries = &self.file_list[from..];
+        let collisions = find_case_collisions(entries);
+        for &(first, second) in &collisions {
+            info_log!(
+                Misc,
+                1,
+                "WARNING: \"{}\" and \"{}\" differ only in case and name the same entry on the case-insensitive destination; only one will survive {}{}",
+                entries[first].path().display(),
+                entries[second].path().display(),
+                crate::role_trailer::error_location!(),
+                crate::role_trailer::receiver()
+            );
+        }
+        collisions.len()
+    }
+}
+
+/// Returns the index pairs of active entries whose paths are equal ignoring
+/// case, pairing each later entry with the first one it collides with.
+///
+/// Entries inside a directory that is itself part of a reported pair are
+/// skipped, so one colliding directory yields one report rather than one per
+/// descendant.
+pub(in crate::receiver) fn find_case_collisions(entries: &[FileEntry]) -> Vec<(usize, usize)> {
+    let mut first_by_folded: HashMap<String, usize> = HashMap::with_capacity(entries.len());
+    let mut colliding_dirs: HashSet<String> = HashSet::new();
+    let mut collisions = Vec::new();
+
+    for (index, entry) in entries.iter().enumerate() {
+        if !entry.is_active() {
+            continue;
+        }
+        let folded = fold_case(entry.path());
+        let under_collision = Path::new(&folded)
+            .ancestors()
+            .skip(1)
+            .any(|parent| colliding_dirs.contains(parent.to_string_lossy().as_ref()));
+        if under_collision {
+            continue;
+        }
+        match first_by_folded.get(&folded) {
+            Some(&first) => {
+                // The exact same name twice is the duplicate-clean's job.
+                if entries[first].path() != entry.path() {
+                    if entry.is_dir() || entries[first].is_dir() {
+                        colliding_dirs.insert(folded);
+                    }
+                    collisions.push((first, index));
+                }
+            }
+            None => {
+                first_by_folded.insert(folded, index);
+            }
+        }
+    }
+    collisions
+}
+
+/// Reports whether the filesystem holding `dest_dir` matches names
+/// case-insensitively.
+///
+/// Stats the destination again with the case of one letter in its final
+/// component flipped: reaching the same directory means the filesystem folds
+/// case. A destination that does not exist yet (a first run, or `--dry-run`
+/// into a new directory) or whose name has no ASCII letter falls back to the
+/// platform default - case-insensitive on macOS and Windows.
+pub(in crate::receiver) fn dest_is_case_insensitive(dest_dir: &Path) -> bool {
+    let Ok(resolved) = fs::canonicalize(dest_dir) else {
+        return PLATFORM_FOLDS_CASE;
+    };
+    let Some(flipped) = resolved
+        .file_name()
+        .and_then(OsStr::to_str)
+        .and_then(flip_first_ascii_letter)
+    else {
+        return PLATFORM_FOLDS_CASE;
+    };
+    let Ok(original) = fs::metadata(&resolved) else {
+        return PLATFORM_FOLDS_CASE;
+    };
+    match fs::metadata(resolved.with_file_name(flipped)) {
+        Ok(other) => same_file(&original, &other),
+        Err(_) => false,
+    }
+}
+
+fn fold_case(path: &Path) -> String {
+    path.to_string_lossy().to_lowercase()
+}
+
+fn flip_first_ascii_letter(name: &str) -> Option<String> {
+    let (at, letter) = name.char_indices().find(|(_, c)| c.is_ascii_alphabetic())?;
+    let flipped = if letter.is_ascii_lowercase() {
+        letter.to_ascii_uppercase()
+    } else {
+        letter.to_ascii_lowercase()
+    };
+    let mut out = String::with_capacity(name.len());
+    out.push_str(&name[..at]);
+    out.push(flipped);
+    out.push_str(&name[at + 1..]);
+    Some(out)
+}
+
+#[cfg(unix)]
+fn same_file(a: &fs::Metadata, b: &fs::Metadata) -> bool {
+    use std::os::unix::fs::MetadataExt;
+    a.dev() == b.dev() && a.ino() == b.ino()
+}
+
+/// Without a stable file identity in `std`, a directory that answers to the
+/// flipped name is taken to be the same one.
+#[cfg(not(unix))]
+fn same_file(_a: &fs::Metadata, b: &fs::Metadata) -> bool {
+    b.is_dir()
+}
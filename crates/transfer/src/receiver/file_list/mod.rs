@@ -19,7 +19,10 @@
 //! - `hardlinks` - post-sort hardlink leader/follower assignment for
 //!   protocol 30+ and pre-30 normalization from (dev, ino) pairs.
 //! - `incremental` - the streaming [`IncrementalFileListReceiver`] type.
+//! - `case_collision` - warnings for received names that differ only in case
+//!   when the destination filesystem is case-insensitive.
 
+mod case_collision;
 mod filter_recheck;
 mod hardlinks;
 mod id_lists;
@@ -29,4 +32,7 @@ mod prune;
 mod receive;
 mod sanitize;
 
+pub(in crate::receiver) use case_collision::dest_is_case_insensitive;
+#[cfg(test)]
+pub(in crate::receiver) use case_collision::find_case_collisions;
 pub use incremental::IncrementalFileListReceiver;
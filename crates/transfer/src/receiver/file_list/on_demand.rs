@@ -354,6 +354,39 @@ mod tests {
         assert!(ctx.flist_eof);
     }
 
+    #[test]
+    fn file_list_callback_sees_each_segment_as_it_arrives() {
+        use std::sync::{Arc, Mutex};
+
+        let segments = vec![vec![("s0/a", 1u64)], vec![("s1/b", 2), ("s1/c", 3)]];
+        let (wire, _total) = encode_segments(&segments);
+
+        let seen: Arc<Mutex<Vec<(PathBuf, u64)>>> = Arc::default();
+        let sink = Arc::clone(&seen);
+        let mut ctx = inc_recurse_receiver();
+        ctx.dir_flist_used = segments.len();
+        ctx.set_file_list_callback(Some(Box::new(move |entry: &crate::ListOnlyEntry| {
+            sink.lock().unwrap().push((entry.path.clone(), entry.size));
+        })));
+        let mut reader = Cursor::new(wire);
+        let mut codec = create_ndx_codec(PROTOCOL);
+
+        // Only the first segment has been read, so only its entry is reported.
+        assert!(ctx.ensure_flat_idx(0, &mut reader, &mut codec).unwrap());
+        assert_eq!(*seen.lock().unwrap(), [(PathBuf::from("s0/a"), 1)]);
+
+        ctx.ensure_all_segments_loaded(&mut reader, &mut codec)
+            .unwrap();
+        assert_eq!(
+            *seen.lock().unwrap(),
+            [
+                (PathBuf::from("s0/a"), 1),
+                (PathBuf::from("s1/b"), 2),
+                (PathBuf::from("s1/c"), 3),
+            ]
+        );
+    }
+
     /// Protocol-32 INC_RECURSE receiver configured for a `-a` pull: the compat
     /// flags mirror what an upstream daemon negotiates (all known bits, so
     /// varint entry flags and inline id names are in force) and owner/group
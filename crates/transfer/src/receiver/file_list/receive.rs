@@ -14,6 +14,7 @@ use protocol::codec::{NDX_FLIST_EOF, NDX_FLIST_OFFSET, NdxCodec, create_ndx_code
 use protocol::flist::{FileEntry, IncrementalFileListBuilder, sort_and_clean_file_list};
 
 use super::super::ReceiverContext;
+use super::super::stats::ListOnlyEntry;
 use super::hardlinks::{match_hard_links, normalize_pre30_hardlinks};
 use super::incremental::IncrementalFileListReceiver;
 use super::prune::prune_empty_dirs_pass;
@@ -196,6 +197,8 @@ impl ReceiverContext {
         // across recv_file_list() calls - cache the reader to preserve that state.
         self.flist_reader_cache = Some(flist_reader);
 
+        self.report_file_list_entries(0);
+
         Ok(count)
     }
 
@@ -331,6 +334,7 @@ impl ReceiverContext {
         // before any further processing and drop the offending entries so nothing
         // escapes into the transfer.
         self.validate_extra_segment_path_belongs(dir_ndx, flat_start)?;
+        self.refuse_unsafe_segment_paths(flat_start)?;
 
         // upstream: flist.c:1646 - leader GNUM is readdir-order wire NDX,
         // assigned before sorting.
@@ -365,6 +369,11 @@ impl ReceiverContext {
         }
         match_hard_links(&mut self.file_list[flat_start..], &mut self.prior_hlinks);
 
+        // A directory's children all arrive in its own sub-list, so checking
+        // the segment alone finds any pair that collides on a case-insensitive
+        // destination.
+        self.warn_case_collisions(flat_start);
+
         // Normalize pre-30 hardlinks in this segment.
         if self.protocol.as_u8() < 30 && self.config.flags.hard_links {
             normalize_pre30_hardlinks(&mut self.file_list[flat_start..]);
@@ -393,6 +402,8 @@ impl ReceiverContext {
         // lands (tasks DDP-E1-E5).
         self.publish_segment_to_delete_pipeline(dir_ndx, flat_start);
 
+        self.report_file_list_entries(flat_start);
+
         debug_log!(
             Flist,
             2,
@@ -404,6 +415,16 @@ impl ReceiverContext {
         Ok(segment_count)
     }
 
+    /// Hands `file_list[flat_start..]` to the installed
+    /// [`FileListCallback`](crate::FileListCallback), if any.
+    fn report_file_list_entries(&mut self, flat_start: usize) {
+        if let Some(callback) = self.file_list_callback.as_mut() {
+            for entry in &self.file_list[flat_start..] {
+                callback.on_file_list_entry(&ListOnlyEntry::from_file_entry(entry));
+            }
+        }
+    }
+
     /// Validates an INC_RECURSE sub-list header's `dir_ndx` against untrusted
     /// wire data, failing closed exactly like upstream, and claims the directory
     /// so a duplicate sub-list is rejected.
@@ -5,6 +5,8 @@
 //! components, and (on Windows) drive/UNC prefixes are stripped from the
 //! file list before any disk operation runs against them.
 
+use std::io;
+
 use logging::info_log;
 
 use super::super::ReceiverContext;
@@ -112,4 +114,53 @@ impl ReceiverContext {
 
         removed
     }
+
+    /// Refuses an INC_RECURSE sub-list segment carrying an unsafe path.
+    ///
+    /// [`sanitize_file_list`](Self::sanitize_file_list) only sees the entries
+    /// present when transfer setup runs; segments fetched on demand afterwards
+    /// land in `file_list[flat_start..]` without passing through it. Dropping
+    /// entries there would shift the segment's wire NDX mapping, so an
+    /// absolute path (without `--relative`), a `..` component or a Windows
+    /// drive/UNC prefix aborts the
+    /// transfer instead, as upstream does for every received entry. Skipped
+    /// entirely under `--trust-sender`.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `flist.c:769`: `ABORTING due to unsafe pathname from sender`
+    pub(in crate::receiver) fn refuse_unsafe_segment_paths(
+        &mut self,
+        flat_start: usize,
+    ) -> io::Result<()> {
+        if self.config.trust_sender {
+            return Ok(());
+        }
+        let relative_paths = self.config.flags.relative;
+        let unsafe_path = self.file_list[flat_start..].iter().find_map(|entry| {
+            let path = entry.path();
+            let refused = (!relative_paths && path.has_root()) || path_contains_dot_dot(path);
+            // Same native-Win32 drive/UNC guard as `sanitize_file_list`.
+            #[cfg(windows)]
+            let refused = refused
+                || path
+                    .components()
+                    .next()
+                    .is_some_and(|c| matches!(c, std::path::Component::Prefix(_)));
+            refused.then(|| path.display().to_string())
+        });
+        let Some(unsafe_path) = unsafe_path else {
+            return Ok(());
+        };
+        // Drop this segment's entries so nothing escapes the tree.
+        self.file_list.truncate(flat_start);
+        Err(io::Error::new(
+            io::ErrorKind::Unsupported,
+            format!(
+                "ABORTING due to unsafe pathname from sender: {unsafe_path} {}{}",
+                crate::role_trailer::error_location!(),
+                crate::role_trailer::receiver()
+            ),
+        ))
+    }
 }
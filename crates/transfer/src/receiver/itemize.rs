@@ -223,6 +223,25 @@ impl ReceiverContext {
         }
     }
 
+    /// Routes an already-formatted per-file error line to the correct sink: a
+    /// server receiver multiplexes it as `MSG_ERROR_XFER` so the peer exits
+    /// with `RERR_PARTIAL`; a client receiver (pull) writes it to stderr.
+    ///
+    /// upstream: log.c:rwrite() - `FERROR_XFER` on `am_server` -> `MSG_ERROR_XFER`,
+    /// else write to the client error fd.
+    pub(in crate::receiver) fn emit_error_line<W: crate::writer::MsgInfoSender + ?Sized>(
+        &self,
+        writer: &mut W,
+        line: &str,
+    ) -> std::io::Result<()> {
+        if self.config.connection.client_mode {
+            use std::io::Write as _;
+            std::io::stderr().write_all(line.as_bytes())
+        } else {
+            writer.send_msg_error_xfer(line.as_bytes())
+        }
+    }
+
     /// Builds the display context for itemize time-position rendering.
     ///
     /// # Upstream Reference
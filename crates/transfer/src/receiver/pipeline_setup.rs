@@ -22,6 +22,9 @@ use metadata::AclIdMapper;
 /// loop and the redo pass.
 pub(in crate::receiver) struct PipelineSetup {
     pub(in crate::receiver) dest_dir: PathBuf,
+    /// Keeps `dest_dir` usable when it is an anchored alias for a destination
+    /// of `PATH_MAX` bytes or more; the alias resolves only while this lives.
+    pub(in crate::receiver) dest_anchor: fast_io::ReachablePath,
     pub(in crate::receiver) metadata_opts: metadata::MetadataOptions,
     pub(in crate::receiver) checksum_length: NonZeroU8,
     pub(in crate::receiver) checksum_algorithm: signature::SignatureAlgorithm,
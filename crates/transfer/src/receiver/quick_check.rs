@@ -4,6 +4,7 @@
 //! destination stat against source file list entries, plus reference
 //! directory handling and file checksum comparison.
 
+use std::ffi::OsStr;
 use std::fs;
 use std::io::Read;
 use std::path::{Component, Path, PathBuf};
@@ -37,6 +38,16 @@ pub(super) fn is_hardlink_follower(entry: &FileEntry) -> bool {
 
 /// Pure-function quick-check: compares destination stat against source entry.
 ///
+/// Whole-file checksum comparison requested by `--checksum` (`-c`).
+#[derive(Clone, Copy, Debug)]
+pub(super) struct AlwaysChecksum<'a> {
+    /// Unseeded algorithm of the sender's file-list sums.
+    pub(super) algorithm: protocol::ChecksumAlgorithm,
+    /// Attribute that may hold the destination's precomputed sum
+    /// (`--checksum-xattr`).
+    pub(super) xattr: Option<&'a OsStr>,
+}
+
 /// Returns `true` when the destination file matches the source entry (skip transfer).
 ///
 /// Follows upstream `generator.c:624 quick_check_ok()` evaluation order:
@@ -51,7 +62,7 @@ pub(super) fn quick_check_matches(
     dest_meta: &fs::Metadata,
     preserve_times: bool,
     size_only: bool,
-    always_checksum: Option<protocol::ChecksumAlgorithm>,
+    always_checksum: Option<AlwaysChecksum<'_>>,
     modify_window: ModifyWindow,
 ) -> bool {
     // upstream: generator.c:621 - size check first
@@ -60,11 +71,9 @@ pub(super) fn quick_check_matches(
     }
     // upstream: generator.c:633 - always_checksum compares file checksums
     // instead of relying on mtime. Takes priority over size_only and ignore_times.
-    if let Some(algorithm) = always_checksum {
+    if let Some(checksum) = always_checksum {
         return match entry.checksum() {
-            Some(expected) => {
-                file_checksum_matches(dest_path, dest_meta.len(), algorithm, expected)
-            }
+            Some(expected) => file_checksum_matches(dest_path, dest_meta.len(), checksum, expected),
             None => false,
         };
     }
@@ -175,6 +184,21 @@ pub(super) fn dest_type_matches_source(dest_path: &Path, source_entry: &FileEntr
     }
 }
 
+/// Returns `true` when the destination is a FIFO, socket or device node
+/// (`FT_SPECIAL` / `FT_DEVICE`), inspected with `symlink_metadata`.
+///
+/// A regular file must never be quick-checked against or read from such a
+/// node: opening a FIFO blocks until a writer appears, and a device yields its
+/// own contents rather than a basis.
+///
+/// upstream: generator.c:recv_generator() - a non-regular destination is
+/// removed with `delete_item(fname, mode, del_opts | DEL_FOR_FILE)` before the
+/// regular file is received.
+pub(super) fn dest_is_special_node(dest_path: &Path) -> bool {
+    fs::symlink_metadata(dest_path)
+        .is_ok_and(|meta| matches!(file_type_category(mode_from_metadata(&meta)), Some(4 | 5)))
+}
+
 /// Extracts the raw `st_mode` bits from filesystem metadata.
 ///
 /// On unix the mode is read directly. On other platforms only the coarse file
@@ -264,7 +288,7 @@ fn best_reference_match<'a>(
     reference_directories: &'a [ReferenceDirectory],
     preserve_times: bool,
     size_only: bool,
-    always_checksum: Option<protocol::ChecksumAlgorithm>,
+    always_checksum: Option<AlwaysChecksum<'_>>,
     modify_window: ModifyWindow,
     copy_links: bool,
     metadata_opts: &MetadataOptions,
@@ -357,7 +381,7 @@ pub(super) fn try_reference_dest(
     reference_directories: &[ReferenceDirectory],
     preserve_times: bool,
     size_only: bool,
-    always_checksum: Option<protocol::ChecksumAlgorithm>,
+    always_checksum: Option<AlwaysChecksum<'_>>,
     modify_window: ModifyWindow,
     copy_links: bool,
     metadata_opts: &MetadataOptions,
@@ -766,14 +790,24 @@ fn io_error_message(error: &std::io::Error) -> String {
 ///
 /// Used by `--checksum` (`-c`) mode to compare file contents instead of
 /// mtime+size quick-check. Returns `true` when checksums match (skip transfer).
+/// A `--checksum-xattr` digest still valid for the file stands in for
+/// reading it.
 ///
 /// upstream: checksum.c:402 `file_checksum()` - plain hash, no seed
 fn file_checksum_matches(
     path: &Path,
     file_size: u64,
-    algorithm: protocol::ChecksumAlgorithm,
+    checksum: AlwaysChecksum<'_>,
     expected: &[u8],
 ) -> bool {
+    let algorithm = checksum.algorithm;
+    if let Some(recorded) = checksum
+        .xattr
+        .and_then(|name| crate::checksum_xattr::recorded_checksum(path, name, algorithm))
+    {
+        let cmp_len = expected.len().min(recorded.len());
+        return recorded[..cmp_len] == expected[..cmp_len];
+    }
     let Ok(mut file) = fs::File::open(path) else {
         return false;
     };
@@ -782,7 +816,7 @@ fn file_checksum_matches(
     let mut buf = vec![0u8; 256 * 1024];
     let mut remaining = file_size;
     while remaining > 0 {
-        let to_read = buf.len().min(remaining as usize);
+        let to_read = remaining.min(buf.len() as u64) as usize;
         if file.read_exact(&mut buf[..to_read]).is_err() {
             return false;
         }
@@ -2001,3 +2035,85 @@ mod non_regular_alt_dest_tests {
         assert!(!handled2, "a differing device rdev must not match");
     }
 }
+
+#[cfg(all(unix, feature = "xattr"))]
+#[cfg(test)]
+mod checksum_xattr_tests {
+    use std::ffi::OsStr;
+    use std::fs;
+
+    use filetime::{FileTime, set_file_mtime};
+    use protocol::flist::FileEntry;
+
+    use super::{AlwaysChecksum, ModifyWindow, quick_check_matches};
+
+    const NAME: &str = "user.rsync.checksum";
+
+    /// A destination whose attribute records the sender's sum matches under
+    /// `-c` without its data being read, and stops matching once its mtime
+    /// no longer agrees with the recorded one.
+    #[test]
+    fn recorded_sum_stands_in_for_hashing_the_destination() {
+        let dir = tempfile::tempdir().unwrap();
+        let dest_path = dir.path().join("a.bin");
+        fs::write(&dest_path, b"payload").unwrap();
+        set_file_mtime(&dest_path, FileTime::from_unix_time(1_600_000_000, 0)).unwrap();
+        // The recorded digest is deliberately not the data's real xxh64, so a
+        // match proves the attribute was used.
+        let value = format!("xxh64 7 1600000000 0 {}", "cd".repeat(8));
+        if xattr::set(&dest_path, NAME, value.as_bytes()).is_err() {
+            eprintln!("user xattrs unsupported, skipping");
+            return;
+        }
+
+        let mut entry = FileEntry::new_file("a.bin".into(), 7, 0o644);
+        entry.set_checksum(vec![0xcd; 8]);
+        let checksum = AlwaysChecksum {
+            algorithm: protocol::ChecksumAlgorithm::XXH64,
+            xattr: Some(OsStr::new(NAME)),
+        };
+        let window = ModifyWindow::from_secs(0);
+
+        let meta = fs::metadata(&dest_path).unwrap();
+        assert!(quick_check_matches(
+            &entry,
+            &dest_path,
+            &meta,
+            true,
+            false,
+            Some(checksum),
+            window
+        ));
+        let unconfigured = AlwaysChecksum {
+            xattr: None,
+            ..checksum
+        };
+        assert!(
+            !quick_check_matches(
+                &entry,
+                &dest_path,
+                &meta,
+                true,
+                false,
+                Some(unconfigured),
+                window
+            ),
+            "without --checksum-xattr the file is hashed"
+        );
+
+        set_file_mtime(&dest_path, FileTime::from_unix_time(1_600_000_001, 0)).unwrap();
+        let meta = fs::metadata(&dest_path).unwrap();
+        assert!(
+            !quick_check_matches(
+                &entry,
+                &dest_path,
+                &meta,
+                true,
+                false,
+                Some(checksum),
+                window
+            ),
+            "a stale attribute is ignored"
+        );
+    }
+}
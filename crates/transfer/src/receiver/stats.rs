@@ -5,6 +5,7 @@
 
 use std::path::PathBuf;
 
+use protocol::flist::FileEntry;
 use protocol::stats::{CreatedStats, DeleteStats};
 
 /// A single file-list entry captured for `--list-only` rendering.
@@ -57,6 +58,34 @@ pub struct ListOnlyEntry {
     pub is_symlink: bool,
 }
 
+impl ListOnlyEntry {
+    /// Snapshots the listing fields of a received file-list entry.
+    pub(crate) fn from_file_entry(entry: &FileEntry) -> Self {
+        let is_symlink = entry.is_symlink();
+        Self {
+            path: entry.path().clone(),
+            mode: entry.mode(),
+            size: entry.size(),
+            mtime: entry.mtime(),
+            mtime_nsec: entry.mtime_nsec(),
+            // upstream: generator.c list_file_entry() renders F_ATIME(f)
+            // and F_CRTIME(f) when the atimes/crtimes ndx columns are
+            // active. The flist FileEntry carries no crtime nanosecond
+            // component, so crtime_nsec is always 0.
+            atime: entry.atime(),
+            atime_nsec: entry.atime_nsec(),
+            crtime: entry.crtime(),
+            crtime_nsec: 0,
+            symlink_target: if is_symlink {
+                entry.link_target().cloned()
+            } else {
+                None
+            },
+            is_symlink,
+        }
+    }
+}
+
 /// Statistics from a receiver transfer operation.
 ///
 /// Returned inside [`crate::ServerStats::Receiver`] after a successful receive.
@@ -201,3 +201,87 @@ fn receiver_skips_fifo_without_specials_flag() {
         "without --specials the receiver must not materialise the FIFO",
     );
 }
+
+/// Records `MSG_ERROR_XFER` payloads so a test can inspect the per-file
+/// diagnostic a server receiver sends back to the client.
+#[derive(Default)]
+struct ErrorCapturingWriter {
+    errors: Vec<String>,
+}
+
+impl Write for ErrorCapturingWriter {
+    fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
+        Ok(buf.len())
+    }
+    fn flush(&mut self) -> io::Result<()> {
+        Ok(())
+    }
+}
+
+impl MsgInfoSender for ErrorCapturingWriter {
+    fn send_msg_error_xfer(&mut self, data: &[u8]) -> io::Result<()> {
+        self.errors.push(String::from_utf8_lossy(data).into_owned());
+        Ok(())
+    }
+}
+
+/// An unprivileged receiver cannot `mknod(2)` a device. The entry must be
+/// skipped with upstream's `mknod "%s" failed` FERROR_XFER line while the
+/// FIFO that follows it is still created, and the io_error bit must be set so
+/// the run exits RERR_PARTIAL (23) instead of aborting or succeeding silently.
+///
+/// upstream: generator.c:atomic_create() - `rsyserr(FERROR_XFER, errno,
+/// "mknod %s failed", full_fname(fname))` then continue.
+#[test]
+fn receiver_warns_and_skips_device_it_cannot_create() {
+    use std::os::unix::fs::FileTypeExt;
+
+    let tmp = tempfile::tempdir().expect("tempdir");
+    let dest = tmp.path();
+
+    // Only meaningful when mknod is refused; root creates the device.
+    let probe = dest.join(".probe");
+    let can_mknod =
+        metadata::create_device_node_from_parts(&probe, 0o600, false, 1, 3, false).is_ok();
+    let _ = std::fs::remove_file(&probe);
+    if can_mknod {
+        return;
+    }
+
+    let handshake = test_handshake();
+    let mut ctx = ReceiverContext::new_for_test(&handshake, special_receiver_config());
+    ctx.file_list = vec![
+        FileEntry::new_char_device("null".into(), 0o666, 1, 3),
+        FileEntry::new_fifo("pipe".into(), 0o640),
+    ];
+
+    let mut writer = ErrorCapturingWriter::default();
+    ctx.create_specials(dest, None, &mut writer)
+        .expect("a refused mknod must not abort the pass");
+
+    assert!(
+        std::fs::symlink_metadata(dest.join("null")).is_err(),
+        "the refused device must be left absent"
+    );
+    assert!(
+        std::fs::symlink_metadata(dest.join("pipe"))
+            .expect("the FIFO after the refused device must still be created")
+            .file_type()
+            .is_fifo()
+    );
+    let expected = format!(
+        "rsync: [generator] mknod \"{}\" failed: ",
+        dest.join("null").display()
+    );
+    assert_eq!(writer.errors.len(), 1, "one diagnostic per skipped node");
+    assert!(
+        writer.errors[0].starts_with(&expected),
+        "unexpected diagnostic: {:?}",
+        writer.errors[0]
+    );
+    assert_ne!(
+        ctx.flist_io_error & crate::generator::io_error_flags::IOERR_GENERAL,
+        0,
+        "the skip must surface as RERR_PARTIAL"
+    );
+}
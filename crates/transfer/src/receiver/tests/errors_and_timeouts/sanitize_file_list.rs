@@ -209,3 +209,48 @@ fn windows_drive_relative_path_allowed_when_trusted() {
     assert_eq!(removed, 0);
     assert_eq!(ctx.file_list.len(), 2);
 }
+
+/// A sub-list segment fetched after setup cannot be filtered without
+/// shifting its NDX mapping, so an untrusted `../evil` entry aborts the
+/// transfer and the segment is discarded.
+#[test]
+fn unsafe_segment_path_refused_when_untrusted() {
+    let entries = vec![FileEntry::new_directory("sub".into(), 0o755)];
+    let mut ctx = receiver_with_trust(entries, false);
+    ctx.file_list
+        .push(FileEntry::new_file("../evil".into(), 10, 0o644));
+
+    let err = ctx
+        .refuse_unsafe_segment_paths(1)
+        .expect_err("../evil must be refused");
+    assert_eq!(err.kind(), std::io::ErrorKind::Unsupported);
+    assert!(
+        err.to_string()
+            .contains("unsafe pathname from sender: ../evil")
+    );
+    assert_eq!(ctx.file_list.len(), 1, "segment entries are dropped");
+}
+
+#[test]
+fn unsafe_segment_path_allowed_when_trusted() {
+    let entries = vec![FileEntry::new_directory("sub".into(), 0o755)];
+    let mut ctx = receiver_with_trust(entries, true);
+    ctx.file_list
+        .push(FileEntry::new_file("../evil".into(), 10, 0o644));
+
+    ctx.refuse_unsafe_segment_paths(1)
+        .expect("--trust-sender accepts the entry");
+    assert_eq!(ctx.file_list.len(), 2);
+}
+
+#[test]
+fn safe_segment_paths_accepted_when_untrusted() {
+    let entries = vec![FileEntry::new_directory("sub".into(), 0o755)];
+    let mut ctx = receiver_with_trust(entries, false);
+    ctx.file_list
+        .push(FileEntry::new_file("sub/file.txt".into(), 10, 0o644));
+
+    ctx.refuse_unsafe_segment_paths(1)
+        .expect("safe segment accepted");
+    assert_eq!(ctx.file_list.len(), 2);
+}
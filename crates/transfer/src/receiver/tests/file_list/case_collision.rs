@@ -0,0 +1,105 @@
+//! Case-insensitive destination collision detection.
+//!
+//! WHY this matters: on macOS and Windows `README` and `readme` are the same
+//! destination file, so a tree carrying both loses one of them with no
+//! diagnostic. The receiver probes the destination and, when it folds case,
+//! reports each colliding pair. The collision finder is exercised directly so
+//! the checks run on case-sensitive hosts too, with the probe pinned against
+//! the real filesystem where its answer is known.
+
+use std::path::PathBuf;
+
+use protocol::flist::FileEntry;
+
+use super::super::super::ReceiverContext;
+use super::super::super::file_list::{dest_is_case_insensitive, find_case_collisions};
+use super::super::support::{test_config, test_handshake};
+
+fn file(name: &str) -> FileEntry {
+    FileEntry::new_file(PathBuf::from(name), 1, 0o644)
+}
+
+fn dir(name: &str) -> FileEntry {
+    FileEntry::new_directory(PathBuf::from(name), 0o755)
+}
+
+#[test]
+fn names_differing_only_in_case_collide() {
+    let entries = [file("README"), file("notes.txt"), file("readme")];
+    assert_eq!(find_case_collisions(&entries), vec![(0, 2)]);
+}
+
+#[test]
+fn distinct_names_do_not_collide() {
+    let entries = [file("a.txt"), file("b.txt"), dir("sub"), file("sub/a.txt")];
+    assert!(find_case_collisions(&entries).is_empty());
+}
+
+#[test]
+fn nested_names_collide_through_parent_case() {
+    let entries = [dir("docs"), file("docs/Guide.md"), file("docs/guide.md")];
+    assert_eq!(find_case_collisions(&entries), vec![(1, 2)]);
+}
+
+/// A colliding directory pair is reported once, not again for each child that
+/// exists under both spellings.
+#[test]
+fn colliding_directory_reports_once() {
+    let entries = [
+        dir("Src"),
+        file("Src/main.rs"),
+        dir("src"),
+        file("src/main.rs"),
+        file("src/lib.rs"),
+    ];
+    assert_eq!(find_case_collisions(&entries), vec![(0, 2)]);
+}
+
+#[test]
+fn tombstoned_entries_are_ignored() {
+    let mut dropped = file("readme");
+    dropped.tombstone();
+    let entries = [file("README"), dropped];
+    assert!(find_case_collisions(&entries).is_empty());
+}
+
+#[test]
+fn receiver_warns_only_for_case_insensitive_destination() {
+    let handshake = test_handshake();
+    let mut ctx = ReceiverContext::new_for_test(&handshake, test_config());
+    ctx.file_list.push(file("Makefile"));
+    ctx.file_list.push(file("makefile"));
+
+    assert_eq!(ctx.warn_case_collisions(0), 0);
+    ctx.case_insensitive_dest = true;
+    assert_eq!(ctx.warn_case_collisions(0), 1);
+    assert_eq!(
+        ctx.warn_case_collisions(1),
+        0,
+        "only entries from `from` on"
+    );
+}
+
+/// Linux filesystems are case-sensitive, so the probe must not fold even
+/// when a sibling spelled with the other case exists as its own directory.
+#[cfg(target_os = "linux")]
+#[test]
+fn probe_reports_case_sensitive_linux_destination() {
+    let root = tempfile::TempDir::new().unwrap();
+    let dest = root.path().join("dest");
+    std::fs::create_dir(&dest).unwrap();
+    assert!(!dest_is_case_insensitive(&dest));
+
+    std::fs::create_dir(root.path().join("Dest")).unwrap();
+    assert!(!dest_is_case_insensitive(&dest));
+}
+
+#[test]
+fn probe_of_missing_destination_uses_platform_default() {
+    let root = tempfile::TempDir::new().unwrap();
+    let missing = root.path().join("not-yet-created");
+    assert_eq!(
+        dest_is_case_insensitive(&missing),
+        cfg!(any(target_os = "macos", windows))
+    );
+}
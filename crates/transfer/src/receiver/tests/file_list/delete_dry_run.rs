@@ -0,0 +1,135 @@
+//! `--dry-run --delete` on the network receiver's delete pass.
+//!
+//! WHY this matters: `-n --delete` is the way to preview what a destructive
+//! sync would remove. Upstream runs the whole deletion walk under dry-run and
+//! logs every victim, but `do_unlink()`/`do_rmdir()` return success without
+//! touching the filesystem (`syscall.c`). Over SSH/daemon the receiver drives
+//! the pass locally, so each removal site it uses - the immediate parallel
+//! pass, the capped serial executor (`--max-delete`), and the deferred
+//! `--delete-delay` executor - must list the victims and leave them in place.
+
+use std::ffi::OsString;
+use std::path::Path;
+
+use protocol::flist::FileEntry;
+
+use super::super::super::ReceiverContext;
+use super::super::support::{CapturingDeletionWriter, test_config, test_handshake};
+
+/// Populates `dest` with a listed `keep.txt` plus an extraneous file and an
+/// extraneous directory holding two files.
+fn populate(dest: &Path) {
+    std::fs::write(dest.join("keep.txt"), b"listed").unwrap();
+    std::fs::write(dest.join("stale.txt"), b"extraneous").unwrap();
+    std::fs::create_dir(dest.join("stale_dir")).unwrap();
+    std::fs::write(dest.join("stale_dir").join("a.txt"), b"a").unwrap();
+    std::fs::write(dest.join("stale_dir").join("b.txt"), b"b").unwrap();
+}
+
+fn build_receiver(dest: &Path, late_delete: bool, max_delete: Option<u64>) -> ReceiverContext {
+    let handshake = test_handshake();
+    let mut config = test_config();
+    config.flags.delete = true;
+    config.flags.dry_run = true;
+    config.flags.info_flags.itemize = true;
+    config.deletion.delete_after = false;
+    config.deletion.late_delete = late_delete;
+    config.deletion.max_delete = max_delete;
+    config.args = vec![OsString::from(dest.to_str().unwrap())];
+
+    let mut ctx = ReceiverContext::new_for_test(&handshake, config);
+    ctx.file_list
+        .push(FileEntry::new_directory(".".into(), 0o755));
+    ctx.file_list
+        .push(FileEntry::new_file("keep.txt".into(), 6, 0o644));
+    ctx
+}
+
+fn assert_preview_lists_victims(lines: &[String]) {
+    for victim in [
+        "*deleting   stale.txt",
+        "*deleting   stale_dir/",
+        "*deleting   stale_dir/a.txt",
+        "*deleting   stale_dir/b.txt",
+    ] {
+        assert!(
+            lines.iter().any(|line| line == victim),
+            "missing {victim:?} in dry-run output: {lines:?}"
+        );
+    }
+    assert!(
+        !lines.iter().any(|line| line.contains("keep.txt")),
+        "a listed file must not be previewed for deletion: {lines:?}"
+    );
+}
+
+fn assert_nothing_removed(dest: &Path) {
+    assert_eq!(
+        std::fs::read(dest.join("stale.txt")).unwrap(),
+        b"extraneous"
+    );
+    assert!(dest.join("stale_dir").join("a.txt").exists());
+    assert!(dest.join("stale_dir").join("b.txt").exists());
+    assert_eq!(std::fs::read(dest.join("keep.txt")).unwrap(), b"listed");
+}
+
+#[test]
+fn immediate_dry_run_delete_lists_victims_and_keeps_them() {
+    let dir = tempfile::TempDir::new().unwrap();
+    let dest = dir.path();
+    populate(dest);
+
+    let ctx = build_receiver(dest, false, None);
+    let mut writer = CapturingDeletionWriter::default();
+    let (stats, limit_exceeded, io_bits) = ctx
+        .delete_extraneous_files(dest, None, &mut writer)
+        .unwrap();
+
+    assert_preview_lists_victims(&writer.lines);
+    assert_nothing_removed(dest);
+    assert_eq!(stats.files, 3, "every extraneous file is counted");
+    assert_eq!(stats.dirs, 1, "the extraneous directory is counted");
+    assert!(!limit_exceeded);
+    assert_eq!(io_bits, 0);
+}
+
+#[test]
+fn capped_dry_run_delete_lists_victims_and_keeps_them() {
+    let dir = tempfile::TempDir::new().unwrap();
+    let dest = dir.path();
+    populate(dest);
+
+    let ctx = build_receiver(dest, false, Some(100));
+    let mut writer = CapturingDeletionWriter::default();
+    let (stats, _limit, _io) = ctx
+        .delete_extraneous_files(dest, None, &mut writer)
+        .unwrap();
+
+    assert_preview_lists_victims(&writer.lines);
+    assert_nothing_removed(dest);
+    assert_eq!(stats.files, 3);
+    assert_eq!(stats.dirs, 1);
+}
+
+#[test]
+fn delayed_dry_run_delete_keeps_victims() {
+    let dir = tempfile::TempDir::new().unwrap();
+    let dest = dir.path();
+    populate(dest);
+
+    let ctx = build_receiver(dest, true, None);
+    let mut writer = CapturingDeletionWriter::default();
+    let (victims, _io) = ctx
+        .collect_delayed_deletions(dest, None, &mut writer)
+        .unwrap();
+    assert!(
+        !victims.is_empty(),
+        "the delayed pass must still decide the extraneous entries"
+    );
+    let (stats, _io) = ctx
+        .execute_delayed_deletions(dest, None, &victims, &mut writer)
+        .unwrap();
+
+    assert_nothing_removed(dest);
+    assert!(stats.files >= 1, "the dry run still counts each victim");
+}
@@ -457,7 +457,7 @@ mod create_directory_incremental_tests {
     /// `delete_item(fname, ..., del_opts | DEL_FOR_DIR)` and then
     /// `do_mkdir_at()` (`generator.c:1451-1455`). The receiver's
     /// `classify_dir_destination` reproduces this: it removes the conflicting
-    /// symlink and reports `ReplacedSymlink`, which drives a fresh `mkdir`.
+    /// symlink and reports `ReplacedNonDirectory`, which drives a fresh `mkdir`.
     ///
     /// A dangling symlink is used deliberately: `Path::exists` follows it and
     /// reports "missing", which the old `.exists()` probe mistook for a plain
@@ -512,6 +512,93 @@ mod create_directory_incremental_tests {
         assert_eq!(failed.count(), 0, "replacement is not a failure");
     }
 
+    /// A regular file at a directory-creation target is replaced by a real
+    /// directory, the same as a conflicting symlink: upstream deletes any
+    /// non-directory via `delete_item(..., DEL_FOR_DIR)` before `do_mkdir_at()`
+    /// (`generator.c:1451-1455`). Reusing it as an existing directory would
+    /// leave every child of the incoming directory failing with `ENOTDIR`.
+    #[test]
+    fn replaces_conflicting_regular_file_with_real_directory() {
+        let temp = TempDir::new().unwrap();
+        let dest = temp.path();
+        let leaf = dest.join("victim");
+        std::fs::write(&leaf, b"not a directory").expect("plant regular file");
+
+        let entry = FileEntry::new_directory("victim".into(), 0o755);
+        let opts = metadata::MetadataOptions::default();
+        let mut failed = FailedDirectories::new();
+
+        let handshake = test_handshake();
+        let config = test_config();
+        let ctx = ReceiverContext::new_for_test(&handshake, config);
+
+        let result = ctx.create_directory_incremental(
+            dest,
+            &entry,
+            &opts,
+            &mut failed,
+            None,
+            None,
+            #[cfg(unix)]
+            None,
+        );
+
+        assert_eq!(
+            result
+                .expect("the file is removed and a directory created")
+                .map(|(is_new, _)| is_new),
+            Some(true),
+        );
+        assert!(leaf.is_dir(), "the destination must now be a directory");
+        assert_eq!(failed.count(), 0, "replacement is not a failure");
+    }
+
+    /// Under `-n` the same type conflict is reported but nothing is touched:
+    /// upstream's `do_unlink()` and `do_mkdir()` return success without
+    /// acting when `dry_run` is set (`syscall.c`), so a dry-run push must not
+    /// delete the destination file.
+    #[test]
+    fn dry_run_keeps_conflicting_regular_file() {
+        let temp = TempDir::new().unwrap();
+        let dest = temp.path();
+        let leaf = dest.join("victim");
+        std::fs::write(&leaf, b"not a directory").expect("plant regular file");
+
+        let entry = FileEntry::new_directory("victim".into(), 0o755);
+        let opts = metadata::MetadataOptions::default();
+        let mut failed = FailedDirectories::new();
+
+        let handshake = test_handshake();
+        let mut config = test_config();
+        config.flags.dry_run = true;
+        let ctx = ReceiverContext::new_for_test(&handshake, config);
+
+        let result = ctx.create_directory_incremental(
+            dest,
+            &entry,
+            &opts,
+            &mut failed,
+            None,
+            None,
+            #[cfg(unix)]
+            None,
+        );
+
+        assert_eq!(
+            result
+                .expect("dry run reports the replacement")
+                .map(|(is_new, _)| is_new),
+            Some(true),
+        );
+        assert!(leaf.is_file(), "dry run must leave the file in place");
+        assert_eq!(
+            std::fs::read(&leaf).unwrap(),
+            b"not a directory",
+            "dry run must not rewrite the file"
+        );
+        assert_eq!(failed.count(), 0);
+    }
+
     /// Fail-loud invariant (Rule 12): a genuine non-EACCES error from the
     /// underlying `mkdir` must propagate as `Err`, never be coerced to
     /// `Ok(None)` / `mark_failed`, which would hide it from the caller's
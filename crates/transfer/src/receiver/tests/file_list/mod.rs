@@ -29,13 +29,20 @@
 //! - [`delete_backup`] - `--backup` / `--backup-dir` preservation of each
 //!   extraneous file victim before the receiver's delete pass unlinks it,
 //!   across the immediate, delayed, and capped removal sites.
+//! - [`delete_dry_run`] - `--dry-run --delete` lists every victim at each
+//!   removal site while leaving the destination untouched.
+//! - [`case_collision`] - names that differ only in case on a
+//!   case-insensitive destination, and the destination case probe.
 //! - [`iconv_wire_order`] - regression coverage for the receiver-side
 //!   `--iconv` ordering invariant (file_list stays in sender wire-emit
 //!   order, never re-sorted on local-charset bytes).
 
+mod case_collision;
 mod dedup;
 #[cfg(unix)]
 mod delete_backup;
+#[cfg(unix)]
+mod delete_dry_run;
 mod delete_pipeline_hook;
 mod delete_timing;
 mod filter_chain;
@@ -11,17 +11,18 @@
 //! - `generator.c:624` - `quick_check_ok()` evaluation order
 
 use std::fs;
-use std::io::Write;
+use std::io::{self, Write};
 use std::path::{Path, PathBuf};
 
+use engine::local_copy::LocalCopyFileInfo;
 use logging::{debug_gte, debug_log, info_log};
 use metadata::{MetadataOptions, apply_metadata_with_cached_stat, metadata_unchanged};
 use protocol::flist::FileEntry;
 
 use crate::receiver::directory::FailedDirectories;
 use crate::receiver::quick_check::{
-    dest_mtime_newer, dest_type_matches_source, is_hardlink_follower, quick_check_matches,
-    try_reference_dest,
+    AlwaysChecksum, dest_is_special_node, dest_mtime_newer, dest_type_matches_source,
+    is_hardlink_follower, quick_check_matches, try_reference_dest,
 };
 use crate::receiver::stats::{ListOnlyEntry, TransferStats};
 use crate::receiver::{ReceiverContext, apply_acls_from_receiver_cache};
@@ -41,30 +42,7 @@ impl ReceiverContext {
     pub(in crate::receiver) fn collect_list_only_entries(&self) -> Vec<ListOnlyEntry> {
         self.file_list
             .iter()
-            .map(|entry| {
-                let is_symlink = entry.is_symlink();
-                ListOnlyEntry {
-                    path: entry.path().clone(),
-                    mode: entry.mode(),
-                    size: entry.size(),
-                    mtime: entry.mtime(),
-                    mtime_nsec: entry.mtime_nsec(),
-                    // upstream: generator.c list_file_entry() renders F_ATIME(f)
-                    // and F_CRTIME(f) when the atimes/crtimes ndx columns are
-                    // active. The flist FileEntry carries no crtime nanosecond
-                    // component, so crtime_nsec is always 0.
-                    atime: entry.atime(),
-                    atime_nsec: entry.atime_nsec(),
-                    crtime: entry.crtime(),
-                    crtime_nsec: 0,
-                    symlink_target: if is_symlink {
-                        entry.link_target().cloned()
-                    } else {
-                        None
-                    },
-                    is_symlink,
-                }
-            })
+            .map(ListOnlyEntry::from_file_entry)
             .collect()
     }
 
@@ -119,6 +97,8 @@ impl ReceiverContext {
         let has_size_bounds = min_size.is_some() || max_size.is_some();
         let has_daemon_filters = daemon_filters.is_some();
         let has_failed_dirs = failed_dirs.is_some();
+        let file_hooks = &self.config.file_hooks;
+        let has_file_filter = file_hooks.has_filter();
         let verbose_client = self.config.flags.verbose && self.config.connection.client_mode;
 
         let candidates: Vec<(usize, &FileEntry)> = self
@@ -155,6 +135,16 @@ impl ReceiverContext {
                         return false;
                     }
                 }
+                // oc-rsync extension: an embedding client's veto is applied
+                // before the destination is examined, so a rejected file is
+                // never requested and its destination is left as it was.
+                if has_file_filter {
+                    let file_path = dest_dir.join(e.path());
+                    let info = LocalCopyFileInfo::received(e.path(), &file_path, e.size());
+                    if !file_hooks.allows(&info) {
+                        return false;
+                    }
+                }
                 true
             })
             .collect();
@@ -197,11 +187,10 @@ impl ReceiverContext {
         let ignore_existing = self.config.file_selection.ignore_existing;
         let existing_only = self.config.file_selection.existing_only;
         let update_only = self.config.flags.update;
-        let always_checksum = if self.config.flags.checksum {
-            Some(self.get_checksum_algorithm())
-        } else {
-            None
-        };
+        let always_checksum = self.config.flags.checksum.then(|| AlwaysChecksum {
+            algorithm: self.get_checksum_algorithm(),
+            xattr: self.config.file_selection.checksum_xattr.as_deref(),
+        });
 
         // Pre-compute whether itemize emission is active so we skip the
         // per-file method dispatch for the common no-itemize case.
@@ -246,6 +235,7 @@ impl ReceiverContext {
             // (allowed_lull None), keeping the default path wire-identical.
             let _ = writer.maybe_send_keepalive();
             let entry = &self.file_list[idx];
+            let mut made_way = false;
             if let Some(ref meta) = dest_meta {
                 if ignore_existing {
                     // upstream: generator.c:1409 - `if (ignore_existing > 0 &&
@@ -300,7 +290,21 @@ impl ReceiverContext {
                     }
                     continue;
                 }
-                if quick_check_matches(
+                if (meta.is_dir()
+                    && fs::symlink_metadata(&file_path).is_ok_and(|lstat| lstat.is_dir()))
+                    || dest_is_special_node(&file_path)
+                {
+                    // upstream: generator.c:recv_generator() - a destination
+                    // that is not a regular file is removed via
+                    // delete_item(..., DEL_FOR_FILE) and the file then counts
+                    // as new (statret = -1); a failed removal skips the file.
+                    // A FIFO, socket or device goes before the quick check so
+                    // it is never compared with, or opened as, the basis.
+                    if !self.make_way_for_file(writer, entry, &file_path, metadata_errors) {
+                        continue;
+                    }
+                    made_way = true;
+                } else if quick_check_matches(
                     entry,
                     &file_path,
                     meta,
@@ -313,6 +317,12 @@ impl ReceiverContext {
                     // up-to-date file; the attr-comparison may still surface a
                     // metadata-only row (perms/owner/group differing while
                     // size+mtime match).
+                    // upstream: generator.c:recv_generator() - an up-to-date
+                    // file drops its stale partial via `do_unlink(partialptr);
+                    // handle_partial_dir(partialptr, PDIR_DELETE)`.
+                    if let Some(dir) = self.config.partial_dir.as_deref() {
+                        crate::temp_guard::remove_partial_dir_file(&file_path, dir);
+                    }
                     let mut unchanged_iflags = self.itemize_existing_flags(entry, meta, 0);
                     // upstream: generator.c:566-572 - ITEM_REPORT_XATTR when the
                     // destination's xattrs differ from the sender's. Computed on
@@ -384,7 +394,7 @@ impl ReceiverContext {
             // changes against the pre-transfer destination. A non-existent dest
             // (statret < 0) is ITEM_IS_NEW; an existing one OR-s the per-attr
             // report bits onto ITEM_TRANSFER.
-            let base_iflags = match dest_meta {
+            let base_iflags = match dest_meta.filter(|_| !made_way) {
                 Some(ref meta) => self.itemize_existing_flags(
                     entry,
                     meta,
@@ -457,11 +467,10 @@ impl ReceiverContext {
         let preserve_times = self.config.flags.times && !self.config.flags.ignore_times;
         let size_only = self.config.file_selection.size_only;
         let modify_window = self.config.file_selection.modify_window;
-        let always_checksum = if self.config.flags.checksum {
-            Some(self.get_checksum_algorithm())
-        } else {
-            None
-        };
+        let always_checksum = self.config.flags.checksum.then(|| AlwaysChecksum {
+            algorithm: self.get_checksum_algorithm(),
+            xattr: self.config.file_selection.checksum_xattr.as_deref(),
+        });
         for (idx, entry) in self.file_list.iter().enumerate() {
             let rel = entry.path();
             let dest_path = if rel.as_os_str() == "." {
@@ -497,7 +506,7 @@ impl ReceiverContext {
         dest_path: &Path,
         preserve_times: bool,
         size_only: bool,
-        always_checksum: Option<protocol::ChecksumAlgorithm>,
+        always_checksum: Option<AlwaysChecksum<'_>>,
         modify_window: metadata::ModifyWindow,
     ) -> u32 {
         use crate::generator::ItemFlags;
@@ -710,6 +719,60 @@ impl ReceiverContext {
         false
     }
 
+    /// Removes the directory, FIFO, socket or device standing where the
+    /// regular file `entry` is about to be received, returning whether the
+    /// file may proceed.
+    ///
+    /// A non-directory node is unlinked. `--delete` and `--force` remove a
+    /// directory with its contents; otherwise only an empty directory can go.
+    /// An entry that cannot be removed is left untouched, the file is skipped,
+    /// and the failure is reported as a transfer error so the run ends with
+    /// `RERR_PARTIAL`.
+    ///
+    /// # Upstream Reference
+    ///
+    /// - `generator.c:recv_generator()` - `del_opts` carries `DEL_RECURSE` when
+    ///   `delete_mode || force_delete`
+    /// - `delete.c:delete_item()` - `"cannot delete non-empty directory: %s"`
+    ///   (`FINFO`) and `"could not make way for new regular file: %s"`
+    ///   (`FERROR_XFER`)
+    pub(in crate::receiver) fn make_way_for_file<W: crate::writer::MsgInfoSender + ?Sized>(
+        &self,
+        writer: &mut W,
+        entry: &FileEntry,
+        dest_path: &Path,
+        metadata_errors: &mut Vec<(PathBuf, String)>,
+    ) -> bool {
+        let recurse = self.config.flags.delete || self.config.deletion.force_delete;
+        let is_dir = fs::symlink_metadata(dest_path).is_ok_and(|lstat| lstat.is_dir());
+        let removed = if !is_dir {
+            fs::remove_file(dest_path)
+        } else if recurse {
+            fs::remove_dir_all(dest_path)
+        } else {
+            fs::remove_dir(dest_path)
+        };
+        match removed {
+            Ok(()) => true,
+            Err(error) if error.kind() == io::ErrorKind::NotFound => true,
+            Err(error) => {
+                let name = entry.path().to_string_lossy();
+                if error.kind() == io::ErrorKind::DirectoryNotEmpty {
+                    let _ = self.emit_info_line(
+                        writer,
+                        &format!("cannot delete non-empty directory: {name}\n"),
+                    );
+                }
+                let _ = self.emit_error_line(
+                    writer,
+                    &format!("could not make way for new regular file: {name}\n"),
+                );
+                metadata_errors.push((dest_path.to_path_buf(), error.to_string()));
+                false
+            }
+        }
+    }
+
     /// Computes the parenthesised reason suffix for the `--ignore-existing`
     /// `"%s exists%s"` notice.
     ///
@@ -729,7 +792,7 @@ impl ReceiverContext {
         dest_meta: &fs::Metadata,
         preserve_times: bool,
         size_only: bool,
-        always_checksum: Option<protocol::ChecksumAlgorithm>,
+        always_checksum: Option<AlwaysChecksum<'_>>,
         modify_window: metadata::ModifyWindow,
         metadata_opts: &MetadataOptions,
     ) -> &'static str {
@@ -1154,7 +1217,7 @@ mod itemize_order_tests {
 mod skip_notice_tests {
     use std::ffi::OsString;
     use std::io;
-    use std::path::Path;
+    use std::path::{Path, PathBuf};
 
     use logging::{InfoFlag, VerbosityConfig};
     use metadata::MetadataOptions;
@@ -1173,6 +1236,7 @@ mod skip_notice_tests {
     #[derive(Default)]
     struct CaptureWriter {
         lines: Vec<String>,
+        errors: Vec<String>,
     }
 
     impl io::Write for CaptureWriter {
@@ -1189,6 +1253,11 @@ mod skip_notice_tests {
             self.lines.push(String::from_utf8_lossy(data).into_owned());
             Ok(())
         }
+
+        fn send_msg_error_xfer(&mut self, data: &[u8]) -> io::Result<()> {
+            self.errors.push(String::from_utf8_lossy(data).into_owned());
+            Ok(())
+        }
     }
 
     fn handshake() -> HandshakeResult {
@@ -1420,4 +1489,139 @@ mod skip_notice_tests {
         let lines = run(cfg(), 2, files(), dest);
         assert_eq!(lines, vec!["typed exists (type change)\n".to_owned()]);
     }
+
+    /// Runs the candidate pass for `files` and returns the selected paths, the
+    /// writer, and the recorded per-file errors.
+    fn select(
+        config: ServerConfig,
+        files: Vec<FileEntry>,
+        dest: &Path,
+    ) -> (Vec<(PathBuf, u32)>, CaptureWriter, Vec<(PathBuf, String)>) {
+        let hs = handshake();
+        let mut ctx = ReceiverContext::new_for_test(&hs, config);
+        ctx.file_list = files;
+
+        let mut writer = CaptureWriter::default();
+        let opts = MetadataOptions::default();
+        let mut errs = Vec::new();
+        let mut stats = TransferStats::default();
+        let selected = ctx
+            .build_files_to_transfer(
+                &mut writer,
+                dest,
+                &opts,
+                None,
+                &mut errs,
+                &mut stats,
+                None,
+                None,
+            )
+            .into_iter()
+            .map(|(_, _, path, iflags)| (path, iflags))
+            .collect();
+        (selected, writer, errs)
+    }
+
+    /// upstream: generator.c:recv_generator() - a populated directory standing
+    /// where a regular file arrives is removed with its contents under
+    /// `--force`, and the file is then requested as a new file.
+    #[test]
+    fn force_replaces_directory_standing_where_file_arrives() {
+        let dir = test_support::create_tempdir();
+        let dest = dir.path();
+        std::fs::create_dir(dest.join("clash")).expect("seed dest directory");
+        std::fs::write(dest.join("clash/inner"), b"old").expect("seed dest child");
+
+        let mut config = server_config();
+        config.deletion.force_delete = true;
+        let files = vec![FileEntry::new_file("clash".into(), 3, 0o644)];
+        let (selected, writer, errs) = select(config, files, dest);
+
+        assert!(
+            !dest.join("clash").exists(),
+            "the directory must be removed"
+        );
+        assert_eq!(selected.len(), 1, "the file must still be requested");
+        assert_eq!(selected[0].0, dest.join("clash"));
+        assert_ne!(
+            selected[0].1 & crate::generator::ItemFlags::ITEM_IS_NEW,
+            0,
+            "the file replaces a removed directory, so it is new"
+        );
+        assert!(writer.errors.is_empty());
+        assert!(errs.is_empty());
+    }
+
+    /// upstream: delete.c:delete_item() - without `--force` or `--delete` a
+    /// populated directory cannot make way for a file: it is kept, the file is
+    /// skipped, and the conflict is reported as a transfer error.
+    #[test]
+    fn directory_standing_where_file_arrives_is_reported_without_force() {
+        let dir = test_support::create_tempdir();
+        let dest = dir.path();
+        std::fs::create_dir(dest.join("clash")).expect("seed dest directory");
+        std::fs::write(dest.join("clash/inner"), b"old").expect("seed dest child");
+
+        let files = vec![FileEntry::new_file("clash".into(), 3, 0o644)];
+        let (selected, writer, errs) = select(server_config(), files, dest);
+
+        assert!(selected.is_empty(), "the file must be skipped");
+        assert!(dest.join("clash/inner").exists(), "the directory is kept");
+        assert_eq!(
+            writer.lines,
+            vec!["cannot delete non-empty directory: clash\n".to_owned()]
+        );
+        assert_eq!(
+            writer.errors,
+            vec!["could not make way for new regular file: clash\n".to_owned()]
+        );
+        assert_eq!(errs.len(), 1, "the failure must mark the run partial");
+    }
+
+    /// An empty directory needs no recursion, so it makes way for the file even
+    /// without `--force`, as upstream's plain `rmdir` in `delete_item()` does.
+    #[test]
+    fn empty_directory_makes_way_for_file_without_force() {
+        let dir = test_support::create_tempdir();
+        let dest = dir.path();
+        std::fs::create_dir(dest.join("clash")).expect("seed dest directory");
+
+        let files = vec![FileEntry::new_file("clash".into(), 3, 0o644)];
+        let (selected, writer, errs) = select(server_config(), files, dest);
+
+        assert_eq!(selected.len(), 1);
+        assert!(!dest.join("clash").exists());
+        assert!(writer.errors.is_empty());
+        assert!(errs.is_empty());
+    }
+
+    /// upstream: generator.c:recv_generator() - a file that is already up to
+    /// date drops the partial a previous interrupted run left in
+    /// `--partial-dir`, and the emptied relative partial-dir is rmdir'd.
+    #[test]
+    fn up_to_date_file_removes_stale_partial_dir_file() {
+        let dir = test_support::create_tempdir();
+        let dest = dir.path();
+        std::fs::write(dest.join("done.bin"), b"complete").expect("seed dest file");
+        filetime::set_file_mtime(
+            dest.join("done.bin"),
+            filetime::FileTime::from_unix_time(1_700_000_000, 0),
+        )
+        .expect("set mtime");
+        std::fs::create_dir(dest.join(".rsync-partial")).expect("seed partial-dir");
+        std::fs::write(dest.join(".rsync-partial/done.bin"), b"comp").expect("seed partial");
+
+        let mut config = server_config();
+        config.flags.times = true;
+        config.partial_dir = Some(PathBuf::from(".rsync-partial"));
+        let mut entry = FileEntry::new_file("done.bin".into(), 8, 0o644);
+        entry.set_mtime(1_700_000_000, 0);
+        let (selected, _, _) = select(config, vec![entry], dest);
+
+        assert!(selected.is_empty(), "the file is up to date");
+        assert!(
+            !dest.join(".rsync-partial").exists(),
+            "the stale partial and its emptied partial-dir must be removed"
+        );
+    }
 }
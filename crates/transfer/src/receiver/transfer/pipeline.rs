@@ -16,6 +16,7 @@ use std::io::{self, Read, Write};
 use std::path::PathBuf;
 use std::sync::Arc;
 
+use engine::local_copy::{LocalCopyError, LocalCopyFileHooks, LocalCopyFileInfo};
 use logging::{debug_log, info_log};
 use protocol::codec::{MonotonicNdxWriter, NdxCodec, create_ndx_codec};
 use protocol::flist::FileEntry;
@@ -43,6 +44,23 @@ type PipelineResult = (
     Vec<(PathBuf, PathBuf)>,
 );
 
+/// Runs the embedding client's done callback for a file the receiver
+/// requested, passing the failure when `result` is an error.
+fn notify_file_done<T>(
+    hooks: &LocalCopyFileHooks,
+    file: &LocalCopyFileInfo<'_>,
+    result: &io::Result<T>,
+) {
+    let error = result.as_ref().err().map(|error| {
+        LocalCopyError::io(
+            "receive",
+            file.destination(),
+            io::Error::new(error.kind(), error.to_string()),
+        )
+    });
+    hooks.notify_done(file, error.as_ref());
+}
+
 impl ReceiverContext {
     /// Emits `MSG_SUCCESS(ndx)` to the sender for every file whose commit was
     /// confirmed since the last drain, when `--remove-source-files` is active.
@@ -204,6 +222,9 @@ impl ReceiverContext {
             partial_mode,
             delay_updates: self.config.write.delay_updates,
             append_verify: self.config.flags.append_verify && !is_redo_pass,
+            receiver_fs: self.receiver_fs.clone(),
+            copy_as: self.config.copy_as,
+            write_buf_size: self.config.write.copy_buffer_size,
             ..DiskCommitConfig::default()
         };
         let mut pipelined_receiver = PipelinedReceiver::new(disk_config)?;
@@ -383,6 +404,16 @@ impl ReceiverContext {
                     dest_dir: Some(setup.dest_dir.as_path()),
                 };
 
+                // oc-rsync extension: an embedding client's start and done
+                // callbacks bracket the file's data. A redo pass re-receives
+                // files that were already reported.
+                let run_hooks = !is_redo_pass && !self.config.file_hooks.is_empty();
+                let hook_info =
+                    LocalCopyFileInfo::received(file_entry.path(), &file_path, file_entry.size());
+                if run_hooks {
+                    self.config.file_hooks.notify_start(&hook_info);
+                }
+
                 let xattr_list = self.resolve_xattr_list(file_entry);
                 let is_device_target = self.config.write.write_devices && file_entry.is_device();
                 let result = process_file_response_streaming(
@@ -397,7 +428,11 @@ impl ReceiverContext {
                     is_device_target,
                     xattr_list,
                     &mut token_reader,
-                )?;
+                );
+                if run_hooks {
+                    notify_file_done(&self.config.file_hooks, &hook_info, &result);
+                }
+                let result = result?;
 
                 pipelined_receiver.note_commit_sent(
                     result.expected_checksum,
@@ -588,7 +623,14 @@ impl ReceiverContext {
         // upstream: io.c perform_io() flushes output via select() while waiting
         // for input. We flush once before blocking on each response read, but
         // only when needed (the multiplex dirty-flag skips redundant syscalls).
-        for &(file_idx, file_entry, _, base_iflags) in files_to_transfer {
+        let run_hooks = !self.config.file_hooks.is_empty();
+        for &(file_idx, file_entry, ref file_path, base_iflags) in files_to_transfer {
+            let hook_info =
+                LocalCopyFileInfo::received(file_entry.path(), file_path, file_entry.size());
+            if run_hooks {
+                self.config.file_hooks.notify_start(&hook_info);
+            }
+
             // upstream: generator.c:1938 - write_ndx(f_out, ndx)
             let wire_ndx = self.flat_to_wire_ndx(file_idx);
             ndx_write_codec.write_ndx(&mut *writer, wire_ndx)?;
@@ -610,13 +652,16 @@ impl ReceiverContext {
             writer.flush()?;
 
             // upstream: sender.c:394-399 - sender echoes write_ndx_and_attrs back
-            let (_echoed_ndx, _sender_attrs) =
-                crate::receiver::wire::SenderAttrs::read_with_codec_xattr(
-                    reader,
-                    &mut ndx_read_codec,
-                    preserve_xattrs,
-                    want_xattr_optim,
-                )?;
+            let echo = crate::receiver::wire::SenderAttrs::read_with_codec_xattr(
+                reader,
+                &mut ndx_read_codec,
+                preserve_xattrs,
+                want_xattr_optim,
+            );
+            if run_hooks {
+                notify_file_done(&self.config.file_hooks, &hook_info, &echo);
+            }
+            let (_echoed_ndx, _sender_attrs) = echo?;
 
             // upstream: rsync.c:672-676 set_file_attrs emits the bare-name
             // notice AFTER the transfer decision is known. In dry-run the
@@ -16,6 +16,7 @@ use protocol::filters::{FilterRuleWireFormat, read_filter_list};
 
 use filters::FilterChain;
 
+use crate::receiver::file_list::dest_is_case_insensitive;
 use crate::receiver::{
     PHASE1_CHECKSUM_LENGTH, PipelineSetup, ReceiverContext, dest_arg_has_trailing_slash,
     ensure_dest_root_exists,
@@ -309,6 +310,22 @@ impl ReceiverContext {
         // same dirfd they always did.
         let dest_dir = self.apply_single_file_rename(dest_dir, file_count, trailing_slash);
 
+        // A destination of PATH_MAX bytes or more is reached through an
+        // anchored alias, kept open in `PipelineSetup::dest_anchor`, standing
+        // in for upstream's main.c:change_dir(dest_path). Every per-entry
+        // path is joined below the alias; notices still name the operand.
+        let dest_anchor = fast_io::reach_long_path(&dest_dir).map_err(|e| {
+            io::Error::new(
+                e.kind(),
+                format!(
+                    "failed to resolve destination root {}: {e} {}{}",
+                    dest_dir.display(),
+                    crate::role_trailer::error_location!(),
+                    crate::role_trailer::receiver()
+                ),
+            )
+        })?;
+
         // upstream: main.c:778-792 get_local_name() - pre-flight mkdir of the
         // destination root when the transfer is multi-file or the operand
         // carries a trailing slash. The local-mode receiver creates the root
@@ -325,7 +342,7 @@ impl ReceiverContext {
         // `get_local_name()` which calls `do_mkdir()` directly against the
         // local filesystem.
         let created_dest_root = ensure_dest_root_exists(
-            &dest_dir,
+            dest_anchor.path(),
             file_count,
             trailing_slash,
             self.config.flags.skip_dest_writes(),
@@ -369,6 +386,8 @@ impl ReceiverContext {
             }
         }
 
+        let dest_dir = dest_anchor.path().to_path_buf();
+
         // UTS-SLDB: when the dest root is a symlink that resolved to a real
         // directory via the stat path in ensure_dest_root_exists, lock the
         // canonical target in here so every downstream open (DirSandbox,
@@ -417,6 +436,14 @@ impl ReceiverContext {
             dest_dir
         };
 
+        // Two names that differ only in case land on the same entry of a
+        // case-insensitive destination (the macOS and Windows defaults), and
+        // the later write silently replaces the earlier one. Probe the
+        // destination once and warn about such pairs in the list received so
+        // far; INC_RECURSE sub-lists are checked as they arrive.
+        self.case_insensitive_dest = dest_is_case_insensitive(&dest_dir);
+        self.warn_case_collisions(0);
+
         let acl_cache = if self.config.flags.acls {
             self.flist_reader_cache
                 .as_ref()
@@ -469,6 +496,7 @@ impl ReceiverContext {
             file_count,
             PipelineSetup {
                 dest_dir,
+                dest_anchor,
                 metadata_opts,
                 checksum_length,
                 checksum_algorithm,
@@ -18,7 +18,7 @@ use engine::CleanupManager;
 
 use crate::delta_apply::ChecksumVerifier;
 use crate::receiver::basis::find_basis_file_with_config;
-use crate::receiver::quick_check::is_hardlink_follower;
+use crate::receiver::quick_check::{dest_is_special_node, is_hardlink_follower};
 use crate::receiver::stats::TransferStats;
 use crate::receiver::wire::{SenderAttrs, SumHead, write_signature_blocks};
 use crate::receiver::{PipelineSetup, ReceiverContext, apply_acls_from_receiver_cache};
@@ -49,6 +49,7 @@ impl ReceiverContext {
 
         let PipelineSetup {
             dest_dir,
+            dest_anchor: _dest_anchor,
             metadata_opts,
             checksum_length,
             checksum_algorithm,
@@ -181,6 +182,15 @@ impl ReceiverContext {
                 continue;
             }
 
+            // upstream: generator.c:recv_generator() - a FIFO, socket or
+            // device at the destination is removed before the file is
+            // received, so the basis search below never opens it.
+            if dest_is_special_node(&file_path)
+                && !self.make_way_for_file(writer, file_entry, &file_path, &mut metadata_errors)
+            {
+                continue;
+            }
+
             let ndx = self.flat_to_wire_ndx(file_idx);
             ndx_write_codec.write_ndx(&mut *writer, ndx)?;
 
@@ -519,6 +529,11 @@ impl ReceiverContext {
             }
             CleanupManager::global().unregister_temp_file(temp_guard.path());
             temp_guard.keep();
+            // upstream: receiver.c:1035-1037 - a committed file no longer needs
+            // its --partial-dir leftover; handle_partial_dir(PDIR_DELETE).
+            if let Some(dir) = self.config.partial_dir.as_deref() {
+                crate::temp_guard::remove_partial_dir_file(&file_path, dir);
+            }
 
             // Skip the stat inside apply_metadata_from_file_entry: the file
             // was just renamed from a temp file, so pass None to apply
@@ -0,0 +1,235 @@
+//! Receiver-side filesystem hook for custom destinations.
+//!
+//! Hosts the [`ReceiverFs`] trait, the production [`OsReceiverFs`] backed by
+//! `std::fs`, and the [`RecordingReceiverFs`] test fake. The trait carves
+//! one method per step of upstream's per-file commit sequence so an embedder
+//! can redirect the destination writes to a custom backend (an object store,
+//! a database, a content-addressed cache) without forking the disk-commit
+//! thread:
+//!
+//! 1. `receiver.c` `open_tmpfile()` - [`ReceiverFs::create_temp`]
+//! 2. `rsync.c` `finish_transfer()` -> `set_file_attrs()` -
+//!    [`ReceiverFs::set_permissions`], [`ReceiverFs::chown`],
+//!    [`ReceiverFs::set_mtime`] against the temp file
+//! 3. `util1.c` `robust_rename()` - [`ReceiverFs::rename`]
+//!
+//! The temp file is still a local [`fs::File`]: the disk thread streams
+//! delta output through io_uring / IOCP / buffered writers that need a real
+//! descriptor. Backends that do not store files locally spool the temp file
+//! in a scratch directory of their choice and publish it from
+//! [`ReceiverFs::rename`], which is the commit point.
+//!
+//! When no hook is installed the disk thread keeps its SEC-1.j/SEC-1.r
+//! sandbox-anchored fast paths. An installed hook owns path resolution, so
+//! the sandbox carriers are bypassed for the hooked operations.
+
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+use std::sync::{Mutex, PoisonError};
+
+use filetime::FileTime;
+
+/// Filesystem operations the receiver issues to put a transferred file into
+/// place.
+///
+/// All methods take `&self` so one hook can be shared across the receiver
+/// and the disk-commit thread behind an `Arc`. Implementations must be
+/// `Send + Sync` because the disk thread runs on its own
+/// [`std::thread`].
+///
+/// Paths are absolute destination paths as computed by the receiver; the
+/// hook decides how they map onto the backend.
+pub trait ReceiverFs: std::fmt::Debug + Send + Sync {
+    /// Creates the temp file that will receive `dest`'s reconstructed data.
+    ///
+    /// `temp_dir` carries `--temp-dir` when set. Returns the open handle and
+    /// the temp path the receiver later passes to [`Self::rename`]. The
+    /// receiver unlinks the returned path with [`fs::remove_file`] when the
+    /// transfer of this file fails, so the path must name a local file.
+    fn create_temp(&self, dest: &Path, temp_dir: Option<&Path>) -> io::Result<(fs::File, PathBuf)>;
+
+    /// Atomically moves the finished temp file onto its final destination.
+    fn rename(&self, from: &Path, to: &Path) -> io::Result<()>;
+
+    /// Applies the permission bits (`mode & 0o7777`) to `path`.
+    fn set_permissions(&self, path: &Path, mode: u32) -> io::Result<()>;
+
+    /// Changes ownership of `path`. `None` leaves that id untouched,
+    /// mirroring `chown(2)`'s `-1` convention.
+    fn chown(&self, path: &Path, uid: Option<u32>, gid: Option<u32>) -> io::Result<()>;
+
+    /// Sets the modification time of `path`.
+    fn set_mtime(&self, path: &Path, mtime: FileTime) -> io::Result<()>;
+}
+
+/// Production [`ReceiverFs`] implementation backed by `std::fs`.
+///
+/// Temp files follow upstream's `.<name>.XXXXXX` naming via
+/// [`crate::temp_guard::open_tmpfile`]. Ownership changes are a no-op on
+/// non-Unix targets, matching the metadata crate's behaviour there.
+#[derive(Debug, Default, Clone, Copy)]
+pub struct OsReceiverFs;
+
+impl ReceiverFs for OsReceiverFs {
+    fn create_temp(&self, dest: &Path, temp_dir: Option<&Path>) -> io::Result<(fs::File, PathBuf)> {
+        let (file, mut guard) = crate::temp_guard::open_tmpfile(dest, temp_dir)?;
+        // The caller installs its own guard over the returned path.
+        guard.keep();
+        Ok((file, guard.path().to_path_buf()))
+    }
+
+    fn rename(&self, from: &Path, to: &Path) -> io::Result<()> {
+        fs::rename(from, to)
+    }
+
+    #[cfg(unix)]
+    fn set_permissions(&self, path: &Path, mode: u32) -> io::Result<()> {
+        use std::os::unix::fs::PermissionsExt;
+        fs::set_permissions(path, fs::Permissions::from_mode(mode & 0o7777))
+    }
+
+    #[cfg(not(unix))]
+    fn set_permissions(&self, path: &Path, mode: u32) -> io::Result<()> {
+        let mut perms = fs::metadata(path)?.permissions();
+        perms.set_readonly(mode & 0o200 == 0);
+        fs::set_permissions(path, perms)
+    }
+
+    #[cfg(unix)]
+    fn chown(&self, path: &Path, uid: Option<u32>, gid: Option<u32>) -> io::Result<()> {
+        std::os::unix::fs::chown(path, uid, gid)
+    }
+
+    #[cfg(not(unix))]
+    fn chown(&self, _path: &Path, _uid: Option<u32>, _gid: Option<u32>) -> io::Result<()> {
+        Ok(())
+    }
+
+    fn set_mtime(&self, path: &Path, mtime: FileTime) -> io::Result<()> {
+        filetime::set_file_mtime(path, mtime)
+    }
+}
+
+/// Operation captured by [`RecordingReceiverFs`] for each receiver dispatch.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum ReceiverFsOp {
+    /// [`ReceiverFs::create_temp`] for the given final destination.
+    CreateTemp {
+        /// Final destination the temp file stages.
+        dest: PathBuf,
+    },
+    /// [`ReceiverFs::rename`] from the temp path onto the destination.
+    Rename {
+        /// Temp path returned by the matching `CreateTemp`.
+        from: PathBuf,
+        /// Final destination.
+        to: PathBuf,
+    },
+    /// [`ReceiverFs::set_permissions`].
+    SetPermissions {
+        /// Target path (the temp file before rename).
+        path: PathBuf,
+        /// Permission bits.
+        mode: u32,
+    },
+    /// [`ReceiverFs::chown`].
+    Chown {
+        /// Target path (the temp file before rename).
+        path: PathBuf,
+        /// Requested uid, `None` to leave unchanged.
+        uid: Option<u32>,
+        /// Requested gid, `None` to leave unchanged.
+        gid: Option<u32>,
+    },
+    /// [`ReceiverFs::set_mtime`].
+    SetMtime {
+        /// Target path (the temp file before rename).
+        path: PathBuf,
+        /// Requested modification time.
+        mtime: FileTime,
+    },
+}
+
+/// Test fake that records every [`ReceiverFs`] dispatch and forwards it to
+/// [`OsReceiverFs`].
+///
+/// Unlike the delete emitter's recorder, this one performs the operations
+/// for real: the disk thread writes into the handle returned by
+/// `create_temp`, so a transfer driven through the recorder still produces
+/// the destination file and the recorded sequence can be checked against
+/// the on-disk result.
+#[derive(Debug, Default)]
+pub struct RecordingReceiverFs {
+    inner: OsReceiverFs,
+    ops: Mutex<Vec<ReceiverFsOp>>,
+}
+
+impl RecordingReceiverFs {
+    /// Creates an empty recorder.
+    #[must_use]
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Returns a snapshot of the recorded operations in dispatch order.
+    ///
+    /// A poisoned mutex still yields a debuggable trace, so the guard is
+    /// recovered rather than propagated.
+    #[must_use]
+    pub fn ops(&self) -> Vec<ReceiverFsOp> {
+        self.ops
+            .lock()
+            .unwrap_or_else(PoisonError::into_inner)
+            .clone()
+    }
+
+    fn record(&self, op: ReceiverFsOp) {
+        self.ops
+            .lock()
+            .unwrap_or_else(PoisonError::into_inner)
+            .push(op);
+    }
+}
+
+impl ReceiverFs for RecordingReceiverFs {
+    fn create_temp(&self, dest: &Path, temp_dir: Option<&Path>) -> io::Result<(fs::File, PathBuf)> {
+        self.record(ReceiverFsOp::CreateTemp {
+            dest: dest.to_path_buf(),
+        });
+        self.inner.create_temp(dest, temp_dir)
+    }
+
+    fn rename(&self, from: &Path, to: &Path) -> io::Result<()> {
+        self.record(ReceiverFsOp::Rename {
+            from: from.to_path_buf(),
+            to: to.to_path_buf(),
+        });
+        self.inner.rename(from, to)
+    }
+
+    fn set_permissions(&self, path: &Path, mode: u32) -> io::Result<()> {
+        self.record(ReceiverFsOp::SetPermissions {
+            path: path.to_path_buf(),
+            mode,
+        });
+        self.inner.set_permissions(path, mode)
+    }
+
+    fn chown(&self, path: &Path, uid: Option<u32>, gid: Option<u32>) -> io::Result<()> {
+        self.record(ReceiverFsOp::Chown {
+            path: path.to_path_buf(),
+            uid,
+            gid,
+        });
+        self.inner.chown(path, uid, gid)
+    }
+
+    fn set_mtime(&self, path: &Path, mtime: FileTime) -> io::Result<()> {
+        self.record(ReceiverFsOp::SetMtime {
+            path: path.to_path_buf(),
+            mtime,
+        });
+        self.inner.set_mtime(path, mtime)
+    }
+}
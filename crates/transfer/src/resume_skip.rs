@@ -0,0 +1,110 @@
+#![deny(unsafe_code)]
+//! Files a reconnecting client already holds (oc-rsync resume extension).
+//!
+//! The daemon fills a [`ResumeSkipList`] from the client's resume manifest
+//! (see [`protocol::daemon_resume`]). While building the file list the
+//! sender drops every regular file whose relative name is listed and whose
+//! contents still hash to the client's digest. A digest mismatch - the file
+//! changed, or the client's copy is incomplete - keeps the file in the list,
+//! so a stale manifest can only cost a transfer, never skip one wrongly.
+//!
+//! Upstream rsync has no equivalent.
+
+use std::collections::HashMap;
+use std::fs::File;
+use std::io::{self, Read};
+use std::path::{Path, PathBuf};
+
+use checksums::strong::Md5;
+
+/// Relative file names mapped to the MD5 digest the client holds.
+#[derive(Clone, Debug, Default, Eq, PartialEq)]
+pub struct ResumeSkipList {
+    entries: HashMap<PathBuf, [u8; 16]>,
+}
+
+impl ResumeSkipList {
+    /// Creates an empty list.
+    #[must_use]
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// Records that the client holds `relative` with contents `digest`.
+    pub fn insert(&mut self, relative: impl Into<PathBuf>, digest: [u8; 16]) {
+        self.entries.insert(relative.into(), digest);
+    }
+
+    /// Number of listed files.
+    #[must_use]
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Reports whether no files are listed.
+    #[must_use]
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+
+    /// Reports whether the regular file at `path`, sent as `relative`, is
+    /// listed and unchanged, so the sender can leave it out.
+    ///
+    /// Unreadable files are never skipped; the normal send path reports
+    /// the error.
+    pub(crate) fn skips(&self, relative: &Path, path: &Path) -> bool {
+        self.entries.get(relative).is_some_and(|expected| {
+            resume_file_digest(path).is_ok_and(|digest| digest == *expected)
+        })
+    }
+}
+
+/// MD5 digest of the file at `path`, as carried in a resume manifest.
+pub fn resume_file_digest(path: &Path) -> io::Result<[u8; 16]> {
+    let mut file = File::open(path)?;
+    let mut hasher = Md5::new();
+    let mut buffer = vec![0u8; 64 * 1024];
+    loop {
+        let read = file.read(&mut buffer)?;
+        if read == 0 {
+            return Ok(hasher.finalize());
+        }
+        hasher.update(&buffer[..read]);
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn skips_only_listed_files_with_matching_contents() {
+        let dir = tempfile::tempdir().expect("tempdir");
+        let held = dir.path().join("held.txt");
+        let changed = dir.path().join("changed.txt");
+        std::fs::write(&held, b"same contents").expect("write held");
+        std::fs::write(&changed, b"new contents").expect("write changed");
+
+        let mut list = ResumeSkipList::new();
+        list.insert("held.txt", Md5::digest(b"same contents"));
+        list.insert("changed.txt", Md5::digest(b"old contents"));
+        list.insert("missing.txt", Md5::digest(b""));
+
+        assert!(list.skips(Path::new("held.txt"), &held));
+        assert!(!list.skips(Path::new("changed.txt"), &changed));
+        assert!(!list.skips(Path::new("missing.txt"), &dir.path().join("missing.txt")));
+        assert!(!list.skips(Path::new("other.txt"), &held));
+    }
+
+    #[test]
+    fn digest_matches_one_shot_md5() {
+        let dir = tempfile::tempdir().expect("tempdir");
+        let path = dir.path().join("big.bin");
+        let data: Vec<u8> = (0..200_000u32).map(|i| (i % 251) as u8).collect();
+        std::fs::write(&path, &data).expect("write");
+        assert_eq!(
+            resume_file_digest(&path).expect("digest"),
+            Md5::digest(&data)
+        );
+    }
+}
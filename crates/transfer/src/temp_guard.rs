@@ -497,6 +497,31 @@ pub fn partial_dir_fname(dest_path: &Path, partial_dir: &Path) -> Option<PathBuf
     Some(partial_path)
 }
 
+/// Removes the `--partial-dir` file left for `dest_path` and rmdir's the
+/// partial directory once it is empty, mirroring upstream
+/// `handle_partial_dir(partialptr, PDIR_DELETE)`.
+///
+/// Best-effort: a missing partial file or a non-empty partial-dir leaves the
+/// filesystem untouched. Absolute `--partial-dir` values are never rmdir'd,
+/// matching upstream `util1.c:1343` (`if (!create && *partial_dir == '/')`).
+///
+/// # Upstream Reference
+///
+/// - `receiver.c:1035-1037` - unlink after a partial-dir basis was committed
+/// - `generator.c:recv_generator()` - unlink when the file is already up to date
+pub fn remove_partial_dir_file(dest_path: &Path, partial_dir: &Path) {
+    let Some(partial) = partial_dir_fname(dest_path, partial_dir) else {
+        return;
+    };
+    let _ = fs::remove_file(&partial);
+    // upstream: handle_partial_dir() only rmdir's a relative partial-dir.
+    if !partial_dir.is_absolute() {
+        if let Some(parent) = partial.parent() {
+            let _ = fs::remove_dir(parent);
+        }
+    }
+}
+
 /// Returns `true` when an I/O error represents a cross-device link (EXDEV).
 fn is_cross_device_error(e: &io::Error) -> bool {
     match e.raw_os_error() {
@@ -1017,6 +1042,48 @@ mod tests {
         );
     }
 
+    #[test]
+    fn remove_partial_dir_file_prunes_emptied_relative_dir() {
+        let dir = tempdir().expect("create temp dir");
+        let dest_path = dir.path().join("file.txt");
+        let partial_dir = dir.path().join(".rsync-partial");
+        fs::create_dir(&partial_dir).unwrap();
+        fs::write(partial_dir.join("file.txt"), b"partial").unwrap();
+
+        remove_partial_dir_file(&dest_path, Path::new(".rsync-partial"));
+
+        assert!(!partial_dir.exists(), "emptied partial-dir must be removed");
+    }
+
+    #[test]
+    fn remove_partial_dir_file_keeps_dir_with_other_partials() {
+        let dir = tempdir().expect("create temp dir");
+        let dest_path = dir.path().join("file.txt");
+        let partial_dir = dir.path().join(".rsync-partial");
+        fs::create_dir(&partial_dir).unwrap();
+        fs::write(partial_dir.join("file.txt"), b"partial").unwrap();
+        fs::write(partial_dir.join("other.txt"), b"still pending").unwrap();
+
+        remove_partial_dir_file(&dest_path, Path::new(".rsync-partial"));
+
+        assert!(!partial_dir.join("file.txt").exists());
+        assert!(partial_dir.join("other.txt").exists());
+    }
+
+    #[test]
+    fn remove_partial_dir_file_never_removes_absolute_dir() {
+        let dir = tempdir().expect("create temp dir");
+        let dest_path = dir.path().join("file.txt");
+        let partial_dir = dir.path().join("partial");
+        fs::create_dir(&partial_dir).unwrap();
+        fs::write(partial_dir.join("file.txt"), b"partial").unwrap();
+
+        remove_partial_dir_file(&dest_path, &partial_dir);
+
+        assert!(!partial_dir.join("file.txt").exists());
+        assert!(partial_dir.is_dir(), "absolute partial-dir must be kept");
+    }
+
     #[test]
     fn rename_to_partial_dir_absolute() {
         let dir = tempdir().expect("create temp dir");
@@ -11,14 +11,17 @@
 //! - `multiplex` - Buffered writer that frames output in `MSG_DATA` multiplex frames.
 //! - `msg_info` - Trait for sending `MSG_INFO` protocol messages through multiplexed streams.
 //! - `counting` - Byte-counting writer wrapper for transfer statistics.
+//! - `sendfile` - Destination socket handle for the zero-copy whole-file sender.
 
 mod counting;
 mod msg_info;
 pub(crate) mod multiplex;
+mod sendfile;
 mod server;
 
 pub use self::counting::CountingWriter;
 pub use self::msg_info::MsgInfoSender;
+pub use self::sendfile::SendfileSocket;
 pub use self::server::{ServerWriter, shutdown_send_side};
 
 #[cfg(test)]
@@ -177,6 +177,51 @@ impl<W: Write> MultiplexWriter<W> {
         self.last_io_out = Instant::now();
         Ok(())
     }
+
+    /// Returns `true` when literal bytes may bypass this writer.
+    ///
+    /// A batch recorder must see every pre-mux byte, so bytes that never pass
+    /// through `write` would be missing from the batch file.
+    pub(crate) fn can_bypass(&self) -> bool {
+        self.batch_recorder.is_none()
+    }
+
+    /// Sends one literal token of `len` bytes read from `source` at `offset`
+    /// directly to `socket_fd` with `sendfile(2)`.
+    ///
+    /// Buffered output is flushed first so the frame lands after everything
+    /// already written through this writer. The frame is the one the buffered
+    /// path produces for a token: a `MSG_DATA` header covering the 4-byte
+    /// token length plus `len` data bytes.
+    ///
+    /// upstream: token.c:simple_send_token() - `write_int(f, n)` then
+    /// `write_buf(f, buf + offset, n)` per literal run.
+    #[cfg(target_os = "linux")]
+    pub(crate) fn send_literal_from_file(
+        &mut self,
+        socket_fd: std::os::fd::RawFd,
+        source: &std::fs::File,
+        offset: u64,
+        len: usize,
+    ) -> io::Result<()> {
+        debug_assert!(
+            self.can_bypass(),
+            "batch recording must not bypass the writer"
+        );
+        self.flush()?;
+
+        let payload_len = u32::try_from(4 + len)
+            .map_err(|_| io::Error::new(io::ErrorKind::InvalidInput, "literal token too large"))?;
+        let header = MessageHeader::new(MessageCode::Data, payload_len)
+            .map_err(|e| io::Error::new(io::ErrorKind::InvalidInput, e))?;
+        let mut frame_header = [0u8; 8];
+        frame_header[..4].copy_from_slice(&header.encode());
+        frame_header[4..].copy_from_slice(&(len as i32).to_le_bytes());
+
+        fast_io::sendfile::send_framed_file_range(socket_fd, &frame_header, source, offset, len)?;
+        self.last_io_out = Instant::now();
+        Ok(())
+    }
 }
 
 impl<W: Write> Write for MultiplexWriter<W> {
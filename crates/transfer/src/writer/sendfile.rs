@@ -0,0 +1,37 @@
+//! Destination socket handle for the zero-copy whole-file sender.
+//!
+//! The generator writes through a `ServerWriter<W>` whose transport is erased
+//! behind `W: Write`, so the socket descriptor is not reachable from the send
+//! loop. A caller that owns a TCP transport hands a cloned `TcpStream` in via
+//! [`SendfileSocket`]; the generator then moves uncompressed literal tokens
+//! straight from the source file onto that socket with `sendfile(2)`.
+
+use std::net::TcpStream;
+use std::sync::Arc;
+
+/// Cloned destination socket for the zero-copy whole-file sender.
+///
+/// Holding the clone keeps the descriptor open for the whole transfer. Both
+/// descriptors reference one kernel socket, so bytes sent through this handle
+/// interleave in order with bytes written through the transfer's writer as
+/// long as the writer is flushed first (see
+/// `MultiplexWriter::send_literal_from_file`).
+///
+/// Only Linux engages the zero-copy path; elsewhere the handle is accepted
+/// and ignored so callers need no platform gate.
+#[derive(Clone, Debug)]
+pub struct SendfileSocket(Arc<TcpStream>);
+
+impl SendfileSocket {
+    /// Wraps a cloned write half of the transfer socket.
+    #[must_use]
+    pub fn new(stream: TcpStream) -> Self {
+        Self(Arc::new(stream))
+    }
+
+    /// Raw descriptor of the wrapped socket.
+    #[cfg(target_os = "linux")]
+    pub(crate) fn raw_fd(&self) -> std::os::fd::RawFd {
+        std::os::fd::AsRawFd::as_raw_fd(&*self.0)
+    }
+}
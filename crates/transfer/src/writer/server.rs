@@ -432,6 +432,49 @@ impl<W: Write> ServerWriter<W> {
         }
     }
 
+    /// Returns `true` when whole-file literals may be sent with `sendfile(2)`
+    /// instead of through this writer.
+    ///
+    /// Only the uncompressed multiplex mode qualifies: compressed tokens must
+    /// pass through the encoder, plain mode is never used for file data, and an
+    /// attached batch recorder must observe every outgoing byte.
+    pub fn supports_sendfile(&self) -> bool {
+        match self {
+            Self::Multiplex(mux) => mux.can_bypass(),
+            Self::Plain(_) | Self::Compressed(_) | Self::Taken => false,
+        }
+    }
+
+    /// Sends one literal token read from `source` at `offset` straight to
+    /// `socket` with `sendfile(2)`.
+    ///
+    /// `socket` must be the transport this writer wraps. The wire bytes match
+    /// what writing `[len as i32 LE][data]` through this writer produces.
+    ///
+    /// # Errors
+    ///
+    /// Returns `Unsupported` when [`Self::supports_sendfile`] is `false` or off
+    /// Linux, or the underlying I/O error.
+    #[cfg_attr(not(target_os = "linux"), allow(unused_variables))]
+    pub(crate) fn send_literal_from_file(
+        &mut self,
+        socket: &super::SendfileSocket,
+        source: &std::fs::File,
+        offset: u64,
+        len: usize,
+    ) -> io::Result<()> {
+        match self {
+            #[cfg(target_os = "linux")]
+            Self::Multiplex(mux) if mux.can_bypass() => {
+                mux.send_literal_from_file(socket.raw_fd(), source, offset, len)
+            }
+            _ => Err(io::Error::new(
+                io::ErrorKind::Unsupported,
+                "sendfile requires an uncompressed multiplex writer",
+            )),
+        }
+    }
+
     /// Writes raw bytes directly to the underlying stream, bypassing multiplexing.
     ///
     /// Used for protocol exchanges like the final goodbye handshake where
@@ -0,0 +1,136 @@
+//! `--chown=USER:GROUP` forces one owner and group onto every received entry.
+//!
+//! `--chown` is shorthand for `--usermap=*:USER --groupmap=*:GROUP`, so the
+//! override reaches a remote receiver through the mapping arguments rather
+//! than only the local copy path. Each test sends a small tree with numeric
+//! ids that no source file carries, locally, as a push and as a pull (both
+//! sides oc-rsync, the remote behind a shell shim), then checks that every
+//! destination entry is owned by that user and group.
+//!
+//! Upstream reference: `options.c:parse_arguments()` `OPT_CHOWN` - sets
+//! `usermap = "*:USER"` / `groupmap = "*:GROUP"` and turns on
+//! `preserve_uid` / `preserve_gid`.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - Not running as root (only root can give files away).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{is_root, spawn_with_timeout, src_dest_fixture, write_rsh_shim};
+use std::fs;
+use std::os::unix::fs::MetadataExt;
+use std::path::Path;
+use std::process::Command;
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Ids unlikely to own anything in the scratch tree beforehand.
+const CHOWN_UID: u32 = 43_210;
+const CHOWN_GID: u32 = 43_211;
+
+const NEEDS_ROOT: &str = "--chown to another user requires root";
+
+/// Builds `src/{top.txt,sub/nested.txt}`.
+fn populate(src: &Path) {
+    fs::create_dir_all(src.join("sub")).unwrap();
+    fs::write(src.join("top.txt"), b"top\n").unwrap();
+    fs::write(src.join("sub/nested.txt"), b"nested\n").unwrap();
+}
+
+fn run_chown(oc_rsync: &Path, shim: Option<&Path>, src: &str, dest: &str) {
+    let mut cmd = Command::new(oc_rsync);
+    cmd.arg("-r")
+        .arg(format!("--chown={CHOWN_UID}:{CHOWN_GID}"));
+    if let Some(shim) = shim {
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+    }
+    cmd.arg(src).arg(dest);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "{src} -> {dest} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+}
+
+fn assert_owned_by_chown(dest: &Path) {
+    for rel in ["top.txt", "sub", "sub/nested.txt"] {
+        let metadata = fs::symlink_metadata(dest.join(rel))
+            .unwrap_or_else(|error| panic!("{rel} not transferred: {error}"));
+        assert_eq!(
+            (metadata.uid(), metadata.gid()),
+            (CHOWN_UID, CHOWN_GID),
+            "{rel} does not carry the --chown owner and group"
+        );
+    }
+}
+
+#[test]
+fn local_chown_sets_owner_and_group() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "local --chown",
+        tmp.path(),
+        (!is_root()).then_some(NEEDS_ROOT),
+        populate,
+    ) else {
+        return;
+    };
+    run_chown(
+        &oc_rsync,
+        None,
+        &format!("{}/", src.display()),
+        &format!("{}/", dest.display()),
+    );
+    assert_owned_by_chown(&dest);
+}
+
+#[test]
+fn push_chown_sets_owner_and_group() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "push --chown",
+        tmp.path(),
+        (!is_root()).then_some(NEEDS_ROOT),
+        populate,
+    ) else {
+        return;
+    };
+    let shim = write_rsh_shim(tmp.path());
+    run_chown(
+        &oc_rsync,
+        Some(&shim),
+        &format!("{}/", src.display()),
+        &format!("phantom-host:{}/", dest.display()),
+    );
+    assert_owned_by_chown(&dest);
+}
+
+#[test]
+fn pull_chown_sets_owner_and_group() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "pull --chown",
+        tmp.path(),
+        (!is_root()).then_some(NEEDS_ROOT),
+        populate,
+    ) else {
+        return;
+    };
+    let shim = write_rsh_shim(tmp.path());
+    run_chown(
+        &oc_rsync,
+        Some(&shim),
+        &format!("phantom-host:{}/", src.display()),
+        &format!("{}/", dest.display()),
+    );
+    assert_owned_by_chown(&dest);
+}
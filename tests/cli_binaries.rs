@@ -249,6 +249,144 @@ fn daemon_rejects_unknown_flag() {
     }
 }
 
+/// Writes a minimal daemon configuration under `dir` and returns the config
+/// and pid file paths.
+#[cfg(unix)]
+fn write_detach_test_config(dir: &std::path::Path) -> (PathBuf, PathBuf) {
+    let module = dir.join("module");
+    fs::create_dir_all(&module).expect("create module dir");
+    let pid_file = dir.join("rsyncd.pid");
+    let config = dir.join("rsyncd.conf");
+    fs::write(
+        &config,
+        format!(
+            "pid file = {}\nlog file = {}\nuse chroot = false\n\n[data]\npath = {}\n",
+            pid_file.display(),
+            dir.join("rsyncd.log").display(),
+            module.display()
+        ),
+    )
+    .expect("write daemon config");
+    (config, pid_file)
+}
+
+/// Builds a `--daemon` command listening on a free loopback port.
+#[cfg(unix)]
+fn daemon_command(binary: &str, config: &std::path::Path, detach: bool) -> (Command, u16) {
+    let port = std::net::TcpListener::bind("127.0.0.1:0")
+        .and_then(|listener| listener.local_addr())
+        .expect("allocate port")
+        .port();
+    let mut command = binary_command(binary);
+    command
+        .arg("--daemon")
+        .arg(if detach { "--detach" } else { "--no-detach" })
+        .arg(format!("--config={}", config.display()))
+        .arg("--address=127.0.0.1")
+        .arg(format!("--port={port}"))
+        .stdin(std::process::Stdio::null())
+        .stdout(std::process::Stdio::null())
+        .stderr(std::process::Stdio::null());
+    (command, port)
+}
+
+/// Connects to the daemon on `port` and returns its greeting line.
+#[cfg(unix)]
+fn read_daemon_greeting(port: u16) -> String {
+    use std::io::{BufRead, BufReader};
+    use std::time::{Duration, Instant};
+
+    let deadline = Instant::now() + Duration::from_secs(10);
+    loop {
+        match std::net::TcpStream::connect(("127.0.0.1", port)) {
+            Ok(stream) => {
+                stream
+                    .set_read_timeout(Some(Duration::from_secs(5)))
+                    .expect("read timeout");
+                let mut line = String::new();
+                BufReader::new(stream)
+                    .read_line(&mut line)
+                    .expect("read greeting");
+                return line;
+            }
+            Err(_) if Instant::now() < deadline => {
+                std::thread::sleep(Duration::from_millis(50));
+            }
+            Err(error) => panic!("daemon never accepted on port {port}: {error}"),
+        }
+    }
+}
+
+#[cfg(unix)]
+#[test]
+fn daemon_no_detach_stays_in_foreground() {
+    let temp = tempfile::tempdir().expect("tempdir");
+    let (config, pid_file) = write_detach_test_config(temp.path());
+    let (mut command, port) = daemon_command(DAEMON_PROGRAM_NAME, &config, false);
+    let mut child = command.spawn().expect("spawn daemon");
+
+    let greeting = read_daemon_greeting(port);
+    let still_running = child.try_wait().expect("poll daemon").is_none();
+    let recorded_pid = fs::read_to_string(&pid_file).unwrap_or_default();
+    let _ = child.kill();
+    let _ = child.wait();
+
+    assert!(
+        greeting.starts_with("@RSYNCD: "),
+        "got greeting {greeting:?}"
+    );
+    assert!(
+        still_running,
+        "--no-detach daemon must keep serving in the spawned process"
+    );
+    if cargo_target_runner().is_none() {
+        assert_eq!(
+            recorded_pid.trim(),
+            child.id().to_string(),
+            "--no-detach must not fork: the pid file names the spawned process"
+        );
+    }
+}
+
+#[cfg(unix)]
+#[test]
+fn daemon_detach_returns_while_child_serves() {
+    use std::time::{Duration, Instant};
+
+    let temp = tempfile::tempdir().expect("tempdir");
+    let (config, pid_file) = write_detach_test_config(temp.path());
+    let (mut command, port) = daemon_command(DAEMON_PROGRAM_NAME, &config, true);
+    let mut parent = command.spawn().expect("spawn daemon");
+
+    let deadline = Instant::now() + Duration::from_secs(10);
+    let status = loop {
+        if let Some(status) = parent.try_wait().expect("poll daemon parent") {
+            break status;
+        }
+        if Instant::now() >= deadline {
+            let _ = parent.kill();
+            panic!("detaching daemon parent did not return");
+        }
+        std::thread::sleep(Duration::from_millis(20));
+    };
+    assert!(status.success(), "detaching parent exited with {status}");
+
+    let greeting = read_daemon_greeting(port);
+    let child_pid = fs::read_to_string(&pid_file).expect("read pid file");
+    let child_pid = child_pid.trim();
+    let _ = Command::new("kill").arg(child_pid).status();
+
+    assert!(
+        greeting.starts_with("@RSYNCD: "),
+        "got greeting {greeting:?}"
+    );
+    assert_ne!(
+        child_pid,
+        parent.id().to_string(),
+        "the serving daemon must be the forked child"
+    );
+}
+
 fn binary_command(name: &str) -> Command {
     let binary = locate_binary(name)
         .unwrap_or_else(|| panic!("failed to locate {name} binary for integration testing"));
@@ -0,0 +1,192 @@
+//! Interop test: device numbers survive a transfer between upstream rsync and
+//! oc-rsync on every legacy and current protocol.
+//!
+//! The file list carries a device's rdev as a major/minor pair whose encoding
+//! changed over time: protocols 28-29 send the major as a 4-byte int and the
+//! minor as either a byte (`XMIT_RDEV_MINOR_8_pre30`) or a 4-byte int, while
+//! protocol 30+ uses varints for both. The devices created here use numbers
+//! that do not fit the 8-bit fields of the old `dev_t` layout, so a wrong
+//! width or a lost `XMIT_SAME_RDEV_MAJOR` carry shows up as a mismatched
+//! node on the destination.
+//!
+//! Each protocol is exercised in both directions with upstream as the client
+//! and oc-rsync as the remote over a shell shim: a push makes oc-rsync decode
+//! upstream's file list, a pull makes upstream decode oc-rsync's.
+//!
+//! Upstream reference: `flist.c:send_file_entry()` and
+//! `flist.c:recv_file_entry()` for the rdev fields.
+//!
+//! Protocol 27 is not exercised: oc-rsync's oldest supported protocol is 28.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Linux (the device numbers exceed the BSD `dev_t` major field).
+//! - Not running as root (`mknod` for devices needs `CAP_MKNOD`).
+//! - No upstream `rsync` available (env `OC_RSYNC_UPSTREAM`, then
+//!   `target/interop/upstream-install/<version>/bin/rsync`, then `which rsync`).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(target_os = "linux")]
+
+mod integration;
+
+use integration::helpers::{
+    is_root, locate_binary, locate_upstream_rsync, spawn_with_timeout, write_rsh_shim,
+};
+use std::fs;
+use std::os::unix::fs::{FileTypeExt, MetadataExt};
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Protocols negotiated with `--protocol`; 28 is the oldest oc-rsync speaks.
+const PROTOCOLS: [u32; 4] = [28, 29, 30, 31];
+
+/// `(name, mknod type, major, minor)` for each device node in the source.
+/// Two nodes share major 259 so the second rides on `XMIT_SAME_RDEV_MAJOR`.
+const DEVICES: [(&str, &str, u32, u32); 4] = [
+    ("null", "c", 1, 3),
+    ("wide_minor", "c", 259, 65538),
+    ("same_major", "b", 259, 17),
+    ("wide_major", "b", 4000, 300),
+];
+
+/// Splits a Linux `dev_t` the way glibc's `major()`/`minor()` do.
+fn major_minor(rdev: u64) -> (u32, u32) {
+    let major = ((rdev >> 8) & 0xfff) | ((rdev >> 32) & !0xfff);
+    let minor = (rdev & 0xff) | ((rdev >> 12) & !0xff);
+    (major as u32, minor as u32)
+}
+
+/// Binaries and shim for one test, or `None` when the test should skip.
+fn interop_setup(test: &str, root: &Path) -> Option<(PathBuf, PathBuf, PathBuf)> {
+    if !is_root() {
+        eprintln!("skipping {test}: creating device nodes requires root");
+        return None;
+    }
+    let Some(upstream) = locate_upstream_rsync() else {
+        eprintln!(
+            "skipping {test}: no upstream rsync found \
+             (set OC_RSYNC_UPSTREAM or install rsync)"
+        );
+        return None;
+    };
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping {test}: oc-rsync binary not built");
+        return None;
+    };
+    Some((upstream, oc_rsync, write_rsh_shim(root)))
+}
+
+/// Creates the [`DEVICES`] nodes in `src`, returning `false` when `mknod` is
+/// refused (e.g. root inside a container without `CAP_MKNOD`).
+fn populate_devices(src: &Path) -> bool {
+    fs::create_dir_all(src).unwrap();
+    for (name, kind, major, minor) in DEVICES {
+        let status = Command::new("mknod")
+            .arg(src.join(name))
+            .arg(kind)
+            .arg(major.to_string())
+            .arg(minor.to_string())
+            .stderr(Stdio::null())
+            .status();
+        if !matches!(status, Ok(s) if s.success()) {
+            return false;
+        }
+    }
+    true
+}
+
+fn assert_devices_match(dst: &Path, label: &str) {
+    for (name, kind, major, minor) in DEVICES {
+        let path = dst.join(name);
+        let metadata = fs::symlink_metadata(&path)
+            .unwrap_or_else(|error| panic!("{label}: {name} missing from destination: {error}"));
+        let file_type = metadata.file_type();
+        if kind == "c" {
+            assert!(
+                file_type.is_char_device(),
+                "{label}: {name} not a char device"
+            );
+        } else {
+            assert!(
+                file_type.is_block_device(),
+                "{label}: {name} not a block device"
+            );
+        }
+        assert_eq!(
+            major_minor(metadata.rdev()),
+            (major, minor),
+            "{label}: {name} major/minor differ"
+        );
+    }
+}
+
+fn run_upstream(
+    upstream: &Path,
+    shim: &Path,
+    oc_rsync: &Path,
+    protocol: u32,
+    src: &str,
+    dst: &str,
+) {
+    let mut cmd = Command::new(upstream);
+    cmd.arg("-rD")
+        .arg(format!("--protocol={protocol}"))
+        .arg(format!("--rsh={}", shim.display()))
+        .arg(format!("--rsync-path={}", oc_rsync.display()))
+        .arg(src)
+        .arg(dst);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("upstream rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "upstream rsync --protocol={protocol} failed with {:?}\nstdout:\n{}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stdout),
+        String::from_utf8_lossy(&output.stderr)
+    );
+}
+
+/// Runs every protocol in one direction; `pull` selects which side is remote.
+fn run_direction(test: &str, pull: bool) {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let root = tmp.path();
+    let Some((upstream, oc_rsync, shim)) = interop_setup(test, root) else {
+        return;
+    };
+    let src = root.join("src");
+    if !populate_devices(&src) {
+        eprintln!("skipping {test}: mknod refused (no CAP_MKNOD?)");
+        return;
+    }
+
+    for protocol in PROTOCOLS {
+        let dst = root.join(format!("dst{protocol}"));
+        fs::create_dir_all(&dst).unwrap();
+        let (src_arg, dst_arg) = if pull {
+            (
+                format!("phantom-host:{}/", src.display()),
+                format!("{}/", dst.display()),
+            )
+        } else {
+            (
+                format!("{}/", src.display()),
+                format!("phantom-host:{}/", dst.display()),
+            )
+        };
+        run_upstream(&upstream, &shim, &oc_rsync, protocol, &src_arg, &dst_arg);
+        assert_devices_match(&dst, &format!("{test} protocol {protocol}"));
+    }
+}
+
+#[test]
+fn upstream_push_devices_to_oc_rsync_preserves_rdev() {
+    run_direction("device push", false);
+}
+
+#[test]
+fn upstream_pull_devices_from_oc_rsync_preserves_rdev() {
+    run_direction("device pull", true);
+}
@@ -0,0 +1,116 @@
+//! `-a src/ dest/` gives `dest` the mode and mtime of `src`.
+//!
+//! A trailing-slash source sends the directory itself as the `.` entry ahead
+//! of its contents, and the receiver applies that entry's attributes to the
+//! destination directory once its children are written, whether the
+//! directory existed beforehand or was created. Each case runs locally, as a
+//! push (local sender) and as a pull (oc-rsync `--server --sender` behind a
+//! shell shim).
+//!
+//! Upstream reference: `flist.c:send_file_list()` sends the `.` entry for a
+//! trailing-slash argument, and `generator.c:touch_up_dirs()` sets directory
+//! times after the transfer so writing the children does not bump them.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout, write_rsh_shim};
+use std::fs;
+use std::os::unix::fs::{MetadataExt, PermissionsExt};
+use std::path::Path;
+use std::process::Command;
+use std::time::{Duration, UNIX_EPOCH};
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+const SOURCE_MODE: u32 = 0o751;
+const SOURCE_MTIME: u64 = 1_600_000_000;
+
+#[derive(Clone, Copy, Debug)]
+enum Mode {
+    Local,
+    Push,
+    Pull,
+}
+
+/// Runs `oc-rsync -a <src>/ <dest>/` with the remote side, if any, behind
+/// the shim, and asserts success.
+fn run(oc_rsync: &Path, root: &Path, src: &Path, dest: &Path, mode: Mode) {
+    let src_arg = format!("{}/", src.display());
+    let dest_arg = format!("{}/", dest.display());
+    let mut cmd = Command::new(oc_rsync);
+    cmd.arg("-a");
+    let (src_arg, dest_arg) = match mode {
+        Mode::Local => (src_arg, dest_arg),
+        Mode::Push => (src_arg, format!("phantom-host:{dest_arg}")),
+        Mode::Pull => (format!("phantom-host:{src_arg}"), dest_arg),
+    };
+    if !matches!(mode, Mode::Local) {
+        let shim = write_rsh_shim(root);
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+    }
+    cmd.arg(&src_arg).arg(&dest_arg);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "{mode:?} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+}
+
+fn set_dir_attributes(dir: &Path, mode: u32, mtime: u64) {
+    fs::set_permissions(dir, fs::Permissions::from_mode(mode)).expect("set dir mode");
+    fs::File::open(dir)
+        .and_then(|handle| handle.set_modified(UNIX_EPOCH + Duration::from_secs(mtime)))
+        .expect("set dir mtime");
+}
+
+/// Source root with a file and a subdirectory, so writing the children would
+/// bump the destination root's mtime if it were set too early.
+fn build_source(src: &Path) {
+    fs::create_dir_all(src.join("sub")).unwrap();
+    fs::write(src.join("file.txt"), b"root file\n").unwrap();
+    fs::write(src.join("sub/nested.txt"), b"nested\n").unwrap();
+    set_dir_attributes(src, SOURCE_MODE, SOURCE_MTIME);
+}
+
+#[test]
+fn dest_root_takes_the_source_root_mode_and_mtime() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping dot root entry: oc-rsync binary not built");
+        return;
+    };
+    for mode in [Mode::Local, Mode::Push, Mode::Pull] {
+        for dest_exists in [false, true] {
+            let context = format!("{mode:?} dest_exists={dest_exists}");
+            let tmp = tempfile::tempdir().expect("create tempdir");
+            let root = tmp.path();
+            let src = root.join("src");
+            let dest = root.join("dest");
+            build_source(&src);
+            if dest_exists {
+                fs::create_dir_all(&dest).unwrap();
+                set_dir_attributes(&dest, 0o700, SOURCE_MTIME + 86_400);
+            }
+
+            run(&oc_rsync, root, &src, &dest, mode);
+
+            let meta = fs::metadata(&dest).expect("dest metadata");
+            assert_eq!(meta.mode() & 0o7777, SOURCE_MODE, "{context}");
+            assert_eq!(meta.mtime(), SOURCE_MTIME as i64, "{context}");
+            assert_eq!(
+                fs::read(dest.join("sub/nested.txt")).unwrap(),
+                b"nested\n",
+                "{context}"
+            );
+        }
+    }
+}
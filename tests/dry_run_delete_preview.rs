@@ -0,0 +1,155 @@
+//! `-nv --delete` as a preview of what `--delete` would remove.
+//!
+//! With `--dry-run` the delete pass still runs and logs a `deleting` line for
+//! every extraneous destination entry, including the contents of an
+//! extraneous directory, but nothing is removed. The pass runs on whichever
+//! side receives: the local engine, the client on a pull, or the server on a
+//! push (which forwards each name back as `MSG_DELETED`), so all three are
+//! exercised. The remote cases point `--rsh` at a shell shim so both ends are
+//! oc-rsync.
+//!
+//! Upstream reference: `delete.c:delete_item()` logs through `log_delete()`,
+//! and `syscall.c:do_unlink()`/`do_rmdir()` return success without touching
+//! the filesystem under `dry_run`.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout, write_rsh_shim};
+use std::fs;
+use std::path::PathBuf;
+use std::process::Command;
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Lines a preview must print, in any order.
+const EXPECTED_DELETIONS: [&str; 4] = [
+    "deleting stale.txt",
+    "deleting stale_dir/",
+    "deleting stale_dir/a.txt",
+    "deleting stale_dir/b.txt",
+];
+
+/// How the source and destination operands reach the other end.
+#[derive(Clone, Copy, Debug)]
+enum Transport {
+    Local,
+    Pull,
+    Push,
+}
+
+/// A source holding `keep.txt` and a destination that additionally holds an
+/// extraneous file and an extraneous directory with two files.
+struct Fixture {
+    _tmp: tempfile::TempDir,
+    root: PathBuf,
+    oc_rsync: PathBuf,
+    shim: PathBuf,
+}
+
+impl Fixture {
+    fn new(test: &str) -> Option<Self> {
+        let Some(oc_rsync) = locate_binary("oc-rsync") else {
+            eprintln!("skipping {test}: oc-rsync binary not built");
+            return None;
+        };
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path().to_path_buf();
+        fs::create_dir_all(root.join("src")).unwrap();
+        fs::write(root.join("src/keep.txt"), b"keep\n").unwrap();
+        fs::create_dir_all(root.join("dest/stale_dir")).unwrap();
+        fs::write(root.join("dest/keep.txt"), b"keep\n").unwrap();
+        fs::write(root.join("dest/stale.txt"), b"stale\n").unwrap();
+        fs::write(root.join("dest/stale_dir/a.txt"), b"a\n").unwrap();
+        fs::write(root.join("dest/stale_dir/b.txt"), b"b\n").unwrap();
+        let shim = write_rsh_shim(&root);
+        Some(Self {
+            _tmp: tmp,
+            root,
+            oc_rsync,
+            shim,
+        })
+    }
+
+    /// Runs `oc-rsync -rnv --delete src/ dest/` over `transport` and returns
+    /// its stdout.
+    fn preview(&self, transport: Transport) -> String {
+        let src = format!("{}/", self.root.join("src").display());
+        let dest = format!("{}/", self.root.join("dest").display());
+        let (src, dest) = match transport {
+            Transport::Local => (src, dest),
+            Transport::Pull => (format!("phantom-host:{src}"), dest),
+            Transport::Push => (src, format!("phantom-host:{dest}")),
+        };
+
+        let mut cmd = Command::new(&self.oc_rsync);
+        cmd.args(["-rnv", "--delete"]);
+        if !matches!(transport, Transport::Local) {
+            cmd.arg(format!("--rsh={}", self.shim.display()))
+                .arg(format!("--rsync-path={}", self.oc_rsync.display()));
+        }
+        cmd.arg(&src).arg(&dest);
+        let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+            .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+        assert!(
+            output.status.success(),
+            "{transport:?} preview failed with {:?}\nstderr:\n{}",
+            output.status,
+            String::from_utf8_lossy(&output.stderr)
+        );
+        String::from_utf8_lossy(&output.stdout).into_owned()
+    }
+
+    fn assert_preview(&self, transport: Transport) {
+        let stdout = self.preview(transport);
+        let lines: Vec<&str> = stdout.lines().map(str::trim_end).collect();
+        for expected in EXPECTED_DELETIONS {
+            assert!(
+                lines.contains(&expected),
+                "{transport:?}: missing {expected:?} in preview:\n{stdout}"
+            );
+        }
+        assert!(
+            !lines
+                .iter()
+                .any(|line| line.starts_with("deleting keep.txt")),
+            "{transport:?}: a source file was listed for deletion:\n{stdout}"
+        );
+
+        let dest = self.root.join("dest");
+        assert_eq!(fs::read(dest.join("stale.txt")).unwrap(), b"stale\n");
+        assert_eq!(fs::read(dest.join("stale_dir/a.txt")).unwrap(), b"a\n");
+        assert_eq!(fs::read(dest.join("stale_dir/b.txt")).unwrap(), b"b\n");
+        assert_eq!(fs::read(dest.join("keep.txt")).unwrap(), b"keep\n");
+    }
+}
+
+#[test]
+fn local_dry_run_delete_previews_without_deleting() {
+    let Some(fx) = Fixture::new("local -n --delete") else {
+        return;
+    };
+    fx.assert_preview(Transport::Local);
+}
+
+#[test]
+fn pull_dry_run_delete_previews_without_deleting() {
+    let Some(fx) = Fixture::new("pull -n --delete") else {
+        return;
+    };
+    fx.assert_preview(Transport::Pull);
+}
+
+#[test]
+fn push_dry_run_delete_previews_without_deleting() {
+    let Some(fx) = Fixture::new("push -n --delete") else {
+        return;
+    };
+    fx.assert_preview(Transport::Push);
+}
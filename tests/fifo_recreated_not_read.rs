@@ -0,0 +1,175 @@
+//! A source FIFO is recreated as a node and never opened for its content.
+//!
+//! rsync has no way to transfer what flows through a named pipe: with
+//! `--specials` (implied by `-a`) the receiver makes a new FIFO with the
+//! source's permissions, and without it the FIFO is skipped. Either way the
+//! sender must not open the pipe, because a blocking `open(2)` of a FIFO with
+//! no writer never returns. Each case runs locally and as a push to oc-rsync
+//! `--server` behind a shell shim, under a timeout that catches a hang.
+//!
+//! Upstream reference: `flist.c:make_file()` sends specials as mode-only
+//! entries, and `generator.c:recv_generator()` recreates them with
+//! `atomic_create()` (`do_mknod()` of an `S_IFIFO` node). `sender.c` is only
+//! ever asked for regular files.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - Not root.
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{is_root, locate_binary, spawn_with_timeout, write_rsh_shim};
+use std::fs;
+use std::os::unix::fs::{FileTypeExt, PermissionsExt};
+use std::path::{Path, PathBuf};
+use std::process::Command;
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Runs `oc-rsync <flags> <src>/ <dest>/`, locally or as a push through the
+/// shim, and asserts success. A sender blocked opening a FIFO trips the
+/// timeout.
+fn run(oc_rsync: &Path, root: &Path, flags: &[&str], src: &Path, dest: &Path, push: bool) {
+    let mut cmd = Command::new(oc_rsync);
+    cmd.args(flags);
+    let dest_arg = if push {
+        let shim = write_rsh_shim(root);
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+        format!("phantom-host:{}/", dest.display())
+    } else {
+        format!("{}/", dest.display())
+    };
+    let src_arg = format!("{}/", src.display());
+    cmd.arg(&src_arg).arg(&dest_arg);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT).unwrap_or_else(|error| {
+        panic!("{flags:?} push={push} did not finish: {error}; was a FIFO opened?")
+    });
+    assert!(
+        output.status.success(),
+        "{flags:?} push={push} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+}
+
+fn mkfifo(path: &Path, mode: &str) {
+    let status = Command::new("mkfifo")
+        .args(["-m", mode])
+        .arg(path)
+        .status()
+        .expect("run mkfifo");
+    assert!(status.success(), "mkfifo failed");
+}
+
+/// Source tree shared by every case: a writer-less FIFO, a symlink to it and
+/// a regular file that must still arrive.
+fn build_source(src: &Path) {
+    fs::create_dir_all(src).unwrap();
+    mkfifo(&src.join("pipe"), "640");
+    std::os::unix::fs::symlink("pipe", src.join("link")).unwrap();
+    fs::write(src.join("file"), b"regular payload\n").unwrap();
+}
+
+fn assert_is_fifo(path: &Path, mode: u32, context: &str) {
+    let meta = fs::symlink_metadata(path)
+        .unwrap_or_else(|e| panic!("{} missing ({context}): {e}", path.display()));
+    assert!(
+        meta.file_type().is_fifo(),
+        "{} must be recreated as a FIFO ({context})",
+        path.display()
+    );
+    assert_eq!(meta.permissions().mode() & 0o7777, mode, "{context}");
+}
+
+/// The oc-rsync binary, or why the test should skip.
+fn oc_rsync_or_skip() -> Result<PathBuf, &'static str> {
+    if !is_root() {
+        return Err("needs root");
+    }
+    locate_binary("oc-rsync").ok_or("oc-rsync binary not built")
+}
+
+#[test]
+fn fifo_is_recreated_without_reading_it() {
+    let oc_rsync = match oc_rsync_or_skip() {
+        Ok(path) => path,
+        Err(reason) => {
+            eprintln!("skipping fifo recreation: {reason}");
+            return;
+        }
+    };
+    // `-c` makes the sender checksum every regular file while building the
+    // list; `-L` turns `link` into a second FIFO entry.
+    let cases: [(&[&str], bool); 3] = [
+        (&["-a"], false),
+        (&["-ac", "--no-whole-file"], false),
+        (&["-aL"], true),
+    ];
+    for (flags, link_is_fifo) in cases {
+        for push in [false, true] {
+            let context = format!("{flags:?} push={push}");
+            let tmp = tempfile::tempdir().expect("create tempdir");
+            let root = tmp.path();
+            let src = root.join("src");
+            let dest = root.join("dest");
+            build_source(&src);
+            fs::create_dir_all(&dest).unwrap();
+
+            run(&oc_rsync, root, flags, &src, &dest, push);
+
+            assert_is_fifo(&dest.join("pipe"), 0o640, &context);
+            if link_is_fifo {
+                assert_is_fifo(&dest.join("link"), 0o640, &context);
+            } else {
+                assert!(
+                    fs::symlink_metadata(dest.join("link"))
+                        .unwrap()
+                        .file_type()
+                        .is_symlink(),
+                    "{context}"
+                );
+            }
+            assert_eq!(
+                fs::read(dest.join("file")).unwrap(),
+                b"regular payload\n",
+                "{context}"
+            );
+        }
+    }
+}
+
+#[test]
+fn fifo_is_skipped_without_specials() {
+    let oc_rsync = match oc_rsync_or_skip() {
+        Ok(path) => path,
+        Err(reason) => {
+            eprintln!("skipping fifo skip: {reason}");
+            return;
+        }
+    };
+    for push in [false, true] {
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path();
+        let src = root.join("src");
+        let dest = root.join("dest");
+        build_source(&src);
+        fs::create_dir_all(&dest).unwrap();
+
+        run(&oc_rsync, root, &["-rt"], &src, &dest, push);
+
+        assert!(
+            fs::symlink_metadata(dest.join("pipe")).is_err(),
+            "a FIFO must not be created without --specials (push={push})"
+        );
+        assert_eq!(
+            fs::read(dest.join("file")).unwrap(),
+            b"regular payload\n",
+            "push={push}"
+        );
+    }
+}
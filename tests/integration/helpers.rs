@@ -237,7 +237,7 @@ impl RsyncCommand {
 }
 
 /// Locate the test binary.
-fn locate_binary(name: &str) -> Option<PathBuf> {
+pub fn locate_binary(name: &str) -> Option<PathBuf> {
     // Try CARGO_BIN_EXE_<name> first
     let env_var = format!("CARGO_BIN_EXE_{name}");
     if let Some(path) = env::var_os(&env_var) {
@@ -271,6 +271,86 @@ fn locate_binary(name: &str) -> Option<PathBuf> {
     None
 }
 
+/// Write a `--rsh` shim into `dir` that runs the remote command locally.
+///
+/// oc-rsync invokes it as `<shim> [-l user] <host> <rsync-path> --server ...`;
+/// the shim drops the SSH-style options and the host, then execs the rest.
+#[cfg(unix)]
+pub fn write_rsh_shim(dir: &Path) -> PathBuf {
+    use std::os::unix::fs::PermissionsExt;
+
+    let script = dir.join("fake_rsh.sh");
+    let body = "#!/bin/sh\n\
+                while [ $# -gt 0 ]; do\n\
+                case \"$1\" in\n\
+                -l) shift 2 ;;\n\
+                -*) shift ;;\n\
+                *) break ;;\n\
+                esac\n\
+                done\n\
+                # $1 is the host placeholder; discard.\n\
+                shift\n\
+                exec \"$@\"\n";
+    fs::write(&script, body).expect("write fake rsh shim");
+    fs::set_permissions(&script, fs::Permissions::from_mode(0o755)).expect("chmod fake rsh shim");
+    script
+}
+
+/// Effective-UID probe via `id -u`, keeping the tests free of `unsafe`.
+///
+/// A failure to run `id` counts as not root.
+pub fn is_root() -> bool {
+    match Command::new("id").arg("-u").output() {
+        Ok(o) if o.status.success() => {
+            let s = String::from_utf8_lossy(&o.stdout);
+            s.trim().parse::<u32>().map(|v| v == 0).unwrap_or(false)
+        }
+        _ => false,
+    }
+}
+
+/// Locates oc-rsync and builds `root/src`, filled by `populate`, next to an
+/// empty `root/dest`, returning `(oc-rsync, src, dest)`.
+///
+/// Returns `None` after printing why `test` is skipped: `skip` is a reason
+/// known up front (such as a privilege the runner lacks), and otherwise the
+/// binary may not have been built.
+pub fn src_dest_fixture(
+    test: &str,
+    root: &Path,
+    skip: Option<&str>,
+    populate: impl FnOnce(&Path),
+) -> Option<(PathBuf, PathBuf, PathBuf)> {
+    if let Some(reason) = skip {
+        eprintln!("skipping {test}: {reason}");
+        return None;
+    }
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping {test}: oc-rsync binary not built");
+        return None;
+    };
+    let src = root.join("src");
+    fs::create_dir_all(&src).unwrap();
+    populate(&src);
+    let dest = root.join("dest");
+    fs::create_dir_all(&dest).unwrap();
+    Some((oc_rsync, src, dest))
+}
+
+/// Reads a `--stats` counter such as `Literal data: 1,234 bytes`.
+pub fn stats_bytes(stdout: &str, label: &str) -> u64 {
+    let line = stdout
+        .lines()
+        .find_map(|line| line.trim().strip_prefix(label))
+        .unwrap_or_else(|| panic!("no '{label}' line in --stats output:\n{stdout}"));
+    line.trim_start_matches(':')
+        .split_whitespace()
+        .next()
+        .map(|value| value.replace(',', ""))
+        .and_then(|value| value.parse().ok())
+        .unwrap_or_else(|| panic!("unparsable '{label}' line: {line}"))
+}
+
 /// Get cargo target runner if configured.
 fn cargo_target_runner() -> Option<Vec<String>> {
     let target = env::var("TARGET").ok()?;
@@ -723,3 +803,29 @@ pub fn upstream_rsync_binary(version: &str) -> Option<PathBuf> {
     ));
     if path.is_file() { Some(path) } else { None }
 }
+
+/// Locate any upstream rsync: `OC_RSYNC_UPSTREAM` first, then the interop
+/// installs newest first, then `rsync` on `PATH`.
+pub fn locate_upstream_rsync() -> Option<PathBuf> {
+    if let Some(path) = env::var_os("OC_RSYNC_UPSTREAM").map(PathBuf::from)
+        && path.is_file()
+    {
+        return Some(path);
+    }
+    if let Some(path) = ["3.4.2", "3.4.1", "3.1.3", "3.0.9"]
+        .into_iter()
+        .find_map(upstream_rsync_binary)
+    {
+        return Some(path);
+    }
+    let which = Command::new("sh")
+        .arg("-c")
+        .arg("command -v rsync 2>/dev/null")
+        .output()
+        .ok()?;
+    if !which.status.success() {
+        return None;
+    }
+    let path = PathBuf::from(String::from_utf8(which.stdout).ok()?.trim());
+    if path.is_file() { Some(path) } else { None }
+}
@@ -194,6 +194,59 @@ fn recursive_required_for_directory_contents() {
     assert!(!dest_dir.join("subdir/file.txt").exists());
 }
 
+#[test]
+fn archive_with_no_recursive_copies_only_top_level_entries() {
+    // upstream: options.c - `-a` sets recurse in argv order, so a later
+    // `--no-r` wins and directory operands are skipped, not descended into.
+    let test_dir = TestDir::new().expect("create test dir");
+    let src_dir = test_dir.mkdir("src").unwrap();
+    let dest_dir = test_dir.mkdir("dest").unwrap();
+
+    fs::write(src_dir.join("top.txt"), b"top").unwrap();
+    fs::create_dir(src_dir.join("subdir")).unwrap();
+    fs::write(src_dir.join("subdir/nested.txt"), b"nested").unwrap();
+
+    let mut cmd = RsyncCommand::new();
+    cmd.args([
+        "-a",
+        "--no-r",
+        src_dir.join("top.txt").to_str().unwrap(),
+        src_dir.join("subdir").to_str().unwrap(),
+        &format!("{}/", dest_dir.display()),
+    ]);
+    cmd.assert_success();
+
+    assert_eq!(fs::read(dest_dir.join("top.txt")).unwrap(), b"top");
+    assert!(
+        !dest_dir.join("subdir").exists(),
+        "-a --no-r must not copy directory operands"
+    );
+}
+
+#[test]
+fn no_recursive_before_archive_is_overridden() {
+    let test_dir = TestDir::new().expect("create test dir");
+    let src_dir = test_dir.mkdir("src").unwrap();
+    let dest_dir = test_dir.mkdir("dest").unwrap();
+
+    fs::create_dir(src_dir.join("subdir")).unwrap();
+    fs::write(src_dir.join("subdir/nested.txt"), b"nested").unwrap();
+
+    let mut cmd = RsyncCommand::new();
+    cmd.args([
+        "--no-r",
+        "-a",
+        &format!("{}/", src_dir.display()),
+        dest_dir.to_str().unwrap(),
+    ]);
+    cmd.assert_success();
+
+    assert_eq!(
+        fs::read(dest_dir.join("subdir/nested.txt")).unwrap(),
+        b"nested"
+    );
+}
+
 #[test]
 fn update_flag_skips_newer_files() {
     let test_dir = TestDir::new().expect("create test dir");
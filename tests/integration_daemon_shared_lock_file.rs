@@ -0,0 +1,210 @@
+//! Two daemon processes sharing a `lock file` share one `max connections`.
+//!
+//! Spawns two separate `oc-rsync --daemon --no-detach` processes on
+//! different ports from the same rsyncd.conf, whose module sets
+//! `max connections = 2` and whose global `lock file` names one path. The
+//! test asserts:
+//!
+//! * One client admitted by each daemon exhausts the combined cap, so a
+//!   third client is refused by either daemon with the upstream payload
+//!   `@ERROR: max connections (2) reached -- try again later`.
+//! * Closing the connection held by one daemon frees its slot for a client
+//!   of the other.
+//!
+//! Each connection holds an advisory lock on a four-byte slot of the lock
+//! file for its lifetime, so the count is enforced by the kernel across
+//! processes rather than by any in-memory counter.
+//!
+//! Upstream reference: `connection.c:claim_connection()` locks the first free
+//! `lock_range(fd, i*4, 4)` of the lock file; `clientserver.c:rsync_module()`
+//! refuses the module when none is free.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix.
+//! - The oc-rsync binary has not been built.
+//! - A daemon never starts listening (e.g. sandboxed CI).
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::locate_binary;
+use std::fs;
+use std::io::{BufRead, BufReader, Write};
+use std::net::{Ipv4Addr, SocketAddr, TcpListener, TcpStream};
+use std::path::Path;
+use std::process::{Child, Command, Stdio};
+use std::thread;
+use std::time::{Duration, Instant};
+
+const MODULE_NAME: &str = "shared";
+const CONNECTION_CAP: u32 = 2;
+const READY_TIMEOUT: Duration = Duration::from_secs(15);
+
+/// Bind to ephemeral port, capture it, then release for the daemon.
+fn allocate_test_port() -> u16 {
+    let listener = TcpListener::bind((Ipv4Addr::LOCALHOST, 0u16)).expect("allocate port");
+    listener.local_addr().expect("local addr").port()
+}
+
+/// A daemon child process, killed when dropped so a failing assertion does
+/// not leave it listening.
+struct DaemonProcess {
+    child: Child,
+    port: u16,
+}
+
+impl DaemonProcess {
+    fn spawn(binary: &Path, config: &Path) -> Self {
+        let port = allocate_test_port();
+        let child = Command::new(binary)
+            .arg("--daemon")
+            .arg("--no-detach")
+            .arg(format!("--config={}", config.display()))
+            .arg("--address=127.0.0.1")
+            .arg(format!("--port={port}"))
+            .stdin(Stdio::null())
+            .stdout(Stdio::null())
+            .stderr(Stdio::null())
+            .spawn()
+            .expect("spawn daemon");
+        Self { child, port }
+    }
+}
+
+impl Drop for DaemonProcess {
+    fn drop(&mut self) {
+        let _ = self.child.kill();
+        let _ = self.child.wait();
+    }
+}
+
+/// Outcome of a module request.
+enum Response {
+    /// `@RSYNCD: OK`; the open stream keeps the connection slot claimed.
+    Admitted(TcpStream),
+    /// The `@ERROR:` line the daemon answered with.
+    Refused(String),
+}
+
+/// Connects to `port`, retrying until the daemon listens, and requests the
+/// module. Returns `None` when the daemon never starts listening.
+fn request_module(port: u16) -> Option<Response> {
+    let target = SocketAddr::from((Ipv4Addr::LOCALHOST, port));
+    let deadline = Instant::now() + READY_TIMEOUT;
+    let mut stream = loop {
+        match TcpStream::connect_timeout(&target, Duration::from_millis(500)) {
+            Ok(stream) => break stream,
+            Err(_) if Instant::now() < deadline => thread::sleep(Duration::from_millis(20)),
+            Err(_) => return None,
+        }
+    };
+    stream
+        .set_read_timeout(Some(Duration::from_secs(10)))
+        .expect("set read timeout");
+
+    let mut reader = BufReader::new(stream.try_clone().expect("clone stream"));
+    let mut line = String::new();
+    reader.read_line(&mut line).expect("read greeting");
+    assert!(line.starts_with("@RSYNCD:"), "unexpected greeting {line:?}");
+
+    stream
+        .write_all(format!("@RSYNCD: 32.0\n{MODULE_NAME}\n").as_bytes())
+        .expect("send module request");
+    stream.flush().expect("flush module request");
+
+    line.clear();
+    reader.read_line(&mut line).expect("read module response");
+    let trimmed = line.trim_end_matches(['\r', '\n']).to_owned();
+    if trimmed.starts_with("@ERROR:") {
+        return Some(Response::Refused(trimmed));
+    }
+    assert!(
+        trimmed.starts_with("@RSYNCD: OK"),
+        "unexpected module response {trimmed:?}"
+    );
+    Some(Response::Admitted(stream))
+}
+
+fn expect_admitted(port: u16, context: &str) -> Option<TcpStream> {
+    match request_module(port)? {
+        Response::Admitted(stream) => Some(stream),
+        Response::Refused(error) => panic!("{context}: refused with {error:?}"),
+    }
+}
+
+fn expect_refused(port: u16, context: &str) {
+    match request_module(port) {
+        Some(Response::Refused(error)) => assert_eq!(
+            error,
+            format!("@ERROR: max connections ({CONNECTION_CAP}) reached -- try again later"),
+            "{context}"
+        ),
+        Some(Response::Admitted(_)) => panic!("{context}: admitted past the combined cap"),
+        None => panic!("{context}: daemon stopped listening"),
+    }
+}
+
+#[test]
+fn daemons_sharing_lock_file_enforce_combined_max_connections() {
+    let Some(binary) = locate_binary("oc-rsync") else {
+        eprintln!("skipping shared lock file: oc-rsync binary not built");
+        return;
+    };
+
+    let temp = tempfile::tempdir().expect("tempdir");
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module dir");
+    let config_path = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "lock file = {lock}\n\
+             use chroot = false\n\n\
+             [{MODULE_NAME}]\n\
+             path = {path}\n\
+             read only = true\n\
+             max connections = {CONNECTION_CAP}\n",
+            lock = temp.path().join("rsyncd.lock").display(),
+            path = module_dir.display(),
+        ),
+    )
+    .expect("write rsyncd.conf");
+
+    let first = DaemonProcess::spawn(&binary, &config_path);
+    let second = DaemonProcess::spawn(&binary, &config_path);
+
+    let Some(held_by_first) = expect_admitted(first.port, "first daemon, first client") else {
+        eprintln!("skipping shared lock file: first daemon never listened");
+        return;
+    };
+    let Some(held_by_second) = expect_admitted(second.port, "second daemon, first client") else {
+        eprintln!("skipping shared lock file: second daemon never listened");
+        return;
+    };
+
+    // Each daemon holds only one connection itself; the cap is reached only
+    // because the pair shares the lock file.
+    expect_refused(first.port, "first daemon at the combined cap");
+    expect_refused(second.port, "second daemon at the combined cap");
+
+    // Once the second daemon's connection closes, its slot is free for a
+    // client of the first. The daemon releases it when it sees EOF, so poll.
+    drop(held_by_second);
+    let deadline = Instant::now() + Duration::from_secs(10);
+    let reclaimed = loop {
+        match request_module(first.port) {
+            Some(Response::Admitted(stream)) => break stream,
+            Some(Response::Refused(_)) if Instant::now() < deadline => {
+                thread::sleep(Duration::from_millis(50));
+            }
+            Some(Response::Refused(error)) => {
+                panic!("slot freed by the second daemon was never reused: {error:?}")
+            }
+            None => panic!("first daemon stopped listening"),
+        }
+    };
+
+    drop(reclaimed);
+    drop(held_by_first);
+}
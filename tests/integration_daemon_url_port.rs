@@ -0,0 +1,182 @@
+//! A port in an `rsync://` URL and a `--port` flag are reconciled.
+//!
+//! Starts an `oc-rsync --daemon --no-detach` on one port and runs clients
+//! that name it in different ways. The test asserts the documented
+//! precedence:
+//!
+//! * A port embedded in the URL wins over `--port`, so a `--port` naming a
+//!   closed port does not stop `rsync://127.0.0.1:PORT/module/` from
+//!   transferring, or from listing modules.
+//! * `--port` is used when the operand names no port, both for
+//!   `host::module` and for a port-less `rsync://` URL.
+//!
+//! Upstream reference: `main.c:check_for_hostspec()` seeds the port with
+//! `rsync_port` (`--port`) and `parse_hostspec()` overwrites it with the
+//! URL's `:PORT` when one is present.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix.
+//! - The oc-rsync binary has not been built.
+//! - The daemon never starts listening (e.g. sandboxed CI).
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout};
+use std::fs;
+use std::net::{Ipv4Addr, SocketAddr, TcpListener, TcpStream};
+use std::path::Path;
+use std::process::{Child, Command, Output, Stdio};
+use std::thread;
+use std::time::{Duration, Instant};
+
+const MODULE_NAME: &str = "files";
+const READY_TIMEOUT: Duration = Duration::from_secs(15);
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Bind to ephemeral port, capture it, then release it.
+fn allocate_test_port() -> u16 {
+    let listener = TcpListener::bind((Ipv4Addr::LOCALHOST, 0u16)).expect("allocate port");
+    listener.local_addr().expect("local addr").port()
+}
+
+/// The daemon child process, killed when dropped so a failing assertion does
+/// not leave it listening.
+struct DaemonProcess {
+    child: Child,
+    port: u16,
+}
+
+impl DaemonProcess {
+    fn spawn(binary: &Path, config: &Path) -> Self {
+        let port = allocate_test_port();
+        let child = Command::new(binary)
+            .arg("--daemon")
+            .arg("--no-detach")
+            .arg(format!("--config={}", config.display()))
+            .arg("--address=127.0.0.1")
+            .arg(format!("--port={port}"))
+            .stdin(Stdio::null())
+            .stdout(Stdio::null())
+            .stderr(Stdio::null())
+            .spawn()
+            .expect("spawn daemon");
+        Self { child, port }
+    }
+
+    /// Waits until the daemon accepts connections.
+    fn wait_ready(&self) -> bool {
+        let target = SocketAddr::from((Ipv4Addr::LOCALHOST, self.port));
+        let deadline = Instant::now() + READY_TIMEOUT;
+        while Instant::now() < deadline {
+            if TcpStream::connect_timeout(&target, Duration::from_millis(500)).is_ok() {
+                return true;
+            }
+            thread::sleep(Duration::from_millis(20));
+        }
+        false
+    }
+}
+
+impl Drop for DaemonProcess {
+    fn drop(&mut self) {
+        let _ = self.child.kill();
+        let _ = self.child.wait();
+    }
+}
+
+fn run_client(binary: &Path, args: &[String]) -> Output {
+    let mut command = Command::new(binary);
+    command.args(args).arg("--contimeout=10");
+    let output = spawn_with_timeout(command, RUN_TIMEOUT).expect("run client");
+    assert!(
+        output.status.success(),
+        "{args:?} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+    output
+}
+
+#[test]
+fn url_port_takes_precedence_over_port_flag() {
+    let Some(binary) = locate_binary("oc-rsync") else {
+        eprintln!("skipping url port precedence: oc-rsync binary not built");
+        return;
+    };
+
+    let temp = tempfile::tempdir().expect("tempdir");
+    let module_dir = temp.path().join("module");
+    fs::create_dir(&module_dir).expect("create module dir");
+    fs::write(module_dir.join("file.txt"), b"served by the daemon\n").expect("write file");
+    let config_path = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "use chroot = false\n\n\
+             [{MODULE_NAME}]\n\
+             path = {path}\n\
+             read only = true\n",
+            path = module_dir.display(),
+        ),
+    )
+    .expect("write rsyncd.conf");
+
+    let daemon = DaemonProcess::spawn(&binary, &config_path);
+    if !daemon.wait_ready() {
+        eprintln!("skipping url port precedence: daemon never listened");
+        return;
+    }
+    let port = daemon.port;
+    // Nothing listens here; a client that dialled it would fail.
+    let closed_port = allocate_test_port();
+
+    let cases = [
+        (
+            "url port beats --port",
+            closed_port,
+            format!("rsync://127.0.0.1:{port}/{MODULE_NAME}/"),
+        ),
+        (
+            "--port fills a port-less url",
+            port,
+            format!("rsync://127.0.0.1/{MODULE_NAME}/"),
+        ),
+        (
+            "--port applies to host::module",
+            port,
+            format!("127.0.0.1::{MODULE_NAME}/"),
+        ),
+    ];
+    for (index, (context, flag_port, source)) in cases.into_iter().enumerate() {
+        let dest = temp.path().join(format!("dest{index}"));
+        let args = vec![
+            "-r".to_owned(),
+            format!("--port={flag_port}"),
+            source,
+            format!("{}/", dest.display()),
+        ];
+        run_client(&binary, &args);
+        assert_eq!(
+            fs::read(dest.join("file.txt")).expect("read transferred file"),
+            b"served by the daemon\n",
+            "{context}"
+        );
+    }
+
+    let listing = run_client(
+        &binary,
+        &[
+            format!("--port={closed_port}"),
+            format!("rsync://127.0.0.1:{port}/"),
+        ],
+    );
+    let stdout = String::from_utf8_lossy(&listing.stdout);
+    assert!(
+        stdout
+            .lines()
+            .any(|line| line.split_whitespace().next() == Some(MODULE_NAME)),
+        "module listing via the url port must name {MODULE_NAME}:\n{stdout}"
+    );
+}
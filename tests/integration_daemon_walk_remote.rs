@@ -0,0 +1,152 @@
+//! `core::client::walk_remote` streams a daemon module's file list.
+//!
+//! Starts an in-process daemon serving a small tree, walks the module with a
+//! recursive list-only pull and consumes the entry channel, asserting that
+//! every file, directory and symlink arrives with its size, type, mtime and
+//! link target, and that the walk reports success once the list is drained.
+//!
+//! Gated `#[cfg(unix)]` for the symlink and the POSIX mode bits; skips when
+//! no ephemeral port can be bound (sandboxed CI), like
+//! `integration_daemon_filter_directives.rs`.
+
+#![cfg(unix)]
+
+use std::collections::BTreeMap;
+use std::ffi::OsString;
+use std::fs;
+use std::net::{Ipv4Addr, TcpListener};
+use std::path::{Path, PathBuf};
+use std::thread;
+use std::time::{Duration, UNIX_EPOCH};
+
+use core::client::{ClientConfig, RemoteFileEntry, walk_remote};
+use daemon::{DaemonConfig, run_daemon};
+use tempfile::tempdir;
+
+const S_IFMT: u32 = 0o170_000;
+const S_IFREG: u32 = 0o100_000;
+const S_IFDIR: u32 = 0o040_000;
+const S_IFLNK: u32 = 0o120_000;
+const MTIME: u64 = 1_700_000_000;
+
+fn allocate_test_port() -> Option<(u16, TcpListener)> {
+    let listener = TcpListener::bind((Ipv4Addr::LOCALHOST, 0u16)).ok()?;
+    let port = listener.local_addr().ok()?.port();
+    Some((port, listener))
+}
+
+fn write_file(path: &Path, contents: &[u8]) {
+    fs::write(path, contents).expect("write file");
+    fs::File::options()
+        .write(true)
+        .open(path)
+        .and_then(|file| file.set_modified(UNIX_EPOCH + Duration::from_secs(MTIME)))
+        .expect("set mtime");
+}
+
+#[test]
+fn walk_remote_streams_every_entry_of_a_module() {
+    let Some((port, held_listener)) = allocate_test_port() else {
+        eprintln!("walk_remote: skipped, no free port");
+        return;
+    };
+
+    let temp = tempdir().expect("tempdir");
+    let module = temp.path().join("module");
+    fs::create_dir_all(module.join("a/b")).expect("create tree");
+    write_file(&module.join("top.txt"), b"top level\n");
+    write_file(&module.join("a/one.txt"), b"1");
+    write_file(&module.join("a/b/two.txt"), b"twenty-two");
+    std::os::unix::fs::symlink("a/one.txt", module.join("link")).expect("symlink");
+
+    let config_path = temp.path().join("rsyncd.conf");
+    fs::write(
+        &config_path,
+        format!(
+            "[walk]\n\
+             path = {}\n\
+             use chroot = false\n\
+             read only = true\n",
+            module.display()
+        ),
+    )
+    .expect("write rsyncd.conf");
+
+    let daemon_config = DaemonConfig::builder()
+        .disable_default_paths()
+        .arguments([
+            OsString::from("--port"),
+            OsString::from(port.to_string()),
+            OsString::from("--config"),
+            config_path.into_os_string(),
+            OsString::from("--max-sessions"),
+            OsString::from("1"),
+        ])
+        .pre_bound_listener(held_listener)
+        .build();
+    let daemon_handle = thread::spawn(move || run_daemon(daemon_config));
+
+    let client_config = ClientConfig::builder()
+        .transfer_args([format!("rsync://127.0.0.1:{port}/walk/")])
+        .list_only(true)
+        .recursive(true)
+        .links(true)
+        .times(true)
+        .no_motd(true)
+        .build();
+    let mut walk = walk_remote(client_config).expect("start walk");
+
+    let mut entries: BTreeMap<PathBuf, RemoteFileEntry> = BTreeMap::new();
+    for entry in walk.by_ref() {
+        let previous = entries.insert(entry.path().to_path_buf(), entry);
+        assert!(previous.is_none(), "entry streamed twice: {previous:?}");
+    }
+    walk.finish().expect("walk succeeds");
+    daemon_handle
+        .join()
+        .expect("daemon thread panicked")
+        .expect("daemon exits cleanly");
+
+    let paths: Vec<&str> = entries
+        .keys()
+        .map(|path| path.to_str().expect("utf-8 path"))
+        .collect();
+    assert_eq!(
+        paths,
+        [
+            ".",
+            "a",
+            "a/b",
+            "a/b/two.txt",
+            "a/one.txt",
+            "link",
+            "top.txt"
+        ]
+    );
+
+    for (path, size) in [("top.txt", 10), ("a/one.txt", 1), ("a/b/two.txt", 10)] {
+        let entry = &entries[Path::new(path)];
+        assert_eq!(entry.mode() & S_IFMT, S_IFREG, "{path}");
+        assert_eq!(entry.size(), size, "{path}");
+        assert_eq!(entry.mtime(), MTIME as i64, "{path}");
+        assert_eq!(entry.link_target(), None, "{path}");
+    }
+    for path in [".", "a", "a/b"] {
+        assert_eq!(entries[Path::new(path)].mode() & S_IFMT, S_IFDIR, "{path}");
+    }
+    let link = &entries[Path::new("link")];
+    assert_eq!(link.mode() & S_IFMT, S_IFLNK);
+    assert_eq!(link.link_target(), Some(Path::new("a/one.txt")));
+}
+
+#[test]
+fn walk_remote_refuses_a_transfer_that_is_not_list_only() {
+    let config = ClientConfig::builder()
+        .transfer_args(["rsync://127.0.0.1/walk/", "dest/"])
+        .build();
+    let err = walk_remote(config).expect_err("a copy is not a walk");
+    assert!(
+        err.to_string().contains("list-only"),
+        "unexpected error: {err}"
+    );
+}
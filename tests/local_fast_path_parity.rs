@@ -0,0 +1,265 @@
+//! A local-to-local run takes the engine's direct copy path, not the protocol.
+//!
+//! With both operands local, oc-rsync copies through `engine::local_copy`
+//! (reflink / `copy_file_range` where available) instead of starting a server
+//! over a pipe. That path must still honour filters, `--delete` and metadata
+//! exactly as the protocol does. These tests sync the same source into one
+//! destination locally and into another through the protocol (a push to
+//! oc-rsync `--server` behind a shell shim), then compare the two trees entry
+//! by entry. A second test checks that `--no-whole-file` still makes the local
+//! path reuse the basis file's blocks, while the local default sends the whole
+//! file.
+//!
+//! Upstream reference: `options.c` - `whole_file` defaults on when both
+//! sides are local (`local_server`), and `--no-whole-file` turns delta back
+//! on. `generator.c:recv_generator()` applies filters, deletions and
+//! attributes the same way either way.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout, stats_bytes, write_rsh_shim};
+use std::collections::BTreeMap;
+use std::fs;
+use std::os::unix::fs::{PermissionsExt, symlink};
+use std::path::{Path, PathBuf};
+use std::process::{Command, Output};
+use std::time::{Duration, UNIX_EPOCH};
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Runs `oc-rsync <flags> <src>/ <dest>/`, locally or as a push through the
+/// shim, and returns its output.
+fn run(
+    oc_rsync: &Path,
+    root: &Path,
+    flags: &[&str],
+    src: &Path,
+    dest: &Path,
+    push: bool,
+) -> Output {
+    let mut cmd = Command::new(oc_rsync);
+    cmd.args(flags);
+    let dest_arg = if push {
+        let shim = write_rsh_shim(root);
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+        format!("phantom-host:{}/", dest.display())
+    } else {
+        format!("{}/", dest.display())
+    };
+    let src_arg = format!("{}/", src.display());
+    cmd.arg(&src_arg).arg(&dest_arg);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "{flags:?} {src_arg} -> {dest_arg} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+    output
+}
+
+/// One destination entry as the comparison sees it.
+#[derive(Debug, PartialEq, Eq)]
+enum Entry {
+    Dir {
+        mode: u32,
+        mtime: u64,
+    },
+    File {
+        mode: u32,
+        mtime: u64,
+        data: Vec<u8>,
+    },
+    Symlink {
+        target: PathBuf,
+    },
+}
+
+/// Maps every path below `root` (relative, `/`-separated) to its entry.
+fn snapshot(root: &Path) -> BTreeMap<String, Entry> {
+    fn walk(root: &Path, dir: &Path, out: &mut BTreeMap<String, Entry>) {
+        for dirent in fs::read_dir(dir).unwrap() {
+            let path = dirent.unwrap().path();
+            let rel = path
+                .strip_prefix(root)
+                .unwrap()
+                .to_string_lossy()
+                .into_owned();
+            let meta = fs::symlink_metadata(&path).unwrap();
+            let mode = meta.permissions().mode() & 0o7777;
+            let mtime = || {
+                meta.modified()
+                    .unwrap()
+                    .duration_since(UNIX_EPOCH)
+                    .unwrap()
+                    .as_secs()
+            };
+            let entry = if meta.file_type().is_symlink() {
+                Entry::Symlink {
+                    target: fs::read_link(&path).unwrap(),
+                }
+            } else if meta.is_dir() {
+                walk(root, &path, out);
+                Entry::Dir {
+                    mode,
+                    mtime: mtime(),
+                }
+            } else {
+                Entry::File {
+                    mode,
+                    mtime: mtime(),
+                    data: fs::read(&path).unwrap(),
+                }
+            };
+            out.insert(rel, entry);
+        }
+    }
+    let mut out = BTreeMap::new();
+    walk(root, root, &mut out);
+    out
+}
+
+fn set_mtime(path: &Path, secs: u64) {
+    fs::File::options()
+        .write(true)
+        .open(path)
+        .unwrap()
+        .set_modified(UNIX_EPOCH + Duration::from_secs(secs))
+        .unwrap();
+}
+
+/// Deterministic pseudo-random bytes, so no block matches by accident.
+fn noise(len: usize, seed: u64) -> Vec<u8> {
+    let mut state = seed;
+    (0..len)
+        .map(|_| {
+            state ^= state << 13;
+            state ^= state >> 7;
+            state ^= state << 17;
+            (state >> 24) as u8
+        })
+        .collect()
+}
+
+/// Source tree exercising filters, permissions, symlinks and empty dirs.
+fn populate_source(src: &Path) {
+    fs::create_dir_all(src.join("sub/empty")).unwrap();
+    fs::write(src.join("a.txt"), b"alpha\n").unwrap();
+    fs::write(src.join("scratch.tmp"), b"excluded\n").unwrap();
+    fs::write(src.join("sub/b.bin"), noise(300_000, 7)).unwrap();
+    fs::write(src.join("run.sh"), b"#!/bin/sh\n").unwrap();
+    fs::set_permissions(src.join("run.sh"), fs::Permissions::from_mode(0o750)).unwrap();
+    fs::set_permissions(src.join("a.txt"), fs::Permissions::from_mode(0o640)).unwrap();
+    symlink("../a.txt", src.join("sub/link")).unwrap();
+    for (name, secs) in [
+        ("a.txt", 1_600_000_000),
+        ("scratch.tmp", 1_600_000_001),
+        ("sub/b.bin", 1_600_000_002),
+        ("run.sh", 1_600_000_003),
+    ] {
+        set_mtime(&src.join(name), secs);
+    }
+}
+
+/// Stale destination with a file to delete and outdated copies to update.
+fn seed_destination(dest: &Path) {
+    fs::create_dir_all(dest.join("sub")).unwrap();
+    fs::write(dest.join("stale.txt"), b"only in dest\n").unwrap();
+    fs::write(dest.join("a.txt"), b"old alpha\n").unwrap();
+    fs::write(dest.join("sub/b.bin"), noise(200_000, 7)).unwrap();
+}
+
+#[test]
+fn local_copy_matches_protocol_transfer() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping local fast path parity: oc-rsync binary not built");
+        return;
+    };
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let root = tmp.path();
+    let src = root.join("src");
+    let local = root.join("local");
+    let remote = root.join("remote");
+    populate_source(&src);
+    seed_destination(&local);
+    seed_destination(&remote);
+
+    let flags = ["-a", "--delete", "--exclude=*.tmp"];
+    run(&oc_rsync, root, &flags, &src, &local, false);
+    run(&oc_rsync, root, &flags, &src, &remote, true);
+
+    let local_tree = snapshot(&local);
+    assert_eq!(
+        local_tree,
+        snapshot(&remote),
+        "local fast path and protocol transfer produced different trees"
+    );
+    assert!(!local_tree.contains_key("stale.txt"), "--delete ignored");
+    assert!(!local_tree.contains_key("scratch.tmp"), "filter ignored");
+    assert_eq!(
+        local_tree.get("sub/link"),
+        Some(&Entry::Symlink {
+            target: PathBuf::from("../a.txt")
+        })
+    );
+}
+
+#[test]
+fn local_no_whole_file_still_uses_delta() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping local --no-whole-file delta: oc-rsync binary not built");
+        return;
+    };
+    let size = 1024 * 1024;
+    let basis = noise(size, 11);
+    let mut changed = basis.clone();
+    for byte in &mut changed[size / 2..size / 2 + 4096] {
+        *byte = !*byte;
+    }
+
+    let mut matched = Vec::new();
+    let mut trees = Vec::new();
+    for (flags, push) in [
+        (&["-rt", "--stats", "--no-whole-file"][..], false),
+        (&["-rt", "--stats"][..], false),
+        (&["-rt", "--stats", "--no-whole-file"][..], true),
+    ] {
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path();
+        let src = root.join("src");
+        let dest = root.join("dest");
+        fs::create_dir_all(&src).unwrap();
+        fs::create_dir_all(&dest).unwrap();
+        fs::write(src.join("large.bin"), &changed).unwrap();
+        set_mtime(&src.join("large.bin"), 1_600_000_100);
+        fs::write(dest.join("large.bin"), &basis).unwrap();
+        set_mtime(&dest.join("large.bin"), 1_600_000_000);
+
+        let output = run(&oc_rsync, root, flags, &src, &dest, push);
+        assert_eq!(fs::read(dest.join("large.bin")).unwrap(), changed);
+        let stdout = String::from_utf8_lossy(&output.stdout);
+        if !push {
+            matched.push(stats_bytes(&stdout, "Matched data"));
+        }
+        trees.push(snapshot(&dest));
+    }
+
+    assert!(
+        matched[0] > (size as u64) / 2,
+        "--no-whole-file must reuse basis blocks locally, matched {} bytes",
+        matched[0]
+    );
+    assert_eq!(matched[1], 0, "a local copy defaults to whole-file");
+    assert_eq!(
+        trees[0], trees[2],
+        "local and protocol --no-whole-file results differ"
+    );
+}
@@ -0,0 +1,201 @@
+//! Operands of `PATH_MAX` bytes or more still transfer.
+//!
+//! The kernel rejects any path of `PATH_MAX` (4096 on Linux) bytes or more
+//! with `ENAMETOOLONG`, however short its components. Upstream rsync never
+//! hands it such a path: it `chdir()`s into the transfer root and works with
+//! names relative to it. oc-rsync reaches long operands through an alias
+//! anchored at an open directory instead. These tests nest a source and a
+//! destination so deep that their absolute paths pass the limit, then sync
+//! between them - locally, and as pushes to oc-rsync `--server` behind a
+//! shell shim from and into a long operand - and read the results back
+//! through the same kind of anchor.
+//!
+//! Upstream reference: `util1.c:change_dir()` and `main.c:get_local_name()`
+//! move into the destination before any per-file name is built.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Linux (the anchors are `/proc/self/fd` links).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(target_os = "linux")]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout, stats_bytes, write_rsh_shim};
+use std::fs;
+use std::os::fd::AsRawFd;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Output};
+use std::time::{Duration, UNIX_EPOCH};
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Linux `PATH_MAX`, terminating NUL included.
+const PATH_MAX: usize = 4096;
+
+/// Runs `oc-rsync <flags> <src>/ <dest>/`, locally or as a push through the
+/// shim, and returns its output.
+fn run(
+    oc_rsync: &Path,
+    root: &Path,
+    flags: &[&str],
+    src: &Path,
+    dest: &Path,
+    push: bool,
+) -> Output {
+    let mut cmd = Command::new(oc_rsync);
+    cmd.args(flags);
+    let dest_arg = if push {
+        let shim = write_rsh_shim(root);
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+        format!("phantom-host:{}/", dest.display())
+    } else {
+        format!("{}/", dest.display())
+    };
+    let src_arg = format!("{}/", src.display());
+    cmd.arg(&src_arg).arg(&dest_arg);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "{flags:?} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+    output
+}
+
+/// A directory nested below `root` until its absolute path passes
+/// `PATH_MAX`, held open so the test can reach it without the long path.
+struct DeepDir {
+    path: PathBuf,
+    handle: fs::File,
+}
+
+impl DeepDir {
+    /// Creates each level through the previous level's `/proc/self/fd` alias,
+    /// so no syscall sees a path longer than one alias plus one name.
+    fn create(root: &Path) -> Self {
+        let name = "n".repeat(250);
+        let mut path = root.to_path_buf();
+        let mut handle = fs::File::open(root).expect("open root");
+        while path.as_os_str().len() < PATH_MAX + 256 {
+            let level = alias(&handle).join(&name);
+            fs::create_dir(&level).expect("create level");
+            handle = fs::File::open(&level).expect("open level");
+            path.push(&name);
+        }
+        Self { path, handle }
+    }
+
+    /// Short name for `self.path.join(relative)`.
+    fn reach(&self, relative: &str) -> PathBuf {
+        alias(&self.handle).join(relative)
+    }
+}
+
+fn alias(handle: &fs::File) -> PathBuf {
+    PathBuf::from(format!("/proc/self/fd/{}", handle.as_raw_fd()))
+}
+
+fn mtime(path: &Path) -> u64 {
+    fs::metadata(path)
+        .unwrap()
+        .modified()
+        .unwrap()
+        .duration_since(UNIX_EPOCH)
+        .unwrap()
+        .as_secs()
+}
+
+/// Writes `a.txt` and `sub/b.txt` below `dir` with fixed mtimes.
+fn populate(dir: &Path) {
+    fs::create_dir_all(dir.join("sub")).unwrap();
+    for (name, data, secs) in [
+        ("a.txt", &b"alpha\n"[..], 1_600_000_000),
+        ("sub/b.txt", &b"bravo\n"[..], 1_600_000_001),
+    ] {
+        fs::write(dir.join(name), data).unwrap();
+        fs::File::options()
+            .write(true)
+            .open(dir.join(name))
+            .unwrap()
+            .set_modified(UNIX_EPOCH + Duration::from_secs(secs))
+            .unwrap();
+    }
+}
+
+fn assert_synced(dest: &Path) {
+    assert_eq!(fs::read(dest.join("a.txt")).unwrap(), b"alpha\n");
+    assert_eq!(fs::read(dest.join("sub/b.txt")).unwrap(), b"bravo\n");
+    assert_eq!(mtime(&dest.join("a.txt")), 1_600_000_000);
+    assert_eq!(mtime(&dest.join("sub/b.txt")), 1_600_000_001);
+}
+
+#[test]
+fn local_copy_between_long_operands() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping long path names: oc-rsync binary not built");
+        return;
+    };
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let deep = DeepDir::create(tmp.path());
+    let src = deep.path.join("src");
+    let dest = deep.path.join("dest");
+    assert!(
+        fs::metadata(&deep.path).is_err(),
+        "the nested prefix must be too long for the kernel"
+    );
+    populate(&deep.reach("src"));
+
+    run(&oc_rsync, tmp.path(), &["-a"], &src, &dest, false);
+    assert_synced(&deep.reach("dest"));
+
+    let output = run(
+        &oc_rsync,
+        tmp.path(),
+        &["-a", "--stats"],
+        &src,
+        &dest,
+        false,
+    );
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(
+        stats_bytes(&stdout, "Literal data"),
+        0,
+        "an unchanged tree must not be sent again"
+    );
+}
+
+#[test]
+fn push_into_long_destination() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping long path names: oc-rsync binary not built");
+        return;
+    };
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let src = tmp.path().join("src");
+    populate(&src);
+    let deep = DeepDir::create(tmp.path());
+    let dest = deep.path.join("dest");
+
+    run(&oc_rsync, tmp.path(), &["-a"], &src, &dest, true);
+    assert_synced(&deep.reach("dest"));
+}
+
+#[test]
+fn push_from_long_source() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping long path names: oc-rsync binary not built");
+        return;
+    };
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let deep = DeepDir::create(tmp.path());
+    let src = deep.path.join("src");
+    populate(&deep.reach("src"));
+    let dest = tmp.path().join("dest");
+
+    run(&oc_rsync, tmp.path(), &["-a"], &src, &dest, true);
+    assert_synced(&dest);
+}
@@ -0,0 +1,174 @@
+//! `-o`/`--owner` and `-g`/`--group` preserve ownership independently.
+//!
+//! Each toggle, and its `--no-` form after `-a`, must touch only its own half
+//! of the ownership: `-o` applies the source uid and leaves the group the
+//! receiver created the file with, `-g` the reverse. The root tests give the
+//! source tree an owner and group that differ from the receiving process
+//! (both sides oc-rsync, the pushed remote behind a shell shim) and check each
+//! destination attribute separately. Owner preservation needs root, but a
+//! non-root receiver may still set a group it belongs to, so one test covers
+//! `-g` into a supplementary group without privilege.
+//!
+//! Upstream reference: `rsync.c:set_file_attrs()` - `change_uid` is gated on
+//! `preserve_uid` and `change_gid` on `preserve_gid`, and
+//! `uidlist.c:recv_add_id()` skips a group the receiver is not a member of
+//! unless it runs as root.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - Root tests: not running as root.
+//! - Supplementary-group test: running as root, or the process has no group
+//!   besides its primary one.
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{is_root, locate_binary, spawn_with_timeout, write_rsh_shim};
+use std::fs;
+use std::os::unix::fs::{MetadataExt, chown};
+use std::path::PathBuf;
+use std::process::Command;
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Source owner and group, distinct from the root receiver's own ids.
+const SRC_UID: u32 = 43_220;
+const SRC_GID: u32 = 43_221;
+
+/// Runs `id <flag>` and parses the whitespace-separated numeric ids it prints.
+fn id_values(flag: &str) -> Vec<u32> {
+    match Command::new("id").arg(flag).output() {
+        Ok(o) if o.status.success() => String::from_utf8_lossy(&o.stdout)
+            .split_whitespace()
+            .filter_map(|v| v.parse().ok())
+            .collect(),
+        _ => Vec::new(),
+    }
+}
+
+/// Scratch tree for one run: `src/file.txt` owned by `(uid, gid)`.
+struct Fixture {
+    _tmp: tempfile::TempDir,
+    root: PathBuf,
+    oc_rsync: PathBuf,
+}
+
+impl Fixture {
+    fn new(test: &str, owner: Option<u32>, group: u32) -> Option<Self> {
+        let Some(oc_rsync) = locate_binary("oc-rsync") else {
+            eprintln!("skipping {test}: oc-rsync binary not built");
+            return None;
+        };
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path().to_path_buf();
+        let src = root.join("src");
+        fs::create_dir_all(&src).unwrap();
+        fs::write(src.join("file.txt"), b"payload\n").unwrap();
+        chown(src.join("file.txt"), owner, Some(group)).expect("chown source file");
+        fs::create_dir_all(root.join("dest")).unwrap();
+        Some(Self {
+            _tmp: tmp,
+            root,
+            oc_rsync,
+        })
+    }
+
+    /// Runs `oc-rsync <flags> src/ dest/`, optionally as a push through the
+    /// shim, and returns the received file's `(uid, gid)`.
+    fn transfer(&self, flags: &[&str], push: bool) -> (u32, u32) {
+        let src = format!("{}/", self.root.join("src").display());
+        let dest = self.root.join("dest");
+        let mut cmd = Command::new(&self.oc_rsync);
+        cmd.args(flags);
+        let dest_arg = if push {
+            let shim = write_rsh_shim(&self.root);
+            cmd.arg(format!("--rsh={}", shim.display()))
+                .arg(format!("--rsync-path={}", self.oc_rsync.display()));
+            format!("phantom-host:{}/", dest.display())
+        } else {
+            format!("{}/", dest.display())
+        };
+        cmd.arg(&src).arg(&dest_arg);
+        let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+            .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+        assert!(
+            output.status.success(),
+            "{flags:?} {src} -> {dest_arg} failed with {:?}\nstderr:\n{}",
+            output.status,
+            String::from_utf8_lossy(&output.stderr)
+        );
+        let metadata = fs::metadata(dest.join("file.txt")).expect("file transferred");
+        (metadata.uid(), metadata.gid())
+    }
+}
+
+/// Runs `flags` locally and as a push, asserting which half of the source
+/// ownership each run carried over.
+fn assert_root_toggle(test: &str, flags: &[&str], expect_owner: bool, expect_group: bool) {
+    if !is_root() {
+        eprintln!("skipping {test}: giving files away requires root");
+        return;
+    }
+    for push in [false, true] {
+        let Some(fx) = Fixture::new(test, Some(SRC_UID), SRC_GID) else {
+            return;
+        };
+        let (uid, gid) = fx.transfer(flags, push);
+        let mode = if push { "push" } else { "local" };
+        assert_eq!(
+            uid == SRC_UID,
+            expect_owner,
+            "{test} ({mode}): owner {uid}, source owner {SRC_UID}"
+        );
+        assert_eq!(
+            gid == SRC_GID,
+            expect_group,
+            "{test} ({mode}): group {gid}, source group {SRC_GID}"
+        );
+    }
+}
+
+#[test]
+fn owner_flag_preserves_only_owner() {
+    assert_root_toggle("-o", &["-r", "-o"], true, false);
+}
+
+#[test]
+fn group_flag_preserves_only_group() {
+    assert_root_toggle("-g", &["-r", "-g"], false, true);
+}
+
+#[test]
+fn archive_no_owner_keeps_group() {
+    assert_root_toggle("-a --no-owner", &["-a", "--no-owner"], false, true);
+}
+
+#[test]
+fn archive_no_group_keeps_owner() {
+    assert_root_toggle("-a --no-group", &["-a", "--no-group"], true, false);
+}
+
+#[test]
+fn non_root_group_flag_sets_supplementary_group() {
+    let test = "non-root -g";
+    if is_root() {
+        eprintln!("skipping {test}: covered by the root tests");
+        return;
+    }
+    let primary = id_values("-g").first().copied();
+    let Some(supplementary) = id_values("-G").into_iter().find(|g| Some(*g) != primary) else {
+        eprintln!("skipping {test}: no supplementary group to preserve");
+        return;
+    };
+    let Some(fx) = Fixture::new(test, None, supplementary) else {
+        return;
+    };
+    let (_uid, gid) = fx.transfer(&["-r", "-g"], false);
+    assert_eq!(
+        gid, supplementary,
+        "-g must set a group the receiver belongs to"
+    );
+}
@@ -0,0 +1,105 @@
+//! `-R` reproduces only the part of a source path after a `/./` anchor.
+//!
+//! With `--relative`, `src/./a/b` names the file-list root `a/b`: the
+//! receiver creates `dest/a/b` (and the implied `dest/a`), never `dest/src`.
+//! The anchor is resolved by whichever side sends the file list, so each case
+//! runs locally, as a push (local sender) and as a pull (oc-rsync `--server
+//! --sender` behind a shell shim).
+//!
+//! Upstream reference: `flist.c:send_file_list()` splits each argument at
+//! `strstr(fbuf, "/./")`, changes into the part before it and sends the rest
+//! as the relative name.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout, write_rsh_shim};
+use std::fs;
+use std::path::Path;
+use std::process::Command;
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+#[derive(Clone, Copy, Debug)]
+enum Mode {
+    Local,
+    Push,
+    Pull,
+}
+
+/// Runs `oc-rsync -rR <src>/./a/b <dest>/` with the remote side, if any,
+/// behind the shim, and asserts success.
+fn run(oc_rsync: &Path, root: &Path, src: &Path, dest: &Path, mode: Mode) {
+    let anchored = format!("{}/./a/b", src.display());
+    let dest_arg = format!("{}/", dest.display());
+    let mut cmd = Command::new(oc_rsync);
+    cmd.arg("-rR");
+    let (src_arg, dest_arg) = match mode {
+        Mode::Local => (anchored, dest_arg),
+        Mode::Push => (anchored, format!("phantom-host:{dest_arg}")),
+        Mode::Pull => (format!("phantom-host:{anchored}"), dest_arg),
+    };
+    if !matches!(mode, Mode::Local) {
+        let shim = write_rsh_shim(root);
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+    }
+    cmd.arg(&src_arg).arg(&dest_arg);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "{mode:?} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+}
+
+fn sorted_names(dir: &Path) -> Vec<String> {
+    let mut names: Vec<String> = fs::read_dir(dir)
+        .unwrap_or_else(|e| panic!("read {}: {e}", dir.display()))
+        .map(|entry| entry.unwrap().file_name().to_string_lossy().into_owned())
+        .collect();
+    names.sort();
+    names
+}
+
+#[test]
+fn only_the_post_anchor_hierarchy_reaches_dest() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping relative dot anchor: oc-rsync binary not built");
+        return;
+    };
+    for mode in [Mode::Local, Mode::Push, Mode::Pull] {
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path();
+        let src = root.join("src");
+        let dest = root.join("dest");
+        fs::create_dir_all(src.join("a/b/sub")).unwrap();
+        fs::write(src.join("a/b/file.txt"), b"top\n").unwrap();
+        fs::write(src.join("a/b/sub/deep.txt"), b"deep\n").unwrap();
+        fs::write(src.join("a/outside.txt"), b"not listed\n").unwrap();
+        fs::create_dir_all(&dest).unwrap();
+
+        run(&oc_rsync, root, &src, &dest, mode);
+
+        assert_eq!(sorted_names(&dest), ["a"], "{mode:?}");
+        assert_eq!(sorted_names(&dest.join("a")), ["b"], "{mode:?}");
+        assert_eq!(
+            sorted_names(&dest.join("a/b")),
+            ["file.txt", "sub"],
+            "{mode:?}"
+        );
+        assert_eq!(
+            fs::read(dest.join("a/b/sub/deep.txt")).unwrap(),
+            b"deep\n",
+            "{mode:?}"
+        );
+    }
+}
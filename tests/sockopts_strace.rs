@@ -0,0 +1,210 @@
+//! `--sockopts` reaches `setsockopt(2)` on both ends of a daemon connection.
+//!
+//! The client applies `--sockopts` to the socket it dials for an
+//! `rsync://` URL, and the daemon applies its own `--sockopts` to the
+//! listener and each accepted connection. Unit tests read the options back
+//! with `getsockopt(2)` on sockets the library opened; these tests run the
+//! real binary under `strace -e trace=setsockopt` instead, so the check
+//! covers the option string travelling from the command line to the kernel.
+//!
+//! Upstream reference: `socket.c:set_socket_options()` - each
+//! comma-separated name is looked up in `socket_options[]` and passed to
+//! `setsockopt()` with its value (or 1 for boolean options).
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Linux (`strace` is Linux-only).
+//! - `strace` is missing or cannot trace (e.g. ptrace is forbidden).
+//! - The oc-rsync binary has not been built.
+//! - No free loopback port could be reserved.
+
+#![cfg(target_os = "linux")]
+
+mod integration;
+
+use integration::helpers::locate_binary;
+use std::fs;
+use std::io::{Read, Write};
+use std::net::{TcpListener, TcpStream};
+use std::path::{Path, PathBuf};
+use std::process::{Child, Command, Output, Stdio};
+use std::thread;
+use std::time::{Duration, Instant};
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+const SOCKOPTS: &str = "TCP_NODELAY,SO_KEEPALIVE,SO_SNDBUF=65536";
+
+/// Reports whether `strace` exists and is allowed to trace a child.
+fn strace_usable() -> bool {
+    Command::new("strace")
+        .args(["-f", "-e", "trace=none", "true"])
+        .stdout(Stdio::null())
+        .stderr(Stdio::null())
+        .status()
+        .is_ok_and(|status| status.success())
+}
+
+fn free_port() -> Option<u16> {
+    let listener = TcpListener::bind("127.0.0.1:0").ok()?;
+    Some(listener.local_addr().ok()?.port())
+}
+
+fn wait_with_timeout(mut child: Child, timeout: Duration) -> Option<Output> {
+    let deadline = Instant::now() + timeout;
+    loop {
+        match child.try_wait().ok()? {
+            Some(_) => return child.wait_with_output().ok(),
+            None if Instant::now() >= deadline => {
+                let _ = child.kill();
+                let _ = child.wait();
+                return None;
+            }
+            None => thread::sleep(Duration::from_millis(50)),
+        }
+    }
+}
+
+/// Common setup, returning the binary or `None` when the test should skip.
+fn setup(test: &str) -> Option<PathBuf> {
+    if !strace_usable() {
+        eprintln!("skipping {test}: strace is unavailable or cannot trace");
+        return None;
+    }
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping {test}: oc-rsync binary not built");
+        return None;
+    };
+    Some(oc_rsync)
+}
+
+/// Asserts that the trace shows every option in [`SOCKOPTS`] being set.
+fn assert_sockopts_traced(trace: &Path, label: &str) {
+    let trace = fs::read_to_string(trace).expect("read strace output");
+    let calls: Vec<&str> = trace
+        .lines()
+        .filter(|line| line.contains("setsockopt("))
+        .collect();
+    for (level, name, value) in [
+        ("IPPROTO_TCP", "TCP_NODELAY", "[1]"),
+        ("SOL_SOCKET", "SO_KEEPALIVE", "[1]"),
+        ("SOL_SOCKET", "SO_SNDBUF", "[65536]"),
+    ] {
+        assert!(
+            calls
+                .iter()
+                .any(|call| call.contains(level) && call.contains(&format!("{name}, {value}"))),
+            "{label}: no setsockopt({level}, {name}, {value}) in trace:\n{}",
+            calls.join("\n")
+        );
+    }
+}
+
+#[test]
+fn client_sockopts_are_applied_to_dialed_socket() {
+    let Some(oc_rsync) = setup("client --sockopts") else {
+        return;
+    };
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let trace = tmp.path().join("client.strace");
+
+    // A stand-in daemon: greet, then hang up once the client answers.
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind listener");
+    let port = listener.local_addr().expect("local addr").port();
+    let server = thread::spawn(move || {
+        if let Ok((mut stream, _)) = listener.accept() {
+            let _ = stream.write_all(b"@RSYNCD: 31.0\n");
+            let mut buf = [0u8; 256];
+            let _ = stream.read(&mut buf);
+        }
+    });
+
+    let child = Command::new("strace")
+        .args(["-f", "-e", "trace=setsockopt", "-o"])
+        .arg(&trace)
+        .arg(&oc_rsync)
+        .arg(format!("--sockopts={SOCKOPTS}"))
+        .arg(format!("rsync://127.0.0.1:{port}/"))
+        .stdin(Stdio::null())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .expect("spawn strace");
+    wait_with_timeout(child, RUN_TIMEOUT)
+        .unwrap_or_else(|| panic!("oc-rsync did not exit within {RUN_TIMEOUT:?}"));
+    server.join().expect("stand-in daemon");
+
+    assert_sockopts_traced(&trace, "client");
+}
+
+#[test]
+fn daemon_sockopts_are_applied_to_accepted_socket() {
+    let Some(oc_rsync) = setup("daemon --sockopts") else {
+        return;
+    };
+    let Some(port) = free_port() else {
+        eprintln!("skipping daemon --sockopts: no free port");
+        return;
+    };
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let trace = tmp.path().join("daemon.strace");
+    let module_dir = tmp.path().join("module");
+    fs::create_dir(&module_dir).unwrap();
+    let config = tmp.path().join("rsyncd.conf");
+    fs::write(
+        &config,
+        format!(
+            "[share]\npath = {}\nuse chroot = false\n",
+            module_dir.display()
+        ),
+    )
+    .unwrap();
+
+    let child = Command::new("strace")
+        .args(["-f", "-e", "trace=setsockopt", "-o"])
+        .arg(&trace)
+        .arg(&oc_rsync)
+        .args([
+            "--daemon",
+            "--no-detach",
+            "--once",
+            "--address",
+            "127.0.0.1",
+        ])
+        .arg(format!("--port={port}"))
+        .arg(format!("--config={}", config.display()))
+        .arg(format!("--sockopts={SOCKOPTS}"))
+        .stdin(Stdio::null())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .expect("spawn strace");
+
+    let deadline = Instant::now() + Duration::from_secs(15);
+    let mut stream = loop {
+        match TcpStream::connect(("127.0.0.1", port)) {
+            Ok(stream) => break stream,
+            Err(_) if Instant::now() < deadline => thread::sleep(Duration::from_millis(50)),
+            Err(error) => panic!("daemon never accepted on port {port}: {error}"),
+        }
+    };
+    stream
+        .set_read_timeout(Some(Duration::from_secs(10)))
+        .unwrap();
+    let mut greeting = [0u8; 64];
+    let _ = stream.read(&mut greeting);
+    let _ = stream.write_all(b"@RSYNCD: 31.0\n#list\n");
+    let mut rest = Vec::new();
+    let _ = stream.read_to_end(&mut rest);
+    drop(stream);
+
+    let output = wait_with_timeout(child, RUN_TIMEOUT)
+        .unwrap_or_else(|| panic!("oc-rsync --daemon did not exit within {RUN_TIMEOUT:?}"));
+    assert!(
+        output.status.success(),
+        "daemon exited with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+
+    assert_sockopts_traced(&trace, "daemon");
+}
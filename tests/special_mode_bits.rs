@@ -0,0 +1,147 @@
+//! The setuid, setgid and sticky bits survive a transfer.
+//!
+//! The high permission bits travel in the file-list mode word and are applied
+//! by the receiver's chmod, which runs after its chown because `chown(2)`
+//! clears setuid/setgid on a regular file. Each test sends a setuid file, a
+//! setuid+setgid file, a setgid directory and a sticky directory with `-a`,
+//! locally, as a push and as a pull (both sides oc-rsync, the remote behind a
+//! shell shim), then checks every destination mode bit for bit.
+//!
+//! Upstream reference: `rsync.c:set_file_attrs()` - ownership is changed
+//! first, then `do_chmod()` applies the full `file->mode` when
+//! `preserve_perms` is set.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - Not running as root (a non-root receiver drops setgid for groups it is
+//!   not in, and `-a` ownership needs root to match the source).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{is_root, spawn_with_timeout, src_dest_fixture, write_rsh_shim};
+use std::fs;
+use std::os::unix::fs::PermissionsExt;
+use std::path::Path;
+use std::process::Command;
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Relative path and full permission bits of each entry under `src/`.
+const ENTRIES: [(&str, u32); 4] = [
+    ("setuid.bin", 0o4755),
+    ("setuid_setgid.bin", 0o6711),
+    ("setgid_dir", 0o2775),
+    ("sticky_dir", 0o1777),
+];
+
+const NEEDS_ROOT: &str = "preserving setgid and ownership requires root";
+
+/// Builds the tree described by [`ENTRIES`] below `src`.
+fn populate(src: &Path) {
+    for (rel, mode) in ENTRIES {
+        let path = src.join(rel);
+        if rel.ends_with("_dir") {
+            fs::create_dir(&path).unwrap();
+            fs::write(path.join("inner.txt"), b"inner\n").unwrap();
+        } else {
+            fs::write(&path, b"#!/bin/sh\n").unwrap();
+        }
+        fs::set_permissions(&path, fs::Permissions::from_mode(mode)).unwrap();
+    }
+}
+
+fn run_archive(oc_rsync: &Path, shim: Option<&Path>, src: &str, dest: &str) {
+    let mut cmd = Command::new(oc_rsync);
+    cmd.arg("-a");
+    if let Some(shim) = shim {
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+    }
+    cmd.arg(src).arg(dest);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "{src} -> {dest} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+}
+
+fn assert_special_bits_kept(dest: &Path) {
+    for (rel, mode) in ENTRIES {
+        let metadata = fs::symlink_metadata(dest.join(rel))
+            .unwrap_or_else(|error| panic!("{rel} not transferred: {error}"));
+        assert_eq!(
+            metadata.permissions().mode() & 0o7777,
+            mode,
+            "{rel}: permission bits changed"
+        );
+    }
+}
+
+#[test]
+fn local_archive_keeps_special_mode_bits() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "local special bits",
+        tmp.path(),
+        (!is_root()).then_some(NEEDS_ROOT),
+        populate,
+    ) else {
+        return;
+    };
+    run_archive(
+        &oc_rsync,
+        None,
+        &format!("{}/", src.display()),
+        &format!("{}/", dest.display()),
+    );
+    assert_special_bits_kept(&dest);
+}
+
+#[test]
+fn push_archive_keeps_special_mode_bits() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "push special bits",
+        tmp.path(),
+        (!is_root()).then_some(NEEDS_ROOT),
+        populate,
+    ) else {
+        return;
+    };
+    let shim = write_rsh_shim(tmp.path());
+    run_archive(
+        &oc_rsync,
+        Some(&shim),
+        &format!("{}/", src.display()),
+        &format!("phantom-host:{}/", dest.display()),
+    );
+    assert_special_bits_kept(&dest);
+}
+
+#[test]
+fn pull_archive_keeps_special_mode_bits() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "pull special bits",
+        tmp.path(),
+        (!is_root()).then_some(NEEDS_ROOT),
+        populate,
+    ) else {
+        return;
+    };
+    let shim = write_rsh_shim(tmp.path());
+    run_archive(
+        &oc_rsync,
+        Some(&shim),
+        &format!("phantom-host:{}/", src.display()),
+        &format!("{}/", dest.display()),
+    );
+    assert_special_bits_kept(&dest);
+}
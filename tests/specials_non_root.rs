@@ -0,0 +1,114 @@
+//! `-D` transfers run by an unprivileged user.
+//!
+//! A non-root receiver can create FIFOs but not device nodes: `mknod(2)`
+//! needs `CAP_MKNOD`. Upstream reports each refused node with an
+//! `FERROR_XFER` line and carries on, so the rest of the tree still lands
+//! and the run exits `RERR_PARTIAL` (23). These tests send a tree holding a
+//! regular file and a FIFO together with `/dev/null`, locally and as a push
+//! to an oc-rsync server receiver behind a shell shim, and check that only
+//! the device is missing.
+//!
+//! Upstream reference: `generator.c:atomic_create()` -
+//! `rsyserr(FERROR_XFER, errno, "mknod %s failed", full_fname(fname))`.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - Running as root (the device would be created).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{is_root, spawn_with_timeout, src_dest_fixture, write_rsh_shim};
+use std::fs;
+use std::os::unix::fs::FileTypeExt;
+use std::path::Path;
+use std::process::{Command, Output};
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+const ROOT_SKIP: &str = "root can create device nodes";
+
+/// Builds `src/{file.txt,pipe}`.
+fn populate(src: &Path) {
+    fs::write(src.join("file.txt"), b"payload\n").unwrap();
+    let status = Command::new("mkfifo")
+        .arg(src.join("pipe"))
+        .status()
+        .expect("run mkfifo");
+    assert!(status.success(), "mkfifo failed");
+}
+
+fn assert_device_skipped(output: &Output, dest: &Path) {
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    assert_eq!(
+        output.status.code(),
+        Some(23),
+        "expected RERR_PARTIAL, got {:?}\nstderr:\n{stderr}",
+        output.status
+    );
+    assert!(
+        stderr.contains("mknod \"") && stderr.contains("null\" failed"),
+        "missing per-file mknod warning\nstderr:\n{stderr}"
+    );
+    assert_eq!(fs::read(dest.join("file.txt")).unwrap(), b"payload\n");
+    assert!(
+        fs::symlink_metadata(dest.join("pipe"))
+            .expect("fifo transferred")
+            .file_type()
+            .is_fifo()
+    );
+    assert!(
+        fs::symlink_metadata(dest.join("null")).is_err(),
+        "device must not be created by a non-root receiver"
+    );
+}
+
+#[test]
+fn local_non_root_skips_device_and_copies_rest() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "local non-root -D",
+        tmp.path(),
+        is_root().then_some(ROOT_SKIP),
+        populate,
+    ) else {
+        return;
+    };
+
+    let mut cmd = Command::new(&oc_rsync);
+    cmd.arg("-rD")
+        .arg(format!("{}/", src.display()))
+        .arg("/dev/null")
+        .arg(&dest);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert_device_skipped(&output, &dest);
+}
+
+#[test]
+fn push_non_root_skips_device_and_copies_rest() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let Some((oc_rsync, src, dest)) = src_dest_fixture(
+        "push non-root -D",
+        tmp.path(),
+        is_root().then_some(ROOT_SKIP),
+        populate,
+    ) else {
+        return;
+    };
+    let shim = write_rsh_shim(tmp.path());
+
+    let mut cmd = Command::new(&oc_rsync);
+    cmd.arg("-rD")
+        .arg(format!("--rsh={}", shim.display()))
+        .arg(format!("--rsync-path={}", oc_rsync.display()))
+        .arg(format!("{}/", src.display()))
+        .arg("/dev/null")
+        .arg(format!("phantom-host:{}/", dest.display()));
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert_device_skipped(&output, &dest);
+}
@@ -0,0 +1,179 @@
+//! Trailing-slash source semantics across local and remote-shell transfers.
+//!
+//! A source written as `src/` copies the directory's contents into the
+//! destination, while `src` copies the directory itself, producing
+//! `dest/src`. The distinction is decided by the sending side, so it must
+//! survive the trip through the remote command line on a pull and through
+//! the client's own file-list builder on a push.
+//!
+//! The remote cases point `--rsh` at a POSIX shell shim that drops the host
+//! argument and execs the command line locally, with `--rsync-path` naming
+//! the oc-rsync binary, so both ends of the transfer are oc-rsync.
+//!
+//! Upstream reference: `flist.c:send_file_list()` turns a trailing slash into
+//! the `.` (DOTDIR_NAME) entry so only the children are sent, and
+//! `main.c:get_local_name()` places a multi-entry list under the destination.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout, write_rsh_shim};
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+use std::time::Duration;
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// How the source and destination operands reach the other end.
+#[derive(Clone, Copy, Debug)]
+enum Transport {
+    Local,
+    Pull,
+    Push,
+}
+
+/// A scratch tree with `src/{top.txt,nested/inner.txt}` and an existing,
+/// empty `dest`, plus the binary and shim needed to run a transfer.
+struct Fixture {
+    _tmp: tempfile::TempDir,
+    root: PathBuf,
+    oc_rsync: PathBuf,
+    shim: PathBuf,
+}
+
+impl Fixture {
+    fn new(test: &str) -> Option<Self> {
+        let Some(oc_rsync) = locate_binary("oc-rsync") else {
+            eprintln!("skipping {test}: oc-rsync binary not built");
+            return None;
+        };
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path().to_path_buf();
+        fs::create_dir_all(root.join("src/nested")).unwrap();
+        fs::write(root.join("src/top.txt"), b"top\n").unwrap();
+        fs::write(root.join("src/nested/inner.txt"), b"inner\n").unwrap();
+        fs::create_dir_all(root.join("dest")).unwrap();
+        let shim = write_rsh_shim(&root);
+        Some(Self {
+            _tmp: tmp,
+            root,
+            oc_rsync,
+            shim,
+        })
+    }
+
+    fn dest(&self) -> PathBuf {
+        self.root.join("dest")
+    }
+
+    /// Runs `oc-rsync -r <src>[/] <dest>` over `transport`.
+    fn run(&self, transport: Transport, trailing_slash: bool) {
+        let mut src = self.root.join("src").display().to_string();
+        if trailing_slash {
+            src.push('/');
+        }
+        let dest = self.dest().display().to_string();
+        let (src, dest) = match transport {
+            Transport::Local => (src, dest),
+            Transport::Pull => (format!("phantom-host:{src}"), dest),
+            Transport::Push => (src, format!("phantom-host:{dest}")),
+        };
+
+        let mut cmd = Command::new(&self.oc_rsync);
+        cmd.arg("-r");
+        if !matches!(transport, Transport::Local) {
+            cmd.arg(format!("--rsh={}", self.shim.display()))
+                .arg(format!("--rsync-path={}", self.oc_rsync.display()));
+        }
+        cmd.arg(&src).arg(&dest);
+        let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+            .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+        assert!(
+            output.status.success(),
+            "{transport:?} {src} -> {dest} failed with {:?}\nstderr:\n{}",
+            output.status,
+            String::from_utf8_lossy(&output.stderr)
+        );
+    }
+}
+
+fn assert_contents_copied(dest: &Path) {
+    assert_eq!(fs::read(dest.join("top.txt")).unwrap(), b"top\n");
+    assert_eq!(fs::read(dest.join("nested/inner.txt")).unwrap(), b"inner\n");
+    assert!(
+        !dest.join("src").exists(),
+        "trailing-slash source must not create dest/src"
+    );
+}
+
+fn assert_directory_copied(dest: &Path) {
+    assert_eq!(fs::read(dest.join("src/top.txt")).unwrap(), b"top\n");
+    assert_eq!(
+        fs::read(dest.join("src/nested/inner.txt")).unwrap(),
+        b"inner\n"
+    );
+    assert!(
+        !dest.join("top.txt").exists(),
+        "source without a trailing slash must not spill its contents into dest"
+    );
+}
+
+#[test]
+fn local_trailing_slash_copies_contents() {
+    let Some(fx) = Fixture::new("local trailing slash") else {
+        return;
+    };
+    fx.run(Transport::Local, true);
+    assert_contents_copied(&fx.dest());
+}
+
+#[test]
+fn local_without_trailing_slash_copies_directory() {
+    let Some(fx) = Fixture::new("local no trailing slash") else {
+        return;
+    };
+    fx.run(Transport::Local, false);
+    assert_directory_copied(&fx.dest());
+}
+
+#[test]
+fn pull_trailing_slash_copies_contents() {
+    let Some(fx) = Fixture::new("pull trailing slash") else {
+        return;
+    };
+    fx.run(Transport::Pull, true);
+    assert_contents_copied(&fx.dest());
+}
+
+#[test]
+fn pull_without_trailing_slash_copies_directory() {
+    let Some(fx) = Fixture::new("pull no trailing slash") else {
+        return;
+    };
+    fx.run(Transport::Pull, false);
+    assert_directory_copied(&fx.dest());
+}
+
+#[test]
+fn push_trailing_slash_copies_contents() {
+    let Some(fx) = Fixture::new("push trailing slash") else {
+        return;
+    };
+    fx.run(Transport::Push, true);
+    assert_contents_copied(&fx.dest());
+}
+
+#[test]
+fn push_without_trailing_slash_copies_directory() {
+    let Some(fx) = Fixture::new("push no trailing slash") else {
+        return;
+    };
+    fx.run(Transport::Push, false);
+    assert_directory_copied(&fx.dest());
+}
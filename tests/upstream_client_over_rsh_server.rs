@@ -0,0 +1,327 @@
+//! Interop test: an upstream rsync client driving oc-rsync as its remote.
+//!
+//! Over a remote shell, upstream rsync starts the peer as
+//! `<rsync-path> --server [--sender] <flags> . <path>` and speaks the
+//! protocol over its stdin/stdout. These tests point upstream's `--rsh` at a
+//! POSIX shell shim that drops the host argument and execs the command line
+//! locally, with `--rsync-path` naming the oc-rsync binary, so both the
+//! receiver (`--server`, push) and sender (`--server --sender`, pull) server
+//! roles run against a real upstream client. The delta test checks that, as
+//! sender, oc-rsync matches the client's block checksums and sends only the
+//! changed region of a large file. The repeat-run test syncs the same tree
+//! three times and checks that the final, no-change pass still finishes
+//! promptly, which depends on the end-of-transfer phase and goodbye exchange.
+//!
+//! Upstream reference: `main.c:do_cmd()` builds the remote command line,
+//! `options.c:server_options()` the flag string, and `main.c:start_server()`
+//! picks the role from `--sender`. On a pull, the client's generator sends
+//! block checksums (`generator.c:generate_and_send_sums()`) and the sender
+//! answers with matched-block tokens and literal data (`match.c:match_sums()`).
+//! Every run ends with one `NDX_DONE` per phase, the sender's stats, and
+//! `main.c:read_final_goodbye()`.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - No upstream `rsync` available (env `OC_RSYNC_UPSTREAM`, then
+//!   `target/interop/upstream-install/<version>/bin/rsync`, then `which rsync`).
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{
+    locate_binary, locate_upstream_rsync, spawn_with_timeout, stats_bytes, write_rsh_shim,
+};
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Output};
+use std::time::{Duration, SystemTime, UNIX_EPOCH};
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Budget for a pass with nothing to transfer. Such a run is only the file
+/// list and the end-of-transfer handshake, so missing it means a side is
+/// waiting on a frame the other never sends.
+const NO_CHANGE_TIMEOUT: Duration = Duration::from_secs(15);
+
+/// Binaries and shim for one test, or `None` when the test should skip.
+fn interop_setup(test: &str, root: &Path) -> Option<(PathBuf, PathBuf, PathBuf)> {
+    let Some(upstream) = locate_upstream_rsync() else {
+        eprintln!(
+            "skipping {test}: no upstream rsync found \
+             (set OC_RSYNC_UPSTREAM or install rsync)"
+        );
+        return None;
+    };
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping {test}: oc-rsync binary not built");
+        return None;
+    };
+    Some((upstream, oc_rsync, write_rsh_shim(root)))
+}
+
+/// Populate `src` with a small tree: nested directories, an empty file, a
+/// file larger than one block, and a fixed mtime on every file.
+fn populate_source(src: &Path) -> Vec<&'static str> {
+    let files = [
+        "top.txt",
+        "empty.dat",
+        "nested/inner.txt",
+        "nested/deeper/big.bin",
+    ];
+    fs::create_dir_all(src.join("nested/deeper")).unwrap();
+    fs::write(src.join("top.txt"), b"hello from upstream\n").unwrap();
+    fs::write(src.join("empty.dat"), b"").unwrap();
+    fs::write(src.join("nested/inner.txt"), b"inner contents\n").unwrap();
+    let big: Vec<u8> = (0..300_000u32).map(|i| (i % 251) as u8).collect();
+    fs::write(src.join("nested/deeper/big.bin"), big).unwrap();
+    let mtime = UNIX_EPOCH + Duration::from_secs(1_600_000_000);
+    for name in files {
+        let file = fs::File::options()
+            .write(true)
+            .open(src.join(name))
+            .unwrap();
+        file.set_modified(mtime).unwrap();
+    }
+    files.to_vec()
+}
+
+fn modified_secs(path: &Path) -> u64 {
+    let modified: SystemTime = fs::metadata(path).unwrap().modified().unwrap();
+    modified.duration_since(UNIX_EPOCH).unwrap().as_secs()
+}
+
+fn assert_trees_match(src: &Path, dst: &Path, files: &[&str]) {
+    for name in files {
+        let expected = fs::read(src.join(name)).unwrap();
+        let actual = fs::read(dst.join(name))
+            .unwrap_or_else(|error| panic!("{name} missing from destination: {error}"));
+        assert_eq!(actual, expected, "{name} contents differ");
+        assert_eq!(
+            modified_secs(&dst.join(name)),
+            modified_secs(&src.join(name)),
+            "{name} mtime not preserved"
+        );
+    }
+}
+
+fn run_upstream(
+    upstream: &Path,
+    shim: &Path,
+    oc_rsync: &Path,
+    extra_args: &[&str],
+    src: &str,
+    dst: &str,
+) -> Output {
+    run_upstream_within(upstream, shim, oc_rsync, extra_args, src, dst, RUN_TIMEOUT)
+}
+
+fn run_upstream_within(
+    upstream: &Path,
+    shim: &Path,
+    oc_rsync: &Path,
+    extra_args: &[&str],
+    src: &str,
+    dst: &str,
+    timeout: Duration,
+) -> Output {
+    let mut cmd = Command::new(upstream);
+    cmd.arg("-rt")
+        .args(extra_args)
+        .arg(format!("--rsh={}", shim.display()))
+        .arg(format!("--rsync-path={}", oc_rsync.display()))
+        .arg(src)
+        .arg(dst);
+    let output = spawn_with_timeout(cmd, timeout)
+        .unwrap_or_else(|error| panic!("upstream rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "upstream rsync failed with {:?}\nstdout:\n{}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stdout),
+        String::from_utf8_lossy(&output.stderr)
+    );
+    output
+}
+
+#[test]
+fn upstream_push_to_oc_rsync_server_receiver() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let root = tmp.path();
+    let Some((upstream, oc_rsync, shim)) = interop_setup("upstream push", root) else {
+        return;
+    };
+    let src = root.join("src");
+    let dst = root.join("dst");
+    let files = populate_source(&src);
+    fs::create_dir_all(&dst).unwrap();
+
+    run_upstream(
+        &upstream,
+        &shim,
+        &oc_rsync,
+        &[],
+        &format!("{}/", src.display()),
+        &format!("phantom-host:{}/", dst.display()),
+    );
+    assert_trees_match(&src, &dst, &files);
+}
+
+#[test]
+fn upstream_pull_from_oc_rsync_server_sender() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let root = tmp.path();
+    let Some((upstream, oc_rsync, shim)) = interop_setup("upstream pull", root) else {
+        return;
+    };
+    let src = root.join("src");
+    let dst = root.join("dst");
+    let files = populate_source(&src);
+    fs::create_dir_all(&dst).unwrap();
+
+    run_upstream(
+        &upstream,
+        &shim,
+        &oc_rsync,
+        &[],
+        &format!("phantom-host:{}/", src.display()),
+        &format!("{}/", dst.display()),
+    );
+    assert_trees_match(&src, &dst, &files);
+}
+
+/// Syncs the tree, changes one file and syncs again, then runs a third pass
+/// that must find nothing to do. The third pass must finish well inside
+/// [`NO_CHANGE_TIMEOUT`], itemize nothing and leave the destination untouched,
+/// in both the push (oc-rsync receiver) and pull (oc-rsync sender) direction.
+#[test]
+fn upstream_third_no_change_pass_terminates_promptly() {
+    for push in [true, false] {
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path();
+        let Some((upstream, oc_rsync, shim)) = interop_setup("upstream repeat run", root) else {
+            return;
+        };
+        let src = root.join("src");
+        let dst = root.join("dst");
+        let files = populate_source(&src);
+        fs::create_dir_all(&dst).unwrap();
+        let (src_arg, dst_arg) = if push {
+            (
+                format!("{}/", src.display()),
+                format!("phantom-host:{}/", dst.display()),
+            )
+        } else {
+            (
+                format!("phantom-host:{}/", src.display()),
+                format!("{}/", dst.display()),
+            )
+        };
+        let mode = if push { "push" } else { "pull" };
+
+        run_upstream(&upstream, &shim, &oc_rsync, &[], &src_arg, &dst_arg);
+        assert_trees_match(&src, &dst, &files);
+
+        fs::write(src.join("nested/inner.txt"), b"changed inner contents\n").unwrap();
+        fs::File::options()
+            .write(true)
+            .open(src.join("nested/inner.txt"))
+            .unwrap()
+            .set_modified(UNIX_EPOCH + Duration::from_secs(1_600_000_100))
+            .unwrap();
+        run_upstream(&upstream, &shim, &oc_rsync, &[], &src_arg, &dst_arg);
+        assert_trees_match(&src, &dst, &files);
+
+        let before: Vec<u64> = files
+            .iter()
+            .map(|name| modified_secs(&dst.join(name)))
+            .collect();
+        let output = run_upstream_within(
+            &upstream,
+            &shim,
+            &oc_rsync,
+            &["-i"],
+            &src_arg,
+            &dst_arg,
+            NO_CHANGE_TIMEOUT,
+        );
+        let stdout = String::from_utf8_lossy(&output.stdout);
+        assert!(
+            stdout.trim().is_empty(),
+            "{mode}: the third pass should not modify anything, itemized:\n{stdout}"
+        );
+        let after: Vec<u64> = files
+            .iter()
+            .map(|name| modified_secs(&dst.join(name)))
+            .collect();
+        assert_eq!(
+            before, after,
+            "{mode}: the third pass touched the destination"
+        );
+        assert_trees_match(&src, &dst, &files);
+    }
+}
+
+#[test]
+fn upstream_pull_of_modified_large_file_uses_delta() {
+    let tmp = tempfile::tempdir().expect("create tempdir");
+    let root = tmp.path();
+    let Some((upstream, oc_rsync, shim)) = interop_setup("upstream delta pull", root) else {
+        return;
+    };
+    let src = root.join("src");
+    let dst = root.join("dst");
+    fs::create_dir_all(&src).unwrap();
+    fs::create_dir_all(&dst).unwrap();
+
+    // 8 MiB of pseudo-random bytes so no block matches by accident.
+    let size = 8 * 1024 * 1024;
+    let mut state = 0x9e37_79b9_7f4a_7c15u64;
+    let original: Vec<u8> = (0..size)
+        .map(|_| {
+            state ^= state << 13;
+            state ^= state >> 7;
+            state ^= state << 17;
+            (state >> 24) as u8
+        })
+        .collect();
+    fs::write(dst.join("large.bin"), &original).unwrap();
+
+    // Overwrite 64 KiB in the middle and append a tail, so the sender must
+    // mix matched blocks with literal data on both sides of the change.
+    let mut modified = original.clone();
+    let offset = size / 2;
+    for byte in &mut modified[offset..offset + 64 * 1024] {
+        *byte = !*byte;
+    }
+    modified.extend_from_slice(b"appended tail\n");
+    fs::write(src.join("large.bin"), &modified).unwrap();
+    let file = fs::File::options()
+        .write(true)
+        .open(src.join("large.bin"))
+        .unwrap();
+    file.set_modified(UNIX_EPOCH + Duration::from_secs(1_600_000_000))
+        .unwrap();
+
+    let output = run_upstream(
+        &upstream,
+        &shim,
+        &oc_rsync,
+        &["--no-whole-file", "--stats"],
+        &format!("phantom-host:{}/", src.display()),
+        &format!("{}/", dst.display()),
+    );
+    assert_trees_match(&src, &dst, &["large.bin"]);
+
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    let literal = stats_bytes(&stdout, "Literal data");
+    let matched = stats_bytes(&stdout, "Matched data");
+    assert!(
+        literal < 256 * 1024,
+        "sender should send only the changed region, sent {literal} literal bytes"
+    );
+    assert!(
+        matched > (size as u64) / 2,
+        "most of the file should come from matched blocks, matched {matched} bytes"
+    );
+}
@@ -0,0 +1,200 @@
+//! Zero-length files and empty directories keep their metadata.
+//!
+//! Length 0 is an edge case for every stage of a transfer: the file list
+//! encodes a zero size, the generator sends a sum_head with no blocks, and the
+//! sender's delta is nothing but the end-of-file token. An off-by-one in any
+//! of them shows up as a missing file, a stray data block or metadata that
+//! was never applied. These tests sync an empty file and an empty directory
+//! locally and as a push to oc-rsync `--server` behind a shell shim, then
+//! check mode, mtime and ownership, and that `--stats` reports no data.
+//!
+//! Upstream reference: `generator.c:generate_and_send_sums()` zeroes the
+//! sum_struct for a zero-length basis, and `receiver.c:receive_data()`
+//! creates the file before the token loop, so an empty file needs no data.
+//!
+//! Skip conditions (test exits cleanly with a printed reason):
+//! - Not Unix (the shim uses `/bin/sh`).
+//! - The oc-rsync binary has not been built.
+
+#![cfg(unix)]
+
+mod integration;
+
+use integration::helpers::{locate_binary, spawn_with_timeout, stats_bytes, write_rsh_shim};
+use std::fs;
+use std::os::unix::fs::{MetadataExt, PermissionsExt};
+use std::path::Path;
+use std::process::{Command, Output};
+use std::time::{Duration, UNIX_EPOCH};
+
+const RUN_TIMEOUT: Duration = Duration::from_secs(60);
+
+/// Runs `oc-rsync <flags> <src>/ <dest>/`, locally or as a push through the
+/// shim, and returns its output.
+fn run(
+    oc_rsync: &Path,
+    root: &Path,
+    flags: &[&str],
+    src: &Path,
+    dest: &Path,
+    push: bool,
+) -> Output {
+    let mut cmd = Command::new(oc_rsync);
+    cmd.args(flags);
+    let dest_arg = if push {
+        let shim = write_rsh_shim(root);
+        cmd.arg(format!("--rsh={}", shim.display()))
+            .arg(format!("--rsync-path={}", oc_rsync.display()));
+        format!("phantom-host:{}/", dest.display())
+    } else {
+        format!("{}/", dest.display())
+    };
+    let src_arg = format!("{}/", src.display());
+    cmd.arg(&src_arg).arg(&dest_arg);
+    let output = spawn_with_timeout(cmd, RUN_TIMEOUT)
+        .unwrap_or_else(|error| panic!("oc-rsync did not finish: {error}"));
+    assert!(
+        output.status.success(),
+        "{flags:?} {src_arg} -> {dest_arg} failed with {:?}\nstderr:\n{}",
+        output.status,
+        String::from_utf8_lossy(&output.stderr)
+    );
+    output
+}
+
+fn set_mtime(path: &Path, secs: u64) {
+    fs::File::options()
+        .write(true)
+        .open(path)
+        .unwrap()
+        .set_modified(UNIX_EPOCH + Duration::from_secs(secs))
+        .unwrap();
+}
+
+/// Sets a directory's mtime; `File::set_modified` needs a file handle, which
+/// a directory opened read-only provides on Unix.
+fn set_dir_mtime(path: &Path, secs: u64) {
+    fs::File::open(path)
+        .unwrap()
+        .set_modified(UNIX_EPOCH + Duration::from_secs(secs))
+        .unwrap();
+}
+
+/// Source tree with one empty file and one empty directory, both carrying a
+/// non-default mode and a fixed mtime.
+fn populate_source(src: &Path) {
+    fs::create_dir_all(src.join("hollow")).unwrap();
+    fs::write(src.join("empty.txt"), b"").unwrap();
+    fs::set_permissions(src.join("empty.txt"), fs::Permissions::from_mode(0o604)).unwrap();
+    fs::set_permissions(src.join("hollow"), fs::Permissions::from_mode(0o750)).unwrap();
+    set_mtime(&src.join("empty.txt"), 1_500_000_000);
+    set_dir_mtime(&src.join("hollow"), 1_500_000_100);
+}
+
+/// Asserts that `dest/<name>` carries the same type, mode, mtime and owner as
+/// `src/<name>`.
+fn assert_same_metadata(src: &Path, dest: &Path, name: &str, how: &str) {
+    let want = fs::symlink_metadata(src.join(name)).unwrap();
+    let got = fs::symlink_metadata(dest.join(name))
+        .unwrap_or_else(|error| panic!("{how}: {name} missing from destination: {error}"));
+    assert_eq!(got.file_type(), want.file_type(), "{how}: {name} type");
+    assert_eq!(
+        got.mode() & 0o7777,
+        want.mode() & 0o7777,
+        "{how}: {name} mode"
+    );
+    assert_eq!(got.mtime(), want.mtime(), "{how}: {name} mtime");
+    assert_eq!(
+        (got.uid(), got.gid()),
+        (want.uid(), want.gid()),
+        "{how}: {name} owner"
+    );
+}
+
+#[test]
+fn empty_file_and_directory_keep_metadata() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping zero-length entries: oc-rsync binary not built");
+        return;
+    };
+
+    for (how, push) in [("local", false), ("push", true)] {
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path();
+        let src = root.join("src");
+        let dest = root.join("dest");
+        populate_source(&src);
+        fs::create_dir_all(&dest).unwrap();
+
+        let output = run(&oc_rsync, root, &["-a", "--stats"], &src, &dest, push);
+        let stdout = String::from_utf8_lossy(&output.stdout);
+
+        assert_same_metadata(&src, &dest, "empty.txt", how);
+        assert_same_metadata(&src, &dest, "hollow", how);
+        let file = fs::metadata(dest.join("empty.txt")).unwrap();
+        assert_eq!(file.len(), 0, "{how}: empty.txt gained data");
+        assert_eq!(file.blocks(), 0, "{how}: empty.txt has allocated blocks");
+        assert_eq!(
+            fs::read_dir(dest.join("hollow")).unwrap().count(),
+            0,
+            "{how}: hollow is no longer empty"
+        );
+        assert_eq!(
+            stats_bytes(&stdout, "Literal data"),
+            0,
+            "{how}: literal data"
+        );
+        assert_eq!(
+            stats_bytes(&stdout, "Matched data"),
+            0,
+            "{how}: matched data"
+        );
+    }
+}
+
+#[test]
+fn stale_file_is_truncated_to_zero_length() {
+    let Some(oc_rsync) = locate_binary("oc-rsync") else {
+        eprintln!("skipping zero-length truncation: oc-rsync binary not built");
+        return;
+    };
+
+    // `--no-whole-file` makes the generator sign the stale basis, so the
+    // sender matches blocks against it and must still emit an empty file.
+    for (how, push) in [("local", false), ("push", true)] {
+        let tmp = tempfile::tempdir().expect("create tempdir");
+        let root = tmp.path();
+        let src = root.join("src");
+        let dest = root.join("dest");
+        populate_source(&src);
+        fs::create_dir_all(dest.join("hollow")).unwrap();
+        fs::write(dest.join("empty.txt"), vec![b'x'; 8192]).unwrap();
+        fs::write(dest.join("hollow/leftover"), b"stale\n").unwrap();
+
+        let flags = ["-a", "--delete", "--no-whole-file", "--stats"];
+        let output = run(&oc_rsync, root, &flags, &src, &dest, push);
+        let stdout = String::from_utf8_lossy(&output.stdout);
+
+        assert_same_metadata(&src, &dest, "empty.txt", how);
+        assert_same_metadata(&src, &dest, "hollow", how);
+        assert_eq!(
+            fs::metadata(dest.join("empty.txt")).unwrap().len(),
+            0,
+            "{how}"
+        );
+        assert!(
+            !dest.join("hollow/leftover").exists(),
+            "{how}: --delete left the stale entry"
+        );
+        assert_eq!(
+            stats_bytes(&stdout, "Literal data"),
+            0,
+            "{how}: literal data"
+        );
+        assert_eq!(
+            stats_bytes(&stdout, "Matched data"),
+            0,
+            "{how}: matched data"
+        );
+    }
+}